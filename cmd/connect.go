@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// connectTrino creates the Trino clients and tests the connection with the
+// selected TRINO_AUTH method. When the method fails because there is no
+// password and no terminal to prompt for one, or because Trino rejects its
+// credentials, the next usable method in TRINO_AUTH is tried. It returns the
+// configuration of the method that worked.
+func connectTrino(cfg *config.TrinoConfig, interactive bool) (*config.TrinoConfig, *trino.Clusters, error) {
+	current := cfg
+	next := 0
+	for i, method := range cfg.AuthMethods {
+		if method == cfg.Auth {
+			next = i + 1
+		}
+	}
+	for {
+		clusters, rejected, err := connectWithAuth(current, interactive)
+		if err == nil {
+			return current, clusters, nil
+		}
+		if !rejected {
+			return nil, nil, err
+		}
+
+		var fallback *config.TrinoConfig
+		for ; fallback == nil && next < len(cfg.AuthMethods); next++ {
+			var skipped error
+			if fallback, skipped = cfg.ForAuth(cfg.AuthMethods[next]); skipped != nil {
+				log.Printf("INFO: Skipping Trino auth method %s: %v", cfg.AuthMethods[next], skipped)
+			}
+		}
+		if fallback == nil {
+			return nil, nil, err
+		}
+		log.Printf("WARNING: Trino auth method %s failed, trying %s: %v", current.Auth, fallback.Auth, err)
+		current = fallback
+	}
+}
+
+// connectWithAuth connects with the auth method of cfg. Rejected reports
+// whether the method itself failed, so that another method may work.
+func connectWithAuth(cfg *config.TrinoConfig, interactive bool) (clusters *trino.Clusters, rejected bool, err error) {
+	if cfg.Auth == config.AuthPassword && cfg.Password == "" {
+		if !interactive {
+			return nil, true, fmt.Errorf("TRINO_AUTH=password requires TRINO_PASSWORD or TRINO_PASSWORD_FILE unless the server runs in a terminal with the stdio transport")
+		}
+		if err := promptPassword(cfg); err != nil {
+			return nil, true, err
+		}
+	}
+
+	log.Println("Connecting to Trino server...")
+	clusters, err = trino.NewClusters(cfg)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to initialize Trino client: %w", err)
+	}
+
+	// External auth connects lazily, on the first query
+	if cfg.ExternalAuth {
+		log.Println("External auth enabled - connection will be established on first query")
+		return clusters, false, nil
+	}
+	if err := testConnection(cfg, clusters); err != nil {
+		if closeErr := clusters.Close(); closeErr != nil {
+			log.Printf("Error closing Trino client: %v", closeErr)
+		}
+		return nil, trino.IsUnauthorized(err), err
+	}
+	return clusters, false, nil
+}
+
+// testConnection lists the catalogs of every cluster. With failover routing,
+// starting needs only one reachable cluster.
+func testConnection(cfg *config.TrinoConfig, clusters *trino.Clusters) error {
+	log.Println("Testing Trino connection...")
+	failover := cfg.RoutingPolicy != config.RoutingNone
+	connected := 0
+	var lastErr error
+	for _, cluster := range clusters.List() {
+		catalogs, err := cluster.Client.ListCatalogsWithContext(context.Background())
+		if err != nil {
+			lastErr = fmt.Errorf("failed to connect to Trino cluster %s: %w", cluster.Name, err)
+			if !failover {
+				return lastErr
+			}
+			log.Printf("WARNING: %v", lastErr)
+			continue
+		}
+		connected++
+		log.Printf("Connected to Trino cluster %s. Available catalogs: %s", cluster.Name, strings.Join(catalogs, ", "))
+	}
+	if connected == 0 {
+		return fmt.Errorf("failed to connect to any Trino cluster: %w", lastErr)
+	}
+	return nil
+}
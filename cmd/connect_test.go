@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestConnectTrinoFallsThroughAuthMethods(t *testing.T) {
+	// A coordinator that rejects every statement as unauthenticated
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	original := ttyPath
+	defer func() { ttyPath = original }()
+	ttyPath = filepath.Join(t.TempDir(), "no-tty")
+
+	t.Setenv("OAUTH_ENABLED", "false")
+	t.Setenv("TRINO_SCHEME", "http")
+	t.Setenv("TRINO_SSL", "false")
+	t.Setenv("TRINO_HOST", serverURL.Hostname())
+	t.Setenv("TRINO_PORT", serverURL.Port())
+	t.Setenv("TRINO_JWT", "eyJhbGciOiJIUzI1NiJ9.e30.sig")
+
+	// The rejected JWT falls through to the browser flow, which connects lazily
+	t.Setenv("TRINO_AUTH", "jwt,external")
+	cfg, err := config.NewTrinoConfig()
+	if err != nil {
+		t.Fatalf("NewTrinoConfig() error = %v", err)
+	}
+	connected, clusters, err := connectTrino(cfg, false)
+	if err != nil {
+		t.Fatalf("connectTrino() error = %v", err)
+	}
+	defer func() { _ = clusters.Close() }()
+	if connected.Auth != config.AuthExternal || connected.AccessToken != "" {
+		t.Errorf("connected with %q (token %q), want external auth without the JWT", connected.Auth, connected.AccessToken)
+	}
+
+	// Without another method, the rejection is reported
+	t.Setenv("TRINO_AUTH", "jwt")
+	if cfg, err = config.NewTrinoConfig(); err != nil {
+		t.Fatalf("NewTrinoConfig() error = %v", err)
+	}
+	if _, _, err := connectTrino(cfg, false); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("connectTrino() = %v, want the 401", err)
+	}
+}
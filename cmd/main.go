@@ -4,10 +4,12 @@ import (
 	"context"
 	"log"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
 
 	"github.com/tuannvm/mcp-trino/internal/config"
 	"github.com/tuannvm/mcp-trino/internal/mcp"
+	"github.com/tuannvm/mcp-trino/internal/tracing"
 	"github.com/tuannvm/mcp-trino/internal/trino"
 )
 
@@ -20,6 +22,16 @@ var (
 // Context keys are now imported from auth package
 
 func main() {
+	// Subcommands; without one the MCP server starts
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(runValidate(os.Stdout))
+		case "query":
+			os.Exit(runQuery(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	log.Println("Starting Trino MCP Server...")
 
 	// Initialize Trino configuration
@@ -29,36 +41,44 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize Trino client
-	log.Println("Connecting to Trino server...")
-	trinoClient, err := trino.NewClient(trinoConfig)
+	// Debug logging may also be toggled at runtime, with SIGUSR1 or set_debug_logging
+	trino.SetDebugLogging(trinoConfig.DebugLogging)
+	go toggleDebugLoggingOnSignal()
+
+	// Choose server mode
+	transport := getEnv("MCP_TRANSPORT", "stdio")
+
+	// Initialize OpenTelemetry tracing
+	if trinoConfig.TracingEnabled {
+		shutdown, err := tracing.Setup(context.Background(), trinoConfig.TracingServiceName, Version)
+		if err != nil {
+			log.Fatalf("Failed to initialize tracing: %v", err)
+		}
+		defer func() {
+			if err := shutdown(context.Background()); err != nil {
+				log.Printf("Error shutting down tracer provider: %v", err)
+			}
+		}()
+	}
+
+	// Initialize Trino clients (one per configured cluster), falling through the
+	// TRINO_AUTH methods until one works; only stdio runs in a user's terminal
+	trinoConfig, clusters, err := connectTrino(trinoConfig, transport == "stdio")
 	if err != nil {
-		log.Fatalf("Failed to initialize Trino client: %v", err)
+		log.Fatalf("Failed to connect to Trino: %v", err)
 	}
 	defer func() {
-		if err := trinoClient.Close(); err != nil {
+		if err := clusters.Close(); err != nil {
 			log.Printf("Error closing Trino client: %v", err)
 		}
 	}()
 
-	// Test connection by listing catalogs (skip for external auth - lazy connection)
-	if !trinoConfig.ExternalAuth {
-		log.Println("Testing Trino connection...")
-		catalogs, err := trinoClient.ListCatalogsWithContext(context.Background())
-		if err != nil {
-			log.Fatalf("Failed to connect to Trino: %v", err)
-		}
-		log.Printf("Connected to Trino server. Available catalogs: %s", strings.Join(catalogs, ", "))
-	} else {
-		log.Println("External auth enabled - connection will be established on first query")
-	}
-
 	// Create MCP server
 	log.Println("Initializing MCP server...")
-	server := mcp.NewServer(trinoClient, trinoConfig, Version)
+	server := mcp.NewServer(clusters, trinoConfig, Version)
 
-	// Choose server mode
-	transport := getEnv("MCP_TRANSPORT", "stdio")
+	// Reload allowlists, result limits and rate limits on SIGHUP without dropping connections
+	go reloadOnSignal(server)
 
 	log.Printf("Starting MCP server with %s transport...", transport)
 	switch transport {
@@ -78,6 +98,33 @@ func main() {
 	log.Println("Server shutdown complete")
 }
 
+// reloadOnSignal re-reads the reloadable configuration each time SIGHUP arrives
+func reloadOnSignal(server *mcp.Server) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	for range ch {
+		log.Println("Received SIGHUP, reloading allowlists and result limits...")
+		if err := server.Reload(); err != nil {
+			log.Printf("ERROR: Configuration reload failed, keeping current settings: %v", err)
+			continue
+		}
+		log.Println("Configuration reloaded")
+	}
+}
+
+// toggleDebugLoggingOnSignal turns debug logging on or off each time SIGUSR1
+// arrives; Windows has no such signal
+func toggleDebugLoggingOnSignal() {
+	if len(debugLogSignals) == 0 {
+		return
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, debugLogSignals...)
+	for range ch {
+		trino.SetDebugLogging(!trino.DebugLogging())
+	}
+}
+
 func getEnv(key, def string) string {
 	if v, ok := os.LookupEnv(key); ok {
 		return v
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"golang.org/x/term"
+)
+
+// ttyPath is the controlling terminal the password prompt is shown on
+var ttyPath = "/dev/tty"
+
+// promptPassword asks for the Trino password when TRINO_AUTH=password is set
+// without one. The prompt uses the controlling terminal rather than stdin and
+// stdout, which carry the MCP protocol in stdio mode, and does not echo input.
+func promptPassword(cfg *config.TrinoConfig) error {
+	if cfg.Auth != config.AuthPassword || cfg.Password != "" {
+		return nil
+	}
+	tty, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("TRINO_AUTH=password needs TRINO_PASSWORD or TRINO_PASSWORD_FILE when no terminal is available to prompt for it")
+	}
+	defer func() { _ = tty.Close() }()
+
+	if _, err := fmt.Fprintf(tty, "Trino password for %s@%s: ", cfg.User, cfg.Host); err != nil {
+		return fmt.Errorf("failed to prompt for the Trino password: %w", err)
+	}
+	password, err := term.ReadPassword(int(tty.Fd()))
+	_, _ = fmt.Fprintln(tty)
+	if err != nil {
+		return fmt.Errorf("failed to read the Trino password: %w", err)
+	}
+	if len(password) == 0 {
+		return fmt.Errorf("no Trino password entered")
+	}
+	cfg.SetPassword(string(password))
+	return nil
+}
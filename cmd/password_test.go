@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestPromptPassword(t *testing.T) {
+	original := ttyPath
+	defer func() { ttyPath = original }()
+	ttyPath = filepath.Join(t.TempDir(), "no-tty")
+
+	// Nothing to ask without password auth, or with a configured password
+	for _, cfg := range []*config.TrinoConfig{{}, {Auth: config.AuthPassword, Password: "secret"}} {
+		if err := promptPassword(cfg); err != nil {
+			t.Errorf("promptPassword(%+v) = %v, want no prompt", cfg, err)
+		}
+	}
+
+	cfg := &config.TrinoConfig{Auth: config.AuthPassword, User: "analyst", Host: "trino.example.com"}
+	if err := promptPassword(cfg); err == nil || !strings.Contains(err.Error(), "TRINO_PASSWORD_FILE") {
+		t.Errorf("promptPassword() without a terminal = %v, want a hint to configure the password", err)
+	}
+}
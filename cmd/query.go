@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/mcp"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// Exit codes of the query subcommand
+const (
+	exitQueryFailed = 1 // The query was rejected or failed
+	exitUsage       = 2 // Invalid arguments or configuration
+)
+
+// runQuery runs a single query through the same client, read-only enforcement,
+// allowlists, query policies, result limits and impersonation as execute_query and prints the result. It
+// returns the process exit code.
+func runQuery(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("query", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "markdown", "Output format: markdown, json, csv, tsv or arrow")
+	cluster := flags.String("cluster", "", "Cluster to query (default: TRINO_DEFAULT_CLUSTER)")
+	user := flags.String("user", "", "Run the query as this user (requires TRINO_ENABLE_IMPERSONATION=true)")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "Usage: mcp-trino query [flags] \"SELECT ...\"")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	query := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if query == "" {
+		flags.Usage()
+		return exitUsage
+	}
+	outputFormat, err := mcp.NormalizeFormat(*format)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	cfg, err := config.NewTrinoConfigWithVersion(Version)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: invalid configuration: %v\n", err)
+		return exitUsage
+	}
+	if err := promptPassword(cfg); err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	name := *cluster
+	if name == "" {
+		name = cfg.DefaultCluster
+	}
+	clusterCfg, err := cfg.ForCluster(name)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	ctx := context.Background()
+	if *user != "" {
+		if !cfg.EnableImpersonation {
+			_, _ = fmt.Fprintln(stderr, "Error: --user requires TRINO_ENABLE_IMPERSONATION=true")
+			return exitUsage
+		}
+		ctx = trino.WithImpersonatedUser(ctx, *user)
+	}
+
+	client, err := trino.NewClient(clusterCfg)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitQueryFailed
+	}
+	defer func() { _ = client.Close() }()
+
+	result, err := client.ExecuteAgentQueryWithResult(ctx, query)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitQueryFailed
+	}
+	output, err := mcp.FormatResult(result, outputFormat)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "Error: failed to format results as %s: %v\n", outputFormat, err)
+		return exitQueryFailed
+	}
+	_, _ = fmt.Fprintln(stdout, strings.TrimRight(output, "\n"))
+
+	summary := fmt.Sprintf("%d row(s)", result.RowCount)
+	if result.Truncated {
+		summary += fmt.Sprintf(", truncated: %s", result.TruncationReason)
+	}
+	if result.QueryID != "" {
+		summary += fmt.Sprintf(" [query %s]", result.QueryID)
+	}
+	_, _ = fmt.Fprintln(stderr, summary)
+	return exitOK
+}
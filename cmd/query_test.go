@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		env          map[string]string
+		expectedCode int
+		expectedErr  string
+	}{
+		{
+			name:         "Missing query",
+			args:         nil,
+			expectedCode: exitUsage,
+			expectedErr:  "Usage: mcp-trino query",
+		},
+		{
+			name:         "Invalid format",
+			args:         []string{"--format", "xml", "SELECT 1"},
+			expectedCode: exitUsage,
+			expectedErr:  "invalid format",
+		},
+		{
+			name:         "Unknown cluster",
+			args:         []string{"--cluster", "staging", "SELECT 1"},
+			expectedCode: exitUsage,
+			expectedErr:  "unknown cluster",
+		},
+		{
+			name:         "Impersonation disabled",
+			args:         []string{"--user", "alice", "SELECT 1"},
+			expectedCode: exitUsage,
+			expectedErr:  "TRINO_ENABLE_IMPERSONATION",
+		},
+		{
+			name:         "Table outside the allowlist",
+			args:         []string{"SELECT * FROM postgresql.public.accounts"},
+			env:          map[string]string{"TRINO_ALLOWED_CATALOGS": "hive"},
+			expectedCode: exitQueryFailed,
+			expectedErr:  "catalog access denied: postgresql not in allowlist",
+		},
+		{
+			name:         "Write query rejected",
+			args:         []string{"DROP TABLE hive.analytics.users"},
+			expectedCode: exitQueryFailed,
+			expectedErr:  "security restriction",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OAUTH_ENABLED", "false")
+			t.Setenv("TRINO_ALLOW_WRITE_QUERIES", "false")
+			t.Setenv("TRINO_ENABLE_IMPERSONATION", "false")
+			t.Setenv("TRINO_SCHEME", "http")
+			t.Setenv("TRINO_HOST", "127.0.0.1")
+			t.Setenv("TRINO_PORT", "1")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var stdout, stderr bytes.Buffer
+			code := runQuery(tt.args, &stdout, &stderr)
+			if code != tt.expectedCode {
+				t.Errorf("runQuery() = %d, want %d\n%s", code, tt.expectedCode, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.expectedErr) {
+				t.Errorf("stderr does not contain %q:\n%s", tt.expectedErr, stderr.String())
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("unexpected output on stdout: %s", stdout.String())
+			}
+		})
+	}
+}
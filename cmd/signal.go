@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// debugLogSignals toggle debug logging
+var debugLogSignals = []os.Signal{syscall.SIGUSR1}
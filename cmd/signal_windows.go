@@ -0,0 +1,7 @@
+package main
+
+import "os"
+
+// debugLogSignals is empty: Windows has no SIGUSR1, so debug logging is
+// toggled with the set_debug_logging tool only
+var debugLogSignals []os.Signal
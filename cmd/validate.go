@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// Exit codes of the validate subcommand
+const (
+	exitOK            = 0 // Every check passed (warnings allowed)
+	exitCheckFailed   = 1 // Configuration loaded but a connectivity, auth or query check failed
+	exitInvalidConfig = 2 // Configuration could not be loaded
+)
+
+// report collects check results and prints them as they complete
+type report struct {
+	out      io.Writer
+	failed   int
+	warnings int
+}
+
+func (r *report) section(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(r.out, "\n"+format+"\n", args...)
+}
+
+func (r *report) pass(format string, args ...interface{}) {
+	r.line("PASS", format, args...)
+}
+
+func (r *report) warn(format string, args ...interface{}) {
+	r.warnings++
+	r.line("WARN", format, args...)
+}
+
+func (r *report) fail(format string, args ...interface{}) {
+	r.failed++
+	r.line("FAIL", format, args...)
+}
+
+func (r *report) skip(format string, args ...interface{}) {
+	r.line("SKIP", format, args...)
+}
+
+func (r *report) line(status, format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(r.out, "  %-5s %s\n", status, fmt.Sprintf(format, args...))
+}
+
+// runValidate loads the configuration, connects to every configured cluster,
+// checks authentication with SELECT 1 and prints a report to out. It returns
+// the process exit code.
+func runValidate(out io.Writer) int {
+	r := &report{out: out}
+	_, _ = fmt.Fprintf(out, "mcp-trino %s configuration check\n", Version)
+
+	r.section("Configuration")
+	cfg, err := config.NewTrinoConfigWithVersion(Version)
+	if err != nil {
+		r.fail("%v", err)
+		_, _ = fmt.Fprintf(out, "\nResult: configuration invalid\n")
+		return exitInvalidConfig
+	}
+	if err := promptPassword(cfg); err != nil {
+		r.fail("%v", err)
+		_, _ = fmt.Fprintf(out, "\nResult: configuration invalid\n")
+		return exitInvalidConfig
+	}
+	r.pass("configuration loaded (%d cluster(s), default: %s)", len(cfg.ClusterNames()), cfg.DefaultCluster)
+	r.pass("allowlists valid (%d catalogs, %d schemas, %d tables)",
+		len(cfg.AllowedCatalogs), len(cfg.AllowedSchemas), len(cfg.AllowedTables))
+	if cfg.ReadOnly {
+		r.pass("read-only mode: write queries, procedure calls and session statements are blocked")
+	}
+	if cfg.AllowWriteQueries {
+		r.warn("write queries are enabled (TRINO_ALLOW_WRITE_QUERIES=true)")
+	}
+	if cfg.SSLInsecure && strings.EqualFold(cfg.Scheme, "https") {
+		r.warn("TLS certificate verification is disabled (TRINO_SSL_INSECURE=true)")
+	}
+
+	for _, name := range cfg.ClusterNames() {
+		clusterCfg, err := cfg.ForCluster(name)
+		if err != nil {
+			r.section("Cluster %s", name)
+			r.fail("%v", err)
+			continue
+		}
+		validateCluster(r, name, clusterCfg)
+	}
+
+	_, _ = fmt.Fprintf(out, "\nResult: %d failed, %d warning(s)\n", r.failed, r.warnings)
+	if r.failed > 0 {
+		return exitCheckFailed
+	}
+	return exitOK
+}
+
+// validateCluster runs the connectivity, authentication and allowlist checks for one cluster
+func validateCluster(r *report, name string, cfg *config.TrinoConfig) {
+	r.section("Cluster %s (%s://%s:%d)", name, cfg.Scheme, cfg.Host, cfg.Port)
+
+	client, err := trino.NewClient(cfg)
+	if err != nil {
+		r.fail("connection: %v", err)
+		return
+	}
+	defer func() { _ = client.Close() }()
+	r.pass("connection configured")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.QueryTimeout)
+	defer cancel()
+
+	if err := client.CheckHealth(ctx); err != nil {
+		r.fail("coordinator: %v", err)
+		return
+	}
+	r.pass("coordinator reachable")
+
+	if cfg.ExternalAuth {
+		r.skip("authentication: external authentication requires a browser login")
+		return
+	}
+
+	start := time.Now()
+	if _, err := client.ExecuteQueryWithContext(ctx, "SELECT 1"); err != nil {
+		if trino.IsAuthenticationError(err) {
+			r.fail("authentication: credentials for user %q were rejected", cfg.User)
+		} else {
+			r.fail("SELECT 1: %v", err)
+		}
+		return
+	}
+	r.pass("authenticated as %q", cfg.User)
+	r.pass("SELECT 1 (%s)", time.Since(start).Round(time.Millisecond))
+
+	if len(cfg.AllowedCatalogs) == 0 {
+		return
+	}
+	catalogs, err := client.ListCatalogsWithContext(ctx)
+	if err != nil {
+		r.fail("list catalogs: %v", err)
+		return
+	}
+	for _, allowed := range cfg.AllowedCatalogs {
+		if !containsFold(catalogs, allowed) {
+			r.warn("allowlisted catalog %q does not exist", allowed)
+		}
+	}
+}
+
+// containsFold reports whether list contains value, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
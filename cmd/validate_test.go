@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRunValidate(t *testing.T) {
+	// A coordinator that answers health checks but rejects every statement as unauthenticated
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/info" {
+			_, _ = fmt.Fprint(w, `{"starting":false}`)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	tests := []struct {
+		name         string
+		env          map[string]string
+		expectedCode int
+		expectedText string
+	}{
+		{
+			name:         "Invalid allowlist",
+			env:          map[string]string{"TRINO_ALLOWED_TABLES": "hive.users"},
+			expectedCode: exitInvalidConfig,
+			expectedText: "TRINO_ALLOWED_TABLES",
+		},
+		{
+			name:         "Unreachable coordinator",
+			env:          map[string]string{"TRINO_SCHEME": "http", "TRINO_HOST": "127.0.0.1", "TRINO_PORT": "1"},
+			expectedCode: exitCheckFailed,
+			expectedText: "FAIL  coordinator",
+		},
+		{
+			name: "Rejected credentials",
+			env: map[string]string{
+				"TRINO_SCHEME": "http", "TRINO_HOST": serverURL.Hostname(), "TRINO_PORT": serverURL.Port(),
+				"TRINO_SSL": "false",
+			},
+			expectedCode: exitCheckFailed,
+			expectedText: "FAIL  authentication",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OAUTH_ENABLED", "false")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var out bytes.Buffer
+			code := runValidate(&out)
+			if code != tt.expectedCode {
+				t.Errorf("runValidate() = %d, want %d\n%s", code, tt.expectedCode, out.String())
+			}
+			if !strings.Contains(out.String(), tt.expectedText) {
+				t.Errorf("report does not contain %q:\n%s", tt.expectedText, out.String())
+			}
+		})
+	}
+}
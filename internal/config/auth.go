@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Trino authentication methods selected with TRINO_AUTH
+const (
+	AuthPassword = "password" // Basic auth with TRINO_USER and TRINO_PASSWORD, e.g. LDAP-backed
+	AuthJWT      = "jwt"      // Pre-issued token from TRINO_JWT, TRINO_JWT_FILE or TRINO_JWT_COMMAND
+	AuthExternal = "external" // Trino's browser OAuth flow, same as TRINO_EXTERNAL_AUTH=true
+)
+
+// parseAuthMethods reads TRINO_AUTH: one method, or a comma-separated list of
+// methods tried in order until one works
+func parseAuthMethods() ([]string, error) {
+	methods := parseAllowlist(strings.ToLower(getEnv("TRINO_AUTH", "")))
+	for i, method := range methods {
+		switch method {
+		case AuthPassword, AuthJWT, AuthExternal:
+		default:
+			return nil, fmt.Errorf("invalid TRINO_AUTH method '%s': must be password, jwt or external", method)
+		}
+		if containsFold(methods[:i], method) {
+			return nil, fmt.Errorf("invalid TRINO_AUTH: method '%s' is listed twice", method)
+		}
+	}
+	return methods, nil
+}
+
+// ForAuth returns a copy of c that authenticates to Trino with method, sending
+// only that method's credentials. It fails when the method cannot be used with
+// the configured credentials.
+func (c *TrinoConfig) ForAuth(method string) (*TrinoConfig, error) {
+	switch method {
+	case AuthPassword:
+		// Trino only accepts passwords over TLS, and the driver drops them otherwise
+		if !strings.EqualFold(c.Scheme, "https") {
+			return nil, fmt.Errorf("TRINO_AUTH=password requires TRINO_SCHEME=https")
+		}
+	case AuthJWT:
+		if c.jwtErr != nil {
+			return nil, c.jwtErr
+		}
+		if c.jwt == "" {
+			return nil, fmt.Errorf("TRINO_AUTH=jwt requires TRINO_JWT, TRINO_JWT_FILE or TRINO_JWT_COMMAND")
+		}
+	case AuthExternal:
+	default:
+		return nil, fmt.Errorf("unknown Trino auth method '%s'", method)
+	}
+
+	derived := *c
+	derived.Auth = method
+	derived.ExternalAuth = method == AuthExternal
+	derived.Password, derived.AccessToken = "", ""
+	switch method {
+	case AuthPassword:
+		derived.Password = c.password
+	case AuthJWT:
+		derived.AccessToken = c.jwt
+	}
+	return &derived, nil
+}
+
+// SetPassword sets the Trino password, e.g. one entered at a prompt
+func (c *TrinoConfig) SetPassword(password string) {
+	c.Password = password
+	c.password = password
+}
+
+// selectAuth returns the configuration for the first TRINO_AUTH method that can
+// be used. Without TRINO_AUTH, whatever credentials are configured are sent.
+func (c *TrinoConfig) selectAuth() (*TrinoConfig, error) {
+	if len(c.AuthMethods) == 0 {
+		c.Password, c.AccessToken = c.password, c.jwt
+		return c, nil
+	}
+	for _, method := range c.AuthMethods {
+		derived, err := c.ForAuth(method)
+		if err != nil {
+			if len(c.AuthMethods) == 1 {
+				return nil, err
+			}
+			log.Printf("INFO: Skipping Trino auth method %s: %v", method, err)
+			continue
+		}
+		log.Printf("INFO: Trino authentication: %s", method)
+		return derived, nil
+	}
+	return nil, fmt.Errorf("none of the TRINO_AUTH methods (%s) can be used", strings.Join(c.AuthMethods, ", "))
+}
+
+// ForAuditWriter returns the configuration of the dedicated connection that
+// inserts audit events into TRINO_AUDIT_TABLE: the cluster's, connecting as
+// TRINO_AUDIT_USER when set. The connection never prompts for external
+// authentication and never impersonates, since it writes in the background.
+func (c *TrinoConfig) ForAuditWriter() *TrinoConfig {
+	derived := *c
+	derived.ClusterName = strings.TrimPrefix(c.ClusterName+"-audit", "-")
+	if c.AuditUser != "" {
+		derived.User = c.AuditUser
+		derived.Password, derived.AccessToken = c.AuditPassword, ""
+	}
+	derived.ExternalAuth = false
+	derived.EnableImpersonation = false
+	derived.RecordDir, derived.ReplayDir = "", ""
+	derived.AuditTable, derived.AuditKafkaRESTURL, derived.AuditKafkaTopic = "", "", ""
+	return &derived
+}
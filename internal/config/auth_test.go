@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthMethodChain(t *testing.T) {
+	t.Setenv("OAUTH_ENABLED", "false")
+
+	// Methods without credentials are skipped
+	t.Setenv("TRINO_AUTH", "jwt,external,password")
+	cfg, err := NewTrinoConfig()
+	if err != nil {
+		t.Fatalf("NewTrinoConfig() error = %v", err)
+	}
+	if cfg.Auth != AuthExternal || !cfg.ExternalAuth {
+		t.Errorf("Auth = %q, ExternalAuth = %v; want external", cfg.Auth, cfg.ExternalAuth)
+	}
+
+	// An expired token skips jwt instead of failing
+	t.Setenv("TRINO_JWT", testJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(-time.Hour).Unix())))
+	if cfg, err = NewTrinoConfig(); err != nil || cfg.Auth != AuthExternal {
+		t.Errorf("NewTrinoConfig() with an expired JWT = %v, %v; want external", cfg, err)
+	}
+
+	// Only the selected method's credential is sent
+	token := testJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(2*time.Hour).Unix()))
+	t.Setenv("TRINO_JWT", token)
+	t.Setenv("TRINO_PASSWORD", "secret")
+	t.Setenv("TRINO_SCHEME", "https")
+	t.Setenv("TRINO_AUTH", "jwt,password")
+	if cfg, err = NewTrinoConfig(); err != nil {
+		t.Fatalf("NewTrinoConfig() error = %v", err)
+	}
+	if cfg.Auth != AuthJWT || cfg.AccessToken != token || cfg.Password != "" {
+		t.Errorf("Auth = %q, AccessToken set = %v, Password = %q; want jwt only", cfg.Auth, cfg.AccessToken != "", cfg.Password)
+	}
+	fallback, err := cfg.ForAuth(AuthPassword)
+	if err != nil {
+		t.Fatalf("ForAuth(password) error = %v", err)
+	}
+	if fallback.Password != "secret" || fallback.AccessToken != "" || fallback.ExternalAuth {
+		t.Errorf("ForAuth(password) = Password %q, AccessToken set %v; want the password only", fallback.Password, fallback.AccessToken != "")
+	}
+
+	for _, tt := range []struct{ auth, externalAuth, wantErr string }{
+		{auth: "jwt,jwt", wantErr: "listed twice"},
+		{auth: "jwt,kerberos", wantErr: "invalid TRINO_AUTH method 'kerberos'"},
+		{auth: "jwt,password", externalAuth: "true", wantErr: "TRINO_EXTERNAL_AUTH"},
+	} {
+		t.Setenv("TRINO_AUTH", tt.auth)
+		t.Setenv("TRINO_EXTERNAL_AUTH", tt.externalAuth)
+		if _, err := NewTrinoConfig(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("NewTrinoConfig() with TRINO_AUTH=%s = %v, want %q", tt.auth, err, tt.wantErr)
+		}
+	}
+}
+
+func TestExternalAuthFlowConfiguration(t *testing.T) {
+	t.Setenv("OAUTH_ENABLED", "false")
+	t.Setenv("TRINO_EXTERNAL_AUTH", "true")
+
+	cfg, err := NewTrinoConfig()
+	if err != nil || cfg.ExternalAuthFlow != ExternalAuthFlowTrino || cfg.ExternalAuthIdP != nil {
+		t.Fatalf("NewTrinoConfig() = %+v, %v; want Trino's flow by default", cfg, err)
+	}
+
+	t.Setenv("TRINO_EXTERNAL_AUTH_FLOW", "device")
+	if _, err := NewTrinoConfig(); err == nil || !strings.Contains(err.Error(), "must be trino or pkce") {
+		t.Errorf("NewTrinoConfig() with an unknown flow = %v, want an error", err)
+	}
+
+	t.Setenv("TRINO_EXTERNAL_AUTH_FLOW", "pkce")
+	t.Setenv("TRINO_EXTERNAL_AUTH_ISSUER", "https://idp.example.com/")
+	if _, err := NewTrinoConfig(); err == nil || !strings.Contains(err.Error(), "TRINO_EXTERNAL_AUTH_CLIENT_ID") {
+		t.Errorf("NewTrinoConfig() without a client ID = %v, want an error", err)
+	}
+
+	t.Setenv("TRINO_EXTERNAL_AUTH_CLIENT_ID", "mcp-trino")
+	t.Setenv("TRINO_EXTERNAL_AUTH_SCOPES", "openid, offline_access")
+	t.Setenv("TRINO_EXTERNAL_AUTH_REDIRECT_PORT", "8400")
+	if cfg, err = NewTrinoConfig(); err != nil {
+		t.Fatalf("NewTrinoConfig() error = %v", err)
+	}
+	idp := cfg.ExternalAuthIdP
+	if idp == nil || idp.Issuer != "https://idp.example.com" || idp.RedirectPort != 8400 || strings.Join(idp.Scopes, " ") != "openid offline_access" {
+		t.Errorf("ExternalAuthIdP = %+v, want the configured identity provider", idp)
+	}
+}
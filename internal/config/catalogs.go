@@ -0,0 +1,111 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CatalogConfig holds the settings of one catalog, so that a server querying
+// catalogs of different connectors can give each its own
+type CatalogConfig struct {
+	Schema            string            `json:"schema,omitempty"`            // Default schema of the catalog, instead of TRINO_SCHEMA
+	SessionProperties map[string]string `json:"sessionProperties,omitempty"` // Catalog session properties, named without the catalog prefix
+	Role              string            `json:"role,omitempty"`              // Connector role, like an entry of TRINO_CATALOG_ROLES
+	ExtraCredentials  map[string]string `json:"extraCredentials,omitempty"`  // X-Trino-Extra-Credential entries for the catalog's connector; values may be secret references
+
+	ExtraCredentialsEnv map[string]string `json:"extraCredentialsEnv,omitempty"` // Read extra credentials from these environment variables, by credential name
+}
+
+// sessionPropertyName matches the names of catalog session properties
+var sessionPropertyName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// loadCatalogs reads per-catalog settings from TRINO_CATALOGS_JSON or
+// TRINO_CATALOGS_FILE, a JSON object keyed by catalog name
+func loadCatalogs() (map[string]CatalogConfig, error) {
+	data := getEnv("TRINO_CATALOGS_JSON", "")
+	source := "TRINO_CATALOGS_JSON"
+	if path := getEnv("TRINO_CATALOGS_FILE", ""); path != "" {
+		if data != "" {
+			return nil, fmt.Errorf("set only one of TRINO_CATALOGS_JSON and TRINO_CATALOGS_FILE")
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TRINO_CATALOGS_FILE: %w", err)
+		}
+		data = string(content)
+		source = "TRINO_CATALOGS_FILE"
+	}
+	if strings.TrimSpace(data) == "" {
+		return nil, nil
+	}
+
+	var parsed map[string]CatalogConfig
+	if err := json.Unmarshal([]byte(data), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid %s: expected a JSON object of catalogs: %w", source, err)
+	}
+
+	catalogs := make(map[string]CatalogConfig, len(parsed))
+	names := make([]string, 0, len(parsed))
+	for name, cat := range parsed {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			return nil, fmt.Errorf("invalid %s: empty catalog name", source)
+		}
+		if _, ok := catalogs[name]; ok {
+			return nil, fmt.Errorf("invalid %s: duplicate catalog '%s'", source, name)
+		}
+		cat.Schema = strings.TrimSpace(cat.Schema)
+		cat.Role = strings.TrimSpace(cat.Role)
+		for property, value := range cat.SessionProperties {
+			if !sessionPropertyName.MatchString(property) {
+				return nil, fmt.Errorf("invalid %s: session property '%s' of catalog '%s' (expected the property name without the catalog prefix)", source, property, name)
+			}
+			if strings.ContainsAny(value, "\r\n") {
+				return nil, fmt.Errorf("invalid %s: session property '%s' of catalog '%s' contains a line break", source, property, name)
+			}
+		}
+		for credential, env := range cat.ExtraCredentialsEnv {
+			if cat.ExtraCredentials == nil {
+				cat.ExtraCredentials = make(map[string]string)
+			}
+			if _, ok := cat.ExtraCredentials[credential]; !ok {
+				cat.ExtraCredentials[credential] = os.Getenv(env)
+			}
+		}
+		for credential, value := range cat.ExtraCredentials {
+			if credential == "" || strings.ContainsAny(credential, "=, \r\n") {
+				return nil, fmt.Errorf("invalid %s: extra credential name '%s' of catalog '%s'", source, credential, name)
+			}
+			// The value may be a secret, so only its name is reported
+			resolved, err := resolveSecret(fmt.Sprintf("extra credential '%s' of catalog '%s'", credential, name), value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", source, err)
+			}
+			if resolved == "" {
+				log.Printf("WARNING: Extra credential '%s' of catalog '%s' is empty and not sent", credential, name)
+				delete(cat.ExtraCredentials, credential)
+				continue
+			}
+			cat.ExtraCredentials[credential] = resolved
+		}
+		catalogs[name] = cat
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	log.Printf("INFO: Per-catalog settings for %s", strings.Join(names, ", "))
+	return catalogs, nil
+}
+
+// DefaultSchema returns the schema tools use for catalog when none is given:
+// the catalog's own from TRINO_CATALOGS_JSON, or else TRINO_SCHEMA
+func (c *TrinoConfig) DefaultSchema(catalog string) string {
+	if cat, ok := c.Catalogs[strings.ToLower(catalog)]; ok && cat.Schema != "" {
+		return cat.Schema
+	}
+	return c.Schema
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadCatalogs(t *testing.T) {
+	t.Setenv("OAUTH_ENABLED", "false")
+	t.Setenv("TRINO_SCHEMA", "default")
+	t.Setenv("HIVE_SECRET_KEY", "from-env")
+	t.Setenv("TRINO_CATALOGS_JSON", `{
+		"Hive": {"schema": "web", "role": "reader", "extraCredentialsEnv": {"hive.s3.secret-key": "HIVE_SECRET_KEY"}},
+		"postgresql": {"sessionProperties": {"join_pushdown_enabled": "true"}}
+	}`)
+
+	config, err := NewTrinoConfig()
+	if err != nil {
+		t.Fatalf("NewTrinoConfig() error = %v", err)
+	}
+	hive := config.Catalogs["hive"]
+	if hive.Role != "reader" || hive.ExtraCredentials["hive.s3.secret-key"] != "from-env" {
+		t.Errorf("hive = %+v, want its role and the resolved credential", hive)
+	}
+	if got := config.DefaultSchema("HIVE"); got != "web" {
+		t.Errorf("DefaultSchema(HIVE) = %q, want web", got)
+	}
+	if got := config.DefaultSchema("postgresql"); got != "default" {
+		t.Errorf("DefaultSchema(postgresql) = %q, want TRINO_SCHEMA", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "catalogs.json")
+	if err := os.WriteFile(path, []byte(`{"iceberg": {"schema": "analytics"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRINO_CATALOGS_JSON", "")
+	t.Setenv("TRINO_CATALOGS_FILE", path)
+	if config, err = NewTrinoConfig(); err != nil || config.DefaultSchema("iceberg") != "analytics" {
+		t.Errorf("TRINO_CATALOGS_FILE: DefaultSchema(iceberg) = %q, error = %v", config.DefaultSchema("iceberg"), err)
+	}
+	t.Setenv("TRINO_CATALOGS_FILE", "")
+
+	for name, value := range map[string]string{
+		"not an object":             `[{"name": "hive"}]`,
+		"prefixed session property": `{"hive": {"sessionProperties": {"hive.compression_codec": "ZSTD"}}}`,
+		"credential name with =":    `{"hive": {"extraCredentials": {"a=b": "c"}}}`,
+		"duplicate catalog":         `{"hive": {}, "HIVE": {}}`,
+	} {
+		t.Setenv("TRINO_CATALOGS_JSON", value)
+		if _, err := NewTrinoConfig(); err == nil || !strings.Contains(err.Error(), "TRINO_CATALOGS_JSON") {
+			t.Errorf("%s: NewTrinoConfig() error = %v, want an invalid TRINO_CATALOGS_JSON error", name, err)
+		}
+	}
+}
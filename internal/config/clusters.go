@@ -0,0 +1,306 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cluster routing policies
+const (
+	RoutingNone       = "none"        // Requests go to the named or default cluster only
+	RoutingFailover   = "failover"    // Primary/secondary: the default cluster first, then the others in order
+	RoutingRoundRobin = "round-robin" // Requests rotate across healthy clusters
+)
+
+// DefaultClusterName is the name of the cluster configured through TRINO_HOST etc.
+// when no named clusters are defined
+const DefaultClusterName = "default"
+
+// ClusterConfig describes a named Trino cluster. Unset fields inherit the values
+// from the top-level TRINO_* configuration.
+type ClusterConfig struct {
+	Name              string   `json:"name"`
+	Description       string   `json:"description,omitempty"`
+	Host              string   `json:"host"`
+	Port              int      `json:"port,omitempty"`
+	User              string   `json:"user,omitempty"`
+	Password          string   `json:"password,omitempty"`
+	PasswordEnv       string   `json:"passwordEnv,omitempty"`  // Read the password from this environment variable
+	PasswordFile      string   `json:"passwordFile,omitempty"` // Read the password from this file, e.g. a mounted secret
+	Catalog           string   `json:"catalog,omitempty"`
+	Schema            string   `json:"schema,omitempty"`
+	Scheme            string   `json:"scheme,omitempty"`
+	SSL               *bool    `json:"ssl,omitempty"`
+	SSLInsecure       *bool    `json:"sslInsecure,omitempty"`
+	AllowWriteQueries *bool    `json:"allowWriteQueries,omitempty"`
+	QueryTimeout      int      `json:"queryTimeout,omitempty"` // Seconds
+	AllowedCatalogs   []string `json:"allowedCatalogs,omitempty"`
+	AllowedSchemas    []string `json:"allowedSchemas,omitempty"`
+	AllowedTables     []string `json:"allowedTables,omitempty"`
+
+	Role         string            `json:"role,omitempty"`         // Replaces TRINO_ROLE for this cluster
+	CatalogRoles map[string]string `json:"catalogRoles,omitempty"` // Replaces TRINO_CATALOG_ROLES for this cluster
+}
+
+// loadClusters reads named clusters from TRINO_CLUSTERS_JSON or TRINO_CLUSTERS_FILE
+func loadClusters() ([]ClusterConfig, error) {
+	data := getEnv("TRINO_CLUSTERS_JSON", "")
+	source := "TRINO_CLUSTERS_JSON"
+	if path := getEnv("TRINO_CLUSTERS_FILE", ""); path != "" {
+		if data != "" {
+			return nil, fmt.Errorf("set only one of TRINO_CLUSTERS_JSON and TRINO_CLUSTERS_FILE")
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TRINO_CLUSTERS_FILE: %w", err)
+		}
+		data = string(content)
+		source = "TRINO_CLUSTERS_FILE"
+	}
+	if strings.TrimSpace(data) == "" {
+		return nil, nil
+	}
+
+	var clusters []ClusterConfig
+	if err := json.Unmarshal([]byte(data), &clusters); err != nil {
+		return nil, fmt.Errorf("invalid %s: expected a JSON array of clusters: %w", source, err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range clusters {
+		cl := &clusters[i]
+		cl.Name = strings.TrimSpace(cl.Name)
+		if cl.Name == "" {
+			return nil, fmt.Errorf("invalid %s: cluster %d has no name", source, i)
+		}
+		if seen[strings.ToLower(cl.Name)] {
+			return nil, fmt.Errorf("invalid %s: duplicate cluster name '%s'", source, cl.Name)
+		}
+		seen[strings.ToLower(cl.Name)] = true
+		if cl.Host == "" {
+			return nil, fmt.Errorf("invalid %s: cluster '%s' has no host", source, cl.Name)
+		}
+		if err := validateAllowlist(cl.Name+" allowedSchemas", cl.AllowedSchemas, 1); err != nil {
+			return nil, err
+		}
+		if err := validateAllowlist(cl.Name+" allowedTables", cl.AllowedTables, 2); err != nil {
+			return nil, err
+		}
+		if cl.PasswordEnv != "" && cl.Password == "" {
+			cl.Password = os.Getenv(cl.PasswordEnv)
+		}
+		if cl.PasswordFile != "" && cl.Password == "" {
+			content, err := os.ReadFile(cl.PasswordFile)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: failed to read passwordFile of cluster '%s': %w", source, cl.Name, err)
+			}
+			cl.Password = strings.TrimSpace(string(content))
+		}
+		password, err := resolveSecret(fmt.Sprintf("password of cluster '%s'", cl.Name), cl.Password)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", source, err)
+		}
+		cl.Password = password
+	}
+	return clusters, nil
+}
+
+// routingConfig holds the parsed routing, health check and circuit breaker settings
+type routingConfig struct {
+	policy         string
+	clusters       []string
+	healthInterval time.Duration
+	threshold      int
+	cooldown       time.Duration
+}
+
+// loadRouting reads the TRINO_ROUTING_*, TRINO_HEALTH_CHECK_INTERVAL and
+// TRINO_CIRCUIT_BREAKER_* settings
+func loadRouting(clusters []ClusterConfig, defaultCluster string) (routingConfig, error) {
+	routing := routingConfig{
+		policy:         strings.ToLower(getEnv("TRINO_ROUTING_POLICY", RoutingNone)),
+		clusters:       parseAllowlist(getEnv("TRINO_ROUTING_CLUSTERS", "")),
+		healthInterval: parseSeconds("TRINO_HEALTH_CHECK_INTERVAL", 30),
+		cooldown:       parseSeconds("TRINO_CIRCUIT_BREAKER_COOLDOWN", 30),
+	}
+	switch routing.policy {
+	case RoutingNone, RoutingFailover, RoutingRoundRobin:
+	case "":
+		routing.policy = RoutingNone
+	default:
+		return routingConfig{}, fmt.Errorf("invalid TRINO_ROUTING_POLICY '%s'. Supported policies: none, failover, round-robin", routing.policy)
+	}
+
+	threshold, err := strconv.Atoi(getEnv("TRINO_CIRCUIT_BREAKER_THRESHOLD", "3"))
+	if err != nil || threshold < 0 {
+		log.Printf("WARNING: Invalid TRINO_CIRCUIT_BREAKER_THRESHOLD, using default of 3 failures")
+		threshold = 3
+	}
+	routing.threshold = threshold
+
+	for _, name := range routing.clusters {
+		if !clusterExists(clusters, name) {
+			return routingConfig{}, fmt.Errorf("invalid TRINO_ROUTING_CLUSTERS entry '%s': no such cluster", name)
+		}
+	}
+	if routing.policy != RoutingNone && len(routing.clusters) > 0 && !containsFold(routing.clusters, defaultCluster) {
+		log.Printf("WARNING: Default cluster '%s' is not in TRINO_ROUTING_CLUSTERS; requests without a cluster are routed to the listed clusters only", defaultCluster)
+	}
+	return routing, nil
+}
+
+// parseSeconds reads a non-negative number of seconds, falling back to the default on invalid input
+func parseSeconds(envVar string, fallback int) time.Duration {
+	value := getEnv(envVar, strconv.Itoa(fallback))
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		log.Printf("WARNING: Invalid %s '%s', using default of %d seconds", envVar, value, fallback)
+		seconds = fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// containsFold reports whether list contains name, ignoring case
+func containsFold(list []string, name string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterExists reports whether name matches a configured cluster
+// (or the implicit default cluster when none are configured)
+func clusterExists(clusters []ClusterConfig, name string) bool {
+	if len(clusters) == 0 {
+		return strings.EqualFold(name, DefaultClusterName)
+	}
+	for _, cl := range clusters {
+		if strings.EqualFold(cl.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClusterNames returns the configured cluster names, or DefaultClusterName when
+// no named clusters are defined
+func (c *TrinoConfig) ClusterNames() []string {
+	if len(c.Clusters) == 0 {
+		return []string{DefaultClusterName}
+	}
+	names := make([]string, len(c.Clusters))
+	for i, cl := range c.Clusters {
+		names[i] = cl.Name
+	}
+	return names
+}
+
+// ForCluster returns the configuration for one named cluster: a copy of c with
+// the cluster's connection, auth and allowlist settings applied.
+func (c *TrinoConfig) ForCluster(name string) (*TrinoConfig, error) {
+	if len(c.Clusters) == 0 && strings.EqualFold(name, DefaultClusterName) {
+		return c, nil
+	}
+	for _, cl := range c.Clusters {
+		if strings.EqualFold(cl.Name, name) {
+			return c.withCluster(cl), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown cluster '%s' (available: %s)", name, strings.Join(c.ClusterNames(), ", "))
+}
+
+// withCluster overlays a cluster definition on a copy of the base configuration
+func (c *TrinoConfig) withCluster(cl ClusterConfig) *TrinoConfig {
+	derived := *c
+	derived.Clusters = nil
+	derived.ClusterName = cl.Name
+	derived.Host = cl.Host
+	if cl.Port > 0 {
+		derived.Port = cl.Port
+	}
+	if cl.User != "" {
+		derived.User = cl.User
+		// A cluster with its own user never inherits the top-level password or JWT
+		derived.Password = cl.Password
+		derived.AccessToken = ""
+	} else if cl.Password != "" {
+		derived.Password = cl.Password
+	}
+	if cl.Catalog != "" {
+		derived.Catalog = cl.Catalog
+	}
+	if cl.Schema != "" {
+		derived.Schema = cl.Schema
+	}
+	if cl.Scheme != "" {
+		derived.Scheme = cl.Scheme
+	}
+	if cl.SSL != nil {
+		derived.SSL = *cl.SSL
+	}
+	if cl.SSLInsecure != nil {
+		derived.SSLInsecure = *cl.SSLInsecure
+	}
+	if strings.EqualFold(derived.Scheme, "https") {
+		derived.SSL = true
+	}
+	if cl.AllowWriteQueries != nil && !c.ReadOnly {
+		derived.AllowWriteQueries = *cl.AllowWriteQueries
+	}
+	if cl.QueryTimeout > 0 {
+		derived.QueryTimeout = time.Duration(cl.QueryTimeout) * time.Second
+		derived.MaxQueryTimeout = max(derived.MaxQueryTimeout, derived.QueryTimeout)
+	}
+	if cl.AllowedCatalogs != nil {
+		derived.AllowedCatalogs = cl.AllowedCatalogs
+	}
+	if cl.AllowedSchemas != nil {
+		derived.AllowedSchemas = cl.AllowedSchemas
+	}
+	if cl.AllowedTables != nil {
+		derived.AllowedTables = cl.AllowedTables
+	}
+	if cl.Role != "" {
+		derived.Role = cl.Role
+	}
+	if cl.CatalogRoles != nil {
+		derived.CatalogRoles = make(map[string]string, len(cl.CatalogRoles))
+		for catalog, role := range cl.CatalogRoles {
+			derived.CatalogRoles[strings.ToLower(catalog)] = role
+		}
+	}
+	return &derived
+}
+
+// logClusterConfiguration logs the named clusters without credentials
+func logClusterConfiguration(clusters []ClusterConfig, defaultCluster string) {
+	if len(clusters) == 0 {
+		return
+	}
+	for _, cl := range clusters {
+		log.Printf("INFO: Trino cluster '%s': %s", cl.Name, cl.Host)
+	}
+	log.Printf("INFO: Default Trino cluster: %s", defaultCluster)
+}
+
+// logRoutingConfiguration logs the routing policy when failover is enabled
+func logRoutingConfiguration(routing routingConfig) {
+	if routing.policy == RoutingNone {
+		if routing.threshold > 0 {
+			log.Printf("INFO: Trino circuit breaker: opens after %d connection failures for %s", routing.threshold, routing.cooldown)
+		}
+		return
+	}
+	log.Printf("INFO: Trino cluster routing policy: %s (health checks every %s, circuit opens after %d failures for %s)",
+		routing.policy, routing.healthInterval, routing.threshold, routing.cooldown)
+	if len(routing.clusters) > 0 {
+		log.Printf("INFO: Trino routing clusters: %s", strings.Join(routing.clusters, ", "))
+	}
+}
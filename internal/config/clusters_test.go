@@ -0,0 +1,359 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClusterConfiguration(t *testing.T) {
+	// Save original environment
+	originalJSON := os.Getenv("TRINO_CLUSTERS_JSON")
+	originalFile := os.Getenv("TRINO_CLUSTERS_FILE")
+	originalDefault := os.Getenv("TRINO_DEFAULT_CLUSTER")
+	originalOAuth := os.Getenv("OAUTH_ENABLED")
+
+	// Clean up after test
+	defer func() {
+		_ = os.Setenv("TRINO_CLUSTERS_JSON", originalJSON)
+		_ = os.Setenv("TRINO_CLUSTERS_FILE", originalFile)
+		_ = os.Setenv("TRINO_DEFAULT_CLUSTER", originalDefault)
+		_ = os.Setenv("OAUTH_ENABLED", originalOAuth)
+	}()
+
+	tests := []struct {
+		name           string
+		clustersJSON   string
+		defaultCluster string
+		wantNames      []string
+		wantDefault    string
+		expectError    bool
+	}{
+		{
+			name:        "No clusters",
+			wantNames:   []string{DefaultClusterName},
+			wantDefault: DefaultClusterName,
+		},
+		{
+			name:         "Named clusters default to the first",
+			clustersJSON: `[{"name":"prod","host":"prod.example.com"},{"name":"staging","host":"staging.example.com"}]`,
+			wantNames:    []string{"prod", "staging"},
+			wantDefault:  "prod",
+		},
+		{
+			name:           "Explicit default cluster",
+			clustersJSON:   `[{"name":"prod","host":"prod.example.com"},{"name":"staging","host":"staging.example.com"}]`,
+			defaultCluster: "staging",
+			wantNames:      []string{"prod", "staging"},
+			wantDefault:    "staging",
+		},
+		{
+			name:           "Unknown default cluster",
+			clustersJSON:   `[{"name":"prod","host":"prod.example.com"}]`,
+			defaultCluster: "dev",
+			expectError:    true,
+		},
+		{
+			name:         "Duplicate names",
+			clustersJSON: `[{"name":"prod","host":"a"},{"name":"PROD","host":"b"}]`,
+			expectError:  true,
+		},
+		{
+			name:         "Missing host",
+			clustersJSON: `[{"name":"prod"}]`,
+			expectError:  true,
+		},
+		{
+			name:         "Missing name",
+			clustersJSON: `[{"host":"prod.example.com"}]`,
+			expectError:  true,
+		},
+		{
+			name:         "Not an array",
+			clustersJSON: `{"name":"prod","host":"prod.example.com"}`,
+			expectError:  true,
+		},
+		{
+			name:         "Malformed cluster allowlist",
+			clustersJSON: `[{"name":"prod","host":"a","allowedSchemas":["analytics"]}]`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Unsetenv("TRINO_CLUSTERS_JSON")
+			_ = os.Unsetenv("TRINO_CLUSTERS_FILE")
+			_ = os.Unsetenv("TRINO_DEFAULT_CLUSTER")
+			_ = os.Setenv("OAUTH_ENABLED", "false")
+
+			if tt.clustersJSON != "" {
+				_ = os.Setenv("TRINO_CLUSTERS_JSON", tt.clustersJSON)
+			}
+			if tt.defaultCluster != "" {
+				_ = os.Setenv("TRINO_DEFAULT_CLUSTER", tt.defaultCluster)
+			}
+
+			config, err := NewTrinoConfig()
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("NewTrinoConfig() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTrinoConfig() error = %v", err)
+			}
+
+			if names := config.ClusterNames(); !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("ClusterNames() = %v, want %v", names, tt.wantNames)
+			}
+			if config.DefaultCluster != tt.wantDefault {
+				t.Errorf("DefaultCluster = %q, want %q", config.DefaultCluster, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestLoadClustersFromFile(t *testing.T) {
+	originalJSON := os.Getenv("TRINO_CLUSTERS_JSON")
+	originalFile := os.Getenv("TRINO_CLUSTERS_FILE")
+	defer func() {
+		_ = os.Setenv("TRINO_CLUSTERS_JSON", originalJSON)
+		_ = os.Setenv("TRINO_CLUSTERS_FILE", originalFile)
+	}()
+
+	path := filepath.Join(t.TempDir(), "clusters.json")
+	content := `[{"name":"prod","host":"prod.example.com","user":"svc","passwordEnv":"TEST_PROD_PASSWORD"}]`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_PROD_PASSWORD", "secret")
+
+	_ = os.Unsetenv("TRINO_CLUSTERS_JSON")
+	_ = os.Setenv("TRINO_CLUSTERS_FILE", path)
+	clusters, err := loadClusters()
+	if err != nil {
+		t.Fatalf("loadClusters() error = %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].Name != "prod" {
+		t.Fatalf("loadClusters() = %+v, want one cluster named prod", clusters)
+	}
+	if clusters[0].Password != "secret" {
+		t.Errorf("Password = %q, want value of passwordEnv", clusters[0].Password)
+	}
+
+	// The password can come from a mounted secret file
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fileContent := `[{"name":"prod","host":"prod.example.com","passwordFile":"` + passwordFile + `"}]`
+	if err := os.WriteFile(path, []byte(fileContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if clusters, err = loadClusters(); err != nil || clusters[0].Password != "from-file" {
+		t.Errorf("loadClusters() = %+v, %v; want the password from passwordFile", clusters, err)
+	}
+
+	// Both sources at once are ambiguous
+	_ = os.Setenv("TRINO_CLUSTERS_JSON", content)
+	if _, err := loadClusters(); err == nil {
+		t.Error("loadClusters() expected error when both JSON and file are set")
+	}
+}
+
+func TestForCluster(t *testing.T) {
+	ssl := false
+	allowWrites := true
+	base := &TrinoConfig{
+		Host:            "localhost",
+		Port:            8080,
+		User:            "admin",
+		Password:        "base-secret",
+		Catalog:         "memory",
+		Schema:          "default",
+		Scheme:          "https",
+		SSL:             true,
+		QueryTimeout:    30 * time.Second,
+		AllowedCatalogs: []string{"memory"},
+		Role:            "analyst",
+		DefaultCluster:  "prod",
+		Clusters: []ClusterConfig{
+			{Name: "prod", Host: "prod.example.com", Port: 443, Catalog: "hive"},
+			{
+				Name: "dev", Host: "dev.example.com", User: "dev", Scheme: "http", SSL: &ssl,
+				AllowWriteQueries: &allowWrites, QueryTimeout: 5, AllowedCatalogs: []string{"iceberg"},
+				Role: "developer", CatalogRoles: map[string]string{"Hive": "admin"},
+			},
+		},
+	}
+
+	prod, err := base.ForCluster("PROD")
+	if err != nil {
+		t.Fatalf("ForCluster(prod) error = %v", err)
+	}
+	if prod.ClusterName != "prod" || prod.Host != "prod.example.com" || prod.Port != 443 {
+		t.Errorf("prod connection = %s %s:%d", prod.ClusterName, prod.Host, prod.Port)
+	}
+	if prod.Catalog != "hive" || prod.Schema != "default" {
+		t.Errorf("prod catalog/schema = %s/%s, want hive/default", prod.Catalog, prod.Schema)
+	}
+	if prod.User != "admin" || prod.Password != "base-secret" {
+		t.Errorf("prod should inherit top-level credentials, got user %q", prod.User)
+	}
+	if prod.Role != "analyst" || prod.CatalogRoles != nil {
+		t.Errorf("prod roles = %q %v, want the top-level role", prod.Role, prod.CatalogRoles)
+	}
+	if prod.Clusters != nil {
+		t.Error("derived config should not carry the cluster list")
+	}
+
+	dev, err := base.ForCluster("dev")
+	if err != nil {
+		t.Fatalf("ForCluster(dev) error = %v", err)
+	}
+	if dev.User != "dev" || dev.Password != "" {
+		t.Errorf("dev should not inherit the top-level password, got user %q password %q", dev.User, dev.Password)
+	}
+	if dev.SSL || dev.Scheme != "http" {
+		t.Errorf("dev SSL = %v scheme = %s, want false/http", dev.SSL, dev.Scheme)
+	}
+	if !dev.AllowWriteQueries || dev.QueryTimeout != 5*time.Second {
+		t.Errorf("dev writes = %v timeout = %v", dev.AllowWriteQueries, dev.QueryTimeout)
+	}
+	if !reflect.DeepEqual(dev.AllowedCatalogs, []string{"iceberg"}) {
+		t.Errorf("dev AllowedCatalogs = %v, want [iceberg]", dev.AllowedCatalogs)
+	}
+	if dev.Role != "developer" || !reflect.DeepEqual(dev.CatalogRoles, map[string]string{"hive": "admin"}) {
+		t.Errorf("dev roles = %q %v, want developer and hive=admin", dev.Role, dev.CatalogRoles)
+	}
+
+	// The base config is left untouched
+	if base.Host != "localhost" || base.AllowWriteQueries {
+		t.Error("ForCluster modified the base configuration")
+	}
+
+	if _, err := base.ForCluster("missing"); err == nil {
+		t.Error("ForCluster(missing) expected error")
+	}
+
+	single := &TrinoConfig{Host: "localhost"}
+	if cfg, err := single.ForCluster(DefaultClusterName); err != nil || cfg != single {
+		t.Errorf("ForCluster(default) without clusters = %v, %v; want the config itself", cfg, err)
+	}
+}
+
+func TestRoutingConfiguration(t *testing.T) {
+	envVars := []string{
+		"TRINO_CLUSTERS_JSON", "TRINO_ROUTING_POLICY", "TRINO_ROUTING_CLUSTERS",
+		"TRINO_HEALTH_CHECK_INTERVAL", "TRINO_CIRCUIT_BREAKER_THRESHOLD", "TRINO_CIRCUIT_BREAKER_COOLDOWN",
+	}
+	original := make(map[string]string)
+	for _, name := range envVars {
+		original[name] = os.Getenv(name)
+	}
+	defer func() {
+		for name, value := range original {
+			_ = os.Setenv(name, value)
+		}
+	}()
+
+	clustersJSON := `[{"name":"prod-a","host":"a.example.com"},{"name":"prod-b","host":"b.example.com"}]`
+	tests := []struct {
+		name          string
+		env           map[string]string
+		wantPolicy    string
+		wantClusters  []string
+		wantInterval  time.Duration
+		wantThreshold int
+		wantCooldown  time.Duration
+		expectError   bool
+	}{
+		{
+			name:          "Defaults",
+			wantPolicy:    RoutingNone,
+			wantInterval:  30 * time.Second,
+			wantThreshold: 3,
+			wantCooldown:  30 * time.Second,
+		},
+		{
+			name: "Round robin with custom settings",
+			env: map[string]string{
+				"TRINO_ROUTING_POLICY":            "Round-Robin",
+				"TRINO_ROUTING_CLUSTERS":          "prod-b,prod-a",
+				"TRINO_HEALTH_CHECK_INTERVAL":     "0",
+				"TRINO_CIRCUIT_BREAKER_THRESHOLD": "5",
+				"TRINO_CIRCUIT_BREAKER_COOLDOWN":  "60",
+			},
+			wantPolicy:    RoutingRoundRobin,
+			wantClusters:  []string{"prod-b", "prod-a"},
+			wantInterval:  0,
+			wantThreshold: 5,
+			wantCooldown:  time.Minute,
+		},
+		{
+			name: "Invalid numbers fall back to defaults",
+			env: map[string]string{
+				"TRINO_ROUTING_POLICY":            "failover",
+				"TRINO_HEALTH_CHECK_INTERVAL":     "-1",
+				"TRINO_CIRCUIT_BREAKER_THRESHOLD": "many",
+			},
+			wantPolicy:    RoutingFailover,
+			wantInterval:  30 * time.Second,
+			wantThreshold: 3,
+			wantCooldown:  30 * time.Second,
+		},
+		{
+			name:        "Unknown policy",
+			env:         map[string]string{"TRINO_ROUTING_POLICY": "random"},
+			expectError: true,
+		},
+		{
+			name:        "Unknown routing cluster",
+			env:         map[string]string{"TRINO_ROUTING_POLICY": "failover", "TRINO_ROUTING_CLUSTERS": "prod-a,prod-c"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range envVars {
+				_ = os.Unsetenv(name)
+			}
+			_ = os.Setenv("TRINO_CLUSTERS_JSON", clustersJSON)
+			for name, value := range tt.env {
+				_ = os.Setenv(name, value)
+			}
+
+			config, err := NewTrinoConfig()
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("NewTrinoConfig() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTrinoConfig() error = %v", err)
+			}
+
+			if config.RoutingPolicy != tt.wantPolicy {
+				t.Errorf("RoutingPolicy = %q, want %q", config.RoutingPolicy, tt.wantPolicy)
+			}
+			if !reflect.DeepEqual(config.RoutingClusters, tt.wantClusters) {
+				t.Errorf("RoutingClusters = %v, want %v", config.RoutingClusters, tt.wantClusters)
+			}
+			if config.HealthCheckInterval != tt.wantInterval {
+				t.Errorf("HealthCheckInterval = %v, want %v", config.HealthCheckInterval, tt.wantInterval)
+			}
+			if config.BreakerThreshold != tt.wantThreshold {
+				t.Errorf("BreakerThreshold = %d, want %d", config.BreakerThreshold, tt.wantThreshold)
+			}
+			if config.BreakerCooldown != tt.wantCooldown {
+				t.Errorf("BreakerCooldown = %v, want %v", config.BreakerCooldown, tt.wantCooldown)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Response encodings accepted from Trino, selected with TRINO_COMPRESSION
+const (
+	CompressionZstd = "zstd"
+	CompressionGzip = "gzip"
+	CompressionNone = "identity" // Uncompressed responses, for CPU-bound or already fast links
+)
+
+// parseCompression reads TRINO_COMPRESSION: the encodings Trino may compress
+// responses with, in order of preference, or none
+func parseCompression() ([]string, error) {
+	encodings := parseAllowlist(strings.ToLower(getEnv("TRINO_COMPRESSION", "zstd,gzip")))
+	for i, encoding := range encodings {
+		switch encoding {
+		case CompressionZstd, CompressionGzip:
+		case "none", CompressionNone:
+			if len(encodings) > 1 {
+				return nil, fmt.Errorf("invalid TRINO_COMPRESSION: none cannot be combined with other encodings")
+			}
+			log.Println("INFO: Trino response compression disabled (TRINO_COMPRESSION=none)")
+			return []string{CompressionNone}, nil
+		default:
+			return nil, fmt.Errorf("invalid TRINO_COMPRESSION encoding '%s': must be zstd, gzip or none", encoding)
+		}
+		if containsFold(encodings[:i], encoding) {
+			return nil, fmt.Errorf("invalid TRINO_COMPRESSION: encoding '%s' is listed twice", encoding)
+		}
+	}
+	return encodings, nil
+}
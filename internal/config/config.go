@@ -3,26 +3,98 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// DefaultResultSpillBytes is the encoded execute_query output kept in memory
+// before it is spilled to a temp file, when TRINO_RESULT_SPILL_BYTES is unset
+const DefaultResultSpillBytes = 8 << 20
+
+// Encodings of DECIMAL and BIGINT values in execute_query results, set by
+// TRINO_NUMBER_ENCODING
+const (
+	NumberEncodingDefault = "default" // DECIMAL as strings, BIGINT as numbers
+	NumberEncodingString  = "string"  // DECIMAL and BIGINT as strings, which no JSON parser rounds
+	NumberEncodingFloat   = "float"   // DECIMAL and BIGINT as numbers, which parsers may round to a double
+)
+
+// Representations of NULL in execute_query results, set by TRINO_NULL_VALUE;
+// any other value is used as a sentinel string
+const (
+	NullValueNull  = "null"  // JSON null, and NULL in the text formats
+	NullValueEmpty = "empty" // Empty string
+)
+
+// Handling of information_schema in the queries agents write, set by
+// TRINO_INFORMATION_SCHEMA
+const (
+	InformationSchemaFilter = "filter" // Rows of objects outside the allowlists are filtered out
+	InformationSchemaBlock  = "block"  // Queries of information_schema are rejected
+	InformationSchemaAllow  = "allow"  // Queries of information_schema run unchanged
 )
 
 // TrinoConfig holds Trino connection parameters
 type TrinoConfig struct {
 	// Basic connection parameters
-	Host              string
-	Port              int
-	User              string
-	Password          string
-	Catalog           string
-	Schema            string
-	Scheme            string
-	SSL               bool
-	SSLInsecure       bool
-	AllowWriteQueries bool          // Controls whether non-read-only SQL queries are allowed
-	QueryTimeout      time.Duration // Query execution timeout
+	Host               string
+	Port               int
+	User               string
+	Password           string
+	AccessToken        string // JWT sent to Trino as a bearer token (Trino JWT authentication)
+	Catalog            string
+	Schema             string
+	Scheme             string
+	SSL                bool
+	SSLInsecure        bool
+	AllowWriteQueries  bool          // Controls whether non-read-only SQL queries are allowed
+	ReadOnly           bool          // TRINO_READ_ONLY: no write, procedure or session statement runs, whatever else is configured
+	QueryTimeout       time.Duration // Query execution timeout
+	MaxQueryTimeout    time.Duration // Longest timeout a tool call may request, at least QueryTimeout
+	MaxResultRows      int           // Maximum rows returned by execute_query (0 means unlimited)
+	MaxResultBytes     int64         // Maximum approximate result size in bytes (0 means unlimited)
+	ResultSpillBytes   int64         // Encoded execute_query output kept in memory before it moves to a temp file (0 never spills)
+	ResultMemoryBudget int64         // Encoded execute_query output all concurrent calls may hold in memory (0 means unlimited)
+	NumberEncoding     string        // How execute_query encodes DECIMAL and BIGINT values (see NumberEncoding* constants)
+	NullValue          string        // How execute_query represents NULL: NullValueNull, NullValueEmpty or a sentinel string
+	MaxCellChars       int           // Characters of a string value execute_query returns before truncating it (0 means unlimited)
+
+	// Checks and rewrites of the queries agents write
+	SelectStarMaxColumns int    // SELECT * on a table with more visible columns is rejected (0 means unlimited)
+	SelectStarExpand     bool   // SELECT * is rewritten into the table's explicit column list
+	InformationSchema    string // How information_schema queries are handled (see InformationSchema* constants)
+
+	// HTTP transport of Trino connections
+	ProxyURL         string            // Egress proxy from TRINO_PROXY_URL; empty uses HTTPS_PROXY and NO_PROXY
+	Compression      []string          // Accept-Encoding preference for Trino responses; nil leaves gzip to the transport
+	HTTPHeaders      map[string]string // Headers a gateway in front of Trino requires, e.g. X-Trino-Routing-Group
+	Gateway          bool              // Trino Gateway in front of the backends: keep credentials on its redirects, retry on another backend
+	RoutingGroup     string            // Trino Gateway routing group (X-Trino-Routing-Group) of every query
+	Spooling         Spooling          // Segment download of results under the spooling protocol
+	ConnectionMaxAge time.Duration     // Idle connections are closed this often so new ones re-resolve the coordinator (0 disables)
+	RecordDir        string            // Trino HTTP exchanges are saved here as fixtures
+	ReplayDir        string            // Trino HTTP exchanges are answered from the fixtures here instead of the network
+
+	// Per-client rate limits in HTTP mode (0 means unlimited)
+	RateLimitRequestsPerMinute int // MCP requests per minute per client
+	RateLimitQueriesPerHour    int // Query tool calls per hour per client
+
+	// Per-client daily quotas (0 means unlimited); days start at midnight UTC
+	QuotaQueriesPerDay      int    // Query tool calls per day per client
+	QuotaScannedBytesPerDay int64  // Bytes Trino may scan per day per client
+	QuotaFile               string // File the day's usage is kept in across restarts (empty keeps it in memory)
+
+	// Operator debugging: /debug/pprof in HTTP mode and the server_stats tool
+	DebugEnabled bool   // Expose the debug endpoints and tool
+	DebugToken   string // Bearer token /debug/pprof requires (empty leaves it open)
+	DebugLogging bool   // Log tool calls, SQL and Trino HTTP exchanges from startup; toggled at runtime
 
 	// OAuth mode configuration
 	OAuthEnabled  bool   // Enable OAuth 2.1 authentication
@@ -42,18 +114,155 @@ type TrinoConfig struct {
 	AllowedSchemas  []string // List of allowed schemas in catalog.schema format
 	AllowedTables   []string // List of allowed tables in catalog.schema.table format
 
+	// Column masks keyed by lower-case catalog.schema.table.column (see Mask* actions)
+	ColumnMasks map[string]string
+
+	// Queries matching any of these patterns (after comment and literal removal) are rejected
+	BlockedQueryPatterns []*regexp.Regexp
+
+	// Tables, by lower-case catalog.schema.table, whose queries must filter on one of the listed partition columns
+	RequiredPartitionFilters map[string][]string
+
+	// Queries referencing tables of more than one catalog, or exceeding a
+	// complexity limit (0 means unlimited), are rejected
+	BlockCrossCatalogQueries bool
+	MaxQueryTables           int // Distinct tables referenced
+	MaxQueryLength           int // Characters of the query text
+	MaxQueryJoins            int // JOIN clauses
+	MaxSubqueryDepth         int // Nesting of subqueries, including those of WITH clauses
+
+	// Procedures, as lower-case schema.procedure or catalog.schema.procedure, that call_procedure may run even without write queries
+	AllowedProcedures []string
+
 	// Impersonation configuration
 	EnableImpersonation bool   // Enable Trino user impersonation via X-Trino-User header
 	ImpersonationField  string // JWT field to use for impersonation: "username", "email", or "subject" (default: "username")
 
+	// Refuse tool calls without an authenticated user to impersonate, instead
+	// of running them as TRINO_USER
+	ImpersonationRequired bool
+
 	// Query attribution
-	TrinoSource string // Value for X-Trino-Source header (identifies query source to Trino)
+	TrinoSource string   // Value for X-Trino-Source header (identifies query source to Trino)
+	ClientTags  []string // X-Trino-Client-Tags added to every query, for resource group selectors and chargeback
+
+	// Session time zone sent as X-Trino-Time-Zone; timestamps without time zone are reported in it (UTC when empty)
+	SessionTimeZone string
+
+	// Roles enabled for every query via X-Trino-Role: a role name, "all" or "none"
+	Role         string            // Role of the system access control (catalog "system")
+	CatalogRoles map[string]string // Connector roles by catalog, such as Hive roles
+
+	// Settings of individual catalogs from TRINO_CATALOGS_JSON or TRINO_CATALOGS_FILE, by lower-case name
+	Catalogs map[string]CatalogConfig
+
+	// Trino authentication method: AuthPassword, AuthJWT, AuthExternal or empty (whatever credentials are set)
+	Auth        string
+	AuthMethods []string // Methods of TRINO_AUTH in the order they are tried; Auth is the one in use
+
+	// Credentials as configured; ForAuth copies those of the selected method to Password and AccessToken
+	password string
+	jwt      string
+	jwtErr   error // Why the JWT could not be loaded, when another method may be used instead
 
 	// External authentication (Trino's browser OAuth flow)
-	ExternalAuth        bool // Enable Trino external authentication (browser OAuth)
-	ExternalAuthTimeout int  // Timeout in seconds for external auth flow (default: 300)
+	ExternalAuth         bool // Enable Trino external authentication (browser OAuth)
+	ExternalAuthTimeout  int  // Timeout in seconds for external auth flow (default: 300)
+	ExternalAuthHeadless bool // Return the login URL to the MCP client instead of opening a browser
+
+	// Wait between polls of Trino's token server (default: 5s); Retry-After
+	// and server errors lengthen it
+	ExternalAuthPollInterval time.Duration
+
+	// How the external auth token is obtained: trino (default) or pkce, which
+	// logs in with ExternalAuthIdP directly
+	ExternalAuthFlow string
+	ExternalAuthIdP  *ExternalAuthIdP
+
+	// Tool exposure configuration
+	EnabledTools  []string // MCP tools to register (empty means all tools)
+	DisabledTools []string // MCP tools to hide, applied after EnabledTools
+
+	// OpenTelemetry tracing
+	TracingEnabled     bool   // Export spans via OTLP (endpoint from OTEL_EXPORTER_OTLP_* variables)
+	TracingServiceName string // Service name reported on spans (default: mcp-trino)
+
+	// export_query destinations
+	ExportDir         string   // Directory local exports are confined to
+	ExportAllowedURIs []string // s3:// or gs:// prefixes remote exports may target (empty disables remote exports)
+	ExportGCSKeyID    string   // HMAC access key for gs:// exports
+	ExportGCSSecret   string   // HMAC secret for gs:// exports
+
+	// Named clusters; when set, the top-level connection settings only provide defaults
+	Clusters       []ClusterConfig
+	DefaultCluster string // Cluster used when a tool call names none
+	ClusterName    string // Set on per-cluster configurations returned by ForCluster
+
+	// Cluster routing and failover; inactive when RoutingPolicy is RoutingNone
+	RoutingPolicy       string        // RoutingNone, RoutingFailover or RoutingRoundRobin
+	RoutingClusters     []string      // Clusters eligible for routing in priority order (empty means all)
+	HealthCheckInterval time.Duration // Interval between coordinator health checks (0 disables)
+	BreakerThreshold    int           // Consecutive failures that open a cluster's circuit (0 disables)
+	BreakerCooldown     time.Duration // Time an open circuit waits before letting a trial request through
+
+	// Warm-up and keepalive pings (SELECT 1), reported by list_clusters and /status
+	WarmUp            bool          // Ping every cluster once at startup
+	KeepaliveInterval time.Duration // Interval between pings (0 disables)
+
+	// Table schema resources: how often subscribed tables are checked for column changes
+	SchemaPollInterval time.Duration // 0 disables resource subscriptions
+
+	// External query authorization via Open Policy Agent
+	OPAURL      string        // OPA decision endpoint (empty disables the check)
+	OPATimeout  time.Duration // Timeout of each decision request
+	OPAFailOpen bool          // Allow queries when OPA cannot be reached instead of rejecting them
+
+	// dbt project metadata for the model tools
+	DBTManifest string // Path or http(s) URL of a dbt manifest.json (empty disables the model tools)
+
+	// Data catalog whose descriptions, owners and tags are merged into schema output
+	DataCatalog        string        // DataCatalogDataHub, DataCatalogAmundsen or DataCatalogOpenMetadata (empty disables)
+	DataCatalogURL     string        // Base URL of the data catalog API
+	DataCatalogToken   string        // Bearer token for the data catalog API
+	DataCatalogService string        // Name Trino is registered under in the data catalog
+	DataCatalogEnv     string        // DataHub environment (fabric) of the datasets
+	DataCatalogTimeout time.Duration // Timeout of each data catalog request
+
+	// Chat notifications about slow and failed queries
+	NotifyWebhookURL string        // Slack or Teams incoming webhook (empty disables notifications)
+	NotifyFormat     string        // NotifyFormatSlack or NotifyFormatTeams
+	NotifySlowQuery  time.Duration // Queries running at least this long are reported (0 disables)
+	NotifyErrors     []string      // Error types or names of failed queries that are reported
+
+	// Server log of slow queries
+	SlowQueryLog time.Duration // Queries running at least this long are logged (0 disables)
+
+	// Audit events of every query, written to a Trino table or a Kafka topic
+	AuditTable        string // Table events are inserted into, as catalog.schema.table (empty disables)
+	AuditUser         string // User of the connection that inserts events (empty uses TRINO_USER)
+	AuditPassword     string // Password of that user
+	AuditKafkaRESTURL string // Kafka REST Proxy events are produced through (empty disables)
+	AuditKafkaTopic   string // Topic events are produced to
+
+	// Operational admin_* tools, authorized separately from the analyst-facing tools
+	AdminEnabled bool     // Register the admin tools
+	AdminAPIKey  string   // Key HTTP clients send in X-MCP-Admin-Key to use them (empty accepts no key)
+	AdminUsers   []string // OAuth users (username, email or subject) allowed to use them
 }
 
+// Supported data catalogs
+const (
+	DataCatalogDataHub      = "datahub"
+	DataCatalogAmundsen     = "amundsen"
+	DataCatalogOpenMetadata = "openmetadata"
+)
+
+// Message formats of query notifications
+const (
+	NotifyFormatSlack = "slack" // Slack incoming webhook payload
+	NotifyFormatTeams = "teams" // Microsoft Teams incoming webhook payload
+)
+
 // NewTrinoConfig creates a new TrinoConfig with values from environment variables or defaults
 func NewTrinoConfig() (*TrinoConfig, error) {
 	return NewTrinoConfigWithVersion("dev")
@@ -61,23 +270,46 @@ func NewTrinoConfig() (*TrinoConfig, error) {
 
 // NewTrinoConfigWithVersion creates a new TrinoConfig with a specific version for X-Trino-Source
 func NewTrinoConfigWithVersion(version string) (*TrinoConfig, error) {
+	// A profile fills in the settings the environment leaves unset
+	if err := applyProfile(); err != nil {
+		return nil, err
+	}
+
 	port, _ := strconv.Atoi(getEnv("TRINO_PORT", "8080"))
 	ssl, _ := strconv.ParseBool(getEnv("TRINO_SSL", "true"))
 	sslInsecure, _ := strconv.ParseBool(getEnv("TRINO_SSL_INSECURE", "true"))
 	scheme := getEnv("TRINO_SCHEME", "https")
 	allowWriteQueries, _ := strconv.ParseBool(getEnv("TRINO_ALLOW_WRITE_QUERIES", "false"))
+	readOnly, _ := strconv.ParseBool(getEnv("TRINO_READ_ONLY", "false"))
 
 	// OAuth configuration - OAUTH_ENABLED is the single source of truth
 	oauthEnabled, _ := strconv.ParseBool(getEnv("OAUTH_ENABLED", "false"))
 	oauthMode := strings.ToLower(getEnv("OAUTH_MODE", "native"))
 	oauthProvider := strings.ToLower(getEnv("OAUTH_PROVIDER", "hmac"))
-	jwtSecret := getEnv("JWT_SECRET", "")
+
+	// Secrets may also be mounted as files (Kubernetes or Docker secrets) via *_FILE
+	secrets, err := loadSecrets("TRINO_PASSWORD", "JWT_SECRET", "OIDC_CLIENT_SECRET",
+		"TRINO_DATA_CATALOG_TOKEN", "TRINO_EXPORT_GCS_SECRET_ACCESS_KEY", "TRINO_HTTP_HEADERS",
+		"MCP_DEBUG_TOKEN", "TRINO_AUDIT_PASSWORD", "MCP_ADMIN_API_KEY")
+	if err != nil {
+		return nil, err
+	}
+	authMethods, err := parseAuthMethods()
+	if err != nil {
+		return nil, err
+	}
+	// An unusable JWT only fails startup when no other method can be tried
+	accessToken, jwtErr := loadJWT()
+	if jwtErr != nil && !(len(authMethods) > 1 && containsFold(authMethods, AuthJWT)) {
+		return nil, jwtErr
+	}
+	jwtSecret := secrets["JWT_SECRET"]
 
 	// OIDC configuration with secure defaults
 	oidcIssuer := getEnv("OIDC_ISSUER", "")
 	oidcAudience := getEnv("OIDC_AUDIENCE", "") // No default - must be explicitly configured
 	oidcClientID := getEnv("OIDC_CLIENT_ID", "")
-	oidcClientSecret := getEnv("OIDC_CLIENT_SECRET", "")
+	oidcClientSecret := secrets["OIDC_CLIENT_SECRET"]
 
 	// Redirect URI configuration with backward compatibility
 	oauthRedirectURIs := getEnv("OAUTH_ALLOWED_REDIRECT_URIS", "")
@@ -106,21 +338,75 @@ func NewTrinoConfigWithVersion(version string) (*TrinoConfig, error) {
 
 	queryTimeout := time.Duration(timeoutInt) * time.Second
 
-	// Parse allowlist configuration
-	allowedCatalogs := parseAllowlist(getEnv("TRINO_ALLOWED_CATALOGS", ""))
-	allowedSchemas := parseAllowlist(getEnv("TRINO_ALLOWED_SCHEMAS", ""))
-	allowedTables := parseAllowlist(getEnv("TRINO_ALLOWED_TABLES", ""))
+	// Parse the longest timeout execute_query callers may ask for
+	maxTimeoutStr := getEnv("TRINO_MAX_QUERY_TIMEOUT", strconv.Itoa(timeoutInt))
+	maxTimeoutInt, err := strconv.Atoi(maxTimeoutStr)
+	switch {
+	case err != nil:
+		log.Printf("WARNING: Invalid TRINO_MAX_QUERY_TIMEOUT '%s': not an integer. Using TRINO_QUERY_TIMEOUT of %d seconds", maxTimeoutStr, timeoutInt)
+		maxTimeoutInt = timeoutInt
+	case maxTimeoutInt < timeoutInt:
+		log.Printf("WARNING: TRINO_MAX_QUERY_TIMEOUT '%d' is below TRINO_QUERY_TIMEOUT. Using %d seconds", maxTimeoutInt, timeoutInt)
+		maxTimeoutInt = timeoutInt
+	}
+	maxQueryTimeout := time.Duration(maxTimeoutInt) * time.Second
+
+	// Parse allowlists and result size limits (reloadable via TRINO_POLICY_FILE)
+	policy, err := loadPolicy()
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse impersonation configuration
 	enableImpersonation, _ := strconv.ParseBool(getEnv("TRINO_ENABLE_IMPERSONATION", "false"))
 	impersonationField := strings.ToLower(getEnv("TRINO_IMPERSONATION_FIELD", "username"))
+	impersonationRequired, _ := strconv.ParseBool(getEnv("TRINO_IMPERSONATION_REQUIRED", "false"))
+	if impersonationRequired && !(enableImpersonation && oauthEnabled) {
+		return nil, fmt.Errorf("TRINO_IMPERSONATION_REQUIRED requires TRINO_ENABLE_IMPERSONATION=true and OAUTH_ENABLED=true")
+	}
 
-	// Parse Trino source configuration with default
-	trinoSource := getEnv("TRINO_SOURCE", fmt.Sprintf("mcp-trino/%s", version))
+	// Parse Trino source configuration with default; TRINO_QUERY_SOURCE takes
+	// precedence over the older TRINO_SOURCE
+	trinoSource := getEnv("TRINO_QUERY_SOURCE", getEnv("TRINO_SOURCE", fmt.Sprintf("mcp-trino/%s", version)))
 	if trinoSource == "" {
 		// If explicitly set to empty, use default
 		trinoSource = fmt.Sprintf("mcp-trino/%s", version)
 	}
+	clientTags := parseAllowlist(getEnv("TRINO_CLIENT_TAGS", ""))
+
+	// Parse the session time zone (empty leaves Trino's default)
+	sessionTimeZone := strings.TrimSpace(getEnv("TRINO_SESSION_TIMEZONE", ""))
+	if sessionTimeZone != "" {
+		if _, err := LoadTimeZone(sessionTimeZone); err != nil {
+			return nil, fmt.Errorf("invalid TRINO_SESSION_TIMEZONE: %w", err)
+		}
+	}
+
+	// Parse roles enabled for the server's queries
+	role := strings.TrimSpace(getEnv("TRINO_ROLE", ""))
+	catalogRoles, err := parseCatalogRoles(getEnv("TRINO_CATALOG_ROLES", ""))
+	if err != nil {
+		return nil, err
+	}
+	catalogs, err := loadCatalogs()
+	if err != nil {
+		return nil, err
+	}
+	httpHeaders, err := parseHTTPHeaders(secrets["TRINO_HTTP_HEADERS"])
+	if err != nil {
+		return nil, err
+	}
+	gateway, _ := strconv.ParseBool(getEnv("TRINO_GATEWAY", "false"))
+	routingGroup := strings.TrimSpace(getEnv("TRINO_ROUTING_GROUP", ""))
+	if strings.ContainsFunc(routingGroup, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return nil, fmt.Errorf("invalid TRINO_ROUTING_GROUP: control characters are not allowed")
+	}
+	if gateway {
+		log.Println("INFO: Trino Gateway mode enabled (TRINO_GATEWAY=true)")
+	}
+	if routingGroup != "" {
+		log.Printf("INFO: Trino Gateway routing group: %s", routingGroup)
+	}
 
 	// Parse external authentication configuration
 	externalAuth, _ := strconv.ParseBool(getEnv("TRINO_EXTERNAL_AUTH", "false"))
@@ -130,12 +416,243 @@ func NewTrinoConfigWithVersion(version string) (*TrinoConfig, error) {
 		log.Printf("WARNING: Invalid TRINO_EXTERNAL_AUTH_TIMEOUT, using default of 300 seconds")
 		externalAuthTimeout = 300
 	}
+	externalAuthHeadless, _ := strconv.ParseBool(getEnv("TRINO_EXTERNAL_AUTH_HEADLESS", "false"))
+	externalAuthPollInterval := parseSeconds("TRINO_EXTERNAL_AUTH_POLL_INTERVAL", 5)
+	if externalAuthPollInterval == 0 {
+		log.Printf("WARNING: TRINO_EXTERNAL_AUTH_POLL_INTERVAL must be at least 1 second, using default of 5 seconds")
+		externalAuthPollInterval = 5 * time.Second
+	}
+	externalAuthFlow := strings.ToLower(getEnv("TRINO_EXTERNAL_AUTH_FLOW", ExternalAuthFlowTrino))
+	var externalAuthIdP *ExternalAuthIdP
+	switch externalAuthFlow {
+	case ExternalAuthFlowTrino:
+	case ExternalAuthFlowPKCE:
+		if externalAuthIdP, err = parseExternalAuthIdP(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid TRINO_EXTERNAL_AUTH_FLOW '%s': must be trino or pkce", externalAuthFlow)
+	}
+	if externalAuth {
+		if len(authMethods) > 0 && !(len(authMethods) == 1 && authMethods[0] == AuthExternal) {
+			return nil, fmt.Errorf("TRINO_EXTERNAL_AUTH cannot be combined with TRINO_AUTH=%s; add external to TRINO_AUTH instead", strings.Join(authMethods, ","))
+		}
+		authMethods = []string{AuthExternal}
+	}
+	// A single method must not be given credentials of another
+	if len(authMethods) == 1 {
+		switch {
+		case authMethods[0] == AuthPassword && accessToken != "":
+			return nil, fmt.Errorf("TRINO_AUTH=password cannot be combined with TRINO_JWT")
+		case authMethods[0] == AuthJWT && secrets["TRINO_PASSWORD"] != "":
+			return nil, fmt.Errorf("TRINO_AUTH=jwt cannot be combined with TRINO_PASSWORD")
+		case authMethods[0] == AuthExternal && accessToken != "":
+			return nil, fmt.Errorf("set only one of TRINO_JWT and TRINO_EXTERNAL_AUTH")
+		}
+	}
+	if len(authMethods) == 0 && secrets["TRINO_PASSWORD"] != "" && !strings.EqualFold(scheme, "https") {
+		log.Printf("WARNING: TRINO_PASSWORD is not sent over %s; set TRINO_SCHEME=https", scheme)
+	}
+
+	// Parse tool exposure configuration
+	enabledTools := parseAllowlist(getEnv("TRINO_ENABLED_TOOLS", ""))
+	disabledTools := parseAllowlist(getEnv("TRINO_DISABLED_TOOLS", ""))
+
+	// Parse tracing configuration
+	tracingEnabled, _ := strconv.ParseBool(getEnv("OTEL_TRACING_ENABLED", "false"))
+	tracingServiceName := getEnv("OTEL_SERVICE_NAME", "mcp-trino")
+
+	// Parse the size beyond which encoded results are spilled to a temp file, and
+	// the memory all concurrent results may buffer (0 disables the budget)
+	resultSpillBytes, err := strconv.ParseInt(getEnv("TRINO_RESULT_SPILL_BYTES", strconv.Itoa(DefaultResultSpillBytes)), 10, 64)
+	if err != nil || resultSpillBytes < 0 {
+		log.Printf("WARNING: Invalid TRINO_RESULT_SPILL_BYTES, using default of %d bytes", DefaultResultSpillBytes)
+		resultSpillBytes = DefaultResultSpillBytes
+	}
 
-	// Validate allowlist formats
-	if err := validateAllowlist("TRINO_ALLOWED_SCHEMAS", allowedSchemas, 1); err != nil { // Must have catalog.schema format
+	resultMemoryBudget, err := strconv.ParseInt(getEnv("TRINO_RESULT_MEMORY_BUDGET", "0"), 10, 64)
+	if err != nil || resultMemoryBudget < 0 {
+		log.Printf("WARNING: Invalid TRINO_RESULT_MEMORY_BUDGET, result memory will not be limited")
+		resultMemoryBudget = 0
+	}
+
+	// Parse how DECIMAL and BIGINT values are encoded in results
+	numberEncoding := strings.ToLower(strings.TrimSpace(getEnv("TRINO_NUMBER_ENCODING", NumberEncodingDefault)))
+	if numberEncoding == "" {
+		numberEncoding = NumberEncodingDefault
+	}
+	switch numberEncoding {
+	case NumberEncodingDefault, NumberEncodingString, NumberEncodingFloat:
+	default:
+		return nil, fmt.Errorf("invalid TRINO_NUMBER_ENCODING '%s': must be default, string or float", numberEncoding)
+	}
+
+	// Parse how NULLs and long strings are represented in results
+	nullValue := getEnv("TRINO_NULL_VALUE", NullValueNull)
+	if nullValue == "" {
+		nullValue = NullValueNull
+	}
+	maxCellChars, err := strconv.Atoi(getEnv("TRINO_MAX_CELL_CHARS", "0"))
+	if err != nil || maxCellChars < 0 {
+		log.Printf("WARNING: Invalid TRINO_MAX_CELL_CHARS, values will not be truncated")
+		maxCellChars = 0
+	}
+	selectStarMaxColumns, err := strconv.Atoi(getEnv("TRINO_SELECT_STAR_MAX_COLUMNS", "0"))
+	if err != nil || selectStarMaxColumns < 0 {
+		log.Printf("WARNING: Invalid TRINO_SELECT_STAR_MAX_COLUMNS, SELECT * will not be limited")
+		selectStarMaxColumns = 0
+	}
+	selectStarExpand, _ := strconv.ParseBool(getEnv("TRINO_SELECT_STAR_EXPAND", "false"))
+	informationSchema := strings.ToLower(strings.TrimSpace(getEnv("TRINO_INFORMATION_SCHEMA", InformationSchemaFilter)))
+	switch informationSchema {
+	case InformationSchemaFilter, InformationSchemaBlock, InformationSchemaAllow:
+	case "":
+		informationSchema = InformationSchemaFilter
+	default:
+		return nil, fmt.Errorf("invalid TRINO_INFORMATION_SCHEMA '%s': must be filter, block or allow", informationSchema)
+	}
+
+	// Parse export configuration
+	exportDir := getEnv("TRINO_EXPORT_DIR", filepath.Join(os.TempDir(), "mcp-trino-exports"))
+	exportAllowedURIs := parseAllowlist(getEnv("TRINO_EXPORT_ALLOWED_URIS", ""))
+	for _, uri := range exportAllowedURIs {
+		if !strings.HasPrefix(uri, "s3://") && !strings.HasPrefix(uri, "gs://") {
+			return nil, fmt.Errorf("invalid TRINO_EXPORT_ALLOWED_URIS entry '%s': must start with s3:// or gs://", uri)
+		}
+	}
+
+	proxyURL, err := parseProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	compression, err := parseCompression()
+	if err != nil {
 		return nil, err
 	}
-	if err := validateAllowlist("TRINO_ALLOWED_TABLES", allowedTables, 2); err != nil { // Must have catalog.schema.table format
+	spooling, err := parseSpooling()
+	if err != nil {
+		return nil, err
+	}
+	connectionMaxAge := parseSeconds("TRINO_CONNECTION_MAX_AGE", 300)
+	recordDir := getEnv("TRINO_RECORD_DIR", "")
+	replayDir := getEnv("TRINO_REPLAY_DIR", "")
+	if recordDir != "" && replayDir != "" {
+		return nil, fmt.Errorf("set only one of TRINO_RECORD_DIR and TRINO_REPLAY_DIR")
+	}
+	if recordDir != "" {
+		log.Printf("WARNING: Recording Trino HTTP exchanges to %s - the fixtures contain query results", recordDir)
+	}
+	warmUp, _ := strconv.ParseBool(getEnv("TRINO_WARM_UP", "false"))
+	keepaliveInterval := parseSeconds("TRINO_KEEPALIVE_INTERVAL", 0)
+	schemaPollInterval := parseSeconds("TRINO_SCHEMA_POLL_INTERVAL", 60)
+	debugEnabled, _ := strconv.ParseBool(getEnv("MCP_DEBUG_ENABLED", "false"))
+	debugLogging, _ := strconv.ParseBool(getEnv("MCP_DEBUG_LOG", "false"))
+	if debugEnabled && secrets["MCP_DEBUG_TOKEN"] == "" {
+		log.Println("WARNING: MCP_DEBUG_ENABLED without MCP_DEBUG_TOKEN - /debug/pprof is open to anyone who can reach the server")
+	}
+	adminEnabled, _ := strconv.ParseBool(getEnv("MCP_ADMIN_ENABLED", "false"))
+	adminUsers := parseAllowlist(getEnv("MCP_ADMIN_USERS", ""))
+	if adminEnabled && secrets["MCP_ADMIN_API_KEY"] == "" && len(adminUsers) == 0 {
+		log.Println("WARNING: MCP_ADMIN_ENABLED without MCP_ADMIN_API_KEY or MCP_ADMIN_USERS - the admin tools can only be used over stdio")
+	}
+	if len(adminUsers) > 0 && !oauthEnabled {
+		log.Println("WARNING: MCP_ADMIN_USERS has no effect without OAUTH_ENABLED")
+	}
+
+	// Parse external policy engine configuration
+	opaURL := strings.TrimSpace(getEnv("TRINO_OPA_URL", ""))
+	if opaURL != "" && !strings.HasPrefix(opaURL, "http://") && !strings.HasPrefix(opaURL, "https://") {
+		return nil, fmt.Errorf("invalid TRINO_OPA_URL '%s': must start with http:// or https://", opaURL)
+	}
+	opaTimeoutSec, err := strconv.Atoi(getEnv("TRINO_OPA_TIMEOUT", "5"))
+	if err != nil || opaTimeoutSec <= 0 {
+		log.Printf("WARNING: Invalid TRINO_OPA_TIMEOUT, using default of 5 seconds")
+		opaTimeoutSec = 5
+	}
+	opaFailOpen, _ := strconv.ParseBool(getEnv("TRINO_OPA_FAIL_OPEN", "false"))
+
+	// Parse dbt configuration
+	dbtManifest := strings.TrimSpace(getEnv("TRINO_DBT_MANIFEST", ""))
+	if strings.Contains(dbtManifest, "://") && !strings.HasPrefix(dbtManifest, "http://") && !strings.HasPrefix(dbtManifest, "https://") {
+		return nil, fmt.Errorf("invalid TRINO_DBT_MANIFEST '%s': must be a file path or start with http:// or https://", dbtManifest)
+	}
+
+	// Parse data catalog configuration
+	dataCatalog := strings.ToLower(strings.TrimSpace(getEnv("TRINO_DATA_CATALOG", "")))
+	dataCatalogURL := strings.TrimSuffix(strings.TrimSpace(getEnv("TRINO_DATA_CATALOG_URL", "")), "/")
+	switch dataCatalog {
+	case "":
+	case DataCatalogDataHub, DataCatalogAmundsen, DataCatalogOpenMetadata:
+		if !strings.HasPrefix(dataCatalogURL, "http://") && !strings.HasPrefix(dataCatalogURL, "https://") {
+			return nil, fmt.Errorf("invalid TRINO_DATA_CATALOG_URL '%s': must start with http:// or https://", dataCatalogURL)
+		}
+	default:
+		return nil, fmt.Errorf("invalid TRINO_DATA_CATALOG '%s': must be datahub, amundsen or openmetadata", dataCatalog)
+	}
+	dataCatalogService := getEnv("TRINO_DATA_CATALOG_SERVICE", "")
+	if dataCatalogService == "" && dataCatalog != DataCatalogDataHub {
+		dataCatalogService = "trino"
+	}
+	dataCatalogTimeoutSec, err := strconv.Atoi(getEnv("TRINO_DATA_CATALOG_TIMEOUT", "5"))
+	if err != nil || dataCatalogTimeoutSec <= 0 {
+		log.Printf("WARNING: Invalid TRINO_DATA_CATALOG_TIMEOUT, using default of 5 seconds")
+		dataCatalogTimeoutSec = 5
+	}
+
+	// Parse query notification configuration
+	notifyWebhookURL := strings.TrimSpace(getEnv("TRINO_NOTIFY_WEBHOOK_URL", ""))
+	if notifyWebhookURL != "" && !strings.HasPrefix(notifyWebhookURL, "http://") && !strings.HasPrefix(notifyWebhookURL, "https://") {
+		return nil, fmt.Errorf("invalid TRINO_NOTIFY_WEBHOOK_URL: must start with http:// or https://")
+	}
+	notifyFormat := strings.ToLower(strings.TrimSpace(getEnv("TRINO_NOTIFY_FORMAT", NotifyFormatSlack)))
+	if notifyFormat == "" {
+		notifyFormat = NotifyFormatSlack
+	}
+	if notifyFormat != NotifyFormatSlack && notifyFormat != NotifyFormatTeams {
+		return nil, fmt.Errorf("invalid TRINO_NOTIFY_FORMAT '%s': must be slack or teams", notifyFormat)
+	}
+	notifySlowSec, err := strconv.Atoi(getEnv("TRINO_NOTIFY_SLOW_QUERY_SECONDS", "300"))
+	if err != nil || notifySlowSec < 0 {
+		log.Printf("WARNING: Invalid TRINO_NOTIFY_SLOW_QUERY_SECONDS, using default of 300 seconds")
+		notifySlowSec = 300
+	}
+	notifyErrors := parseAllowlist(strings.ToUpper(getEnv("TRINO_NOTIFY_ERRORS", "INSUFFICIENT_RESOURCES,INTERNAL_ERROR,EXTERNAL")))
+	slowQueryLog := parseSeconds("TRINO_SLOW_QUERY_LOG_SECONDS", 0)
+
+	// Parse audit sink configuration
+	auditTable := strings.TrimSpace(getEnv("TRINO_AUDIT_TABLE", ""))
+	if auditTable != "" {
+		if err := validateAllowlist("TRINO_AUDIT_TABLE", []string{auditTable}, 2); err != nil {
+			return nil, err
+		}
+	}
+	auditKafkaRESTURL := strings.TrimSuffix(strings.TrimSpace(getEnv("TRINO_AUDIT_KAFKA_REST_URL", "")), "/")
+	auditKafkaTopic := strings.TrimSpace(getEnv("TRINO_AUDIT_KAFKA_TOPIC", ""))
+	if auditKafkaRESTURL != "" && !strings.HasPrefix(auditKafkaRESTURL, "http://") && !strings.HasPrefix(auditKafkaRESTURL, "https://") {
+		return nil, fmt.Errorf("invalid TRINO_AUDIT_KAFKA_REST_URL: must start with http:// or https://")
+	}
+	if (auditKafkaRESTURL == "") != (auditKafkaTopic == "") {
+		return nil, fmt.Errorf("set both TRINO_AUDIT_KAFKA_REST_URL and TRINO_AUDIT_KAFKA_TOPIC to write audit events to Kafka")
+	}
+
+	// Parse named clusters
+	clusters, err := loadClusters()
+	if err != nil {
+		return nil, err
+	}
+	defaultCluster := getEnv("TRINO_DEFAULT_CLUSTER", "")
+	switch {
+	case defaultCluster == "" && len(clusters) > 0:
+		defaultCluster = clusters[0].Name
+	case defaultCluster == "":
+		defaultCluster = DefaultClusterName
+	case !clusterExists(clusters, defaultCluster):
+		return nil, fmt.Errorf("invalid TRINO_DEFAULT_CLUSTER '%s': no such cluster", defaultCluster)
+	}
+
+	// Parse cluster routing configuration
+	routing, err := loadRouting(clusters, defaultCluster)
+	if err != nil {
 		return nil, err
 	}
 
@@ -144,11 +661,33 @@ func NewTrinoConfigWithVersion(version string) (*TrinoConfig, error) {
 		ssl = true
 	}
 
+	// Read-only mode overrides every setting that would let a statement write
+	if readOnly {
+		if allowWriteQueries {
+			log.Println("INFO: TRINO_READ_ONLY=true overrides TRINO_ALLOW_WRITE_QUERIES=true")
+		}
+		allowWriteQueries = false
+		policy.AllowedProcedures = nil
+		log.Println("INFO: Read-only mode (TRINO_READ_ONLY=true): write queries, procedure calls and session statements are blocked")
+	}
+
 	// Log a warning if write queries are allowed
 	if allowWriteQueries {
 		log.Println("WARNING: Write queries are enabled (TRINO_ALLOW_WRITE_QUERIES=true). SQL injection protection is bypassed.")
 	}
 
+	// Log result limits
+	if policy.MaxResultRows > 0 || policy.MaxResultBytes > 0 {
+		log.Printf("INFO: Query result limits: max rows %d, max bytes %d (0 means unlimited)", policy.MaxResultRows, policy.MaxResultBytes)
+	}
+	logColumnMasks(policy.ColumnMasks)
+	logBlockedQueryPatterns(policy.BlockedQueryPatterns)
+	logRequiredPartitionFilters(policy.RequiredPartitionFilters)
+	logQueryScope(policy)
+	logAllowedProcedures(policy.AllowedProcedures)
+	logRateLimits(policy)
+	logQuotas(policy)
+
 	// Log OAuth status - detailed validation delegated to oauth-mcp-proxy
 	if oauthEnabled {
 		log.Printf("INFO: OAuth 2.1 enabled (mode: %s, provider: %s)", oauthMode, oauthProvider)
@@ -168,7 +707,7 @@ func NewTrinoConfigWithVersion(version string) (*TrinoConfig, error) {
 	}
 
 	// Log allowlist configuration
-	logAllowlistConfiguration(allowedCatalogs, allowedSchemas, allowedTables)
+	logAllowlistConfiguration(policy.AllowedCatalogs, policy.AllowedSchemas, policy.AllowedTables)
 
 	// Validate impersonation field
 	validFields := map[string]bool{"username": true, "email": true, "subject": true}
@@ -180,6 +719,9 @@ func NewTrinoConfigWithVersion(version string) (*TrinoConfig, error) {
 	if enableImpersonation {
 		log.Printf("INFO: Trino user impersonation enabled (TRINO_ENABLE_IMPERSONATION=true)")
 		log.Printf("INFO: Impersonation principal field: %s", impersonationField)
+		if impersonationRequired {
+			log.Println("INFO: Tool calls without an authenticated user are refused (TRINO_IMPERSONATION_REQUIRED=true)")
+		}
 		if !oauthEnabled {
 			log.Println("WARNING: Impersonation is enabled but OAuth is disabled. Impersonation requires OAuth to extract user information.")
 		}
@@ -190,23 +732,75 @@ func NewTrinoConfigWithVersion(version string) (*TrinoConfig, error) {
 	// Log query attribution configuration
 	log.Printf("INFO: Trino query source attribution: %s", trinoSource)
 
-	// Log external authentication configuration
-	if externalAuth {
-		log.Printf("INFO: Trino external authentication enabled (direct browser OAuth flow)")
+	// Log Trino authentication configuration
+	if len(authMethods) > 1 {
+		log.Printf("INFO: Trino auth methods in order of preference: %s", strings.Join(authMethods, ", "))
+	}
+
+	// Log tool exposure configuration
+	if len(enabledTools) > 0 {
+		log.Printf("INFO: Enabled MCP tools: %s", strings.Join(enabledTools, ", "))
+	}
+	if len(disabledTools) > 0 {
+		log.Printf("INFO: Disabled MCP tools: %s", strings.Join(disabledTools, ", "))
+	}
+
+	// Log cluster configuration
+	logClusterConfiguration(clusters, defaultCluster)
+	logRoutingConfiguration(routing)
+
+	// Log external policy engine configuration
+	if opaURL != "" {
+		log.Printf("INFO: Query authorization via OPA: %s (timeout %ds, fail open: %t)", opaURL, opaTimeoutSec, opaFailOpen)
 	}
 
-	return &TrinoConfig{
+	// Log query notification configuration; the webhook URL is a secret
+	if notifyWebhookURL != "" {
+		log.Printf("INFO: Query notifications via %s webhook (slow queries: %ds, errors: %s)", notifyFormat, notifySlowSec, strings.Join(notifyErrors, ","))
+	}
+
+	// Log audit sink configuration; the REST Proxy URL may hold credentials
+	if auditTable != "" {
+		log.Printf("INFO: Writing audit events of every query to Trino table %s", auditTable)
+	}
+	if auditKafkaTopic != "" {
+		log.Printf("INFO: Writing audit events of every query to Kafka topic %s", auditKafkaTopic)
+	}
+
+	// Log admin tool configuration
+	if adminEnabled {
+		log.Printf("INFO: Admin tools enabled (API key: %t, admin users: %s)", secrets["MCP_ADMIN_API_KEY"] != "", strings.Join(adminUsers, ", "))
+	}
+
+	// Log dbt configuration
+	if dbtManifest != "" {
+		log.Printf("INFO: dbt model tools enabled with manifest %s", dbtManifest)
+	}
+
+	// Log data catalog configuration
+	if dataCatalog != "" {
+		log.Printf("INFO: Merging %s metadata from %s into schema output", dataCatalog, dataCatalogURL)
+	}
+
+	// Log export configuration
+	log.Printf("INFO: export_query local directory: %s", exportDir)
+	if len(exportAllowedURIs) > 0 {
+		log.Printf("INFO: export_query remote destinations: %s", strings.Join(exportAllowedURIs, ", "))
+	}
+
+	cfg := &TrinoConfig{
 		Host:                getEnv("TRINO_HOST", "localhost"),
 		Port:                port,
 		User:                getEnv("TRINO_USER", "trino"),
-		Password:            getEnv("TRINO_PASSWORD", ""),
 		Catalog:             getEnv("TRINO_CATALOG", "memory"),
 		Schema:              getEnv("TRINO_SCHEMA", "default"),
 		Scheme:              scheme,
 		SSL:                 ssl,
 		SSLInsecure:         sslInsecure,
 		AllowWriteQueries:   allowWriteQueries,
+		ReadOnly:            readOnly,
 		QueryTimeout:        queryTimeout,
+		MaxQueryTimeout:     maxQueryTimeout,
 		OAuthEnabled:        oauthEnabled,
 		OAuthMode:           oauthMode,
 		OAuthProvider:       oauthProvider,
@@ -216,15 +810,131 @@ func NewTrinoConfigWithVersion(version string) (*TrinoConfig, error) {
 		OIDCClientID:        oidcClientID,
 		OIDCClientSecret:    oidcClientSecret,
 		OAuthRedirectURIs:   oauthRedirectURIs,
-		AllowedCatalogs:     allowedCatalogs,
-		AllowedSchemas:      allowedSchemas,
-		AllowedTables:       allowedTables,
-		EnableImpersonation:  enableImpersonation,
-		ImpersonationField:   impersonationField,
-		TrinoSource:          trinoSource,
-		ExternalAuth:         externalAuth,
-		ExternalAuthTimeout:  externalAuthTimeout,
-	}, nil
+		AllowedCatalogs:     policy.AllowedCatalogs,
+		AllowedSchemas:      policy.AllowedSchemas,
+		AllowedTables:       policy.AllowedTables,
+		EnableImpersonation: enableImpersonation,
+		ImpersonationField:  impersonationField,
+		TrinoSource:         trinoSource,
+		ClientTags:          clientTags,
+		SessionTimeZone:     sessionTimeZone,
+		Role:                role,
+		CatalogRoles:        catalogRoles,
+		Catalogs:            catalogs,
+		AuthMethods:         authMethods,
+		password:            secrets["TRINO_PASSWORD"],
+		jwt:                 accessToken,
+		jwtErr:              jwtErr,
+		ExternalAuth:        externalAuth,
+		ExternalAuthTimeout: externalAuthTimeout,
+		EnabledTools:        enabledTools,
+		DisabledTools:       disabledTools,
+		TracingEnabled:      tracingEnabled,
+		TracingServiceName:  tracingServiceName,
+		MaxResultRows:       policy.MaxResultRows,
+		MaxResultBytes:      policy.MaxResultBytes,
+		ResultSpillBytes:    resultSpillBytes,
+		ResultMemoryBudget:  resultMemoryBudget,
+		NumberEncoding:      numberEncoding,
+		NullValue:           nullValue,
+		MaxCellChars:        maxCellChars,
+
+		SelectStarMaxColumns: selectStarMaxColumns,
+		SelectStarExpand:     selectStarExpand,
+		InformationSchema:    informationSchema,
+
+		ExportDir:           exportDir,
+		ExportAllowedURIs:   exportAllowedURIs,
+		ExportGCSKeyID:      getEnv("TRINO_EXPORT_GCS_ACCESS_KEY_ID", ""),
+		ExportGCSSecret:     secrets["TRINO_EXPORT_GCS_SECRET_ACCESS_KEY"],
+		Clusters:            clusters,
+		DefaultCluster:      defaultCluster,
+		RoutingPolicy:       routing.policy,
+		RoutingClusters:     routing.clusters,
+		HealthCheckInterval: routing.healthInterval,
+		BreakerThreshold:    routing.threshold,
+		BreakerCooldown:     routing.cooldown,
+		OPAURL:              opaURL,
+		OPATimeout:          time.Duration(opaTimeoutSec) * time.Second,
+		OPAFailOpen:         opaFailOpen,
+		DBTManifest:         dbtManifest,
+		DataCatalog:         dataCatalog,
+		DataCatalogURL:      dataCatalogURL,
+		DataCatalogToken:    secrets["TRINO_DATA_CATALOG_TOKEN"],
+		DataCatalogService:  dataCatalogService,
+		DataCatalogEnv:      getEnv("TRINO_DATA_CATALOG_ENV", "PROD"),
+		DataCatalogTimeout:  time.Duration(dataCatalogTimeoutSec) * time.Second,
+		NotifyWebhookURL:    notifyWebhookURL,
+		NotifyFormat:        notifyFormat,
+		NotifySlowQuery:     time.Duration(notifySlowSec) * time.Second,
+		NotifyErrors:        notifyErrors,
+		SlowQueryLog:        slowQueryLog,
+
+		ColumnMasks:                policy.ColumnMasks,
+		BlockedQueryPatterns:       policy.BlockedQueryPatterns,
+		RequiredPartitionFilters:   policy.RequiredPartitionFilters,
+		BlockCrossCatalogQueries:   policy.BlockCrossCatalogQueries,
+		MaxQueryTables:             policy.MaxQueryTables,
+		MaxQueryLength:             policy.MaxQueryLength,
+		MaxQueryJoins:              policy.MaxQueryJoins,
+		MaxSubqueryDepth:           policy.MaxSubqueryDepth,
+		AllowedProcedures:          policy.AllowedProcedures,
+		RateLimitRequestsPerMinute: policy.RateLimitRequestsPerMinute,
+		RateLimitQueriesPerHour:    policy.RateLimitQueriesPerHour,
+		QuotaQueriesPerDay:         policy.QuotaQueriesPerDay,
+		QuotaScannedBytesPerDay:    policy.QuotaScannedBytesPerDay,
+		QuotaFile:                  strings.TrimSpace(getEnv("MCP_QUOTA_FILE", "")),
+		ExternalAuthHeadless:       externalAuthHeadless,
+		ExternalAuthFlow:           externalAuthFlow,
+		ExternalAuthPollInterval:   externalAuthPollInterval,
+		ImpersonationRequired:      impersonationRequired,
+		ExternalAuthIdP:            externalAuthIdP,
+		ProxyURL:                   proxyURL,
+		Compression:                compression,
+		HTTPHeaders:                httpHeaders,
+		Gateway:                    gateway,
+		RoutingGroup:               routingGroup,
+		Spooling:                   spooling,
+		ConnectionMaxAge:           connectionMaxAge,
+		RecordDir:                  recordDir,
+		ReplayDir:                  replayDir,
+		WarmUp:                     warmUp,
+		KeepaliveInterval:          keepaliveInterval,
+		SchemaPollInterval:         schemaPollInterval,
+		DebugEnabled:               debugEnabled,
+		DebugToken:                 secrets["MCP_DEBUG_TOKEN"],
+		DebugLogging:               debugLogging,
+
+		AuditTable:        auditTable,
+		AuditUser:         strings.TrimSpace(getEnv("TRINO_AUDIT_USER", "")),
+		AuditPassword:     secrets["TRINO_AUDIT_PASSWORD"],
+		AuditKafkaRESTURL: auditKafkaRESTURL,
+		AuditKafkaTopic:   auditKafkaTopic,
+
+		AdminEnabled: adminEnabled,
+		AdminAPIKey:  secrets["MCP_ADMIN_API_KEY"],
+		AdminUsers:   adminUsers,
+	}
+	return cfg.selectAuth()
+}
+
+// IsToolEnabled reports whether the named MCP tool should be registered.
+// An empty EnabledTools list enables every tool; DisabledTools always wins.
+func (c *TrinoConfig) IsToolEnabled(name string) bool {
+	for _, disabled := range c.DisabledTools {
+		if strings.EqualFold(name, disabled) {
+			return false
+		}
+	}
+	if len(c.EnabledTools) == 0 {
+		return true
+	}
+	for _, enabled := range c.EnabledTools {
+		if strings.EqualFold(name, enabled) {
+			return true
+		}
+	}
+	return false
 }
 
 // parseAllowlist parses a comma-separated allowlist from an environment variable
@@ -245,6 +955,48 @@ func parseAllowlist(value string) []string {
 	return result
 }
 
+// parseCatalogRoles parses TRINO_CATALOG_ROLES, a comma-separated list of
+// catalog=role entries
+func parseCatalogRoles(value string) (map[string]string, error) {
+	items := parseAllowlist(value)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	roles := make(map[string]string, len(items))
+	for _, item := range items {
+		catalog, role, found := strings.Cut(item, "=")
+		catalog, role = strings.ToLower(strings.TrimSpace(catalog)), strings.TrimSpace(role)
+		if !found || catalog == "" || role == "" {
+			return nil, fmt.Errorf("invalid format in TRINO_CATALOG_ROLES: '%s' (expected catalog=role)", item)
+		}
+		roles[catalog] = role
+	}
+	return roles, nil
+}
+
+// parseHTTPHeaders parses TRINO_HTTP_HEADERS, a comma-separated list of
+// Name=value headers sent with every Trino request
+func parseHTTPHeaders(value string) (map[string]string, error) {
+	items := parseAllowlist(value)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(items))
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		name, headerValue, found := strings.Cut(item, "=")
+		name, headerValue = http.CanonicalHeaderKey(strings.TrimSpace(name)), strings.TrimSpace(headerValue)
+		if !found || !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(headerValue) {
+			// The value may be a gateway secret, so only the name is reported
+			return nil, fmt.Errorf("invalid format in TRINO_HTTP_HEADERS: header '%s' (expected Name=value)", name)
+		}
+		headers[name] = headerValue
+		names = append(names, name)
+	}
+	log.Printf("INFO: Sending headers %s with every Trino request", strings.Join(names, ", "))
+	return headers, nil
+}
+
 // validateAllowlist validates the format of allowlist entries
 func validateAllowlist(envVar string, allowlist []string, expectedDots int) error {
 	for _, item := range allowlist {
@@ -282,3 +1034,38 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// loadSecrets reads each secret from its environment variable or from the file
+// named by the variable with a _FILE suffix, so secrets need not appear in the
+// environment or process listing. File contents are trimmed of surrounding
+// whitespace such as the trailing newline most secret files end with. Values
+// that reference a secret store (vault://path#key, aws-sm://name) are resolved.
+func loadSecrets(keys ...string) (map[string]string, error) {
+	secrets := make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, err := readSecret(key)
+		if err != nil {
+			return nil, err
+		}
+		if secrets[key], err = resolveSecret(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return secrets, nil
+}
+
+// readSecret reads a secret from the environment variable or its _FILE variant
+func readSecret(key string) (string, error) {
+	path := getEnv(key+"_FILE", "")
+	if path == "" {
+		return getEnv(key, ""), nil
+	}
+	if getEnv(key, "") != "" {
+		return "", fmt.Errorf("set only one of %s and %s_FILE", key, key)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", key, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
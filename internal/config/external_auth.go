@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// External authentication flows selected with TRINO_EXTERNAL_AUTH_FLOW
+const (
+	ExternalAuthFlowTrino = "trino" // Trino starts the login and mcp-trino polls its x_token_server (default)
+	ExternalAuthFlowPKCE  = "pkce"  // mcp-trino logs in with the IdP itself, via a localhost callback and PKCE
+)
+
+// ExternalAuthIdP is the identity provider the PKCE flow logs in with. Its
+// token must be one the Trino OAuth2 or JWT authenticator accepts.
+type ExternalAuthIdP struct {
+	Issuer       string   // OIDC issuer whose discovery document gives the endpoints
+	AuthorizeURL string   // Authorization endpoint, instead of discovery
+	TokenURL     string   // Token endpoint, instead of discovery
+	ClientID     string   // Public (or confidential) client registered for the localhost redirect
+	ClientSecret string   // Only for IdPs that require one for native apps
+	Scopes       []string // Scopes requested (default: openid)
+	RedirectPort int      // Port of http://localhost:<port>/callback; 0 picks a free port
+	UseIDToken   bool     // Send the ID token to Trino instead of the access token
+}
+
+// parseExternalAuthIdP reads the identity provider settings of the PKCE flow
+func parseExternalAuthIdP() (*ExternalAuthIdP, error) {
+	secrets, err := loadSecrets("TRINO_EXTERNAL_AUTH_CLIENT_SECRET")
+	if err != nil {
+		return nil, err
+	}
+	idp := &ExternalAuthIdP{
+		Issuer:       strings.TrimSuffix(getEnv("TRINO_EXTERNAL_AUTH_ISSUER", ""), "/"),
+		AuthorizeURL: getEnv("TRINO_EXTERNAL_AUTH_AUTHORIZE_URL", ""),
+		TokenURL:     getEnv("TRINO_EXTERNAL_AUTH_TOKEN_URL", ""),
+		ClientID:     getEnv("TRINO_EXTERNAL_AUTH_CLIENT_ID", ""),
+		ClientSecret: secrets["TRINO_EXTERNAL_AUTH_CLIENT_SECRET"],
+		Scopes:       strings.Fields(strings.ReplaceAll(getEnv("TRINO_EXTERNAL_AUTH_SCOPES", "openid"), ",", " ")),
+	}
+	idp.UseIDToken, _ = strconv.ParseBool(getEnv("TRINO_EXTERNAL_AUTH_USE_ID_TOKEN", "false"))
+
+	portStr := getEnv("TRINO_EXTERNAL_AUTH_REDIRECT_PORT", "0")
+	if idp.RedirectPort, err = strconv.Atoi(portStr); err != nil || idp.RedirectPort < 0 || idp.RedirectPort > 65535 {
+		log.Printf("WARNING: Invalid TRINO_EXTERNAL_AUTH_REDIRECT_PORT '%s', using a free port", portStr)
+		idp.RedirectPort = 0
+	}
+
+	if idp.ClientID == "" {
+		return nil, fmt.Errorf("TRINO_EXTERNAL_AUTH_FLOW=pkce requires TRINO_EXTERNAL_AUTH_CLIENT_ID")
+	}
+	if idp.Issuer == "" && (idp.AuthorizeURL == "" || idp.TokenURL == "") {
+		return nil, fmt.Errorf("TRINO_EXTERNAL_AUTH_FLOW=pkce requires TRINO_EXTERNAL_AUTH_ISSUER, or TRINO_EXTERNAL_AUTH_AUTHORIZE_URL and TRINO_EXTERNAL_AUTH_TOKEN_URL")
+	}
+	return idp, nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// jwtCommandTimeout bounds TRINO_JWT_COMMAND
+	jwtCommandTimeout = 30 * time.Second
+
+	// jwtExpiryWarning is how close to its expiry a Trino JWT is reported
+	jwtExpiryWarning = time.Hour
+)
+
+// loadJWT returns the Trino JWT from TRINO_JWT, TRINO_JWT_FILE or the output
+// of TRINO_JWT_COMMAND, and refuses one that expires within TRINO_JWT_MIN_VALIDITY
+func loadJWT() (string, error) {
+	secrets, err := loadSecrets("TRINO_JWT")
+	if err != nil {
+		return "", err
+	}
+	token := secrets["TRINO_JWT"]
+	if command := strings.TrimSpace(getEnv("TRINO_JWT_COMMAND", "")); command != "" {
+		if token != "" {
+			return "", fmt.Errorf("set only one of TRINO_JWT, TRINO_JWT_FILE and TRINO_JWT_COMMAND")
+		}
+		if token, err = runJWTCommand(command); err != nil {
+			return "", err
+		}
+	}
+	if token == "" {
+		return "", nil
+	}
+	minValidity := parseSeconds("TRINO_JWT_MIN_VALIDITY", 60)
+	if err := checkJWTExpiry(token, minValidity, time.Now()); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// runJWTCommand runs a shell command that prints a token, such as a cloud CLI
+func runJWTCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwtCommandTimeout)
+	defer cancel()
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("TRINO_JWT_COMMAND failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("TRINO_JWT_COMMAND printed no token")
+	}
+	return token, nil
+}
+
+// jwtExpiry returns the exp claim of a JWT, or the zero time when it has none.
+// The signature is not verified; Trino does that.
+func jwtExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("expected three dot-separated parts")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid payload encoding: %w", err)
+	}
+	var claims struct {
+		Exp *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("invalid payload: %w", err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, nil
+	}
+	return time.Unix(int64(*claims.Exp), 0), nil
+}
+
+// checkJWTExpiry refuses a token that has expired or expires within
+// minValidity, and warns about one that expires within jwtExpiryWarning
+func checkJWTExpiry(token string, minValidity time.Duration, now time.Time) error {
+	expires, err := jwtExpiry(token)
+	if err != nil {
+		return fmt.Errorf("invalid Trino JWT: %w", err)
+	}
+	if expires.IsZero() {
+		log.Println("INFO: Trino JWT has no expiry")
+		return nil
+	}
+	left := expires.Sub(now).Truncate(time.Second)
+	switch {
+	case left <= 0:
+		return fmt.Errorf("invalid Trino JWT: expired at %s", expires.UTC().Format(time.RFC3339))
+	case left < minValidity:
+		return fmt.Errorf("invalid Trino JWT: expires in %v, less than TRINO_JWT_MIN_VALIDITY (%v)", left, minValidity)
+	case left < jwtExpiryWarning:
+		log.Printf("WARNING: Trino JWT expires in %v (at %s); renew it and send SIGHUP or restart before then",
+			left, expires.UTC().Format(time.RFC3339))
+	default:
+		log.Printf("INFO: Trino JWT valid until %s", expires.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
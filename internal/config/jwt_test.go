@@ -0,0 +1,82 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testJWT builds an unsigned token with the given claims
+func testJWT(claims string) string {
+	return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".sig"
+}
+
+func TestCheckJWTExpiry(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "valid for a day", token: testJWT(fmt.Sprintf(`{"sub":"ci","exp":%d}`, now.Add(24*time.Hour).Unix()))},
+		{name: "expiring soon is only reported", token: testJWT(fmt.Sprintf(`{"exp":%d}`, now.Add(10*time.Minute).Unix()))},
+		{name: "no expiry", token: testJWT(`{"sub":"svc"}`)},
+		{name: "expired", token: testJWT(fmt.Sprintf(`{"exp":%d}`, now.Add(-time.Minute).Unix())), wantErr: "expired at 2024-06-01T11:59:00Z"},
+		{name: "below minimum validity", token: testJWT(fmt.Sprintf(`{"exp":%d}`, now.Add(30*time.Second).Unix())), wantErr: "expires in 30s"},
+		{name: "not a JWT", token: "opaque-token", wantErr: "three dot-separated parts"},
+		{name: "invalid payload", token: "a.b!.c", wantErr: "invalid payload encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkJWTExpiry(tt.token, time.Minute, now)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkJWTExpiry() = %v, want no error", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("checkJWTExpiry() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWTAuthConfiguration(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("TRINO_JWT_COMMAND test uses a POSIX shell")
+	}
+	t.Setenv("OAUTH_ENABLED", "false")
+	t.Setenv("TRINO_AUTH", "jwt")
+
+	if _, err := NewTrinoConfig(); err == nil || !strings.Contains(err.Error(), "TRINO_JWT_COMMAND") {
+		t.Errorf("NewTrinoConfig() without a token = %v, want an error naming the token sources", err)
+	}
+
+	token := testJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(2*time.Hour).Unix()))
+	t.Setenv("TRINO_JWT_COMMAND", "echo "+token)
+	config, err := NewTrinoConfig()
+	if err != nil {
+		t.Fatalf("NewTrinoConfig() error = %v", err)
+	}
+	if config.Auth != AuthJWT || config.AccessToken != token {
+		t.Errorf("Auth = %q, AccessToken = %q; want the token printed by the command", config.Auth, config.AccessToken)
+	}
+
+	t.Setenv("TRINO_JWT", token)
+	if _, err := NewTrinoConfig(); err == nil {
+		t.Error("NewTrinoConfig() should reject TRINO_JWT together with TRINO_JWT_COMMAND")
+	}
+	t.Setenv("TRINO_JWT", "")
+
+	t.Setenv("TRINO_JWT_COMMAND", "echo denied >&2; exit 1")
+	if _, err := NewTrinoConfig(); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("NewTrinoConfig() with a failing command = %v, want its stderr", err)
+	}
+
+	expired := testJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(-time.Hour).Unix()))
+	t.Setenv("TRINO_JWT_COMMAND", "echo "+expired)
+	if _, err := NewTrinoConfig(); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("NewTrinoConfig() with an expired token = %v, want it refused", err)
+	}
+}
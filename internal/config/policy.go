@@ -0,0 +1,634 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Column mask actions
+const (
+	MaskDrop   = "drop"   // Remove the column from results and table schemas
+	MaskSHA256 = "sha256" // Replace values with their SHA-256 hex digest
+	MaskRedact = "redact" // Replace values with a fixed placeholder
+	MaskNull   = "null"   // Replace values with NULL
+)
+
+// Policy holds the governance settings that can be reloaded while the server
+// runs: allowlists, column masks, result limits, per-client rate limits and
+// daily quotas
+type Policy struct {
+	AllowedCatalogs            []string
+	AllowedSchemas             []string
+	AllowedTables              []string
+	ColumnMasks                map[string]string   // Lower-case catalog.schema.table.column -> mask action
+	BlockedQueryPatterns       []*regexp.Regexp    // Queries matching any pattern are rejected
+	RequiredPartitionFilters   map[string][]string // Lower-case catalog.schema.table -> partition columns, one of which queries must filter on
+	BlockCrossCatalogQueries   bool                // Queries referencing tables of more than one catalog are rejected
+	MaxQueryTables             int                 // Queries referencing more distinct tables are rejected (0 means unlimited)
+	MaxQueryLength             int                 // Queries of more characters are rejected (0 means unlimited)
+	MaxQueryJoins              int                 // Queries with more JOINs are rejected (0 means unlimited)
+	MaxSubqueryDepth           int                 // Queries nesting subqueries deeper are rejected (0 means unlimited)
+	AllowedProcedures          []string            // Lower-case schema.procedure or catalog.schema.procedure callable with call_procedure
+	MaxResultRows              int
+	MaxResultBytes             int64
+	RateLimitRequestsPerMinute int
+	RateLimitQueriesPerHour    int
+	QuotaQueriesPerDay         int   // Query tool calls per day per client
+	QuotaScannedBytesPerDay    int64 // Bytes Trino may scan per day per client
+}
+
+// policyFile is the TRINO_POLICY_FILE format; fields present in the file
+// replace the corresponding TRINO_* environment settings
+type policyFile struct {
+	AllowedCatalogs          *[]string            `json:"allowedCatalogs"`
+	AllowedSchemas           *[]string            `json:"allowedSchemas"`
+	AllowedTables            *[]string            `json:"allowedTables"`
+	ColumnMasks              *map[string]string   `json:"columnMasks"`
+	RequiredPartitionFilters *map[string][]string `json:"requiredPartitionFilters"`
+	BlockCrossCatalogQueries *bool                `json:"blockCrossCatalogQueries"`
+	MaxQueryTables           *int                 `json:"maxQueryTables"`
+	MaxQueryLength           *int                 `json:"maxQueryLength"`
+	MaxQueryJoins            *int                 `json:"maxQueryJoins"`
+	MaxSubqueryDepth         *int                 `json:"maxSubqueryDepth"`
+	AllowedProcedures        *[]string            `json:"allowedProcedures"`
+	MaxResultRows            *int                 `json:"maxResultRows"`
+	MaxResultBytes           *int64               `json:"maxResultBytes"`
+	RateLimit                *struct {
+		RequestsPerMinute *int `json:"requestsPerMinute"`
+		QueriesPerHour    *int `json:"queriesPerHour"`
+	} `json:"rateLimit"`
+	Quota *struct {
+		QueriesPerDay      *int   `json:"queriesPerDay"`
+		ScannedBytesPerDay *int64 `json:"scannedBytesPerDay"`
+	} `json:"quota"`
+}
+
+// Policy returns the allowlists, result limits, rate limits and quotas of the configuration
+func (c *TrinoConfig) Policy() *Policy {
+	return &Policy{
+		AllowedCatalogs:            c.AllowedCatalogs,
+		AllowedSchemas:             c.AllowedSchemas,
+		AllowedTables:              c.AllowedTables,
+		ColumnMasks:                c.ColumnMasks,
+		BlockedQueryPatterns:       c.BlockedQueryPatterns,
+		RequiredPartitionFilters:   c.RequiredPartitionFilters,
+		BlockCrossCatalogQueries:   c.BlockCrossCatalogQueries,
+		MaxQueryTables:             c.MaxQueryTables,
+		MaxQueryLength:             c.MaxQueryLength,
+		MaxQueryJoins:              c.MaxQueryJoins,
+		MaxSubqueryDepth:           c.MaxSubqueryDepth,
+		AllowedProcedures:          c.AllowedProcedures,
+		MaxResultRows:              c.MaxResultRows,
+		MaxResultBytes:             c.MaxResultBytes,
+		RateLimitRequestsPerMinute: c.RateLimitRequestsPerMinute,
+		RateLimitQueriesPerHour:    c.RateLimitQueriesPerHour,
+		QuotaQueriesPerDay:         c.QuotaQueriesPerDay,
+		QuotaScannedBytesPerDay:    c.QuotaScannedBytesPerDay,
+	}
+}
+
+// loadPolicy reads allowlists, result limits, rate limits and quotas from the
+// environment and overlays TRINO_POLICY_FILE when set
+func loadPolicy() (*Policy, error) {
+	// Parse result size limits (0 disables the limit)
+	maxResultRows, err := strconv.Atoi(getEnv("TRINO_MAX_RESULT_ROWS", "0"))
+	if err != nil || maxResultRows < 0 {
+		log.Printf("WARNING: Invalid TRINO_MAX_RESULT_ROWS, result rows will not be limited")
+		maxResultRows = 0
+	}
+	maxResultBytes, err := strconv.ParseInt(getEnv("TRINO_MAX_RESULT_BYTES", "0"), 10, 64)
+	if err != nil || maxResultBytes < 0 {
+		log.Printf("WARNING: Invalid TRINO_MAX_RESULT_BYTES, result size will not be limited")
+		maxResultBytes = 0
+	}
+
+	// Parse query scope limits (0 disables the limit)
+	blockCrossCatalog, _ := strconv.ParseBool(getEnv("TRINO_BLOCK_CROSS_CATALOG_QUERIES", "false"))
+	maxQueryTables, err := strconv.Atoi(getEnv("TRINO_MAX_QUERY_TABLES", "0"))
+	if err != nil || maxQueryTables < 0 {
+		log.Printf("WARNING: Invalid TRINO_MAX_QUERY_TABLES, tables per query will not be limited")
+		maxQueryTables = 0
+	}
+	maxQueryLength, err := strconv.Atoi(getEnv("TRINO_MAX_QUERY_LENGTH", "0"))
+	if err != nil || maxQueryLength < 0 {
+		log.Printf("WARNING: Invalid TRINO_MAX_QUERY_LENGTH, query length will not be limited")
+		maxQueryLength = 0
+	}
+	maxQueryJoins, err := strconv.Atoi(getEnv("TRINO_MAX_QUERY_JOINS", "0"))
+	if err != nil || maxQueryJoins < 0 {
+		log.Printf("WARNING: Invalid TRINO_MAX_QUERY_JOINS, joins per query will not be limited")
+		maxQueryJoins = 0
+	}
+	maxSubqueryDepth, err := strconv.Atoi(getEnv("TRINO_MAX_SUBQUERY_DEPTH", "0"))
+	if err != nil || maxSubqueryDepth < 0 {
+		log.Printf("WARNING: Invalid TRINO_MAX_SUBQUERY_DEPTH, subquery depth will not be limited")
+		maxSubqueryDepth = 0
+	}
+
+	// Parse per-client rate limits (0 disables the limit)
+	requestsPerMinute, err := strconv.Atoi(getEnv("MCP_RATE_LIMIT_REQUESTS_PER_MINUTE", "0"))
+	if err != nil || requestsPerMinute < 0 {
+		log.Printf("WARNING: Invalid MCP_RATE_LIMIT_REQUESTS_PER_MINUTE, requests will not be rate limited")
+		requestsPerMinute = 0
+	}
+	queriesPerHour, err := strconv.Atoi(getEnv("MCP_RATE_LIMIT_QUERIES_PER_HOUR", "0"))
+	if err != nil || queriesPerHour < 0 {
+		log.Printf("WARNING: Invalid MCP_RATE_LIMIT_QUERIES_PER_HOUR, queries will not be rate limited")
+		queriesPerHour = 0
+	}
+
+	// Parse per-client daily quotas (0 disables the quota)
+	queriesPerDay, err := strconv.Atoi(getEnv("MCP_QUOTA_QUERIES_PER_DAY", "0"))
+	if err != nil || queriesPerDay < 0 {
+		log.Printf("WARNING: Invalid MCP_QUOTA_QUERIES_PER_DAY, daily queries will not be limited")
+		queriesPerDay = 0
+	}
+	scannedBytesPerDay, err := strconv.ParseInt(getEnv("MCP_QUOTA_SCANNED_BYTES_PER_DAY", "0"), 10, 64)
+	if err != nil || scannedBytesPerDay < 0 {
+		log.Printf("WARNING: Invalid MCP_QUOTA_SCANNED_BYTES_PER_DAY, daily scanned bytes will not be limited")
+		scannedBytesPerDay = 0
+	}
+
+	columnMasks, err := parseColumnMasks(getEnv("TRINO_COLUMN_MASKS", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	blockedQueryPatterns, err := loadBlockedQueryPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	partitionFilters, err := parseRequiredPartitionFilters(getEnv("TRINO_REQUIRED_PARTITION_FILTERS", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	allowedProcedures, err := normalizeProcedures(parseAllowlist(getEnv("TRINO_ALLOWED_PROCEDURES", "")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRINO_ALLOWED_PROCEDURES: %w", err)
+	}
+
+	policy := &Policy{
+		AllowedCatalogs:            parseAllowlist(getEnv("TRINO_ALLOWED_CATALOGS", "")),
+		AllowedSchemas:             parseAllowlist(getEnv("TRINO_ALLOWED_SCHEMAS", "")),
+		AllowedTables:              parseAllowlist(getEnv("TRINO_ALLOWED_TABLES", "")),
+		ColumnMasks:                columnMasks,
+		BlockedQueryPatterns:       blockedQueryPatterns,
+		RequiredPartitionFilters:   partitionFilters,
+		BlockCrossCatalogQueries:   blockCrossCatalog,
+		MaxQueryTables:             maxQueryTables,
+		MaxQueryLength:             maxQueryLength,
+		MaxQueryJoins:              maxQueryJoins,
+		MaxSubqueryDepth:           maxSubqueryDepth,
+		AllowedProcedures:          allowedProcedures,
+		MaxResultRows:              maxResultRows,
+		MaxResultBytes:             maxResultBytes,
+		RateLimitRequestsPerMinute: requestsPerMinute,
+		RateLimitQueriesPerHour:    queriesPerHour,
+		QuotaQueriesPerDay:         queriesPerDay,
+		QuotaScannedBytesPerDay:    scannedBytesPerDay,
+	}
+	if path := getEnv("TRINO_POLICY_FILE", ""); path != "" {
+		if err := policy.applyFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate allowlist formats
+	if err := validateAllowlist("TRINO_ALLOWED_SCHEMAS", policy.AllowedSchemas, 1); err != nil { // Must have catalog.schema format
+		return nil, err
+	}
+	if err := validateAllowlist("TRINO_ALLOWED_TABLES", policy.AllowedTables, 2); err != nil { // Must have catalog.schema.table format
+		return nil, err
+	}
+	return policy, nil
+}
+
+// applyFile overlays the settings present in a policy file
+func (p *Policy) applyFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read TRINO_POLICY_FILE: %w", err)
+	}
+	var file policyFile
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("invalid TRINO_POLICY_FILE: %w", err)
+	}
+
+	if file.AllowedCatalogs != nil {
+		p.AllowedCatalogs = cleanList(*file.AllowedCatalogs)
+	}
+	if file.AllowedSchemas != nil {
+		p.AllowedSchemas = cleanList(*file.AllowedSchemas)
+	}
+	if file.AllowedTables != nil {
+		p.AllowedTables = cleanList(*file.AllowedTables)
+	}
+	if file.ColumnMasks != nil {
+		masks, err := normalizeColumnMasks(*file.ColumnMasks)
+		if err != nil {
+			return fmt.Errorf("invalid TRINO_POLICY_FILE: %w", err)
+		}
+		p.ColumnMasks = masks
+	}
+	if file.RequiredPartitionFilters != nil {
+		filters, err := normalizeRequiredPartitionFilters(*file.RequiredPartitionFilters)
+		if err != nil {
+			return fmt.Errorf("invalid TRINO_POLICY_FILE: %w", err)
+		}
+		p.RequiredPartitionFilters = filters
+	}
+	if file.BlockCrossCatalogQueries != nil {
+		p.BlockCrossCatalogQueries = *file.BlockCrossCatalogQueries
+	}
+	if file.MaxQueryTables != nil {
+		if *file.MaxQueryTables < 0 {
+			return fmt.Errorf("invalid TRINO_POLICY_FILE: maxQueryTables must not be negative")
+		}
+		p.MaxQueryTables = *file.MaxQueryTables
+	}
+	if file.MaxQueryLength != nil {
+		if *file.MaxQueryLength < 0 {
+			return fmt.Errorf("invalid TRINO_POLICY_FILE: maxQueryLength must not be negative")
+		}
+		p.MaxQueryLength = *file.MaxQueryLength
+	}
+	if file.MaxQueryJoins != nil {
+		if *file.MaxQueryJoins < 0 {
+			return fmt.Errorf("invalid TRINO_POLICY_FILE: maxQueryJoins must not be negative")
+		}
+		p.MaxQueryJoins = *file.MaxQueryJoins
+	}
+	if file.MaxSubqueryDepth != nil {
+		if *file.MaxSubqueryDepth < 0 {
+			return fmt.Errorf("invalid TRINO_POLICY_FILE: maxSubqueryDepth must not be negative")
+		}
+		p.MaxSubqueryDepth = *file.MaxSubqueryDepth
+	}
+	if file.AllowedProcedures != nil {
+		procedures, err := normalizeProcedures(cleanList(*file.AllowedProcedures))
+		if err != nil {
+			return fmt.Errorf("invalid TRINO_POLICY_FILE: %w", err)
+		}
+		p.AllowedProcedures = procedures
+	}
+	if file.MaxResultRows != nil {
+		if *file.MaxResultRows < 0 {
+			return fmt.Errorf("invalid TRINO_POLICY_FILE: maxResultRows must not be negative")
+		}
+		p.MaxResultRows = *file.MaxResultRows
+	}
+	if file.MaxResultBytes != nil {
+		if *file.MaxResultBytes < 0 {
+			return fmt.Errorf("invalid TRINO_POLICY_FILE: maxResultBytes must not be negative")
+		}
+		p.MaxResultBytes = *file.MaxResultBytes
+	}
+	if limit := file.RateLimit; limit != nil {
+		if limit.RequestsPerMinute != nil {
+			if *limit.RequestsPerMinute < 0 {
+				return fmt.Errorf("invalid TRINO_POLICY_FILE: rateLimit.requestsPerMinute must not be negative")
+			}
+			p.RateLimitRequestsPerMinute = *limit.RequestsPerMinute
+		}
+		if limit.QueriesPerHour != nil {
+			if *limit.QueriesPerHour < 0 {
+				return fmt.Errorf("invalid TRINO_POLICY_FILE: rateLimit.queriesPerHour must not be negative")
+			}
+			p.RateLimitQueriesPerHour = *limit.QueriesPerHour
+		}
+	}
+	if quota := file.Quota; quota != nil {
+		if quota.QueriesPerDay != nil {
+			if *quota.QueriesPerDay < 0 {
+				return fmt.Errorf("invalid TRINO_POLICY_FILE: quota.queriesPerDay must not be negative")
+			}
+			p.QuotaQueriesPerDay = *quota.QueriesPerDay
+		}
+		if quota.ScannedBytesPerDay != nil {
+			if *quota.ScannedBytesPerDay < 0 {
+				return fmt.Errorf("invalid TRINO_POLICY_FILE: quota.scannedBytesPerDay must not be negative")
+			}
+			p.QuotaScannedBytesPerDay = *quota.ScannedBytesPerDay
+		}
+	}
+	return nil
+}
+
+// parseColumnMasks parses TRINO_COLUMN_MASKS, a comma-separated list of
+// catalog.schema.table.column=action entries
+func parseColumnMasks(value string) (map[string]string, error) {
+	masks := make(map[string]string)
+	for _, item := range parseAllowlist(value) {
+		column, action, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid format in TRINO_COLUMN_MASKS: '%s' (expected catalog.schema.table.column=action)", item)
+		}
+		masks[column] = action
+	}
+	normalized, err := normalizeColumnMasks(masks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRINO_COLUMN_MASKS: %w", err)
+	}
+	return normalized, nil
+}
+
+// normalizeColumnMasks validates column masks and lower-cases their names and actions
+func normalizeColumnMasks(masks map[string]string) (map[string]string, error) {
+	if len(masks) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string]string, len(masks))
+	for column, action := range masks {
+		column = strings.ToLower(strings.TrimSpace(column))
+		action = strings.ToLower(strings.TrimSpace(action))
+		parts := strings.Split(column, ".")
+		if len(parts) != 4 || containsFold(parts, "") {
+			return nil, fmt.Errorf("column '%s' must have catalog.schema.table.column format", column)
+		}
+		switch action {
+		case MaskDrop, MaskSHA256, MaskRedact, MaskNull:
+		default:
+			return nil, fmt.Errorf("unknown mask '%s' for column '%s' (allowed: drop, sha256, redact, null)", action, column)
+		}
+		normalized[column] = action
+	}
+	return normalized, nil
+}
+
+// parseRequiredPartitionFilters parses TRINO_REQUIRED_PARTITION_FILTERS, a
+// comma-separated list of catalog.schema.table=column entries; several
+// partition columns of a table are separated by |
+func parseRequiredPartitionFilters(value string) (map[string][]string, error) {
+	filters := make(map[string][]string)
+	for _, item := range parseAllowlist(value) {
+		table, columns, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid format in TRINO_REQUIRED_PARTITION_FILTERS: '%s' (expected catalog.schema.table=column)", item)
+		}
+		filters[table] = append(filters[table], strings.Split(columns, "|")...)
+	}
+	normalized, err := normalizeRequiredPartitionFilters(filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRINO_REQUIRED_PARTITION_FILTERS: %w", err)
+	}
+	return normalized, nil
+}
+
+// normalizeRequiredPartitionFilters validates required partition filters and
+// lower-cases their table and column names
+func normalizeRequiredPartitionFilters(filters map[string][]string) (map[string][]string, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string][]string, len(filters))
+	for table, columns := range filters {
+		table = strings.ToLower(strings.TrimSpace(table))
+		parts := strings.Split(table, ".")
+		if len(parts) != 3 || containsFold(parts, "") {
+			return nil, fmt.Errorf("table '%s' must have catalog.schema.table format", table)
+		}
+		for _, column := range columns {
+			column = strings.ToLower(strings.TrimSpace(column))
+			if column == "" || strings.Contains(column, ".") {
+				return nil, fmt.Errorf("invalid partition column '%s' for table '%s'", column, table)
+			}
+			normalized[table] = append(normalized[table], column)
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeProcedures validates procedure allowlist entries, which name a
+// procedure in any catalog as schema.procedure or in one catalog as
+// catalog.schema.procedure, and lower-cases them
+func normalizeProcedures(procedures []string) ([]string, error) {
+	var normalized []string
+	for _, procedure := range procedures {
+		procedure = strings.ToLower(procedure)
+		parts := strings.Split(procedure, ".")
+		if len(parts) < 2 || len(parts) > 3 || containsFold(parts, "") {
+			return nil, fmt.Errorf("procedure '%s' must have schema.procedure or catalog.schema.procedure format", procedure)
+		}
+		normalized = append(normalized, procedure)
+	}
+	return normalized, nil
+}
+
+// loadBlockedQueryPatterns reads query blocking rules, one regular expression
+// per line, from TRINO_BLOCKED_QUERY_PATTERNS or TRINO_BLOCKED_QUERY_PATTERNS_FILE.
+// Blank lines and lines starting with # are ignored; matching is case-insensitive.
+func loadBlockedQueryPatterns() ([]*regexp.Regexp, error) {
+	data := getEnv("TRINO_BLOCKED_QUERY_PATTERNS", "")
+	source := "TRINO_BLOCKED_QUERY_PATTERNS"
+	if path := getEnv("TRINO_BLOCKED_QUERY_PATTERNS_FILE", ""); path != "" {
+		if data != "" {
+			return nil, fmt.Errorf("set only one of TRINO_BLOCKED_QUERY_PATTERNS and TRINO_BLOCKED_QUERY_PATTERNS_FILE")
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TRINO_BLOCKED_QUERY_PATTERNS_FILE: %w", err)
+		}
+		data = string(content)
+		source = "TRINO_BLOCKED_QUERY_PATTERNS_FILE"
+	}
+
+	var patterns []*regexp.Regexp
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern, err := regexp.Compile("(?i)" + line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: line %d: %w", source, i+1, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// cleanList trims entries and drops empty ones, like parseAllowlist
+func cleanList(items []string) []string {
+	var result []string
+	for _, item := range items {
+		if cleaned := strings.TrimSpace(item); cleaned != "" {
+			result = append(result, cleaned)
+		}
+	}
+	return result
+}
+
+// logColumnMasks logs the configured column masks
+func logColumnMasks(masks map[string]string) {
+	if len(masks) == 0 {
+		return
+	}
+	columns := make([]string, 0, len(masks))
+	for column, action := range masks {
+		columns = append(columns, column+" -> "+action)
+	}
+	sort.Strings(columns)
+	log.Printf("INFO: Column masks: %s", strings.Join(columns, ", "))
+}
+
+// logBlockedQueryPatterns logs the number of query blocking rules
+func logBlockedQueryPatterns(patterns []*regexp.Regexp) {
+	if len(patterns) > 0 {
+		log.Printf("INFO: %d blocked query pattern(s) configured", len(patterns))
+	}
+}
+
+// logRequiredPartitionFilters logs the tables that require a partition filter
+func logRequiredPartitionFilters(filters map[string][]string) {
+	if len(filters) == 0 {
+		return
+	}
+	tables := make([]string, 0, len(filters))
+	for table, columns := range filters {
+		tables = append(tables, table+" -> "+strings.Join(columns, "|"))
+	}
+	sort.Strings(tables)
+	log.Printf("INFO: Required partition filters: %s", strings.Join(tables, ", "))
+}
+
+// logQueryScope logs the limits on the tables a query may reference and on
+// its complexity
+func logQueryScope(policy *Policy) {
+	if policy.BlockCrossCatalogQueries {
+		log.Printf("INFO: Queries referencing tables of more than one catalog are rejected")
+	}
+	if policy.MaxQueryTables > 0 || policy.MaxQueryLength > 0 || policy.MaxQueryJoins > 0 || policy.MaxSubqueryDepth > 0 {
+		log.Printf("INFO: Query complexity limits: max tables %d, max length %d, max joins %d, max subquery depth %d (0 means unlimited)",
+			policy.MaxQueryTables, policy.MaxQueryLength, policy.MaxQueryJoins, policy.MaxSubqueryDepth)
+	}
+}
+
+// logAllowedProcedures logs the procedures call_procedure may run
+func logAllowedProcedures(procedures []string) {
+	if len(procedures) > 0 {
+		log.Printf("INFO: Allowed procedures: %s", strings.Join(procedures, ", "))
+	}
+}
+
+// logRateLimits logs the per-client rate limits when any is set
+func logRateLimits(policy *Policy) {
+	if policy.RateLimitRequestsPerMinute > 0 || policy.RateLimitQueriesPerHour > 0 {
+		log.Printf("INFO: Per-client rate limits (HTTP mode): %d requests/minute, %d queries/hour (0 means unlimited)",
+			policy.RateLimitRequestsPerMinute, policy.RateLimitQueriesPerHour)
+	}
+}
+
+// logQuotas logs the per-client daily quotas when any is set
+func logQuotas(policy *Policy) {
+	if policy.QuotaQueriesPerDay > 0 || policy.QuotaScannedBytesPerDay > 0 {
+		log.Printf("INFO: Per-client daily quotas: %d queries, %d scanned bytes (0 means unlimited)",
+			policy.QuotaQueriesPerDay, policy.QuotaScannedBytesPerDay)
+	}
+}
+
+// Reload re-reads TRINO_POLICY_FILE and TRINO_CLUSTERS_FILE and returns a copy
+// of c with the new allowlists, result limits, rate limits and quotas, and the
+// Trino credentials with secret references resolved again. Other connection
+// settings, and clusters added or removed since startup, only take effect after a restart.
+func (c *TrinoConfig) Reload() (*TrinoConfig, error) {
+	policy, err := loadPolicy()
+	if err != nil {
+		return nil, err
+	}
+	reloaded := *c
+	reloaded.AllowedCatalogs = policy.AllowedCatalogs
+	reloaded.AllowedSchemas = policy.AllowedSchemas
+	reloaded.AllowedTables = policy.AllowedTables
+	reloaded.ColumnMasks = policy.ColumnMasks
+	reloaded.BlockedQueryPatterns = policy.BlockedQueryPatterns
+	reloaded.RequiredPartitionFilters = policy.RequiredPartitionFilters
+	reloaded.BlockCrossCatalogQueries = policy.BlockCrossCatalogQueries
+	reloaded.MaxQueryTables = policy.MaxQueryTables
+	reloaded.MaxQueryLength = policy.MaxQueryLength
+	reloaded.MaxQueryJoins = policy.MaxQueryJoins
+	reloaded.MaxSubqueryDepth = policy.MaxSubqueryDepth
+	if !c.ReadOnly {
+		reloaded.AllowedProcedures = policy.AllowedProcedures
+	}
+	reloaded.MaxResultRows = policy.MaxResultRows
+	reloaded.MaxResultBytes = policy.MaxResultBytes
+	reloaded.RateLimitRequestsPerMinute = policy.RateLimitRequestsPerMinute
+	reloaded.RateLimitQueriesPerHour = policy.RateLimitQueriesPerHour
+	reloaded.QuotaQueriesPerDay = policy.QuotaQueriesPerDay
+	reloaded.QuotaScannedBytesPerDay = policy.QuotaScannedBytesPerDay
+
+	// Secret references are resolved again, so rotated Trino credentials apply
+	secrets, err := loadSecrets("TRINO_PASSWORD")
+	if err != nil {
+		return nil, err
+	}
+	reloaded.jwt, reloaded.jwtErr = loadJWT()
+	if reloaded.jwtErr != nil && (c.Auth == AuthJWT || len(c.AuthMethods) == 0) {
+		return nil, reloaded.jwtErr
+	}
+	reloaded.password = secrets["TRINO_PASSWORD"]
+	if reloaded.password == "" && c.Auth == AuthPassword {
+		// A password entered at the TRINO_AUTH=password prompt is kept
+		reloaded.password = c.password
+	}
+	reloaded.Password, reloaded.AccessToken = reloaded.password, reloaded.jwt
+	if c.Auth != "" {
+		derived, err := reloaded.ForAuth(c.Auth)
+		if err != nil {
+			return nil, err
+		}
+		reloaded = *derived
+	}
+
+	if len(c.Clusters) > 0 {
+		clusters, err := loadClusters()
+		if err != nil {
+			return nil, err
+		}
+		reloaded.Clusters = make([]ClusterConfig, len(c.Clusters))
+		copy(reloaded.Clusters, c.Clusters)
+		for i := range reloaded.Clusters {
+			current := &reloaded.Clusters[i]
+			found := false
+			for _, cl := range clusters {
+				if strings.EqualFold(cl.Name, current.Name) {
+					current.AllowedCatalogs = cl.AllowedCatalogs
+					current.AllowedSchemas = cl.AllowedSchemas
+					current.AllowedTables = cl.AllowedTables
+					current.Password = cl.Password
+					found = true
+					break
+				}
+			}
+			if !found {
+				log.Printf("WARNING: Cluster '%s' was removed from the configuration; keeping it until restart", current.Name)
+			}
+		}
+		for _, cl := range clusters {
+			if !clusterExists(c.Clusters, cl.Name) {
+				log.Printf("WARNING: New cluster '%s' is ignored until restart", cl.Name)
+			}
+		}
+	}
+
+	logAllowlistConfiguration(reloaded.AllowedCatalogs, reloaded.AllowedSchemas, reloaded.AllowedTables)
+	logColumnMasks(policy.ColumnMasks)
+	logRequiredPartitionFilters(policy.RequiredPartitionFilters)
+	logQueryScope(policy)
+	logAllowedProcedures(reloaded.AllowedProcedures)
+	log.Printf("INFO: Query result limits: max rows %d, max bytes %d (0 means unlimited)", reloaded.MaxResultRows, reloaded.MaxResultBytes)
+	logRateLimits(policy)
+	logQuotas(policy)
+	return &reloaded, nil
+}
@@ -0,0 +1,363 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPolicyFile(t *testing.T) {
+	envVars := []string{
+		"TRINO_POLICY_FILE", "TRINO_ALLOWED_CATALOGS", "TRINO_ALLOWED_SCHEMAS",
+		"TRINO_ALLOWED_TABLES", "TRINO_MAX_RESULT_ROWS", "TRINO_MAX_RESULT_BYTES",
+		"TRINO_COLUMN_MASKS", "MCP_RATE_LIMIT_REQUESTS_PER_MINUTE", "MCP_RATE_LIMIT_QUERIES_PER_HOUR",
+		"TRINO_REQUIRED_PARTITION_FILTERS", "MCP_QUOTA_QUERIES_PER_DAY", "MCP_QUOTA_SCANNED_BYTES_PER_DAY",
+	}
+	original := make(map[string]string)
+	for _, name := range envVars {
+		original[name] = os.Getenv(name)
+	}
+	defer func() {
+		for name, value := range original {
+			_ = os.Setenv(name, value)
+		}
+	}()
+
+	tests := []struct {
+		name        string
+		content     string
+		want        *Policy
+		expectError bool
+	}{
+		{
+			name:    "File overrides present fields only",
+			content: `{"allowedCatalogs": ["iceberg", " "], "maxResultRows": 500}`,
+			want: &Policy{
+				AllowedCatalogs: []string{"iceberg"},
+				AllowedSchemas:  []string{"hive.analytics"},
+				MaxResultRows:   500,
+				MaxResultBytes:  1024,
+			},
+		},
+		{
+			name:    "Empty list clears the environment allowlist",
+			content: `{"allowedSchemas": [], "maxResultBytes": 0}`,
+			want: &Policy{
+				AllowedCatalogs: []string{"hive"},
+				MaxResultRows:   100,
+			},
+		},
+		{
+			name:    "Rate limits",
+			content: `{"rateLimit": {"requestsPerMinute": 60, "queriesPerHour": 100}}`,
+			want: &Policy{
+				AllowedCatalogs:            []string{"hive"},
+				AllowedSchemas:             []string{"hive.analytics"},
+				MaxResultRows:              100,
+				MaxResultBytes:             1024,
+				RateLimitRequestsPerMinute: 60,
+				RateLimitQueriesPerHour:    100,
+			},
+		},
+		{
+			name:    "Daily quotas",
+			content: `{"quota": {"queriesPerDay": 500, "scannedBytesPerDay": 1099511627776}}`,
+			want: &Policy{
+				AllowedCatalogs:         []string{"hive"},
+				AllowedSchemas:          []string{"hive.analytics"},
+				MaxResultRows:           100,
+				MaxResultBytes:          1024,
+				QuotaQueriesPerDay:      500,
+				QuotaScannedBytesPerDay: 1 << 40,
+			},
+		},
+		{
+			name:    "Column masks",
+			content: `{"columnMasks": {"Hive.Analytics.Users.Email": "SHA256", "hive.analytics.users.ssn": "drop"}}`,
+			want: &Policy{
+				AllowedCatalogs: []string{"hive"},
+				AllowedSchemas:  []string{"hive.analytics"},
+				ColumnMasks:     map[string]string{"hive.analytics.users.email": MaskSHA256, "hive.analytics.users.ssn": MaskDrop},
+				MaxResultRows:   100,
+				MaxResultBytes:  1024,
+			},
+		},
+		{
+			name:    "Required partition filters",
+			content: `{"requiredPartitionFilters": {"Hive.Analytics.Events": ["DS", "hour"]}}`,
+			want: &Policy{
+				AllowedCatalogs:          []string{"hive"},
+				AllowedSchemas:           []string{"hive.analytics"},
+				RequiredPartitionFilters: map[string][]string{"hive.analytics.events": {"ds", "hour"}},
+				MaxResultRows:            100,
+				MaxResultBytes:           1024,
+			},
+		},
+		{
+			name:    "Allowed procedures",
+			content: `{"allowedProcedures": ["System.Sync_Partition_Metadata", " iceberg.system.rollback_to_snapshot "]}`,
+			want: &Policy{
+				AllowedCatalogs:   []string{"hive"},
+				AllowedSchemas:    []string{"hive.analytics"},
+				AllowedProcedures: []string{"system.sync_partition_metadata", "iceberg.system.rollback_to_snapshot"},
+				MaxResultRows:     100,
+				MaxResultBytes:    1024,
+			},
+		},
+		{
+			name:    "Query scope",
+			content: `{"blockCrossCatalogQueries": true, "maxQueryTables": 5, "maxQueryLength": 10000, "maxQueryJoins": 4, "maxSubqueryDepth": 3}`,
+			want: &Policy{
+				AllowedCatalogs:          []string{"hive"},
+				AllowedSchemas:           []string{"hive.analytics"},
+				BlockCrossCatalogQueries: true,
+				MaxQueryTables:           5,
+				MaxQueryLength:           10000,
+				MaxQueryJoins:            4,
+				MaxSubqueryDepth:         3,
+				MaxResultRows:            100,
+				MaxResultBytes:           1024,
+			},
+		},
+		{
+			name:        "Negative table limit",
+			content:     `{"maxQueryTables": -1}`,
+			expectError: true,
+		},
+		{
+			name:        "Negative subquery depth",
+			content:     `{"maxSubqueryDepth": -1}`,
+			expectError: true,
+		},
+		{
+			name:        "Procedure without schema",
+			content:     `{"allowedProcedures": ["sync_partition_metadata"]}`,
+			expectError: true,
+		},
+		{
+			name:        "Partition filter without schema",
+			content:     `{"requiredPartitionFilters": {"events": ["ds"]}}`,
+			expectError: true,
+		},
+		{
+			name:        "Unknown mask",
+			content:     `{"columnMasks": {"hive.analytics.users.email": "md5"}}`,
+			expectError: true,
+		},
+		{
+			name:        "Mask without table",
+			content:     `{"columnMasks": {"users.email": "drop"}}`,
+			expectError: true,
+		},
+		{
+			name:        "Negative rate limit",
+			content:     `{"rateLimit": {"queriesPerHour": -1}}`,
+			expectError: true,
+		},
+		{
+			name:        "Negative quota",
+			content:     `{"quota": {"scannedBytesPerDay": -1}}`,
+			expectError: true,
+		},
+		{
+			name:        "Unknown field",
+			content:     `{"allowedCatalog": ["hive"]}`,
+			expectError: true,
+		},
+		{
+			name:        "Malformed table allowlist",
+			content:     `{"allowedTables": ["hive.users"]}`,
+			expectError: true,
+		},
+		{
+			name:        "Negative limit",
+			content:     `{"maxResultRows": -1}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range envVars {
+				_ = os.Unsetenv(name)
+			}
+			_ = os.Setenv("TRINO_ALLOWED_CATALOGS", "hive")
+			_ = os.Setenv("TRINO_ALLOWED_SCHEMAS", "hive.analytics")
+			_ = os.Setenv("TRINO_MAX_RESULT_ROWS", "100")
+			_ = os.Setenv("TRINO_MAX_RESULT_BYTES", "1024")
+
+			path := filepath.Join(t.TempDir(), "policy.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_ = os.Setenv("TRINO_POLICY_FILE", path)
+
+			policy, err := loadPolicy()
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("loadPolicy() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadPolicy() error = %v", err)
+			}
+			if !reflect.DeepEqual(policy, tt.want) {
+				t.Errorf("loadPolicy() = %+v, want %+v", policy, tt.want)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	envVars := []string{"TRINO_POLICY_FILE", "TRINO_CLUSTERS_JSON", "TRINO_CLUSTERS_FILE", "TRINO_ALLOWED_CATALOGS"}
+	original := make(map[string]string)
+	for _, name := range envVars {
+		original[name] = os.Getenv(name)
+	}
+	defer func() {
+		for name, value := range original {
+			_ = os.Setenv(name, value)
+		}
+	}()
+	for _, name := range envVars {
+		_ = os.Unsetenv(name)
+	}
+
+	dir := t.TempDir()
+	policyPath := filepath.Join(dir, "policy.json")
+	clustersPath := filepath.Join(dir, "clusters.json")
+	write := func(path, content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(policyPath, `{"allowedCatalogs": ["hive"]}`)
+	write(clustersPath, `[{"name":"prod","host":"prod.example.com","allowedCatalogs":["hive"]}]`)
+	_ = os.Setenv("TRINO_POLICY_FILE", policyPath)
+	_ = os.Setenv("TRINO_CLUSTERS_FILE", clustersPath)
+
+	base, err := NewTrinoConfig()
+	if err != nil {
+		t.Fatalf("NewTrinoConfig() error = %v", err)
+	}
+
+	write(policyPath, `{"allowedCatalogs": ["iceberg"], "maxResultRows": 50}`)
+	write(clustersPath, `[{"name":"prod","host":"changed.example.com","allowedCatalogs":["iceberg"]},{"name":"new","host":"new.example.com"}]`)
+	reloaded, err := base.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(reloaded.AllowedCatalogs, []string{"iceberg"}) || reloaded.MaxResultRows != 50 {
+		t.Errorf("reloaded policy = %+v", reloaded.Policy())
+	}
+	if !reflect.DeepEqual(base.AllowedCatalogs, []string{"hive"}) {
+		t.Error("Reload() modified the original configuration")
+	}
+	if len(reloaded.Clusters) != 1 {
+		t.Fatalf("Reload() clusters = %+v, want the startup cluster only", reloaded.Clusters)
+	}
+	if cl := reloaded.Clusters[0]; cl.Host != "prod.example.com" || !reflect.DeepEqual(cl.AllowedCatalogs, []string{"iceberg"}) {
+		t.Errorf("reloaded cluster = %+v, want new allowlist with unchanged host", cl)
+	}
+	if !reflect.DeepEqual(base.Clusters[0].AllowedCatalogs, []string{"hive"}) {
+		t.Error("Reload() modified the original cluster configuration")
+	}
+
+	// A broken file keeps the current settings
+	write(policyPath, `{`)
+	if _, err := base.Reload(); err == nil {
+		t.Error("Reload() expected error for invalid policy file")
+	}
+}
+
+func TestParseColumnMasks(t *testing.T) {
+	masks, err := parseColumnMasks("hive.analytics.users.email=sha256, hive.analytics.users.ssn = drop")
+	if err != nil {
+		t.Fatalf("parseColumnMasks() error = %v", err)
+	}
+	want := map[string]string{"hive.analytics.users.email": MaskSHA256, "hive.analytics.users.ssn": MaskDrop}
+	if !reflect.DeepEqual(masks, want) {
+		t.Errorf("parseColumnMasks() = %v, want %v", masks, want)
+	}
+
+	for _, value := range []string{"hive.analytics.users.email", "hive.analytics.users.email=hash", "hive..users.email=drop"} {
+		if _, err := parseColumnMasks(value); err == nil {
+			t.Errorf("parseColumnMasks(%q) expected error", value)
+		}
+	}
+	if masks, err := parseColumnMasks(""); err != nil || masks != nil {
+		t.Errorf("parseColumnMasks(\"\") = %v, %v; want no masks", masks, err)
+	}
+}
+
+func TestParseRequiredPartitionFilters(t *testing.T) {
+	filters, err := parseRequiredPartitionFilters("hive.analytics.events=ds, hive.logs.requests = ds|hour")
+	if err != nil {
+		t.Fatalf("parseRequiredPartitionFilters() error = %v", err)
+	}
+	want := map[string][]string{"hive.analytics.events": {"ds"}, "hive.logs.requests": {"ds", "hour"}}
+	if !reflect.DeepEqual(filters, want) {
+		t.Errorf("parseRequiredPartitionFilters() = %v, want %v", filters, want)
+	}
+
+	for _, value := range []string{"hive.analytics.events", "analytics.events=ds", "hive.analytics.events=", "hive.analytics.events=ds|", "hive.analytics.events=e.ds"} {
+		if _, err := parseRequiredPartitionFilters(value); err == nil {
+			t.Errorf("parseRequiredPartitionFilters(%q) expected error", value)
+		}
+	}
+	if filters, err := parseRequiredPartitionFilters(""); err != nil || filters != nil {
+		t.Errorf("parseRequiredPartitionFilters(\"\") = %v, %v; want no filters", filters, err)
+	}
+}
+
+func TestBlockedQueryPatterns(t *testing.T) {
+	envVars := []string{"TRINO_BLOCKED_QUERY_PATTERNS", "TRINO_BLOCKED_QUERY_PATTERNS_FILE"}
+	original := make(map[string]string)
+	for _, name := range envVars {
+		original[name] = os.Getenv(name)
+	}
+	defer func() {
+		for name, value := range original {
+			_ = os.Setenv(name, value)
+		}
+	}()
+	reset := func() {
+		for _, name := range envVars {
+			_ = os.Unsetenv(name)
+		}
+	}
+
+	reset()
+	_ = os.Setenv("TRINO_BLOCKED_QUERY_PATTERNS", "\\bcross join\\b\n# comment\n\ninformation_schema\\.table_privileges")
+	patterns, err := loadBlockedQueryPatterns()
+	if err != nil {
+		t.Fatalf("loadBlockedQueryPatterns() error = %v", err)
+	}
+	if len(patterns) != 2 || !patterns[0].MatchString("SELECT * FROM a CROSS JOIN b") {
+		t.Errorf("loadBlockedQueryPatterns() = %v, want 2 case-insensitive patterns", patterns)
+	}
+
+	reset()
+	path := filepath.Join(t.TempDir(), "blocked.txt")
+	if err := os.WriteFile(path, []byte("# Block expensive joins\n\\bcross join\\b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Setenv("TRINO_BLOCKED_QUERY_PATTERNS_FILE", path)
+	if patterns, err := loadBlockedQueryPatterns(); err != nil || len(patterns) != 1 {
+		t.Errorf("loadBlockedQueryPatterns() from file = %v, %v; want 1 pattern", patterns, err)
+	}
+
+	// Both sources at once, and invalid expressions, are errors
+	_ = os.Setenv("TRINO_BLOCKED_QUERY_PATTERNS", "drop")
+	if _, err := loadBlockedQueryPatterns(); err == nil {
+		t.Error("loadBlockedQueryPatterns() expected error when both variables are set")
+	}
+	reset()
+	_ = os.Setenv("TRINO_BLOCKED_QUERY_PATTERNS", "select (")
+	if _, err := loadBlockedQueryPatterns(); err == nil {
+		t.Error("loadBlockedQueryPatterns() expected error for an invalid pattern")
+	}
+}
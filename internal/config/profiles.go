@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// settingName matches the environment variables a profile may set
+var settingName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// profilesFile returns MCP_TRINO_PROFILES_FILE, or profiles.json in the
+// mcp-trino directory of the user's configuration directory
+func profilesFile() string {
+	if path := getEnv("MCP_TRINO_PROFILES_FILE", ""); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mcp-trino", "profiles.json")
+}
+
+// applyProfile sets the environment variables of the profile named by
+// MCP_TRINO_PROFILE, so that one installed configuration serves several
+// environments. The profiles file is a JSON object of profiles, each an object
+// of settings such as TRINO_HOST or TRINO_MAX_RESULT_ROWS. Variables already
+// set in the environment take precedence over the profile.
+func applyProfile() error {
+	name := strings.TrimSpace(getEnv("MCP_TRINO_PROFILE", ""))
+	if name == "" {
+		return nil
+	}
+	path := profilesFile()
+	if path == "" {
+		return fmt.Errorf("MCP_TRINO_PROFILE requires MCP_TRINO_PROFILES_FILE")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read profiles for MCP_TRINO_PROFILE: %w", err)
+	}
+	var profiles map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(content, &profiles); err != nil {
+		return fmt.Errorf("invalid profiles file %s: expected a JSON object of profiles: %w", path, err)
+	}
+
+	profile, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("invalid MCP_TRINO_PROFILE '%s': profiles in %s are %s", name, path, strings.Join(names, ", "))
+	}
+
+	// Check every setting before any is applied
+	values := make(map[string]string, len(profile))
+	for key, raw := range profile {
+		if !settingName.MatchString(key) || strings.HasPrefix(key, "MCP_TRINO_PROFILE") {
+			return fmt.Errorf("invalid profile '%s' in %s: '%s' is not a setting", name, path, key)
+		}
+		// Numbers and booleans may be written unquoted
+		var parsed interface{}
+		_ = json.Unmarshal(raw, &parsed)
+		switch v := parsed.(type) {
+		case string:
+			values[key] = v
+		case float64, bool:
+			values[key] = string(raw)
+		default:
+			return fmt.Errorf("invalid profile '%s' in %s: %s must be a string, number or boolean", name, path, key)
+		}
+	}
+
+	var applied, overridden []string
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); set {
+			overridden = append(overridden, key)
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to apply %s of profile '%s': %w", key, name, err)
+		}
+		applied = append(applied, key)
+	}
+
+	// Values may be secrets, so only names are logged
+	sort.Strings(applied)
+	log.Printf("INFO: Using profile '%s' from %s: %s", name, path, strings.Join(applied, ", "))
+	if len(overridden) > 0 {
+		sort.Strings(overridden)
+		log.Printf("INFO: Environment overrides profile '%s' for %s", name, strings.Join(overridden, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(`{
+		"dev":  {"TRINO_HOST": "localhost", "TRINO_PORT": 8080, "TRINO_SCHEME": "http", "TRINO_SSL": false, "TRINO_ALLOW_WRITE_QUERIES": true},
+		"prod": {"TRINO_HOST": "trino.example.com", "TRINO_ALLOWED_CATALOGS": "hive", "TRINO_MAX_RESULT_ROWS": 1000},
+		"bad":  {"TRINO_ALLOWED_CATALOGS": ["hive"]}
+	}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OAUTH_ENABLED", "false")
+	t.Setenv("MCP_TRINO_PROFILES_FILE", path)
+	// Registered with t.Setenv so that what the profile sets is undone after the test
+	for _, key := range []string{"TRINO_HOST", "TRINO_PORT", "TRINO_SCHEME", "TRINO_SSL", "TRINO_ALLOW_WRITE_QUERIES", "TRINO_ALLOWED_CATALOGS", "TRINO_MAX_RESULT_ROWS"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+
+	t.Setenv("MCP_TRINO_PROFILE", "dev")
+	config, err := NewTrinoConfig()
+	if err != nil {
+		t.Fatalf("NewTrinoConfig() with dev profile error = %v", err)
+	}
+	if config.Host != "localhost" || config.Port != 8080 || config.SSL || !config.AllowWriteQueries {
+		t.Errorf("dev profile: host %s:%d, SSL %v, writes %v", config.Host, config.Port, config.SSL, config.AllowWriteQueries)
+	}
+
+	// Variables set in the environment win over the profile
+	t.Setenv("MCP_TRINO_PROFILE", "prod")
+	t.Setenv("TRINO_HOST", "trino-canary.example.com")
+	t.Setenv("TRINO_MAX_RESULT_ROWS", "")
+	os.Unsetenv("TRINO_MAX_RESULT_ROWS")
+	config, err = NewTrinoConfig()
+	if err != nil {
+		t.Fatalf("NewTrinoConfig() with prod profile error = %v", err)
+	}
+	if config.Host != "trino-canary.example.com" || config.MaxResultRows != 1000 || len(config.AllowedCatalogs) != 1 {
+		t.Errorf("prod profile: host %s, max rows %d, catalogs %v", config.Host, config.MaxResultRows, config.AllowedCatalogs)
+	}
+
+	for profile, want := range map[string]string{"staging": "profiles in", "bad": "must be a string"} {
+		t.Setenv("MCP_TRINO_PROFILE", profile)
+		if _, err := NewTrinoConfig(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("profile %s: NewTrinoConfig() error = %v, want %q", profile, err, want)
+		}
+	}
+}
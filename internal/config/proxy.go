@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// parseProxyURL reads TRINO_PROXY_URL, the egress proxy for Trino connections.
+// Without it, HTTPS_PROXY, HTTP_PROXY and NO_PROXY apply as usual.
+func parseProxyURL() (string, error) {
+	proxyURL := strings.TrimSpace(getEnv("TRINO_PROXY_URL", ""))
+	if proxyURL == "" {
+		return "", nil
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid TRINO_PROXY_URL: must be a URL such as http://proxy.example.com:3128")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return "", fmt.Errorf("invalid TRINO_PROXY_URL scheme '%s': must be http, https or socks5", u.Scheme)
+	}
+	// The URL may carry proxy credentials
+	log.Printf("INFO: Trino connections use proxy %s (hosts in NO_PROXY connect directly)", u.Redacted())
+	return proxyURL, nil
+}
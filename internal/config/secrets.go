@@ -0,0 +1,142 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// secretResolveTimeout bounds the lookup of one secret reference
+const secretResolveTimeout = 10 * time.Second
+
+// secretResolver returns the secret named by ref, the part of a reference after scheme://
+type secretResolver func(ctx context.Context, ref string) (string, error)
+
+// secretResolvers maps the schemes of secret references to their resolvers
+var secretResolvers = map[string]secretResolver{
+	"vault":  resolveVaultSecret,
+	"aws-sm": resolveAWSSecret,
+}
+
+// resolveSecret returns value, or the secret it references when it has the
+// form scheme://ref for a known secret store. Name identifies the setting in errors.
+func resolveSecret(name, value string) (string, error) {
+	scheme, ref, found := strings.Cut(value, "://")
+	resolve, ok := secretResolvers[scheme]
+	if !found || !ok {
+		return value, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+	secret, err := resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s from %s: %w", name, value, err)
+	}
+	return secret, nil
+}
+
+// resolveVaultSecret reads path#key from Vault's HTTP API at VAULT_ADDR, using
+// VAULT_TOKEN (or VAULT_TOKEN_FILE) and VAULT_NAMESPACE. KV version 2 secrets
+// are unwrapped, so vault://secret/data/trino#password reads the password field.
+func resolveVaultSecret(ctx context.Context, ref string) (string, error) {
+	addr := strings.TrimSuffix(getEnv("VAULT_ADDR", ""), "/")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token, err := readSecret("VAULT_TOKEN")
+	if err != nil {
+		return "", err
+	}
+	path, key, _ := strings.Cut(ref, "#")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := getEnv("VAULT_NAMESPACE", ""); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&secret); err != nil {
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
+	fields := secret.Data
+	if inner, ok := fields["data"].(map[string]interface{}); ok && fields["metadata"] != nil {
+		fields = inner
+	}
+	return secretField(fields, key)
+}
+
+// resolveAWSSecret reads a secret from AWS Secrets Manager with the default
+// credential chain. The ref is the secret's name or ARN, optionally followed by
+// #key to pick a field of a JSON secret.
+func resolveAWSSecret(ctx context.Context, ref string) (string, error) {
+	id, key, _ := strings.Cut(ref, "#")
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	if parsed, err := arn.Parse(id); err == nil && parsed.Region != "" {
+		cfg.Region = parsed.Region
+	}
+	out, err := secretsmanager.NewFromConfig(cfg).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", err
+	}
+	value := aws.ToString(out.SecretString)
+	if out.SecretString == nil {
+		value = string(out.SecretBinary)
+	}
+	if key == "" {
+		return value, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, so #%s cannot be selected", key)
+	}
+	return secretField(fields, key)
+}
+
+// secretField returns the named string field of a secret, or its only field when key is empty
+func secretField(fields map[string]interface{}, key string) (string, error) {
+	if key == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("secret has %d fields; select one with #key", len(fields))
+		}
+		for name := range fields {
+			key = name
+		}
+	}
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no field '%s'", key)
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("field '%s' of the secret is not a string", key)
+	}
+	return text, nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveVaultSecret(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" || r.Header.Get("X-Vault-Namespace") != "analytics" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/trino":
+			fmt.Fprint(w, `{"data":{"data":{"password":"kv2-secret","user":"svc"},"metadata":{"version":3}}}`)
+		case "/v1/kv/trino":
+			fmt.Fprint(w, `{"data":{"password":"kv1-secret"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer vault.Close()
+	t.Setenv("VAULT_ADDR", vault.URL+"/")
+	t.Setenv("VAULT_TOKEN", "s.token")
+	t.Setenv("VAULT_NAMESPACE", "analytics")
+
+	tests := []struct {
+		value, want, wantErr string
+	}{
+		{value: "vault://secret/data/trino#password", want: "kv2-secret"},
+		{value: "vault://kv/trino", want: "kv1-secret"},
+		{value: "vault://secret/data/trino", wantErr: "select one with #key"},
+		{value: "vault://secret/data/trino#token", wantErr: "no field 'token'"},
+		{value: "vault://secret/data/missing#password", wantErr: "HTTP 404"},
+		{value: "plain-password", want: "plain-password"},
+		{value: "https://not-a-secret-store", want: "https://not-a-secret-store"},
+	}
+	for _, tt := range tests {
+		got, err := resolveSecret("TRINO_PASSWORD", tt.value)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolveSecret(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resolveSecret(%q) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+
+	t.Setenv("VAULT_TOKEN", "wrong")
+	if _, err := resolveSecret("TRINO_PASSWORD", "vault://kv/trino"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("resolveSecret() with a bad token = %v, want Vault's error", err)
+	}
+}
+
+func TestSecretReferences(t *testing.T) {
+	t.Setenv("OAUTH_ENABLED", "false")
+	original := secretResolvers["aws-sm"]
+	defer func() { secretResolvers["aws-sm"] = original }()
+	password := "first"
+	secretResolvers["aws-sm"] = func(ctx context.Context, ref string) (string, error) {
+		if ref != "prod/trino#password" {
+			return "", fmt.Errorf("no secret %s", ref)
+		}
+		return password, nil
+	}
+	t.Setenv("TRINO_PASSWORD", "aws-sm://prod/trino#password")
+
+	cfg, err := NewTrinoConfig()
+	if err != nil {
+		t.Fatalf("NewTrinoConfig() error = %v", err)
+	}
+	if cfg.Password != "first" {
+		t.Errorf("Password = %q, want the resolved secret", cfg.Password)
+	}
+
+	// Reloading picks up a rotated secret; a failing lookup fails the reload
+	password = "rotated"
+	reloaded, err := cfg.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if reloaded.Password != "rotated" {
+		t.Errorf("Password after reload = %q, want the rotated secret", reloaded.Password)
+	}
+	t.Setenv("TRINO_PASSWORD", "aws-sm://prod/other")
+	if _, err := cfg.Reload(); err == nil || !strings.Contains(err.Error(), "failed to resolve TRINO_PASSWORD") {
+		t.Errorf("Reload() error = %v, want the resolution failure", err)
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Result encodings of Trino's spooling protocol, selected with TRINO_SPOOLING_ENCODING
+const (
+	SpoolingEncodingJSON = "json"
+	SpoolingEncodingZstd = "json+zstd"
+	SpoolingEncodingLZ4  = "json+lz4"
+)
+
+// Spooling configures how results are fetched when Trino has the spooling
+// protocol enabled: Trino writes large results as segments to its spooling
+// storage, and the segments are downloaded directly from there in parallel
+// instead of being paged through the coordinator.
+type Spooling struct {
+	Encodings             []string // Segment encodings in order of preference
+	Workers               int      // Segments downloaded in parallel
+	MaxOutOfOrderSegments int      // Segments buffered ahead of the one being read, at least Workers
+}
+
+// parseSpooling reads TRINO_SPOOLING_ENCODING, TRINO_SPOOLING_WORKERS and
+// TRINO_SPOOLING_MAX_OUT_OF_ORDER_SEGMENTS
+func parseSpooling() (Spooling, error) {
+	spooling := Spooling{
+		Encodings:             parseAllowlist(strings.ToLower(getEnv("TRINO_SPOOLING_ENCODING", "json+zstd,json+lz4,json"))),
+		Workers:               parsePositiveInt("TRINO_SPOOLING_WORKERS", 5),
+		MaxOutOfOrderSegments: parsePositiveInt("TRINO_SPOOLING_MAX_OUT_OF_ORDER_SEGMENTS", 10),
+	}
+	for _, encoding := range spooling.Encodings {
+		switch encoding {
+		case SpoolingEncodingJSON, SpoolingEncodingZstd, SpoolingEncodingLZ4:
+		default:
+			return Spooling{}, fmt.Errorf("invalid TRINO_SPOOLING_ENCODING '%s': must be json, json+zstd or json+lz4", encoding)
+		}
+	}
+	// Every download worker holds a buffer slot, so fewer slots would idle workers
+	if spooling.Workers > spooling.MaxOutOfOrderSegments {
+		log.Printf("WARNING: TRINO_SPOOLING_WORKERS (%d) exceeds TRINO_SPOOLING_MAX_OUT_OF_ORDER_SEGMENTS (%d); buffering %d segments",
+			spooling.Workers, spooling.MaxOutOfOrderSegments, spooling.Workers)
+		spooling.MaxOutOfOrderSegments = spooling.Workers
+	}
+	return spooling, nil
+}
+
+// parsePositiveInt reads a positive integer, warning about and replacing an
+// invalid value with fallback
+func parsePositiveInt(envVar string, fallback int) int {
+	value := getEnv(envVar, strconv.Itoa(fallback))
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("WARNING: Invalid %s '%s', using default of %d", envVar, value, fallback)
+		return fallback
+	}
+	return n
+}
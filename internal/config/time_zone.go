@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // The container image has no zoneinfo files
+)
+
+// LoadTimeZone resolves a Trino session time zone: an IANA zone name such as
+// Europe/Paris, UTC, or a fixed offset such as +05:30
+func LoadTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("time zone must not be empty")
+	}
+	if name[0] == '+' || name[0] == '-' {
+		hours, minutes, found := strings.Cut(name[1:], ":")
+		h, errH := strconv.Atoi(hours)
+		m, errM := strconv.Atoi(minutes)
+		if !found || errH != nil || errM != nil || len(hours) != 2 || len(minutes) != 2 || h > 14 || m > 59 {
+			return nil, fmt.Errorf("invalid time zone offset '%s': use +HH:MM or -HH:MM", name)
+		}
+		offset := h*3600 + m*60
+		if name[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(name, offset), nil
+	}
+	// time.LoadLocation reads "Local" as the server's zone, which Trino does not know
+	if name == "Local" {
+		return nil, fmt.Errorf("invalid time zone '%s'", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone '%s': use an IANA name such as Europe/Paris, UTC or an offset such as +05:30", name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+var januaryFirst = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+func TestLoadTimeZone(t *testing.T) {
+	tests := []struct {
+		name    string
+		offset  int // Seconds east of UTC in January 2024
+		wantErr bool
+	}{
+		{name: "UTC"},
+		{name: "America/New_York", offset: -5 * 3600},
+		{name: "+05:30", offset: 5*3600 + 30*60},
+		{name: "-08:00", offset: -8 * 3600},
+		{name: "Mars/Olympus_Mons", wantErr: true},
+		{name: "+5:30", wantErr: true},
+		{name: "+15:00", wantErr: true},
+		{name: "Local", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		loc, err := LoadTimeZone(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LoadTimeZone(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if _, offset := januaryFirst.In(loc).Zone(); offset != tt.offset {
+			t.Errorf("LoadTimeZone(%q) offset = %d, want %d", tt.name, offset, tt.offset)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package datacatalog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// amundsen reads tables from the Amundsen metadata service
+type amundsen struct {
+	api      *apiClient
+	database string // Database of the table keys, "trino" by default
+}
+
+type amundsenTable struct {
+	Description string `json:"description"`
+	Owners      []struct {
+		Email  string `json:"email"`
+		UserID string `json:"user_id"`
+	} `json:"owners"`
+	Tags []struct {
+		TagName string `json:"tag_name"`
+	} `json:"tags"`
+	Columns []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Badges      []struct {
+			BadgeName string `json:"badge_name"`
+		} `json:"badges"`
+	} `json:"columns"`
+}
+
+func (a *amundsen) table(ctx context.Context, catalog, schema, table string) (*TableMetadata, error) {
+	// Table keys are database://cluster.schema/table, with the Trino catalog as cluster
+	key := fmt.Sprintf("%s://%s.%s/%s", a.database, catalog, schema, table)
+	var resp amundsenTable
+	if err := a.api.do(ctx, http.MethodGet, "/table/"+url.PathEscape(key), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	metadata := &TableMetadata{Description: resp.Description, Columns: make(map[string]ColumnMetadata)}
+	for _, owner := range resp.Owners {
+		if owner.Email != "" {
+			metadata.Owners = appendUnique(metadata.Owners, owner.Email)
+		} else {
+			metadata.Owners = appendUnique(metadata.Owners, owner.UserID)
+		}
+	}
+	for _, tag := range resp.Tags {
+		metadata.Tags = appendUnique(metadata.Tags, tag.TagName)
+	}
+	for _, col := range resp.Columns {
+		column := ColumnMetadata{Description: col.Description}
+		for _, badge := range col.Badges {
+			column.Tags = appendUnique(column.Tags, badge.BadgeName)
+		}
+		metadata.Columns[strings.ToLower(col.Name)] = column
+	}
+	return metadata, nil
+}
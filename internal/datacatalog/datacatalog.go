@@ -0,0 +1,223 @@
+// Package datacatalog fetches table and column descriptions, owners and tags
+// from a data catalog (DataHub, Amundsen or OpenMetadata) so they can be
+// merged into schema output.
+package datacatalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+const (
+	// cacheTTL is how long fetched metadata, or its absence, is reused
+	cacheTTL = 10 * time.Minute
+
+	// fetchWorkers bounds the requests Tables sends at once
+	fetchWorkers = 8
+
+	// MaxListedTables bounds the tables Tables fetches metadata for
+	MaxListedTables = 200
+)
+
+// errNotFound is returned by providers for tables the data catalog does not know
+var errNotFound = errors.New("table not found in data catalog")
+
+// TableMetadata is what a data catalog knows about a table
+type TableMetadata struct {
+	Description string                    `json:"description,omitempty"`
+	Owners      []string                  `json:"owners,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Columns     map[string]ColumnMetadata `json:"-"` // By lowercased column name
+}
+
+// ColumnMetadata is what a data catalog knows about a column
+type ColumnMetadata struct {
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// provider fetches the metadata of one table from a data catalog API
+type provider interface {
+	table(ctx context.Context, catalog, schema, table string) (*TableMetadata, error)
+}
+
+// Catalog fetches table metadata from the configured data catalog, caching
+// results. Metadata is an enrichment: failures are logged and reported as
+// missing metadata rather than failing the tool call.
+type Catalog struct {
+	name     string
+	provider provider
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	metadata  *TableMetadata // nil when the table is unknown
+	fetchedAt time.Time
+}
+
+// New returns the data catalog configured by cfg, or nil if none is
+func New(cfg *config.TrinoConfig) *Catalog {
+	api := &apiClient{
+		baseURL:    cfg.DataCatalogURL,
+		token:      cfg.DataCatalogToken,
+		httpClient: &http.Client{Timeout: cfg.DataCatalogTimeout},
+	}
+	var p provider
+	switch cfg.DataCatalog {
+	case config.DataCatalogDataHub:
+		p = &dataHub{api: api, platformInstance: cfg.DataCatalogService, env: cfg.DataCatalogEnv}
+	case config.DataCatalogAmundsen:
+		p = &amundsen{api: api, database: cfg.DataCatalogService}
+	case config.DataCatalogOpenMetadata:
+		p = &openMetadata{api: api, service: cfg.DataCatalogService}
+	default:
+		return nil
+	}
+	return &Catalog{name: cfg.DataCatalog, provider: p, cache: make(map[string]cacheEntry)}
+}
+
+// Table returns the metadata of a table, or nil if the data catalog has none
+func (c *Catalog) Table(ctx context.Context, catalog, schema, table string) *TableMetadata {
+	key := strings.ToLower(catalog + "." + schema + "." + table)
+	c.mu.Lock()
+	entry, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < cacheTTL {
+		return entry.metadata
+	}
+
+	metadata, err := c.provider.table(ctx, strings.ToLower(catalog), strings.ToLower(schema), strings.ToLower(table))
+	switch {
+	case errors.Is(err, errNotFound):
+		metadata = nil
+	case err != nil:
+		log.Printf("WARNING: Failed to fetch %s metadata of %s: %v", c.name, key, err)
+		return nil // Not cached, so the next call retries
+	}
+	c.mu.Lock()
+	c.cache[key] = cacheEntry{metadata: metadata, fetchedAt: time.Now()}
+	c.mu.Unlock()
+	return metadata
+}
+
+// Invalidate drops the cached metadata, so that it is fetched again on next
+// use, and returns the number of tables dropped
+func (c *Catalog) Invalidate() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.cache)
+	c.cache = make(map[string]cacheEntry)
+	return n
+}
+
+// Tables returns the metadata of the first MaxListedTables tables of a
+// schema that the data catalog knows, by table name, fetching concurrently
+func (c *Catalog) Tables(ctx context.Context, catalog, schema string, tables []string) map[string]*TableMetadata {
+	if len(tables) > MaxListedTables {
+		tables = tables[:MaxListedTables]
+	}
+	found := make([]*TableMetadata, len(tables))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < fetchWorkers && w < len(tables); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				found[i] = c.Table(ctx, catalog, schema, tables[i])
+			}
+		}()
+	}
+	for i := 0; i < len(tables) && ctx.Err() == nil; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	metadata := make(map[string]*TableMetadata)
+	for i, table := range tables {
+		if found[i] != nil {
+			metadata[table] = found[i]
+		}
+	}
+	return metadata
+}
+
+// apiClient sends authenticated JSON requests to a data catalog API
+type apiClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// do sends a request and decodes the JSON response into out, returning
+// errNotFound for HTTP 404
+func (a *apiClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode != http.StatusOK:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// appendUnique appends the non-empty values not yet in list
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		seen := false
+		for _, existing := range list {
+			seen = seen || existing == value
+		}
+		if !seen {
+			list = append(list, value)
+		}
+	}
+	return list
+}
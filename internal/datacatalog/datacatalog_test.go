@@ -0,0 +1,183 @@
+package datacatalog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func newTestCatalog(t *testing.T, kind string, handler http.HandlerFunc) *Catalog {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	cfg := &config.TrinoConfig{
+		DataCatalog:        kind,
+		DataCatalogURL:     server.URL,
+		DataCatalogToken:   "secret",
+		DataCatalogService: "trino",
+		DataCatalogEnv:     "PROD",
+		DataCatalogTimeout: time.Second,
+	}
+	if kind == config.DataCatalogDataHub {
+		cfg.DataCatalogService = ""
+	}
+	return New(cfg)
+}
+
+func TestDataHub(t *testing.T) {
+	catalog := newTestCatalog(t, config.DataCatalogDataHub, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]string `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/api/graphql" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("request = %s %s with %q", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+		}
+		if body.Variables["urn"] != "urn:li:dataset:(urn:li:dataPlatform:trino,hive.sales.orders,PROD)" {
+			_, _ = w.Write([]byte(`{"data": {"dataset": null}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"dataset": {
+			"properties": {"description": "Ingested description"},
+			"editableProperties": {"description": "One row per order"},
+			"ownership": {"owners": [{"owner": {"username": "jdoe"}}, {"owner": {"name": "sales-eng"}}]},
+			"tags": {"tags": [{"tag": {"name": "Tier1"}}]},
+			"glossaryTerms": {"terms": [{"term": {"name": "Order"}}]},
+			"schemaMetadata": {"fields": [
+				{"fieldPath": "[version=2.0].[type=string].Status", "description": "Order status"},
+				{"fieldPath": "[version=2.0].[type=struct].address.[type=string].city", "description": "Nested"}
+			]},
+			"editableSchemaMetadata": {"editableSchemaFieldInfo": [
+				{"fieldPath": "email", "tags": {"tags": [{"tag": {"name": "PII"}}]}}
+			]}
+		}}}`))
+	})
+
+	got := catalog.Table(context.Background(), "hive", "sales", "orders")
+	want := &TableMetadata{
+		Description: "One row per order",
+		Owners:      []string{"jdoe", "sales-eng"},
+		Tags:        []string{"Tier1", "Order"},
+		Columns: map[string]ColumnMetadata{
+			"status": {Description: "Order status"},
+			"email":  {Tags: []string{"PII"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Table() = %+v, want %+v", got, want)
+	}
+	if got := catalog.Table(context.Background(), "hive", "sales", "returns"); got != nil {
+		t.Errorf("Table() of an unknown dataset = %+v, want nil", got)
+	}
+}
+
+func TestAmundsen(t *testing.T) {
+	catalog := newTestCatalog(t, config.DataCatalogAmundsen, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/table/trino://hive.sales/orders" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"description": "One row per order",
+			"owners": [{"email": "jdoe@example.com"}, {"user_id": "sales-eng"}],
+			"tags": [{"tag_name": "finance"}],
+			"columns": [{"name": "Status", "description": "Order status", "badges": [{"badge_name": "primary"}]}]
+		}`))
+	})
+
+	got := catalog.Table(context.Background(), "hive", "sales", "orders")
+	want := &TableMetadata{
+		Description: "One row per order",
+		Owners:      []string{"jdoe@example.com", "sales-eng"},
+		Tags:        []string{"finance"},
+		Columns:     map[string]ColumnMetadata{"status": {Description: "Order status", Tags: []string{"primary"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Table() = %+v, want %+v", got, want)
+	}
+	if got := catalog.Table(context.Background(), "hive", "sales", "returns"); got != nil {
+		t.Errorf("Table() of an unknown table = %+v, want nil", got)
+	}
+}
+
+func TestOpenMetadata(t *testing.T) {
+	catalog := newTestCatalog(t, config.DataCatalogOpenMetadata, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/tables/name/trino.hive.sales.orders" || r.URL.Query().Get("fields") != "owners,tags,columns" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"description": "One row per order",
+			"owners": [{"name": "jdoe", "displayName": "Jane Doe"}, {"name": "sales-eng"}],
+			"tags": [{"tagFQN": "Tier.Tier1"}],
+			"columns": [{"name": "email", "description": "Customer email", "tags": [{"tagFQN": "PII.Sensitive"}]}]
+		}`))
+	})
+
+	got := catalog.Table(context.Background(), "hive", "sales", "orders")
+	want := &TableMetadata{
+		Description: "One row per order",
+		Owners:      []string{"Jane Doe", "sales-eng"},
+		Tags:        []string{"Tier.Tier1"},
+		Columns:     map[string]ColumnMetadata{"email": {Description: "Customer email", Tags: []string{"PII.Sensitive"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Table() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCatalogCachesAndFailsOpen(t *testing.T) {
+	var requests atomic.Int32
+	var failing atomic.Bool
+	catalog := newTestCatalog(t, config.DataCatalogAmundsen, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/table/trino://hive.sales/orders" {
+			_, _ = w.Write([]byte(`{"description": "One row per order"}`))
+			return
+		}
+		http.NotFound(w, r)
+	})
+	ctx := context.Background()
+
+	metadata := catalog.Tables(ctx, "hive", "sales", []string{"orders", "returns", "orders"})
+	if len(metadata) != 1 || metadata["orders"] == nil {
+		t.Errorf("Tables() = %v, want metadata of orders only", metadata)
+	}
+	if n := requests.Load(); n > 3 {
+		t.Errorf("Tables() sent %d requests, want at most 3", n)
+	}
+
+	// Found and missing tables are cached
+	before := requests.Load()
+	catalog.Table(ctx, "HIVE", "sales", "orders")
+	catalog.Table(ctx, "hive", "sales", "returns")
+	if requests.Load() != before {
+		t.Errorf("cached tables were fetched again")
+	}
+
+	// Errors are not fatal and not cached
+	failing.Store(true)
+	if got := catalog.Table(ctx, "hive", "sales", "customers"); got != nil {
+		t.Errorf("Table() with a failing data catalog = %+v, want nil", got)
+	}
+	if _, cached := catalog.cache["hive.sales.customers"]; cached {
+		t.Error("a failed fetch should not be cached")
+	}
+}
+
+func TestNewWithoutDataCatalog(t *testing.T) {
+	if catalog := New(&config.TrinoConfig{}); catalog != nil {
+		t.Errorf("New() without TRINO_DATA_CATALOG = %v, want nil", catalog)
+	}
+}
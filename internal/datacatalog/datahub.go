@@ -0,0 +1,181 @@
+package datacatalog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// dataHubQuery reads the documentation, ownership and tags of a dataset
+const dataHubQuery = `query dataset($urn: String!) {
+  dataset(urn: $urn) {
+    properties { description }
+    editableProperties { description }
+    ownership { owners { owner { ... on CorpUser { username } ... on CorpGroup { name } } } }
+    tags { tags { tag { name } } }
+    glossaryTerms { terms { term { name } } }
+    schemaMetadata { fields { fieldPath description tags { tags { tag { name } } } } }
+    editableSchemaMetadata { editableSchemaFieldInfo { fieldPath description tags { tags { tag { name } } } } }
+  }
+}`
+
+// dataHub reads datasets of the trino platform from the DataHub GraphQL API
+type dataHub struct {
+	api              *apiClient
+	platformInstance string // Optional prefix of dataset names
+	env              string // Fabric of the dataset URNs, e.g. PROD
+}
+
+type dataHubTags struct {
+	Tags []struct {
+		Tag struct {
+			Name string `json:"name"`
+		} `json:"tag"`
+	} `json:"tags"`
+}
+
+type dataHubField struct {
+	FieldPath   string       `json:"fieldPath"`
+	Description string       `json:"description"`
+	Tags        *dataHubTags `json:"tags"`
+}
+
+type dataHubResponse struct {
+	Data struct {
+		Dataset *struct {
+			Properties *struct {
+				Description string `json:"description"`
+			} `json:"properties"`
+			EditableProperties *struct {
+				Description string `json:"description"`
+			} `json:"editableProperties"`
+			Ownership *struct {
+				Owners []struct {
+					Owner struct {
+						Username string `json:"username"` // CorpUser
+						Name     string `json:"name"`     // CorpGroup
+					} `json:"owner"`
+				} `json:"owners"`
+			} `json:"ownership"`
+			Tags          *dataHubTags `json:"tags"`
+			GlossaryTerms *struct {
+				Terms []struct {
+					Term struct {
+						Name string `json:"name"`
+					} `json:"term"`
+				} `json:"terms"`
+			} `json:"glossaryTerms"`
+			SchemaMetadata *struct {
+				Fields []dataHubField `json:"fields"`
+			} `json:"schemaMetadata"`
+			EditableSchemaMetadata *struct {
+				Fields []dataHubField `json:"editableSchemaFieldInfo"`
+			} `json:"editableSchemaMetadata"`
+		} `json:"dataset"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// urn returns the URN DataHub's Trino ingestion gives a table
+func (d *dataHub) urn(catalog, schema, table string) string {
+	name := catalog + "." + schema + "." + table
+	if d.platformInstance != "" {
+		name = d.platformInstance + "." + name
+	}
+	return fmt.Sprintf("urn:li:dataset:(urn:li:dataPlatform:trino,%s,%s)", name, d.env)
+}
+
+func (d *dataHub) table(ctx context.Context, catalog, schema, table string) (*TableMetadata, error) {
+	body := map[string]interface{}{
+		"query":     dataHubQuery,
+		"variables": map[string]string{"urn": d.urn(catalog, schema, table)},
+	}
+	var resp dataHubResponse
+	if err := d.api.do(ctx, http.MethodPost, "/api/graphql", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("DataHub error: %s", resp.Errors[0].Message)
+	}
+	dataset := resp.Data.Dataset
+	if dataset == nil {
+		return nil, errNotFound
+	}
+
+	metadata := &TableMetadata{Columns: make(map[string]ColumnMetadata)}
+	// Descriptions edited in the DataHub UI take precedence over ingested ones
+	if dataset.Properties != nil {
+		metadata.Description = dataset.Properties.Description
+	}
+	if dataset.EditableProperties != nil && dataset.EditableProperties.Description != "" {
+		metadata.Description = dataset.EditableProperties.Description
+	}
+	if dataset.Ownership != nil {
+		for _, owner := range dataset.Ownership.Owners {
+			metadata.Owners = appendUnique(metadata.Owners, owner.Owner.Username, owner.Owner.Name)
+		}
+	}
+	metadata.Tags = appendUnique(metadata.Tags, dataHubTagNames(dataset.Tags)...)
+	if dataset.GlossaryTerms != nil {
+		for _, term := range dataset.GlossaryTerms.Terms {
+			metadata.Tags = appendUnique(metadata.Tags, term.Term.Name)
+		}
+	}
+
+	var fields []dataHubField
+	if dataset.SchemaMetadata != nil {
+		fields = append(fields, dataset.SchemaMetadata.Fields...)
+	}
+	if dataset.EditableSchemaMetadata != nil {
+		fields = append(fields, dataset.EditableSchemaMetadata.Fields...)
+	}
+	for _, field := range fields {
+		name, ok := dataHubColumn(field.FieldPath)
+		if !ok {
+			continue
+		}
+		column := metadata.Columns[name]
+		if field.Description != "" {
+			column.Description = field.Description
+		}
+		column.Tags = appendUnique(column.Tags, dataHubTagNames(field.Tags)...)
+		metadata.Columns[name] = column
+	}
+	return metadata, nil
+}
+
+// dataHubTagNames returns the names of tags
+func dataHubTagNames(tags *dataHubTags) []string {
+	if tags == nil {
+		return nil
+	}
+	names := make([]string, 0, len(tags.Tags))
+	for _, tag := range tags.Tags {
+		names = append(names, tag.Tag.Name)
+	}
+	return names
+}
+
+// dataHubFieldAnnotation matches the [version=2.0] and [type=...] segments of v2 field paths
+var dataHubFieldAnnotation = regexp.MustCompile(`\[[^\]]*\]`)
+
+// dataHubColumn returns the top-level column of a field path, which is
+// either the column name or, in the v2 format, the name after bracketed
+// version and type segments: [version=2.0].[type=string].status. Nested
+// fields are skipped.
+func dataHubColumn(fieldPath string) (string, bool) {
+	var parts []string
+	for _, part := range strings.Split(dataHubFieldAnnotation.ReplaceAllString(fieldPath, ""), ".") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) != 1 {
+		return "", false
+	}
+	return strings.ToLower(parts[0]), true
+}
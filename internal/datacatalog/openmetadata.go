@@ -0,0 +1,71 @@
+package datacatalog
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// openMetadata reads tables from the OpenMetadata REST API (1.5 or later,
+// which reports owners as a list)
+type openMetadata struct {
+	api     *apiClient
+	service string // Database service the Trino server is registered as
+}
+
+type openMetadataOwner struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type openMetadataTags []struct {
+	TagFQN string `json:"tagFQN"`
+}
+
+type openMetadataTable struct {
+	Description string              `json:"description"`
+	Owners      []openMetadataOwner `json:"owners"`
+	Tags        openMetadataTags    `json:"tags"`
+	Columns     []struct {
+		Name        string           `json:"name"`
+		Description string           `json:"description"`
+		Tags        openMetadataTags `json:"tags"`
+	} `json:"columns"`
+}
+
+func (o *openMetadata) table(ctx context.Context, catalog, schema, table string) (*TableMetadata, error) {
+	// Fully qualified names are service.database.schema.table, with the Trino
+	// catalog as database; names containing dots are quoted
+	parts := []string{o.service, catalog, schema, table}
+	for i, part := range parts {
+		if strings.Contains(part, ".") {
+			parts[i] = `"` + part + `"`
+		}
+	}
+	fqn := strings.Join(parts, ".")
+	var resp openMetadataTable
+	if err := o.api.do(ctx, http.MethodGet, "/api/v1/tables/name/"+url.PathEscape(fqn)+"?fields=owners,tags,columns", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	metadata := &TableMetadata{Description: resp.Description, Columns: make(map[string]ColumnMetadata)}
+	for _, owner := range resp.Owners {
+		if owner.DisplayName != "" {
+			metadata.Owners = appendUnique(metadata.Owners, owner.DisplayName)
+		} else {
+			metadata.Owners = appendUnique(metadata.Owners, owner.Name)
+		}
+	}
+	for _, tag := range resp.Tags {
+		metadata.Tags = appendUnique(metadata.Tags, tag.TagFQN)
+	}
+	for _, col := range resp.Columns {
+		column := ColumnMetadata{Description: col.Description}
+		for _, tag := range col.Tags {
+			column.Tags = appendUnique(column.Tags, tag.TagFQN)
+		}
+		metadata.Columns[strings.ToLower(col.Name)] = column
+	}
+	return metadata, nil
+}
@@ -0,0 +1,98 @@
+package dbt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// refreshInterval is how long a loaded manifest is used before it is
+	// read again, picking up new dbt runs without a restart
+	refreshInterval = 5 * time.Minute
+
+	// maxManifestBytes bounds a manifest downloaded over HTTP
+	maxManifestBytes = 512 << 20
+)
+
+// Loader reads a manifest from a file or an http(s) URL on first use and
+// again once it is older than refreshInterval. When a refresh fails, the
+// last manifest read keeps being served.
+type Loader struct {
+	source     string
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	manifest *Manifest
+	loadedAt time.Time
+}
+
+// NewLoader returns a loader for a manifest path or URL, or nil if source is empty
+func NewLoader(source string) *Loader {
+	if source == "" {
+		return nil
+	}
+	return &Loader{source: source, httpClient: &http.Client{Timeout: time.Minute}}
+}
+
+// Manifest returns the current manifest, reading it if needed
+func (l *Loader) Manifest(ctx context.Context) (*Manifest, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.manifest != nil && time.Since(l.loadedAt) < refreshInterval {
+		return l.manifest, nil
+	}
+
+	manifest, err := l.read(ctx)
+	if err != nil {
+		if l.manifest != nil {
+			log.Printf("WARNING: Failed to refresh dbt manifest, using the one read at %s: %v", l.loadedAt.Format(time.RFC3339), err)
+			l.loadedAt = time.Now() // Retry after the next interval rather than on every call
+			return l.manifest, nil
+		}
+		return nil, err
+	}
+	l.manifest, l.loadedAt = manifest, time.Now()
+	log.Printf("INFO: Loaded dbt manifest of project %s with %d models", manifest.ProjectName, len(manifest.Models))
+	return manifest, nil
+}
+
+// Refresh makes the next Manifest call read the manifest again; the current
+// one stays in use if that fails
+func (l *Loader) Refresh() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.loadedAt = time.Time{}
+}
+
+// read parses the manifest from its file or URL
+func (l *Loader) read(ctx context.Context) (*Manifest, error) {
+	if !strings.HasPrefix(l.source, "http://") && !strings.HasPrefix(l.source, "https://") {
+		f, err := os.Open(l.source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dbt manifest: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		return Parse(f)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dbt manifest request: %w", err)
+	}
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download dbt manifest: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download dbt manifest: HTTP %d", resp.StatusCode)
+	}
+	return Parse(io.LimitReader(resp.Body, maxManifestBytes))
+}
@@ -0,0 +1,200 @@
+// Package dbt reads the models of a dbt project from its manifest.json, so
+// tools can map dbt models to the Trino tables they materialize.
+package dbt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Model search defaults and bounds
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 500
+)
+
+// Manifest holds the models of a dbt project
+type Manifest struct {
+	ProjectName string
+	GeneratedAt string
+	Models      []*Model // Sorted by unique ID
+}
+
+// Model is a dbt model and the relation it materializes
+type Model struct {
+	UniqueID     string   `json:"uniqueId"`
+	Name         string   `json:"name"`
+	Package      string   `json:"package"`
+	Version      string   `json:"version,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Catalog      string   `json:"catalog"` // The dbt database
+	Schema       string   `json:"schema"`
+	Table        string   `json:"table"` // The dbt alias, or the model name
+	Materialized string   `json:"materialized,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Path         string   `json:"path,omitempty"`      // SQL file in the dbt project
+	DependsOn    []string `json:"dependsOn,omitempty"` // Unique IDs of upstream models, sources and seeds
+	Columns      []Column `json:"columns,omitempty"`   // Documented columns by name
+}
+
+// Column is a column documented in a model's YAML
+type Column struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	DataType    string   `json:"dataType,omitempty"` // Type declared in dbt, if any
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// Relation returns catalog.schema.table, or "" for models that are not
+// materialized in Trino
+func (m *Model) Relation() string {
+	if m.Materialized == "ephemeral" || m.Table == "" {
+		return ""
+	}
+	return m.Catalog + "." + m.Schema + "." + m.Table
+}
+
+// manifestFile is the part of manifest.json the model tools read
+type manifestFile struct {
+	Metadata struct {
+		ProjectName string `json:"project_name"`
+		GeneratedAt string `json:"generated_at"`
+	} `json:"metadata"`
+	Nodes map[string]manifestNode `json:"nodes"`
+}
+
+type manifestNode struct {
+	ResourceType string          `json:"resource_type"`
+	Name         string          `json:"name"`
+	PackageName  string          `json:"package_name"`
+	Version      json.RawMessage `json:"version"` // String or number
+	Description  string          `json:"description"`
+	Database     string          `json:"database"`
+	Schema       string          `json:"schema"`
+	Alias        string          `json:"alias"`
+	Tags         []string        `json:"tags"`
+	Path         string          `json:"original_file_path"`
+	Config       struct {
+		Materialized string `json:"materialized"`
+	} `json:"config"`
+	DependsOn struct {
+		Nodes []string `json:"nodes"`
+	} `json:"depends_on"`
+	Columns map[string]struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		DataType    string   `json:"data_type"`
+		Tags        []string `json:"tags"`
+	} `json:"columns"`
+}
+
+// Parse reads the models of a manifest.json. Relation names are lowercased
+// like Trino identifiers.
+func Parse(r io.Reader) (*Manifest, error) {
+	var file manifestFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid dbt manifest: %w", err)
+	}
+
+	manifest := &Manifest{ProjectName: file.Metadata.ProjectName, GeneratedAt: file.Metadata.GeneratedAt}
+	for id, node := range file.Nodes {
+		if node.ResourceType != "model" {
+			continue
+		}
+		table := node.Alias
+		if table == "" {
+			table = node.Name
+		}
+		model := &Model{
+			UniqueID:     id,
+			Name:         node.Name,
+			Package:      node.PackageName,
+			Version:      strings.Trim(string(node.Version), `"`),
+			Description:  node.Description,
+			Catalog:      strings.ToLower(node.Database),
+			Schema:       strings.ToLower(node.Schema),
+			Table:        strings.ToLower(table),
+			Materialized: node.Config.Materialized,
+			Tags:         node.Tags,
+			Path:         node.Path,
+			DependsOn:    node.DependsOn.Nodes,
+		}
+		if model.Version == "null" {
+			model.Version = ""
+		}
+		for _, name := range sortedColumnNames(node) {
+			col := node.Columns[name]
+			if col.Name == "" {
+				col.Name = name
+			}
+			model.Columns = append(model.Columns, Column{Name: col.Name, Description: col.Description, DataType: col.DataType, Tags: col.Tags})
+		}
+		manifest.Models = append(manifest.Models, model)
+	}
+	sort.Slice(manifest.Models, func(i, j int) bool {
+		return manifest.Models[i].UniqueID < manifest.Models[j].UniqueID
+	})
+	return manifest, nil
+}
+
+// sortedColumnNames returns the documented columns of a node by name, as
+// the decoded map has lost the order of the manifest
+func sortedColumnNames(node manifestNode) []string {
+	names := make([]string, 0, len(node.Columns))
+	for name := range node.Columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Find returns the model with a unique ID (model.package.name), a
+// package-qualified name (package.name) or a name, case-insensitively. A
+// name shared by several packages or versions must be qualified.
+func (m *Manifest) Find(name string) (*Model, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	var matches []*Model
+	for _, model := range m.Models {
+		switch strings.ToLower(model.UniqueID) {
+		case name, "model." + name:
+			return model, nil
+		}
+		if strings.EqualFold(model.Name, name) || strings.EqualFold(model.Package+"."+model.Name, name) {
+			matches = append(matches, model)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("dbt model '%s' not found", name)
+	case 1:
+		return matches[0], nil
+	}
+	ids := make([]string, len(matches))
+	for i, model := range matches {
+		ids[i] = model.UniqueID
+	}
+	return nil, fmt.Errorf("dbt model name '%s' is ambiguous; use one of: %s", name, strings.Join(ids, ", "))
+}
+
+// Search returns the models whose name, description, tags or relation
+// contain text, case-insensitively; empty text returns every model
+func (m *Manifest) Search(text string) []*Model {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return m.Models
+	}
+	var found []*Model
+	for _, model := range m.Models {
+		fields := append([]string{model.UniqueID, model.Description, model.Relation()}, model.Tags...)
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), text) {
+				found = append(found, model)
+				break
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,170 @@
+package dbt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testManifest = `{
+  "metadata": {"project_name": "shop", "generated_at": "2024-06-01T12:00:00Z"},
+  "nodes": {
+    "model.shop.orders": {
+      "resource_type": "model",
+      "name": "orders",
+      "package_name": "shop",
+      "description": "One row per order.\nIncludes cancelled orders.",
+      "database": "Hive",
+      "schema": "analytics",
+      "alias": "fct_orders",
+      "tags": ["finance"],
+      "original_file_path": "models/marts/orders.sql",
+      "config": {"materialized": "table"},
+      "depends_on": {"nodes": ["model.shop.stg_orders"]},
+      "columns": {
+        "status": {"name": "status", "description": "Order status", "data_type": "varchar"},
+        "order_id": {"name": "order_id", "description": "Primary key"}
+      }
+    },
+    "model.shop.stg_orders": {
+      "resource_type": "model",
+      "name": "stg_orders",
+      "package_name": "shop",
+      "database": "hive",
+      "schema": "staging",
+      "alias": "",
+      "config": {"materialized": "ephemeral"},
+      "columns": {}
+    },
+    "model.payments.orders": {
+      "resource_type": "model",
+      "name": "orders",
+      "package_name": "payments",
+      "version": 2,
+      "database": "hive",
+      "schema": "payments",
+      "alias": "orders_v2",
+      "config": {"materialized": "view"},
+      "columns": {}
+    },
+    "test.shop.not_null_orders_order_id": {
+      "resource_type": "test",
+      "name": "not_null_orders_order_id",
+      "package_name": "shop"
+    }
+  }
+}`
+
+func loadTestManifest(t *testing.T) *Manifest {
+	t.Helper()
+	manifest, err := Parse(strings.NewReader(testManifest))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return manifest
+}
+
+func TestParse(t *testing.T) {
+	manifest := loadTestManifest(t)
+	if manifest.ProjectName != "shop" || len(manifest.Models) != 3 {
+		t.Fatalf("Parse() = project %q with %d models, want shop with 3", manifest.ProjectName, len(manifest.Models))
+	}
+
+	orders, err := manifest.Find("model.shop.orders")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if got := orders.Relation(); got != "hive.analytics.fct_orders" {
+		t.Errorf("Relation() = %q, want hive.analytics.fct_orders", got)
+	}
+	wantColumns := []Column{
+		{Name: "order_id", Description: "Primary key"},
+		{Name: "status", Description: "Order status", DataType: "varchar"},
+	}
+	if !reflect.DeepEqual(orders.Columns, wantColumns) {
+		t.Errorf("Columns = %+v, want %+v", orders.Columns, wantColumns)
+	}
+
+	staging, _ := manifest.Find("stg_orders")
+	if staging == nil || staging.Relation() != "" {
+		t.Errorf("ephemeral model relation = %v, want empty", staging)
+	}
+	payments, _ := manifest.Find("payments.orders")
+	if payments == nil || payments.Version != "2" {
+		t.Errorf("versioned model = %+v, want version 2", payments)
+	}
+}
+
+func TestFind(t *testing.T) {
+	manifest := loadTestManifest(t)
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "shop.orders", want: "model.shop.orders"},
+		{name: "MODEL.shop.orders", want: "model.shop.orders"},
+		{name: "stg_orders", want: "model.shop.stg_orders"},
+		{name: "orders", wantErr: true}, // In two packages
+		{name: "customers", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := manifest.Find(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Find(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err == nil && model.UniqueID != tt.want {
+				t.Errorf("Find(%q) = %s, want %s", tt.name, model.UniqueID, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	manifest := loadTestManifest(t)
+	if got := manifest.Search(""); len(got) != 3 {
+		t.Errorf("Search(\"\") returned %d models, want 3", len(got))
+	}
+	for text, want := range map[string]string{
+		"FINANCE":   "model.shop.orders",     // Tag
+		"cancelled": "model.shop.orders",     // Description
+		"orders_v2": "model.payments.orders", // Table
+	} {
+		got := manifest.Search(text)
+		if len(got) != 1 || got[0].UniqueID != want {
+			t.Errorf("Search(%q) = %v, want %s", text, got, want)
+		}
+	}
+}
+
+func TestLoaderKeepsLastManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, []byte(testManifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loader := NewLoader(path)
+	manifest, err := loader.Manifest(context.Background())
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+
+	// A failed refresh serves the manifest read before
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	loader.loadedAt = loader.loadedAt.Add(-2 * refreshInterval)
+	if again, err := loader.Manifest(context.Background()); err != nil || again != manifest {
+		t.Errorf("Manifest() after a failed refresh = %p, %v; want the previous manifest", again, err)
+	}
+
+	if _, err := NewLoader(path).Manifest(context.Background()); err == nil {
+		t.Error("Manifest() of a missing file should fail")
+	}
+	if NewLoader("") != nil {
+		t.Error("NewLoader(\"\") should return nil")
+	}
+}
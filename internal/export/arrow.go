@@ -0,0 +1,353 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// recordBatchSize is the number of rows buffered before a record batch is written
+const recordBatchSize = 10000
+
+// recordWriter is implemented by the Arrow IPC and Parquet file writers
+type recordWriter interface {
+	Write(rec arrow.Record) error
+	Close() error
+}
+
+// batchWriter buffers rows into Arrow record batches and hands them to a recordWriter
+type batchWriter struct {
+	w       io.Writer
+	open    func(schema *arrow.Schema, w io.Writer) (recordWriter, error)
+	rw      recordWriter
+	builder *array.RecordBuilder
+	pending int
+}
+
+// newArrowWriter writes an Arrow IPC stream
+func newArrowWriter(w io.Writer) *batchWriter {
+	return &batchWriter{w: w, open: func(schema *arrow.Schema, w io.Writer) (recordWriter, error) {
+		return ipc.NewWriter(w, ipc.WithSchema(schema)), nil
+	}}
+}
+
+func (b *batchWriter) Begin(columns []trino.ColumnInfo) error {
+	schema := arrowSchema(columns)
+	rw, err := b.open(schema, b.w)
+	if err != nil {
+		return err
+	}
+	b.rw = rw
+	b.builder = array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	return nil
+}
+
+func (b *batchWriter) WriteRow(values []interface{}) error {
+	for i, v := range values {
+		if err := appendValue(b.builder.Field(i), v); err != nil {
+			return fmt.Errorf("column %q: %w", b.builder.Schema().Field(i).Name, err)
+		}
+	}
+	b.pending++
+	if b.pending >= recordBatchSize {
+		return b.flush()
+	}
+	return nil
+}
+
+// flush writes buffered rows as one record batch
+func (b *batchWriter) flush() error {
+	if b.pending == 0 {
+		return nil
+	}
+	rec := b.builder.NewRecordBatch()
+	defer rec.Release()
+	b.pending = 0
+	return b.rw.Write(rec)
+}
+
+func (b *batchWriter) Close() error {
+	if b.rw == nil {
+		return nil
+	}
+	defer b.builder.Release()
+	if err := b.flush(); err != nil {
+		_ = b.rw.Close()
+		return err
+	}
+	return b.rw.Close()
+}
+
+// EncodeArrow encodes a buffered query result as an Arrow IPC stream
+func EncodeArrow(result *trino.QueryResult) ([]byte, error) {
+	var buf bytes.Buffer
+	w := newArrowWriter(&buf)
+	if err := w.Begin(result.ColumnTypes); err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(result.ColumnTypes))
+	for _, row := range result.Rows {
+		for i, col := range result.ColumnTypes {
+			values[i] = row[col.Name]
+		}
+		if err := w.WriteRow(values); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// appendValue appends a driver value to an Arrow builder. Top-level values arrive
+// converted by the driver (int64, float64, time.Time, ...); values nested in arrays,
+// maps and rows arrive as decoded JSON (json.Number, strings), so both are accepted.
+func appendValue(b array.Builder, v interface{}) error {
+	if v == nil {
+		b.AppendNull()
+		return nil
+	}
+	switch builder := b.(type) {
+	case *array.BooleanBuilder:
+		val, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected value of type %T for boolean column", v)
+		}
+		builder.Append(val)
+	case *array.Int8Builder:
+		val, err := toInt64(v)
+		if err != nil {
+			return err
+		}
+		builder.Append(int8(val))
+	case *array.Int16Builder:
+		val, err := toInt64(v)
+		if err != nil {
+			return err
+		}
+		builder.Append(int16(val))
+	case *array.Int32Builder:
+		val, err := toInt64(v)
+		if err != nil {
+			return err
+		}
+		builder.Append(int32(val))
+	case *array.Int64Builder:
+		val, err := toInt64(v)
+		if err != nil {
+			return err
+		}
+		builder.Append(val)
+	case *array.Float32Builder:
+		val, err := toFloat64(v)
+		if err != nil {
+			return err
+		}
+		builder.Append(float32(val))
+	case *array.Float64Builder:
+		val, err := toFloat64(v)
+		if err != nil {
+			return err
+		}
+		builder.Append(val)
+	case *array.Decimal128Builder:
+		dt := builder.Type().(*arrow.Decimal128Type)
+		num, err := decimal128.FromString(FormatValue(v), dt.Precision, dt.Scale)
+		if err != nil {
+			return fmt.Errorf("invalid decimal %v: %w", v, err)
+		}
+		builder.Append(num)
+	case *array.BinaryBuilder:
+		switch val := v.(type) {
+		case []byte:
+			builder.Append(val)
+		case string:
+			// Nested VARBINARY values are base64 encoded in Trino's JSON protocol
+			if data, err := base64.StdEncoding.DecodeString(val); err == nil {
+				builder.Append(data)
+			} else {
+				builder.AppendString(val)
+			}
+		default:
+			return fmt.Errorf("unexpected value of type %T for binary column", v)
+		}
+	case *array.StringBuilder:
+		builder.Append(FormatValue(v))
+	case *array.Date32Builder:
+		t, err := toTime(v)
+		if err != nil {
+			return err
+		}
+		builder.Append(arrow.Date32(wallClockUTC(t).Unix() / 86400))
+	case *array.TimestampBuilder:
+		t, err := toTime(v)
+		if err != nil {
+			return err
+		}
+		dt := builder.Type().(*arrow.TimestampType)
+		if dt.TimeZone == "" {
+			// TIMESTAMP without time zone is a wall-clock value; keep its fields as-is
+			t = wallClockUTC(t)
+		}
+		ts, err := arrow.TimestampFromTime(t, dt.Unit)
+		if err != nil {
+			return err
+		}
+		builder.Append(ts)
+	case *array.Time64Builder:
+		t, err := toTime(v)
+		if err != nil {
+			return err
+		}
+		sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+		unit := builder.Type().(*arrow.Time64Type).Unit
+		builder.Append(arrow.Time64(int64(sinceMidnight) / int64(unit.Multiplier())))
+	case *array.ListBuilder:
+		items, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected value of type %T for array column", v)
+		}
+		builder.Append(true)
+		for _, item := range items {
+			if err := appendValue(builder.ValueBuilder(), item); err != nil {
+				return err
+			}
+		}
+	case *array.MapBuilder:
+		entries, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected value of type %T for map column", v)
+		}
+		keys := make([]string, 0, len(entries))
+		for key := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		builder.Append(true)
+		for _, key := range keys {
+			if err := appendValue(builder.KeyBuilder(), key); err != nil {
+				return err
+			}
+			if err := appendValue(builder.ItemBuilder(), entries[key]); err != nil {
+				return err
+			}
+		}
+	case *array.StructBuilder:
+		fields, ok := v.([]interface{})
+		if !ok || len(fields) != builder.NumField() {
+			return fmt.Errorf("unexpected value %v for row column", v)
+		}
+		builder.Append(true)
+		for i, field := range fields {
+			if err := appendValue(builder.FieldBuilder(i), field); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported arrow builder %T", b)
+	}
+	return nil
+}
+
+// toInt64 converts driver or nested JSON values to int64
+func toInt64(v interface{}) (int64, error) {
+	switch val := v.(type) {
+	case int64:
+		return val, nil
+	case json.Number:
+		return val.Int64()
+	case string:
+		return strconv.ParseInt(val, 10, 64)
+	case float64:
+		return int64(val), nil
+	default:
+		return 0, fmt.Errorf("unexpected value of type %T for integer column", v)
+	}
+}
+
+// toFloat64 converts driver or nested JSON values to float64. Trino encodes
+// NaN and infinities as strings, which ParseFloat understands.
+func toFloat64(v interface{}) (float64, error) {
+	switch val := v.(type) {
+	case float64:
+		return val, nil
+	case json.Number:
+		return strconv.ParseFloat(val.String(), 64)
+	case string:
+		return strconv.ParseFloat(val, 64)
+	case int64:
+		return float64(val), nil
+	default:
+		return 0, fmt.Errorf("unexpected value of type %T for floating point column", v)
+	}
+}
+
+// Layouts of Trino's date/time literals in nested values
+var (
+	timeLayouts   = []string{"2006-01-02 15:04:05.999999999", "2006-01-02", "15:04:05.999999999"}
+	timeLayoutsTZ = []string{"2006-01-02 15:04:05.999999999 -07:00", "15:04:05.999999999 -07:00"}
+)
+
+// toTime converts a driver time.Time or a nested date/time literal
+func toTime(v interface{}) (time.Time, error) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, nil
+	case string:
+		return parseTrinoTime(val)
+	default:
+		return time.Time{}, fmt.Errorf("unexpected value of type %T for temporal column", v)
+	}
+}
+
+// parseTrinoTime parses date, time and timestamp literals, with an optional
+// numeric offset or zone name (e.g. "2024-01-02 03:04:05.123 Europe/Paris")
+func parseTrinoTime(s string) (time.Time, error) {
+	for _, layout := range timeLayoutsTZ {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if idx := strings.LastIndex(s, " "); idx > 0 {
+		if zone := s[idx+1:]; zone != "" && (zone[0] < '0' || zone[0] > '9') {
+			loc, err := time.LoadLocation(zone)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("unknown time zone in %q: %w", s, err)
+			}
+			return parseInLocation(s[:idx], loc)
+		}
+	}
+	return parseInLocation(s, time.UTC)
+}
+
+func parseInLocation(s string, loc *time.Location) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date/time value %q: %w", s, err)
+}
+
+// wallClockUTC returns the same wall-clock reading in UTC
+func wallClockUTC(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
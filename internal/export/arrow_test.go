@@ -0,0 +1,168 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+var typedColumns = []trino.ColumnInfo{
+	{Name: "amount", Type: "DECIMAL", Precision: 10, Scale: 2},
+	{Name: "created", Type: "TIMESTAMP", Precision: 3},
+	{Name: "day", Type: "DATE"},
+	{Name: "tags", Type: "ARRAY(VARCHAR)"},
+	{Name: "attrs", Type: "MAP(VARCHAR, BIGINT)"},
+	{Name: "point", Type: "ROW(X DOUBLE, Y DOUBLE)"},
+}
+
+func typedResult() *trino.QueryResult {
+	// The driver parses TIMESTAMP and DATE in the local time zone; the wall clock must survive
+	created := time.Date(2024, 3, 1, 12, 30, 45, 123000000, time.Local)
+	return &trino.QueryResult{
+		ColumnTypes: typedColumns,
+		Rows: []map[string]interface{}{
+			{
+				"amount":  "1234.50",
+				"created": created,
+				"day":     time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local),
+				"tags":    []interface{}{"a", nil},
+				"attrs":   map[string]interface{}{"k": json.Number("7")},
+				"point":   []interface{}{json.Number("1.5"), json.Number("-2")},
+			},
+			{"amount": nil, "created": nil, "day": nil, "tags": nil, "attrs": nil, "point": nil},
+		},
+	}
+}
+
+func TestEncodeArrow(t *testing.T) {
+	data, err := EncodeArrow(typedResult())
+	if err != nil {
+		t.Fatalf("EncodeArrow() error = %v", err)
+	}
+
+	reader, err := ipc.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read arrow stream: %v", err)
+	}
+	defer reader.Release()
+
+	if !reader.Next() {
+		t.Fatalf("expected a record batch, err = %v", reader.Err())
+	}
+	rec := reader.Record()
+	if rec.NumRows() != 2 {
+		t.Fatalf("NumRows() = %d, want 2", rec.NumRows())
+	}
+
+	amount := rec.Column(0).(*array.Decimal128)
+	if got := amount.Value(0).ToString(2); got != "1234.50" {
+		t.Errorf("amount = %s, want 1234.50", got)
+	}
+	if !amount.IsNull(1) {
+		t.Error("amount[1] should be null")
+	}
+
+	created := rec.Column(1).(*array.Timestamp)
+	want := time.Date(2024, 3, 1, 12, 30, 45, 123000000, time.UTC)
+	if got := created.Value(0).ToTime(arrow.Millisecond); !got.Equal(want) {
+		t.Errorf("created = %s, want %s", got, want)
+	}
+
+	day := rec.Column(2).(*array.Date32)
+	if got := day.Value(0).ToTime().Format("2006-01-02"); got != "2024-03-01" {
+		t.Errorf("day = %s, want 2024-03-01", got)
+	}
+
+	tags := rec.Column(3).(*array.List)
+	if got := tags.ListValues().Len(); got != 2 {
+		t.Errorf("tags values = %d, want 2", got)
+	}
+
+	point := rec.Column(5).(*array.Struct)
+	if got := point.Field(0).(*array.Float64).Value(0); got != 1.5 {
+		t.Errorf("point.x = %v, want 1.5", got)
+	}
+	if got := point.Field(1).(*array.Float64).Value(0); got != -2 {
+		t.Errorf("point.y = %v, want -2", got)
+	}
+}
+
+func TestParquetWriterPreservesTypes(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatParquet, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	result := typedResult()
+	if err := w.Begin(result.ColumnTypes); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	for _, row := range result.Rows {
+		values := make([]interface{}, len(typedColumns))
+		for i, col := range typedColumns {
+			values[i] = row[col.Name]
+		}
+		if err := w.WriteRow(values); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	pf, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to read parquet output: %v", err)
+	}
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatalf("failed to create arrow reader: %v", err)
+	}
+	table, err := fr.ReadTable(context.Background())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	defer table.Release()
+
+	expected := arrowSchema(typedColumns)
+	for i, field := range table.Schema().Fields() {
+		if !arrow.TypeEqual(field.Type, expected.Field(i).Type) {
+			t.Errorf("column %q type = %s, want %s", field.Name, field.Type, expected.Field(i).Type)
+		}
+	}
+}
+
+func TestParseTrinoTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-03-01 12:30:45.123", time.Date(2024, 3, 1, 12, 30, 45, 123000000, time.UTC)},
+		{"2024-03-01 12:30:45.123 +02:00", time.Date(2024, 3, 1, 10, 30, 45, 123000000, time.UTC)},
+		{"2024-03-01 12:30:45.123 UTC", time.Date(2024, 3, 1, 12, 30, 45, 123000000, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseTrinoTime(tt.input)
+			if err != nil {
+				t.Fatalf("parseTrinoTime(%q) error = %v", tt.input, err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("parseTrinoTime(%q) = %s, want %s", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// gcsEndpoint is the S3-compatible XML API endpoint of Google Cloud Storage
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// Options controls where exports may be written
+type Options struct {
+	// Dir is the directory local exports are confined to
+	Dir string
+	// AllowedURIs lists s3:// or gs:// prefixes remote exports may be written under.
+	// Remote exports are disabled when empty.
+	AllowedURIs []string
+	// GCSAccessKeyID and GCSSecretAccessKey are HMAC credentials for gs:// exports
+	GCSAccessKeyID     string
+	GCSSecretAccessKey string
+}
+
+// Target is an opened export destination. Data is written to File; Commit
+// uploads it when the destination is remote.
+type Target struct {
+	File     *os.File
+	Location string
+
+	scheme string
+	bucket string
+	key    string
+	opts   Options
+}
+
+// Open resolves a destination and creates the file the export is written to.
+// An empty destination creates a new file in the export directory.
+func Open(destination, format string, opts Options) (*Target, error) {
+	destination = strings.TrimSpace(destination)
+	if strings.HasPrefix(destination, "s3://") || strings.HasPrefix(destination, "gs://") {
+		return openRemote(destination, format, opts)
+	}
+	return openLocal(destination, format, opts)
+}
+
+// openLocal creates a local file inside the export directory
+func openLocal(destination, format string, opts Options) (*Target, error) {
+	if strings.Contains(destination, "://") {
+		return nil, fmt.Errorf("unsupported export destination scheme: %s", destination)
+	}
+
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid export directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	if destination == "" {
+		f, err := os.CreateTemp(dir, "export-*."+format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create export file: %w", err)
+		}
+		return &Target{File: f, Location: f.Name()}, nil
+	}
+
+	target := destination
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	target = filepath.Clean(target)
+	if !isWithin(dir, target) {
+		return nil, fmt.Errorf("export path %q is outside the export directory %s", destination, dir)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	// Re-check with symlinks resolved so a linked subdirectory cannot escape
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid export directory: %w", err)
+	}
+	realParent, err := filepath.EvalSymlinks(filepath.Dir(target))
+	if err != nil || !isWithin(realDir, filepath.Join(realParent, filepath.Base(target))) {
+		return nil, fmt.Errorf("export path %q is outside the export directory %s", destination, dir)
+	}
+	// O_EXCL keeps exports from overwriting existing files
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	return &Target{File: f, Location: target}, nil
+}
+
+// isWithin reports whether target is strictly inside dir
+func isWithin(dir, target string) bool {
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return true
+}
+
+// openRemote validates an object storage URI and stages the export in a temp file
+func openRemote(destination, format string, opts Options) (*Target, error) {
+	if !isAllowedURI(destination, opts.AllowedURIs) {
+		return nil, fmt.Errorf("export destination %s is not allowed (see TRINO_EXPORT_ALLOWED_URIS)", destination)
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid export destination: %s", destination)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if key == "" || strings.HasSuffix(key, "/") {
+		key = path.Join(key, fmt.Sprintf("export-%s.%s", time.Now().UTC().Format("20060102T150405.000000000Z"), format))
+	}
+
+	f, err := os.CreateTemp("", "mcp-trino-export-*."+format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging file: %w", err)
+	}
+	return &Target{
+		File:     f,
+		Location: fmt.Sprintf("%s://%s/%s", u.Scheme, u.Host, key),
+		scheme:   u.Scheme,
+		bucket:   u.Host,
+		key:      key,
+		opts:     opts,
+	}, nil
+}
+
+// isAllowedURI reports whether uri falls under one of the allowed prefixes
+func isAllowedURI(uri string, allowed []string) bool {
+	if strings.Contains(uri, "/../") || strings.HasSuffix(uri, "/..") {
+		return false
+	}
+	for _, prefix := range allowed {
+		if prefix != "" && strings.HasPrefix(uri, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRemote reports whether the export is uploaded to object storage
+func (t *Target) IsRemote() bool {
+	return t.scheme != ""
+}
+
+// Commit closes the file and, for remote destinations, uploads and removes the staging file.
+// It returns the size of the export in bytes.
+func (t *Target) Commit(ctx context.Context) (int64, error) {
+	info, err := t.File.Stat()
+	if err != nil {
+		_ = t.File.Close()
+		return 0, fmt.Errorf("failed to stat export file: %w", err)
+	}
+	size := info.Size()
+
+	if !t.IsRemote() {
+		if err := t.File.Close(); err != nil {
+			return 0, fmt.Errorf("failed to close export file: %w", err)
+		}
+		return size, nil
+	}
+
+	defer func() {
+		_ = t.File.Close()
+		_ = os.Remove(t.File.Name())
+	}()
+	if _, err := t.File.Seek(0, 0); err != nil {
+		return 0, fmt.Errorf("failed to rewind staging file: %w", err)
+	}
+
+	client, err := t.s3Client(ctx)
+	if err != nil {
+		return 0, err
+	}
+	uploader := manager.NewUploader(client)
+	if _, err := uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(t.key),
+		Body:   t.File,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to upload export to %s: %w", t.Location, err)
+	}
+	return size, nil
+}
+
+// Abort closes and removes a partially written export
+func (t *Target) Abort() {
+	_ = t.File.Close()
+	_ = os.Remove(t.File.Name())
+}
+
+// s3Client builds a client for the target's scheme. S3 uses the default AWS
+// credential chain; GCS uses its S3-compatible API with HMAC credentials.
+func (t *Target) s3Client(ctx context.Context) (*s3.Client, error) {
+	if t.scheme != "gs" {
+		cfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+		}
+		return s3.NewFromConfig(cfg), nil
+	}
+
+	if t.opts.GCSAccessKeyID == "" || t.opts.GCSSecretAccessKey == "" {
+		return nil, fmt.Errorf("gs:// exports require TRINO_EXPORT_GCS_ACCESS_KEY_ID and TRINO_EXPORT_GCS_SECRET_ACCESS_KEY")
+	}
+	cfg := aws.Config{
+		Region:      "auto",
+		Credentials: credentials.NewStaticCredentialsProvider(t.opts.GCSAccessKeyID, t.opts.GCSSecretAccessKey, ""),
+		// GCS rejects the flexible checksum headers newer SDKs send by default
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	}
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(gcsEndpoint)
+		o.UsePathStyle = true
+	}), nil
+}
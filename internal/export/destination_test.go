@@ -0,0 +1,118 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenLocal(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Dir: dir}
+
+	tests := []struct {
+		name        string
+		destination string
+		wantErr     bool
+	}{
+		{name: "temp file", destination: ""},
+		{name: "relative path", destination: "reports/out.csv"},
+		{name: "absolute path inside dir", destination: filepath.Join(dir, "abs.csv")},
+		{name: "parent traversal", destination: "../escape.csv", wantErr: true},
+		{name: "absolute path outside dir", destination: filepath.Join(os.TempDir(), "outside.csv"), wantErr: true},
+		{name: "export dir itself", destination: dir, wantErr: true},
+		{name: "unsupported scheme", destination: "https://example.com/out.csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := Open(tt.destination, FormatCSV, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open(%q) error = %v, wantErr %v", tt.destination, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer target.Abort()
+
+			if !strings.HasPrefix(target.Location, dir+string(filepath.Separator)) {
+				t.Errorf("Location = %q, want a path inside %q", target.Location, dir)
+			}
+			if target.IsRemote() {
+				t.Error("IsRemote() = true for a local destination")
+			}
+		})
+	}
+}
+
+func TestOpenLocalDoesNotOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.csv")
+	if err := os.WriteFile(existing, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open("existing.csv", FormatCSV, Options{Dir: dir}); err == nil {
+		t.Error("Open() expected error for existing file")
+	}
+}
+
+func TestOpenLocalRejectsSymlinkEscape(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if _, err := Open("link/out.csv", FormatCSV, Options{Dir: dir}); err == nil {
+		t.Error("Open() expected error for path escaping through a symlink")
+	}
+}
+
+func TestOpenRemote(t *testing.T) {
+	opts := Options{Dir: t.TempDir(), AllowedURIs: []string{"s3://bucket/exports/", "gs://other/"}}
+
+	tests := []struct {
+		name         string
+		destination  string
+		wantLocation string
+		wantErr      bool
+	}{
+		{name: "allowed s3 key", destination: "s3://bucket/exports/out.csv", wantLocation: "s3://bucket/exports/out.csv"},
+		{name: "allowed gcs key", destination: "gs://other/a/b.csv", wantLocation: "gs://other/a/b.csv"},
+		{name: "prefix generates name", destination: "s3://bucket/exports/"},
+		{name: "outside prefix", destination: "s3://bucket/private/out.csv", wantErr: true},
+		{name: "traversal", destination: "s3://bucket/exports/../private/out.csv", wantErr: true},
+		{name: "different bucket", destination: "s3://bucket2/exports/out.csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := Open(tt.destination, FormatCSV, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open(%q) error = %v, wantErr %v", tt.destination, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer target.Abort()
+
+			if !target.IsRemote() {
+				t.Error("IsRemote() = false for a remote destination")
+			}
+			if tt.wantLocation != "" && target.Location != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", target.Location, tt.wantLocation)
+			}
+			if tt.wantLocation == "" && !strings.HasSuffix(target.Location, ".csv") {
+				t.Errorf("Location = %q, want generated .csv file name", target.Location)
+			}
+		})
+	}
+}
+
+func TestOpenRemoteDisabledByDefault(t *testing.T) {
+	if _, err := Open("s3://bucket/out.csv", FormatCSV, Options{Dir: t.TempDir()}); err == nil {
+		t.Error("Open() expected error when no remote prefixes are allowed")
+	}
+}
@@ -0,0 +1,25 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// newParquetWriter writes Parquet with one row group per record batch. The
+// Arrow schema is stored in the file so readers recover the exact column types.
+func newParquetWriter(w io.Writer) *batchWriter {
+	return &batchWriter{w: w, open: func(schema *arrow.Schema, w io.Writer) (recordWriter, error) {
+		props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+		arrowProps := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema())
+		fw, err := pqarrow.NewFileWriter(schema, w, props, arrowProps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+		}
+		return fw, nil
+	}}
+}
@@ -0,0 +1,232 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// maxDecimal128Precision is the largest precision Decimal128 can hold; Trino's maximum is also 38
+const maxDecimal128Precision = 38
+
+// trinoType is a parsed Trino type signature such as DECIMAL(10,2) or ARRAY(ROW(A BIGINT))
+type trinoType struct {
+	base      string // lower-case type name, e.g. "decimal", "timestamp with time zone"
+	precision int    // decimal precision or fractional-second digits; -1 when absent
+	scale     int
+	elems     []trinoType // array element, map key and value, or row fields
+	names     []string    // row field names
+}
+
+// multiWordTypes are type names containing spaces, used to tell anonymous row fields from named ones
+var multiWordTypes = []string{
+	"timestamp with time zone", "time with time zone",
+	"interval day to second", "interval year to month", "double precision",
+}
+
+// parseTrinoType parses a Trino type name as reported by the driver. Row field
+// names are lower-cased since the driver reports nested type names in upper case.
+func parseTrinoType(s string) trinoType {
+	s = strings.TrimSpace(s)
+	open := strings.IndexByte(s, '(')
+	if open < 0 {
+		return trinoType{base: strings.ToLower(s), precision: -1}
+	}
+	closing := matchingParen(s, open)
+	if closing < 0 {
+		return trinoType{base: strings.ToLower(s), precision: -1}
+	}
+
+	t := trinoType{
+		base:      strings.ToLower(strings.TrimSpace(s[:open]) + s[closing+1:]),
+		precision: -1,
+	}
+	args := splitTopLevel(s[open+1 : closing])
+	switch t.base {
+	case "array":
+		t.elems = []trinoType{parseTrinoType(args[0])}
+	case "map":
+		if len(args) == 2 {
+			t.elems = []trinoType{parseTrinoType(args[0]), parseTrinoType(args[1])}
+		}
+	case "row":
+		for i, arg := range args {
+			name, typ := splitRowField(arg)
+			if name == "" {
+				name = fmt.Sprintf("field%d", i)
+			}
+			t.names = append(t.names, name)
+			t.elems = append(t.elems, parseTrinoType(typ))
+		}
+	case "decimal", "timestamp", "timestamp with time zone", "time", "time with time zone":
+		if p, err := strconv.Atoi(strings.TrimSpace(args[0])); err == nil {
+			t.precision = p
+		}
+		if len(args) > 1 {
+			t.scale, _ = strconv.Atoi(strings.TrimSpace(args[1]))
+		}
+	}
+	return t
+}
+
+// columnType parses a column's type, filling in precision the driver reports separately
+func columnType(col trino.ColumnInfo) trinoType {
+	t := parseTrinoType(col.Type)
+	if t.precision < 0 && col.Precision > 0 {
+		t.precision = int(col.Precision)
+		t.scale = int(col.Scale)
+	}
+	return t
+}
+
+// matchingParen returns the index of the parenthesis closing the one at open
+func matchingParen(s string, open int) int {
+	depth := 0
+	inQuotes := false
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case '(':
+			if !inQuotes {
+				depth++
+			}
+		case ')':
+			if !inQuotes {
+				depth--
+				if depth == 0 {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits type arguments on commas that are not nested in parentheses or quotes
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case '(':
+			if !inQuotes {
+				depth++
+			}
+		case ')':
+			if !inQuotes {
+				depth--
+			}
+		case ',':
+			if !inQuotes && depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// splitRowField splits a row field into its name and type. Anonymous fields return an empty name.
+func splitRowField(field string) (string, string) {
+	if strings.HasPrefix(field, `"`) {
+		if end := strings.Index(field[1:], `"`); end >= 0 {
+			return strings.ToLower(field[1 : end+1]), strings.TrimSpace(field[end+2:])
+		}
+	}
+
+	lower := strings.ToLower(field)
+	for _, typ := range multiWordTypes {
+		if strings.HasPrefix(lower, typ) {
+			return "", field
+		}
+	}
+	space := strings.IndexByte(field, ' ')
+	if space < 0 || strings.ContainsRune(field[:space], '(') {
+		return "", field
+	}
+	return strings.ToLower(field[:space]), strings.TrimSpace(field[space+1:])
+}
+
+// timeUnit picks the Arrow unit that holds the given fractional-second digits
+func timeUnit(precision int) arrow.TimeUnit {
+	switch {
+	case precision >= 0 && precision <= 3:
+		return arrow.Millisecond
+	case precision >= 0 && precision <= 6:
+		return arrow.Microsecond
+	default:
+		// Unknown or above microseconds; Go times carry at most nanoseconds
+		return arrow.Nanosecond
+	}
+}
+
+// arrowType maps a Trino type to an Arrow type. Types without a lossless Arrow
+// equivalent (JSON, UUID, intervals, TIME WITH TIME ZONE, ...) are written as strings.
+func arrowType(t trinoType) arrow.DataType {
+	switch t.base {
+	case "boolean":
+		return arrow.FixedWidthTypes.Boolean
+	case "tinyint":
+		return arrow.PrimitiveTypes.Int8
+	case "smallint":
+		return arrow.PrimitiveTypes.Int16
+	case "integer":
+		return arrow.PrimitiveTypes.Int32
+	case "bigint":
+		return arrow.PrimitiveTypes.Int64
+	case "real":
+		return arrow.PrimitiveTypes.Float32
+	case "double":
+		return arrow.PrimitiveTypes.Float64
+	case "decimal":
+		if t.precision <= 0 || t.precision > maxDecimal128Precision {
+			return arrow.BinaryTypes.String
+		}
+		return &arrow.Decimal128Type{Precision: int32(t.precision), Scale: int32(t.scale)}
+	case "varbinary":
+		return arrow.BinaryTypes.Binary
+	case "date":
+		return arrow.FixedWidthTypes.Date32
+	case "timestamp":
+		return &arrow.TimestampType{Unit: timeUnit(t.precision)}
+	case "timestamp with time zone":
+		return &arrow.TimestampType{Unit: timeUnit(t.precision), TimeZone: "UTC"}
+	case "time":
+		if timeUnit(t.precision) == arrow.Nanosecond {
+			return arrow.FixedWidthTypes.Time64ns
+		}
+		return arrow.FixedWidthTypes.Time64us
+	case "array":
+		if len(t.elems) == 1 {
+			return arrow.ListOf(arrowType(t.elems[0]))
+		}
+	case "map":
+		if len(t.elems) == 2 {
+			return arrow.MapOf(arrowType(t.elems[0]), arrowType(t.elems[1]))
+		}
+	case "row":
+		fields := make([]arrow.Field, len(t.elems))
+		for i, elem := range t.elems {
+			fields[i] = arrow.Field{Name: t.names[i], Type: arrowType(elem), Nullable: true}
+		}
+		return arrow.StructOf(fields...)
+	}
+	return arrow.BinaryTypes.String
+}
+
+// arrowSchema builds the Arrow schema for a result set
+func arrowSchema(columns []trino.ColumnInfo) *arrow.Schema {
+	fields := make([]arrow.Field, len(columns))
+	for i, col := range columns {
+		fields[i] = arrow.Field{Name: col.Name, Type: arrowType(columnType(col)), Nullable: true}
+	}
+	return arrow.NewSchema(fields, nil)
+}
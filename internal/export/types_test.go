@@ -0,0 +1,61 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func TestArrowType(t *testing.T) {
+	tests := []struct {
+		column   trino.ColumnInfo
+		expected arrow.DataType
+	}{
+		{trino.ColumnInfo{Type: "BOOLEAN"}, arrow.FixedWidthTypes.Boolean},
+		{trino.ColumnInfo{Type: "TINYINT"}, arrow.PrimitiveTypes.Int8},
+		{trino.ColumnInfo{Type: "SMALLINT"}, arrow.PrimitiveTypes.Int16},
+		{trino.ColumnInfo{Type: "INTEGER"}, arrow.PrimitiveTypes.Int32},
+		{trino.ColumnInfo{Type: "BIGINT"}, arrow.PrimitiveTypes.Int64},
+		{trino.ColumnInfo{Type: "REAL"}, arrow.PrimitiveTypes.Float32},
+		{trino.ColumnInfo{Type: "DOUBLE"}, arrow.PrimitiveTypes.Float64},
+		{trino.ColumnInfo{Type: "VARCHAR"}, arrow.BinaryTypes.String},
+		{trino.ColumnInfo{Type: "JSON"}, arrow.BinaryTypes.String},
+		{trino.ColumnInfo{Type: "VARBINARY"}, arrow.BinaryTypes.Binary},
+		{trino.ColumnInfo{Type: "DATE"}, arrow.FixedWidthTypes.Date32},
+		{trino.ColumnInfo{Type: "DECIMAL", Precision: 12, Scale: 2}, &arrow.Decimal128Type{Precision: 12, Scale: 2}},
+		{trino.ColumnInfo{Type: "DECIMAL"}, arrow.BinaryTypes.String},
+		{trino.ColumnInfo{Type: "TIMESTAMP", Precision: 3}, &arrow.TimestampType{Unit: arrow.Millisecond}},
+		{trino.ColumnInfo{Type: "TIMESTAMP", Precision: 6}, &arrow.TimestampType{Unit: arrow.Microsecond}},
+		{trino.ColumnInfo{Type: "TIMESTAMP WITH TIME ZONE", Precision: 9}, &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}},
+		{trino.ColumnInfo{Type: "TIME", Precision: 3}, arrow.FixedWidthTypes.Time64us},
+		{trino.ColumnInfo{Type: "TIME WITH TIME ZONE", Precision: 3}, arrow.BinaryTypes.String},
+		{trino.ColumnInfo{Type: "ARRAY(DECIMAL(10,2))"}, arrow.ListOf(&arrow.Decimal128Type{Precision: 10, Scale: 2})},
+		{trino.ColumnInfo{Type: "MAP(VARCHAR, ARRAY(BIGINT))"}, arrow.MapOf(arrow.BinaryTypes.String, arrow.ListOf(arrow.PrimitiveTypes.Int64))},
+		{
+			trino.ColumnInfo{Type: "ROW(ID BIGINT, TS TIMESTAMP(6) WITH TIME ZONE, \"TAGS\" ARRAY(VARCHAR))"},
+			arrow.StructOf(
+				arrow.Field{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+				arrow.Field{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}, Nullable: true},
+				arrow.Field{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+			),
+		},
+		{
+			trino.ColumnInfo{Type: "ROW(BIGINT, TIMESTAMP(3) WITH TIME ZONE)"},
+			arrow.StructOf(
+				arrow.Field{Name: "field0", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+				arrow.Field{Name: "field1", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}, Nullable: true},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.column.Type, func(t *testing.T) {
+			got := arrowType(columnType(tt.column))
+			if !arrow.TypeEqual(got, tt.expected) {
+				t.Errorf("arrowType(%q) = %s, want %s", tt.column.Type, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// Row is a ROW value; it marshals as a JSON object with its fields in
+// declaration order
+type Row struct {
+	Names  []string
+	Values []interface{}
+}
+
+// MarshalJSON writes the fields as a JSON object, keeping their order
+func (r Row) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range r.Names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Structurer converts the values the driver returns into structured JSON by
+// column type, for the JSON and text formats: ROW values become objects keyed
+// by field name, JSON values are embedded rather than quoted, VARBINARY is
+// base64 encoded and non-finite doubles become "NaN" and "Infinity" strings.
+// ARRAY and MAP values are converted element by element. UUID, IPADDRESS and
+// geometries (as WKT) are already strings. DECIMAL and BIGINT values are
+// encoded by the numbers setting. Arrow and Parquet keep the driver's values.
+type Structurer struct {
+	types   []trinoType
+	numbers string
+}
+
+// NewStructurer returns a converter for rows of the columns that encodes
+// DECIMAL and BIGINT values as numbers says (one of the config.NumberEncoding*
+// constants, empty for the default), or nil when no column needs converting
+func NewStructurer(columns []trino.ColumnInfo, numbers string) *Structurer {
+	s := &Structurer{types: make([]trinoType, len(columns)), numbers: numbers}
+	needed := false
+	for i, col := range columns {
+		s.types[i] = columnType(col)
+		needed = needed || s.needsStructuring(s.types[i])
+	}
+	if !needed {
+		return nil
+	}
+	return s
+}
+
+// Apply converts a row's values in place; a nil Structurer leaves them unchanged
+func (s *Structurer) Apply(values []interface{}) {
+	if s == nil {
+		return
+	}
+	for i, v := range values {
+		if i < len(s.types) {
+			values[i] = s.structure(s.types[i], v)
+		}
+	}
+}
+
+// needsStructuring reports whether values of the type are converted
+func (s *Structurer) needsStructuring(t trinoType) bool {
+	switch t.base {
+	case "row", "json", "varbinary", "double", "real", "array", "map":
+		return true
+	case "decimal", "bigint":
+		return s.numbers == config.NumberEncodingString || s.numbers == config.NumberEncodingFloat
+	}
+	return false
+}
+
+// structure converts a value, nested values included, to its JSON form
+func (s *Structurer) structure(t trinoType, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch t.base {
+	case "row":
+		fields, ok := v.([]interface{})
+		if !ok || len(fields) != len(t.elems) {
+			return v
+		}
+		row := Row{Names: t.names, Values: make([]interface{}, len(fields))}
+		for i, field := range fields {
+			row.Values[i] = s.structure(t.elems[i], field)
+		}
+		return row
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok || len(t.elems) != 1 {
+			return v
+		}
+		converted := make([]interface{}, len(items))
+		for i, item := range items {
+			converted[i] = s.structure(t.elems[0], item)
+		}
+		return converted
+	case "map":
+		entries, ok := v.(map[string]interface{})
+		if !ok || len(t.elems) != 2 {
+			return v
+		}
+		converted := make(map[string]interface{}, len(entries))
+		for key, value := range entries {
+			converted[key] = s.structure(t.elems[1], value)
+		}
+		return converted
+	case "json":
+		// Embed valid JSON documents rather than returning them as strings
+		if doc, ok := v.(string); ok && json.Valid([]byte(doc)) {
+			return json.RawMessage(doc)
+		}
+	case "varbinary":
+		// Nested VARBINARY values already arrive base64 encoded
+		if data, ok := v.([]byte); ok {
+			return base64.StdEncoding.EncodeToString(data)
+		}
+	case "double", "real":
+		// JSON has no NaN or Infinity; Trino spells them like this in nested values
+		if f, ok := v.(float64); ok {
+			switch {
+			case math.IsNaN(f):
+				return "NaN"
+			case math.IsInf(f, 1):
+				return "Infinity"
+			case math.IsInf(f, -1):
+				return "-Infinity"
+			}
+		}
+	case "decimal", "bigint":
+		return s.encodeNumber(v)
+	}
+	return v
+}
+
+// encodeNumber encodes a DECIMAL or BIGINT value by the numbers setting. The
+// driver returns DECIMAL as a string and BIGINT as an int64, or both as
+// json.Number when nested.
+func (s *Structurer) encodeNumber(v interface{}) interface{} {
+	switch s.numbers {
+	case config.NumberEncodingString:
+		switch n := v.(type) {
+		case int64:
+			return strconv.FormatInt(n, 10)
+		case json.Number:
+			return n.String()
+		}
+	case config.NumberEncodingFloat:
+		// json.Number keeps every digit in the output; the client decides how to parse it
+		if n, ok := v.(string); ok {
+			if _, err := strconv.ParseFloat(n, 64); err == nil {
+				return json.Number(n)
+			}
+		}
+	}
+	return v
+}
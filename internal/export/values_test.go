@@ -0,0 +1,104 @@
+package export
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func TestStructurer(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   string
+		value interface{}
+		want  string // JSON encoding of the converted value
+	}{
+		{"row", "ROW(ID BIGINT, NAME VARCHAR)", []interface{}{json.Number("7"), "alice"}, `{"id":7,"name":"alice"}`},
+		{"anonymous row fields", "ROW(BIGINT, VARCHAR)", []interface{}{json.Number("7"), "alice"}, `{"field0":7,"field1":"alice"}`},
+		{"nested row", "ROW(A ROW(B INTEGER))", []interface{}{[]interface{}{json.Number("1")}}, `{"a":{"b":1}}`},
+		{"array of rows", "ARRAY(ROW(K VARCHAR, V DOUBLE))", []interface{}{[]interface{}{"x", json.Number("1.5")}}, `[{"k":"x","v":1.5}]`},
+		{"array of json", "ARRAY(JSON)", []interface{}{`{"a":1}`, "[1,2]"}, `[{"a":1},[1,2]]`},
+		{"map of rows", "MAP(VARCHAR, ROW(N BIGINT))", map[string]interface{}{"a": []interface{}{json.Number("1")}}, `{"a":{"n":1}}`},
+		{"json object", "JSON", `{"b":[1,2],"a":null}`, `{"b":[1,2],"a":null}`},
+		{"json scalar", "JSON", `"text"`, `"text"`},
+		{"invalid json stays a string", "JSON", `{oops`, `"{oops"`},
+		{"varbinary", "VARBINARY", []byte("hi"), `"aGk="`},
+		{"nested varbinary is already base64", "ARRAY(VARBINARY)", []interface{}{"aGk="}, `["aGk="]`},
+		{"uuid", "UUID", "12151fd2-7586-11e9-8f9e-2a86e4085a59", `"12151fd2-7586-11e9-8f9e-2a86e4085a59"`},
+		{"ipaddress", "IPADDRESS", "10.0.0.1", `"10.0.0.1"`},
+		{"geometry as wkt", "GEOMETRY", "POINT (1 2)", `"POINT (1 2)"`},
+		{"nan", "DOUBLE", math.NaN(), `"NaN"`},
+		{"infinity", "REAL", math.Inf(-1), `"-Infinity"`},
+		{"finite double", "DOUBLE", 2.5, `2.5`},
+		{"null row", "ROW(A BIGINT)", nil, `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := []interface{}{tt.value}
+			NewStructurer([]trino.ColumnInfo{{Name: "c", Type: tt.typ}}, "").Apply(values)
+			data, err := json.Marshal(values[0])
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("structured %s = %s, want %s", tt.typ, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructurerNumberEncoding(t *testing.T) {
+	columns := []trino.ColumnInfo{{Type: "DECIMAL(38,10)"}, {Type: "BIGINT"}, {Type: "ARRAY(BIGINT)"}}
+	row := func() []interface{} {
+		return []interface{}{"12345678901234567890.0123456789", int64(9007199254740993), []interface{}{json.Number("9007199254740993")}}
+	}
+	tests := []struct {
+		numbers string
+		want    string
+	}{
+		{"", `["12345678901234567890.0123456789",9007199254740993,[9007199254740993]]`},
+		{config.NumberEncodingDefault, `["12345678901234567890.0123456789",9007199254740993,[9007199254740993]]`},
+		{config.NumberEncodingString, `["12345678901234567890.0123456789","9007199254740993",["9007199254740993"]]`},
+		{config.NumberEncodingFloat, `[12345678901234567890.0123456789,9007199254740993,[9007199254740993]]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.numbers, func(t *testing.T) {
+			values := row()
+			NewStructurer(columns, tt.numbers).Apply(values)
+			data, err := json.Marshal(values)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("structured with %q = %s, want %s", tt.numbers, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructurerSkipsPlainColumns(t *testing.T) {
+	if s := NewStructurer([]trino.ColumnInfo{{Type: "VARCHAR"}, {Type: "BIGINT"}, {Type: "UUID"}}, ""); s != nil {
+		t.Errorf("NewStructurer() = %v, want nil for columns that need no conversion", s)
+	}
+	var s *Structurer
+	values := []interface{}{[]byte("x")}
+	s.Apply(values) // A nil Structurer leaves values unchanged
+	if string(values[0].([]byte)) != "x" {
+		t.Errorf("nil Structurer changed %v", values)
+	}
+}
+
+func TestFormatValueStructured(t *testing.T) {
+	row := Row{Names: []string{"z", "a"}, Values: []interface{}{1, json.RawMessage(`{"k":true}`)}}
+	if got := FormatValue(row); got != `{"z":1,"a":{"k":true}}` {
+		t.Errorf("FormatValue(Row) = %s, want fields in declaration order", got)
+	}
+	if got := FormatValue(json.RawMessage(`[1]`)); got != "[1]" {
+		t.Errorf("FormatValue(json.RawMessage) = %s, want [1]", got)
+	}
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// Supported export formats
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+	FormatArrow   = "arrow"
+)
+
+// NullString is how NULL values are rendered in text formats
+const NullString = "NULL"
+
+// Writer encodes streamed rows into an export format. It implements trino.RowSink.
+type Writer interface {
+	trino.RowSink
+	// Close flushes buffered data; it does not close the underlying io.Writer
+	Close() error
+}
+
+// NormalizeFormat validates an export format, defaulting to CSV
+func NormalizeFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatJSONL, FormatParquet, FormatArrow:
+		return f, nil
+	case "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("invalid export format: %q (allowed: csv, jsonl, parquet, arrow)", format)
+	}
+}
+
+// NewWriter creates a writer for the given format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatJSONL:
+		return &jsonlWriter{w: bufio.NewWriter(w)}, nil
+	case FormatParquet:
+		return newParquetWriter(w), nil
+	case FormatArrow:
+		return newArrowWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %q", format)
+	}
+}
+
+// FormatValue renders a single value for text output
+func FormatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return NullString
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case json.RawMessage:
+		return string(val)
+	case fmt.Stringer:
+		return val.String()
+	case []interface{}, map[string]interface{}, Row:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// csvWriter writes RFC 4180 CSV with a header line
+type csvWriter struct {
+	w          *csv.Writer
+	record     []string
+	structurer *Structurer
+}
+
+func (c *csvWriter) Begin(columns []trino.ColumnInfo) error {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	c.record = make([]string, len(columns))
+	c.structurer = NewStructurer(columns, "")
+	return c.w.Write(header)
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	c.structurer.Apply(values)
+	for i, v := range values {
+		c.record[i] = FormatValue(v)
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlWriter writes one JSON object per line, keeping NULL as null
+type jsonlWriter struct {
+	w          *bufio.Writer
+	columns    []string
+	structurer *Structurer
+}
+
+func (j *jsonlWriter) Begin(columns []trino.ColumnInfo) error {
+	j.columns = make([]string, len(columns))
+	for i, col := range columns {
+		j.columns[i] = col.Name
+	}
+	j.structurer = NewStructurer(columns, "")
+	return nil
+}
+
+func (j *jsonlWriter) WriteRow(values []interface{}) error {
+	j.structurer.Apply(values)
+	row := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		row[j.columns[i]] = v
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("failed to encode row: %w", err)
+	}
+	if _, err := j.w.Write(data); err != nil {
+		return err
+	}
+	return j.w.WriteByte('\n')
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.w.Flush()
+}
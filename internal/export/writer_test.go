@@ -0,0 +1,119 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/parquet/file"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+var sampleColumns = []trino.ColumnInfo{
+	{Name: "id", Type: "BIGINT"},
+	{Name: "name", Type: "VARCHAR"},
+	{Name: "score", Type: "DOUBLE"},
+	{Name: "active", Type: "BOOLEAN"},
+}
+
+var sampleRows = [][]interface{}{
+	{int64(1), "alice, \"a\"", 9.5, true},
+	{int64(2), nil, nil, false},
+}
+
+func writeAll(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter(%q) error = %v", format, err)
+	}
+	if err := w.Begin(sampleColumns); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	for _, row := range sampleRows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestNormalizeFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{input: "", expected: FormatCSV},
+		{input: "CSV", expected: FormatCSV},
+		{input: "jsonl", expected: FormatJSONL},
+		{input: "ndjson", expected: FormatJSONL},
+		{input: "parquet", expected: FormatParquet},
+		{input: "xlsx", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("NormalizeFormat(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	expected := "id,name,score,active\n1,\"alice, \"\"a\"\"\",9.5,true\n2,NULL,NULL,false\n"
+	if got := string(writeAll(t, FormatCSV)); got != expected {
+		t.Errorf("csv output = %q, want %q", got, expected)
+	}
+}
+
+func TestJSONLWriter(t *testing.T) {
+	expected := `{"active":true,"id":1,"name":"alice, \"a\"","score":9.5}` + "\n" +
+		`{"active":false,"id":2,"name":null,"score":null}` + "\n"
+	if got := string(writeAll(t, FormatJSONL)); got != expected {
+		t.Errorf("jsonl output = %q, want %q", got, expected)
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	data := writeAll(t, FormatParquet)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read parquet output: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if got := reader.NumRows(); got != int64(len(sampleRows)) {
+		t.Errorf("NumRows() = %d, want %d", got, len(sampleRows))
+	}
+	schema := reader.MetaData().Schema
+	if got := schema.NumColumns(); got != len(sampleColumns) {
+		t.Fatalf("NumColumns() = %d, want %d", got, len(sampleColumns))
+	}
+	for i, col := range sampleColumns {
+		if got := schema.Column(i).Name(); got != col.Name {
+			t.Errorf("column %d name = %q, want %q", i, got, col.Name)
+		}
+	}
+}
+
+func TestParquetWriterRejectsMismatchedValue(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(FormatParquet, &buf)
+	if err := w.Begin([]trino.ColumnInfo{{Name: "id", Type: "BIGINT"}}); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if err := w.WriteRow([]interface{}{"not a number"}); err == nil {
+		t.Error("WriteRow() expected error for string in BIGINT column")
+	}
+}
@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+	oauth "github.com/tuannvm/oauth-mcp-proxy"
+)
+
+// adminToolPrefix starts the names of the operational tools that need admin
+// authorization on top of the authentication every tool call needs
+const adminToolPrefix = "admin_"
+
+// debugTools are the MCP_DEBUG_ENABLED tools, which expose and change the
+// server's own state and are authorized like the admin tools
+var debugTools = map[string]bool{"server_stats": true, "set_debug_logging": true}
+
+// requiresAdmin reports whether calls of a tool need admin authorization
+func requiresAdmin(tool string) bool {
+	return strings.HasPrefix(tool, adminToolPrefix) || debugTools[tool]
+}
+
+// adminKeyHeader is the HTTP header clients send MCP_ADMIN_API_KEY in
+const adminKeyHeader = "X-MCP-Admin-Key"
+
+type adminKeyContextKey struct{}
+
+// withAdminKey records the admin key an HTTP request carried, "" for none
+func withAdminKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, adminKeyContextKey{}, key)
+}
+
+// isAdmin reports whether a tool call may use the admin tools. Calls over
+// stdio come from the operator who started the server and always may; HTTP
+// calls need MCP_ADMIN_API_KEY or an OAuth user listed in MCP_ADMIN_USERS.
+func isAdmin(ctx context.Context, cfg *config.TrinoConfig) bool {
+	key, overHTTP := ctx.Value(adminKeyContextKey{}).(string)
+	if !overHTTP {
+		return true
+	}
+	if cfg.AdminAPIKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(cfg.AdminAPIKey)) == 1 {
+		return true
+	}
+	user, ok := oauth.GetUserFromContext(ctx)
+	if !ok || user == nil {
+		return false
+	}
+	for _, admin := range cfg.AdminUsers {
+		for _, name := range []string{user.Username, user.Email, user.Subject} {
+			if name != "" && strings.EqualFold(admin, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// adminMiddleware rejects calls of the admin and debug tools by clients that
+// are not admins, so operational tools can be served next to the analyst-facing ones
+func adminMiddleware(cfg *config.TrinoConfig) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !requiresAdmin(request.Params.Name) || isAdmin(ctx, cfg) {
+				return next(ctx, request)
+			}
+			log.Printf("Rejected %s for %s: not an admin", request.Params.Name, clientKey(ctx))
+			return toolError(&trino.QueryError{
+				Name:    trino.ErrorPermissionDenied,
+				Type:    "USER_ERROR",
+				Message: fmt.Sprintf("admin access required: %s is an operator tool", request.Params.Name),
+				Policy:  true,
+				Hint:    "Send MCP_ADMIN_API_KEY in the " + adminKeyHeader + " header, or sign in as a user listed in MCP_ADMIN_USERS",
+			}), nil
+		}
+	}
+}
+
+// AdminKillQuery handles killing a running Trino query of any user
+func (h *TrinoHandlers) AdminKillQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+	queryID, ok := args["query_id"].(string)
+	if !ok || queryID == "" {
+		mcpErr := fmt.Errorf("query_id parameter must be a non-empty string")
+		return toolError(mcpErr), nil
+	}
+
+	cluster, err := trino.Route(ctx, h.Clusters, clusterName(request), false, func(cluster *trino.Cluster) (string, error) {
+		return cluster.Name, cluster.Client.KillQuery(ctx, queryID)
+	})
+	if err != nil {
+		log.Printf("Error killing query %s for %s: %v", queryID, clientKey(ctx), err)
+		return toolError(err), nil
+	}
+	log.Printf("Query %s on cluster '%s' killed by %s", queryID, cluster, clientKey(ctx))
+
+	jsonData, err := json.MarshalIndent(map[string]string{"queryId": queryID, "cluster": cluster, "status": "killed"}, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal kill result to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// invalidatedCaches is the result of admin_invalidate_caches
+type invalidatedCaches struct {
+	MetadataEntries    map[string]int `json:"metadataEntries"`              // Entries dropped by cluster
+	DataCatalogEntries *int           `json:"dataCatalogEntries,omitempty"` // Omitted without TRINO_DATA_CATALOG
+	DBTManifest        bool           `json:"dbtManifestRefreshed"`         // Whether the dbt manifest is read again on next use
+}
+
+// AdminInvalidateCaches handles dropping the cached Trino metadata of every
+// cluster, the data catalog metadata and the dbt manifest, so that changes
+// made outside the server show up at once
+func (h *TrinoHandlers) AdminInvalidateCaches(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result := invalidatedCaches{MetadataEntries: make(map[string]int)}
+	for _, cl := range h.Clusters.List() {
+		result.MetadataEntries[cl.Name] = cl.Client.InvalidateMetadata()
+	}
+	if h.dataCatalog != nil {
+		n := h.dataCatalog.Invalidate()
+		result.DataCatalogEntries = &n
+	}
+	if h.manifest != nil {
+		h.manifest.Refresh()
+		result.DBTManifest = true
+	}
+	log.Printf("Caches invalidated by %s", clientKey(ctx))
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal invalidated caches to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// AdminReloadConfig handles re-reading the reloadable configuration, as
+// SIGHUP does, for deployments where operators cannot signal the process
+func (h *TrinoHandlers) AdminReloadConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.reload == nil {
+		mcpErr := fmt.Errorf("configuration reload is not available")
+		return toolError(mcpErr), nil
+	}
+	log.Printf("Reloading allowlists and result limits for %s...", clientKey(ctx))
+	if err := h.reload(); err != nil {
+		log.Printf("ERROR: Configuration reload failed, keeping current settings: %v", err)
+		mcpErr := fmt.Errorf("configuration reload failed, keeping current settings: %w", err)
+		return toolError(mcpErr), nil
+	}
+	log.Println("Configuration reloaded")
+
+	jsonData, err := json.MarshalIndent(map[string]string{"status": "reloaded"}, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal reload result to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// AdminUsageReport handles reporting every client's query usage today
+func (h *TrinoHandlers) AdminUsageReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jsonData, err := json.MarshalIndent(h.quota.reportAll(), "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal usage report to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
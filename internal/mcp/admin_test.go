@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+	oauth "github.com/tuannvm/oauth-mcp-proxy"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	cfg := &config.TrinoConfig{AdminEnabled: true, AdminAPIKey: "adm1n", AdminUsers: []string{"Alice@example.com"}}
+	overHTTP := withAdminKey(context.Background(), "")
+
+	tests := []struct {
+		name     string
+		tool     string
+		ctx      context.Context
+		rejected bool
+	}{
+		{name: "analyst tool over HTTP", tool: "execute_query", ctx: overHTTP},
+		{name: "stdio", tool: "admin_kill_query", ctx: context.Background()},
+		{name: "no key", tool: "admin_kill_query", ctx: overHTTP, rejected: true},
+		{name: "wrong key", tool: "admin_kill_query", ctx: withAdminKey(context.Background(), "guess"), rejected: true},
+		{name: "admin key", tool: "admin_kill_query", ctx: withAdminKey(context.Background(), "adm1n")},
+		{name: "admin user by email", tool: "admin_usage_report", ctx: oauth.WithUser(overHTTP, &oauth.User{Username: "alice", Email: "alice@example.com"})},
+		{name: "other user", tool: "admin_usage_report", ctx: oauth.WithUser(overHTTP, &oauth.User{Username: "bob"}), rejected: true},
+		{name: "debug tool without key", tool: "set_debug_logging", ctx: overHTTP, rejected: true},
+		{name: "debug tool with admin key", tool: "server_stats", ctx: withAdminKey(context.Background(), "adm1n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tt.tool
+			result, err := adminMiddleware(cfg)(handler)(tt.ctx, request)
+			if err != nil {
+				t.Fatalf("middleware error = %v", err)
+			}
+			if result.IsError != tt.rejected {
+				t.Fatalf("IsError = %v, want %v", result.IsError, tt.rejected)
+			}
+			if tt.rejected {
+				text := result.Content[0].(mcp.TextContent).Text
+				if !strings.Contains(text, "admin access required") || !strings.Contains(text, adminKeyHeader) {
+					t.Errorf("error = %q, want admin access required with a hint", text)
+				}
+			}
+		})
+	}
+
+	// An empty key never matches when MCP_ADMIN_API_KEY is unset
+	if isAdmin(overHTTP, &config.TrinoConfig{AdminEnabled: true}) {
+		t.Error("isAdmin() without MCP_ADMIN_API_KEY accepted an HTTP call without a key")
+	}
+}
+
+func TestAdminInvalidateCaches(t *testing.T) {
+	cfg := &config.TrinoConfig{Host: "localhost", Port: 8080, User: "mcp", DefaultCluster: config.DefaultClusterName}
+	handlers := newTestHandlers(t, cfg)
+
+	result, err := handlers.AdminInvalidateCaches(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("AdminInvalidateCaches() = %v, %v", result, err)
+	}
+	var invalidated invalidatedCaches
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &invalidated); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := invalidated.MetadataEntries[config.DefaultClusterName]; !ok || invalidated.DataCatalogEntries != nil || invalidated.DBTManifest {
+		t.Errorf("invalidated = %+v, want the default cluster's metadata only", invalidated)
+	}
+}
+
+func TestAdminReloadConfig(t *testing.T) {
+	handlers := &TrinoHandlers{Config: &config.TrinoConfig{}}
+	if result, _ := handlers.AdminReloadConfig(context.Background(), mcp.CallToolRequest{}); !result.IsError {
+		t.Error("AdminReloadConfig() without a reload function succeeded")
+	}
+
+	reloads := 0
+	handlers.reload = func() error {
+		if reloads++; reloads > 1 {
+			return errors.New("invalid TRINO_POLICY_FILE")
+		}
+		return nil
+	}
+	if result, _ := handlers.AdminReloadConfig(context.Background(), mcp.CallToolRequest{}); result.IsError {
+		t.Errorf("AdminReloadConfig() = %v", result.Content)
+	}
+	result, _ := handlers.AdminReloadConfig(context.Background(), mcp.CallToolRequest{})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "keeping current settings: invalid TRINO_POLICY_FILE") {
+		t.Errorf("AdminReloadConfig() after a failed reload = %v", result.Content)
+	}
+}
+
+func TestAdminKillQueryRejectsInvalidID(t *testing.T) {
+	cfg := &config.TrinoConfig{Host: "localhost", Port: 8080, User: "mcp", DefaultCluster: config.DefaultClusterName}
+	handlers := newTestHandlers(t, cfg)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"query_id": "x'); DROP TABLE t; --"}
+	result, err := handlers.AdminKillQuery(context.Background(), request)
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "invalid query ID") {
+		t.Errorf("AdminKillQuery() = %v, %v; want an invalid query ID error", result, err)
+	}
+}
+
+// newTestHandlers returns handlers of a single cluster that never connects
+func newTestHandlers(t *testing.T, cfg *config.TrinoConfig) *TrinoHandlers {
+	t.Helper()
+	clusters, err := trino.NewClusters(cfg)
+	if err != nil {
+		t.Fatalf("NewClusters() error = %v", err)
+	}
+	t.Cleanup(func() { _ = clusters.Close() })
+	return NewTrinoHandlers(clusters, cfg)
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// errorResultTooLarge is the error name of a result that does not fit in the memory budget
+const errorResultTooLarge = "RESULT_TOO_LARGE"
+
+// memoryBudget bounds the encoded results that concurrent execute_query calls
+// hold in memory. A nil budget is unlimited.
+type memoryBudget struct {
+	mu    sync.Mutex
+	limit int64
+	used  int64
+}
+
+// newMemoryBudget creates a budget of limit bytes; 0 means unlimited
+func newMemoryBudget(limit int64) *memoryBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &memoryBudget{limit: limit}
+}
+
+// reserve takes n bytes from the budget, reporting false when they are not available
+func (b *memoryBudget) reserve(n int64) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used+n > b.limit {
+		return false
+	}
+	b.used += n
+	return true
+}
+
+// release returns n reserved bytes to the budget
+func (b *memoryBudget) release(n int64) {
+	if b == nil || n == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+}
+
+// inUse returns the bytes reserved by running calls
+func (b *memoryBudget) inUse() int64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// fits reports whether n bytes could ever be held within the budget
+func (b *memoryBudget) fits(n int64) bool {
+	return b == nil || n <= b.limit
+}
+
+// resultTooLarge reports a result that cannot be held within the memory budget
+func resultTooLarge(format string, args ...interface{}) *trino.QueryError {
+	return &trino.QueryError{
+		Name:    errorResultTooLarge,
+		Type:    "INSUFFICIENT_RESOURCES",
+		Message: "result too large: " + fmt.Sprintf(format, args...),
+		Policy:  true,
+		Hint:    "Add a LIMIT or aggregate the rows, or use export_query to write the full result to a file",
+	}
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	if newMemoryBudget(0) != nil {
+		t.Fatal("a zero budget should be unlimited")
+	}
+	var unlimited *memoryBudget
+	if !unlimited.reserve(1<<40) || !unlimited.fits(1<<40) {
+		t.Error("an unlimited budget should allow any reservation")
+	}
+
+	b := newMemoryBudget(100)
+	if !b.reserve(60) {
+		t.Fatal("reserve(60) of 100 should succeed")
+	}
+	if b.reserve(50) {
+		t.Error("reserve(50) should fail with 40 bytes left")
+	}
+	b.release(60)
+	if !b.reserve(100) {
+		t.Error("released bytes should be available again")
+	}
+	if b.fits(101) {
+		t.Error("fits(101) should be false for a budget of 100")
+	}
+}
+
+// assertTooLarge checks that err is a RESULT_TOO_LARGE query error
+func assertTooLarge(t *testing.T, err error) {
+	t.Helper()
+	var queryErr *trino.QueryError
+	if !errors.As(err, &queryErr) || queryErr.Name != errorResultTooLarge {
+		t.Fatalf("error = %v, want %s", err, errorResultTooLarge)
+	}
+	if !strings.Contains(queryErr.Hint, "export_query") || !strings.Contains(queryErr.Hint, "LIMIT") {
+		t.Errorf("hint = %q, want a pointer to export_query and LIMIT", queryErr.Hint)
+	}
+}
+
+func TestSpoolSpillsWhenBudgetIsInUse(t *testing.T) {
+	budget := newMemoryBudget(16)
+	budget.reserve(10) // Held by another query
+
+	s := &spool{limit: 1024, budget: budget}
+	defer func() { _ = s.Close() }()
+	if _, err := s.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !s.Spilled() {
+		t.Error("spool should spill when the budget cannot hold its output")
+	}
+	if budget.used != 10 {
+		t.Errorf("budget used = %d, want the other query's 10 bytes", budget.used)
+	}
+}
+
+func TestSpoolFailsWhenBudgetIsInUseAndSpillingIsDisabled(t *testing.T) {
+	budget := newMemoryBudget(16)
+	budget.reserve(10)
+
+	s := &spool{budget: budget}
+	defer func() { _ = s.Close() }()
+	if _, err := s.Write([]byte("abc")); err != nil {
+		t.Fatalf("Write() within the budget error = %v", err)
+	}
+	_, err := s.Write([]byte("0123456789"))
+	assertTooLarge(t, err)
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if budget.used != 10 {
+		t.Errorf("budget used after Close() = %d, want 10", budget.used)
+	}
+}
+
+func TestSpoolRejectsResultLargerThanBudget(t *testing.T) {
+	s := &spool{limit: 4, budget: newMemoryBudget(16)}
+	defer func() { _ = s.Close() }()
+	_, err := s.Write([]byte(strings.Repeat("x", 17)))
+	assertTooLarge(t, err)
+}
+
+func TestResultEncoderRenderReservesBudget(t *testing.T) {
+	result := sampleResult()
+	result.ColumnTypes = []trino.ColumnInfo{{Name: "name"}, {Name: "note"}, {Name: "amount"}}
+	budget := newMemoryBudget(1024)
+
+	encoder := newResultEncoder(formatCSV, 0, budget, valueEncoding{})
+	output := encodeStreamed(t, encoder, result)
+	if want := 2 * int64(len(output)); budget.used != want {
+		t.Errorf("budget used = %d, want %d for the buffer and the response", budget.used, want)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if budget.used != 0 {
+		t.Errorf("budget used after Close() = %d, want 0", budget.used)
+	}
+
+	// A response the remaining budget cannot hold is rejected
+	budget.reserve(1024 - int64(len(output)))
+	encoder = newResultEncoder(formatCSV, 1, budget, valueEncoding{})
+	defer func() { _ = encoder.Close() }()
+	if err := encoder.Begin(result.ColumnTypes); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	for _, row := range result.Rows {
+		if err := encoder.WriteRow([]interface{}{row["name"], row["note"], row["amount"]}); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	budget.reserve(1)
+	_, err := encoder.render(result)
+	assertTooLarge(t, err)
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// methodNotificationCancelled is sent by clients to abort an in-flight request
+	methodNotificationCancelled = "notifications/cancelled"
+
+	// requestIDMetaKey is the _meta field used to hand the JSON-RPC request ID
+	// from the before-call hook to the tool handler middleware
+	requestIDMetaKey = "mcp-trino/requestId"
+)
+
+// requestCanceller tracks in-flight tool calls so that a notifications/cancelled
+// message from the client cancels the handler context, which in turn cancels
+// the Trino query running on its behalf.
+type requestCanceller struct {
+	mu       sync.Mutex
+	inFlight map[string]context.CancelFunc
+}
+
+// newRequestCanceller creates an empty request canceller
+func newRequestCanceller() *requestCanceller {
+	return &requestCanceller{inFlight: make(map[string]context.CancelFunc)}
+}
+
+// requestKey builds a key that is unique per session and request ID
+func requestKey(ctx context.Context, id any) string {
+	key := mcp.NewRequestId(id).String()
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		key = session.SessionID() + "/" + key
+	}
+	return key
+}
+
+// beforeCallTool stamps the request key onto the tool call so the middleware can find it.
+// mcp-go does not pass the JSON-RPC ID to tool handlers, so _meta is used as the carrier.
+func (rc *requestCanceller) beforeCallTool(ctx context.Context, id any, request *mcp.CallToolRequest) {
+	if request.Params.Meta == nil {
+		request.Params.Meta = &mcp.Meta{}
+	}
+	if request.Params.Meta.AdditionalFields == nil {
+		request.Params.Meta.AdditionalFields = make(map[string]any)
+	}
+	request.Params.Meta.AdditionalFields[requestIDMetaKey] = requestKey(ctx, id)
+}
+
+// middleware wraps tool handlers with a cancellable context registered under the request key
+func (rc *requestCanceller) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var key string
+		if request.Params.Meta != nil {
+			key, _ = request.Params.Meta.AdditionalFields[requestIDMetaKey].(string)
+		}
+		if key == "" {
+			return next(ctx, request)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		rc.mu.Lock()
+		rc.inFlight[key] = cancel
+		rc.mu.Unlock()
+
+		defer func() {
+			rc.mu.Lock()
+			delete(rc.inFlight, key)
+			rc.mu.Unlock()
+			cancel()
+		}()
+
+		return next(ctx, request)
+	}
+}
+
+// handleCancelled cancels the in-flight tool call named by a notifications/cancelled message
+func (rc *requestCanceller) handleCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+	key := requestKey(ctx, id)
+
+	rc.mu.Lock()
+	cancel, ok := rc.inFlight[key]
+	rc.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	reason, _ := notification.Params.AdditionalFields["reason"].(string)
+	log.Printf("INFO: Client cancelled request %s: %s", key, reason)
+	cancel()
+}
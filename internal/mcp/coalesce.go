@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// queryFlight is a tool call whose result is shared by identical concurrent calls
+type queryFlight struct {
+	done    chan struct{} // Closed when result and err are set
+	result  *mcp.CallToolResult
+	err     error
+	waiters int                // Callers still waiting for the result
+	cancel  context.CancelFunc // Cancels the call once every caller gave up
+}
+
+// queryCoalescer runs identical concurrent tool calls once and fans the result
+// out, so an agent retrying a slow query does not start it again on the
+// cluster. A call runs until its last waiting caller goes away.
+type queryCoalescer struct {
+	mu      sync.Mutex
+	flights map[string]*queryFlight
+}
+
+// newQueryCoalescer creates a coalescer without calls in flight
+func newQueryCoalescer() *queryCoalescer {
+	return &queryCoalescer{flights: make(map[string]*queryFlight)}
+}
+
+// do runs next for the key, or waits for the call with that key already running
+func (g *queryCoalescer) do(ctx context.Context, key string, request mcp.CallToolRequest, next server.ToolHandlerFunc) (*mcp.CallToolResult, error) {
+	g.mu.Lock()
+	flight, ok := g.flights[key]
+	if ok {
+		flight.waiters++
+		log.Printf("INFO: %s joined an identical call already running", request.Params.Name)
+	} else {
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		flight = &queryFlight{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.flights[key] = flight
+		go g.run(runCtx, key, flight, request, next)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-flight.done:
+		return flight.result, flight.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		flight.waiters--
+		if flight.waiters == 0 {
+			// Nobody wants the result any more: stop the query, and let new
+			// callers start a fresh one rather than join a cancelled call
+			flight.cancel()
+			if g.flights[key] == flight {
+				delete(g.flights, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// run executes the call and hands the result to every waiting caller
+func (g *queryCoalescer) run(ctx context.Context, key string, flight *queryFlight, request mcp.CallToolRequest, next server.ToolHandlerFunc) {
+	result, err := next(ctx, request)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.flights[key] == flight {
+		delete(g.flights, key)
+	}
+	flight.result, flight.err = result, err
+	flight.cancel()
+	close(flight.done)
+}
+
+// size returns the number of calls in flight
+func (g *queryCoalescer) size() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.flights)
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestQueryCoalescer(t *testing.T) {
+	g := newQueryCoalescer()
+	var runs atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		runs.Add(1)
+		started <- struct{}{}
+		<-release
+		return mcp.NewToolResultText("rows"), nil
+	}
+	var request mcp.CallToolRequest
+
+	// Three identical calls share one run
+	var wg sync.WaitGroup
+	results := make([]*mcp.CallToolResult, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = g.do(context.Background(), "key", request, handler)
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+	waitForWaiters(t, g, "key", 3)
+	close(release)
+	wg.Wait()
+
+	if n := runs.Load(); n != 1 {
+		t.Errorf("handler ran %d times, want 1", n)
+	}
+	for i, result := range results {
+		if result == nil || result.Content[0].(mcp.TextContent).Text != "rows" {
+			t.Errorf("result %d = %+v, want the shared rows", i, result)
+		}
+	}
+
+	// Once finished, the next call runs again
+	if _, err := g.do(context.Background(), "key", request, handler); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if n := runs.Load(); n != 2 {
+		t.Errorf("handler ran %d times, want a new run after the first finished", n)
+	}
+}
+
+func TestQueryCoalescerCancelsWhenAllCallersLeave(t *testing.T) {
+	g := newQueryCoalescer()
+	cancelled := make(chan struct{})
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}
+	var request mcp.CallToolRequest
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	second, cancelSecond := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() { _, err := g.do(first, "key", request, handler); errs <- err }()
+	waitForWaiters(t, g, "key", 1)
+	go func() { _, err := g.do(second, "key", request, handler); errs <- err }()
+	waitForWaiters(t, g, "key", 2)
+
+	// The query keeps running for the caller still waiting
+	cancelFirst()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller error = %v, want context.Canceled", err)
+	}
+	select {
+	case <-cancelled:
+		t.Fatal("query cancelled while a caller still waits for it")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancelSecond()
+	<-errs
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("query not cancelled after every caller left")
+	}
+}
+
+// waitForWaiters waits until n callers wait for the call with the key
+func waitForWaiters(t *testing.T, g *queryCoalescer, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		flight, ok := g.flights[key]
+		waiting := ok && flight.waiters == n
+		g.mu.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d callers", n)
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// methodCompletion is sent by clients to autocomplete an argument as the user
+// types. mcp-go does not dispatch it, so the interceptor answers it.
+const methodCompletion = "completion/complete"
+
+// completionParams are the params of completion/complete, with the arguments
+// already given that mcp.CompleteParams leaves out
+type completionParams struct {
+	Argument struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"argument"`
+	Context struct {
+		Arguments map[string]string `json:"arguments"`
+	} `json:"context"`
+}
+
+// completer answers completion/complete requests for the catalog, schema
+// and table arguments of the tools from the clusters' metadata caches
+type completer struct {
+	handlers     *TrinoHandlers
+	authenticate authenticator
+}
+
+// handle answers a completion/complete request
+func (c *completer) handle(ctx context.Context, id mcp.RequestId, message json.RawMessage) mcp.JSONRPCMessage {
+	var params completionParams
+	if err := json.Unmarshal(message, &params); err != nil {
+		return mcp.NewJSONRPCError(id, mcp.INVALID_PARAMS, "invalid completion params: "+err.Error(), nil)
+	}
+	ctx, err := c.authenticate(ctx, methodCompletion)
+	if err != nil {
+		return mcp.NewJSONRPCError(id, mcp.INVALID_REQUEST, err.Error(), nil)
+	}
+
+	completion := c.handlers.complete(ctx, params.Argument.Name, params.Argument.Value, params.Context.Arguments)
+	result := mcp.CompleteResult{}
+	result.Completion.Values = completion.Values
+	result.Completion.Total = completion.Total
+	result.Completion.HasMore = completion.Total > len(completion.Values)
+	return mcp.NewJSONRPCResultResponse(id, result)
+}
+
+// complete looks up the completions of an argument. Trino errors leave the
+// argument without completions rather than failing the request, since
+// clients ask again on the next keystroke.
+func (h *TrinoHandlers) complete(ctx context.Context, argument, value string, given map[string]string) *trino.Completion {
+	completion, err := trino.Route(ctx, h.Clusters, given["cluster"], true, func(cl *trino.Cluster) (*trino.Completion, error) {
+		return cl.Client.CompleteWithContext(ctx, argument, value, given["catalog"], given["schema"])
+	})
+	if err != nil {
+		log.Printf("WARNING: Failed to complete %s %q: %v", argument, value, err)
+		return &trino.Completion{Values: []string{}}
+	}
+	return completion
+}
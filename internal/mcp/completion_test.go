@@ -0,0 +1,40 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestCompleterHandle(t *testing.T) {
+	cfg := &config.TrinoConfig{Host: "localhost", Port: 8080, User: "mcp", DefaultCluster: config.DefaultClusterName}
+	c := &completer{handlers: newTestHandlers(t, cfg), authenticate: newAuthenticator()}
+
+	// Arguments other than catalog, schema and table have no completions
+	params := json.RawMessage(`{"ref":{"type":"ref/prompt","name":"p"},"argument":{"name":"query","value":"SEL"}}`)
+	data, _ := json.Marshal(c.handle(context.Background(), mcp.NewRequestId(7), params))
+	if got := string(data); !strings.Contains(got, `"id":7`) || !strings.Contains(got, `"completion":{"values":[]}`) {
+		t.Errorf("response = %s", got)
+	}
+}
+
+func TestCompleterHandleRejected(t *testing.T) {
+	cfg := &config.TrinoConfig{Host: "localhost", Port: 8080, User: "mcp", DefaultCluster: config.DefaultClusterName}
+	reject := func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultError("authentication required"), nil
+		}
+	}
+	c := &completer{handlers: newTestHandlers(t, cfg), authenticate: newAuthenticator(reject)}
+
+	response := c.handle(context.Background(), mcp.NewRequestId("a"), json.RawMessage(`{"argument":{"name":"table","value":"o"}}`))
+	rpcErr, ok := response.(mcp.JSONRPCError)
+	if !ok || rpcErr.Error.Message != "authentication required" {
+		t.Errorf("response = %+v, want the middleware's error", response)
+	}
+}
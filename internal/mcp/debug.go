@@ -0,0 +1,212 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// sqlArguments are tool arguments holding SQL, whose string literals debug logging redacts
+var sqlArguments = map[string]bool{"query": true, "where": true, "filter": true, "template": true}
+
+// valueArguments are tool arguments holding bound values, which debug logging hides
+var valueArguments = map[string]bool{"params": true, "arguments": true}
+
+// startedAt is when the server process started, for the uptime in server_stats
+var startedAt = time.Now()
+
+// registerDebugHandlers serves the pprof profiles under /debug/pprof/. With
+// a token, requests must send it as a bearer token.
+func registerDebugHandlers(mux *http.ServeMux, token string) {
+	handle := func(path string, handler http.HandlerFunc) {
+		mux.Handle(path, requireDebugToken(token, handler))
+	}
+	handle("/debug/pprof/", pprof.Index) // Also serves heap, goroutine, allocs, block, mutex and threadcreate
+	handle("/debug/pprof/cmdline", pprof.Cmdline)
+	handle("/debug/pprof/profile", pprof.Profile)
+	handle("/debug/pprof/symbol", pprof.Symbol)
+	handle("/debug/pprof/trace", pprof.Trace)
+}
+
+// requireDebugToken rejects requests without the bearer token; an empty token lets every request through
+func requireDebugToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-trino debug"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serverStats is the result of server_stats
+type serverStats struct {
+	Uptime     string         `json:"uptime"`
+	GoVersion  string         `json:"goVersion"`
+	Goroutines int            `json:"goroutines"`
+	Heap       heapStats      `json:"heap"`
+	Caches     cacheStats     `json:"caches"`
+	Clusters   []clusterStats `json:"clusters"`
+}
+
+// heapStats summarizes runtime.MemStats
+type heapStats struct {
+	AllocBytes     uint64     `json:"allocBytes"` // Bytes of live heap objects
+	InUseBytes     uint64     `json:"inUseBytes"` // Bytes of heap spans in use
+	SysBytes       uint64     `json:"sysBytes"`   // Memory obtained from the OS
+	Objects        uint64     `json:"objects"`    // Live heap objects
+	GCCycles       uint32     `json:"gcCycles"`
+	GCPauseSeconds float64    `json:"gcPauseSeconds"` // Total stop-the-world pause time
+	LastGC         *time.Time `json:"lastGC,omitempty"`
+}
+
+// cacheStats reports the server's in-memory state of tool calls
+type cacheStats struct {
+	IdempotentCalls   int   `json:"idempotentCalls"`   // execute_query results kept for retries
+	QueriesInFlight   int   `json:"queriesInFlight"`   // Read-only execute_query calls running, shared by identical calls
+	ResultBufferBytes int64 `json:"resultBufferBytes"` // Result memory reserved against TRINO_RESULT_MEMORY_BUDGET
+}
+
+// clusterStats reports a cluster client's connection pool and metadata cache
+type clusterStats struct {
+	Name string `json:"name"`
+	trino.ClientStats
+}
+
+// ServerStats handles reporting the server's runtime state for operators:
+// goroutines, heap, cache sizes and open Trino connections
+func (h *TrinoHandlers) ServerStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := serverStats{
+		Uptime:     time.Since(startedAt).Round(time.Second).String(),
+		GoVersion:  runtime.Version(),
+		Goroutines: runtime.NumGoroutine(),
+		Heap: heapStats{
+			AllocBytes:     mem.HeapAlloc,
+			InUseBytes:     mem.HeapInuse,
+			SysBytes:       mem.Sys,
+			Objects:        mem.HeapObjects,
+			GCCycles:       mem.NumGC,
+			GCPauseSeconds: time.Duration(mem.PauseTotalNs).Seconds(),
+		},
+		Caches: cacheStats{
+			IdempotentCalls:   h.idempotency.size(),
+			QueriesInFlight:   h.inFlight.size(),
+			ResultBufferBytes: h.budget.inUse(),
+		},
+		Clusters: []clusterStats{},
+	}
+	if mem.LastGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC)).UTC()
+		stats.Heap.LastGC = &lastGC
+	}
+	for _, cl := range h.Clusters.List() {
+		stats.Clusters = append(stats.Clusters, clusterStats{Name: cl.Name, ClientStats: cl.Client.Stats()})
+	}
+
+	jsonData, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal server stats to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// debugLogMiddleware logs every tool call with its arguments, and its outcome
+// and duration, while debug logging is on. String literals in SQL arguments
+// and bound values are redacted.
+func debugLogMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !trino.DebugLogging() {
+			return next(ctx, request)
+		}
+		args, _ := json.Marshal(redactArguments(request.Params.Arguments))
+		trino.Debugf("tools/call %s from %s: %s", request.Params.Name, clientKey(ctx), args)
+
+		started := time.Now()
+		result, err := next(ctx, request)
+		elapsed := time.Since(started).Round(time.Millisecond)
+		switch {
+		case err != nil:
+			trino.Debugf("tools/call %s failed after %v: %v", request.Params.Name, elapsed, err)
+		case result != nil && result.IsError:
+			trino.Debugf("tools/call %s returned an error result after %v", request.Params.Name, elapsed)
+		default:
+			trino.Debugf("tools/call %s succeeded in %v (%d bytes)", request.Params.Name, elapsed, resultSize(result))
+		}
+		return result, err
+	}
+}
+
+// redactArguments returns the tool arguments with SQL string literals and bound values redacted
+func redactArguments(arguments interface{}) interface{} {
+	args, ok := arguments.(map[string]interface{})
+	if !ok {
+		return arguments
+	}
+	redacted := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		if sql, ok := value.(string); ok && sqlArguments[name] {
+			value = trino.RedactSQL(sql)
+		}
+		if valueArguments[name] {
+			value = "[REDACTED]"
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// resultSize returns the bytes of text in a tool result
+func resultSize(result *mcp.CallToolResult) int {
+	size := 0
+	if result == nil {
+		return size
+	}
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			size += len(text.Text)
+		}
+	}
+	return size
+}
+
+// SetDebugLogging handles turning debug logging on or off at runtime
+func (h *TrinoHandlers) SetDebugLogging(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+	enabled, ok := args["enabled"].(bool)
+	if !ok {
+		mcpErr := fmt.Errorf("enabled parameter must be a boolean")
+		return toolError(mcpErr), nil
+	}
+	log.Printf("Debug logging set to %t by %s", enabled, clientKey(ctx))
+	trino.SetDebugLogging(enabled)
+
+	jsonData, err := json.MarshalIndent(map[string]bool{"debugLogging": trino.DebugLogging()}, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal debug logging state to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func TestDebugHandlers(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          int
+	}{
+		{name: "open without token", want: http.StatusOK},
+		{name: "missing token", token: "s3cret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "s3cret", authorization: "Bearer guess", want: http.StatusUnauthorized},
+		{name: "valid token", token: "s3cret", authorization: "Bearer s3cret", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			registerDebugHandlers(mux, tt.token)
+			req := httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerStats(t *testing.T) {
+	h := NewTrinoHandlers(&trino.Clusters{}, &config.TrinoConfig{ResultMemoryBudget: 1 << 20})
+	h.budget.reserve(1024)
+
+	result, err := h.ServerStats(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("ServerStats() = %v, %v", result, err)
+	}
+	var stats serverStats
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &stats); err != nil {
+		t.Fatalf("invalid stats: %v", err)
+	}
+	if stats.Goroutines == 0 || stats.Heap.SysBytes == 0 {
+		t.Errorf("stats = %+v, want goroutines and heap reported", stats)
+	}
+	if stats.Caches.ResultBufferBytes != 1024 {
+		t.Errorf("resultBufferBytes = %d, want the reserved 1024", stats.Caches.ResultBufferBytes)
+	}
+}
+
+func TestDebugToolsRequireAdmin(t *testing.T) {
+	// Debug tools are authorized like the admin tools even without MCP_ADMIN_ENABLED
+	cfg := &config.TrinoConfig{Host: "localhost", Port: 8080, User: "mcp", DefaultCluster: config.DefaultClusterName, DebugEnabled: true}
+	handlers := newTestHandlers(t, cfg)
+	mcpServer, _, _ := createMCPServer(handlers.Clusters, cfg, "dev", newQuotaStore(cfg), nil)
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"set_debug_logging","arguments":{"enabled":true}}}`
+	response := mcpServer.HandleMessage(withAdminKey(context.Background(), ""), json.RawMessage(call))
+	result, ok := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	if !ok || !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "admin access required") {
+		t.Fatalf("set_debug_logging over HTTP without admin access = %+v, want it refused", response)
+	}
+	if trino.DebugLogging() {
+		t.Error("refused set_debug_logging call turned debug logging on")
+	}
+}
+
+func TestRedactArguments(t *testing.T) {
+	got := redactArguments(map[string]interface{}{
+		"query":   "SELECT * FROM users WHERE email = ?",
+		"where":   "country = 'NZ'",
+		"params":  []interface{}{"alice@example.com"},
+		"catalog": "hive",
+	}).(map[string]interface{})
+
+	want := map[string]interface{}{
+		"query":   "SELECT * FROM users WHERE email = ?",
+		"where":   "country = '***'",
+		"params":  "[REDACTED]",
+		"catalog": "hive",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("redactArguments() = %v, want %v", got, want)
+	}
+}
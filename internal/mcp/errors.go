@@ -0,0 +1,32 @@
+package mcp
+
+import (
+	"errors"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// queryErrorContent is the structured content of a tool error caused by a
+// failed or rejected query
+type queryErrorContent struct {
+	Error *trino.QueryError `json:"error"`
+}
+
+// toolError reports a failed tool call. Errors from Trino or this server's
+// query policies also carry the error name, code, position and a hint as
+// structured content, and the hint is added to the text for clients that
+// only show text.
+func toolError(err error) *mcp.CallToolResult {
+	var queryErr *trino.QueryError
+	if !errors.As(err, &queryErr) {
+		return mcp.NewToolResultError(err.Error())
+	}
+	text := err.Error()
+	if queryErr.Hint != "" {
+		text += "\nHint: " + queryErr.Hint
+	}
+	result := mcp.NewToolResultError(text)
+	result.StructuredContent = queryErrorContent{Error: queryErr}
+	return result
+}
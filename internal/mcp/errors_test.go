@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func TestToolError(t *testing.T) {
+	plain := toolError(errors.New("invalid arguments format"))
+	if !plain.IsError || plain.StructuredContent != nil {
+		t.Errorf("toolError() = %+v, want an error without structured content", plain)
+	}
+	if text := plain.Content[0].(mcp.TextContent).Text; text != "invalid arguments format" {
+		t.Errorf("toolError() text = %q", text)
+	}
+
+	queryErr := &trino.QueryError{Name: "COLUMN_NOT_FOUND", Type: "USER_ERROR", Message: "line 1:8: Column 'x' cannot be resolved", Line: 1, Column: 8, Hint: "Use get_table_schema"}
+	result := toolError(fmt.Errorf("query execution failed: %w", queryErr))
+	if !result.IsError {
+		t.Error("toolError() should mark the result as an error")
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.HasPrefix(text, "query execution failed: COLUMN_NOT_FOUND: line 1:8") || !strings.HasSuffix(text, "\nHint: Use get_table_schema") {
+		t.Errorf("toolError() text = %q", text)
+	}
+	content, ok := result.StructuredContent.(queryErrorContent)
+	if !ok || content.Error != queryErr {
+		t.Errorf("toolError() structured content = %+v, want the query error", result.StructuredContent)
+	}
+}
@@ -0,0 +1,446 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/export"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// Supported execute_query output formats
+const (
+	formatJSON     = "json"
+	formatCSV      = "csv"
+	formatTSV      = "tsv"
+	formatMarkdown = "markdown"
+	formatArrow    = "arrow"
+)
+
+// resultMetadata is the execution metadata reported next to non-JSON results
+type resultMetadata struct {
+	QueryID          string            `json:"queryId,omitempty"`
+	InfoURI          string            `json:"infoUri,omitempty"`
+	Stats            *trino.QueryStats `json:"stats,omitempty"`
+	RowCount         int               `json:"rowCount"`
+	Truncated        bool              `json:"truncated,omitempty"`
+	TruncationReason string            `json:"truncationReason,omitempty"`
+	RowsScanned      int64             `json:"rowsScanned,omitempty"`
+}
+
+// normalizeFormat validates a requested output format, defaulting to JSON
+func normalizeFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "":
+		return formatJSON, nil
+	case formatJSON, formatCSV, formatTSV:
+		return f, nil
+	case formatMarkdown, "md":
+		return formatMarkdown, nil
+	case formatArrow:
+		return formatArrow, nil
+	default:
+		return "", fmt.Errorf("invalid format: %q (allowed: json, csv, tsv, markdown, arrow)", format)
+	}
+}
+
+// formatResult renders query rows in the requested format
+func formatResult(result *trino.QueryResult, format string) (string, error) {
+	if format != formatArrow {
+		structureRows(result)
+	}
+	switch format {
+	case formatCSV:
+		return formatDelimited(result, ',')
+	case formatTSV:
+		return formatTSVRows(result), nil
+	case formatMarkdown:
+		return formatMarkdownTable(result), nil
+	case formatArrow:
+		// Base64-encoded Arrow IPC stream, keeping decimal, temporal and nested types intact
+		data, err := export.EncodeArrow(result)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	default:
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(jsonData), nil
+	}
+}
+
+// resultEncoder renders rows in an execute_query output format as they are
+// read from Trino, so a result is never held as decoded rows. The encoded
+// output goes to a spool that moves to a temporary file beyond the spill
+// threshold. It implements trino.RowSink; rows are rendered exactly as
+// formatResult renders them.
+type resultEncoder struct {
+	format     string
+	out        spool
+	columns    []string
+	values     valueEncoding
+	structurer *export.Structurer // Structures nested values for all formats but Arrow
+	rows       int
+	rendered   int64 // Budget taken by the rendered response
+
+	record   []string          // Reused for CSV records
+	csv      *csv.Writer       // CSV rows
+	replacer *strings.Replacer // TSV and Markdown cell escaping
+	arrow    export.Writer     // Arrow IPC stream, base64-encoded by b64
+	b64      io.WriteCloser
+}
+
+// valueEncoding is how execute_query represents values; the zero value keeps
+// the driver's values
+type valueEncoding struct {
+	numbers  string // TRINO_NUMBER_ENCODING of DECIMAL and BIGINT values
+	null     string // TRINO_NULL_VALUE: config.NullValueNull, config.NullValueEmpty or a sentinel
+	maxChars int    // TRINO_MAX_CELL_CHARS: strings beyond it are truncated (0 means unlimited)
+}
+
+// newValueEncoding returns the value encoding configured for the server
+func newValueEncoding(cfg *config.TrinoConfig) valueEncoding {
+	return valueEncoding{numbers: cfg.NumberEncoding, null: cfg.NullValue, maxChars: cfg.MaxCellChars}
+}
+
+// truncationMarker ends strings cut at the configured length
+const truncationMarker = "…"
+
+// apply replaces NULLs by the configured representation and truncates long
+// strings in place. Nested values are left alone.
+func (v valueEncoding) apply(values []interface{}) {
+	for i, value := range values {
+		switch value := value.(type) {
+		case nil:
+			switch v.null {
+			case "", config.NullValueNull:
+			case config.NullValueEmpty:
+				values[i] = ""
+			default:
+				values[i] = v.null
+			}
+		case string:
+			if v.maxChars > 0 && utf8.RuneCountInString(value) > v.maxChars {
+				values[i] = string([]rune(value)[:v.maxChars]) + truncationMarker
+			}
+		}
+	}
+}
+
+// newResultEncoder creates an encoder for a normalized format that spills
+// its output to a temporary file beyond spillBytes (0 never spills), takes
+// the memory it buffers from budget and represents values as values says
+func newResultEncoder(format string, spillBytes int64, budget *memoryBudget, values valueEncoding) *resultEncoder {
+	return &resultEncoder{format: format, values: values, out: spool{limit: spillBytes, budget: budget}}
+}
+
+func (e *resultEncoder) Begin(columns []trino.ColumnInfo) error {
+	e.columns = make([]string, len(columns))
+	for i, col := range columns {
+		e.columns[i] = col.Name
+	}
+	if e.format != formatArrow {
+		e.structurer = export.NewStructurer(columns, e.values.numbers)
+	}
+
+	switch e.format {
+	case formatCSV:
+		e.csv = csv.NewWriter(&e.out)
+		e.record = make([]string, len(columns))
+		return e.csv.Write(e.columns)
+	case formatTSV:
+		e.replacer = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+		return e.writeLine(e.columns)
+	case formatMarkdown:
+		e.replacer = strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+		if err := e.writeLine(e.columns); err != nil {
+			return err
+		}
+		_, err := io.WriteString(&e.out, "|"+strings.Repeat(" --- |", len(columns))+"\n")
+		return err
+	case formatArrow:
+		e.b64 = base64.NewEncoder(base64.StdEncoding, &e.out)
+		w, err := export.NewWriter(export.FormatArrow, e.b64)
+		if err != nil {
+			return err
+		}
+		e.arrow = w
+		return w.Begin(columns)
+	}
+	return nil
+}
+
+func (e *resultEncoder) WriteRow(values []interface{}) error {
+	e.rows++
+	if e.format != formatArrow {
+		e.structurer.Apply(values)
+		e.values.apply(values)
+	}
+	switch e.format {
+	case formatCSV:
+		for i, v := range values {
+			e.record[i] = export.FormatValue(v)
+		}
+		return e.csv.Write(e.record)
+	case formatTSV:
+		return e.writeLine(formatValues(values))
+	case formatMarkdown:
+		return e.writeLine(formatValues(values))
+	case formatArrow:
+		return e.arrow.WriteRow(values)
+	default:
+		// Indented like the rows of json.MarshalIndent(result, "", "  ")
+		row := make(map[string]interface{}, len(e.columns))
+		for i, col := range e.columns {
+			row[col] = values[i]
+		}
+		data, err := json.MarshalIndent(row, "    ", "  ")
+		if err != nil {
+			return err
+		}
+		separator := ",\n    "
+		if e.rows == 1 {
+			separator = "    "
+		}
+		if _, err := io.WriteString(&e.out, separator); err != nil {
+			return err
+		}
+		_, err = e.out.Write(data)
+		return err
+	}
+}
+
+// writeLine writes escaped cells as a TSV line or a Markdown table row
+func (e *resultEncoder) writeLine(cells []string) error {
+	var sb strings.Builder
+	if e.format == formatMarkdown {
+		sb.WriteString("|")
+	}
+	for i, cell := range cells {
+		cell = e.replacer.Replace(cell)
+		if e.format == formatMarkdown {
+			sb.WriteString(" " + cell + " |")
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('\t')
+		}
+		sb.WriteString(cell)
+	}
+	sb.WriteString("\n")
+	_, err := io.WriteString(&e.out, sb.String())
+	return err
+}
+
+// formatValues renders values for text output
+func formatValues(values []interface{}) []string {
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = export.FormatValue(v)
+	}
+	return cells
+}
+
+// Reset discards the rows received so far, so the query can be replayed
+func (e *resultEncoder) Reset() error {
+	e.rows = 0
+	e.csv, e.arrow, e.b64 = nil, nil, nil
+	return e.out.Close()
+}
+
+// Close discards the encoded output, removing a spill file, and returns the
+// memory of the rendered response to the budget
+func (e *resultEncoder) Close() error {
+	e.out.budget.release(e.rendered)
+	e.rendered = 0
+	return e.out.Close()
+}
+
+// render returns the complete output for a result whose rows were streamed
+// to the encoder, adding the JSON envelope or the Markdown truncation note
+func (e *resultEncoder) render(result *trino.QueryResult) (string, error) {
+	var head, tail string
+	switch e.format {
+	case formatCSV:
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return "", err
+		}
+	case formatMarkdown:
+		if result.Truncated {
+			tail = fmt.Sprintf("\n_Results truncated after %d rows (%s)._\n", result.RowCount, result.TruncationReason)
+		}
+	case formatArrow:
+		if err := e.arrow.Close(); err != nil {
+			return "", err
+		}
+		if err := e.b64.Close(); err != nil {
+			return "", err
+		}
+	case formatJSON:
+		envelope := *result
+		envelope.Rows = []map[string]interface{}{}
+		data, err := json.MarshalIndent(&envelope, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		head = string(data)
+		if e.rows > 0 {
+			// Splice the streamed rows into the empty array that closes the envelope
+			head = strings.TrimSuffix(head, "[]\n}") + "[\n"
+			tail = "\n  ]\n}"
+		}
+	}
+
+	size := int64(len(head)) + e.out.Len() + int64(len(tail))
+	if !e.out.budget.reserve(size) {
+		return "", resultTooLarge("the %d-byte response does not fit in the server's memory budget for results (TRINO_RESULT_MEMORY_BUDGET) while other queries use it", size)
+	}
+	e.rendered += size
+
+	var sb strings.Builder
+	sb.Grow(int(size))
+	sb.WriteString(head)
+	if _, err := e.out.WriteTo(&sb); err != nil {
+		return "", fmt.Errorf("failed to read spilled results: %w", err)
+	}
+	sb.WriteString(tail)
+	return sb.String(), nil
+}
+
+// NormalizeFormat validates an execute_query output format for the query subcommand
+func NormalizeFormat(format string) (string, error) {
+	return normalizeFormat(format)
+}
+
+// FormatResult renders a query result in a normalized execute_query output format
+func FormatResult(result *trino.QueryResult, format string) (string, error) {
+	return formatResult(result, format)
+}
+
+// structureRows converts the nested values of collected rows like
+// resultEncoder does for streamed ones
+func structureRows(result *trino.QueryResult) {
+	structurer := export.NewStructurer(result.ColumnTypes, "")
+	if structurer == nil || len(result.ColumnTypes) != len(result.Columns) {
+		return
+	}
+	values := make([]interface{}, len(result.Columns))
+	for _, row := range result.Rows {
+		for i, col := range result.Columns {
+			values[i] = row[col]
+		}
+		structurer.Apply(values)
+		for i, col := range result.Columns {
+			row[col] = values[i]
+		}
+	}
+}
+
+// metadataFor extracts execution metadata from a query result
+func metadataFor(result *trino.QueryResult) resultMetadata {
+	return resultMetadata{
+		QueryID:          result.QueryID,
+		InfoURI:          result.InfoURI,
+		Stats:            result.Stats,
+		RowCount:         result.RowCount,
+		Truncated:        result.Truncated,
+		TruncationReason: result.TruncationReason,
+		RowsScanned:      result.RowsScanned,
+	}
+}
+
+// provenanceMetaKey is the _meta field of query results that holds their provenance
+const provenanceMetaKey = "provenance"
+
+// withProvenance attaches where a query result came from to a tool result as
+// _meta, outside the content the model reads
+func withProvenance(result *mcp.CallToolResult, provenance *trino.Provenance) *mcp.CallToolResult {
+	result.Meta = &mcp.Meta{AdditionalFields: map[string]any{provenanceMetaKey: provenance}}
+	return result
+}
+
+// formatDelimited renders rows as RFC 4180 CSV with a header line
+func formatDelimited(result *trino.QueryResult, delimiter rune) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = delimiter
+
+	if err := w.Write(result.Columns); err != nil {
+		return "", err
+	}
+	record := make([]string, len(result.Columns))
+	for _, row := range result.Rows {
+		for i, col := range result.Columns {
+			record[i] = export.FormatValue(row[col])
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// formatTSVRows renders rows as tab-separated values, escaping tabs and newlines
+func formatTSVRows(result *trino.QueryResult) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+	var sb strings.Builder
+	for i, col := range result.Columns {
+		if i > 0 {
+			sb.WriteByte('\t')
+		}
+		sb.WriteString(replacer.Replace(col))
+	}
+	sb.WriteByte('\n')
+	for _, row := range result.Rows {
+		for i, col := range result.Columns {
+			if i > 0 {
+				sb.WriteByte('\t')
+			}
+			sb.WriteString(replacer.Replace(export.FormatValue(row[col])))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// formatMarkdownTable renders rows as a GitHub-flavored Markdown table
+func formatMarkdownTable(result *trino.QueryResult) string {
+	replacer := strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+	var sb strings.Builder
+	sb.WriteString("|")
+	for _, col := range result.Columns {
+		sb.WriteString(" " + replacer.Replace(col) + " |")
+	}
+	sb.WriteString("\n|")
+	for range result.Columns {
+		sb.WriteString(" --- |")
+	}
+	sb.WriteString("\n")
+	for _, row := range result.Rows {
+		sb.WriteString("|")
+		for _, col := range result.Columns {
+			sb.WriteString(" " + replacer.Replace(export.FormatValue(row[col])) + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	if result.Truncated {
+		fmt.Fprintf(&sb, "\n_Results truncated after %d rows (%s)._\n", result.RowCount, result.TruncationReason)
+	}
+	return sb.String()
+}
@@ -0,0 +1,321 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func sampleResult() *trino.QueryResult {
+	return &trino.QueryResult{
+		RowCount: 2,
+		Columns:  []string{"name", "note", "amount"},
+		Rows: []map[string]interface{}{
+			{"name": "alice", "note": "a|b, \"c\"", "amount": int64(10)},
+			{"name": "bob", "note": nil, "amount": 2.5},
+		},
+	}
+}
+
+func TestNormalizeFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{input: "", expected: formatJSON},
+		{input: "JSON", expected: formatJSON},
+		{input: "csv", expected: formatCSV},
+		{input: "tsv", expected: formatTSV},
+		{input: "md", expected: formatMarkdown},
+		{input: "markdown", expected: formatMarkdown},
+		{input: "arrow", expected: formatArrow},
+		{input: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := normalizeFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("normalizeFormat(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatResult(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   formatCSV,
+			expected: "name,note,amount\nalice,\"a|b, \"\"c\"\"\",10\nbob,NULL,2.5\n",
+		},
+		{
+			format:   formatTSV,
+			expected: "name\tnote\tamount\nalice\ta|b, \"c\"\t10\nbob\tNULL\t2.5\n",
+		},
+		{
+			format:   formatMarkdown,
+			expected: "| name | note | amount |\n| --- | --- | --- |\n| alice | a\\|b, \"c\" | 10 |\n| bob | NULL | 2.5 |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := formatResult(sampleResult(), tt.format)
+			if err != nil {
+				t.Fatalf("formatResult() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("formatResult(%s) =\n%q\nwant\n%q", tt.format, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatResultJSONKeepsNull(t *testing.T) {
+	got, err := formatResult(sampleResult(), formatJSON)
+	if err != nil {
+		t.Fatalf("formatResult() error = %v", err)
+	}
+	if !strings.Contains(got, `"note": null`) {
+		t.Errorf("JSON output should render NULL as null, got %s", got)
+	}
+}
+
+func TestFormatResultStructuresNestedTypes(t *testing.T) {
+	newResult := func() *trino.QueryResult {
+		return &trino.QueryResult{
+			RowCount:    1,
+			Columns:     []string{"item", "payload"},
+			ColumnTypes: []trino.ColumnInfo{{Name: "item", Type: "ROW(ID BIGINT, TAGS ARRAY(VARCHAR))"}, {Name: "payload", Type: "JSON"}},
+			Rows: []map[string]interface{}{
+				{"item": []interface{}{int64(1), []interface{}{"a"}}, "payload": `{"ok":true}`},
+			},
+		}
+	}
+
+	got, err := formatResult(newResult(), formatJSON)
+	if err != nil {
+		t.Fatalf("formatResult() error = %v", err)
+	}
+	for _, want := range []string{`"id": 1`, `"tags": [`, `"ok": true`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("JSON output should contain %s, got %s", want, got)
+		}
+	}
+
+	got, err = formatResult(newResult(), formatCSV)
+	if err != nil {
+		t.Fatalf("formatResult() error = %v", err)
+	}
+	if want := "item,payload\n\"{\"\"id\"\":1,\"\"tags\"\":[\"\"a\"\"]}\",\"{\"\"ok\"\":true}\"\n"; got != want {
+		t.Errorf("CSV output = %q, want %q", got, want)
+	}
+}
+
+func TestFormatMarkdownTruncationNote(t *testing.T) {
+	result := sampleResult()
+	result.Truncated = true
+	result.TruncationReason = "row limit of 2 reached"
+
+	got := formatMarkdownTable(result)
+	if !strings.Contains(got, "Results truncated after 2 rows") {
+		t.Errorf("expected truncation note in markdown output, got %s", got)
+	}
+}
+
+func TestFormatResultArrow(t *testing.T) {
+	result := sampleResult()
+	result.ColumnTypes = []trino.ColumnInfo{
+		{Name: "name", Type: "VARCHAR"},
+		{Name: "note", Type: "VARCHAR"},
+		{Name: "amount", Type: "DOUBLE"},
+	}
+	result.Rows[0]["amount"] = 10.0
+
+	got, err := formatResult(result, formatArrow)
+	if err != nil {
+		t.Fatalf("formatResult() error = %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(got)
+	if err != nil {
+		t.Fatalf("arrow output is not valid base64: %v", err)
+	}
+
+	reader, err := ipc.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read arrow stream: %v", err)
+	}
+	defer reader.Release()
+	if !reader.Next() {
+		t.Fatalf("expected a record batch, err = %v", reader.Err())
+	}
+	rec := reader.Record()
+	if rec.NumRows() != 2 || rec.NumCols() != 3 {
+		t.Fatalf("record shape = %dx%d, want 2x3", rec.NumRows(), rec.NumCols())
+	}
+	if !rec.Column(1).IsNull(1) {
+		t.Error("NULL note should stay null in arrow output")
+	}
+	if got := rec.Column(2).(*array.Float64).Value(1); got != 2.5 {
+		t.Errorf("amount[1] = %v, want 2.5", got)
+	}
+}
+
+// encodeStreamed feeds the rows of a result through a resultEncoder
+func encodeStreamed(t *testing.T, encoder *resultEncoder, result *trino.QueryResult) string {
+	t.Helper()
+	if err := encoder.Begin(result.ColumnTypes); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	values := make([]interface{}, len(result.Columns))
+	for _, row := range result.Rows {
+		for i, col := range result.Columns {
+			values[i] = row[col]
+		}
+		if err := encoder.WriteRow(values); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	got, err := encoder.render(result)
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	return got
+}
+
+func TestResultEncoderMatchesFormatResult(t *testing.T) {
+	full := sampleResult()
+	full.QueryID = "20240101_000000_00000_abcde"
+	full.Truncated = true
+	full.TruncationReason = "row limit of 2 reached"
+	full.Rows[0]["amount"] = 10.0
+	empty := sampleResult()
+	empty.RowCount = 0
+	empty.Rows = []map[string]interface{}{}
+
+	for _, result := range []*trino.QueryResult{full, empty} {
+		result.ColumnTypes = []trino.ColumnInfo{
+			{Name: "name", Type: "VARCHAR"},
+			{Name: "note", Type: "VARCHAR"},
+			{Name: "amount", Type: "DOUBLE"},
+		}
+		for _, format := range []string{formatJSON, formatCSV, formatTSV, formatMarkdown, formatArrow} {
+			want, err := formatResult(result, format)
+			if err != nil {
+				t.Fatalf("formatResult() error = %v", err)
+			}
+			for _, spillBytes := range []int64{0, 16} {
+				encoder := newResultEncoder(format, spillBytes, nil, valueEncoding{})
+				got := encodeStreamed(t, encoder, result)
+				if got != want {
+					t.Errorf("%s with %d rows, spill %d =\n%q\nwant\n%q", format, result.RowCount, spillBytes, got, want)
+				}
+				if spillBytes == 0 && encoder.out.Spilled() {
+					t.Errorf("%s: output spilled although spilling is disabled", format)
+				}
+				_ = encoder.Close()
+			}
+		}
+	}
+}
+
+func TestResultEncoderReset(t *testing.T) {
+	result := sampleResult()
+	result.ColumnTypes = []trino.ColumnInfo{{Name: "name"}, {Name: "note"}, {Name: "amount"}}
+	encoder := newResultEncoder(formatCSV, 0, nil, valueEncoding{})
+	defer func() { _ = encoder.Close() }()
+
+	// Rows of an attempt that failed are discarded before the query is replayed
+	if err := encoder.Begin(result.ColumnTypes); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if err := encoder.WriteRow([]interface{}{"carol", nil, int64(1)}); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if err := encoder.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+
+	got := encodeStreamed(t, encoder, result)
+	want := "name,note,amount\nalice,\"a|b, \"\"c\"\"\",10\nbob,NULL,2.5\n"
+	if got != want {
+		t.Errorf("output after Reset() = %q, want %q", got, want)
+	}
+}
+
+func TestResultEncoderValueEncoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		values valueEncoding
+		want   string
+	}{
+		{"null in text", formatCSV, valueEncoding{null: config.NullValueNull}, "name,note,amount\nalice,\"a|b, \"\"c\"\"\",10\nbob,NULL,2.5\n"},
+		{"empty null", formatCSV, valueEncoding{null: config.NullValueEmpty}, "name,note,amount\nalice,\"a|b, \"\"c\"\"\",10\nbob,,2.5\n"},
+		{"sentinel null", formatTSV, valueEncoding{null: "\\N"}, "name\tnote\tamount\nalice\ta|b, \"c\"\t10\nbob\t\\\\N\t2.5\n"},
+		{"truncated", formatMarkdown, valueEncoding{maxChars: 3}, "| name | note | amount |\n| --- | --- | --- |\n| ali… | a\\|b… | 10 |\n| bob | NULL | 2.5 |\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := sampleResult()
+			result.ColumnTypes = []trino.ColumnInfo{{Name: "name"}, {Name: "note"}, {Name: "amount"}}
+			encoder := newResultEncoder(tt.format, 0, nil, tt.values)
+			defer func() { _ = encoder.Close() }()
+			if got := encodeStreamed(t, encoder, result); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultEncoderValueEncodingJSON(t *testing.T) {
+	result := sampleResult()
+	result.ColumnTypes = []trino.ColumnInfo{{Name: "name"}, {Name: "note"}, {Name: "amount"}}
+	encoder := newResultEncoder(formatJSON, 0, nil, valueEncoding{null: "N/A", maxChars: 2})
+	defer func() { _ = encoder.Close() }()
+
+	got := encodeStreamed(t, encoder, result)
+	for _, want := range []string{`"name": "al…"`, `"note": "N/A"`, `"amount": 10`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %s:\n%s", want, got)
+		}
+	}
+}
+
+func TestWithProvenance(t *testing.T) {
+	provenance := &trino.Provenance{Cluster: "analytics", QueryID: "20240131_120000_00001_abcde", RowCount: 2}
+	result := withProvenance(mcp.NewToolResultText("[]"), provenance)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded struct {
+		Meta struct {
+			Provenance trino.Provenance `json:"provenance"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got := decoded.Meta.Provenance; got.Cluster != "analytics" || got.QueryID != provenance.QueryID || got.RowCount != 2 {
+		t.Errorf("_meta.provenance = %+v, want %+v", got, provenance)
+	}
+}
@@ -2,128 +2,246 @@ package mcp
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/tuannvm/mcp-trino/internal/config"
-	oauth "github.com/tuannvm/oauth-mcp-proxy"
+	"github.com/tuannvm/mcp-trino/internal/datacatalog"
+	"github.com/tuannvm/mcp-trino/internal/dbt"
+	"github.com/tuannvm/mcp-trino/internal/export"
 	"github.com/tuannvm/mcp-trino/internal/trino"
 )
 
 // TrinoHandlers contains all handlers for Trino-related tools
 type TrinoHandlers struct {
-	TrinoClient *trino.Client
-	Config      *config.TrinoConfig
+	Clusters *trino.Clusters
+	Config   *config.TrinoConfig
+
+	budget      *memoryBudget        // Memory execute_query results may buffer across concurrent calls
+	manifest    *dbt.Loader          // dbt manifest of the model tools (nil when TRINO_DBT_MANIFEST is unset)
+	dataCatalog *datacatalog.Catalog // Metadata merged into schema output (nil when TRINO_DATA_CATALOG is unset)
+	quota       *quotaStore          // Daily query budgets, shared with the Server that reloads them
+	idempotency *idempotencyCache    // execute_query results kept for retries with the same idempotency key
+	inFlight    *queryCoalescer      // Read-only execute_query calls running, shared by identical calls
+	reload      func() error         // Reloads the configuration as SIGHUP does, for admin_reload_config
 }
 
 // NewTrinoHandlers creates a new set of Trino handlers
-func NewTrinoHandlers(client *trino.Client, cfg *config.TrinoConfig) *TrinoHandlers {
+func NewTrinoHandlers(clusters *trino.Clusters, cfg *config.TrinoConfig) *TrinoHandlers {
 	return &TrinoHandlers{
-		TrinoClient: client,
+		Clusters:    clusters,
 		Config:      cfg,
+		budget:      newMemoryBudget(cfg.ResultMemoryBudget),
+		manifest:    dbt.NewLoader(cfg.DBTManifest),
+		dataCatalog: datacatalog.New(cfg),
+		idempotency: newIdempotencyCache(),
+		inFlight:    newQueryCoalescer(),
 	}
 }
 
-// prepareImpersonationContext adds impersonated user to context
-func (h *TrinoHandlers) prepareImpersonationContext(ctx context.Context) context.Context {
-	if user, ok := oauth.GetUserFromContext(ctx); ok {
-		var principal string
-		switch h.Config.ImpersonationField {
-		case "email":
-			principal = user.Email
-		case "subject":
-			principal = user.Subject
-		case "username":
-			fallthrough
-		default:
-			principal = user.Username
-		}
-
-		if principal != "" {
-			return trino.WithImpersonatedUser(ctx, principal)
-		} else {
-			log.Printf("Warning: Impersonation enabled but %s field is empty for user", h.Config.ImpersonationField)
+// clusterName returns the optional cluster argument; empty lets the routing policy choose
+func clusterName(request mcp.CallToolRequest) string {
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if clusterParam, ok := args["cluster"].(string); ok {
+			return clusterParam
 		}
 	}
-	return ctx
+	return ""
 }
 
-// ExecuteQuery handles query execution
+// ExecuteQuery handles query execution. Calls with an idempotency key run
+// once; retries with the key get the original result.
 func (h *TrinoHandlers) ExecuteQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-    if h.Config.EnableImpersonation {
-        ctx = h.prepareImpersonationContext(ctx)
-    }
+	key, err := idempotencyKey(request)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if key != "" {
+		return h.idempotency.do(ctx, clientKey(ctx), key, request, h.coalesceQuery)
+	}
+	return h.coalesceQuery(ctx, request)
+}
 
+// coalesceQuery runs identical read-only execute_query calls of a client that
+// overlap in time as a single Trino query. Calls are identical when all their
+// arguments are, so the format, parameters and session settings match too.
+func (h *TrinoHandlers) coalesceQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, _ := request.Params.Arguments.(map[string]interface{})
+	query, _ := args["query"].(string)
+	if !trino.IsReadOnlyQuery(query) {
+		return h.executeQuery(ctx, request)
+	}
+	return h.inFlight.do(ctx, clientKey(ctx)+"\x00"+fingerprint(request), request, h.executeQuery)
+}
+
+// executeQuery runs the query of an execute_query call
+func (h *TrinoHandlers) executeQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Type assert Arguments to map[string]interface{}
 	args, ok := request.Params.Arguments.(map[string]interface{})
 	if !ok {
 		mcpErr := fmt.Errorf("invalid arguments format")
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
 	// Extract the query parameter
 	query, ok := args["query"].(string)
 	if !ok {
 		mcpErr := fmt.Errorf("query parameter must be a string")
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
+	}
+
+	// Extract optional output format
+	var formatParam string
+	if f, ok := args["format"].(string); ok {
+		formatParam = f
+	}
+	format, err := normalizeFormat(formatParam)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	// Extract optional placeholder values
+	var params []interface{}
+	if rawParams, ok := args["params"]; ok && rawParams != nil {
+		list, ok := rawParams.([]interface{})
+		if !ok {
+			mcpErr := fmt.Errorf("params parameter must be an array")
+			return toolError(mcpErr), nil
+		}
+		if params, err = queryParams(list); err != nil {
+			return toolError(err), nil
+		}
+	}
+
+	// Read Iceberg/Delta tables at a historical version when requested
+	travel, err := timeTravelParams(args)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if query, err = trino.ApplyTimeTravel(query, travel); err != nil {
+		return toolError(err), nil
+	}
+
+	// Label the query for resource group routing when the caller asks to
+	labels, err := queryLabelParams(args)
+	if err != nil {
+		return toolError(err), nil
+	}
+	ctx = trino.WithQueryLabels(ctx, labels)
+
+	// Run the query in another session time zone when the caller asks to
+	timeZone, err := timeZoneParam(args)
+	if err != nil {
+		return toolError(err), nil
+	}
+	ctx = trino.WithSessionTimeZone(ctx, timeZone)
+
+	// Give the query more or less time than TRINO_QUERY_TIMEOUT when the caller asks to
+	timeout, err := timeoutParam(args, h.Config.MaxQueryTimeout)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if timeout > 0 {
+		ctx = trino.WithQueryTimeout(ctx, timeout)
 	}
 
 	// Execute the query - SQL injection protection is handled within the client
-	results, err := h.TrinoClient.ExecuteQueryWithContext(ctx, query)
+	// Only read-only queries are retried on another cluster when one is unreachable
+	// Rows are encoded as they arrive rather than collected, spilling to a temp file beyond TRINO_RESULT_SPILL_BYTES
+	encoder := newResultEncoder(format, h.Config.ResultSpillBytes, h.budget, newValueEncoding(h.Config))
+	defer func() { _ = encoder.Close() }()
+	var served *trino.Cluster
+	started := time.Now()
+	results, err := trino.Route(ctx, h.Clusters, clusterName(request), trino.IsReadOnlyQuery(query),
+		func(cluster *trino.Cluster) (*trino.QueryResult, error) {
+			if err := encoder.Reset(); err != nil {
+				return nil, err
+			}
+			served = cluster
+			return cluster.Client.StreamQueryWithResult(ctx, query, encoder, params...)
+		})
 	if err != nil {
 		log.Printf("Error executing query: %v", err)
 		mcpErr := fmt.Errorf("query execution failed: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
+	}
+	if encoder.out.Spilled() {
+		log.Printf("INFO: Spilled %d bytes of query results to a temp file", encoder.out.Len())
 	}
 
-	// Convert results to JSON string for display
-	jsonData, err := json.MarshalIndent(results, "", "  ")
+	// Render results in the requested format
+	output, err := encoder.render(results)
 	if err != nil {
-		mcpErr := fmt.Errorf("failed to marshal results to JSON: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		mcpErr := fmt.Errorf("failed to format results as %s: %w", format, err)
+		return toolError(mcpErr), nil
+	}
+	provenance := served.Provenance(ctx, query, results, started)
+	provenance.ResultSHA256 = fmt.Sprintf("%x", sha256.Sum256([]byte(output)))
+	if format == formatJSON {
+		return withProvenance(mcp.NewToolResultText(output), provenance), nil
 	}
 
-	// Return the results as formatted JSON text
-	return mcp.NewToolResultText(string(jsonData)), nil
+	// Non-JSON formats carry execution metadata in a separate content block
+	// so the data block stays clean for export
+	metadata, err := json.MarshalIndent(metadataFor(results), "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal result metadata to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return withProvenance(&mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewTextContent(output),
+			mcp.NewTextContent(string(metadata)),
+		},
+	}, provenance), nil
 }
 
 // ListCatalogs handles catalog listing
 func (h *TrinoHandlers) ListCatalogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if h.Config.EnableImpersonation {
-		ctx = h.prepareImpersonationContext(ctx)
-	}
-
-	catalogs, err := h.TrinoClient.ListCatalogsWithContext(ctx)
+	catalogs, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) ([]string, error) {
+		return cluster.Client.ListCatalogsWithContext(ctx)
+	})
 	if err != nil {
 		log.Printf("Error listing catalogs: %v", err)
 		mcpErr := fmt.Errorf("failed to list catalogs: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
 	// Convert catalogs to JSON string for display
 	jsonData, err := json.MarshalIndent(catalogs, "", "  ")
 	if err != nil {
 		mcpErr := fmt.Errorf("failed to marshal catalogs to JSON: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
+// schemaPage is the list_schemas output with a limit or page_token
+type schemaPage struct {
+	Schemas       []string `json:"schemas"`
+	NextPageToken string   `json:"nextPageToken,omitempty"` // Omitted on the last page
+}
+
+// tablePage is the list_tables output with a limit or page_token
+type tablePage struct {
+	Tables        interface{} `json:"tables"` // Names, or listedTable objects
+	NextPageToken string      `json:"nextPageToken,omitempty"`
+}
+
 // ListSchemas handles schema listing
 func (h *TrinoHandlers) ListSchemas(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if h.Config.EnableImpersonation {
-		ctx = h.prepareImpersonationContext(ctx)
-	}
-
 	// Type assert Arguments to map[string]interface{}
 	args, ok := request.Params.Arguments.(map[string]interface{})
 	if !ok {
 		mcpErr := fmt.Errorf("invalid arguments format")
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
 	// Extract catalog parameter (optional)
@@ -132,18 +250,35 @@ func (h *TrinoHandlers) ListSchemas(ctx context.Context, request mcp.CallToolReq
 		catalog = catalogParam
 	}
 
-	schemas, err := h.TrinoClient.ListSchemasWithContext(ctx, catalog)
+	page, err := listPageParams(args)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	schemas, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) (*trino.NamePage, error) {
+		if page.Paged() {
+			return cluster.Client.ListSchemasPageWithContext(ctx, catalog, page)
+		}
+		names, err := cluster.Client.ListSchemasWithContext(ctx, catalog)
+		return &trino.NamePage{Names: names}, err
+	})
 	if err != nil {
 		log.Printf("Error listing schemas: %v", err)
 		mcpErr := fmt.Errorf("failed to list schemas: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
+	}
+
+	// Paginated listings also return the token of the next page
+	var output interface{} = schemas.Names
+	if page.Limit > 0 || page.PageToken != "" {
+		output = schemaPage{Schemas: schemas.Names, NextPageToken: schemas.NextPageToken}
 	}
 
 	// Convert schemas to JSON string for display
-	jsonData, err := json.MarshalIndent(schemas, "", "  ")
+	jsonData, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		mcpErr := fmt.Errorf("failed to marshal schemas to JSON: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
 	return mcp.NewToolResultText(string(jsonData)), nil
@@ -151,15 +286,11 @@ func (h *TrinoHandlers) ListSchemas(ctx context.Context, request mcp.CallToolReq
 
 // ListTables handles table listing
 func (h *TrinoHandlers) ListTables(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if h.Config.EnableImpersonation {
-		ctx = h.prepareImpersonationContext(ctx)
-	}
-
 	// Type assert Arguments to map[string]interface{}
 	args, ok := request.Params.Arguments.(map[string]interface{})
 	if !ok {
 		mcpErr := fmt.Errorf("invalid arguments format")
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
 	// Extract catalog and schema parameters (optional)
@@ -171,18 +302,62 @@ func (h *TrinoHandlers) ListTables(ctx context.Context, request mcp.CallToolRequ
 		schema = schemaParam
 	}
 
-	tables, err := h.TrinoClient.ListTablesWithContext(ctx, catalog, schema)
+	includeComments, _ := args["include_comments"].(bool)
+	page, err := listPageParams(args)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	var ref trino.TableRef
+	var comments map[string]string
+	var nextPageToken string
+	tables, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) ([]string, error) {
+		ref = cluster.Client.ResolveTable(catalog, schema, "")
+		var tables []string
+		var err error
+		if page.Paged() {
+			var names *trino.NamePage
+			if names, err = cluster.Client.ListTablesPageWithContext(ctx, catalog, schema, page); err == nil {
+				tables, nextPageToken = names.Names, names.NextPageToken
+			}
+		} else {
+			tables, err = cluster.Client.ListTablesWithContext(ctx, catalog, schema)
+		}
+		if err != nil || !includeComments {
+			return tables, err
+		}
+		// Comments are documentation only; list the tables without them on failure
+		if comments, err = cluster.Client.TableCommentsWithContext(ctx, catalog, schema); err != nil {
+			log.Printf("WARNING: Failed to read table comments of %s.%s: %v", ref.Catalog, ref.Schema, err)
+		}
+		return tables, nil
+	})
 	if err != nil {
 		log.Printf("Error listing tables: %v", err)
 		mcpErr := fmt.Errorf("failed to list tables: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
+	}
+
+	// Add comments and descriptions, owners and tags from the data catalog
+	var output interface{} = tables
+	if includeComments || h.dataCatalog != nil {
+		var metadata map[string]*datacatalog.TableMetadata
+		if h.dataCatalog != nil {
+			metadata = h.dataCatalog.Tables(ctx, ref.Catalog, ref.Schema, tables)
+		}
+		output = listTables(tables, comments, metadata)
+	}
+
+	// Paginated listings also return the token of the next page
+	if page.Limit > 0 || page.PageToken != "" {
+		output = tablePage{Tables: output, NextPageToken: nextPageToken}
 	}
 
 	// Convert tables to JSON string for display
-	jsonData, err := json.MarshalIndent(tables, "", "  ")
+	jsonData, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		mcpErr := fmt.Errorf("failed to marshal tables to JSON: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
 	return mcp.NewToolResultText(string(jsonData)), nil
@@ -190,15 +365,11 @@ func (h *TrinoHandlers) ListTables(ctx context.Context, request mcp.CallToolRequ
 
 // GetTableSchema handles table schema retrieval
 func (h *TrinoHandlers) GetTableSchema(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if h.Config.EnableImpersonation {
-		ctx = h.prepareImpersonationContext(ctx)
-	}
-
 	// Type assert Arguments to map[string]interface{}
 	args, ok := request.Params.Arguments.(map[string]interface{})
 	if !ok {
 		mcpErr := fmt.Errorf("invalid arguments format")
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
 	// Extract parameters
@@ -216,118 +387,2072 @@ func (h *TrinoHandlers) GetTableSchema(ctx context.Context, request mcp.CallTool
 	tableParam, ok := args["table"].(string)
 	if !ok {
 		mcpErr := fmt.Errorf("table parameter is required")
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 	table = tableParam
 
-	tableSchema, err := h.TrinoClient.GetTableSchemaWithContext(ctx, catalog, schema, table)
+	// Optional Iceberg/Delta version to describe
+	travel, err := timeTravelParams(args)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	var ref trino.TableRef
+	var comment string
+	tableSchema, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) ([]map[string]interface{}, error) {
+			ref = cluster.Client.ResolveTable(catalog, schema, table)
+			columns, err := cluster.Client.GetTableSchemaAtWithContext(ctx, catalog, schema, table, travel)
+			if err != nil {
+				return nil, err
+			}
+			// The comment is documentation only; describe the table without it on failure
+			if comment, err = cluster.Client.TableCommentWithContext(ctx, catalog, schema, table); err != nil {
+				log.Printf("WARNING: Failed to read the comment of table %s: %v", ref, err)
+			}
+			return columns, nil
+		})
 	if err != nil {
 		log.Printf("Error getting table schema: %v", err)
 		mcpErr := fmt.Errorf("failed to get table schema: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
+	}
+
+	// Add descriptions, owners and tags from the data catalog
+	var metadata *datacatalog.TableMetadata
+	if h.dataCatalog != nil {
+		metadata = h.dataCatalog.Table(ctx, ref.Catalog, ref.Schema, ref.Table)
 	}
 
 	// Convert table schema to JSON string for display
-	jsonData, err := json.MarshalIndent(tableSchema, "", "  ")
+	jsonData, err := json.MarshalIndent(describeTable(ref.String(), comment, tableSchema, metadata), "", "  ")
 	if err != nil {
 		mcpErr := fmt.Errorf("failed to marshal table schema to JSON: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
-// ExplainQuery handles query plan analysis
-func (h *TrinoHandlers) ExplainQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	if h.Config.EnableImpersonation {
-		ctx = h.prepareImpersonationContext(ctx)
+// GetTableSchemas handles describing several tables in one call
+func (h *TrinoHandlers) GetTableSchemas(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	var catalog, schema string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	tables, err := stringListParam(args, "tables")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	described, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) ([]trino.TableSchema, error) {
+		return cluster.Client.GetTableSchemasWithContext(ctx, catalog, schema, tables)
+	})
+	if err != nil {
+		log.Printf("Error getting table schemas: %v", err)
+		mcpErr := fmt.Errorf("failed to get table schemas: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	result := tableSchemas{Tables: []describedTable{}}
+	for _, table := range described {
+		if table.Err != nil {
+			if result.Errors == nil {
+				result.Errors = make(map[string]string)
+			}
+			result.Errors[table.Table.String()] = table.Err.Error()
+			continue
+		}
+		var metadata *datacatalog.TableMetadata
+		if h.dataCatalog != nil {
+			metadata = h.dataCatalog.Table(ctx, table.Table.Catalog, table.Table.Schema, table.Table.Table)
+		}
+		result.Tables = append(result.Tables, describeTable(table.Table.String(), "", table.Columns, metadata))
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal table schemas to JSON: %w", err)
+		return toolError(mcpErr), nil
 	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
 
+// GetTableDDL handles returning the CREATE statement of a table
+func (h *TrinoHandlers) GetTableDDL(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Type assert Arguments to map[string]interface{}
 	args, ok := request.Params.Arguments.(map[string]interface{})
 	if !ok {
 		mcpErr := fmt.Errorf("invalid arguments format")
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		return toolError(mcpErr), nil
 	}
 
-	// Extract the query parameter
-	query, ok := args["query"].(string)
+	var catalog, schema string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	table, ok := args["table"].(string)
 	if !ok {
-		mcpErr := fmt.Errorf("query parameter must be a string")
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		mcpErr := fmt.Errorf("table parameter is required")
+		return toolError(mcpErr), nil
 	}
 
-	// Extract optional format parameter
-	var format string
-	if formatParam, ok := args["format"].(string); ok {
-		format = formatParam
+	ddl, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) (string, error) {
+		return cluster.Client.GetTableDDLWithContext(ctx, catalog, schema, table)
+	})
+	if err != nil {
+		log.Printf("Error getting table DDL: %v", err)
+		mcpErr := fmt.Errorf("failed to get table DDL: %w", err)
+		return toolError(mcpErr), nil
 	}
 
-	// Execute the explain query
-	results, err := h.TrinoClient.ExplainQueryWithContext(ctx, query, format)
+	return mcp.NewToolResultText(ddl), nil
+}
+
+// SetComment handles setting the comment of a table or column
+func (h *TrinoHandlers) SetComment(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	var catalog, schema, column string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	if columnParam, ok := args["column"].(string); ok {
+		column = columnParam
+	}
+	table, ok := args["table"].(string)
+	if !ok || table == "" {
+		mcpErr := fmt.Errorf("table parameter is required")
+		return toolError(mcpErr), nil
+	}
+	comment, ok := args["comment"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("comment parameter is required (use an empty string to remove the comment)")
+		return toolError(mcpErr), nil
+	}
+
+	// Not retried on another cluster: the statement is a write
+	statement, err := trino.Route(ctx, h.Clusters, clusterName(request), false, func(cluster *trino.Cluster) (string, error) {
+		return cluster.Client.SetCommentWithContext(ctx, catalog, schema, table, column, comment)
+	})
 	if err != nil {
-		log.Printf("Error explaining query: %v", err)
-		mcpErr := fmt.Errorf("query explanation failed: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		log.Printf("Error setting comment: %v", err)
+		mcpErr := fmt.Errorf("failed to set comment: %w", err)
+		return toolError(mcpErr), nil
 	}
 
-	// Convert results to JSON string for display
-	jsonData, err := json.MarshalIndent(results, "", "  ")
+	jsonData, err := json.MarshalIndent(map[string]string{"statement": statement}, "", "  ")
 	if err != nil {
-		mcpErr := fmt.Errorf("failed to marshal explanation results to JSON: %w", err)
-		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		mcpErr := fmt.Errorf("failed to marshal result to JSON: %w", err)
+		return toolError(mcpErr), nil
 	}
 
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
-// RegisterTrinoTools registers all Trino-related tools with the MCP server.
-// OAuth middleware is applied server-wide via WithToolHandlerMiddleware(),
-// so no per-tool middleware application needed.
-func RegisterTrinoTools(m *server.MCPServer, h *TrinoHandlers) {
+// RunTableMaintenance handles running an Iceberg maintenance procedure
+func (h *TrinoHandlers) RunTableMaintenance(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
 
-	m.AddTool(mcp.NewTool("execute_query",
-		mcp.WithDescription("Execute SQL queries on Trino's fast distributed query engine for big data analytics. By default, only read-only queries (SELECT, SHOW, DESCRIBE, EXPLAIN) are allowed for security. When TRINO_ALLOW_WRITE_QUERIES=true is set, supports all SQL statements including INSERT, UPDATE, DELETE, CREATE, DROP, and other DML/DDL operations. Perfect for complex analytics, aggregations, joins, and cross-system data exploration on large datasets."),
-		mcp.WithTitleAnnotation("Execute Query"),
-		mcp.WithDestructiveHintAnnotation(true),
-		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query to execute. By default read-only queries only; DML/DDL requires TRINO_ALLOW_WRITE_QUERIES=true")),
-	), h.ExecuteQuery)
+	var catalog, schema string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	table, ok := args["table"].(string)
+	if !ok || table == "" {
+		mcpErr := fmt.Errorf("table parameter is required")
+		return toolError(mcpErr), nil
+	}
 
-	m.AddTool(mcp.NewTool("list_catalogs",
-		mcp.WithDescription("Discover available Trino catalogs - each catalog represents a connector to different data systems (PostgreSQL, MySQL, S3, HDFS, Kafka, etc.). Catalogs are your entry point to querying data across heterogeneous systems in a single SQL query."),
-		mcp.WithTitleAnnotation("List Catalogs"),
-		mcp.WithReadOnlyHintAnnotation(true)),
-		h.ListCatalogs)
+	// Dry run unless explicitly disabled
+	opts := trino.TableMaintenanceOptions{DryRun: true}
+	if opts.Procedure, ok = args["procedure"].(string); !ok {
+		mcpErr := fmt.Errorf("procedure parameter is required")
+		return toolError(mcpErr), nil
+	}
+	if thresholdParam, ok := args["file_size_threshold_mb"].(float64); ok {
+		opts.FileSizeThresholdMiB = int(thresholdParam)
+	}
+	if retentionParam, ok := args["retention_days"].(float64); ok {
+		opts.RetentionDays = int(retentionParam)
+	}
+	if dryRunParam, ok := args["dry_run"].(bool); ok {
+		opts.DryRun = dryRunParam
+	}
 
-	m.AddTool(mcp.NewTool("list_schemas",
-		mcp.WithDescription("Browse schemas (databases/namespaces) within a Trino catalog. Each schema contains related tables and views. Use this to navigate the data hierarchy before querying specific datasets."),
-		mcp.WithTitleAnnotation("List Schemas"),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithString("catalog", mcp.Description("Trino catalog name (optional; defaults to server configuration if omitted)"))),
-		h.ListSchemas)
+	// A procedure run is a write and is not retried on another cluster
+	maintenance, err := trino.Route(ctx, h.Clusters, clusterName(request), opts.DryRun, func(cluster *trino.Cluster) (*trino.TableMaintenance, error) {
+		return cluster.Client.RunTableMaintenanceWithContext(ctx, catalog, schema, table, opts)
+	})
+	if err != nil {
+		log.Printf("Error running table maintenance: %v", err)
+		mcpErr := fmt.Errorf("failed to run table maintenance: %w", err)
+		return toolError(mcpErr), nil
+	}
 
-	m.AddTool(mcp.NewTool("list_tables",
-		mcp.WithDescription("Discover tables and views available for querying in Trino schemas. Essential for finding datasets to analyze. Can scope to specific catalog/schema or browse all available data across the distributed system."),
-		mcp.WithTitleAnnotation("List Tables"),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithString("catalog", mcp.Description("Trino catalog name (optional)")),
-		mcp.WithString("schema", mcp.Description("Schema name within catalog (optional)"))),
-		h.ListTables)
+	jsonData, err := json.MarshalIndent(maintenance, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal table maintenance to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
 
-	m.AddTool(mcp.NewTool("get_table_schema",
-		mcp.WithDescription("Inspect table structure and column metadata from Trino's distributed data sources. Shows column names, data types, nullability, and constraints. Critical for understanding data before writing analytical queries."),
-		mcp.WithTitleAnnotation("Get Table Schema"),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithString("catalog", mcp.Description("Trino catalog containing the table (optional)")),
-		mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
-		mcp.WithString("table", mcp.Required(), mcp.Description("Table name to inspect"))),
-		h.GetTableSchema)
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
 
-	m.AddTool(mcp.NewTool("explain_query",
-		mcp.WithDescription("Analyze Trino query execution plans without running expensive queries. Shows distributed execution stages, data movement between nodes, and resource estimates. Essential for query optimization and performance tuning."),
-		mcp.WithTitleAnnotation("Explain Query"),
-		mcp.WithReadOnlyHintAnnotation(true),
-		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query to analyze (SELECT, JOIN, aggregations, etc.)")),
-		mcp.WithString("format", mcp.Description("Plan type: LOGICAL, DISTRIBUTED, VALIDATE, or IO (optional)"))),
-		h.ExplainQuery)
+// CallProcedure handles calling an allowlisted connector procedure
+func (h *TrinoHandlers) CallProcedure(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	procedure, ok := args["procedure"].(string)
+	if !ok || procedure == "" {
+		mcpErr := fmt.Errorf("procedure parameter is required")
+		return toolError(mcpErr), nil
+	}
+	arguments, err := procedureArgumentsParam(args)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	// Not retried on another cluster: procedures usually modify data or metadata
+	statement, err := trino.Route(ctx, h.Clusters, clusterName(request), false, func(cluster *trino.Cluster) (string, error) {
+		return cluster.Client.CallProcedureWithContext(ctx, procedure, arguments)
+	})
+	if err != nil {
+		log.Printf("Error calling procedure: %v", err)
+		mcpErr := fmt.Errorf("failed to call procedure: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(map[string]string{"statement": statement}, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal result to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// DumpSchema handles describing every table of a catalog or schema at once
+func (h *TrinoHandlers) DumpSchema(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Catalog is required so a dump never spans every catalog
+	catalog, ok := args["catalog"].(string)
+	if !ok || catalog == "" {
+		mcpErr := fmt.Errorf("catalog parameter is required")
+		return toolError(mcpErr), nil
+	}
+	var schema string
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+
+	dump, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) (*trino.SchemaDump, error) {
+		return cluster.Client.DumpSchemaWithContext(ctx, catalog, schema)
+	})
+	if err != nil {
+		log.Printf("Error dumping schema: %v", err)
+		mcpErr := fmt.Errorf("failed to dump schema: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal schema dump to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// DiffSchemas handles comparing the columns of two tables or schemas
+func (h *TrinoHandlers) DiffSchemas(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	a, okA := args["a"].(string)
+	b, okB := args["b"].(string)
+	if !okA || !okB {
+		mcpErr := fmt.Errorf("a and b parameters are required")
+		return toolError(mcpErr), nil
+	}
+
+	diff, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) (*trino.SchemaDiff, error) {
+		return cluster.Client.DiffSchemasWithContext(ctx, a, b)
+	})
+	if err != nil {
+		log.Printf("Error comparing schemas: %v", err)
+		mcpErr := fmt.Errorf("failed to compare schemas: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal schema diff to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// DiffTables handles comparing the data of two tables
+func (h *TrinoHandlers) DiffTables(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	a, okA := args["a"].(string)
+	b, okB := args["b"].(string)
+	if !okA || !okB {
+		mcpErr := fmt.Errorf("a and b parameters are required")
+		return toolError(mcpErr), nil
+	}
+
+	var opts trino.DiffTablesOptions
+	var err error
+	if opts.KeyColumns, err = stringListParam(args, "key_columns"); err != nil {
+		return toolError(err), nil
+	}
+	if len(opts.KeyColumns) == 0 {
+		mcpErr := fmt.Errorf("key_columns parameter is required")
+		return toolError(mcpErr), nil
+	}
+	if opts.Columns, err = stringListParam(args, "columns"); err != nil {
+		return toolError(err), nil
+	}
+	opts.Where, _ = args["where"].(string)
+	opts.Mode, _ = args["mode"].(string)
+	if limitParam, ok := args["limit"].(float64); ok {
+		opts.Limit = int(limitParam)
+	}
+
+	diff, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) (*trino.TableDataDiff, error) {
+		return cluster.Client.DiffTablesWithContext(ctx, a, b, opts)
+	})
+	if err != nil {
+		log.Printf("Error comparing tables: %v", err)
+		mcpErr := fmt.Errorf("failed to compare tables: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal table diff to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// SuggestJoins handles proposing join keys between a table and the rest of its schema
+func (h *TrinoHandlers) SuggestJoins(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	var catalog, schema string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	table, ok := args["table"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("table parameter is required")
+		return toolError(mcpErr), nil
+	}
+
+	joins, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) (*trino.JoinSuggestions, error) {
+		return cluster.Client.SuggestJoinsWithContext(ctx, catalog, schema, table)
+	})
+	if err != nil {
+		log.Printf("Error suggesting joins: %v", err)
+		mcpErr := fmt.Errorf("failed to suggest joins: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(joins, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal join suggestions to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// ColumnDistribution handles summarizing the values of a column
+func (h *TrinoHandlers) ColumnDistribution(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	table, okTable := args["table"].(string)
+	column, okColumn := args["column"].(string)
+	if !okTable || !okColumn {
+		mcpErr := fmt.Errorf("table and column parameters are required")
+		return toolError(mcpErr), nil
+	}
+
+	var opts trino.DistributionOptions
+	opts.Kind, _ = args["kind"].(string)
+	opts.Where, _ = args["where"].(string)
+	if bucketsParam, ok := args["buckets"].(float64); ok {
+		opts.Buckets = int(bucketsParam)
+	}
+
+	dist, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) (*trino.ColumnDistribution, error) {
+		return cluster.Client.ColumnDistributionWithContext(ctx, table, column, opts)
+	})
+	if err != nil {
+		log.Printf("Error computing column distribution: %v", err)
+		mcpErr := fmt.Errorf("failed to compute column distribution: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(dist, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal column distribution to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// EstimateRowCount handles estimating the rows of a table
+func (h *TrinoHandlers) EstimateRowCount(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	table, ok := args["table"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("table parameter is required")
+		return toolError(mcpErr), nil
+	}
+
+	var opts trino.RowCountOptions
+	opts.Where, _ = args["where"].(string)
+	opts.SamplePercent, _ = args["sample_percent"].(float64)
+	opts.Exact, _ = args["exact"].(bool)
+
+	estimate, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) (*trino.RowCountEstimate, error) {
+		return cluster.Client.EstimateRowCountWithContext(ctx, table, opts)
+	})
+	if err != nil {
+		log.Printf("Error estimating row count: %v", err)
+		mcpErr := fmt.Errorf("failed to estimate row count: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(estimate, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal row count estimate to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// modelSummary is a list_models entry
+type modelSummary struct {
+	UniqueID     string   `json:"uniqueId"`
+	Name         string   `json:"name"`
+	Relation     string   `json:"relation,omitempty"`
+	Materialized string   `json:"materialized,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+// ListModels handles listing the dbt models of the manifest
+func (h *TrinoHandlers) ListModels(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+	search, _ := args["search"].(string)
+	limit := dbt.DefaultSearchLimit
+	if limitParam, ok := args["limit"].(float64); ok {
+		limit = int(limitParam)
+	}
+	if limit <= 0 || limit > dbt.MaxSearchLimit {
+		limit = dbt.MaxSearchLimit
+	}
+
+	manifest, err := h.manifest.Manifest(ctx)
+	if err != nil {
+		log.Printf("Error loading dbt manifest: %v", err)
+		return toolError(err), nil
+	}
+	cluster, err := h.Clusters.Get(clusterName(request))
+	if err != nil {
+		return toolError(err), nil
+	}
+	models := cluster.Client.VisibleModels(manifest.Search(search))
+
+	list := struct {
+		Project   string         `json:"project,omitempty"`
+		Count     int            `json:"count"`
+		Truncated bool           `json:"truncated,omitempty"`
+		Models    []modelSummary `json:"models"`
+	}{Project: manifest.ProjectName, Count: len(models), Models: []modelSummary{}}
+	for i, model := range models {
+		if i == limit {
+			list.Truncated = true
+			break
+		}
+		description, _, _ := strings.Cut(model.Description, "\n")
+		list.Models = append(list.Models, modelSummary{
+			UniqueID:     model.UniqueID,
+			Name:         model.Name,
+			Relation:     model.Relation(),
+			Materialized: model.Materialized,
+			Description:  strings.TrimSpace(description),
+			Tags:         model.Tags,
+		})
+	}
+
+	jsonData, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal models to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// GetModel handles describing a dbt model and its Trino table
+func (h *TrinoHandlers) GetModel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	name, ok := args["model_name"].(string)
+	if !ok || name == "" {
+		mcpErr := fmt.Errorf("model_name parameter is required")
+		return toolError(mcpErr), nil
+	}
+
+	manifest, err := h.manifest.Manifest(ctx)
+	if err != nil {
+		log.Printf("Error loading dbt manifest: %v", err)
+		return toolError(err), nil
+	}
+	model, err := manifest.Find(name)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	described, err := trino.Route(ctx, h.Clusters, clusterName(request), true, func(cluster *trino.Cluster) (*trino.ModelTable, error) {
+		return cluster.Client.DescribeModelWithContext(ctx, model)
+	})
+	if err != nil {
+		log.Printf("Error describing dbt model: %v", err)
+		mcpErr := fmt.Errorf("failed to describe model: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(described, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal model to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// PreviewTable handles returning sample rows of a table
+func (h *TrinoHandlers) PreviewTable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract parameters
+	var catalog, schema, sampling string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	if samplingParam, ok := args["sampling"].(string); ok {
+		sampling = strings.ToLower(samplingParam)
+	}
+	var rows int
+	if rowsParam, ok := args["rows"].(float64); ok {
+		rows = int(rowsParam)
+	}
+	var percentage float64
+	if percentageParam, ok := args["percentage"].(float64); ok {
+		percentage = percentageParam
+	}
+
+	// Table parameter is required
+	table, ok := args["table"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("table parameter is required")
+		return toolError(mcpErr), nil
+	}
+
+	preview, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) (*trino.TablePreview, error) {
+			return cluster.Client.PreviewTableWithContext(ctx, catalog, schema, table, rows, sampling, percentage)
+		})
+	if err != nil {
+		log.Printf("Error previewing table: %v", err)
+		mcpErr := fmt.Errorf("failed to preview table: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert preview to JSON string for display
+	jsonData, err := json.MarshalIndent(preview, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal table preview to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// GetIcebergMetadata handles summarizing the metadata tables of an Iceberg table
+func (h *TrinoHandlers) GetIcebergMetadata(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract parameters
+	var catalog, schema string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	var limit int
+	if limitParam, ok := args["limit"].(float64); ok {
+		limit = int(limitParam)
+	}
+	var smallFileSize int64
+	if thresholdParam, ok := args["small_file_threshold_mb"].(float64); ok {
+		smallFileSize = int64(thresholdParam * (1 << 20))
+	}
+
+	// Table and metadata parameters are required
+	table, ok := args["table"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("table parameter is required")
+		return toolError(mcpErr), nil
+	}
+	metadata, ok := args["metadata"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("metadata parameter is required")
+		return toolError(mcpErr), nil
+	}
+
+	result, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) (*trino.IcebergMetadata, error) {
+			return cluster.Client.IcebergMetadataWithContext(ctx, catalog, schema, table, strings.ToLower(metadata), limit, smallFileSize)
+		})
+	if err != nil {
+		log.Printf("Error reading Iceberg metadata: %v", err)
+		mcpErr := fmt.Errorf("failed to get Iceberg metadata: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert metadata summary to JSON string for display
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal Iceberg metadata to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// GetDeltaHistory handles listing the commit history of a Delta Lake table
+func (h *TrinoHandlers) GetDeltaHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract parameters
+	var catalog, schema, operation string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	if operationParam, ok := args["operation"].(string); ok {
+		operation = strings.TrimSpace(operationParam)
+	}
+	var limit int
+	if limitParam, ok := args["limit"].(float64); ok {
+		limit = int(limitParam)
+	}
+	var sinceVersion *int64
+	if versionParam, ok := args["since_version"].(float64); ok {
+		if versionParam < 0 || versionParam != float64(int64(versionParam)) {
+			mcpErr := fmt.Errorf("invalid since_version %v: must be a non-negative integer", versionParam)
+			return toolError(mcpErr), nil
+		}
+		version := int64(versionParam)
+		sinceVersion = &version
+	}
+
+	// Table parameter is required
+	table, ok := args["table"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("table parameter is required")
+		return toolError(mcpErr), nil
+	}
+
+	result, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) (*trino.DeltaHistory, error) {
+			return cluster.Client.DeltaHistoryWithContext(ctx, catalog, schema, table, limit, sinceVersion, operation)
+		})
+	if err != nil {
+		log.Printf("Error reading Delta Lake history: %v", err)
+		mcpErr := fmt.Errorf("failed to get Delta Lake history: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert history to JSON string for display
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal Delta Lake history to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// ListPartitions handles listing the partitions of a Hive or Iceberg table
+func (h *TrinoHandlers) ListPartitions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract parameters
+	var catalog, schema, filter string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	if filterParam, ok := args["filter"].(string); ok {
+		filter = filterParam
+	}
+	var limit int
+	if limitParam, ok := args["limit"].(float64); ok {
+		limit = int(limitParam)
+	}
+
+	// Table parameter is required
+	table, ok := args["table"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("table parameter is required")
+		return toolError(mcpErr), nil
+	}
+
+	result, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) (*trino.PartitionListing, error) {
+			return cluster.Client.ListPartitionsWithContext(ctx, catalog, schema, table, filter, limit)
+		})
+	if err != nil {
+		log.Printf("Error listing partitions: %v", err)
+		mcpErr := fmt.Errorf("failed to list partitions: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert partition listing to JSON string for display
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal partitions to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// ExplainQuery handles query plan analysis
+func (h *TrinoHandlers) ExplainQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract the query parameter
+	query, ok := args["query"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("query parameter must be a string")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract optional format parameter
+	var format string
+	if formatParam, ok := args["format"].(string); ok {
+		format = formatParam
+	}
+
+	// A diagram is returned as-is so clients can render it
+	if diagram, ok := args["diagram"].(string); ok && diagram != "" {
+		rendered, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+			func(cluster *trino.Cluster) (string, error) {
+				return cluster.Client.ExplainDiagramWithContext(ctx, query, format, diagram)
+			})
+		if err != nil {
+			log.Printf("Error explaining query: %v", err)
+			mcpErr := fmt.Errorf("query explanation failed: %w", err)
+			return toolError(mcpErr), nil
+		}
+		return mcp.NewToolResultText(rendered), nil
+	}
+
+	// Execute the explain query
+	results, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) ([]map[string]interface{}, error) {
+			return cluster.Client.ExplainQueryWithContext(ctx, query, format)
+		})
+	if err != nil {
+		log.Printf("Error explaining query: %v", err)
+		mcpErr := fmt.Errorf("query explanation failed: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert results to JSON string for display
+	jsonData, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal explanation results to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// ValidateQuery handles checking a query without executing it
+func (h *TrinoHandlers) ValidateQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract the query parameter
+	query, ok := args["query"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("query parameter must be a string")
+		return toolError(mcpErr), nil
+	}
+
+	// An invalid query is a successful validation; only failures to validate are tool errors
+	validation, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) (*trino.Validation, error) {
+			return cluster.Client.ValidateQueryWithContext(ctx, query)
+		})
+	if err != nil {
+		log.Printf("Error validating query: %v", err)
+		mcpErr := fmt.Errorf("query validation failed: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert validation to JSON string for display
+	jsonData, err := json.MarshalIndent(validation, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal validation to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// LintQuery handles checking a query for common problems without executing it
+func (h *TrinoHandlers) LintQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract the query parameter
+	query, ok := args["query"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("query parameter must be a string")
+		return toolError(mcpErr), nil
+	}
+
+	// Table columns are looked up through the cluster, or taken from its metadata cache
+	lint, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) (*trino.QueryLint, error) {
+			return cluster.Client.LintQueryWithContext(ctx, query)
+		})
+	if err != nil {
+		log.Printf("Error linting query: %v", err)
+		mcpErr := fmt.Errorf("failed to lint query: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert findings to JSON string for display
+	jsonData, err := json.MarshalIndent(lint, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal lint findings to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// AnalyzeQueryLineage handles extracting the tables and columns a query reads
+// and writes. The query is parsed locally and never sent to Trino.
+func (h *TrinoHandlers) AnalyzeQueryLineage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract the query parameter
+	query, ok := args["query"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("query parameter must be a string")
+		return toolError(mcpErr), nil
+	}
+
+	// Unqualified tables resolve against the cluster's default catalog and schema
+	cluster, err := h.Clusters.Get(clusterName(request))
+	if err != nil {
+		return toolError(err), nil
+	}
+	catalog := cluster.Config.Catalog
+	if catalogParam, ok := args["catalog"].(string); ok && catalogParam != "" {
+		catalog = catalogParam
+	}
+	schema := cluster.Config.DefaultSchema(catalog)
+	if schemaParam, ok := args["schema"].(string); ok && schemaParam != "" {
+		schema = schemaParam
+	}
+
+	lineage := trino.AnalyzeLineage(query, catalog, schema)
+
+	// Convert lineage to JSON string for display
+	jsonData, err := json.MarshalIndent(lineage, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal query lineage to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// FormatSQL handles reformatting SQL canonically. The query is formatted
+// locally and never sent to Trino.
+func (h *TrinoHandlers) FormatSQL(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract the query parameter
+	query, ok := args["query"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("query parameter must be a string")
+		return toolError(mcpErr), nil
+	}
+	keywordCase, _ := args["keyword_case"].(string)
+
+	formatted, err := trino.FormatSQL(query, keywordCase)
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to format query: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(formatted), nil
+}
+
+// RenderQuery handles substituting typed parameters into a query template
+func (h *TrinoHandlers) RenderQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	text, ok := args["template"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("template parameter must be a string")
+		return toolError(mcpErr), nil
+	}
+	values := map[string]interface{}{}
+	if raw, ok := args["params"]; ok && raw != nil {
+		if values, ok = raw.(map[string]interface{}); !ok {
+			mcpErr := fmt.Errorf("params must be an object of parameter values")
+			return toolError(mcpErr), nil
+		}
+	}
+
+	tmpl, err := trino.ParseTemplate(text)
+	if err != nil {
+		mcpErr := fmt.Errorf("invalid template: %w", err)
+		return toolError(mcpErr), nil
+	}
+	query, err := tmpl.Render(values)
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to render template: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(map[string]interface{}{
+		"query":      query,
+		"parameters": tmpl.Params(),
+	}, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal rendered query to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// ListFunctions handles listing the functions available in Trino
+func (h *TrinoHandlers) ListFunctions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract optional parameters
+	var catalog, schema, pattern string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	if patternParam, ok := args["pattern"].(string); ok {
+		pattern = patternParam
+	}
+
+	functions, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) ([]trino.FunctionSummary, error) {
+			return cluster.Client.ListFunctionsWithContext(ctx, catalog, schema, pattern)
+		})
+	if err != nil {
+		log.Printf("Error listing functions: %v", err)
+		mcpErr := fmt.Errorf("failed to list functions: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert functions to JSON string for display
+	jsonData, err := json.MarshalIndent(functions, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal functions to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// DescribeFunction handles listing the signatures of a Trino function
+func (h *TrinoHandlers) DescribeFunction(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Name parameter is required
+	name, ok := args["name"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("name parameter is required")
+		return toolError(mcpErr), nil
+	}
+
+	signatures, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) ([]trino.FunctionSignature, error) {
+			return cluster.Client.DescribeFunctionWithContext(ctx, name)
+		})
+	if err != nil {
+		log.Printf("Error describing function: %v", err)
+		mcpErr := fmt.Errorf("failed to describe function: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert signatures to JSON string for display
+	jsonData, err := json.MarshalIndent(signatures, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal function signatures to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// GetResourceGroups handles reporting running and queued queries per resource group
+func (h *TrinoHandlers) GetResourceGroups(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	groups, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) (*trino.ResourceGroups, error) {
+			return cluster.Client.ResourceGroupsWithContext(ctx)
+		})
+	if err != nil {
+		log.Printf("Error reading resource groups: %v", err)
+		mcpErr := fmt.Errorf("failed to get resource groups: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert resource groups to JSON string for display
+	jsonData, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal resource groups to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// ShowGrants handles reporting the roles and table privileges of the effective Trino user
+func (h *TrinoHandlers) ShowGrants(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract optional parameters
+	var catalog, schema, table string
+	if catalogParam, ok := args["catalog"].(string); ok {
+		catalog = catalogParam
+	}
+	if schemaParam, ok := args["schema"].(string); ok {
+		schema = schemaParam
+	}
+	if tableParam, ok := args["table"].(string); ok {
+		table = tableParam
+	}
+
+	grants, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) (*trino.Grants, error) {
+			return cluster.Client.ShowGrantsWithContext(ctx, catalog, schema, table)
+		})
+	if err != nil {
+		log.Printf("Error showing grants: %v", err)
+		mcpErr := fmt.Errorf("failed to show grants: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert grants to JSON string for display
+	jsonData, err := json.MarshalIndent(grants, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal grants to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// FindQuery handles looking up recent queries by ID or text
+func (h *TrinoHandlers) FindQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract parameters; exactly one of query_id and text is required
+	var queryID, text string
+	if queryIDParam, ok := args["query_id"].(string); ok {
+		queryID = queryIDParam
+	}
+	if textParam, ok := args["text"].(string); ok {
+		text = textParam
+	}
+	var limit int
+	if limitParam, ok := args["limit"].(float64); ok {
+		limit = int(limitParam)
+	}
+
+	queries, err := trino.Route(ctx, h.Clusters, clusterName(request), true,
+		func(cluster *trino.Cluster) ([]trino.QueryStatus, error) {
+			return cluster.Client.FindQueriesWithContext(ctx, queryID, text, limit)
+		})
+	if err != nil {
+		log.Printf("Error finding queries: %v", err)
+		mcpErr := fmt.Errorf("failed to find queries: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	// Convert queries to JSON string for display
+	jsonData, err := json.MarshalIndent(queries, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal queries to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// clusterInfo describes a configured cluster without credentials
+type clusterInfo struct {
+	Name              string `json:"name"`
+	Description       string `json:"description,omitempty"`
+	Host              string `json:"host"`
+	Catalog           string `json:"catalog"`
+	Schema            string `json:"schema"`
+	AllowWriteQueries bool   `json:"allowWriteQueries"`
+	Default           bool   `json:"default"`
+
+	// Routing status, reported when a routing policy is configured
+	Healthy   *bool  `json:"healthy,omitempty"`
+	Circuit   string `json:"circuit,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+
+	// Warm-up and keepalive ping status, reported when pings are configured
+	LastPing  *time.Time `json:"lastPing,omitempty"`
+	PingError string     `json:"pingError,omitempty"`
+}
+
+// ListClusters handles listing the configured Trino clusters
+func (h *TrinoHandlers) ListClusters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	defaultCluster := h.Clusters.Default()
+	clusters := make([]clusterInfo, 0, len(h.Clusters.List()))
+	routed := h.Config.RoutingPolicy != "" && h.Config.RoutingPolicy != config.RoutingNone
+	pinged := h.Config.WarmUp || h.Config.KeepaliveInterval > 0
+	for _, cl := range h.Clusters.List() {
+		info := clusterInfo{
+			Name:              cl.Name,
+			Description:       cl.Description,
+			Host:              cl.Config.Host,
+			Catalog:           cl.Config.Catalog,
+			Schema:            cl.Config.Schema,
+			AllowWriteQueries: cl.Config.AllowWriteQueries,
+			Default:           cl == defaultCluster,
+		}
+		if routed {
+			status := cl.Status()
+			info.Healthy = &status.Healthy
+			info.Circuit = status.Circuit
+			info.LastError = status.LastError
+		}
+		if pinged {
+			status := cl.Status()
+			if !status.PingedAt.IsZero() {
+				info.LastPing = &status.PingedAt
+			}
+			info.PingError = status.PingError
+		}
+		clusters = append(clusters, info)
+	}
+
+	// Convert clusters to JSON string for display
+	jsonData, err := json.MarshalIndent(clusters, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal clusters to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// GetAuthURL handles reporting the Trino external authentication state of a
+// cluster, starting a login and returning its URL when there is no valid token
+func (h *TrinoHandlers) GetAuthURL(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := h.Clusters.Get(clusterName(request))
+	if err != nil {
+		return toolError(err), nil
+	}
+	status, err := cluster.Client.AuthStatus(ctx)
+	if err != nil {
+		log.Printf("Error getting auth URL: %v", err)
+		mcpErr := fmt.Errorf("failed to get auth URL for cluster %s: %w", cluster.Name, err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal auth status to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// Reauthenticate handles discarding a cluster's Trino token and starting a new
+// external authentication login
+func (h *TrinoHandlers) Reauthenticate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cluster, err := h.Clusters.Get(clusterName(request))
+	if err != nil {
+		return toolError(err), nil
+	}
+	status, err := cluster.Client.Reauthenticate(ctx)
+	if err != nil {
+		log.Printf("Error reauthenticating: %v", err)
+		mcpErr := fmt.Errorf("failed to reauthenticate to cluster %s: %w", cluster.Name, err)
+		return toolError(mcpErr), nil
+	}
+
+	jsonData, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal auth status to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// GetUsage handles reporting the caller's query usage today and the budget left
+func (h *TrinoHandlers) GetUsage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jsonData, err := json.MarshalIndent(h.quota.report(clientKey(ctx)), "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal usage to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// exportResult describes a completed export_query call
+type exportResult struct {
+	Location string            `json:"location"`
+	Format   string            `json:"format"`
+	RowCount int               `json:"rowCount"`
+	Bytes    int64             `json:"bytes"`
+	QueryID  string            `json:"queryId,omitempty"`
+	InfoURI  string            `json:"infoUri,omitempty"`
+	Stats    *trino.QueryStats `json:"stats,omitempty"`
+}
+
+// ExportQuery handles streaming a full result set to a file or object storage
+func (h *TrinoHandlers) ExportQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Type assert Arguments to map[string]interface{}
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		mcpErr := fmt.Errorf("invalid arguments format")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract the query parameter
+	query, ok := args["query"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("query parameter must be a string")
+		return toolError(mcpErr), nil
+	}
+
+	// Extract optional format and destination
+	var formatParam, destination string
+	if f, ok := args["format"].(string); ok {
+		formatParam = f
+	}
+	if d, ok := args["destination"].(string); ok {
+		destination = d
+	}
+	format, err := export.NormalizeFormat(formatParam)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	// Label the query for resource group routing when the caller asks to
+	labels, err := queryLabelParams(args)
+	if err != nil {
+		return toolError(err), nil
+	}
+	ctx = trino.WithQueryLabels(ctx, labels)
+
+	// Run the query in another session time zone when the caller asks to
+	timeZone, err := timeZoneParam(args)
+	if err != nil {
+		return toolError(err), nil
+	}
+	ctx = trino.WithSessionTimeZone(ctx, timeZone)
+
+	target, err := export.Open(destination, format, export.Options{
+		Dir:                h.Config.ExportDir,
+		AllowedURIs:        h.Config.ExportAllowedURIs,
+		GCSAccessKeyID:     h.Config.ExportGCSKeyID,
+		GCSSecretAccessKey: h.Config.ExportGCSSecret,
+	})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	writer, err := export.NewWriter(format, target.File)
+	if err != nil {
+		target.Abort()
+		return toolError(err), nil
+	}
+
+	// Stream rows straight to the file - SQL injection protection is handled within the client.
+	// Rows may already be written when a cluster fails, so exports are never retried elsewhere.
+	var served *trino.Cluster
+	started := time.Now()
+	results, err := trino.Route(ctx, h.Clusters, clusterName(request), false,
+		func(cluster *trino.Cluster) (*trino.QueryResult, error) {
+			served = cluster
+			return cluster.Client.StreamQueryWithContext(ctx, query, writer)
+		})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		target.Abort()
+		log.Printf("Error exporting query: %v", err)
+		mcpErr := fmt.Errorf("query export failed: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	size, err := target.Commit(ctx)
+	if err != nil {
+		mcpErr := fmt.Errorf("query export failed: %w", err)
+		return toolError(mcpErr), nil
+	}
+	log.Printf("INFO: Exported %d rows (%d bytes) to %s", results.RowCount, size, target.Location)
+
+	jsonData, err := json.MarshalIndent(exportResult{
+		Location: target.Location,
+		Format:   format,
+		RowCount: results.RowCount,
+		Bytes:    size,
+		QueryID:  results.QueryID,
+		InfoURI:  results.InfoURI,
+		Stats:    results.Stats,
+	}, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal export result to JSON: %w", err)
+		return toolError(mcpErr), nil
+	}
+
+	return withProvenance(mcp.NewToolResultText(string(jsonData)), served.Provenance(ctx, query, results, started)), nil
+}
+
+// RegisterTrinoTools registers all Trino-related tools with the MCP server.
+// OAuth middleware is applied server-wide via WithToolHandlerMiddleware(),
+// so no per-tool middleware application needed. Tools can be hidden with
+// TRINO_ENABLED_TOOLS / TRINO_DISABLED_TOOLS.
+func RegisterTrinoTools(m *server.MCPServer, h *TrinoHandlers) {
+	registered := make(map[string]bool)
+	addTool := func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+		registered[tool.Name] = true
+		if !h.Config.IsToolEnabled(tool.Name) {
+			log.Printf("INFO: Tool %s disabled by configuration", tool.Name)
+			return
+		}
+		m.AddTool(tool, handler)
+	}
+
+	// Tools take a cluster argument only when several clusters are configured
+	var clusterParam mcp.ToolOption = func(*mcp.Tool) {}
+	if names := h.Clusters.Names(); len(names) > 1 {
+		clusterParam = mcp.WithString("cluster",
+			mcp.Description(fmt.Sprintf("Named Trino cluster to use (optional; defaults to %s). Use list_clusters to see what each cluster is for", h.Clusters.Default().Name)),
+			mcp.Enum(names...))
+	}
+
+	// Per-request labels for Trino resource group selectors and chargeback
+	sourceParam := mcp.WithString("source", mcp.Description("Source reported to Trino for this query instead of the configured one (optional), e.g. a dashboard or job name that resource group selectors match on"))
+	clientTagsParam := mcp.WithArray("client_tags", mcp.Description("Client tags added to the configured ones for this query (optional), e.g. [\"team:growth\", \"priority:low\"]; used by resource group selectors and for chargeback"), mcp.Items(map[string]any{"type": "string"}))
+	// Behind a Trino Gateway, a query may pick the gateway's routing group
+	var routingGroupParam mcp.ToolOption = func(*mcp.Tool) {}
+	for _, cl := range h.Clusters.List() {
+		if cl.Config.Gateway {
+			routingGroupParam = mcp.WithString("routing_group", mcp.Description("Trino Gateway routing group for this query instead of the configured one (optional), e.g. etl or adhoc; selects the backend clusters that may run it"))
+			break
+		}
+	}
+	zoneParam := mcp.WithString("time_zone", mcp.Description("Session time zone for this query instead of the configured one (optional), e.g. America/New_York or +05:30; affects current_timestamp, date functions and the offset timestamps without time zone are reported with"))
+
+	// Schema tools merge in data catalog metadata when one is configured
+	dataCatalogNote := ""
+	if h.dataCatalog != nil {
+		dataCatalogNote = fmt.Sprintf(" Includes descriptions, owners and tags from the %s data catalog.", h.Config.DataCatalog)
+	}
+
+	// execute_query may write if any cluster allows write queries
+	allowWrites := false
+	for _, cl := range h.Clusters.List() {
+		allowWrites = allowWrites || cl.Config.AllowWriteQueries
+	}
+	// get_auth_url is offered if any cluster logs in with external authentication
+	externalAuth := false
+	for _, cl := range h.Clusters.List() {
+		externalAuth = externalAuth || cl.Config.ExternalAuth
+	}
+	// call_procedure is offered if any procedure is allowlisted at startup
+	allowProcedures := false
+	for _, cl := range h.Clusters.List() {
+		allowProcedures = allowProcedures || len(cl.Config.AllowedProcedures) > 0
+	}
+
+	addTool(mcp.NewTool("execute_query",
+		mcp.WithDescription("Execute SQL queries on Trino's fast distributed query engine for big data analytics. By default, only read-only queries (SELECT, SHOW, DESCRIBE, EXPLAIN) are allowed for security. When TRINO_ALLOW_WRITE_QUERIES=true is set, supports all SQL statements including INSERT, UPDATE, DELETE, CREATE, DROP, and other DML/DDL operations. Perfect for complex analytics, aggregations, joins, and cross-system data exploration on large datasets."),
+		mcp.WithTitleAnnotation("Execute Query"),
+		mcp.WithReadOnlyHintAnnotation(!allowWrites),
+		mcp.WithDestructiveHintAnnotation(allowWrites),
+		clusterParam,
+		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query to execute. By default read-only queries only; DML/DDL requires TRINO_ALLOW_WRITE_QUERIES=true")),
+		mcp.WithArray("params", mcp.Description("Values bound to ? placeholders in the query, in order (optional). Always pass user-supplied values here instead of concatenating them into the SQL. Use CAST(? AS DATE) etc. for non-string types"), mcp.Items(map[string]any{"type": []string{"string", "number", "boolean", "null"}})),
+		mcp.WithString("snapshot_id", mcp.Description("Iceberg snapshot or Delta Lake version to read, as a string (optional). Adds FOR VERSION AS OF to the table the query reads; the query must read a single table. Find IDs with get_iceberg_metadata")),
+		mcp.WithString("as_of_timestamp", mcp.Description("Read every Iceberg/Delta Lake table in the query as of this time (optional), e.g. 2024-01-31T12:00:00Z or 2024-01-31 12:00:00 (UTC). Adds FOR TIMESTAMP AS OF; set only one of snapshot_id and as_of_timestamp")),
+		mcp.WithString("format", mcp.Description("Output format: json (default), csv, tsv, markdown, or arrow. Markdown tables are compact for reading; csv/tsv suit export; arrow returns a base64-encoded Arrow IPC stream with exact column types for programmatic consumers"), mcp.Enum("json", "csv", "tsv", "markdown", "arrow")),
+		sourceParam,
+		clientTagsParam,
+		routingGroupParam,
+		zoneParam,
+		mcp.WithNumber("timeout_seconds", mcp.Description(fmt.Sprintf("Seconds the query may run before it is cancelled (optional; defaults to %d, at most %d). Also sets Trino's query_max_run_time for the query", int64(h.Config.QueryTimeout/time.Second), int64(h.Config.MaxQueryTimeout/time.Second))), mcp.Min(1)),
+		mcp.WithString("idempotency_key", mcp.Description("Unique key for this call, e.g. a UUID (optional). Retrying with the same key and arguments returns the original result, or waits for the query if it is still running, instead of running it again; results are kept for 10 minutes and failed calls run again")),
+	), h.ExecuteQuery)
+
+	addTool(mcp.NewTool("export_query",
+		mcp.WithDescription("Run a SQL query and stream the complete result set to a file instead of returning rows inline. Use for large results that would not fit in a response. Writes CSV, JSONL, Parquet, or Arrow IPC to a local path under the export directory, an allowed s3:// or gs:// URI, or a new temp file, and returns the location, row count, size, and query statistics."),
+		mcp.WithTitleAnnotation("Export Query"),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query whose results to export. Same read-only restrictions as execute_query")),
+		mcp.WithString("format", mcp.Description("File format: csv (default), jsonl, parquet, or arrow (IPC stream). Parquet and arrow keep decimal, timestamp and nested column types"), mcp.Enum("csv", "jsonl", "parquet", "arrow")),
+		mcp.WithString("destination", mcp.Description("Relative or absolute path inside the export directory, or an s3:// / gs:// URI allowed by TRINO_EXPORT_ALLOWED_URIS (optional; a URI ending in / gets a generated file name; defaults to a new temp file)")),
+		sourceParam,
+		clientTagsParam,
+		routingGroupParam,
+		zoneParam),
+		h.ExportQuery)
+
+	addTool(mcp.NewTool("list_catalogs",
+		mcp.WithDescription("Discover available Trino catalogs - each catalog represents a connector to different data systems (PostgreSQL, MySQL, S3, HDFS, Kafka, etc.). Catalogs are your entry point to querying data across heterogeneous systems in a single SQL query."),
+		mcp.WithTitleAnnotation("List Catalogs"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam),
+		h.ListCatalogs)
+
+	addTool(mcp.NewTool("list_clusters",
+		mcp.WithDescription("List the Trino clusters this server can query, with their host, default catalog/schema, whether write queries are allowed, and which one is the default. Pass a cluster name as the cluster argument of other tools to target it."),
+		mcp.WithTitleAnnotation("List Clusters"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false)),
+		h.ListClusters)
+
+	if externalAuth {
+		addTool(mcp.NewTool("get_auth_url",
+			mcp.WithDescription("Get the URL to open in a browser to log in to Trino with single sign-on, for when the server cannot open a browser itself (e.g. it runs remotely or in a container). Starts a login if none is in progress; once the user has logged in, queries work and this reports authenticated. Call it when a tool fails with \"Trino sign-in required\" and show the URL to the user."),
+			mcp.WithTitleAnnotation("Get Auth URL"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			clusterParam),
+			h.GetAuthURL)
+
+		addTool(mcp.NewTool("reauthenticate",
+			mcp.WithDescription("Log in to Trino again: discard the cached single sign-on token and start a new login, returning its URL. Use it to switch to another identity, or when queries keep failing because the token was revoked. Until the user completes the login, queries wait for it (or, in headless mode, fail with the URL)."),
+			mcp.WithTitleAnnotation("Reauthenticate"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			clusterParam),
+			h.Reauthenticate)
+	}
+
+	addTool(mcp.NewTool("get_usage",
+		mcp.WithDescription("Show how many queries you ran today and how many bytes they scanned, against the server's daily per-user quotas, with the budget remaining and when it resets (midnight UTC). Every tool that runs Trino queries counts towards the quotas, metadata tools included; a limit of 0 means unlimited."),
+		mcp.WithTitleAnnotation("Get Usage"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false)),
+		h.GetUsage)
+
+	if h.Config.DebugEnabled {
+		addTool(mcp.NewTool("server_stats",
+			mcp.WithDescription("Show the MCP server's own runtime state for operators debugging it: uptime, goroutines, heap and garbage collection, cached and in-flight tool calls, and each Trino cluster's open connections and metadata cache size. Reports nothing about Trino queries or data. Operator tool: requires the admin API key or an admin user."),
+			mcp.WithTitleAnnotation("Server Stats"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false)),
+			h.ServerStats)
+
+		addTool(mcp.NewTool("set_debug_logging",
+			mcp.WithDescription("Turn the server's debug logging on or off, for operators diagnosing a problem. While on, the server log records every tool call, the SQL sent to Trino and each Trino HTTP request with its timing; credentials, SQL string literals and bound values are redacted. Turn it off again when done, as it is verbose. Operator tool: requires the admin API key or an admin user."),
+			mcp.WithTitleAnnotation("Set Debug Logging"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithBoolean("enabled", mcp.Required(), mcp.Description("true to turn debug logging on, false to turn it off"))),
+			h.SetDebugLogging)
+	}
+
+	if h.Config.AdminEnabled {
+		addTool(mcp.NewTool("admin_kill_query",
+			mcp.WithDescription("Kill a running Trino query of any user by its query ID, e.g. a runaway query holding cluster resources. Admin tool: requires the admin API key or an admin user."),
+			mcp.WithTitleAnnotation("Kill Query"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			clusterParam,
+			mcp.WithString("query_id", mcp.Required(), mcp.Description("Trino query ID, e.g. 20240131_120000_00001_abcde"))),
+			h.AdminKillQuery)
+
+		addTool(mcp.NewTool("admin_invalidate_caches",
+			mcp.WithDescription("Drop the server's cached Trino metadata of every cluster and its data catalog metadata, and re-read the dbt manifest on next use, so that tables changed outside the server show up at once. Admin tool: requires the admin API key or an admin user."),
+			mcp.WithTitleAnnotation("Invalidate Caches"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false)),
+			h.AdminInvalidateCaches)
+
+		addTool(mcp.NewTool("admin_reload_config",
+			mcp.WithDescription("Reload the allowlists, query policies, result limits, rate limits, quotas and rotated Trino credentials, as SIGHUP does. On failure the current settings are kept. Admin tool: requires the admin API key or an admin user."),
+			mcp.WithTitleAnnotation("Reload Configuration"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false)),
+			h.AdminReloadConfig)
+
+		addTool(mcp.NewTool("admin_usage_report",
+			mcp.WithDescription("Show every client's query tool calls and scanned bytes today, the heaviest first, with the daily quotas. Admin tool: requires the admin API key or an admin user."),
+			mcp.WithTitleAnnotation("Usage Report"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false)),
+			h.AdminUsageReport)
+	}
+
+	addTool(mcp.NewTool("list_schemas",
+		mcp.WithDescription("Browse schemas (databases/namespaces) within a Trino catalog. Each schema contains related tables and views. Use this to navigate the data hierarchy before querying specific datasets."),
+		mcp.WithTitleAnnotation("List Schemas"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Trino catalog name (optional; defaults to server configuration if omitted)")),
+		mcp.WithString("pattern", mcp.Description("SQL LIKE pattern the names must match, e.g. sales_% (optional; % matches any characters, _ one character, and \\ escapes either)")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum names to return, in name order (optional; at most %d). With limit or page_token the result is an object with a nextPageToken while more names follow", trino.MaxListLimit)), mcp.Min(1), mcp.Max(trino.MaxListLimit)),
+		mcp.WithString("page_token", mcp.Description("nextPageToken of the previous page, to continue the listing (optional)"))),
+		h.ListSchemas)
+
+	addTool(mcp.NewTool("list_tables",
+		mcp.WithDescription("Discover tables and views available for querying in Trino schemas. Essential for finding datasets to analyze. Can scope to specific catalog/schema or browse all available data across the distributed system."+dataCatalogNote),
+		mcp.WithTitleAnnotation("List Tables"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Trino catalog name (optional)")),
+		mcp.WithString("schema", mcp.Description("Schema name within catalog (optional)")),
+		mcp.WithBoolean("include_comments", mcp.Description("List each table as an object with its comment, which is often the only documentation of a table (optional; default false)")),
+		mcp.WithString("pattern", mcp.Description("SQL LIKE pattern the names must match, e.g. sales_% (optional; % matches any characters, _ one character, and \\ escapes either)")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum names to return, in name order (optional; at most %d). With limit or page_token the result is an object with a nextPageToken while more names follow", trino.MaxListLimit)), mcp.Min(1), mcp.Max(trino.MaxListLimit)),
+		mcp.WithString("page_token", mcp.Description("nextPageToken of the previous page, to continue the listing (optional)"))),
+		h.ListTables)
+
+	addTool(mcp.NewTool("get_table_schema",
+		mcp.WithDescription("Inspect table structure and column metadata from Trino's distributed data sources. Shows column names, data types, nullability, and constraints, with the table comment and column comments. Critical for understanding data before writing analytical queries."+dataCatalogNote),
+		mcp.WithTitleAnnotation("Get Table Schema"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Trino catalog containing the table (optional)")),
+		mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
+		mcp.WithString("table", mcp.Required(), mcp.Description("Table name to inspect")),
+		mcp.WithString("snapshot_id", mcp.Description("Iceberg snapshot or Delta Lake version to describe, as a string (optional)")),
+		mcp.WithString("as_of_timestamp", mcp.Description("Describe the Iceberg/Delta Lake table as of this time (optional), e.g. 2024-01-31T12:00:00Z")),
+	), h.GetTableSchema)
+
+	addTool(mcp.NewTool("get_table_schemas",
+		mcp.WithDescription(fmt.Sprintf("Get the columns and types of several tables in one call, e.g. all tables of a join being planned. Reads information_schema.columns once per catalog instead of describing each table; use get_table_schema for column comments and time travel. At most %d tables.", trino.MaxSchemaTables)+dataCatalogNote),
+		mcp.WithTitleAnnotation("Get Table Schemas"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Trino catalog of tables given without one (optional)")),
+		mcp.WithString("schema", mcp.Description("Schema of tables given without one (optional)")),
+		mcp.WithArray("tables", mcp.Required(), mcp.Description("Tables to describe; each may be qualified as schema.table or catalog.schema.table"), mcp.Items(map[string]any{"type": "string"})),
+	), h.GetTableSchemas)
+
+	addTool(mcp.NewTool("get_table_ddl",
+		mcp.WithDescription("Show the CREATE statement of a table, view or materialized view (SHOW CREATE TABLE), including what a column list leaves out: partitioning, bucketing, sort order, file format, location and other table properties. Dropped masked columns are left out."),
+		mcp.WithTitleAnnotation("Get Table DDL"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Trino catalog containing the table (optional)")),
+		mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
+		mcp.WithString("table", mcp.Required(), mcp.Description("Table name; may be qualified as schema.table or catalog.schema.table")),
+	), h.GetTableDDL)
+
+	// set_comment writes to the metastore, so it is only offered when writes are allowed
+	if allowWrites {
+		addTool(mcp.NewTool("set_comment",
+			mcp.WithDescription("Set the comment of a table, or of one of its columns, with COMMENT ON, persisting documentation learned while exploring the data into the metastore where get_table_schema and other tools will show it. An empty comment removes the existing one. Only available when write queries are allowed; allowlists apply."),
+			mcp.WithTitleAnnotation("Set Comment"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			clusterParam,
+			mcp.WithString("catalog", mcp.Description("Trino catalog containing the table (optional)")),
+			mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
+			mcp.WithString("table", mcp.Required(), mcp.Description("Table to comment on; may be qualified as schema.table or catalog.schema.table")),
+			mcp.WithString("column", mcp.Description("Column to comment on (optional; default the table itself)")),
+			mcp.WithString("comment", mcp.Required(), mcp.Description("The new comment, replacing any existing one; an empty string removes it")),
+		), h.SetComment)
+	}
+
+	addTool(mcp.NewTool("dump_schema",
+		mcp.WithDescription(fmt.Sprintf("Describe every table of a schema, or of all schemas in a catalog, in a single JSON document: column names, types and comments per table, as get_table_schema returns them. Tables are described concurrently, which is much faster than calling get_table_schema table by table. At most %d tables are included; tables that cannot be described are listed with their error.", trino.MaxDumpTables)),
+		mcp.WithTitleAnnotation("Dump Schema"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Required(), mcp.Description("Trino catalog to describe")),
+		mcp.WithString("schema", mcp.Description("Schema to describe (optional; default all schemas except information_schema)")),
+	), h.DumpSchema)
+
+	addTool(mcp.NewTool("diff_schemas",
+		mcp.WithDescription("Compare the columns of two tables, or of all tables in two schemas (e.g. staging against production), and list added, removed and changed columns by name, type and nullability, plus tables that exist on one side only. Useful for reviewing migrations and dbt changes."),
+		mcp.WithTitleAnnotation("Diff Schemas"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("a", mcp.Required(), mcp.Description("Baseline table (catalog.schema.table) or schema (catalog.schema)")),
+		mcp.WithString("b", mcp.Required(), mcp.Description("Table or schema compared against a, of the same kind; differences are reported as changes from a to b")),
+	), h.DiffSchemas)
+
+	addTool(mcp.NewTool("diff_tables",
+		mcp.WithDescription(fmt.Sprintf("Compare the data of two tables with the same key, e.g. a rebuilt table against production, by generating and running comparison SQL. Mode rows joins the tables on the key columns and reports how many rows exist only in a, only in b or differ in value, with a sample of those rows ordered by key; both tables may hold at most %d rows after the where filter. Mode checksum only compares row counts and an order-insensitive checksum, which is cheap on any size. The generated queries are returned alongside the result.", trino.MaxDiffJoinRows)),
+		mcp.WithTitleAnnotation("Diff Tables"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("a", mcp.Required(), mcp.Description("Baseline table; may be qualified as schema.table or catalog.schema.table")),
+		mcp.WithString("b", mcp.Required(), mcp.Description("Table compared against a; differences are reported from a to b")),
+		mcp.WithArray("key_columns", mcp.Required(), mcp.Description("Columns identifying a row in both tables"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithArray("columns", mcp.Description("Columns to compare (optional; default every other column both tables share, except masked ones)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithString("where", mcp.Description("Filter applied to both tables (optional): a single condition without subqueries, e.g. ds = '2024-01-01' to compare one partition")),
+		mcp.WithString("mode", mcp.Description("Comparison mode: rows (default) or checksum"), mcp.Enum(trino.DiffModeRows, trino.DiffModeChecksum)),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Mismatched rows to return in rows mode (optional; default %d, at most %d)", trino.DefaultDiffMismatchLimit, trino.MaxDiffMismatchLimit)), mcp.Min(1), mcp.Max(trino.MaxDiffMismatchLimit)),
+	), h.DiffTables)
+
+	addTool(mcp.NewTool("suggest_joins",
+		mcp.WithDescription("Propose how to join a table with the other tables of its schema, to help write correct multi-table queries. Matches key-like columns (id, *_id, *key, *_code) of compatible types, such as orders.customer_id = customers.id or lineitem.orderkey = orders.orderkey, and returns ready-to-use join conditions with a confidence (high: the key is named after one of the tables; medium: both tables share the key column). Trino does not expose primary or foreign keys, so suggestions are inferred from names and types."),
+		mcp.WithTitleAnnotation("Suggest Joins"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Trino catalog containing the table (optional)")),
+		mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
+		mcp.WithString("table", mcp.Required(), mcp.Description("Table to find joins for; may be qualified as schema.table or catalog.schema.table")),
+	), h.SuggestJoins)
+
+	addTool(mcp.NewTool("column_distribution",
+		mcp.WithDescription(fmt.Sprintf("Summarize how the values of a column are distributed, without writing the aggregation SQL: null fraction, approximate distinct count, minimum and maximum, plus an equal-width histogram (numeric columns with many distinct values) or the most frequent values with their counts. Tables with more than %d rows after the where filter are sampled with TABLESAMPLE BERNOULLI; counts then refer to the sample and fractions estimate the whole table. The generated queries are returned alongside the result.", trino.DistributionSampleRows)),
+		mcp.WithTitleAnnotation("Column Distribution"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("table", mcp.Required(), mcp.Description("Table to profile; may be qualified as schema.table or catalog.schema.table")),
+		mcp.WithString("column", mcp.Required(), mcp.Description("Column to summarize")),
+		mcp.WithNumber("buckets", mcp.Description(fmt.Sprintf("Histogram buckets or top values to return (optional; default %d, at most %d)", trino.DefaultDistributionBuckets, trino.MaxDistributionBuckets)), mcp.Min(1), mcp.Max(trino.MaxDistributionBuckets)),
+		mcp.WithString("kind", mcp.Description("histogram or top_values (optional; default histogram for numeric columns with more distinct values than buckets, top_values otherwise)"), mcp.Enum(trino.DistributionHistogram, trino.DistributionTopValues)),
+		mcp.WithString("where", mcp.Description("Filter applied to the table (optional): a single condition without subqueries, e.g. ds = '2024-01-01' to profile one partition")),
+	), h.ColumnDistribution)
+
+	addTool(mcp.NewTool("estimate_row_count",
+		mcp.WithDescription("Estimate how many rows a table has, or how many match a filter, to judge whether a join, export or full scan is feasible before running it. Uses the table statistics (SHOW STATS) when the connector has them, which costs no scan; otherwise counts the rows with count(*), in a TABLESAMPLE sample when sample_percent is set. Returns the row count with the method used (stats, sample or count) and the queries that ran."),
+		mcp.WithTitleAnnotation("Estimate Row Count"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("table", mcp.Required(), mcp.Description("Table to count; may be qualified as schema.table or catalog.schema.table")),
+		mcp.WithString("where", mcp.Description("Count only the rows matching this filter (optional): a single condition without subqueries, e.g. ds = '2024-01-01'")),
+		mcp.WithNumber("sample_percent", mcp.Description("Without statistics, count a sample of this percentage of rows and scale it up instead of counting every row (optional)"), mcp.Min(0), mcp.Max(100)),
+		mcp.WithBoolean("exact", mcp.Description("Skip the statistics and count every row (optional; default false)")),
+	), h.EstimateRowCount)
+
+	// dbt model tools, when a manifest is configured
+	if h.manifest != nil {
+		addTool(mcp.NewTool("list_models",
+			mcp.WithDescription("List the models of the configured dbt project with the Trino table each one builds (relation), its materialization, tags and the first line of its description. Search to find the model for a business concept, then use get_model for its columns and docs."),
+			mcp.WithTitleAnnotation("List dbt Models"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			clusterParam,
+			mcp.WithString("search", mcp.Description("Text to find in model names, descriptions, tags or tables, case-insensitive (optional)")),
+			mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum models to list (optional; default %d, at most %d)", dbt.DefaultSearchLimit, dbt.MaxSearchLimit)), mcp.Min(1), mcp.Max(dbt.MaxSearchLimit)),
+		), h.ListModels)
+
+		addTool(mcp.NewTool("get_model",
+			mcp.WithDescription("Describe a dbt model: the Trino table it builds (relation, to use in queries), its description, materialization, tags, SQL file and upstream dependencies, and the columns of the table with their Trino types and dbt column docs. Documented columns missing from the table are listed separately."),
+			mcp.WithTitleAnnotation("Get dbt Model"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			clusterParam,
+			mcp.WithString("model_name", mcp.Required(), mcp.Description("Model name, package.name, or unique ID such as model.shop.orders")),
+		), h.GetModel)
+	}
+
+	addTool(mcp.NewTool("preview_table",
+		mcp.WithDescription("Show a handful of example rows from a table without writing SQL. Rows are randomly sampled with TABLESAMPLE so they are representative rather than just the first rows stored; on small tables or connectors without sampling support the first rows are returned. Allowlists, column masks and result limits apply."),
+		mcp.WithTitleAnnotation("Preview Table"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Trino catalog containing the table (optional)")),
+		mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
+		mcp.WithString("table", mcp.Required(), mcp.Description("Table name to preview; may be qualified as schema.table or catalog.schema.table")),
+		mcp.WithNumber("rows", mcp.Description(fmt.Sprintf("Number of rows to return (optional; default %d, at most %d)", trino.DefaultPreviewRows, trino.MaxPreviewRows)), mcp.Min(1), mcp.Max(trino.MaxPreviewRows)),
+		mcp.WithString("sampling", mcp.Description("Sampling method: bernoulli (default; random rows, works on every connector), system (random splits; cheaper on large tables but connector dependent), or none (first rows)"), mcp.Enum(trino.SampleBernoulli, trino.SampleSystem, trino.SampleNone)),
+		mcp.WithNumber("percentage", mcp.Description(fmt.Sprintf("Percentage of the table to sample from (optional; default %g).", trino.DefaultSamplePercent)), mcp.Min(0), mcp.Max(100))),
+		h.PreviewTable)
+
+	addTool(mcp.NewTool("get_iceberg_metadata",
+		mcp.WithDescription("Summarize the metadata of an Iceberg table: recent snapshots with added/deleted files and records, snapshot history for time travel, partition counts and the largest partitions, or data file statistics including small-file and delete-file counts that indicate when the table needs compaction. Only works on tables in Iceberg catalogs."),
+		mcp.WithTitleAnnotation("Get Iceberg Metadata"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Iceberg catalog containing the table (optional)")),
+		mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
+		mcp.WithString("table", mcp.Required(), mcp.Description("Iceberg table name; may be qualified as schema.table or catalog.schema.table")),
+		mcp.WithString("metadata", mcp.Required(), mcp.Description("Metadata to summarize: snapshots ($snapshots), history ($history), partitions ($partitions) or files ($files)"), mcp.Enum(trino.IcebergSnapshots, trino.IcebergHistory, trino.IcebergPartitions, trino.IcebergFiles)),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum snapshots, history entries or partitions to list, newest or largest first (optional; default %d, at most %d)", trino.DefaultIcebergLimit, trino.MaxIcebergLimit)), mcp.Min(1), mcp.Max(trino.MaxIcebergLimit)),
+		mcp.WithNumber("small_file_threshold_mb", mcp.Description(fmt.Sprintf("Data files smaller than this many MiB count as small files (optional; default %d)", trino.DefaultSmallFileSizeMiB)), mcp.Min(0))),
+		h.GetIcebergMetadata)
+
+	addTool(mcp.NewTool("get_delta_history",
+		mcp.WithDescription("List the commit history of a Delta Lake table, newest first: version, commit time, user, operation (WRITE, MERGE, DELETE, UPDATE, OPTIMIZE, ...), operation parameters such as predicates and modes, and operation metrics such as rows and files added, updated or removed. Use it to audit data changes or pick a version for time travel. Only works on tables in Delta Lake catalogs."),
+		mcp.WithTitleAnnotation("Get Delta Lake History"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Delta Lake catalog containing the table (optional)")),
+		mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
+		mcp.WithString("table", mcp.Required(), mcp.Description("Delta Lake table name; may be qualified as schema.table or catalog.schema.table")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum commits to list, newest first (optional; default %d, at most %d)", trino.DefaultDeltaHistoryLimit, trino.MaxDeltaHistoryLimit)), mcp.Min(1), mcp.Max(trino.MaxDeltaHistoryLimit)),
+		mcp.WithNumber("since_version", mcp.Description("Only list commits at or after this table version (optional)"), mcp.Min(0)),
+		mcp.WithString("operation", mcp.Description("Only list commits of this operation, case-insensitive, e.g. DELETE or MERGE (optional)"))),
+		h.GetDeltaHistory)
+
+	if allowProcedures {
+		addTool(mcp.NewTool("call_procedure",
+			mcp.WithDescription("Call a connector procedure with CALL, such as system.sync_partition_metadata to register Hive partitions written outside Trino or system.rollback_to_snapshot to restore an Iceberg table. Only procedures in the server's TRINO_ALLOWED_PROCEDURES allowlist can be called; they run even when write queries are disabled."),
+			mcp.WithTitleAnnotation("Call Procedure"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			clusterParam,
+			mcp.WithString("procedure", mcp.Required(), mcp.Description("Procedure to call as procedure, schema.procedure or catalog.schema.procedure; the schema defaults to system and the catalog to the default catalog")),
+			mcp.WithArray("arguments", mcp.Description(`Procedure arguments (optional). Each is a value passed by position (string, number, boolean or null), or an object {"name": ..., "type": ..., "value": ...} that passes the value by name and/or casts a string value to a Trino type, e.g. {"type": "bigint", "value": "8954597067493422955"} for a snapshot ID`), mcp.Items(map[string]any{"type": []string{"string", "number", "boolean", "null", "object"}})),
+		), h.CallProcedure)
+	}
+
+	// run_table_maintenance rewrites and deletes files, so it is only offered when writes are allowed
+	if allowWrites {
+		addTool(mcp.NewTool("run_table_maintenance",
+			mcp.WithDescription("Run an Iceberg maintenance procedure on a table with ALTER TABLE ... EXECUTE: optimize compacts data files below a size threshold, expire_snapshots removes snapshots older than the retention, and remove_orphan_files deletes files no snapshot references. Dry run by default, estimating the files and snapshots affected from the table's metadata; pass dry_run=false to run the procedure. Use get_iceberg_metadata to decide whether a table needs maintenance."),
+			mcp.WithTitleAnnotation("Run Table Maintenance"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			clusterParam,
+			mcp.WithString("catalog", mcp.Description("Iceberg catalog containing the table (optional)")),
+			mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
+			mcp.WithString("table", mcp.Required(), mcp.Description("Iceberg table; may be qualified as schema.table or catalog.schema.table")),
+			mcp.WithString("procedure", mcp.Required(), mcp.Enum(trino.MaintenanceOptimize, trino.MaintenanceExpireSnapshots, trino.MaintenanceRemoveOrphanFiles),
+				mcp.Description("Procedure to run")),
+			mcp.WithNumber("file_size_threshold_mb", mcp.Description(fmt.Sprintf("optimize only: data files below this size in MiB are rewritten (default: %d, max: %d)", trino.DefaultSmallFileSizeMiB, trino.MaxFileSizeThresholdMiB))),
+			mcp.WithNumber("retention_days", mcp.Description(fmt.Sprintf("expire_snapshots and remove_orphan_files only: keep snapshots and files younger than this many days (default: %d; Trino rejects values below its configured minimum retention)", trino.DefaultMaintenanceRetentionDays))),
+			mcp.WithBoolean("dry_run", mcp.Description("Only estimate the work without running the procedure (default: true)")),
+		), h.RunTableMaintenance)
+	}
+
+	addTool(mcp.NewTool("list_partitions",
+		mcp.WithDescription("List the partitions of a Hive or Iceberg table from its $partitions metadata table, newest partition values first, with per-partition record, file and size statistics for Iceberg. Pass a filter (the WHERE condition a query would use, on partition columns only) to see how many partitions that query would scan and verify partition pruning before running an expensive scan."),
+		mcp.WithTitleAnnotation("List Partitions"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Catalog containing the table (optional)")),
+		mcp.WithString("schema", mcp.Description("Schema containing the table (optional)")),
+		mcp.WithString("table", mcp.Required(), mcp.Description("Partitioned table name; may be qualified as schema.table or catalog.schema.table")),
+		mcp.WithString("filter", mcp.Description("WHERE condition on partition columns, e.g. ds >= '2024-01-01' AND region = 'eu' (optional). Iceberg partition columns are partition field names such as event_time_day")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum partitions to list (optional; default %d, at most %d)", trino.DefaultPartitionLimit, trino.MaxPartitionLimit)), mcp.Min(1), mcp.Max(trino.MaxPartitionLimit))),
+		h.ListPartitions)
+
+	addTool(mcp.NewTool("explain_query",
+		mcp.WithDescription("Analyze Trino query execution plans without running expensive queries. Shows distributed execution stages, data movement between nodes, and resource estimates. Essential for query optimization and performance tuning. Set diagram to get the plan as a Mermaid flowchart or Graphviz DOT digraph of operators, exchanges and estimated row counts."),
+		mcp.WithTitleAnnotation("Explain Query"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query to analyze (SELECT, JOIN, aggregations, etc.)")),
+		mcp.WithString("format", mcp.Description("Plan type: LOGICAL, DISTRIBUTED, VALIDATE, or IO (optional; diagrams support LOGICAL and DISTRIBUTED, default DISTRIBUTED)")),
+		mcp.WithString("diagram", mcp.Description("Render the plan as a diagram instead of text (optional)"), mcp.Enum(trino.DiagramMermaid, trino.DiagramDOT))),
+		h.ExplainQuery)
+
+	addTool(mcp.NewTool("validate_query",
+		mcp.WithDescription("Check whether a SQL query would run, without executing it: syntax, table and column existence, types, access control and this server's query policies. Returns {\"valid\": true}, or {\"valid\": false} with the error name, position, hint and name suggestions. Cheap enough to call before every execute_query while iterating on SQL."),
+		mcp.WithTitleAnnotation("Validate Query"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query to validate"))),
+		h.ValidateQuery)
+
+	addTool(mcp.NewTool("lint_query",
+		mcp.WithDescription("Statically check a SQL query for common problems before running it: SELECT * on wide tables, partitioned tables scanned without a partition filter, cross joins, WHERE conditions that apply functions to columns or start LIKE with a wildcard, and columns compared to literals of another type. Column types come from the tables' cached schemas. Returns findings with a rule, severity (error: the query fails or is rejected; warning: it scans or computes more than needed), message and suggested fix."),
+		mcp.WithTitleAnnotation("Lint Query"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query to check"))),
+		h.LintQuery)
+
+	addTool(mcp.NewTool("analyze_query_lineage",
+		mcp.WithDescription("Parse a SQL statement without executing it and list the tables it reads and writes (fully qualified as catalog.schema.table) and the columns it references where they can be attributed to a table. Use for governance pre-checks or to build lineage maps. The analysis is lexical: tables behind views are not expanded, and unqualified columns are only attributed in single-table queries."),
+		mcp.WithTitleAnnotation("Analyze Query Lineage"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("query", mcp.Required(), mcp.Description("SQL statement to analyze; any statement type is accepted since nothing is executed")),
+		mcp.WithString("catalog", mcp.Description("Catalog for unqualified table names (optional; defaults to the cluster's catalog)")),
+		mcp.WithString("schema", mcp.Description("Schema for unqualified table names (optional; defaults to the cluster's schema)"))),
+		h.AnalyzeQueryLineage)
+
+	addTool(mcp.NewTool("format_sql",
+		mcp.WithDescription("Reformat SQL canonically without executing it: keywords in one case, each clause on its own line, one select item and AND/OR condition per line, and subqueries indented. Literals, quoted identifiers and comments are kept as written. Use it to show users readable SQL and to make diffs between query iterations meaningful."),
+		mcp.WithTitleAnnotation("Format SQL"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("query", mcp.Required(), mcp.Description("SQL to format; several statements may be separated by semicolons")),
+		mcp.WithString("keyword_case", mcp.Description("Case of keywords: upper or lower (optional; default upper)"), mcp.Enum(trino.KeywordCaseUpper, trino.KeywordCaseLower))),
+		h.FormatSQL)
+
+	addTool(mcp.NewTool("render_query",
+		mcp.WithDescription("Fill in a SQL template with typed parameters instead of splicing values into SQL strings. Placeholders are {{name:type}} (required), {{name:type?}} (optional, renders NULL) or {{name:type=default}}, with type identifier, string, integer, number, boolean, date or timestamp, or a list of one such as integer[] for IN lists. Values are validated and rendered as literals or quoted identifiers, so they cannot change the structure of the query. Returns the rendered query, to pass to execute_query, and the template's parameters."),
+		mcp.WithTitleAnnotation("Render Query"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithString("template", mcp.Required(), mcp.Description("SQL with placeholders, e.g. SELECT * FROM {{table:identifier}} WHERE ds = {{day:date}} LIMIT {{n:integer=100}}")),
+		mcp.WithObject("params", mcp.Description("Parameter values by name (optional): strings, numbers, booleans, null, or arrays for list parameters"))),
+		h.RenderQuery)
+
+	addTool(mcp.NewTool("list_functions",
+		mcp.WithDescription("Search the functions available in Trino, including UDFs registered in a catalog, so queries use functions that exist instead of guessed ones. Returns each matching function name with its type (scalar, aggregate, window, table), number of overloads and description. Use describe_function for the argument and return types."),
+		mcp.WithTitleAnnotation("List Functions"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("pattern", mcp.Description("Name filter (optional): text the name contains, e.g. json or date, or a SQL LIKE pattern with %")),
+		mcp.WithString("catalog", mcp.Description("Catalog of functions registered in a schema (optional; defaults to the cluster's catalog when schema is set)")),
+		mcp.WithString("schema", mcp.Description("Schema whose functions to list (optional; without catalog and schema, built-in functions are listed)"))),
+		h.ListFunctions)
+
+	addTool(mcp.NewTool("describe_function",
+		mcp.WithDescription("Show every signature of a Trino function: argument types, return type, function type, whether it is deterministic, and its description. Use before writing a query with an unfamiliar function."),
+		mcp.WithTitleAnnotation("Describe Function"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("name", mcp.Required(), mcp.Description("Function name, e.g. date_trunc, or catalog.schema.function for a function registered in a catalog"))),
+		h.DescribeFunction)
+
+	addTool(mcp.NewTool("get_resource_groups",
+		mcp.WithDescription("Show how busy the Trino cluster is: running and queued queries per resource group, how long the oldest queued query has waited, and the queue waits of recent queries. Use it to explain why a query is queued or slow to start, and to decide whether to run a heavy query now or later."),
+		mcp.WithTitleAnnotation("Get Resource Groups"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam),
+		h.GetResourceGroups)
+
+	addTool(mcp.NewTool("show_grants",
+		mcp.WithDescription("Show what the server's effective Trino identity can access: the current user (after impersonation), the configured roles, the enabled and granted roles, and the table privileges visible in a catalog. Use it to explain access denied errors or why results differ from the user's own Trino sessions."),
+		mcp.WithTitleAnnotation("Show Grants"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("catalog", mcp.Description("Catalog whose roles and table privileges to show (optional; defaults to the cluster's catalog)")),
+		mcp.WithString("schema", mcp.Description("Only show privileges on tables of this schema (optional)")),
+		mcp.WithString("table", mcp.Description("Only show privileges on this table; may be qualified as schema.table or catalog.schema.table (optional)"))),
+		h.ShowGrants)
+
+	addTool(mcp.NewTool("find_query",
+		mcp.WithDescription("Find a recent Trino query by its query ID, or search the text of recent queries for a fragment, and return its state, user, error type and code, timings and a link to the query in the Trino UI. Use it to check on a query or to hand a failing query ID and link to a platform team."),
+		mcp.WithTitleAnnotation("Find Query"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		clusterParam,
+		mcp.WithString("query_id", mcp.Description("Trino query ID to look up, e.g. 20250101_120000_00042_abcde (set this or text)")),
+		mcp.WithString("text", mcp.Description("Fragment of the query text to search for, case-insensitive (set this or query_id)")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum queries to return, newest first (optional; default %d, at most %d)", trino.DefaultFindQueryLimit, trino.MaxFindQueryLimit)), mcp.Min(1), mcp.Max(trino.MaxFindQueryLimit))),
+		h.FindQuery)
+
+	// Warn about configured tool names that do not match any registered tool
+	for _, name := range append(append([]string{}, h.Config.EnabledTools...), h.Config.DisabledTools...) {
+		if !registered[strings.ToLower(name)] {
+			log.Printf("WARNING: Unknown tool %q in TRINO_ENABLED_TOOLS/TRINO_DISABLED_TOOLS", name)
+		}
+	}
 }
@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// idempotencyTTL is how long the result of a call with an idempotency key is kept
+	idempotencyTTL = 10 * time.Minute
+
+	// maxIdempotentCalls bounds the results kept for retries
+	maxIdempotentCalls = 256
+
+	// maxIdempotencyKeyLength bounds the keys clients may choose
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotentCall is a tool call made with an idempotency key
+type idempotentCall struct {
+	fingerprint string        // Hash of the arguments the key was first used with
+	done        chan struct{} // Closed when result is set
+	result      *mcp.CallToolResult
+	expires     time.Time // Zero while the call runs
+}
+
+// idempotencyCache lets clients retry a tool call, e.g. after a transport
+// hiccup, without running it twice: a retry with the same key waits for the
+// call still running or gets the result it returned. Keys are scoped to the
+// client, and calls that fail are forgotten so a retry runs them again.
+type idempotencyCache struct {
+	mu    sync.Mutex
+	calls map[string]*idempotentCall
+	now   func() time.Time
+}
+
+// newIdempotencyCache creates an empty idempotency cache
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{calls: make(map[string]*idempotentCall), now: time.Now}
+}
+
+// idempotencyKey returns the idempotency_key argument of a tool call, or "" if there is none
+func idempotencyKey(request mcp.CallToolRequest) (string, error) {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok || args["idempotency_key"] == nil {
+		return "", nil
+	}
+	key, ok := args["idempotency_key"].(string)
+	if !ok {
+		return "", fmt.Errorf("idempotency_key parameter must be a string")
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return "", fmt.Errorf("idempotency_key must be at most %d characters", maxIdempotencyKeyLength)
+	}
+	return key, nil
+}
+
+// fingerprint hashes the arguments of a tool call other than its idempotency key
+func fingerprint(request mcp.CallToolRequest) string {
+	args, _ := request.Params.Arguments.(map[string]interface{})
+	rest := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		if name != "idempotency_key" {
+			rest[name] = value
+		}
+	}
+	data, _ := json.Marshal(rest) // Map keys are sorted
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// do runs next once per client and key. The call is detached from the
+// caller's context, so it keeps running when the client disconnects or gives
+// up; the Trino query timeout still bounds it.
+func (c *idempotencyCache) do(ctx context.Context, client, key string, request mcp.CallToolRequest, next server.ToolHandlerFunc) (*mcp.CallToolResult, error) {
+	id := client + "\x00" + key
+	hash := fingerprint(request)
+
+	c.mu.Lock()
+	c.sweep()
+	call, ok := c.calls[id]
+	if ok && call.fingerprint != hash {
+		c.mu.Unlock()
+		return toolError(fmt.Errorf("idempotency_key %q was already used with different arguments; use a new key for a different query", key)), nil
+	}
+	if !ok {
+		call = &idempotentCall{fingerprint: hash, done: make(chan struct{})}
+		c.calls[id] = call
+		go c.run(context.WithoutCancel(ctx), id, call, request, next)
+	} else {
+		log.Printf("INFO: Retry of %s with idempotency key %q attached to the original call", request.Params.Name, key)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run executes the call and keeps its result for retries, unless it failed
+func (c *idempotencyCache) run(ctx context.Context, id string, call *idempotentCall, request mcp.CallToolRequest, next server.ToolHandlerFunc) {
+	result, err := next(ctx, request)
+	if err != nil {
+		result = toolError(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call.result = result
+	if result.IsError {
+		delete(c.calls, id)
+	} else {
+		call.expires = c.now().Add(idempotencyTTL)
+	}
+	close(call.done)
+}
+
+// sweep drops expired results and, above maxIdempotentCalls, the results
+// closest to expiring. Calls still running are kept.
+func (c *idempotencyCache) sweep() {
+	now := c.now()
+	for id, call := range c.calls {
+		if !call.expires.IsZero() && !now.Before(call.expires) {
+			delete(c.calls, id)
+		}
+	}
+	for len(c.calls) >= maxIdempotentCalls {
+		oldest := ""
+		for id, call := range c.calls {
+			if !call.expires.IsZero() && (oldest == "" || call.expires.Before(c.calls[oldest].expires)) {
+				oldest = id
+			}
+		}
+		if oldest == "" {
+			return
+		}
+		delete(c.calls, oldest)
+	}
+}
+
+// size returns the number of calls kept, running or done
+func (c *idempotencyCache) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func idempotentRequest(query, key string) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Name = "execute_query"
+	request.Params.Arguments = map[string]interface{}{"query": query, "idempotency_key": key}
+	return request
+}
+
+func TestIdempotencyCache(t *testing.T) {
+	cache := newIdempotencyCache()
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		runs.Add(1)
+		<-release
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return mcp.NewToolResultText("rows"), nil
+	}
+	request := idempotentRequest("SELECT 1", "key-1")
+
+	// The first caller gives up while the query runs; the query keeps going
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cache.do(ctx, "user:alice", "key-1", request, handler); !errors.Is(err, context.Canceled) {
+		t.Fatalf("do() with cancelled caller = %v, want context.Canceled", err)
+	}
+
+	// The retry attaches to the running call
+	done := make(chan *mcp.CallToolResult)
+	go func() {
+		result, _ := cache.do(context.Background(), "user:alice", "key-1", request, handler)
+		done <- result
+	}()
+	close(release)
+	if result := <-done; result == nil || result.IsError || result.Content[0].(mcp.TextContent).Text != "rows" {
+		t.Fatalf("retry result = %+v, want the original rows", result)
+	}
+
+	// Later retries get the kept result
+	if result, _ := cache.do(context.Background(), "user:alice", "key-1", request, handler); result.IsError {
+		t.Errorf("retry after completion = %+v", result)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Errorf("handler ran %d times, want 1", n)
+	}
+
+	// The key cannot be reused for another query, but other clients have their own keys
+	result, _ := cache.do(context.Background(), "user:alice", "key-1", idempotentRequest("SELECT 2", "key-1"), handler)
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "different arguments") {
+		t.Errorf("reused key result = %+v, want an error", result)
+	}
+	if result, _ := cache.do(context.Background(), "user:bob", "key-1", request, handler); result.IsError {
+		t.Errorf("other client result = %+v", result)
+	}
+	if n := runs.Load(); n != 2 {
+		t.Errorf("handler ran %d times, want 2", n)
+	}
+
+	// Results expire
+	now = now.Add(idempotencyTTL)
+	_, _ = cache.do(context.Background(), "user:alice", "key-1", request, handler)
+	if runs.Load() != 3 {
+		t.Errorf("handler ran %d times after expiry, want 3", runs.Load())
+	}
+}
+
+func TestIdempotencyCacheForgetsFailures(t *testing.T) {
+	cache := newIdempotencyCache()
+	var runs int
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		runs++
+		return mcp.NewToolResultError("cluster unavailable"), nil
+	}
+	request := idempotentRequest("SELECT 1", "key-1")
+	for i := 0; i < 2; i++ {
+		if result, _ := cache.do(context.Background(), "local", "key-1", request, handler); !result.IsError {
+			t.Fatalf("result = %+v, want the error", result)
+		}
+	}
+	if runs != 2 {
+		t.Errorf("handler ran %d times, want a failed call to run again", runs)
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	if key, err := idempotencyKey(idempotentRequest("SELECT 1", "abc")); key != "abc" || err != nil {
+		t.Errorf("idempotencyKey() = %q, %v", key, err)
+	}
+	if _, err := idempotencyKey(idempotentRequest("SELECT 1", strings.Repeat("k", maxIdempotencyKeyLength+1))); err == nil {
+		t.Error("idempotencyKey() should reject an overlong key")
+	}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]interface{}{"query": "SELECT 1", "idempotency_key": 42}
+	if _, err := idempotencyKey(request); err == nil {
+		t.Error("idempotencyKey() should reject a non-string key")
+	}
+}
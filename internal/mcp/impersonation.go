@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+	oauth "github.com/tuannvm/oauth-mcp-proxy"
+)
+
+// impersonationMiddleware runs every tool call as the authenticated MCP user:
+// Trino receives the user's principal in X-Trino-User, so its access control
+// and audit logs see the real end user rather than TRINO_USER. With
+// TRINO_IMPERSONATION_REQUIRED, calls without a principal are refused instead
+// of running as TRINO_USER.
+func impersonationMiddleware(cfg *config.TrinoConfig) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			principal := impersonatedPrincipal(ctx, cfg.ImpersonationField)
+			if principal != "" {
+				return next(trino.WithImpersonatedUser(ctx, principal), request)
+			}
+			if cfg.ImpersonationRequired {
+				log.Printf("Rejected %s: no %s to impersonate", request.Params.Name, cfg.ImpersonationField)
+				return toolError(&trino.QueryError{
+					Name:    trino.ErrorPermissionDenied,
+					Type:    "USER_ERROR",
+					Message: fmt.Sprintf("impersonation required: the authenticated user has no %s", cfg.ImpersonationField),
+					Policy:  true,
+					Hint:    "Sign in with an account whose token carries the claim set in TRINO_IMPERSONATION_FIELD",
+				}), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// impersonatedPrincipal returns the field of the authenticated user that Trino
+// sees as the session user, or "" when there is no user or the field is empty
+func impersonatedPrincipal(ctx context.Context, field string) string {
+	user, ok := oauth.GetUserFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	var principal string
+	switch field {
+	case "email":
+		principal = user.Email
+	case "subject":
+		principal = user.Subject
+	default:
+		principal = user.Username
+	}
+	if principal == "" {
+		log.Printf("Warning: Impersonation enabled but %s field is empty for user", field)
+	}
+	return principal
+}
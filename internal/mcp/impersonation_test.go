@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+	oauth "github.com/tuannvm/oauth-mcp-proxy"
+)
+
+func TestImpersonationMiddleware(t *testing.T) {
+	var impersonated string
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		impersonated, _ = trino.GetImpersonatedUser(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	}
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "list_catalogs"
+	alice := oauth.WithUser(context.Background(), &oauth.User{Username: "alice", Email: "alice@example.com"})
+
+	tests := []struct {
+		name     string
+		cfg      *config.TrinoConfig
+		ctx      context.Context
+		want     string
+		rejected bool
+	}{
+		{name: "username", cfg: &config.TrinoConfig{ImpersonationField: "username"}, ctx: alice, want: "alice"},
+		{name: "email", cfg: &config.TrinoConfig{ImpersonationField: "email"}, ctx: alice, want: "alice@example.com"},
+		{name: "empty field runs as TRINO_USER", cfg: &config.TrinoConfig{ImpersonationField: "subject"}, ctx: alice},
+		{name: "no user runs as TRINO_USER", cfg: &config.TrinoConfig{ImpersonationField: "username"}, ctx: context.Background()},
+		{name: "required without user", cfg: &config.TrinoConfig{ImpersonationField: "username", ImpersonationRequired: true}, ctx: context.Background(), rejected: true},
+		{name: "required with empty field", cfg: &config.TrinoConfig{ImpersonationField: "subject", ImpersonationRequired: true}, ctx: alice, rejected: true},
+		{name: "required with user", cfg: &config.TrinoConfig{ImpersonationField: "username", ImpersonationRequired: true}, ctx: alice, want: "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impersonated = ""
+			result, err := impersonationMiddleware(tt.cfg)(handler)(tt.ctx, request)
+			if err != nil {
+				t.Fatalf("middleware error = %v", err)
+			}
+			if result.IsError != tt.rejected {
+				t.Fatalf("IsError = %v, want %v", result.IsError, tt.rejected)
+			}
+			if tt.rejected {
+				text := result.Content[0].(mcp.TextContent).Text
+				if !strings.Contains(text, "impersonation required") {
+					t.Errorf("error = %q, want impersonation required", text)
+				}
+				return
+			}
+			if impersonated != tt.want {
+				t.Errorf("impersonated user = %q, want %q", impersonated, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stdioSessionID is the ID mcp-go gives the session of the stdio transport
+const stdioSessionID = "stdio"
+
+// authenticator runs the middlewares that authenticate tool calls and pick
+// the Trino user to run them as on a request that is not a tool call. It
+// returns the context to serve the request in.
+type authenticator func(ctx context.Context, method string) (context.Context, error)
+
+// newAuthenticator returns an authenticator running the middlewares in order
+func newAuthenticator(middlewares ...server.ToolHandlerMiddleware) authenticator {
+	return func(ctx context.Context, method string) (context.Context, error) {
+		var authenticated context.Context
+		var handler server.ToolHandlerFunc = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			authenticated = ctx
+			return &mcp.CallToolResult{}, nil
+		}
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			handler = middlewares[i](handler)
+		}
+
+		request := mcp.CallToolRequest{}
+		request.Params.Name = method
+		result, err := handler(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		if authenticated == nil {
+			err = errors.New("request rejected")
+			if len(result.Content) > 0 {
+				if text, ok := result.Content[0].(mcp.TextContent); ok {
+					err = errors.New(text.Text)
+				}
+			}
+			return nil, err
+		}
+		return authenticated, nil
+	}
+}
+
+// interceptor answers the requests mcp-go does not dispatch before the MCP
+// server sees them: completion/complete, and resources/subscribe and
+// resources/unsubscribe when schema subscriptions are enabled. The
+// transports hand it every message from a client.
+type interceptor struct {
+	completer     *completer
+	subscriptions *subscriptions // nil without TRINO_SCHEMA_POLL_INTERVAL
+}
+
+// intercepts reports whether the interceptor answers a method
+func (i *interceptor) intercepts(method string) bool {
+	switch method {
+	case methodCompletion:
+		return true
+	case methodResourcesSubscribe, methodResourcesUnsubscribe:
+		return i.subscriptions != nil
+	}
+	return false
+}
+
+// handle answers a message of a client session; it reports false for
+// messages left to the MCP server
+func (i *interceptor) handle(ctx context.Context, sessionID string, message []byte) (mcp.JSONRPCMessage, bool) {
+	var request struct {
+		ID     mcp.RequestId   `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if json.Unmarshal(message, &request) != nil || !i.intercepts(request.Method) {
+		return nil, false
+	}
+	if request.Method == methodCompletion {
+		return i.completer.handle(ctx, request.ID, request.Params), true
+	}
+	return i.subscriptions.handle(ctx, sessionID, request.Method, request.ID, request.Params), true
+}
+
+// peek returns the method of a JSON-RPC message, "" for a response
+func peek(message []byte) string {
+	var base struct {
+		Method string `json:"method"`
+	}
+	_ = json.Unmarshal(message, &base)
+	return base.Method
+}
+
+// filterStdio returns the messages of stdin that the interceptor leaves to
+// the MCP server, and answers the others on stdout itself
+func (i *interceptor) filterStdio(ctx context.Context, stdin io.Reader, stdout *stdioWriter) io.Reader {
+	reader, writer := io.Pipe()
+	go func() {
+		lines := bufio.NewReader(stdin)
+		for {
+			line, err := lines.ReadBytes('\n')
+			if len(line) > 0 {
+				if i.intercepts(peek(line)) {
+					go func(line []byte) {
+						response, _ := i.handle(ctx, stdioSessionID, line)
+						if data, err := json.Marshal(response); err == nil {
+							_, _ = stdout.Write(append(data, '\n'))
+						}
+					}(line)
+					continue
+				}
+				if _, err := writer.Write(line); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return reader
+}
+
+// stdioWriter serializes the lines the stdio server and the interceptor
+// write, and advertises the completions capability in the initialize response
+type stdioWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *stdioWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(advertiseCompletions(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// serveHTTP answers the POSTs the interceptor handles, and advertises the
+// completions capability in the response to initialize. Other requests are
+// left to next with their body intact.
+func (i *interceptor) serveHTTP(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	if r.Method != http.MethodPost {
+		next.ServeHTTP(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	switch method := peek(body); {
+	case i.intercepts(method):
+		response, _ := i.handle(r.Context(), r.Header.Get(server.HeaderKeySessionID), body)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("Error encoding %s response: %v", method, err)
+		}
+	case method == string(mcp.MethodInitialize):
+		buffered := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(buffered, r)
+		w.Header().Del("Content-Length")
+		w.WriteHeader(buffered.status)
+		_, _ = w.Write(advertiseCompletions(buffered.body.Bytes()))
+	default:
+		next.ServeHTTP(w, r)
+	}
+}
+
+// bufferedResponse holds a response back so it can be rewritten
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) { b.status = status }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+
+// advertiseCompletions adds the completions capability, which mcp-go has no
+// option for, to an initialize response. Other messages are returned as is.
+func advertiseCompletions(message []byte) []byte {
+	if !bytes.Contains(message, []byte(`"protocolVersion"`)) {
+		return message
+	}
+	var response map[string]json.RawMessage
+	if json.Unmarshal(message, &response) != nil {
+		return message
+	}
+	var result map[string]json.RawMessage
+	if json.Unmarshal(response["result"], &result) != nil || result["protocolVersion"] == nil {
+		return message
+	}
+	var capabilities map[string]json.RawMessage
+	if json.Unmarshal(result["capabilities"], &capabilities) != nil || capabilities == nil {
+		return message
+	}
+	capabilities["completions"] = json.RawMessage("{}")
+
+	var err error
+	if result["capabilities"], err = json.Marshal(capabilities); err != nil {
+		return message
+	}
+	if response["result"], err = json.Marshal(result); err != nil {
+		return message
+	}
+	patched, err := json.Marshal(response)
+	if err != nil {
+		return message
+	}
+	if bytes.HasSuffix(message, []byte("\n")) {
+		patched = append(patched, '\n')
+	}
+	return patched
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func TestAuthenticator(t *testing.T) {
+	cfg := &config.TrinoConfig{EnableImpersonation: true, ImpersonationField: "username"}
+	ctx, err := newAuthenticator(impersonationMiddleware(cfg))(context.Background(), methodCompletion)
+	if err != nil {
+		t.Fatalf("authenticate() error = %v", err)
+	}
+	if user, ok := trino.GetImpersonatedUser(ctx); ok {
+		t.Errorf("impersonated user = %q without an OAuth user", user)
+	}
+
+	cfg.ImpersonationRequired = true
+	if _, err := newAuthenticator(impersonationMiddleware(cfg))(context.Background(), methodCompletion); err == nil || !strings.Contains(err.Error(), "impersonation required") {
+		t.Errorf("authenticate() error = %v, want the middleware's rejection", err)
+	}
+}
+
+func TestInterceptorFilterStdio(t *testing.T) {
+	cfg := &config.TrinoConfig{Host: "localhost", Port: 8080, User: "mcp", DefaultCluster: config.DefaultClusterName}
+	i := &interceptor{completer: &completer{handlers: newTestHandlers(t, cfg), authenticate: newAuthenticator()}}
+
+	stdin := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"completion/complete","params":{"argument":{"name":"query","value":""}}}` + "\n" +
+		`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"trino://hive/sales/orders"}}` + "\n" +
+		`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+	var stdout bytes.Buffer
+	forwarded, err := io.ReadAll(i.filterStdio(context.Background(), stdin, &stdioWriter{w: &stdout}))
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	got := string(forwarded)
+	if strings.Contains(got, "completion/complete") || !strings.Contains(got, `"ping"`) || !strings.Contains(got, `"tools/list"`) {
+		t.Errorf("forwarded = %q, want every message but the completion", got)
+	}
+	// Without subscriptions, subscribing is left to mcp-go, which rejects it
+	if !strings.Contains(got, "resources/subscribe") {
+		t.Errorf("forwarded = %q, want the subscription", got)
+	}
+}
+
+func TestAdvertiseCompletions(t *testing.T) {
+	initialize := `{"jsonrpc":"2.0","id":0,"result":{"protocolVersion":"2025-06-18","capabilities":{"tools":{"listChanged":true}},"serverInfo":{"name":"Trino MCP Server","version":"dev"}}}` + "\n"
+	var response struct {
+		Result struct {
+			Capabilities map[string]json.RawMessage `json:"capabilities"`
+		} `json:"result"`
+	}
+	patched := advertiseCompletions([]byte(initialize))
+	if err := json.Unmarshal(patched, &response); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if response.Result.Capabilities["completions"] == nil || response.Result.Capabilities["tools"] == nil {
+		t.Errorf("capabilities = %s", patched)
+	}
+	if !bytes.HasSuffix(patched, []byte("\n")) {
+		t.Error("advertiseCompletions() dropped the line break")
+	}
+
+	// A tool result mentioning protocolVersion is left alone
+	result := `{"jsonrpc":"2.0","id":1,"result":{"content":[{"type":"text","text":"\"protocolVersion\""}]}}`
+	if got := string(advertiseCompletions([]byte(result))); got != result {
+		t.Errorf("advertiseCompletions() changed a tool result: %s", got)
+	}
+}
@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// maxExactJSONInteger is the largest integer a JSON number (float64) holds exactly
+const maxExactJSONInteger = 1 << 53
+
+// queryParams converts JSON tool arguments into values the Trino driver can bind
+// to ? placeholders. Whole numbers bind as BIGINT, other numbers as DECIMAL literals.
+func queryParams(raw []interface{}) ([]interface{}, error) {
+	params := make([]interface{}, len(raw))
+	for i, v := range raw {
+		switch val := v.(type) {
+		case nil, string, bool:
+			params[i] = val
+		case float64:
+			if math.IsNaN(val) || math.IsInf(val, 0) {
+				return nil, fmt.Errorf("params[%d]: %v is not a valid number", i, val)
+			}
+			if val == math.Trunc(val) && math.Abs(val) <= maxExactJSONInteger {
+				params[i] = int64(val)
+			} else {
+				params[i] = trino.Numeric(strconv.FormatFloat(val, 'f', -1, 64))
+			}
+		default:
+			return nil, fmt.Errorf("params[%d]: unsupported value of type %T (use string, number, boolean or null)", i, v)
+		}
+	}
+	return params, nil
+}
+
+// timeTravelParams reads the optional snapshot_id and as_of_timestamp arguments.
+// Snapshot IDs often exceed the integers a JSON number holds exactly, so
+// snapshot_id is expected as a string; exact numbers are accepted too.
+func timeTravelParams(args map[string]interface{}) (trino.TimeTravel, error) {
+	var snapshotID, asOf string
+	switch val := args["snapshot_id"].(type) {
+	case nil:
+	case string:
+		snapshotID = val
+	case float64:
+		if val != math.Trunc(val) || math.Abs(val) > maxExactJSONInteger {
+			return trino.TimeTravel{}, fmt.Errorf("snapshot_id %v cannot be represented exactly as a JSON number; pass it as a string", val)
+		}
+		snapshotID = strconv.FormatInt(int64(val), 10)
+	default:
+		return trino.TimeTravel{}, fmt.Errorf("snapshot_id must be a string")
+	}
+	if val, ok := args["as_of_timestamp"]; ok && val != nil {
+		if asOf, ok = val.(string); !ok {
+			return trino.TimeTravel{}, fmt.Errorf("as_of_timestamp must be a string")
+		}
+	}
+	return trino.ParseTimeTravel(snapshotID, asOf)
+}
+
+// queryLabelParams reads the optional source and client_tags arguments that
+// label a query for resource group selection and chargeback, and the
+// routing_group argument that selects its Trino Gateway routing group
+func queryLabelParams(args map[string]interface{}) (trino.QueryLabels, error) {
+	var labels trino.QueryLabels
+	if val, ok := args["source"]; ok && val != nil {
+		if labels.Source, ok = val.(string); !ok {
+			return trino.QueryLabels{}, fmt.Errorf("source must be a string")
+		}
+	}
+	if val, ok := args["routing_group"]; ok && val != nil {
+		if labels.RoutingGroup, ok = val.(string); !ok {
+			return trino.QueryLabels{}, fmt.Errorf("routing_group must be a string")
+		}
+	}
+	tags, err := stringListParam(args, "client_tags")
+	if err != nil {
+		return trino.QueryLabels{}, err
+	}
+	labels.ClientTags = tags
+	return labels, labels.Validate()
+}
+
+// timeZoneParam reads the optional time_zone argument that overrides
+// TRINO_SESSION_TIMEZONE for one query
+func timeZoneParam(args map[string]interface{}) (string, error) {
+	val, ok := args["time_zone"]
+	if !ok || val == nil {
+		return "", nil
+	}
+	zone, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("time_zone must be a string")
+	}
+	if zone = strings.TrimSpace(zone); zone == "" {
+		return "", nil
+	}
+	if _, err := config.LoadTimeZone(zone); err != nil {
+		return "", err
+	}
+	return zone, nil
+}
+
+// timeoutParam reads the optional timeout_seconds of execute_query, which
+// may not exceed TRINO_MAX_QUERY_TIMEOUT; 0 keeps the configured timeout
+func timeoutParam(args map[string]interface{}, limit time.Duration) (time.Duration, error) {
+	val, ok := args["timeout_seconds"]
+	if !ok || val == nil {
+		return 0, nil
+	}
+	seconds, ok := val.(float64)
+	if !ok || seconds != math.Trunc(seconds) || seconds <= 0 {
+		return 0, fmt.Errorf("timeout_seconds must be a positive whole number")
+	}
+	timeout := time.Duration(seconds) * time.Second
+	if limit > 0 && timeout > limit {
+		return 0, fmt.Errorf("timeout_seconds must be at most %d, the server's TRINO_MAX_QUERY_TIMEOUT", int64(limit/time.Second))
+	}
+	return timeout, nil
+}
+
+// listPageParams reads the optional pattern, limit and page_token of
+// list_schemas and list_tables
+func listPageParams(args map[string]interface{}) (trino.ListPage, error) {
+	var page trino.ListPage
+	page.Pattern, _ = args["pattern"].(string)
+	page.PageToken, _ = args["page_token"].(string)
+	val, ok := args["limit"]
+	if !ok || val == nil {
+		return page, nil
+	}
+	limit, ok := val.(float64)
+	if !ok || limit != math.Trunc(limit) || limit <= 0 || limit > trino.MaxListLimit {
+		return page, fmt.Errorf("limit must be a whole number from 1 to %d", trino.MaxListLimit)
+	}
+	page.Limit = int(limit)
+	return page, nil
+}
+
+// procedureArgumentsParam reads the optional arguments of call_procedure: an
+// array of values passed by position, or of {"name", "type", "value"} objects
+// passing a value by name or cast to a type
+func procedureArgumentsParam(args map[string]interface{}) ([]trino.ProcedureArgument, error) {
+	val, ok := args["arguments"]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("arguments must be an array")
+	}
+	arguments := make([]trino.ProcedureArgument, len(list))
+	for i, item := range list {
+		arg := trino.ProcedureArgument{Value: item}
+		if object, ok := item.(map[string]interface{}); ok {
+			arg.Value = object["value"]
+			for key, field := range map[string]*string{"name": &arg.Name, "type": &arg.Type} {
+				if v, ok := object[key]; ok && v != nil {
+					if *field, ok = v.(string); !ok {
+						return nil, fmt.Errorf("arguments[%d].%s must be a string", i, key)
+					}
+				}
+			}
+		}
+		if number, ok := arg.Value.(float64); ok && number == math.Trunc(number) && math.Abs(number) > maxExactJSONInteger {
+			return nil, fmt.Errorf("arguments[%d]: %v cannot be represented exactly as a JSON number; pass it as a string with a type, e.g. {\"type\": \"bigint\", \"value\": \"...\"}", i, number)
+		}
+		arguments[i] = arg
+	}
+	return arguments, nil
+}
+
+// stringListParam reads an optional array of strings argument
+func stringListParam(args map[string]interface{}, name string) ([]string, error) {
+	val, ok := args[name]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", name)
+	}
+	var values []string
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] must be a string", name, i)
+		}
+		values = append(values, s)
+	}
+	return values, nil
+}
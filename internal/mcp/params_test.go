@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func TestQueryParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []interface{}
+		expected []interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "scalars",
+			input:    []interface{}{"O'Brien", true, nil},
+			expected: []interface{}{"O'Brien", true, nil},
+		},
+		{
+			name:     "whole numbers bind as integers",
+			input:    []interface{}{float64(42), float64(-7)},
+			expected: []interface{}{int64(42), int64(-7)},
+		},
+		{
+			name:     "fractional and large numbers bind as numeric literals",
+			input:    []interface{}{1.25, 1e20},
+			expected: []interface{}{trino.Numeric("1.25"), trino.Numeric("100000000000000000000")},
+		},
+		{
+			name:    "arrays are rejected",
+			input:   []interface{}{[]interface{}{1}},
+			wantErr: true,
+		},
+		{
+			name:    "objects are rejected",
+			input:   []interface{}{map[string]interface{}{"a": 1}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := queryParams(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("queryParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("queryParams() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTimeTravelParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         map[string]interface{}
+		wantSnapshot int64
+		wantErr      bool
+	}{
+		{name: "none", args: map[string]interface{}{}},
+		{name: "snapshot as string", args: map[string]interface{}{"snapshot_id": "8954597067493422955"}, wantSnapshot: 8954597067493422955},
+		{name: "snapshot as exact number", args: map[string]interface{}{"snapshot_id": float64(12345)}, wantSnapshot: 12345},
+		{name: "snapshot beyond exact numbers", args: map[string]interface{}{"snapshot_id": float64(8954597067493422955)}, wantErr: true},
+		{name: "both set", args: map[string]interface{}{"snapshot_id": "1", "as_of_timestamp": "2024-01-31"}, wantErr: true},
+		{name: "timestamp of wrong type", args: map[string]interface{}{"as_of_timestamp": float64(1706702400)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := timeTravelParams(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("timeTravelParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			switch {
+			case tt.wantSnapshot == 0 && !got.IsZero():
+				t.Errorf("timeTravelParams() = %+v, want no time travel", got)
+			case tt.wantSnapshot != 0 && (got.SnapshotID == nil || *got.SnapshotID != tt.wantSnapshot):
+				t.Errorf("timeTravelParams() snapshot = %v, want %d", got.SnapshotID, tt.wantSnapshot)
+			}
+		})
+	}
+}
+
+func TestQueryLabelParams(t *testing.T) {
+	labels, err := queryLabelParams(map[string]interface{}{
+		"source":        "weekly-report",
+		"client_tags":   []interface{}{"team:growth", "priority:low"},
+		"routing_group": "etl",
+	})
+	if err != nil {
+		t.Fatalf("queryLabelParams() error = %v", err)
+	}
+	want := trino.QueryLabels{Source: "weekly-report", ClientTags: []string{"team:growth", "priority:low"}, RoutingGroup: "etl"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("queryLabelParams() = %+v, want %+v", labels, want)
+	}
+
+	if labels, err := queryLabelParams(map[string]interface{}{}); err != nil || !reflect.DeepEqual(labels, trino.QueryLabels{}) {
+		t.Errorf("queryLabelParams() without labels = %+v, %v", labels, err)
+	}
+
+	for name, args := range map[string]map[string]interface{}{
+		"non-string source":   {"source": 1.0},
+		"tags not an array":   {"client_tags": "team:growth"},
+		"non-string tag":      {"client_tags": []interface{}{1.0}},
+		"comma in tag":        {"client_tags": []interface{}{"a,b"}},
+		"empty tag":           {"client_tags": []interface{}{" "}},
+		"newline in source":   {"source": "job\nX-Evil: 1"},
+		"non-string group":    {"routing_group": 1.0},
+		"control char in tag": {"client_tags": []interface{}{"a\tb"}},
+	} {
+		if _, err := queryLabelParams(args); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestProcedureArgumentsParam(t *testing.T) {
+	args := map[string]interface{}{"arguments": []interface{}{
+		"analytics",
+		map[string]interface{}{"name": "snapshot_id", "type": "bigint", "value": "8954597067493422955"},
+		float64(3),
+	}}
+	got, err := procedureArgumentsParam(args)
+	if err != nil {
+		t.Fatalf("procedureArgumentsParam() error = %v", err)
+	}
+	want := []trino.ProcedureArgument{
+		{Value: "analytics"},
+		{Name: "snapshot_id", Type: "bigint", Value: "8954597067493422955"},
+		{Value: float64(3)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("procedureArgumentsParam() = %#v, want %#v", got, want)
+	}
+
+	for _, invalid := range []interface{}{
+		"analytics",
+		[]interface{}{float64(8954597067493422955)},
+		[]interface{}{map[string]interface{}{"name": 1, "value": "x"}},
+	} {
+		if _, err := procedureArgumentsParam(map[string]interface{}{"arguments": invalid}); err == nil {
+			t.Errorf("procedureArgumentsParam(%v) expected error", invalid)
+		}
+	}
+}
+
+func TestTimeZoneParam(t *testing.T) {
+	for input, want := range map[interface{}]string{nil: "", "": "", " UTC ": "UTC", "Asia/Kolkata": "Asia/Kolkata", "-03:00": "-03:00"} {
+		got, err := timeZoneParam(map[string]interface{}{"time_zone": input})
+		if err != nil || got != want {
+			t.Errorf("timeZoneParam(%v) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []interface{}{"Nowhere/City", float64(2)} {
+		if _, err := timeZoneParam(map[string]interface{}{"time_zone": input}); err == nil {
+			t.Errorf("timeZoneParam(%v) expected error", input)
+		}
+	}
+}
+
+func TestTimeoutParam(t *testing.T) {
+	for input, want := range map[interface{}]time.Duration{nil: 0, float64(90): 90 * time.Second, float64(600): 10 * time.Minute} {
+		got, err := timeoutParam(map[string]interface{}{"timeout_seconds": input}, 10*time.Minute)
+		if err != nil || got != want {
+			t.Errorf("timeoutParam(%v) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []interface{}{float64(0), float64(-5), float64(1.5), float64(601), "60"} {
+		if _, err := timeoutParam(map[string]interface{}{"timeout_seconds": input}, 10*time.Minute); err == nil {
+			t.Errorf("timeoutParam(%v) expected error", input)
+		}
+	}
+}
+
+func TestListPageParams(t *testing.T) {
+	page, err := listPageParams(map[string]interface{}{"pattern": "sales_%", "limit": float64(50), "page_token": "b3JkZXJz"})
+	if err != nil || page != (trino.ListPage{Pattern: "sales_%", Limit: 50, PageToken: "b3JkZXJz"}) {
+		t.Errorf("listPageParams() = %+v, %v", page, err)
+	}
+	if page, err := listPageParams(map[string]interface{}{}); err != nil || page.Paged() {
+		t.Errorf("listPageParams() without paging = %+v, %v; want the whole list", page, err)
+	}
+	for _, input := range []interface{}{float64(0), float64(2.5), float64(trino.MaxListLimit + 1), "10"} {
+		if _, err := listPageParams(map[string]interface{}{"limit": input}); err == nil {
+			t.Errorf("listPageParams(limit %v) expected error", input)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+	oauth "github.com/tuannvm/oauth-mcp-proxy"
+)
+
+// errorQuotaExceeded is the error name of a query tool call over a daily quota
+const errorQuotaExceeded = "QUOTA_EXCEEDED"
+
+// quotaDayFormat is the layout of the UTC day usage is counted for
+const quotaDayFormat = "2006-01-02"
+
+type clientKeyContextKey struct{}
+
+// withClientKey records the client an HTTP request was identified as, so tool
+// calls are counted against the same client as the rate limits
+func withClientKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, clientKeyContextKey{}, key)
+}
+
+// clientKey returns the client a tool call is counted against: the key of the
+// HTTP request, else the OAuth user, else "local" for the stdio transport
+func clientKey(ctx context.Context) string {
+	if key, ok := ctx.Value(clientKeyContextKey{}).(string); ok && key != "" {
+		return key
+	}
+	if user, ok := oauth.GetUserFromContext(ctx); ok {
+		if name := oauthUserName(user); name != "" {
+			return "user:" + name
+		}
+	}
+	return "local"
+}
+
+// quotaUsage is what a client used on one day
+type quotaUsage struct {
+	Queries      int   `json:"queries"`
+	ScannedBytes int64 `json:"scannedBytes"`
+}
+
+// quotaState is the MCP_QUOTA_FILE format
+type quotaState struct {
+	Day     string                 `json:"day"`
+	Clients map[string]*quotaUsage `json:"clients"`
+}
+
+// quotaStore enforces the daily per-client quotas on query tool calls: the
+// number of calls and the bytes their Trino queries scanned. Usage is counted
+// per UTC day and kept in MCP_QUOTA_FILE, when set, so restarts do not reset it.
+type quotaStore struct {
+	mu           sync.Mutex
+	queries      int   // Query tool calls per day; 0 is unlimited
+	scannedBytes int64 // Scanned bytes per day; 0 is unlimited
+	path         string
+	state        quotaState
+	now          func() time.Time
+}
+
+// newQuotaStore creates a store with the quotas of cfg and the usage saved in MCP_QUOTA_FILE
+func newQuotaStore(cfg *config.TrinoConfig) *quotaStore {
+	q := &quotaStore{path: cfg.QuotaFile, now: time.Now}
+	q.setLimits(cfg)
+	q.state = quotaState{Clients: make(map[string]*quotaUsage)}
+	if q.path == "" {
+		return q
+	}
+
+	content, err := os.ReadFile(q.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		log.Printf("WARNING: Failed to read MCP_QUOTA_FILE, starting with no usage: %v", err)
+	default:
+		var state quotaState
+		if err := json.Unmarshal(content, &state); err != nil {
+			log.Printf("WARNING: Invalid MCP_QUOTA_FILE, starting with no usage: %v", err)
+		} else if state.Clients != nil {
+			q.state = state
+		}
+	}
+	return q
+}
+
+// setLimits applies new quotas; usage counted so far is kept
+func (q *quotaStore) setLimits(cfg *config.TrinoConfig) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queries = cfg.QuotaQueriesPerDay
+	q.scannedBytes = cfg.QuotaScannedBytesPerDay
+}
+
+// today starts a new day of usage when the date changed
+func (q *quotaStore) today() {
+	if day := q.now().UTC().Format(quotaDayFormat); q.state.Day != day {
+		q.state = quotaState{Day: day, Clients: make(map[string]*quotaUsage)}
+	}
+}
+
+// usage returns the client's usage today, starting a new day when the date changed
+func (q *quotaStore) usage(client string) *quotaUsage {
+	q.today()
+	usage, ok := q.state.Clients[client]
+	if !ok {
+		usage = &quotaUsage{}
+		q.state.Clients[client] = usage
+	}
+	return usage
+}
+
+// check returns an error when the client has used up a quota for today
+func (q *quotaStore) check(client string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage := q.usage(client)
+	switch {
+	case q.queries > 0 && usage.Queries >= q.queries:
+		return q.exceeded("%d of %d queries used today", usage.Queries, q.queries)
+	case q.scannedBytes > 0 && usage.ScannedBytes >= q.scannedBytes:
+		return q.exceeded("%d of %d bytes scanned today", usage.ScannedBytes, q.scannedBytes)
+	}
+	return nil
+}
+
+// exceeded reports an exhausted quota and when it resets
+func (q *quotaStore) exceeded(format string, args ...interface{}) *trino.QueryError {
+	return &trino.QueryError{
+		Name:    errorQuotaExceeded,
+		Type:    "INSUFFICIENT_RESOURCES",
+		Message: "daily quota exceeded: " + fmt.Sprintf(format, args...),
+		Policy:  true,
+		Hint:    fmt.Sprintf("The quota resets at midnight UTC, in %s. Use get_usage to see the remaining budget", q.resetsIn().Round(time.Minute)),
+	}
+}
+
+// record counts a call costing queries and the bytes it scanned
+func (q *quotaStore) record(client string, queries int, scannedBytes int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage := q.usage(client)
+	usage.Queries += queries
+	usage.ScannedBytes += scannedBytes
+	q.save()
+}
+
+// save writes the usage to MCP_QUOTA_FILE. The file is replaced atomically so
+// a crash cannot leave it half written; failures are logged and the usage is
+// kept in memory.
+func (q *quotaStore) save() {
+	if q.path == "" {
+		return
+	}
+	content, err := json.Marshal(q.state)
+	if err != nil {
+		log.Printf("WARNING: Failed to encode quota usage: %v", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".*")
+	if err != nil {
+		log.Printf("WARNING: Failed to save quota usage: %v", err)
+		return
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), q.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		log.Printf("WARNING: Failed to save quota usage: %v", err)
+	}
+}
+
+// resetsIn returns the time until the next UTC day starts
+func (q *quotaStore) resetsIn() time.Duration {
+	now := q.now().UTC()
+	return now.Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+}
+
+// quotaBudget is the usage and remaining budget of one quota
+type quotaBudget struct {
+	Used      int64  `json:"used"`
+	Limit     int64  `json:"limit"`               // 0 means unlimited
+	Remaining *int64 `json:"remaining,omitempty"` // Omitted when unlimited
+}
+
+// quotaReport is the get_usage output
+type quotaReport struct {
+	Client       string      `json:"client"`
+	Day          string      `json:"day"`
+	ResetsAt     time.Time   `json:"resetsAt"`
+	Queries      quotaBudget `json:"queries"`
+	ScannedBytes quotaBudget `json:"scannedBytes"`
+}
+
+// report returns the client's usage today and the budget left
+func (q *quotaStore) report(client string) quotaReport {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage := q.usage(client)
+	return quotaReport{
+		Client:       client,
+		Day:          q.state.Day,
+		ResetsAt:     q.now().UTC().Add(q.resetsIn()),
+		Queries:      newQuotaBudget(int64(usage.Queries), int64(q.queries)),
+		ScannedBytes: newQuotaBudget(usage.ScannedBytes, q.scannedBytes),
+	}
+}
+
+// clientUsage is one client's usage in the admin_usage_report output
+type clientUsage struct {
+	Client string `json:"client"`
+	quotaUsage
+}
+
+// usageReport is the admin_usage_report output
+type usageReport struct {
+	Day                string        `json:"day"`
+	ResetsAt           time.Time     `json:"resetsAt"`
+	QueriesPerDay      int           `json:"queriesPerDay"`      // 0 means unlimited
+	ScannedBytesPerDay int64         `json:"scannedBytesPerDay"` // 0 means unlimited
+	Clients            []clientUsage `json:"clients"`
+}
+
+// reportAll returns every client's usage today, the heaviest first
+func (q *quotaStore) reportAll() usageReport {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.today()
+	report := usageReport{
+		Day:                q.state.Day,
+		ResetsAt:           q.now().UTC().Add(q.resetsIn()),
+		QueriesPerDay:      q.queries,
+		ScannedBytesPerDay: q.scannedBytes,
+		Clients:            make([]clientUsage, 0, len(q.state.Clients)),
+	}
+	for client, usage := range q.state.Clients {
+		report.Clients = append(report.Clients, clientUsage{Client: client, quotaUsage: *usage})
+	}
+	sort.Slice(report.Clients, func(i, j int) bool {
+		a, b := report.Clients[i], report.Clients[j]
+		if a.ScannedBytes != b.ScannedBytes {
+			return a.ScannedBytes > b.ScannedBytes
+		}
+		if a.Queries != b.Queries {
+			return a.Queries > b.Queries
+		}
+		return a.Client < b.Client
+	})
+	return report
+}
+
+func newQuotaBudget(used, limit int64) quotaBudget {
+	budget := quotaBudget{Used: used, Limit: limit}
+	if limit > 0 {
+		remaining := max(limit-used, 0)
+		budget.Remaining = &remaining
+	}
+	return budget
+}
+
+// middleware rejects calls of tools that run queries by clients over a quota
+// and counts the calls that run at their toolQueryCosts, with the bytes their
+// queries scanned
+func (q *quotaStore) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cost := toolQueryCost(request.Params.Name)
+		if cost == 0 {
+			return next(ctx, request)
+		}
+		client := clientKey(ctx)
+		if err := q.check(client); err != nil {
+			log.Printf("Rejected %s for %s: %v", request.Params.Name, client, err)
+			return toolError(err), nil
+		}
+
+		ctx, usage := trino.WithUsage(ctx)
+		result, err := next(ctx, request)
+		q.record(client, cost, usage.ScannedBytes())
+		return result, err
+	}
+}
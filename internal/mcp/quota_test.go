@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func TestQuotaStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	cfg := &config.TrinoConfig{QuotaQueriesPerDay: 2, QuotaScannedBytesPerDay: 1000, QuotaFile: path}
+	now := time.Date(2024, 6, 1, 21, 30, 0, 0, time.UTC)
+	q := newQuotaStore(cfg)
+	q.now = func() time.Time { return now }
+
+	if err := q.check("user:alice"); err != nil {
+		t.Fatalf("check() before any query = %v", err)
+	}
+	q.record("user:alice", 1, 400)
+	q.record("user:alice", 1, 100)
+
+	// The query quota is exhausted; other clients are counted separately
+	err := q.check("user:alice")
+	var queryErr *trino.QueryError
+	if !errors.As(err, &queryErr) || queryErr.Name != errorQuotaExceeded || !strings.Contains(queryErr.Message, "2 of 2 queries") {
+		t.Fatalf("check() = %v, want query quota exceeded", err)
+	}
+	if !strings.Contains(queryErr.Hint, "in 2h30m0s") {
+		t.Errorf("hint = %q, want the time until midnight UTC", queryErr.Hint)
+	}
+	if err := q.check("user:bob"); err != nil {
+		t.Errorf("check() for another client = %v", err)
+	}
+
+	report := q.report("user:alice")
+	if report.Day != "2024-06-01" || report.Queries.Used != 2 || *report.Queries.Remaining != 0 ||
+		report.ScannedBytes.Used != 500 || *report.ScannedBytes.Remaining != 500 {
+		t.Errorf("report() = %+v", report)
+	}
+	if want := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC); !report.ResetsAt.Equal(want) {
+		t.Errorf("ResetsAt = %v, want %v", report.ResetsAt, want)
+	}
+
+	// Usage survives a restart, and a reload that lifts the query quota applies the byte quota
+	q = newQuotaStore(cfg)
+	q.now = func() time.Time { return now }
+	q.setLimits(&config.TrinoConfig{QuotaScannedBytesPerDay: 500})
+	if err := q.check("user:alice"); err == nil || !strings.Contains(err.Error(), "500 of 500 bytes") {
+		t.Errorf("check() after restart = %v, want scanned bytes quota exceeded", err)
+	}
+	if report := q.report("user:alice"); report.Queries.Limit != 0 || report.Queries.Remaining != nil {
+		t.Errorf("unlimited queries reported as %+v", report.Queries)
+	}
+
+	// A new day starts with a fresh budget
+	now = now.Add(3 * time.Hour)
+	if err := q.check("user:alice"); err != nil {
+		t.Errorf("check() on the next day = %v", err)
+	}
+
+	// An unreadable file starts with no usage
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if q := newQuotaStore(cfg); len(q.state.Clients) != 0 {
+		t.Errorf("usage from invalid file = %v, want none", q.state.Clients)
+	}
+}
+
+func TestQuotaMiddleware(t *testing.T) {
+	q := newQuotaStore(&config.TrinoConfig{QuotaQueriesPerDay: 1})
+	calls := 0
+	handler := q.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText("ok"), nil
+	})
+	call := func(ctx context.Context, tool string) *mcp.CallToolResult {
+		var request mcp.CallToolRequest
+		request.Params.Name = tool
+		result, err := handler(ctx, request)
+		if err != nil {
+			t.Fatalf("%s: %v", tool, err)
+		}
+		return result
+	}
+
+	alice := withClientKey(context.Background(), "user:alice")
+	if result := call(alice, "execute_query"); result.IsError {
+		t.Fatalf("first query rejected: %v", result.Content)
+	}
+	if result := call(alice, "preview_table"); !result.IsError {
+		t.Error("second query should be rejected by the quota")
+	}
+	if result := call(alice, "list_tables"); !result.IsError {
+		t.Error("metadata tools run queries and count towards the quota")
+	}
+	if result := call(alice, "list_clusters"); result.IsError {
+		t.Error("tools that run no queries do not count towards the quota")
+	}
+	if result := call(context.Background(), "execute_query"); result.IsError {
+		t.Error("stdio client has its own quota")
+	}
+	if calls != 3 {
+		t.Errorf("handler called %d times, want 3", calls)
+	}
+}
+
+func TestQuotaReportAll(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	q := newQuotaStore(&config.TrinoConfig{QuotaScannedBytesPerDay: 1 << 30})
+	q.now = func() time.Time { return now }
+	q.record("user:alice", 1, 100)
+	q.record("user:bob", 1, 5000)
+	q.record("local", 1, 100)
+	q.record("local", 1, 0)
+
+	report := q.reportAll()
+	if report.Day != "2024-06-01" || report.QueriesPerDay != 0 || report.ScannedBytesPerDay != 1<<30 {
+		t.Errorf("reportAll() = %+v", report)
+	}
+	var order []string
+	for _, usage := range report.Clients {
+		order = append(order, usage.Client)
+	}
+	if strings.Join(order, ",") != "user:bob,local,user:alice" {
+		t.Errorf("clients = %v, want the heaviest first", order)
+	}
+
+	// A new day reports no usage
+	now = now.Add(24 * time.Hour)
+	if report := q.reportAll(); report.Day != "2024-06-02" || len(report.Clients) != 0 {
+		t.Errorf("reportAll() on the next day = %+v", report)
+	}
+}
@@ -0,0 +1,318 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	oauth "github.com/tuannvm/oauth-mcp-proxy"
+)
+
+// toolQueryCosts is how many queries a call of each tool counts as towards
+// the queries-per-hour limit and the daily query quota. Every tool that runs
+// Trino queries costs at least one; tools that run none, and the tools
+// reporting usage or logging in, are exempt with a cost of 0. Tools missing
+// from the table cost one.
+var toolQueryCosts = map[string]int{
+	// Queries the caller writes
+	"execute_query":  1,
+	"export_query":   1,
+	"explain_query":  1,
+	"validate_query": 1,
+	"preview_table":  1,
+	"render_query":   0, // Renders a template without running it
+	"format_sql":     0,
+
+	// Metadata
+	"list_catalogs":         1,
+	"list_schemas":          1,
+	"list_tables":           1,
+	"get_table_schema":      1,
+	"get_table_schemas":     1,
+	"get_table_ddl":         1,
+	"dump_schema":           1,
+	"diff_schemas":          1,
+	"suggest_joins":         1,
+	"list_partitions":       1,
+	"get_iceberg_metadata":  1,
+	"get_delta_history":     1,
+	"list_functions":        1,
+	"describe_function":     1,
+	"get_resource_groups":   1,
+	"show_grants":           1,
+	"find_query":            1,
+	"get_model":             1,
+	"list_models":           0, // Reads the dbt manifest
+	"lint_query":            1,
+	"analyze_query_lineage": 0,
+
+	// Data profiling
+	"diff_tables":         1,
+	"column_distribution": 1,
+	"estimate_row_count":  1,
+
+	// Writes
+	"set_comment":           1,
+	"call_procedure":        1,
+	"run_table_maintenance": 1,
+
+	// Server, session and operator tools
+	"list_clusters":           0,
+	"get_auth_url":            0,
+	"reauthenticate":          0,
+	"get_usage":               0,
+	"server_stats":            0,
+	"set_debug_logging":       0,
+	"admin_kill_query":        0,
+	"admin_invalidate_caches": 0,
+	"admin_reload_config":     0,
+	"admin_usage_report":      0,
+}
+
+// toolQueryCost returns how many queries a call of the tool counts as
+func toolQueryCost(name string) int {
+	if cost, ok := toolQueryCosts[name]; ok {
+		return cost
+	}
+	return 1
+}
+
+// bucketIdleTimeout is how long an untouched bucket is kept; by then it has refilled anyway
+const bucketIdleTimeout = time.Hour
+
+// tokenBucket holds up to limit tokens and refills at limit per window
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimit is a token-bucket limit applied per client
+type rateLimit struct {
+	limit   int // Tokens per window; 0 disables the limit
+	window  time.Duration
+	buckets map[string]*tokenBucket
+}
+
+// take removes a token from the client's bucket. It returns whether the
+// request is allowed, the tokens left and how long until the next token.
+func (l *rateLimit) take(key string, now time.Time) (bool, int, time.Duration) {
+	capacity := float64(l.limit)
+	rate := capacity / l.window.Seconds() // Tokens per second
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+		return false, 0, wait
+	}
+	bucket.tokens--
+	return true, int(bucket.tokens), 0
+}
+
+// rateLimiter enforces per-client request and query limits in HTTP mode.
+// Clients are identified by their authenticated user, their bearer token
+// (API key) or, without either, their IP address.
+type rateLimiter struct {
+	mu        sync.Mutex
+	requests  rateLimit
+	queries   rateLimit
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// newRateLimiter creates a limiter with the rate limits of cfg
+func newRateLimiter(cfg *config.TrinoConfig) *rateLimiter {
+	rl := &rateLimiter{
+		requests: rateLimit{window: time.Minute, buckets: make(map[string]*tokenBucket)},
+		queries:  rateLimit{window: time.Hour, buckets: make(map[string]*tokenBucket)},
+		now:      time.Now,
+	}
+	rl.setLimits(cfg)
+	return rl
+}
+
+// setLimits applies new limits; existing clients keep their remaining tokens
+func (rl *rateLimiter) setLimits(cfg *config.TrinoConfig) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.requests.limit = cfg.RateLimitRequestsPerMinute
+	rl.queries.limit = cfg.RateLimitQueriesPerHour
+}
+
+// enabled reports whether any limit is set
+func (rl *rateLimiter) enabled() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.requests.limit > 0 || rl.queries.limit > 0
+}
+
+// allow checks the request against the client's limits, sets the rate-limit
+// headers and writes a 429 response when a limit is exceeded. It returns
+// false if the request must not be served.
+func (rl *rateLimiter) allow(w http.ResponseWriter, r *http.Request, client string) bool {
+	// Read the body before taking the lock; it is restored for the MCP server
+	isQuery := false
+	if r.Method == http.MethodPost && r.Body != nil {
+		body, err := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return false
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		isQuery = callsQueryTool(body)
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := rl.now()
+	rl.sweep(now)
+
+	if rl.requests.limit > 0 {
+		ok, remaining, wait := rl.requests.take(client, now)
+		setRateLimitHeaders(w.Header(), "X-RateLimit", rl.requests.limit, remaining, wait)
+		if !ok {
+			writeRateLimited(w, wait, fmt.Sprintf("rate limit of %d requests per minute exceeded", rl.requests.limit))
+			return false
+		}
+	}
+	if isQuery && rl.queries.limit > 0 {
+		ok, remaining, wait := rl.queries.take(client, now)
+		setRateLimitHeaders(w.Header(), "X-Query-RateLimit", rl.queries.limit, remaining, wait)
+		if !ok {
+			writeRateLimited(w, wait, fmt.Sprintf("rate limit of %d queries per hour exceeded", rl.queries.limit))
+			return false
+		}
+	}
+	return true
+}
+
+// sweep drops buckets of clients that have been idle long enough to be full again
+func (rl *rateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < bucketIdleTimeout {
+		return
+	}
+	rl.lastSweep = now
+	for _, limit := range []*rateLimit{&rl.requests, &rl.queries} {
+		for key, bucket := range limit.buckets {
+			if now.Sub(bucket.last) >= bucketIdleTimeout {
+				delete(limit.buckets, key)
+			}
+		}
+	}
+}
+
+// setRateLimitHeaders adds the limit, remaining tokens and, when exhausted,
+// the seconds until the next token
+func setRateLimitHeaders(h http.Header, prefix string, limit, remaining int, wait time.Duration) {
+	h.Set(prefix+"-Limit", strconv.Itoa(limit))
+	h.Set(prefix+"-Remaining", strconv.Itoa(remaining))
+	if wait > 0 {
+		h.Set(prefix+"-Reset", strconv.Itoa(retryAfterSeconds(wait)))
+	}
+}
+
+// writeRateLimited writes a 429 response in the same shape as the OAuth errors
+func writeRateLimited(w http.ResponseWriter, wait time.Duration, description string) {
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(wait)))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	errorResponse := map[string]string{
+		"error":             "rate_limited",
+		"error_description": description,
+	}
+	if err := json.NewEncoder(w).Encode(errorResponse); err != nil {
+		log.Printf("Error encoding rate limit response: %v", err)
+	}
+}
+
+// retryAfterSeconds rounds a wait up to whole seconds, at least one
+func retryAfterSeconds(wait time.Duration) int {
+	return int(math.Max(1, math.Ceil(wait.Seconds())))
+}
+
+// callsQueryTool reports whether a JSON-RPC message (or batch) calls a tool
+// that runs queries or reads a table schema resource
+func callsQueryTool(body []byte) bool {
+	type message struct {
+		Method string `json:"method"`
+		Params struct {
+			Name string `json:"name"`
+		} `json:"params"`
+	}
+
+	var messages []message
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &messages); err != nil {
+			return false
+		}
+	} else {
+		var single message
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return false
+		}
+		messages = append(messages, single)
+	}
+
+	for _, msg := range messages {
+		if msg.Method == "tools/call" && toolQueryCost(msg.Params.Name) > 0 || msg.Method == string(mcp.MethodResourcesRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitKey identifies the client of an HTTP request: the OAuth user its
+// bearer token is validated as, else the remote IP address. Unvalidated
+// tokens are ignored, or a client could pick a new key for every request.
+func (s *Server) rateLimitKey(ctx context.Context, r *http.Request) string {
+	authHeader := r.Header.Get("Authorization")
+	if s.oauthServer != nil && len(authHeader) > 7 && strings.EqualFold(authHeader[:7], "bearer ") {
+		token := strings.TrimSpace(authHeader[7:])
+		if user, err := s.oauthServer.ValidateTokenCached(ctx, token); err == nil {
+			if name := oauthUserName(user); name != "" {
+				return "user:" + name
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// oauthUserName returns the name used to identify an OAuth user
+func oauthUserName(user *oauth.User) string {
+	switch {
+	case user == nil:
+		return ""
+	case user.Username != "":
+		return user.Username
+	case user.Email != "":
+		return user.Email
+	default:
+		return user.Subject
+	}
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	rl := newRateLimiter(&config.TrinoConfig{RateLimitRequestsPerMinute: 2, RateLimitQueriesPerHour: 1})
+	rl.now = func() time.Time { return now }
+
+	call := func(client, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		if rl.allow(w, r, client) {
+			// The body must still be readable by the MCP server
+			if restored, _ := io.ReadAll(r.Body); string(restored) != body {
+				t.Errorf("request body = %q, want %q", restored, body)
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+		return w
+	}
+	query := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"execute_query"}}`
+	listing := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"list_clusters"}}`
+
+	w := call("alice", query)
+	if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Remaining") != "1" || w.Header().Get("X-Query-RateLimit-Remaining") != "0" {
+		t.Fatalf("first request: status %d, headers %v", w.Code, w.Header())
+	}
+
+	// The query limit is exhausted but other tools still work
+	if w := call("alice", query); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "3600" {
+		t.Errorf("second query: status %d, Retry-After %q; want 429 after an hour", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := call("alice", listing); w.Code != http.StatusTooManyRequests {
+		t.Errorf("third request: status %d, want 429 for the request limit", w.Code)
+	}
+
+	// Clients are limited independently
+	if w := call("bob", listing); w.Code != http.StatusOK {
+		t.Errorf("other client: status %d, want 200", w.Code)
+	}
+
+	// Tokens refill over the window
+	now = now.Add(30 * time.Second)
+	if w := call("alice", listing); w.Code != http.StatusOK {
+		t.Errorf("after refill: status %d, want 200", w.Code)
+	}
+
+	// Disabling a limit on reload takes effect immediately
+	rl.setLimits(&config.TrinoConfig{})
+	if rl.enabled() {
+		t.Error("limiter should be disabled without limits")
+	}
+}
+
+func TestCallsQueryTool(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected bool
+	}{
+		{"Query tool", `{"method":"tools/call","params":{"name":"execute_query"}}`, true},
+		{"Metadata tool", `{"method":"tools/call","params":{"name":"list_schemas"}}`, true},
+		{"Profiling tool", `{"method":"tools/call","params":{"name":"diff_tables"}}`, true},
+		{"Exempt tool", `{"method":"tools/call","params":{"name":"list_clusters"}}`, false},
+		{"Unknown tool", `{"method":"tools/call","params":{"name":"new_tool"}}`, true},
+		{"Resource read", `{"method":"resources/read","params":{"uri":"trino://catalogs"}}`, true},
+		{"Other method", `{"method":"tools/list"}`, false},
+		{"Batch", `[{"method":"initialize"},{"method":"tools/call","params":{"name":"export_query"}}]`, true},
+		{"Invalid JSON", `not json`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callsQueryTool([]byte(tt.body)); got != tt.expected {
+				t.Errorf("callsQueryTool(%s) = %v, want %v", tt.body, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToolQueryCostsCoverEveryTool(t *testing.T) {
+	// Offer every tool: external auth connects lazily, so no server is needed
+	cfg := &config.TrinoConfig{
+		Host: "localhost", Port: 8080, User: "mcp", DefaultCluster: config.DefaultClusterName,
+		ExternalAuth: true, AllowWriteQueries: true, AllowedProcedures: []string{"system.flush_metadata_cache"},
+		DBTManifest: "manifest.json", DebugEnabled: true, AdminEnabled: true,
+	}
+	m := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	RegisterTrinoTools(m, newTestHandlers(t, cfg))
+
+	tools := m.ListTools()
+	for name := range tools {
+		if _, ok := toolQueryCosts[name]; !ok {
+			t.Errorf("tool %s has no entry in toolQueryCosts; give it a cost, or 0 if it runs no queries", name)
+		}
+	}
+	for name := range toolQueryCosts {
+		if _, ok := tools[name]; !ok {
+			t.Errorf("toolQueryCosts has an entry for %s, which is not registered", name)
+		}
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	// Without OAuth bearer tokens are not validated, so they must not pick the key
+	s := &Server{}
+	for _, authorization := range []string{"", "Bearer random-1", "Bearer random-2"} {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		r.RemoteAddr = "203.0.113.7:51234"
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		if key := s.rateLimitKey(r.Context(), r); key != "ip:203.0.113.7" {
+			t.Errorf("rateLimitKey(%q) = %q, want ip:203.0.113.7", authorization, key)
+		}
+	}
+}
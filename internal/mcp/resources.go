@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/datacatalog"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// tableResourceTemplate is the URI template of the table schema resources,
+// which clients can read and subscribe to
+const tableResourceTemplate = "trino://{catalog}/{schema}/{table}"
+
+// parseTableResourceURI returns the table of a table schema resource
+func parseTableResourceURI(uri string) (trino.TableRef, error) {
+	path, ok := strings.CutPrefix(uri, "trino://")
+	parts := strings.Split(path, "/")
+	if !ok || len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return trino.TableRef{}, fmt.Errorf("invalid table resource URI %q: must be trino://catalog/schema/table", uri)
+	}
+	return trino.TableRef{Catalog: parts[0], Schema: parts[1], Table: parts[2]}, nil
+}
+
+// registerTableResources adds the table schema resources, read like tool
+// calls after the requests pass authenticate. Like get_table_schemas each
+// read runs a query, so it counts towards the daily quotas and, over HTTP,
+// the per-client query rate limit.
+func registerTableResources(s *server.MCPServer, h *TrinoHandlers, authenticate authenticator) {
+	s.AddResourceTemplate(mcp.NewResourceTemplate(tableResourceTemplate, "table_schema",
+		mcp.WithTemplateDescription("Columns and types of a Trino table on the default cluster, as get_table_schemas reports them. Subscribe to be notified when the columns change."),
+		mcp.WithTemplateMIMEType("application/json"),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		ctx, err := authenticate(ctx, string(mcp.MethodResourcesRead))
+		if err != nil {
+			return nil, err
+		}
+		if h.quota == nil {
+			return h.readTableResource(ctx, request.Params.URI)
+		}
+		client := clientKey(ctx)
+		if err := h.quota.check(client); err != nil {
+			log.Printf("Rejected read of %s for %s: %v", request.Params.URI, client, err)
+			return nil, err
+		}
+		ctx, usage := trino.WithUsage(ctx)
+		defer func() { h.quota.record(client, 1, usage.ScannedBytes()) }()
+		return h.readTableResource(ctx, request.Params.URI)
+	})
+}
+
+// readTableResource returns the columns of the table of a table schema
+// resource, with data catalog metadata when configured
+func (h *TrinoHandlers) readTableResource(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+	ref, err := parseTableResourceURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := h.readTableSchemas(ctx, []trino.TableRef{ref})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table schema: %w", err)
+	}
+	if schemas[0].Err != nil {
+		return nil, schemas[0].Err
+	}
+
+	var metadata *datacatalog.TableMetadata
+	if h.dataCatalog != nil {
+		metadata = h.dataCatalog.Table(ctx, ref.Catalog, ref.Schema, ref.Table)
+	}
+	jsonData, err := json.MarshalIndent(describeTable(ref.String(), "", schemas[0].Columns, metadata), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal table schema to JSON: %w", err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(jsonData)}}, nil
+}
+
+// readTableSchemas reads the columns of tables on the default cluster in
+// one information_schema query per catalog
+func (h *TrinoHandlers) readTableSchemas(ctx context.Context, refs []trino.TableRef) ([]trino.TableSchema, error) {
+	tables := make([]string, len(refs))
+	for i, ref := range refs {
+		tables[i] = ref.String()
+	}
+	return trino.Route(ctx, h.Clusters, "", true, func(cluster *trino.Cluster) ([]trino.TableSchema, error) {
+		return cluster.Client.GetTableSchemasWithContext(ctx, "", "", tables)
+	})
+}
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestParseTableResourceURI(t *testing.T) {
+	ref, err := parseTableResourceURI("trino://hive/sales/orders")
+	if err != nil || ref.String() != "hive.sales.orders" {
+		t.Errorf("parseTableResourceURI() = %v, %v", ref, err)
+	}
+	for _, uri := range []string{"trino://hive/sales", "trino://hive//orders", "trino://hive/sales/orders/x", "file:///etc/passwd"} {
+		if _, err := parseTableResourceURI(uri); err == nil {
+			t.Errorf("parseTableResourceURI(%q) succeeded", uri)
+		}
+	}
+}
+
+func TestReadTableResource(t *testing.T) {
+	trinoServer := trinotest.NewServer()
+	defer trinoServer.Close()
+	trinoServer.Handle(ordersColumnsQuery, columnRows("order_id", "amount"))
+	handlers := newMockHandlers(t, trinoServer)
+
+	contents, err := handlers.readTableResource(context.Background(), "trino://hive/sales/orders")
+	if err != nil {
+		t.Fatalf("readTableResource() error = %v", err)
+	}
+	text, ok := contents[0].(mcp.TextResourceContents)
+	if !ok || text.MIMEType != "application/json" {
+		t.Fatalf("contents = %+v", contents)
+	}
+	var described describedTable
+	if err := json.Unmarshal([]byte(text.Text), &described); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if described.Table != "hive.sales.orders" || len(described.Columns) != 2 {
+		t.Errorf("described = %+v", described)
+	}
+}
+
+func TestReadTableResourceQuota(t *testing.T) {
+	trinoServer := trinotest.NewServer()
+	defer trinoServer.Close()
+	trinoServer.Handle(ordersColumnsQuery, columnRows("order_id", "amount"))
+	handlers := newMockHandlers(t, trinoServer)
+	handlers.quota = newQuotaStore(&config.TrinoConfig{QuotaQueriesPerDay: 1})
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(false, false))
+	registerTableResources(mcpServer, handlers, func(ctx context.Context, method string) (context.Context, error) {
+		return ctx, nil
+	})
+
+	read := func() mcp.JSONRPCMessage {
+		return mcpServer.HandleMessage(withClientKey(context.Background(), "user:alice"),
+			json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"trino://hive/sales/orders"}}`))
+	}
+	if response, ok := read().(mcp.JSONRPCResponse); !ok {
+		t.Fatalf("first read = %+v, want a result", response)
+	}
+	if usage := handlers.quota.report("user:alice"); usage.Queries.Used != 1 {
+		t.Errorf("queries used = %d, want 1", usage.Queries.Used)
+	}
+	if response, ok := read().(mcp.JSONRPCError); !ok {
+		t.Errorf("second read = %+v, want the quota error", response)
+	}
+}
+
+func TestResourceCapabilities(t *testing.T) {
+	for _, interval := range []time.Duration{0, time.Hour} {
+		cfg := &config.TrinoConfig{Host: "localhost", Port: 8080, User: "mcp", DefaultCluster: config.DefaultClusterName, SchemaPollInterval: interval}
+		handlers := newTestHandlers(t, cfg)
+		mcpServer, _, _ := createMCPServer(handlers.Clusters, cfg, "dev", newQuotaStore(cfg), nil)
+
+		response := mcpServer.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+		result, ok := response.(mcp.JSONRPCResponse).Result.(mcp.InitializeResult)
+		if !ok || result.Capabilities.Resources == nil {
+			t.Fatalf("interval %s: initialize = %+v, want the resources capability", interval, response)
+		}
+		if result.Capabilities.Resources.Subscribe != (interval > 0) {
+			t.Errorf("interval %s: subscribe = %t", interval, result.Capabilities.Resources.Subscribe)
+		}
+	}
+}
@@ -9,37 +9,88 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	mcpserver "github.com/mark3labs/mcp-go/server"
-	oauth "github.com/tuannvm/oauth-mcp-proxy"
 	"github.com/tuannvm/mcp-trino/internal/config"
 	"github.com/tuannvm/mcp-trino/internal/trino"
+	oauth "github.com/tuannvm/oauth-mcp-proxy"
 )
 
 // Server represents the MCP server with all components
 type Server struct {
 	mcpServer   *mcpserver.MCPServer
+	clusters    *trino.Clusters
 	config      *config.TrinoConfig
 	version     string
 	oauthServer *oauth.Server // oauth-mcp-proxy Server (nil if OAuth disabled)
+	interceptor *interceptor  // Answers the requests mcp-go does not dispatch
+	rateLimiter *rateLimiter  // Per-client limits in HTTP mode
+	quota       *quotaStore   // Per-client daily quotas on query tools
+	reloadMu    sync.Mutex    // Serializes reloads by SIGHUP and admin_reload_config
 }
 
 // NewServer creates a new MCP server instance with all components
-func NewServer(trinoClient *trino.Client, trinoConfig *config.TrinoConfig, version string) *Server {
-	mcpServer, oauthServer := createMCPServer(trinoClient, trinoConfig, version)
-
-	return &Server{
-		mcpServer:   mcpServer,
+func NewServer(clusters *trino.Clusters, trinoConfig *config.TrinoConfig, version string) *Server {
+	s := &Server{
+		clusters:    clusters,
 		config:      trinoConfig,
 		version:     version,
-		oauthServer: oauthServer,
+		rateLimiter: newRateLimiter(trinoConfig),
+		quota:       newQuotaStore(trinoConfig),
+	}
+	s.mcpServer, s.oauthServer, s.interceptor = createMCPServer(clusters, trinoConfig, version, s.quota, s.Reload)
+	return s
+}
+
+// Reload re-reads the reloadable configuration and applies it to the
+// clusters and the server; on failure the current settings are kept
+func (s *Server) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	reloaded, err := s.config.Reload()
+	if err != nil {
+		return err
+	}
+	if err := s.clusters.ApplyPolicy(reloaded); err != nil {
+		return err
 	}
+	s.ApplyPolicy(reloaded)
+	return nil
+}
+
+// ApplyPolicy updates the per-client rate limits and quotas after a configuration reload
+func (s *Server) ApplyPolicy(cfg *config.TrinoConfig) {
+	s.rateLimiter.setLimits(cfg)
+	s.quota.setLimits(cfg)
 }
 
-func createMCPServer(trinoClient *trino.Client, trinoConfig *config.TrinoConfig, version string) (*mcpserver.MCPServer, *oauth.Server) {
-	options := []mcpserver.ServerOption{mcpserver.WithToolCapabilities(true)}
+func createMCPServer(clusters *trino.Clusters, trinoConfig *config.TrinoConfig, version string, quota *quotaStore, reload func() error) (*mcpserver.MCPServer, *oauth.Server, *interceptor) {
+	canceller := newRequestCanceller()
+	hooks := &mcpserver.Hooks{}
+	hooks.AddBeforeCallTool(canceller.beforeCallTool)
+
+	// Subscriptions to table schema resources, polled for column changes
+	var subs *subscriptions
+	if trinoConfig.SchemaPollInterval > 0 {
+		subs = &subscriptions{tables: make(map[watchKey]*watchedTable)}
+		hooks.AddOnUnregisterSession(subs.unregisterSession)
+	}
+
+	options := []mcpserver.ServerOption{
+		mcpserver.WithToolCapabilities(true),
+		// The table schema resources are always served; subscribing to them needs the poll
+		mcpserver.WithResourceCapabilities(subs != nil, false),
+		mcpserver.WithHooks(hooks),
+		mcpserver.WithToolHandlerMiddleware(canceller.middleware),
+		mcpserver.WithToolHandlerMiddleware(tracingMiddleware),
+	}
+
+	// Completions, resource reads and subscriptions are authenticated and
+	// impersonated like tool calls
+	var authMiddlewares []mcpserver.ToolHandlerMiddleware
 
 	var oauthServer *oauth.Server
 	if trinoConfig.OAuthEnabled {
@@ -50,21 +101,57 @@ func createMCPServer(trinoClient *trino.Client, trinoConfig *config.TrinoConfig,
 			log.Printf("ERROR: Failed to create OAuth server: %v", err)
 		} else {
 			options = append(options, mcpserver.WithToolHandlerMiddleware(oauthServer.Middleware()))
+			authMiddlewares = append(authMiddlewares, oauthServer.Middleware())
 			log.Printf("INFO: OAuth enabled with provider: %s, mode: %s", trinoConfig.OAuthProvider, trinoConfig.OAuthMode)
 		}
 	}
 
+	// Inside the OAuth middleware, which authenticates the user to impersonate
+	if trinoConfig.EnableImpersonation {
+		options = append(options, mcpserver.WithToolHandlerMiddleware(impersonationMiddleware(trinoConfig)))
+		authMiddlewares = append(authMiddlewares, impersonationMiddleware(trinoConfig))
+	}
+
+	// Inside the OAuth middleware, which authenticates admin users
+	if trinoConfig.AdminEnabled || trinoConfig.DebugEnabled {
+		options = append(options, mcpserver.WithToolHandlerMiddleware(adminMiddleware(trinoConfig)))
+	}
+
+	// Inside the OAuth middleware, so logged calls name the authenticated user
+	options = append(options, mcpserver.WithToolHandlerMiddleware(debugLogMiddleware))
+
+	// Inside the OAuth middleware, so quotas can fall back to the authenticated user
+	options = append(options, mcpserver.WithToolHandlerMiddleware(quota.middleware))
+
 	mcpServer := mcpserver.NewMCPServer("Trino MCP Server", version, options...)
+	mcpServer.AddNotificationHandler(methodNotificationCancelled, canceller.handleCancelled)
 
-	trinoHandlers := NewTrinoHandlers(trinoClient, trinoConfig)
+	trinoHandlers := NewTrinoHandlers(clusters, trinoConfig)
+	trinoHandlers.quota = quota
+	trinoHandlers.reload = reload
 	RegisterTrinoTools(mcpServer, trinoHandlers)
 
-	return mcpServer, oauthServer
+	authenticate := newAuthenticator(authMiddlewares...)
+	registerTableResources(mcpServer, trinoHandlers, authenticate)
+	if subs != nil {
+		subs.server, subs.handlers, subs.authenticate = mcpServer, trinoHandlers, authenticate
+		go subs.watch(trinoConfig.SchemaPollInterval)
+	}
+
+	return mcpServer, oauthServer, &interceptor{
+		completer:     &completer{handlers: trinoHandlers, authenticate: authenticate},
+		subscriptions: subs,
+	}
 }
 
 // ServeStdio starts the MCP server with STDIO transport
 func (s *Server) ServeStdio() error {
-	return mcpserver.ServeStdio(s.mcpServer)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	stdout := &stdioWriter{w: os.Stdout}
+	stdin := s.interceptor.filterStdio(ctx, os.Stdin, stdout)
+	return mcpserver.NewStdioServer(s.mcpServer).Listen(ctx, stdin, stdout)
 }
 
 // ServeHTTP starts the MCP server with HTTP transport
@@ -91,6 +178,10 @@ func (s *Server) ServeHTTP(port string) error {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/status", s.handleStatus)
+	if s.config.DebugEnabled {
+		registerDebugHandlers(mux, s.config.DebugToken)
+		log.Println("INFO: Debug endpoints enabled at /debug/pprof/")
+	}
 
 	if s.config.OAuthEnabled && s.oauthServer != nil {
 		s.oauthServer.RegisterHandlers(mux)
@@ -171,7 +262,7 @@ func (s *Server) createMCPHandler(streamableServer *mcpserver.StreamableHTTPServ
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+adminKeyHeader)
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -210,15 +301,52 @@ func (s *Server) createMCPHandler(streamableServer *mcpserver.StreamableHTTPServ
 			r = r.WithContext(ctx)
 		}
 
-		streamableServer.ServeHTTP(w, r)
+		client := s.rateLimitKey(r.Context(), r)
+		if s.rateLimiter.enabled() && !s.rateLimiter.allow(w, r, client) {
+			log.Printf("MCP %s %s from %s rejected: rate limit exceeded", r.Method, r.URL.Path, r.RemoteAddr)
+			return
+		}
+		r = r.WithContext(withAdminKey(withClientKey(r.Context(), client), r.Header.Get(adminKeyHeader)))
+
+		s.interceptor.serveHTTP(w, r, streamableServer)
 	}
 }
 
-// handleStatus handles the status endpoint
+// serverStatus is the response of the status endpoint
+type serverStatus struct {
+	Status   string          `json:"status"` // "degraded" when a cluster's last ping failed
+	Version  string          `json:"version"`
+	Clusters []clusterStatus `json:"clusters,omitempty"`
+}
+
+// clusterStatus is the warm-up and keepalive ping status of a cluster
+type clusterStatus struct {
+	Name      string     `json:"name"`
+	LastPing  *time.Time `json:"lastPing,omitempty"`
+	PingError string     `json:"pingError,omitempty"`
+}
+
+// handleStatus handles the status endpoint. It answers 200 even when Trino
+// is failing, so that liveness probes do not restart the server over it;
+// with pings configured, the body reports each cluster's last ping.
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status := serverStatus{Status: "ok", Version: s.version}
+	if s.clusters != nil && (s.config.WarmUp || s.config.KeepaliveInterval > 0) {
+		for _, cl := range s.clusters.List() {
+			clStatus := cl.Status()
+			entry := clusterStatus{Name: cl.Name, PingError: clStatus.PingError}
+			if !clStatus.PingedAt.IsZero() {
+				entry.LastPing = &clStatus.PingedAt
+			}
+			if entry.PingError != "" {
+				status.Status = "degraded"
+			}
+			status.Clusters = append(status.Clusters, entry)
+		}
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_, _ = fmt.Fprintf(w, `{"status":"ok","version":"%s"}`, s.version)
+	_ = json.NewEncoder(w).Encode(status)
 }
 
 // handleSignals handles graceful shutdown signals
@@ -254,7 +382,6 @@ func (s *Server) getOAuthStatusWithWarning() string {
 	return " (OAuth disabled)"
 }
 
-
 func trinoConfigToOAuthConfig(cfg *config.TrinoConfig) *oauth.Config {
 	serverURL := getEnv("MCP_URL", "")
 	if serverURL == "" {
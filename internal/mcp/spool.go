@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// spool collects encoded output in memory and moves it to a temporary file
+// once it grows beyond limit bytes (0 never spills), so the memory held while
+// a large result is read from Trino stays bounded. Buffered bytes are taken
+// from the shared budget; when it runs out the spool spills early, or fails
+// when spilling is disabled.
+type spool struct {
+	limit    int64
+	budget   *memoryBudget
+	reserved int64 // Bytes of buf taken from budget
+	buf      bytes.Buffer
+	file     *os.File
+	size     int64
+}
+
+func (s *spool) Write(p []byte) (int, error) {
+	if !s.budget.fits(s.size + int64(len(p))) {
+		return 0, resultTooLarge("it exceeds the server's memory budget for results of %d bytes (TRINO_RESULT_MEMORY_BUDGET)", s.budget.limit)
+	}
+	if s.file == nil && s.limit > 0 && int64(s.buf.Len()+len(p)) > s.limit {
+		if err := s.spill(); err != nil {
+			return 0, err
+		}
+	}
+	if s.file == nil && !s.budget.reserve(int64(len(p))) {
+		if s.limit == 0 {
+			return 0, resultTooLarge("the server's memory budget for results (TRINO_RESULT_MEMORY_BUDGET) is in use by other queries")
+		}
+		if err := s.spill(); err != nil {
+			return 0, err
+		}
+	}
+	if s.file == nil {
+		n, _ := s.buf.Write(p)
+		s.size += int64(n)
+		s.reserved += int64(len(p))
+		return n, nil
+	}
+	n, err := s.file.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+// spill moves the buffered output to a new temporary file
+func (s *spool) spill() error {
+	f, err := os.CreateTemp("", "mcp-trino-result-*")
+	if err != nil {
+		return fmt.Errorf("failed to create spill file: %w", err)
+	}
+	s.file = f
+	if _, err := s.buf.WriteTo(f); err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+	s.buf = bytes.Buffer{}
+	s.budget.release(s.reserved)
+	s.reserved = 0
+	return nil
+}
+
+// Len returns the number of bytes written
+func (s *spool) Len() int64 {
+	return s.size
+}
+
+// Spilled reports whether the output moved to a temporary file
+func (s *spool) Spilled() bool {
+	return s.file != nil
+}
+
+// WriteTo copies the output written so far to w
+func (s *spool) WriteTo(w io.Writer) (int64, error) {
+	if s.file == nil {
+		return io.Copy(w, bytes.NewReader(s.buf.Bytes()))
+	}
+	return io.Copy(w, io.NewSectionReader(s.file, 0, s.size))
+}
+
+// Close discards the output, removing the temporary file
+func (s *spool) Close() error {
+	s.buf = bytes.Buffer{}
+	s.size = 0
+	s.budget.release(s.reserved)
+	s.reserved = 0
+	if s.file == nil {
+		return nil
+	}
+	f := s.file
+	s.file = nil
+	closeErr := f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	return closeErr
+}
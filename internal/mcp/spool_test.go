@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpoolSpillsBeyondLimit(t *testing.T) {
+	s := &spool{limit: 8}
+	if _, err := s.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if s.Spilled() {
+		t.Fatal("spool spilled below its limit")
+	}
+	if _, err := s.Write([]byte(", world")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !s.Spilled() {
+		t.Fatal("spool did not spill beyond its limit")
+	}
+	name := s.file.Name()
+	if s.buf.Cap() != 0 {
+		t.Errorf("spool kept %d bytes of buffer after spilling", s.buf.Cap())
+	}
+
+	var sb strings.Builder
+	if _, err := s.WriteTo(&sb); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if sb.String() != "hello, world" || s.Len() != int64(len("hello, world")) {
+		t.Errorf("spool content = %q (len %d), want %q", sb.String(), s.Len(), "hello, world")
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("spill file %s still exists after Close(): %v", name, err)
+	}
+}
+
+func TestSpoolWithoutLimitStaysInMemory(t *testing.T) {
+	s := &spool{}
+	defer func() { _ = s.Close() }()
+	if _, err := s.Write([]byte(strings.Repeat("x", 1<<16))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if s.Spilled() {
+		t.Error("spool without a limit should never spill")
+	}
+}
@@ -0,0 +1,230 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+const (
+	// methodResourcesSubscribe and methodResourcesUnsubscribe are sent by
+	// clients to start and stop resources/updated notifications of a resource.
+	// mcp-go does not dispatch them, so the interceptor answers them.
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+
+	// schemaPollTimeout bounds the column reads of one poll of a Trino user's tables
+	schemaPollTimeout = 30 * time.Second
+)
+
+// subscriptions tracks the table schema resources clients subscribed to,
+// and notifies the subscribed sessions when a poll of information_schema
+// finds that a table's columns changed, so that long-lived sessions do not
+// keep working with a stale schema
+type subscriptions struct {
+	server       *server.MCPServer
+	handlers     *TrinoHandlers
+	authenticate authenticator
+
+	mu     sync.Mutex
+	tables map[watchKey]*watchedTable
+}
+
+// watchKey identifies a table resource as a Trino user reads it; users may
+// see different columns
+type watchKey struct {
+	uri  string
+	user string // Impersonated Trino user, "" for the configured one
+}
+
+// watchedTable is a subscribed table resource
+type watchedTable struct {
+	columns  string          // Column names and types when last read
+	sessions map[string]bool // Sessions subscribed
+}
+
+// handle answers a resources/subscribe or resources/unsubscribe request
+func (s *subscriptions) handle(ctx context.Context, sessionID, method string, id mcp.RequestId, message json.RawMessage) mcp.JSONRPCMessage {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(message, &params); err != nil || params.URI == "" {
+		return mcp.NewJSONRPCError(id, mcp.INVALID_PARAMS, "uri parameter is required", nil)
+	}
+	if sessionID == "" {
+		return mcp.NewJSONRPCError(id, mcp.INVALID_REQUEST, "resource subscriptions need a session: send the "+server.HeaderKeySessionID+" header", nil)
+	}
+	ctx, err := s.authenticate(ctx, method)
+	if err != nil {
+		return mcp.NewJSONRPCError(id, mcp.INVALID_REQUEST, err.Error(), nil)
+	}
+
+	if method == methodResourcesUnsubscribe {
+		s.unsubscribe(sessionID, params.URI)
+	} else if err := s.subscribe(ctx, sessionID, params.URI); err != nil {
+		return mcp.NewJSONRPCError(id, mcp.INVALID_PARAMS, err.Error(), nil)
+	}
+	return mcp.NewJSONRPCResultResponse(id, mcp.EmptyResult{})
+}
+
+// subscribe reads the columns of a table resource, which must exist and be
+// within the allowlists, and adds the session to its subscribers
+func (s *subscriptions) subscribe(ctx context.Context, sessionID, uri string) error {
+	ref, err := parseTableResourceURI(uri)
+	if err != nil {
+		return err
+	}
+	schemas, err := s.handlers.readTableSchemas(ctx, []trino.TableRef{ref})
+	if err != nil {
+		return err
+	}
+	if schemas[0].Err != nil {
+		return schemas[0].Err
+	}
+
+	user, _ := trino.GetImpersonatedUser(ctx)
+	key := watchKey{uri: uri, user: user}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	table, ok := s.tables[key]
+	if !ok {
+		table = &watchedTable{columns: columnsFingerprint(schemas[0].Columns), sessions: make(map[string]bool)}
+		s.tables[key] = table
+	}
+	table.sessions[sessionID] = true
+	log.Printf("Session %s subscribed to %s", sessionID, uri)
+	return nil
+}
+
+// unsubscribe removes the session from the subscribers of a table resource
+func (s *subscriptions) unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, table := range s.tables {
+		if key.uri == uri {
+			s.drop(key, table, sessionID)
+		}
+	}
+}
+
+// unregisterSession drops the subscriptions of a session that ended
+func (s *subscriptions) unregisterSession(ctx context.Context, session server.ClientSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, table := range s.tables {
+		s.drop(key, table, session.SessionID())
+	}
+}
+
+// drop removes a subscriber of a table, and the table once it has none; the
+// caller holds s.mu
+func (s *subscriptions) drop(key watchKey, table *watchedTable, sessionID string) {
+	delete(table.sessions, sessionID)
+	if len(table.sessions) == 0 && s.tables[key] == table {
+		delete(s.tables, key)
+	}
+}
+
+// watch polls the subscribed tables at each interval, forever
+func (s *subscriptions) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.poll()
+	}
+}
+
+// poll reads the columns of every subscribed table, at most
+// trino.MaxSchemaTables tables of a Trino user per query, and notifies the
+// subscribers of the tables whose columns changed
+func (s *subscriptions) poll() {
+	s.mu.Lock()
+	byUser := make(map[string][]string)
+	for key := range s.tables {
+		byUser[key.user] = append(byUser[key.user], key.uri)
+	}
+	s.mu.Unlock()
+
+	for user, uris := range byUser {
+		for start := 0; start < len(uris); start += trino.MaxSchemaTables {
+			s.pollTables(user, uris[start:min(start+trino.MaxSchemaTables, len(uris))])
+		}
+	}
+}
+
+// pollTables reads the columns of table resources as a Trino user. A table
+// that was dropped or left the allowlists counts as changed.
+func (s *subscriptions) pollTables(user string, uris []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), schemaPollTimeout)
+	defer cancel()
+	if user != "" {
+		ctx = trino.WithImpersonatedUser(ctx, user)
+	}
+
+	refs := make([]trino.TableRef, len(uris))
+	for i, uri := range uris {
+		refs[i], _ = parseTableResourceURI(uri) // Parsed when subscribed
+	}
+	schemas, err := s.handlers.readTableSchemas(ctx, refs)
+	if err != nil {
+		log.Printf("WARNING: Failed to poll %d subscribed tables: %v", len(uris), err)
+		return
+	}
+	for i, schema := range schemas {
+		var columns string
+		if schema.Err == nil {
+			columns = columnsFingerprint(schema.Columns)
+		}
+		s.update(watchKey{uri: uris[i], user: user}, columns)
+	}
+}
+
+// update records the columns of a table resource, and notifies its
+// subscribers if they changed
+func (s *subscriptions) update(key watchKey, columns string) {
+	s.mu.Lock()
+	table, ok := s.tables[key]
+	if !ok || table.columns == columns {
+		s.mu.Unlock()
+		return
+	}
+	table.columns = columns
+	sessions := make([]string, 0, len(table.sessions))
+	for sessionID := range table.sessions {
+		sessions = append(sessions, sessionID)
+	}
+	s.mu.Unlock()
+
+	log.Printf("Columns of %s changed, notifying %d sessions", key.uri, len(sessions))
+	for _, sessionID := range sessions {
+		err := s.server.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": key.uri})
+		switch {
+		case errors.Is(err, server.ErrSessionNotFound):
+			s.mu.Lock()
+			s.drop(key, table, sessionID)
+			s.mu.Unlock()
+		case err != nil:
+			log.Printf("WARNING: Failed to notify session %s of %s: %v", sessionID, key.uri, err)
+		}
+	}
+}
+
+// columnsFingerprint sums up the column names and types of a table, "" for
+// a table that could not be read
+func columnsFingerprint(columns []map[string]interface{}) string {
+	var b strings.Builder
+	for _, column := range columns {
+		name, _ := column["Column"].(string)
+		typ, _ := column["Type"].(string)
+		b.WriteString(name + " " + typ + "\n")
+	}
+	return b.String()
+}
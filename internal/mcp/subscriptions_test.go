@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+const ordersColumnsQuery = `SELECT table_schema, table_name, column_name, data_type FROM "hive".information_schema.columns` +
+	` WHERE table_schema IN ('sales') AND table_name IN ('orders') ORDER BY table_schema, table_name, ordinal_position`
+
+// columnRows returns an information_schema.columns result of hive.sales.orders
+func columnRows(columns ...string) trinotest.Result {
+	result := trinotest.Result{Columns: []trinotest.Column{
+		{Name: "table_schema", Type: "varchar"}, {Name: "table_name", Type: "varchar"},
+		{Name: "column_name", Type: "varchar"}, {Name: "data_type", Type: "varchar"},
+	}}
+	for _, column := range columns {
+		result.Rows = append(result.Rows, []any{"sales", "orders", column, "bigint"})
+	}
+	return result
+}
+
+// newMockHandlers returns handlers of a single cluster served by a mock Trino
+func newMockHandlers(t *testing.T, trinoServer *trinotest.Server) *TrinoHandlers {
+	t.Helper()
+	host, port := trinoServer.HostPort()
+	return newTestHandlers(t, &config.TrinoConfig{
+		Host: host, Port: port, Scheme: "http", User: "mcp", Catalog: "hive", Schema: "sales",
+		QueryTimeout: 30 * time.Second, DefaultCluster: config.DefaultClusterName,
+	})
+}
+
+// testSession is a client session collecting its notifications
+type testSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()       {}
+func (s *testSession) Initialized() bool { return true }
+func (s *testSession) SessionID() string { return s.id }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func TestSubscriptions(t *testing.T) {
+	trinoServer := trinotest.NewServer()
+	defer trinoServer.Close()
+	trinoServer.Handle(ordersColumnsQuery, columnRows("order_id", "amount"))
+
+	mcpServer := server.NewMCPServer("test", "dev", server.WithResourceCapabilities(true, false))
+	session := &testSession{id: "s1", notifications: make(chan mcp.JSONRPCNotification, 1)}
+	if err := mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatalf("RegisterSession() error = %v", err)
+	}
+	subs := &subscriptions{
+		server:       mcpServer,
+		handlers:     newMockHandlers(t, trinoServer),
+		authenticate: newAuthenticator(),
+		tables:       make(map[watchKey]*watchedTable),
+	}
+
+	uri := json.RawMessage(`{"uri":"trino://hive/sales/orders"}`)
+	if response := subs.handle(context.Background(), "s1", methodResourcesSubscribe, mcp.NewRequestId(1), uri); !isResult(response) {
+		t.Fatalf("subscribe = %+v", response)
+	}
+	if response := subs.handle(context.Background(), "s1", methodResourcesSubscribe, mcp.NewRequestId(2), json.RawMessage(`{"uri":"trino://hive/sales"}`)); isResult(response) {
+		t.Error("subscribing to an invalid URI succeeded")
+	}
+
+	// Unchanged columns notify no one
+	subs.poll()
+	select {
+	case n := <-session.notifications:
+		t.Fatalf("notification %+v without a change", n)
+	default:
+	}
+
+	trinoServer.Handle(ordersColumnsQuery, columnRows("order_id", "amount", "currency"))
+	subs.poll()
+	select {
+	case n := <-session.notifications:
+		if n.Method != mcp.MethodNotificationResourceUpdated || n.Params.AdditionalFields["uri"] != "trino://hive/sales/orders" {
+			t.Errorf("notification = %+v", n)
+		}
+	default:
+		t.Fatal("no notification after a column was added")
+	}
+
+	if response := subs.handle(context.Background(), "s1", methodResourcesUnsubscribe, mcp.NewRequestId(3), uri); !isResult(response) {
+		t.Fatalf("unsubscribe = %+v", response)
+	}
+	if len(subs.tables) != 0 {
+		t.Errorf("%d tables still watched after unsubscribing", len(subs.tables))
+	}
+}
+
+func TestSubscribeMissingTable(t *testing.T) {
+	trinoServer := trinotest.NewServer()
+	defer trinoServer.Close()
+	trinoServer.Handle(ordersColumnsQuery, columnRows())
+	subs := &subscriptions{
+		handlers:     newMockHandlers(t, trinoServer),
+		authenticate: newAuthenticator(),
+		tables:       make(map[watchKey]*watchedTable),
+	}
+
+	response := subs.handle(context.Background(), "s1", methodResourcesSubscribe, mcp.NewRequestId(1), json.RawMessage(`{"uri":"trino://hive/sales/orders"}`))
+	rpcErr, ok := response.(mcp.JSONRPCError)
+	if !ok || !strings.Contains(rpcErr.Error.Message, "does not exist") {
+		t.Errorf("subscribe = %+v, want a missing table error", response)
+	}
+	if response := subs.handle(context.Background(), "", methodResourcesSubscribe, mcp.NewRequestId(2), json.RawMessage(`{"uri":"trino://hive/sales/orders"}`)); isResult(response) {
+		t.Error("subscribing without a session succeeded")
+	}
+}
+
+func isResult(message mcp.JSONRPCMessage) bool {
+	_, ok := message.(mcp.JSONRPCResponse)
+	return ok
+}
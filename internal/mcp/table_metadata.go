@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/datacatalog"
+)
+
+// describedTable is get_table_schema output: the table's comment and data
+// catalog metadata around its columns
+type describedTable struct {
+	Table       string                   `json:"table"`
+	Comment     string                   `json:"comment,omitempty"` // Table comment in Trino
+	Description string                   `json:"description,omitempty"`
+	Owners      []string                 `json:"owners,omitempty"`
+	Tags        []string                 `json:"tags,omitempty"`
+	Columns     []map[string]interface{} `json:"columns"`
+}
+
+// tableSchemas is get_table_schemas output. Tables that could not be
+// described are left out of Tables and listed in Errors with the reason.
+type tableSchemas struct {
+	Tables []describedTable  `json:"tables"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// listedTable is a list_tables entry when comments or data catalog metadata
+// are included
+type listedTable struct {
+	Name        string   `json:"name"`
+	Comment     string   `json:"comment,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Owners      []string `json:"owners,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// describeTable puts a table comment and data catalog metadata around
+// DESCRIBE rows, adding Description and Tags to the columns the catalog
+// documents
+func describeTable(table, comment string, rows []map[string]interface{}, metadata *datacatalog.TableMetadata) describedTable {
+	described := describedTable{Table: table, Comment: comment, Columns: rows}
+	if described.Columns == nil {
+		described.Columns = []map[string]interface{}{}
+	}
+	if metadata == nil {
+		return described
+	}
+	described.Description = metadata.Description
+	described.Owners = metadata.Owners
+	described.Tags = metadata.Tags
+	for _, row := range rows {
+		name, _ := row["Column"].(string)
+		column, ok := metadata.Columns[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		if column.Description != "" {
+			row["Description"] = column.Description
+		}
+		if len(column.Tags) > 0 {
+			row["Tags"] = column.Tags
+		}
+	}
+	return described
+}
+
+// listTables pairs table names with their comments and data catalog metadata
+func listTables(tables []string, comments map[string]string, metadata map[string]*datacatalog.TableMetadata) []listedTable {
+	listed := make([]listedTable, len(tables))
+	for i, table := range tables {
+		listed[i].Name = table
+		listed[i].Comment = comments[table]
+		if m := metadata[table]; m != nil {
+			listed[i].Description = m.Description
+			listed[i].Owners = m.Owners
+			listed[i].Tags = m.Tags
+		}
+	}
+	return listed
+}
@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/datacatalog"
+)
+
+func TestDescribeTable(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"Column": "order_id", "Type": "bigint", "Extra": "", "Comment": ""},
+		{"Column": "Email", "Type": "varchar", "Extra": "", "Comment": "contact"},
+	}
+	metadata := &datacatalog.TableMetadata{
+		Description: "One row per order",
+		Owners:      []string{"jdoe"},
+		Columns: map[string]datacatalog.ColumnMetadata{
+			"email":   {Description: "Customer email", Tags: []string{"PII"}},
+			"dropped": {Description: "Not in the table"},
+		},
+	}
+
+	got := describeTable("hive.sales.orders", "Orders fact table", rows, metadata)
+	if got.Table != "hive.sales.orders" || got.Comment != "Orders fact table" || got.Description != "One row per order" || !reflect.DeepEqual(got.Owners, []string{"jdoe"}) {
+		t.Errorf("describeTable() = %+v", got)
+	}
+	if _, ok := got.Columns[0]["Description"]; ok {
+		t.Errorf("undocumented column = %v, want no Description", got.Columns[0])
+	}
+	if got.Columns[1]["Description"] != "Customer email" || !reflect.DeepEqual(got.Columns[1]["Tags"], []string{"PII"}) {
+		t.Errorf("documented column = %v, want the catalog description and tags", got.Columns[1])
+	}
+	if len(got.Columns) != 2 {
+		t.Errorf("describeTable() has %d columns, want 2", len(got.Columns))
+	}
+
+	if got := describeTable("hive.sales.orders", "", nil, nil); got.Columns == nil || got.Description != "" {
+		t.Errorf("describeTable() without metadata = %+v", got)
+	}
+}
+
+func TestListTables(t *testing.T) {
+	got := listTables([]string{"orders", "returns", "region"},
+		map[string]string{"orders": "Orders fact table", "returns": "Returned items"},
+		map[string]*datacatalog.TableMetadata{"orders": {Description: "One row per order", Tags: []string{"finance"}}})
+	want := []listedTable{
+		{Name: "orders", Comment: "Orders fact table", Description: "One row per order", Tags: []string{"finance"}},
+		{Name: "returns", Comment: "Returned items"},
+		{Name: "region"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listTables() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,23 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceToken(t *testing.T) {
+	if token := TraceToken(context.Background()); token != "" {
+		t.Errorf("TraceToken() without span = %q, want empty", token)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	if token := TraceToken(ctx); token != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceToken() = %q, want trace ID", token)
+	}
+}
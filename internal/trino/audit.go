@@ -0,0 +1,324 @@
+package trino
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+const (
+	// auditBufferSize bounds the events waiting to be written; more are dropped
+	auditBufferSize = 1000
+	// auditBatchSize is the most events written at once
+	auditBatchSize = 100
+	// auditFlushInterval is how long events wait for a batch to fill up
+	auditFlushInterval = 5 * time.Second
+	// auditWriteTimeout bounds each write of a batch to a sink
+	auditWriteTimeout = 30 * time.Second
+	// maxAuditQueryLength bounds the query text of an event
+	maxAuditQueryLength = 10000
+)
+
+// Outcomes of audited queries
+const (
+	auditFinished = "finished" // The query ran to completion
+	auditFailed   = "failed"   // Trino failed the query
+	auditRejected = "rejected" // A query policy of the server rejected it before it ran
+)
+
+// auditEvent records a query the server ran or rejected, for
+// TRINO_AUDIT_TABLE and TRINO_AUDIT_KAFKA_TOPIC
+type auditEvent struct {
+	Time          time.Time `json:"time"`
+	Cluster       string    `json:"cluster"`
+	User          string    `json:"user,omitempty"` // Authenticated OAuth user
+	TrinoUser     string    `json:"trinoUser"`      // User the query ran as in Trino
+	QueryID       string    `json:"queryId,omitempty"`
+	StatementType string    `json:"statementType"`
+	Outcome       string    `json:"outcome"`
+	Error         string    `json:"error,omitempty"`
+	ElapsedMillis int64     `json:"elapsedMillis"`
+	Rows          int64     `json:"rows"`
+	Query         string    `json:"query"`
+}
+
+// auditSink is a destination of audit events
+type auditSink interface {
+	name() string
+	write(ctx context.Context, events []auditEvent) error
+}
+
+// queryAuditor writes an event for every query to the configured sinks, so
+// that query governance data lands in the same lake it governs. Events are
+// written in batches in the background; queries never wait for a sink, and
+// events are dropped with a warning when the sinks fall behind.
+type queryAuditor struct {
+	events chan auditEvent
+	sinks  []auditSink
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// newQueryAuditor returns an auditor for cfg, or nil if neither
+// TRINO_AUDIT_TABLE nor TRINO_AUDIT_KAFKA_TOPIC is set
+func newQueryAuditor(cfg *config.TrinoConfig) *queryAuditor {
+	var sinks []auditSink
+	if cfg.AuditTable != "" {
+		sinks = append(sinks, &tableSink{table: cfg.AuditTable, config: cfg.ForAuditWriter()})
+	}
+	if cfg.AuditKafkaTopic != "" {
+		sinks = append(sinks, &kafkaSink{
+			url:        cfg.AuditKafkaRESTURL + "/topics/" + url.PathEscape(cfg.AuditKafkaTopic),
+			topic:      cfg.AuditKafkaTopic,
+			httpClient: &http.Client{Timeout: auditWriteTimeout},
+		})
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	a := &queryAuditor{
+		events: make(chan auditEvent, auditBufferSize),
+		sinks:  sinks,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// record queues an event without waiting
+func (a *queryAuditor) record(event auditEvent) {
+	select {
+	case <-a.stop:
+		log.Printf("WARNING: Audit event of query %s dropped: the auditor is closed", event.QueryID)
+	case a.events <- event:
+	default:
+		log.Printf("WARNING: Audit event of query %s dropped: %d events are waiting to be written", event.QueryID, auditBufferSize)
+	}
+}
+
+// run writes queued events in batches until the auditor is closed, then
+// writes the remaining ones
+func (a *queryAuditor) run() {
+	defer close(a.done)
+	ticker := time.NewTicker(auditFlushInterval)
+	defer ticker.Stop()
+
+	var batch []auditEvent
+	for {
+		select {
+		case event := <-a.events:
+			if batch = append(batch, event); len(batch) >= auditBatchSize {
+				a.write(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			a.write(batch)
+			batch = nil
+		case <-a.stop:
+			for {
+				select {
+				case event := <-a.events:
+					batch = append(batch, event)
+				default:
+					for len(batch) > 0 {
+						n := min(len(batch), auditBatchSize)
+						a.write(batch[:n])
+						batch = batch[n:]
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// write writes a batch to every sink; failures are logged, never retried
+func (a *queryAuditor) write(batch []auditEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	for _, sink := range a.sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		if err := sink.write(ctx, batch); err != nil {
+			log.Printf("WARNING: Failed to write %d audit events to %s: %v", len(batch), sink.name(), err)
+		}
+		cancel()
+	}
+}
+
+// close writes the queued events and stops the auditor
+func (a *queryAuditor) close() error {
+	a.once.Do(func() { close(a.stop) })
+	<-a.done
+	var errs []error
+	for _, sink := range a.sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// tableSink inserts events into a Trino table through a dedicated connection,
+// which is opened on first use and reopened after a failed write
+type tableSink struct {
+	table  string
+	config *config.TrinoConfig
+	writer *Client
+}
+
+func (s *tableSink) name() string {
+	return "Trino table " + s.table
+}
+
+func (s *tableSink) write(ctx context.Context, events []auditEvent) error {
+	if s.writer == nil {
+		writer, err := NewClient(s.config)
+		if err != nil {
+			return err
+		}
+		s.writer = writer
+	}
+	db, err := s.writer.ensureConnected(ctx)
+	if err == nil {
+		_, err = db.ExecContext(ctx, auditInsert(s.table, events))
+	}
+	if err != nil {
+		_ = s.writer.Close()
+		s.writer = nil
+	}
+	return err
+}
+
+func (s *tableSink) Close() error {
+	if s.writer == nil {
+		return nil
+	}
+	return s.writer.Close()
+}
+
+// auditInsert returns the INSERT statement that writes events into table
+func auditInsert(table string, events []auditEvent) string {
+	optional := func(value string) string {
+		if value == "" {
+			return "NULL"
+		}
+		return quoteLiteral(value)
+	}
+	parts := strings.SplitN(table, ".", 3)
+	rows := make([]string, len(events))
+	for i, e := range events {
+		rows[i] = fmt.Sprintf("(TIMESTAMP '%s', %s, %s, %s, %s, %s, %s, %s, %d, %d, %s)",
+			e.Time.UTC().Format("2006-01-02 15:04:05.000 UTC"), quoteLiteral(e.Cluster), optional(e.User), quoteLiteral(e.TrinoUser),
+			optional(e.QueryID), quoteLiteral(e.StatementType), quoteLiteral(e.Outcome), optional(e.Error),
+			e.ElapsedMillis, e.Rows, quoteLiteral(e.Query))
+	}
+	return "INSERT INTO " + quoteTable(TableRef{Catalog: parts[0], Schema: parts[1], Table: parts[2]}) +
+		" (event_time, cluster, mcp_user, trino_user, query_id, statement_type, outcome, error, elapsed_ms, row_count, query)" +
+		" VALUES " + strings.Join(rows, ", ")
+}
+
+// kafkaSink produces events to a Kafka topic through the Confluent REST Proxy
+// API, keyed by query ID
+type kafkaSink struct {
+	url        string
+	topic      string
+	httpClient *http.Client
+}
+
+func (s *kafkaSink) name() string {
+	return "Kafka topic " + s.topic
+}
+
+func (s *kafkaSink) write(ctx context.Context, events []auditEvent) error {
+	type record struct {
+		Key   string     `json:"key,omitempty"`
+		Value auditEvent `json:"value"`
+	}
+	records := make([]record, len(events))
+	for i, e := range events {
+		records[i] = record{Key: e.QueryID, Value: e}
+	}
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		// The URL may hold credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("REST Proxy returned HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// auditQuery records a query that ran, or that a query policy rejected when
+// started is zero
+func (c *Client) auditQuery(ctx context.Context, query string, tracker *queryTracker, started time.Time, rows int, err error) {
+	if c.auditor == nil {
+		return
+	}
+	cluster := c.config.ClusterName
+	if cluster == "" {
+		cluster = config.DefaultClusterName
+	}
+	event := auditEvent{
+		Time:          time.Now(),
+		Cluster:       cluster,
+		User:          getQueryUsername(ctx),
+		TrinoUser:     c.config.User,
+		StatementType: statementType(tokenizeSQL(query)),
+		Outcome:       auditFinished,
+		Rows:          int64(rows),
+		Query:         query,
+	}
+	if user, ok := GetImpersonatedUser(ctx); ok && user != "" {
+		event.TrinoUser = user
+	}
+	if tracker != nil {
+		event.QueryID = tracker.QueryID()
+	}
+	if !started.IsZero() {
+		event.Time = started
+		event.ElapsedMillis = time.Since(started).Milliseconds()
+	}
+	if len(event.Query) > maxAuditQueryLength {
+		event.Query = event.Query[:maxAuditQueryLength] + "…"
+	}
+	if err != nil {
+		event.Outcome = auditFailed
+		if started.IsZero() {
+			event.Outcome = auditRejected
+		}
+		event.Error = err.Error()
+	}
+	c.auditor.record(event)
+}
@@ -0,0 +1,199 @@
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// memorySink keeps the batches written to it
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]auditEvent
+}
+
+func (s *memorySink) name() string { return "memory" }
+
+func (s *memorySink) write(_ context.Context, events []auditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]auditEvent(nil), events...))
+	return nil
+}
+
+func newTestAuditor(sink auditSink) *queryAuditor {
+	a := &queryAuditor{
+		events: make(chan auditEvent, auditBufferSize),
+		sinks:  []auditSink{sink},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func TestNewQueryAuditor(t *testing.T) {
+	if a := newQueryAuditor(&config.TrinoConfig{}); a != nil {
+		t.Error("newQueryAuditor() without audit sinks should return nil")
+	}
+
+	a := newQueryAuditor(&config.TrinoConfig{
+		AuditTable:        "hive.governance.mcp_queries",
+		AuditKafkaRESTURL: "https://kafka-rest.example.com",
+		AuditKafkaTopic:   "mcp.audit",
+	})
+	if a == nil {
+		t.Fatal("newQueryAuditor() = nil")
+	}
+	defer func() { _ = a.close() }()
+	if len(a.sinks) != 2 || a.sinks[0].name() != "Trino table hive.governance.mcp_queries" || a.sinks[1].name() != "Kafka topic mcp.audit" {
+		t.Errorf("newQueryAuditor() sinks = %v", a.sinks)
+	}
+	if got := a.sinks[1].(*kafkaSink).url; got != "https://kafka-rest.example.com/topics/mcp.audit" {
+		t.Errorf("kafkaSink url = %q", got)
+	}
+}
+
+func TestQueryAuditorWritesOnClose(t *testing.T) {
+	sink := &memorySink{}
+	a := newTestAuditor(sink)
+	for i := 0; i < auditBatchSize+10; i++ {
+		a.record(auditEvent{QueryID: "q", Outcome: auditFinished})
+	}
+	if err := a.close(); err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+
+	total := 0
+	for _, batch := range sink.batches {
+		if len(batch) > auditBatchSize {
+			t.Errorf("batch of %d events, more than %d", len(batch), auditBatchSize)
+		}
+		total += len(batch)
+	}
+	if total != auditBatchSize+10 {
+		t.Errorf("wrote %d events, want %d", total, auditBatchSize+10)
+	}
+
+	// Events recorded after close are dropped, not blocked on
+	a.record(auditEvent{QueryID: "late"})
+	_ = a.close()
+}
+
+func TestAuditQuery(t *testing.T) {
+	sink := &memorySink{}
+	client := &Client{config: &config.TrinoConfig{User: "mcp", ClusterName: "analytics"}, auditor: newTestAuditor(sink)}
+
+	ctx := WithImpersonatedUser(context.Background(), "alice")
+	started := time.Now().Add(-2 * time.Second)
+	client.auditQuery(ctx, "SELECT * FROM orders", nil, started, 42, nil)
+	client.auditQuery(context.Background(), "DROP TABLE orders", nil, time.Time{}, 0, errors.New("write queries are disabled"))
+	client.auditQuery(context.Background(), "SELECT 1/0", nil, started, 0, errors.New("Division by zero"))
+	client.auditQuery(context.Background(), strings.Repeat("x", maxAuditQueryLength+1), nil, started, 0, nil)
+	_ = client.auditor.close()
+
+	var events []auditEvent
+	for _, batch := range sink.batches {
+		events = append(events, batch...)
+	}
+	if len(events) != 4 {
+		t.Fatalf("recorded %d events, want 4", len(events))
+	}
+
+	finished := events[0]
+	if finished.Outcome != auditFinished || finished.Rows != 42 || finished.StatementType != "select" ||
+		finished.Cluster != "analytics" || finished.TrinoUser != "alice" || !finished.Time.Equal(started) || finished.ElapsedMillis < 2000 {
+		t.Errorf("finished event = %+v", finished)
+	}
+	if rejected := events[1]; rejected.Outcome != auditRejected || rejected.Error != "write queries are disabled" || rejected.TrinoUser != "mcp" || rejected.ElapsedMillis != 0 {
+		t.Errorf("rejected event = %+v", rejected)
+	}
+	if failed := events[2]; failed.Outcome != auditFailed || failed.Error != "Division by zero" {
+		t.Errorf("failed event = %+v", failed)
+	}
+	if long := events[3]; len(long.Query) != maxAuditQueryLength+len("…") {
+		t.Errorf("long query recorded with %d bytes", len(long.Query))
+	}
+
+	// Without an auditor nothing is recorded
+	(&Client{config: &config.TrinoConfig{}}).auditQuery(context.Background(), "SELECT 1", nil, time.Now(), 1, nil)
+}
+
+func TestAuditInsert(t *testing.T) {
+	events := []auditEvent{
+		{
+			Time:          time.Date(2024, 1, 31, 12, 0, 0, 123000000, time.UTC),
+			Cluster:       "default",
+			User:          "alice@example.com",
+			TrinoUser:     "alice",
+			QueryID:       "20240131_120000_00001_abcde",
+			StatementType: "select",
+			Outcome:       auditFinished,
+			ElapsedMillis: 842,
+			Rows:          10,
+			Query:         "SELECT * FROM orders WHERE status = 'open'",
+		},
+		{
+			Time:          time.Date(2024, 1, 31, 12, 0, 1, 0, time.UTC),
+			Cluster:       "default",
+			TrinoUser:     "mcp",
+			StatementType: "drop",
+			Outcome:       auditRejected,
+			Error:         "write queries are disabled",
+			Query:         "DROP TABLE orders",
+		},
+	}
+	want := `INSERT INTO "hive"."governance"."mcp_queries"` +
+		` (event_time, cluster, mcp_user, trino_user, query_id, statement_type, outcome, error, elapsed_ms, row_count, query) VALUES ` +
+		`(TIMESTAMP '2024-01-31 12:00:00.123 UTC', 'default', 'alice@example.com', 'alice', '20240131_120000_00001_abcde', 'select', 'finished', NULL, 842, 10, 'SELECT * FROM orders WHERE status = ''open'''), ` +
+		`(TIMESTAMP '2024-01-31 12:00:01.000 UTC', 'default', NULL, 'mcp', NULL, 'drop', 'rejected', 'write queries are disabled', 0, 0, 'DROP TABLE orders')`
+	if got := auditInsert("hive.governance.mcp_queries", events); got != want {
+		t.Errorf("auditInsert() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	var contentType string
+	var body struct {
+		Records []struct {
+			Key   string     `json:"key"`
+			Value auditEvent `json:"value"`
+		} `json:"records"`
+	}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/mcp.audit" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		contentType = r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"error_code":40401,"message":"Topic not found."}`))
+	}))
+	defer server.Close()
+
+	sink := &kafkaSink{url: server.URL + "/topics/mcp.audit", topic: "mcp.audit", httpClient: server.Client()}
+	events := []auditEvent{{QueryID: "20240131_120000_00001_abcde", Outcome: auditFinished, Query: "SELECT 1"}}
+	if err := sink.write(context.Background(), events); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if contentType != "application/vnd.kafka.json.v2+json" {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+	if len(body.Records) != 1 || body.Records[0].Key != events[0].QueryID || body.Records[0].Value.Query != "SELECT 1" {
+		t.Errorf("records = %+v", body.Records)
+	}
+
+	status = http.StatusNotFound
+	if err := sink.write(context.Background(), events); err == nil || !strings.Contains(err.Error(), "HTTP 404: ") {
+		t.Errorf("write() error = %v, want the REST Proxy's error", err)
+	}
+}
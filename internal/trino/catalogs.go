@@ -0,0 +1,37 @@
+package trino
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// catalogSessionProperties renders the session properties of
+// TRINO_CATALOGS_JSON as X-Trino-Session entries: catalog.property=value,
+// with the value URL-encoded as Trino expects. Catalog session properties are
+// namespaced, so each applies only to queries of its catalog.
+func catalogSessionProperties(cfg *config.TrinoConfig) []string {
+	var entries []string
+	for name, cat := range cfg.Catalogs {
+		for property, value := range cat.SessionProperties {
+			entries = append(entries, name+"."+property+"="+url.QueryEscape(value))
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// catalogExtraCredentials renders the extra credentials of
+// TRINO_CATALOGS_JSON as X-Trino-Extra-Credential entries. Trino passes them
+// to every connector, which reads the names it knows.
+func catalogExtraCredentials(cfg *config.TrinoConfig) []string {
+	var entries []string
+	for _, cat := range cfg.Catalogs {
+		for name, value := range cat.ExtraCredentials {
+			entries = append(entries, name+"="+url.QueryEscape(value))
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
@@ -0,0 +1,48 @@
+package trino
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestCatalogSettings(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	server.Handle("SHOW TABLES FROM iceberg.analytics", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "Table", Type: "varchar"}},
+		Rows:    [][]any{{"events"}},
+	})
+	client := newMockClient(t, server, "catalog-settings", func(cfg *config.TrinoConfig) {
+		cfg.Catalogs = map[string]config.CatalogConfig{
+			"iceberg": {Schema: "analytics", SessionProperties: map[string]string{"compression_codec": "ZSTD"}},
+			"hive": {
+				SessionProperties: map[string]string{"insert_existing_partitions_behavior": "OVERWRITE"},
+				ExtraCredentials:  map[string]string{"hive.s3.access-key": "AKIA/EXAMPLE"},
+			},
+		}
+	})
+
+	tables, err := client.ListTablesWithContext(context.Background(), "iceberg", "")
+	if err != nil {
+		t.Fatalf("ListTablesWithContext() error = %v", err)
+	}
+	if len(tables) != 1 || tables[0] != "events" {
+		t.Errorf("tables = %v, want the iceberg catalog's default schema listed", tables)
+	}
+	if ref := client.ResolveTable("hive", "", "orders"); ref.Schema != "sales" {
+		t.Errorf("ResolveTable() schema = %q, want TRINO_SCHEMA for a catalog without its own", ref.Schema)
+	}
+
+	header := server.Requests()[0].Header
+	wantSession := []string{"hive.insert_existing_partitions_behavior=OVERWRITE", "iceberg.compression_codec=ZSTD"}
+	if got := header.Values("X-Trino-Session"); !slices.Equal(got, wantSession) {
+		t.Errorf("X-Trino-Session = %q, want %q", got, wantSession)
+	}
+	if got := header.Values("X-Trino-Extra-Credential"); len(got) != 1 || got[0] != "hive.s3.access-key=AKIA%2FEXAMPLE" {
+		t.Errorf("X-Trino-Extra-Credential = %q, want the hive credential URL-encoded", got)
+	}
+}
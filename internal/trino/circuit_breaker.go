@@ -0,0 +1,114 @@
+package trino
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"    // Requests flow normally
+	CircuitOpen     = "open"      // Requests are rejected until the cooldown elapses
+	CircuitHalfOpen = "half-open" // A single trial request decides whether to close again
+)
+
+// UnavailableError is returned without contacting Trino while a cluster's circuit is open
+type UnavailableError struct {
+	Cluster    string
+	RetryAfter time.Duration
+}
+
+func (e *UnavailableError) Error() string {
+	retryAfter := e.RetryAfter.Round(time.Second)
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	return fmt.Sprintf("Trino unavailable: cluster '%s' is failing to accept connections; retry after %s", e.Cluster, retryAfter)
+}
+
+// circuitBreaker stops sending requests to a cluster after consecutive
+// failures. A nil breaker or a zero threshold lets every request through.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	trial     bool // A half-open trial request is in flight
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     CircuitClosed,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a request may be sent and, if not, how long until the
+// next trial request. Every allowed request must be followed by a call to record.
+func (b *circuitBreaker) allow() (bool, time.Duration) {
+	if b == nil || b.threshold <= 0 {
+		return true, 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if remaining := b.cooldown - b.now().Sub(b.openedAt); remaining > 0 {
+			return false, remaining
+		}
+		b.state = CircuitHalfOpen
+		b.trial = true
+		return true, 0
+	case CircuitHalfOpen:
+		if b.trial {
+			// The outcome of the trial request decides; callers should retry shortly
+			return false, time.Second
+		}
+		b.trial = true
+		return true, 0
+	default:
+		return true, 0
+	}
+}
+
+// record updates the breaker with the outcome of an allowed request. Only
+// failures reaching the cluster count; query errors reported by Trino do not.
+func (b *circuitBreaker) record(err error) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if !isUnavailable(err) {
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// currentState returns the breaker state for status reporting
+func (b *circuitBreaker) currentState() string {
+	if b == nil || b.threshold <= 0 {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
@@ -4,18 +4,28 @@ import (
 	"context"
 	"crypto/tls"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/trinodb/trino-go-client/trino"
 	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/tracing"
 	oauth "github.com/tuannvm/oauth-mcp-proxy"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Context key for impersonated user
@@ -25,20 +35,60 @@ const (
 	impersonatedUserKey contextKey = "impersonated_user"
 )
 
-// headerRoundTripper adds X-Trino-Source and X-Trino-User headers to requests
+// headerRoundTripper adds X-Trino-Source, X-Trino-User, X-Trino-Role and the
+// configured gateway headers to requests
 type headerRoundTripper struct {
 	base   http.RoundTripper
 	config *config.TrinoConfig
+	roles  string // X-Trino-Role value (empty when no role is configured)
+
+	// Per-catalog settings from TRINO_CATALOGS_JSON
+	sessionProperties []string // X-Trino-Session entries
+	extraCredentials  []string // X-Trino-Extra-Credential entries
 }
 
 func (t *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Spooled segments are downloaded from the spooling storage, which gets
+	// only the headers the driver sets from the segment metadata
+	if t.isSpooledSegment(req) {
+		return t.base.RoundTrip(req)
+	}
 	req = req.Clone(req.Context())
 
-	// Set X-Trino-Source header for query attribution
-	if t.config.TrinoSource != "" {
+	// Headers a gateway requires; those the driver or mcp-trino set take precedence
+	for name, value := range t.config.HTTPHeaders {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	// Set X-Trino-Source header for query attribution, unless the request names its own source
+	if labels, ok := getQueryLabels(req.Context()); ok && labels.Source != "" {
+		req.Header.Set("X-Trino-Source", labels.Source)
+	} else if t.config.TrinoSource != "" {
 		req.Header.Set("X-Trino-Source", t.config.TrinoSource)
 	}
 
+	// Enable the configured roles, like SET ROLE in a Trino session
+	if t.roles != "" {
+		req.Header.Set("X-Trino-Role", t.roles)
+	}
+
+	// Apply the settings of TRINO_CATALOGS_JSON alongside those the query sets
+	for _, entry := range t.sessionProperties {
+		req.Header.Add("X-Trino-Session", entry)
+	}
+	for _, entry := range t.extraCredentials {
+		req.Header.Add("X-Trino-Extra-Credential", entry)
+	}
+
+	// Select the Trino Gateway routing group, unless the request names its own
+	if labels, ok := getQueryLabels(req.Context()); ok && labels.RoutingGroup != "" {
+		req.Header.Set("X-Trino-Routing-Group", labels.RoutingGroup)
+	} else if t.config.RoutingGroup != "" {
+		req.Header.Set("X-Trino-Routing-Group", t.config.RoutingGroup)
+	}
+
 	// Set X-Trino-User header if impersonation is enabled
 	if t.config.EnableImpersonation {
 		if user, ok := req.Context().Value(impersonatedUserKey).(string); ok && user != "" {
@@ -46,7 +96,21 @@ func (t *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 		}
 	}
 
-	return t.base.RoundTrip(req)
+	// Correlate the Trino query with the originating MCP tool call
+	if token := tracing.TraceToken(req.Context()); token != "" {
+		req.Header.Set("X-Trino-Trace-Token", token)
+		otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	}
+
+	started := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if debugLogging.Load() {
+		logExchange(req, resp, err, time.Since(started))
+	}
+	if err == nil {
+		captureQueryID(req, resp)
+	}
+	return resp, err
 }
 
 // Client is a wrapper around Trino client
@@ -56,7 +120,20 @@ type Client struct {
 	timeout       time.Duration
 	authenticator *ExternalAuthenticator
 	initialized   bool
-	mu            sync.Mutex // Protects concurrent access to connection state
+	password      string                        // Trino password, replaced when rotated credentials are reloaded
+	accessToken   string                        // Configured JWT (TRINO_JWT), replaced like the password
+	customClient  string                        // Name of the registered HTTP client used in the DSN
+	httpClient    *http.Client                  // Client registered for the DSN, also used for health checks
+	jar           *affinityJar                  // Cookies of the httpClient, e.g. a gateway's sticky session
+	policy        atomic.Pointer[config.Policy] // Allowlists and result limits, replaced on reload
+	authorizer    *opaAuthorizer                // OPA query authorization (nil when TRINO_OPA_URL is unset)
+	notifier      *queryNotifier                // Slow and failed query notifications (nil when TRINO_NOTIFY_WEBHOOK_URL is unset)
+	auditor       *queryAuditor                 // Audit events of every query (nil when no audit sink is configured)
+	slowQueries   atomic.Int64                  // Queries logged as slow, for server_stats
+	metadata      metadataCache                 // Table and column names for "did you mean" suggestions
+	version       atomic.Pointer[string]        // Coordinator's Trino version, once read from /v1/info
+	versionRead   atomic.Bool                   // ServerVersion tried to read /v1/info itself
+	mu            sync.Mutex                    // Protects concurrent access to connection state
 }
 
 // createTransport creates an HTTP transport with appropriate TLS configuration.
@@ -88,19 +165,59 @@ func createTransport(sslInsecure bool) *http.Transport {
 func NewClient(cfg *config.TrinoConfig) (*Client, error) {
 	// Create base transport with TLS config for SSLInsecure support
 	baseTransport := createTransport(cfg.SSLInsecure)
+	// Without TRINO_PROXY_URL, the cloned default transport honors HTTPS_PROXY and NO_PROXY
+	if cfg.ProxyURL != "" {
+		baseTransport.Proxy = proxyFunc(cfg.ProxyURL)
+	}
+	var transport http.RoundTripper = baseTransport
+	if cfg.ConnectionMaxAge > 0 {
+		transport = newConnectionRecycler(baseTransport, cfg.ConnectionMaxAge)
+	}
+	switch {
+	case cfg.ReplayDir != "":
+		replayer, err := newReplayer(fixtureDir(cfg.ReplayDir, cfg.ClusterName))
+		if err != nil {
+			return nil, err
+		}
+		transport = replayer
+	case cfg.RecordDir != "":
+		recorder, err := newRecorder(transport, fixtureDir(cfg.RecordDir, cfg.ClusterName))
+		if err != nil {
+			return nil, err
+		}
+		transport = recorder
+	}
+	if len(cfg.Compression) > 0 {
+		transport = &compressionRoundTripper{base: transport, acceptEncoding: strings.Join(cfg.Compression, ", ")}
+	}
+
+	// Gateways that route by cookie need it on the nextUri requests of a query
+	jar := newAffinityJar()
 
 	// Create HTTP client with custom headers (created once, reused for all connections)
 	httpClient := &http.Client{
+		Jar: jar,
 		Transport: &headerRoundTripper{
-			base:   baseTransport,
+			base:   transport,
 			config: cfg,
+			roles:  roleHeader(cfg),
+
+			sessionProperties: catalogSessionProperties(cfg),
+			extraCredentials:  catalogExtraCredentials(cfg),
 		},
 	}
+	if cfg.Gateway {
+		httpClient.CheckRedirect = gatewayRedirect
+	}
 
 	// Register the custom client. Note: trino-go-client uses global registration,
-	// so only the first registration takes effect. Subsequent calls with different
-	// SSLInsecure settings will use the first client's TLS configuration.
-	if err := trino.RegisterCustomClient("mcp-trino", httpClient); err != nil {
+	// so only the first registration of a name takes effect. Each named cluster
+	// registers under its own name so it keeps its own TLS and header settings.
+	customClient := "mcp-trino"
+	if cfg.ClusterName != "" {
+		customClient = "mcp-trino-" + cfg.ClusterName
+	}
+	if err := trino.RegisterCustomClient(customClient, httpClient); err != nil {
 		// Ignore "already registered" errors (process reuse, tests, multiple clients)
 		if !strings.Contains(err.Error(), "already registered") {
 			return nil, fmt.Errorf("failed to register custom HTTP client: %w", err)
@@ -111,20 +228,39 @@ func NewClient(cfg *config.TrinoConfig) (*Client, error) {
 	}
 
 	client := &Client{
-		config:  cfg,
-		timeout: cfg.QueryTimeout,
-	}
+		config:       cfg,
+		timeout:      cfg.QueryTimeout,
+		password:     cfg.Password,
+		accessToken:  cfg.AccessToken,
+		customClient: customClient,
+		httpClient:   httpClient,
+		jar:          jar,
+		authorizer:   newOPAAuthorizer(cfg),
+		notifier:     newQueryNotifier(cfg),
+		auditor:      newQueryAuditor(cfg),
+	}
+	client.policy.Store(cfg.Policy())
 
 	// If external auth is enabled, defer connection until first query (lazy auth)
 	if cfg.ExternalAuth {
 		baseURL := fmt.Sprintf("%s://%s:%d", cfg.Scheme, cfg.Host, cfg.Port)
 		client.authenticator = NewExternalAuthenticator(baseURL, cfg.User, cfg.ExternalAuthTimeout, cfg.SSLInsecure)
+		client.authenticator.headless = cfg.ExternalAuthHeadless
+		if cfg.ProxyURL != "" {
+			client.authenticator.useProxy(cfg.ProxyURL)
+		}
+		if cfg.ExternalAuthPollInterval > 0 {
+			client.authenticator.pollInterval = cfg.ExternalAuthPollInterval
+		}
+		if cfg.ExternalAuthFlow == config.ExternalAuthFlowPKCE {
+			client.authenticator.usePKCE(cfg.ExternalAuthIdP)
+		}
 		log.Println("INFO: External authentication enabled - connection will be established on first query")
 		return client, nil
 	}
 
-	// Standard connection flow
-	if err := client.connect(""); err != nil {
+	// Standard connection flow, with the configured JWT if any
+	if err := client.connect(client.accessToken); err != nil {
 		return nil, err
 	}
 
@@ -135,18 +271,18 @@ func NewClient(cfg *config.TrinoConfig) (*Client, error) {
 func (c *Client) connect(accessToken string) error {
 	dsnURL := url.URL{
 		Scheme: c.config.Scheme,
-		User:   url.UserPassword(c.config.User, c.config.Password),
+		User:   url.UserPassword(c.config.User, c.password),
 		Host:   fmt.Sprintf("%s:%d", c.config.Host, c.config.Port),
 	}
 
 	params := url.Values{}
 	params.Add("catalog", c.config.Catalog)
-	params.Add("schema", c.config.Schema)
+	params.Add("schema", c.config.DefaultSchema(c.config.Catalog))
 	params.Add("SSL", fmt.Sprintf("%t", c.config.SSL))
 	params.Add("SSLInsecure", fmt.Sprintf("%t", c.config.SSLInsecure))
-	params.Add("custom_client", "mcp-trino")
+	params.Add("custom_client", c.customClient)
 
-	// Add access token if provided (for external auth)
+	// Add access token if provided (TRINO_JWT or external auth)
 	if accessToken != "" {
 		params.Add("accessToken", accessToken)
 	}
@@ -157,7 +293,7 @@ func (c *Client) connect(accessToken string) error {
 	db, err := sql.Open("trino", dsn)
 	if err != nil {
 		// Sanitize error to prevent password exposure
-		sanitizedErr := sanitizeConnectionError(err, c.config.Password)
+		sanitizedErr := sanitizeConnectionError(err, c.password)
 		return fmt.Errorf("failed to connect to Trino: %w", sanitizedErr)
 	}
 
@@ -173,7 +309,7 @@ func (c *Client) connect(accessToken string) error {
 			log.Printf("Error closing DB connection: %v", closeErr)
 		}
 		// Sanitize error to prevent password exposure
-		sanitizedErr := sanitizeConnectionError(err, c.config.Password)
+		sanitizedErr := sanitizeConnectionError(err, c.password)
 		return fmt.Errorf("failed to ping Trino: %w", sanitizedErr)
 	}
 
@@ -203,11 +339,16 @@ func (c *Client) ensureConnected(ctx context.Context) (*sql.DB, error) {
 	c.mu.Unlock()
 
 	// Get token via external auth flow
-	// Use context.Background() to give auth full TRINO_EXTERNAL_AUTH_TIMEOUT duration.
-	// The caller's query timeout shouldn't constrain the one-time browser auth flow,
-	// which can take minutes for the user to complete SSO login.
-	token, err := c.authenticator.GetToken(context.Background())
+	// The query timeout is applied later, so it doesn't constrain the browser auth
+	// flow, which can take minutes for the user to complete SSO login; the flow is
+	// still bounded by TRINO_EXTERNAL_AUTH_TIMEOUT and by the caller's deadline.
+	token, err := c.authenticator.GetToken(ctx)
 	if err != nil {
+		// A pending headless login is not a failure; its error tells the user what to do
+		var authRequired *AuthRequiredError
+		if errors.As(err, &authRequired) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("external authentication failed: %w", err)
 	}
 
@@ -232,8 +373,80 @@ func (c *Client) ensureConnected(ctx context.Context) (*sql.DB, error) {
 	return c.db, nil
 }
 
+// AuthStatus reports whether the client is logged in with external
+// authentication, and otherwise the URL to open to log in
+func (c *Client) AuthStatus(ctx context.Context) (*AuthStatus, error) {
+	if c.authenticator == nil {
+		return nil, fmt.Errorf("cluster does not use external authentication (TRINO_EXTERNAL_AUTH)")
+	}
+	return c.authenticator.Status(ctx)
+}
+
+// Reauthenticate drops the external authentication token and connection of
+// the client and starts a new login, e.g. to switch identities or recover from
+// a revoked token. Queries wait for, or in headless mode ask for, the login.
+func (c *Client) Reauthenticate(ctx context.Context) (*AuthStatus, error) {
+	if c.authenticator == nil {
+		return nil, fmt.Errorf("cluster does not use external authentication (TRINO_EXTERNAL_AUTH)")
+	}
+	c.clearConnectionForReauth()
+	return c.authenticator.Reauthenticate(ctx)
+}
+
+// SetPolicy replaces the allowlists and result limits applied by the client.
+// Queries already running keep the policy they started with.
+func (c *Client) SetPolicy(policy *config.Policy) {
+	c.policy.Store(policy)
+}
+
+// currentPolicy returns the active allowlists and result limits
+func (c *Client) currentPolicy() *config.Policy {
+	if policy := c.policy.Load(); policy != nil {
+		return policy
+	}
+	return c.config.Policy()
+}
+
+// SetCredentials switches the client to a rotated password and JWT. Unless
+// external authentication supplies the token, a new connection is opened with
+// them; queries already running finish on the old one. If the new connection
+// fails, the client keeps the current credentials and connection.
+func (c *Client) SetCredentials(password, accessToken string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if password == c.password && accessToken == c.accessToken {
+		return nil
+	}
+	if c.authenticator != nil || !c.initialized {
+		c.password, c.accessToken = password, accessToken
+		return nil
+	}
+
+	old, oldPassword, oldAccessToken := c.db, c.password, c.accessToken
+	c.password, c.accessToken = password, accessToken
+	if err := c.connect(accessToken); err != nil {
+		c.password, c.accessToken = oldPassword, oldAccessToken
+		return err
+	}
+	go func() {
+		// Close waits for the queries still running on the old connection
+		if err := old.Close(); err != nil {
+			log.Printf("Error closing DB connection: %v", err)
+		}
+	}()
+	log.Printf("INFO: Reconnected to Trino with rotated credentials")
+	return nil
+}
+
 // Close closes the database connection
 func (c *Client) Close() error {
+	// Write the audit events still queued while the connection is open
+	if c.auditor != nil {
+		if err := c.auditor.close(); err != nil {
+			log.Printf("Error closing audit sinks: %v", err)
+		}
+	}
+
 	c.mu.Lock()
 	db := c.db
 	c.db = nil // Prevent double-close from clearConnectionForReauth()
@@ -246,6 +459,45 @@ func (c *Client) Close() error {
 	return db.Close()
 }
 
+// CheckHealth queries the coordinator's /v1/info endpoint, which needs no
+// authentication, and fails unless the coordinator is up and done starting
+func (c *Client) CheckHealth(ctx context.Context) error {
+	infoURL := fmt.Sprintf("%s://%s:%d/v1/info", c.config.Scheme, c.config.Host, c.config.Port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, infoURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health check request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Error closing health check response: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check failed: %s", resp.Status)
+	}
+	var info struct {
+		NodeVersion struct {
+			Version string `json:"version"`
+		} `json:"nodeVersion"`
+		Starting bool `json:"starting"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&info); err != nil {
+		return fmt.Errorf("health check failed: invalid /v1/info response: %w", err)
+	}
+	if version := info.NodeVersion.Version; version != "" {
+		c.version.Store(&version)
+	}
+	if info.Starting {
+		return fmt.Errorf("health check failed: coordinator is still starting")
+	}
+	return nil
+}
+
 // WithImpersonatedUser adds impersonated user to context
 func WithImpersonatedUser(ctx context.Context, username string) context.Context {
 	return context.WithValue(ctx, impersonatedUserKey, username)
@@ -321,6 +573,11 @@ func isReadOnlyQuery(query string) bool {
 	return false
 }
 
+// IsReadOnlyQuery reports whether a query only reads data, and so is safe to retry
+func IsReadOnlyQuery(query string) bool {
+	return isReadOnlyQuery(query)
+}
+
 // isAllowedReadOnlyPattern checks if a query matches known safe read-only patterns
 // even if it contains keywords that might look like write operations
 func isAllowedReadOnlyPattern(queryLower string) bool {
@@ -461,7 +718,6 @@ func sanitizeQueryForKeywordDetection(query string) string {
 			continue
 		}
 
-
 		// Regular character - copy to output
 		result.WriteByte(query[i])
 		i++
@@ -489,9 +745,56 @@ func getQueryUsername(ctx context.Context) string {
 	return ""
 }
 
-// ExecuteQuery executes a SQL query and returns the results
-func (c *Client) ExecuteQuery(query string) ([]map[string]interface{}, error) {
-	return c.ExecuteQueryWithContext(context.Background(), query)
+// QueryResult holds the rows returned by a query together with Trino execution metadata
+type QueryResult struct {
+	QueryID          string                   `json:"queryId,omitempty"`
+	InfoURI          string                   `json:"infoUri,omitempty"`
+	Stats            *QueryStats              `json:"stats,omitempty"`
+	RowCount         int                      `json:"rowCount"`
+	Truncated        bool                     `json:"truncated,omitempty"`
+	TruncationReason string                   `json:"truncationReason,omitempty"`
+	RowsScanned      int64                    `json:"rowsScanned,omitempty"` // Rows processed by Trino when known
+	Columns          []string                 `json:"columns"`
+	ColumnTypes      []ColumnInfo             `json:"-"` // Typed column descriptions for binary encodings
+	Rows             []map[string]interface{} `json:"rows"`
+}
+
+// ColumnInfo describes a result column and its Trino type (e.g. BIGINT, DECIMAL, ARRAY(VARCHAR)).
+// Precision and Scale are set for DECIMAL columns; Precision also holds the
+// fractional-second digits of TIME and TIMESTAMP columns.
+type ColumnInfo struct {
+	Name      string
+	Type      string
+	Precision int64
+	Scale     int64
+}
+
+// RowSink receives rows as they are read from Trino instead of having them
+// buffered in memory. Begin is called once before the first row.
+type RowSink interface {
+	Begin(columns []ColumnInfo) error
+	WriteRow(values []interface{}) error
+}
+
+// resettableSink is a RowSink that can discard the rows it received, which
+// lets a query be replayed into it after re-authentication
+type resettableSink interface {
+	RowSink
+	Reset() error
+}
+
+// Numeric is a numeric query parameter passed to Trino as a literal, avoiding
+// the precision loss of float64 parameters (which the driver rejects)
+type Numeric = trino.Numeric
+
+// queryOptions controls how a single query execution is carried out
+type queryOptions struct {
+	maxRows   int           // Stop reading after this many rows (0 means unlimited)
+	maxBytes  int64         // Stop reading once the approximate result size exceeds this (0 means unlimited)
+	sink      RowSink       // Stream rows to the sink instead of collecting them
+	params    []interface{} // Values bound to ? placeholders
+	procedure bool          // The query is a CALL of an allowlisted procedure, run even without write queries
+	agent     bool          // The query was written by an agent: the allowlists, TRINO_SELECT_STAR_* and TRINO_INFORMATION_SCHEMA apply
 }
 
 // ExecuteQueryWithContext executes a SQL query and returns the results
@@ -499,11 +802,120 @@ func (c *Client) ExecuteQuery(query string) ([]map[string]interface{}, error) {
 // - User impersonation via X-Trino-User header (when EnableImpersonation is true)
 // - Query attribution via X-Trino-Client-Tags/Info/Source (from OAuth user context)
 func (c *Client) ExecuteQueryWithContext(ctx context.Context, query string) ([]map[string]interface{}, error) {
-	return c.executeQueryWithRetry(ctx, query, false)
+	result, err := c.executeQueryWithRetry(ctx, query, queryOptions{}, false)
+	if err != nil {
+		return nil, err
+	}
+	return result.Rows, nil
+}
+
+// ExecuteQueryWithResult executes a SQL query and returns the rows along with
+// the Trino query ID, UI link and execution statistics. Results are truncated
+// according to TRINO_MAX_RESULT_ROWS and TRINO_MAX_RESULT_BYTES. Params are
+// bound to ? placeholders by the driver (EXECUTE IMMEDIATE ... USING), so values
+// are never concatenated into the SQL text.
+func (c *Client) ExecuteQueryWithResult(ctx context.Context, query string, params ...interface{}) (*QueryResult, error) {
+	policy := c.currentPolicy()
+	opts := queryOptions{
+		maxRows:  policy.MaxResultRows,
+		maxBytes: policy.MaxResultBytes,
+		params:   params,
+	}
+	result, err := c.executeQueryWithRetry(ctx, query, opts, false)
+	return result, c.suggestNames(ctx, query, err)
+}
+
+// ExecuteAgentQueryWithResult executes a query an agent or user wrote like
+// ExecuteQueryWithResult, also applying the allowlists, TRINO_SELECT_STAR_*
+// and TRINO_INFORMATION_SCHEMA as execute_query does
+func (c *Client) ExecuteAgentQueryWithResult(ctx context.Context, query string, params ...interface{}) (*QueryResult, error) {
+	policy := c.currentPolicy()
+	opts := queryOptions{
+		maxRows:  policy.MaxResultRows,
+		maxBytes: policy.MaxResultBytes,
+		params:   params,
+		agent:    true,
+	}
+	result, err := c.executeQueryWithRetry(ctx, query, opts, false)
+	return result, c.suggestNames(ctx, query, err)
+}
+
+// StreamQueryWithResult executes a SQL query like ExecuteQueryWithResult,
+// truncating the result according to TRINO_MAX_RESULT_ROWS and
+// TRINO_MAX_RESULT_BYTES, but streams the rows to the sink as they are read
+// instead of holding them in memory. The returned result carries metadata and
+// the row count but no rows.
+func (c *Client) StreamQueryWithResult(ctx context.Context, query string, sink RowSink, params ...interface{}) (*QueryResult, error) {
+	policy := c.currentPolicy()
+	opts := queryOptions{
+		maxRows:  policy.MaxResultRows,
+		maxBytes: policy.MaxResultBytes,
+		sink:     sink,
+		params:   params,
+		agent:    true,
+	}
+	result, err := c.executeQueryWithRetry(ctx, query, opts, false)
+	return result, c.suggestNames(ctx, query, err)
+}
+
+// StreamQueryWithContext executes a SQL query and streams every row to the sink.
+// The returned result carries metadata and the row count but no rows.
+func (c *Client) StreamQueryWithContext(ctx context.Context, query string, sink RowSink) (*QueryResult, error) {
+	result, err := c.executeQueryWithRetry(ctx, query, queryOptions{sink: sink, agent: true}, false)
+	return result, c.suggestNames(ctx, query, err)
+}
+
+// checkQuery applies the server's query policies before a query is sent to
+// Trino and returns the column masks that apply to its results
+func (c *Client) checkQuery(ctx context.Context, query string, opts *queryOptions) (map[string]string, error) {
+	// Read-only mode blocks every statement that could write or change the
+	// session, including CALL of allowlisted procedures
+	if c.config.ReadOnly && !isReadOnlyQuery(query) {
+		return nil, policyError(ErrorPermissionDenied, "Rewrite the statement as a read-only query",
+			"security restriction: the server is in read-only mode (TRINO_READ_ONLY=true); "+
+				"only SELECT, SHOW, DESCRIBE, and EXPLAIN queries are allowed")
+	}
+
+	// SQL injection protection: only allow read-only queries unless explicitly allowed in config
+	if !c.config.AllowWriteQueries && !opts.procedure && !isReadOnlyQuery(query) {
+		return nil, policyError(ErrorPermissionDenied, "Rewrite the statement as a read-only query",
+			"security restriction: only SELECT, SHOW, DESCRIBE, and EXPLAIN queries are allowed. "+
+				"Set TRINO_ALLOW_WRITE_QUERIES=true to enable write operations (at your own risk)")
+	}
+
+	// Reject tables outside the allowlists in queries agents write
+	if opts.agent {
+		if err := c.checkQueryTables(query); err != nil {
+			return nil, err
+		}
+	}
+
+	// Reject queries matching a blocked query pattern
+	if err := c.checkBlockedPatterns(ctx, query); err != nil {
+		return nil, err
+	}
+
+	// Reject unfiltered scans of tables that require a partition filter
+	if err := c.checkPartitionFilters(query); err != nil {
+		return nil, err
+	}
+
+	// Reject cross-catalog queries and queries of too many tables
+	if err := c.checkQueryScope(query); err != nil {
+		return nil, err
+	}
+
+	// Ask the external policy engine, which may also tighten the result limits
+	if err := c.authorizeQuery(ctx, query, opts); err != nil {
+		return nil, err
+	}
+
+	// Find the column masks that apply; rejects queries that could rename masked columns
+	return activeColumnMasks(c.currentPolicy().ColumnMasks, query)
 }
 
 // executeQueryWithRetry handles query execution with automatic re-authentication on 401 errors
-func (c *Client) executeQueryWithRetry(ctx context.Context, query string, isRetry bool) ([]map[string]interface{}, error) {
+func (c *Client) executeQueryWithRetry(ctx context.Context, query string, opts queryOptions, isRetry bool) (*QueryResult, error) {
 	// Ensure connection is established (triggers auth if needed)
 	// Note: Capturing db prevents nil deref but not concurrent closure by clearConnectionForReauth().
 	// If another goroutine closes the connection during re-auth, this query will fail and retry.
@@ -515,30 +927,89 @@ func (c *Client) executeQueryWithRetry(ctx context.Context, query string, isRetr
 	// Strip trailing semicolon that Trino doesn't allow
 	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
 
-	// SQL injection protection: only allow read-only queries unless explicitly allowed in config
-	if !c.config.AllowWriteQueries && !isReadOnlyQuery(query) {
-		return nil, fmt.Errorf("security restriction: only SELECT, SHOW, DESCRIBE, and EXPLAIN queries are allowed. " +
-			"Set TRINO_ALLOW_WRITE_QUERIES=true to enable write operations (at your own risk)")
+	// Reject or rewrite SELECT *, information_schema and SHOW in queries agents write
+	if opts.agent {
+		rewritten, err := c.checkSelectStar(ctx, query)
+		if err == nil {
+			rewritten, err = c.checkInformationSchema(rewritten)
+		}
+		if err == nil {
+			rewritten, err = c.checkShowStatement(rewritten)
+		}
+		if err != nil {
+			c.auditQuery(ctx, query, nil, time.Time{}, 0, err)
+			return nil, err
+		}
+		query = rewritten
+	}
+
+	// Apply the server's query policies and find the column masks that apply
+	masks, err := c.checkQuery(ctx, query, &opts)
+	if err != nil {
+		c.auditQuery(ctx, query, nil, time.Time{}, 0, err)
+		return nil, err
+	}
+
+	// Track the Trino query ID and statistics; also used to kill the query if the caller cancels
+	ctx, tracker := withQueryTracker(ctx)
+	started := time.Now()
+
+	ctx, span := tracing.Tracer().Start(ctx, "trino.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "trino"),
+			attribute.String("db.query.text", query),
+			attribute.Bool("trino.retry", isRetry),
+		),
+	)
+	defer func() {
+		if id := tracker.QueryID(); id != "" {
+			span.SetAttributes(attribute.String("trino.query_id", id))
+		}
+		span.End()
+		recordUsage(ctx, tracker)
+	}()
+
+	// Resolve the session time zone before the query is sent
+	zone, loc, err := c.sessionTimeZone(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create context with timeout, preserving any impersonation data
-	queryCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	timeout, maxRunTime := c.queryTimeout(ctx)
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Build query arguments for attribution headers
 	// These are complementary to the X-Trino-User header set by RoundTripper
-	var queryArgs []interface{}
-	if userName := getQueryUsername(ctx); userName != "" {
-		queryArgs = append(queryArgs,
-			sql.Named("X-Trino-Client-Tags", userName),
-			sql.Named("X-Trino-Client-Info", userName),
-		)
+	queryArgs := []interface{}{
+		sql.Named("X-Trino-Progress-Callback", tracker),
+		sql.Named("X-Trino-Progress-Callback-Period", progressCallbackPeriod),
+	}
+	userName := getQueryUsername(ctx)
+	if tags := c.clientTags(ctx, userName); len(tags) > 0 {
+		queryArgs = append(queryArgs, sql.Named("X-Trino-Client-Tags", strings.Join(tags, ",")))
+	}
+	if zone != "" {
+		queryArgs = append(queryArgs, sql.Named("X-Trino-Time-Zone", zone))
+	}
+	if maxRunTime != "" {
+		queryArgs = append(queryArgs, sql.Named("X-Trino-Session", maxRunTime))
+	}
+	queryArgs = append(queryArgs, spoolingArgs(c.config.Spooling)...)
+	if userName != "" {
+		queryArgs = append(queryArgs, sql.Named("X-Trino-Client-Info", userName))
 		// Only set X-Trino-Source if not already configured globally
 		if c.config.TrinoSource == "" {
 			queryArgs = append(queryArgs, sql.Named("X-Trino-Source", userName))
 		}
 	}
 
+	// Bind placeholder values after the header arguments
+	queryArgs = append(queryArgs, opts.params...)
+	Debugf("Trino query with %d bound values: %s", len(opts.params), RedactSQL(query))
+
 	// Execute the query with optional attribution headers (using captured db handle for lazy auth)
 	rows, err := db.QueryContext(queryCtx, query, queryArgs...)
 	if err != nil {
@@ -546,16 +1017,24 @@ func (c *Client) executeQueryWithRetry(ctx context.Context, query string, isRetr
 		if !isRetry && IsAuthenticationError(err) && c.authenticator != nil {
 			log.Printf("WARNING: Authentication failed (401) - attempting automatic re-authentication...")
 			c.clearConnectionForReauth()
-			// Use fresh context for retry to reset deadline, but preserve impersonation
-			retryCtx := context.Background()
-			if user, ok := GetImpersonatedUser(ctx); ok {
-				retryCtx = WithImpersonatedUser(retryCtx, user)
-			}
-			return c.executeQueryWithRetry(retryCtx, query, true)
+			return c.executeQueryWithRetry(ctx, query, opts, true)
+		}
+		if queryCtx.Err() != nil {
+			c.killQuery(ctx, tracker.QueryID())
 		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		err = queryError(err, tracker.QueryID(), queryCtx.Err() != nil)
+		c.notifyQuery(ctx, query, tracker, started, err)
+		c.logSlowQuery(ctx, query, tracker, started, err)
+		c.auditQuery(ctx, query, tracker, started, 0, err)
 		return nil, fmt.Errorf("query execution failed: %w", err)
 	}
+	rowsClosed := false
 	defer func() {
+		if rowsClosed {
+			return
+		}
 		if err := rows.Close(); err != nil {
 			log.Printf("Error closing rows: %v", err)
 		}
@@ -567,14 +1046,54 @@ func (c *Client) executeQueryWithRetry(ctx context.Context, query string, isRetr
 		return nil, fmt.Errorf("failed to get column names: %w", err)
 	}
 
+	// Get column types, including decimal and timestamp precision
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+	infos := make([]ColumnInfo, len(columnTypes))
+	for i, ct := range columnTypes {
+		infos[i] = ColumnInfo{Name: ct.Name(), Type: ct.DatabaseTypeName()}
+		if precision, scale, ok := ct.DecimalSize(); ok {
+			infos[i].Precision = precision
+			infos[i].Scale = scale
+		}
+	}
+
+	// Timestamps without time zone are reported in the session time zone
+	wallClock := wallClockColumns(infos)
+
+	// Drop and mask protected columns before any row leaves the client; rows
+	// are still scanned with every column the query returns
+	scanned := len(columns)
+	masker := newColumnMasker(masks, infos)
+	if masker != nil {
+		columns, infos = masker.columns, masker.infos
+	}
+
+	// Announce the columns to the sink before streaming rows
+	if opts.sink != nil {
+		if err := opts.sink.Begin(infos); err != nil {
+			return nil, err
+		}
+	}
+
 	// Prepare result container
 	results := make([]map[string]interface{}, 0)
+	var resultBytes int64
+	var truncationReason string
+	rowCount := 0
 
-	// Iterate through rows
+	// Iterate through rows, stopping early when a result limit is reached
 	for rows.Next() {
+		if opts.maxRows > 0 && rowCount >= opts.maxRows {
+			truncationReason = fmt.Sprintf("row limit of %d reached", opts.maxRows)
+			break
+		}
+
 		// Create a slice of interface{} to hold the values
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
+		values := make([]interface{}, scanned)
+		valuePtrs := make([]interface{}, scanned)
 
 		// Initialize the pointers
 		for i := range values {
@@ -586,34 +1105,109 @@ func (c *Client) executeQueryWithRetry(ctx context.Context, query string, isRetr
 			log.Printf("Error scanning row: %v", err)
 			continue
 		}
+		if wallClock != nil {
+			inTimeZone(values, wallClock, loc)
+		}
+		if masker != nil {
+			values = masker.apply(values)
+		}
 
 		// Create a map for the current row
-		rowMap := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			rowMap[col] = val
+		var rowMap map[string]interface{}
+		if opts.sink == nil || opts.maxBytes > 0 {
+			rowMap = make(map[string]interface{}, len(columns))
+			for i, col := range columns {
+				rowMap[col] = values[i]
+			}
 		}
 
+		if opts.maxBytes > 0 {
+			resultBytes += estimateRowSize(rowMap)
+			if resultBytes > opts.maxBytes {
+				truncationReason = fmt.Sprintf("byte limit of %d reached", opts.maxBytes)
+				break
+			}
+		}
+		rowCount++
+
+		if opts.sink != nil {
+			if err := opts.sink.WriteRow(values); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		results = append(results, rowMap)
 	}
 
 	// Check for errors after iterating
 	if err := rows.Err(); err != nil {
-		// Check for auth errors during result processing
-		if !isRetry && IsAuthenticationError(err) && c.authenticator != nil {
+		// Check for auth errors during result processing (streamed rows cannot be replayed)
+		if !isRetry && IsAuthenticationError(err) && c.authenticator != nil && resetSink(opts.sink) {
 			log.Printf("WARNING: Authentication failed during result processing - attempting re-auth...")
 			c.clearConnectionForReauth()
-			// Use fresh context for retry to reset deadline, but preserve impersonation
-			retryCtx := context.Background()
-			if user, ok := GetImpersonatedUser(ctx); ok {
-				retryCtx = WithImpersonatedUser(retryCtx, user)
-			}
-			return c.executeQueryWithRetry(retryCtx, query, true)
+			return c.executeQueryWithRetry(ctx, query, opts, true)
+		}
+		if queryCtx.Err() != nil {
+			c.killQuery(ctx, tracker.QueryID())
 		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		err = queryError(err, tracker.QueryID(), queryCtx.Err() != nil)
+		c.notifyQuery(ctx, query, tracker, started, err)
+		c.logSlowQuery(ctx, query, tracker, started, err)
+		c.auditQuery(ctx, query, tracker, started, rowCount, err)
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	return results, nil
+	// Close rows before reading stats: the driver delivers the final progress update on close
+	rowsClosed = true
+	if err := rows.Close(); err != nil {
+		log.Printf("Error closing rows: %v", err)
+	}
+
+	result := &QueryResult{
+		QueryID:     tracker.QueryID(),
+		InfoURI:     tracker.InfoURI(),
+		Stats:       tracker.Stats(),
+		RowCount:    rowCount,
+		Columns:     columns,
+		ColumnTypes: infos,
+		Rows:        results,
+	}
+	if opts.sink != nil {
+		result.Rows = nil
+	}
+	if truncationReason != "" {
+		result.Truncated = true
+		result.TruncationReason = truncationReason
+		if result.Stats != nil {
+			result.RowsScanned = result.Stats.ProcessedRows
+		}
+		log.Printf("INFO: Query result truncated after %d rows: %s", rowCount, truncationReason)
+	}
+	c.notifyQuery(ctx, query, tracker, started, nil)
+	c.logSlowQuery(ctx, query, tracker, started, nil)
+	c.auditQuery(ctx, query, tracker, started, rowCount, nil)
+	return result, nil
+}
+
+// resetSink reports whether a query can be replayed into sink: no rows were
+// streamed to one, or the sink discarded the rows it received
+func resetSink(sink RowSink) bool {
+	if sink == nil {
+		return true
+	}
+	r, ok := sink.(resettableSink)
+	return ok && r.Reset() == nil
+}
+
+// estimateRowSize approximates the serialized size of a row in bytes
+func estimateRowSize(row map[string]interface{}) int64 {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return int64(len(fmt.Sprint(row)))
+	}
+	return int64(len(data))
 }
 
 // clearConnectionForReauth clears the connection state to allow re-authentication
@@ -631,11 +1225,6 @@ func (c *Client) clearConnectionForReauth() {
 	c.initialized = false
 }
 
-// ListCatalogs returns a list of available catalogs
-func (c *Client) ListCatalogs() ([]string, error) {
-	return c.ListCatalogsWithContext(context.Background())
-}
-
 // ListCatalogsWithContext returns a list of available catalogs with context
 func (c *Client) ListCatalogsWithContext(ctx context.Context) ([]string, error) {
 	results, err := c.ExecuteQueryWithContext(ctx, "SHOW CATALOGS")
@@ -649,20 +1238,16 @@ func (c *Client) ListCatalogsWithContext(ctx context.Context) ([]string, error)
 			catalogs = append(catalogs, catalog)
 		}
 	}
+	c.metadata.put(metadataKey(ctx, "catalogs"), catalogs)
 
 	// Apply catalog filtering if allowlist is configured
-	if len(c.config.AllowedCatalogs) > 0 {
+	if len(c.currentPolicy().AllowedCatalogs) > 0 {
 		catalogs = c.filterCatalogs(catalogs)
 	}
 
 	return catalogs, nil
 }
 
-// ListSchemas returns a list of schemas in the specified catalog
-func (c *Client) ListSchemas(catalog string) ([]string, error) {
-	return c.ListSchemasWithContext(context.Background(), catalog)
-}
-
 // ListSchemasWithContext returns a list of schemas in the specified catalog with context
 func (c *Client) ListSchemasWithContext(ctx context.Context, catalog string) ([]string, error) {
 	if catalog == "" {
@@ -681,27 +1266,23 @@ func (c *Client) ListSchemasWithContext(ctx context.Context, catalog string) ([]
 			schemas = append(schemas, schema)
 		}
 	}
+	c.metadata.put(metadataKey(ctx, "schemas", catalog), schemas)
 
 	// Apply schema filtering if allowlist is configured
-	if len(c.config.AllowedSchemas) > 0 {
+	if len(c.currentPolicy().AllowedSchemas) > 0 {
 		schemas = c.filterSchemas(schemas, catalog)
 	}
 
 	return schemas, nil
 }
 
-// ListTables returns a list of tables in the specified catalog and schema
-func (c *Client) ListTables(catalog, schema string) ([]string, error) {
-	return c.ListTablesWithContext(context.Background(), catalog, schema)
-}
-
 // ListTablesWithContext returns a list of tables in the specified catalog and schema with context
 func (c *Client) ListTablesWithContext(ctx context.Context, catalog, schema string) ([]string, error) {
 	if catalog == "" {
 		catalog = c.config.Catalog
 	}
 	if schema == "" {
-		schema = c.config.Schema
+		schema = c.config.DefaultSchema(catalog)
 	}
 
 	query := fmt.Sprintf("SHOW TABLES FROM %s.%s", catalog, schema)
@@ -716,23 +1297,19 @@ func (c *Client) ListTablesWithContext(ctx context.Context, catalog, schema stri
 			tables = append(tables, table)
 		}
 	}
+	c.metadata.put(metadataKey(ctx, "tables", catalog, schema), tables)
 
 	// Apply table filtering if allowlist is configured
-	if len(c.config.AllowedTables) > 0 {
+	if len(c.currentPolicy().AllowedTables) > 0 {
 		tables = c.filterTables(tables, catalog, schema)
 	}
 
 	return tables, nil
 }
 
-// GetTableSchema returns the schema of a table
-func (c *Client) GetTableSchema(catalog, schema, table string) ([]map[string]interface{}, error) {
-	return c.GetTableSchemaWithContext(context.Background(), catalog, schema, table)
-}
-
-// GetTableSchemaWithContext returns the schema of a table with context
-func (c *Client) GetTableSchemaWithContext(ctx context.Context, catalog, schema, table string) ([]map[string]interface{}, error) {
-	// Resolve catalog/schema/table parameters first
+// resolveTableName qualifies a table that may be given as table, schema.table
+// or catalog.schema.table with the configured default catalog and schema
+func (c *Client) resolveTableName(catalog, schema, table string) (string, string, string) {
 	parts := strings.Split(table, ".")
 	if len(parts) == 3 {
 		// If table is already fully qualified, extract components
@@ -752,25 +1329,48 @@ func (c *Client) GetTableSchemaWithContext(ctx context.Context, catalog, schema,
 			catalog = c.config.Catalog
 		}
 		if schema == "" {
-			schema = c.config.Schema
+			schema = c.config.DefaultSchema(catalog)
 		}
 	}
+	return catalog, schema, table
+}
+
+// ResolveTable returns the table a tool call names, qualified with the
+// default catalog and schema like GetTableSchemaWithContext does
+func (c *Client) ResolveTable(catalog, schema, table string) TableRef {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	return TableRef{Catalog: catalog, Schema: schema, Table: table}
+}
+
+// GetTableSchemaWithContext returns the schema of a table with context
+func (c *Client) GetTableSchemaWithContext(ctx context.Context, catalog, schema, table string) ([]map[string]interface{}, error) {
+	// Resolve catalog/schema/table parameters first
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
 
 	// Check if table access is allowed when table allowlist is configured (after resolution)
-	if len(c.config.AllowedTables) > 0 {
+	if len(c.currentPolicy().AllowedTables) > 0 {
 		if !c.isTableAllowed(catalog, schema, table) {
-			return nil, fmt.Errorf("table access denied: %s.%s.%s not in allowlist", catalog, schema, table)
+			return nil, accessDenied("table access denied: %s.%s.%s not in allowlist", catalog, schema, table)
 		}
 	}
 
 	// Build and execute query with resolved parameters
 	query := fmt.Sprintf("DESCRIBE %s.%s.%s", catalog, schema, table)
-	return c.ExecuteQueryWithContext(ctx, query)
-}
-
-// ExplainQuery returns the query execution plan for a given SQL query
-func (c *Client) ExplainQuery(query string, format string) ([]map[string]interface{}, error) {
-	return c.ExplainQueryWithContext(context.Background(), query, format)
+	columns, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(columns))
+	described := make([]describedColumn, 0, len(columns))
+	for _, column := range columns {
+		if name, ok := column["Column"].(string); ok {
+			names = append(names, name)
+			described = append(described, describedColumn{name: name, typ: stringValue(column["Type"]), extra: stringValue(column["Extra"])})
+		}
+	}
+	c.metadata.put(metadataKey(ctx, "columns", catalog, schema, table), names)
+	c.metadata.putColumns(metadataKey(ctx, "describe", catalog, schema, table), described)
+	return maskTableSchema(c.currentPolicy().ColumnMasks, catalog, schema, table, columns), nil
 }
 
 // ExplainQueryWithContext returns the query execution plan for a given SQL query with context
@@ -787,7 +1387,11 @@ func (c *Client) ExplainQueryWithContext(ctx context.Context, query string, form
 	}
 	explainQuery = fmt.Sprintf("%s %s", explainQuery, query)
 
-	return c.ExecuteQueryWithContext(ctx, explainQuery)
+	result, err := c.executeQueryWithRetry(ctx, explainQuery, queryOptions{agent: true}, false)
+	if err != nil {
+		return nil, err
+	}
+	return result.Rows, nil
 }
 
 // sanitizeConnectionError removes sensitive information from connection errors
@@ -813,7 +1417,7 @@ func sanitizeConnectionError(err error, password string) error {
 
 // filterCatalogs filters a list of catalogs based on the allowlist configuration
 func (c *Client) filterCatalogs(catalogs []string) []string {
-	if len(c.config.AllowedCatalogs) == 0 {
+	if len(c.currentPolicy().AllowedCatalogs) == 0 {
 		return catalogs
 	}
 
@@ -830,7 +1434,7 @@ func (c *Client) filterCatalogs(catalogs []string) []string {
 
 // filterSchemas filters a list of schemas based on the allowlist configuration
 func (c *Client) filterSchemas(schemas []string, catalog string) []string {
-	if len(c.config.AllowedSchemas) == 0 {
+	if len(c.currentPolicy().AllowedSchemas) == 0 {
 		return schemas
 	}
 
@@ -847,7 +1451,7 @@ func (c *Client) filterSchemas(schemas []string, catalog string) []string {
 
 // filterTables filters a list of tables based on the allowlist configuration
 func (c *Client) filterTables(tables []string, catalog, schema string) []string {
-	if len(c.config.AllowedTables) == 0 {
+	if len(c.currentPolicy().AllowedTables) == 0 {
 		return tables
 	}
 
@@ -864,7 +1468,7 @@ func (c *Client) filterTables(tables []string, catalog, schema string) []string
 
 // isCatalogAllowed checks if a catalog is in the allowed catalogs list
 func (c *Client) isCatalogAllowed(catalog string) bool {
-	for _, allowed := range c.config.AllowedCatalogs {
+	for _, allowed := range c.currentPolicy().AllowedCatalogs {
 		if strings.EqualFold(catalog, allowed) {
 			return true
 		}
@@ -875,7 +1479,7 @@ func (c *Client) isCatalogAllowed(catalog string) bool {
 // isSchemaAllowed checks if a schema is in the allowed schemas list
 func (c *Client) isSchemaAllowed(catalog, schema string) bool {
 	fullSchemaName := catalog + "." + schema
-	for _, allowed := range c.config.AllowedSchemas {
+	for _, allowed := range c.currentPolicy().AllowedSchemas {
 		if strings.EqualFold(fullSchemaName, allowed) {
 			return true
 		}
@@ -886,10 +1490,72 @@ func (c *Client) isSchemaAllowed(catalog, schema string) bool {
 // isTableAllowed checks if a table is in the allowed tables list
 func (c *Client) isTableAllowed(catalog, schema, table string) bool {
 	fullTableName := catalog + "." + schema + "." + table
-	for _, allowed := range c.config.AllowedTables {
+	for _, allowed := range c.currentPolicy().AllowedTables {
 		if strings.EqualFold(fullTableName, allowed) {
 			return true
 		}
 	}
 	return false
 }
+
+// checkTableAccess rejects a table outside any configured catalog, schema or table allowlist
+func (c *Client) checkTableAccess(catalog, schema, table string) error {
+	policy := c.currentPolicy()
+	if len(policy.AllowedCatalogs) > 0 && !c.isCatalogAllowed(catalog) {
+		return accessDenied("catalog access denied: %s not in allowlist", catalog)
+	}
+	if len(policy.AllowedSchemas) > 0 && !c.isSchemaAllowed(catalog, schema) {
+		return accessDenied("schema access denied: %s.%s not in allowlist", catalog, schema)
+	}
+	if len(policy.AllowedTables) > 0 && !c.isTableAllowed(catalog, schema, table) {
+		return accessDenied("table access denied: %s.%s.%s not in allowlist", catalog, schema, table)
+	}
+	return nil
+}
+
+// checkQueryTables enforces the allowlists on the tables a query an agent
+// wrote reads or writes, with unqualified names resolved with the default
+// catalog and schema. Metadata tables such as "orders$snapshots" belong to
+// their table, and the catalog of a table function such as
+// TABLE(postgresql.system.query(...)) must be allowed. information_schema is
+// left to TRINO_INFORMATION_SCHEMA, and unless that is allow the system
+// catalog's jdbc and metadata tables, which list the objects of every
+// catalog, are rejected. Like the other query checks it is lexical:
+// tables read through views are not checked.
+func (c *Client) checkQueryTables(query string) error {
+	policy := c.currentPolicy()
+	if len(policy.AllowedCatalogs) == 0 && len(policy.AllowedSchemas) == 0 && len(policy.AllowedTables) == 0 {
+		return nil
+	}
+	tokens := tokenizeSQL(query)
+	for _, name := range tableNames(tokens) {
+		if name.cte {
+			continue
+		}
+		ref := resolveTable(name.parts, c.config.Catalog, c.config.DefaultSchema(c.config.Catalog))
+		if strings.EqualFold(ref.Schema, "information_schema") {
+			continue
+		}
+		if strings.EqualFold(ref.Catalog, "system") && (strings.EqualFold(ref.Schema, "jdbc") || strings.EqualFold(ref.Schema, "metadata")) &&
+			c.config.InformationSchema != config.InformationSchemaAllow {
+			return accessDenied("metadata access denied: %s lists objects outside the allowlists", ref)
+		}
+		table, _, _ := strings.Cut(ref.Table, "$")
+		if err := c.checkTableAccess(ref.Catalog, ref.Schema, table); err != nil {
+			return err
+		}
+	}
+
+	// Table functions run in the catalog that qualifies them
+	for i := 0; i+2 < len(tokens); i++ {
+		if !tokens[i].keyword("table") || tokens[i+1].text != "(" {
+			continue
+		}
+		parts, end := qualifiedName(tokens, i+2)
+		if len(parts) == 3 && end < len(tokens) && tokens[end].text == "(" &&
+			len(policy.AllowedCatalogs) > 0 && !c.isCatalogAllowed(parts[0]) {
+			return accessDenied("catalog access denied: %s not in allowlist", parts[0])
+		}
+	}
+	return nil
+}
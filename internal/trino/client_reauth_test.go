@@ -1,6 +1,8 @@
 package trino
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"testing"
 
@@ -70,6 +72,23 @@ func TestClientCloseWithNilDB(t *testing.T) {
 	}
 }
 
+func TestEnsureConnectedHonorsCallerContext(t *testing.T) {
+	client := &Client{
+		config:        &config.TrinoConfig{ExternalAuth: true, ExternalAuthTimeout: 300},
+		authenticator: NewExternalAuthenticator("http://localhost:8080", "testuser", 300, false),
+	}
+
+	// A caller that already gave up must not start the browser auth flow
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ensureConnected(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ensureConnected() = %v, want context.Canceled", err)
+	}
+	if client.initialized {
+		t.Error("Expected client to stay uninitialized after a cancelled auth flow")
+	}
+}
+
 func TestLazyAuthClientCreation(t *testing.T) {
 	cfg := &config.TrinoConfig{
 		Host:                "localhost",
@@ -3,6 +3,7 @@ package trino
 import (
 	"context"
 	"crypto/tls"
+	"database/sql"
 	"net/http"
 	"reflect"
 	"strings"
@@ -289,6 +290,71 @@ func TestIsTableAllowed(t *testing.T) {
 	}
 }
 
+func TestCheckQueryTables(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:         "hive",
+		Schema:          "analytics",
+		AllowedCatalogs: []string{"hive", "iceberg"},
+		AllowedSchemas:  []string{"hive.analytics", "iceberg.marts"},
+		AllowedTables:   []string{"hive.analytics.users", "hive.analytics.events", "iceberg.marts.revenue"},
+	}}
+
+	tests := []struct {
+		name        string
+		query       string
+		expectError string
+	}{
+		{"Allowed table", "SELECT * FROM users", ""},
+		{"Allowed join", "SELECT * FROM hive.analytics.users u JOIN iceberg.marts.revenue r ON u.id = r.user_id", ""},
+		{"CTE", "WITH recent AS (SELECT * FROM events) SELECT * FROM recent", ""},
+		{"Metadata table", `SELECT * FROM iceberg.marts."revenue$snapshots"`, ""},
+		{"information_schema", "SELECT * FROM information_schema.tables", ""},
+		{"No tables", "SELECT 1", ""},
+		{"Table outside the allowlist", "SELECT * FROM orders", "table access denied: hive.analytics.orders not in allowlist"},
+		{"Schema outside the allowlist", "SELECT * FROM hive.staging.users", "schema access denied: hive.staging not in allowlist"},
+		{"Catalog outside the allowlist", "SELECT * FROM postgresql.public.accounts", "catalog access denied: postgresql not in allowlist"},
+		{"In a subquery", "SELECT * FROM users WHERE id IN (SELECT user_id FROM hive.analytics.orders)", "hive.analytics.orders not in allowlist"},
+		{"Write target", "INSERT INTO hive.analytics.orders SELECT * FROM users", "hive.analytics.orders not in allowlist"},
+		{"Show stats of a query", "SHOW STATS FOR (SELECT * FROM hive.hr.salaries)", "schema access denied: hive.hr not in allowlist"},
+		{"Describe", "DESCRIBE hive.analytics.orders", "hive.analytics.orders not in allowlist"},
+		{"CTE shadowing a table", "SELECT * FROM orders, (WITH orders AS (SELECT 1 x) SELECT * FROM orders) s", "hive.analytics.orders not in allowlist"},
+		{"Parenthesized table", "SELECT * FROM (hive.secret.users)", "schema access denied: hive.secret not in allowlist"},
+		{"Parenthesized join", "SELECT * FROM users JOIN (hive.secret.users) u ON true", "schema access denied: hive.secret not in allowlist"},
+		{"After a join condition", "SELECT * FROM users u JOIN events e ON u.id = e.user_id, orders", "hive.analytics.orders not in allowlist"},
+		{"Table function", "SELECT * FROM TABLE(postgresql.system.query(query => 'SELECT * FROM accounts'))", "catalog access denied: postgresql not in allowlist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.checkQueryTables(tt.query)
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("checkQueryTables(%q) unexpected error: %v", tt.query, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("checkQueryTables(%q) error = %v, want %q", tt.query, err, tt.expectError)
+			}
+		})
+	}
+
+	// The system catalog's jdbc and metadata tables list every catalog's objects
+	withSystem := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "analytics", AllowedCatalogs: []string{"hive", "system"}}}
+	for _, query := range []string{"SELECT * FROM system.jdbc.tables", "SELECT * FROM system.metadata.table_comments"} {
+		if err := withSystem.checkQueryTables(query); err == nil || !strings.Contains(err.Error(), "metadata access denied") {
+			t.Errorf("checkQueryTables(%q) error = %v, want metadata access denied", query, err)
+		}
+	}
+	if err := withSystem.checkQueryTables("SELECT * FROM system.runtime.queries"); err != nil {
+		t.Errorf("checkQueryTables() of system.runtime error = %v", err)
+	}
+
+	open := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "analytics"}}
+	if err := open.checkQueryTables("SELECT * FROM postgresql.public.accounts"); err != nil {
+		t.Errorf("checkQueryTables() without allowlists error = %v", err)
+	}
+}
+
 func TestTableParameterResolution(t *testing.T) {
 	client := &Client{
 		config: &config.TrinoConfig{
@@ -445,6 +511,36 @@ func TestImprovedIsReadOnlyQuery(t *testing.T) {
 	}
 }
 
+func TestReadOnlyMode(t *testing.T) {
+	// AllowWriteQueries is forced off by the configuration; it is set here to
+	// show read-only mode holds even when it is not
+	client := &Client{config: &config.TrinoConfig{ReadOnly: true, AllowWriteQueries: true}}
+
+	tests := []struct {
+		query     string
+		procedure bool
+		allowed   bool
+	}{
+		{"SELECT * FROM users", false, true},
+		{"SHOW SESSION", false, true},
+		{"INSERT INTO users VALUES (1)", false, false},
+		{"SET SESSION query_max_run_time = '1h'", false, false},
+		{"RESET SESSION query_max_run_time", false, false},
+		{"SET ROLE admin IN hive", false, false},
+		{"USE hive.sales", false, false},
+		{"CALL system.sync_partition_metadata('sales', 'orders', 'ADD')", true, false},
+	}
+	for _, tt := range tests {
+		_, err := client.checkQuery(context.Background(), tt.query, &queryOptions{procedure: tt.procedure})
+		if (err == nil) != tt.allowed {
+			t.Errorf("checkQuery(%q) error = %v, want allowed %v", tt.query, err, tt.allowed)
+		}
+		if queryErr, ok := err.(*QueryError); err != nil && (!ok || queryErr.Name != ErrorPermissionDenied) {
+			t.Errorf("checkQuery(%q) error = %v, want %s", tt.query, err, ErrorPermissionDenied)
+		}
+	}
+}
+
 func TestGetQueryUsername(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -645,3 +741,100 @@ func TestCreateTransport_IndependentInstances(t *testing.T) {
 		t.Error("TLSClientConfig should be different instances")
 	}
 }
+
+func TestEstimateRowSize(t *testing.T) {
+	small := estimateRowSize(map[string]interface{}{"id": 1})
+	large := estimateRowSize(map[string]interface{}{"id": 1, "payload": strings.Repeat("x", 1000)})
+
+	if small <= 0 {
+		t.Errorf("estimateRowSize() = %d, want positive", small)
+	}
+	if large < 1000 {
+		t.Errorf("estimateRowSize() = %d, want at least 1000 for a 1000-byte string", large)
+	}
+}
+
+func TestSetPolicy(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			AllowedCatalogs: []string{"hive"},
+		},
+	}
+	if !client.isCatalogAllowed("hive") || client.isCatalogAllowed("iceberg") {
+		t.Fatal("client should fall back to the configured allowlist")
+	}
+
+	client.SetPolicy(&config.Policy{AllowedCatalogs: []string{"iceberg"}, MaxResultRows: 10})
+	if client.isCatalogAllowed("hive") || !client.isCatalogAllowed("iceberg") {
+		t.Error("reloaded allowlist not applied")
+	}
+	if rows := client.currentPolicy().MaxResultRows; rows != 10 {
+		t.Errorf("MaxResultRows = %d, want 10", rows)
+	}
+
+	// An empty allowlist disables filtering
+	client.SetPolicy(&config.Policy{})
+	if got := client.filterCatalogs([]string{"hive", "iceberg"}); len(got) != 2 {
+		t.Errorf("filterCatalogs() = %v, want no filtering", got)
+	}
+}
+
+func TestSetCredentials(t *testing.T) {
+	db, err := sql.Open("trino", "http://trino@127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	client := &Client{
+		config:      &config.TrinoConfig{Host: "127.0.0.1", Port: 1, Scheme: "http", User: "trino"},
+		db:          db,
+		initialized: true,
+		password:    "old",
+	}
+
+	// Unchanged credentials keep the connection
+	if err := client.SetCredentials("old", ""); err != nil || client.db != db {
+		t.Fatalf("SetCredentials() with the same credentials = %v, want no reconnect", err)
+	}
+
+	// Rotated credentials open a new connection
+	if err := client.SetCredentials("new", ""); err != nil {
+		t.Fatalf("SetCredentials() error = %v", err)
+	}
+	if client.db == db || client.password != "new" {
+		t.Errorf("client kept the old connection or password %q", client.password)
+	}
+	defer client.Close()
+
+	// Before the first connection, the credentials are only stored
+	lazy := &Client{config: client.config}
+	if err := lazy.SetCredentials("new", "jwt"); err != nil || lazy.password != "new" || lazy.accessToken != "jwt" {
+		t.Errorf("SetCredentials() on an unconnected client = %v, stored %q/%q", err, lazy.password, lazy.accessToken)
+	}
+}
+
+// replayableSink records rows and can discard them
+type replayableSink struct{ rows int }
+
+func (s *replayableSink) Begin([]ColumnInfo) error     { return nil }
+func (s *replayableSink) WriteRow([]interface{}) error { s.rows++; return nil }
+func (s *replayableSink) Reset() error                 { s.rows = 0; return nil }
+
+// appendOnlySink records rows but cannot discard them
+type appendOnlySink struct{}
+
+func (appendOnlySink) Begin([]ColumnInfo) error     { return nil }
+func (appendOnlySink) WriteRow([]interface{}) error { return nil }
+
+func TestResetSink(t *testing.T) {
+	if !resetSink(nil) {
+		t.Error("a query without a sink should be replayable")
+	}
+	sink := &replayableSink{rows: 3}
+	if !resetSink(sink) || sink.rows != 0 {
+		t.Errorf("resetSink() should discard the rows of a resettable sink, %d left", sink.rows)
+	}
+	if resetSink(appendOnlySink{}) {
+		t.Error("rows streamed to a sink that cannot reset should not be replayed")
+	}
+}
@@ -0,0 +1,156 @@
+package trino
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// Cluster is a named Trino cluster with its own client and configuration
+type Cluster struct {
+	Name        string
+	Description string
+	Config      *config.TrinoConfig
+	Client      *Client
+
+	breaker   *circuitBreaker // nil unless a routing policy is configured
+	mu        sync.Mutex      // Protects the health fields below
+	unhealthy bool
+	lastError string
+	checkedAt time.Time
+	pingedAt  time.Time
+	pingError string
+}
+
+// Clusters holds a client per configured Trino cluster
+type Clusters struct {
+	clusters    []*Cluster
+	defaultName string
+
+	// Routing state, set when a routing policy is configured
+	policy string
+	pool   []*Cluster // Clusters eligible for routing in priority order
+	next   atomic.Uint64
+
+	// Background health checks and pings, stopped by Close
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewClusters creates a client for every cluster in cfg. Without named clusters
+// a single cluster called config.DefaultClusterName is created from the top-level settings.
+func NewClusters(cfg *config.TrinoConfig) (*Clusters, error) {
+	set := &Clusters{defaultName: cfg.DefaultCluster}
+	for _, name := range cfg.ClusterNames() {
+		clusterCfg, err := cfg.ForCluster(name)
+		if err != nil {
+			_ = set.Close()
+			return nil, err
+		}
+		client, err := NewClient(clusterCfg)
+		if err != nil {
+			_ = set.Close()
+			return nil, fmt.Errorf("cluster '%s': %w", name, err)
+		}
+		set.clusters = append(set.clusters, &Cluster{
+			Name:        name,
+			Description: clusterDescription(cfg, name),
+			Config:      clusterCfg,
+			Client:      client,
+		})
+	}
+	set.configureRouting(cfg)
+	set.startKeepalive(cfg)
+	return set, nil
+}
+
+// clusterDescription returns the configured description of a named cluster
+func clusterDescription(cfg *config.TrinoConfig, name string) string {
+	for _, cl := range cfg.Clusters {
+		if strings.EqualFold(cl.Name, name) {
+			return cl.Description
+		}
+	}
+	return ""
+}
+
+// Get returns the named cluster; an empty name selects the default cluster
+func (s *Clusters) Get(name string) (*Cluster, error) {
+	if name == "" {
+		name = s.defaultName
+	}
+	for _, cl := range s.clusters {
+		if strings.EqualFold(cl.Name, name) {
+			return cl, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown cluster '%s' (available: %s)", name, strings.Join(s.Names(), ", "))
+}
+
+// Default returns the cluster used when a request names none
+func (s *Clusters) Default() *Cluster {
+	cl, err := s.Get("")
+	if err != nil {
+		return s.clusters[0]
+	}
+	return cl
+}
+
+// List returns all clusters in configuration order
+func (s *Clusters) List() []*Cluster {
+	return s.clusters
+}
+
+// Names returns the cluster names in configuration order
+func (s *Clusters) Names() []string {
+	names := make([]string, len(s.clusters))
+	for i, cl := range s.clusters {
+		names[i] = cl.Name
+	}
+	return names
+}
+
+// ApplyPolicy updates every cluster client with the allowlists and result
+// limits of a reloaded configuration. Clients only reconnect when their
+// credentials were rotated; a cluster that rejects the new ones keeps the old.
+func (s *Clusters) ApplyPolicy(cfg *config.TrinoConfig) error {
+	configs := make([]*config.TrinoConfig, len(s.clusters))
+	for i, cl := range s.clusters {
+		clusterCfg, err := cfg.ForCluster(cl.Name)
+		if err != nil {
+			return err
+		}
+		configs[i] = clusterCfg
+	}
+	for i, cl := range s.clusters {
+		cl.Client.SetPolicy(configs[i].Policy())
+		if err := cl.Client.SetCredentials(configs[i].Password, configs[i].AccessToken); err != nil {
+			log.Printf("ERROR: Cluster '%s' rejected the rotated credentials, keeping the current connection: %v", cl.Name, err)
+		}
+	}
+	return nil
+}
+
+// Close stops health checks and pings and closes every cluster client
+func (s *Clusters) Close() error {
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+			s.wg.Wait()
+		}
+	})
+	var errs []error
+	for _, cl := range s.clusters {
+		if err := cl.Client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("cluster '%s': %w", cl.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
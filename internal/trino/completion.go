@@ -0,0 +1,113 @@
+package trino
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// MaxCompletions is the most values a completion returns, the limit MCP sets
+const MaxCompletions = 100
+
+// Completion is the names completing a catalog, schema or table argument
+type Completion struct {
+	Values []string // In name order, at most MaxCompletions
+	Total  int      // Names matching, which may exceed len(Values)
+}
+
+// CompleteWithContext returns the catalogs, schemas or tables within the
+// allowlists whose names start with value, for autocompleting the catalog,
+// schema and table arguments of tools as the user types. Names come from the
+// metadata cache and are listed from Trino on a miss. A table value may be
+// qualified as schema.table or catalog.schema.table; its completions keep the
+// qualifier. catalog and schema are the arguments already given, if any.
+// Other arguments have no completions.
+func (c *Client) CompleteWithContext(ctx context.Context, argument, value, catalog, schema string) (*Completion, error) {
+	if catalog == "" {
+		catalog = c.config.Catalog
+	}
+	switch argument {
+	case "catalog":
+		catalogs, err := c.cachedCatalogs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return completeNames(catalogs, "", value), nil
+
+	case "schema":
+		if len(c.currentPolicy().AllowedCatalogs) > 0 && !c.isCatalogAllowed(catalog) {
+			return &Completion{Values: []string{}}, nil
+		}
+		schemas, err := c.cachedSchemas(ctx, catalog)
+		if err != nil {
+			return nil, err
+		}
+		return completeNames(schemas, "", value), nil
+
+	case "table":
+		parts := strings.Split(value, ".")
+		switch len(parts) {
+		case 1:
+		case 2:
+			schema = parts[0]
+		case 3:
+			catalog, schema = parts[0], parts[1]
+		default:
+			return &Completion{Values: []string{}}, nil
+		}
+		if schema == "" {
+			schema = c.config.DefaultSchema(catalog)
+		}
+		tables, ok := c.metadata.get(metadataKey(ctx, "tables", catalog, schema))
+		if !ok {
+			var err error
+			if tables, err = c.ListTablesWithContext(ctx, catalog, schema); err != nil {
+				return nil, err
+			}
+		}
+		allowed := make([]string, 0, len(tables))
+		for _, table := range tables {
+			if c.checkTableAccess(catalog, schema, table) == nil {
+				allowed = append(allowed, table)
+			}
+		}
+		qualifier := value[:len(value)-len(parts[len(parts)-1])]
+		return completeNames(allowed, qualifier, parts[len(parts)-1]), nil
+	}
+	return &Completion{Values: []string{}}, nil
+}
+
+// cachedCatalogs returns the allowed catalogs, from the metadata cache if there
+func (c *Client) cachedCatalogs(ctx context.Context) ([]string, error) {
+	if catalogs, ok := c.metadata.get(metadataKey(ctx, "catalogs")); ok {
+		return c.filterCatalogs(catalogs), nil
+	}
+	return c.ListCatalogsWithContext(ctx)
+}
+
+// cachedSchemas returns the allowed schemas of a catalog, from the metadata
+// cache if there
+func (c *Client) cachedSchemas(ctx context.Context, catalog string) ([]string, error) {
+	if schemas, ok := c.metadata.get(metadataKey(ctx, "schemas", catalog)); ok {
+		return c.filterSchemas(schemas, catalog), nil
+	}
+	return c.ListSchemasWithContext(ctx, catalog)
+}
+
+// completeNames returns the names starting with prefix, ignoring case, in
+// name order and prefixed with qualifier
+func completeNames(names []string, qualifier, prefix string) *Completion {
+	prefix = strings.ToLower(prefix)
+	values := make([]string, 0)
+	for _, name := range names {
+		if strings.HasPrefix(strings.ToLower(name), prefix) {
+			values = append(values, qualifier+name)
+		}
+	}
+	sort.Strings(values)
+	completion := &Completion{Values: values, Total: len(values)}
+	if len(values) > MaxCompletions {
+		completion.Values = values[:MaxCompletions]
+	}
+	return completion
+}
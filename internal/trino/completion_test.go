@@ -0,0 +1,79 @@
+package trino
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestCompleteWithContext(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	server.Handle("SHOW CATALOGS", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "Catalog", Type: "varchar"}},
+		Rows:    [][]any{{"hive"}, {"iceberg"}, {"system"}},
+	})
+	server.Handle("SHOW SCHEMAS FROM hive", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "Schema", Type: "varchar"}},
+		Rows:    [][]any{{"finance"}, {"sales"}, {"sales_archive"}},
+	})
+	server.Handle("SHOW TABLES FROM hive.sales", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "Table", Type: "varchar"}},
+		Rows:    [][]any{{"orders"}, {"order_items"}, {"customers"}},
+	})
+	client := newMockClient(t, server, "complete", func(cfg *config.TrinoConfig) {
+		cfg.AllowedCatalogs = []string{"hive", "iceberg"}
+		cfg.AllowedTables = []string{"hive.sales.orders", "hive.sales.order_items"}
+	})
+
+	tests := []struct {
+		argument, value, catalog, schema string
+		want                             []string
+	}{
+		{argument: "catalog", value: "", want: []string{"hive", "iceberg"}},
+		{argument: "catalog", value: "HI", want: []string{"hive"}},
+		{argument: "schema", value: "sal", want: []string{"sales", "sales_archive"}},
+		{argument: "schema", value: "", catalog: "system", want: []string{}},
+		{argument: "table", value: "ord", want: []string{"order_items", "orders"}},
+		{argument: "table", value: "", schema: "sales", want: []string{"order_items", "orders"}},
+		{argument: "table", value: "sales.orders", want: []string{"sales.orders"}},
+		{argument: "table", value: "hive.sales.o", want: []string{"hive.sales.order_items", "hive.sales.orders"}},
+		{argument: "table", value: "a.b.c.d", want: []string{}},
+		{argument: "query", value: "SEL", want: []string{}},
+	}
+	for _, tt := range tests {
+		got, err := client.CompleteWithContext(context.Background(), tt.argument, tt.value, tt.catalog, tt.schema)
+		if err != nil {
+			t.Fatalf("CompleteWithContext(%s, %q) error = %v", tt.argument, tt.value, err)
+		}
+		if !slices.Equal(got.Values, tt.want) || got.Total != len(tt.want) {
+			t.Errorf("CompleteWithContext(%s, %q) = %+v, want %v", tt.argument, tt.value, got, tt.want)
+		}
+	}
+
+	// Names are listed once and then completed from the metadata cache
+	shows := 0
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request.Query, "SHOW") {
+			shows++
+		}
+	}
+	if shows != 3 {
+		t.Errorf("%d SHOW statements ran, want one per catalog, schema and table listing", shows)
+	}
+}
+
+func TestCompleteNamesLimit(t *testing.T) {
+	names := make([]string, MaxCompletions+20)
+	for i := range names {
+		names[i] = "t" + strings.Repeat("x", i)
+	}
+	got := completeNames(names, "", "T")
+	if len(got.Values) != MaxCompletions || got.Total != len(names) {
+		t.Errorf("completeNames() returned %d of %d values, want %d of %d", len(got.Values), got.Total, MaxCompletions, len(names))
+	}
+}
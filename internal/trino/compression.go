@@ -0,0 +1,71 @@
+package trino
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// compressionRoundTripper asks Trino for responses in the configured encodings
+// and decompresses them. The transport negotiates only gzip on its own, and
+// not at all once a request sets Accept-Encoding.
+type compressionRoundTripper struct {
+	base           http.RoundTripper
+	acceptEncoding string
+}
+
+func (t *compressionRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", t.acceptEncoding)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil || resp.Body == http.NoBody || req.Method == http.MethodHead {
+		return resp, err
+	}
+	var body io.ReadCloser
+	switch encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); encoding {
+	case config.CompressionGzip:
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("failed to decompress Trino response: %w", err)
+		}
+		body = &decompressedBody{Reader: reader, body: resp.Body, close: func() { _ = reader.Close() }}
+	case config.CompressionZstd:
+		decoder, err := zstd.NewReader(resp.Body, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("failed to decompress Trino response: %w", err)
+		}
+		body = &decompressedBody{Reader: decoder, body: resp.Body, close: decoder.Close}
+	default:
+		return resp, nil
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// decompressedBody reads a response through its decoder and closes both
+type decompressedBody struct {
+	io.Reader
+	body  io.ReadCloser
+	close func()
+}
+
+func (b *decompressedBody) Close() error {
+	b.close()
+	return b.body.Close()
+}
@@ -0,0 +1,60 @@
+package trino
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompressionRoundTripper(t *testing.T) {
+	payload := `{"id":"20240601_000000_00000_abcde","data":[["` + strings.Repeat("text-heavy ", 200) + `"]]}`
+	var accepted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepted = r.Header.Get("Accept-Encoding")
+		var buf bytes.Buffer
+		switch {
+		case strings.HasPrefix(accepted, "zstd"):
+			encoder, _ := zstd.NewWriter(&buf)
+			_, _ = encoder.Write([]byte(payload))
+			_ = encoder.Close()
+			w.Header().Set("Content-Encoding", "zstd")
+		case strings.HasPrefix(accepted, "gzip"):
+			writer := gzip.NewWriter(&buf)
+			_, _ = writer.Write([]byte(payload))
+			_ = writer.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+		default:
+			buf.WriteString(payload)
+		}
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	for _, acceptEncoding := range []string{"zstd, gzip", "gzip", "identity"} {
+		client := &http.Client{Transport: &compressionRoundTripper{base: createTransport(false), acceptEncoding: acceptEncoding}}
+		resp, err := client.Get(server.URL + "/v1/statement/executing/1")
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", acceptEncoding, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: reading body failed: %v", acceptEncoding, err)
+		}
+		if accepted != acceptEncoding {
+			t.Errorf("Accept-Encoding = %q, want %q", accepted, acceptEncoding)
+		}
+		if string(body) != payload {
+			t.Errorf("%s: body was not decompressed: %.40q", acceptEncoding, body)
+		}
+		if resp.Header.Get("Content-Encoding") != "" {
+			t.Errorf("%s: Content-Encoding = %q after decompression", acceptEncoding, resp.Header.Get("Content-Encoding"))
+		}
+	}
+}
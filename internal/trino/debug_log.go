@@ -0,0 +1,92 @@
+package trino
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// debugLogging turns on logging of tool calls, generated SQL and Trino HTTP
+// exchanges, with credentials and string literals redacted
+var debugLogging atomic.Bool
+
+// redactedHeaders are the request headers whose values debug logging hides
+var redactedHeaders = map[string]bool{
+	"Authorization":            true,
+	"Proxy-Authorization":      true,
+	"Cookie":                   true,
+	"X-Trino-Extra-Credential": true,
+}
+
+// SetDebugLogging turns debug logging on or off
+func SetDebugLogging(on bool) {
+	if debugLogging.Swap(on) == on {
+		return
+	}
+	if on {
+		log.Println("INFO: Debug logging enabled")
+	} else {
+		log.Println("INFO: Debug logging disabled")
+	}
+}
+
+// DebugLogging reports whether debug logging is on
+func DebugLogging() bool {
+	return debugLogging.Load()
+}
+
+// Debugf logs a DEBUG line when debug logging is on
+func Debugf(format string, args ...interface{}) {
+	if debugLogging.Load() {
+		log.Printf("DEBUG: "+format, args...)
+	}
+}
+
+// RedactSQL replaces the string literals of a statement with '***', so that
+// logged queries keep their shape but not the values they filter on
+func RedactSQL(query string) string {
+	var out strings.Builder
+	last := 0
+	for _, token := range tokenizeSQL(query) {
+		if token.text == "'" && !token.ident {
+			out.WriteString(query[last:token.start])
+			out.WriteString("'***'")
+			last = token.end
+		}
+	}
+	out.WriteString(query[last:])
+	return out.String()
+}
+
+// redactHeader formats request headers for debug logging, hiding credentials
+func redactHeader(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[REDACTED]"
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", name, value))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// logExchange logs a Trino HTTP request, without its query string, and the
+// response status and time to headers
+func logExchange(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	target := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	if err != nil {
+		Debugf("Trino %s %s failed after %v: %v [%s]", req.Method, target, elapsed.Round(time.Millisecond), err, redactHeader(req.Header))
+		return
+	}
+	Debugf("Trino %s %s -> %s in %v [%s]", req.Method, target, resp.Status, elapsed.Round(time.Millisecond), redactHeader(req.Header))
+}
@@ -0,0 +1,64 @@
+package trino
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestRedactSQL(t *testing.T) {
+	tests := map[string]string{
+		"SELECT * FROM users WHERE email = 'alice@example.com'":      "SELECT * FROM users WHERE email = '***'",
+		"SELECT 'it''s', \"we'ird\" FROM t":                          "SELECT '***', \"we'ird\" FROM t",
+		"SELECT 1 -- don't\nFROM t WHERE x IN ('a', 'b') AND y = 42": "SELECT 1 -- don't\nFROM t WHERE x IN ('***', '***') AND y = 42",
+		"SELECT /* it's */ 'secret'":                                 "SELECT /* it's */ '***'",
+		"SELECT 'unterminated":                                       "SELECT '***'",
+		"SELECT x FROM t /* unterminated 'comment":                   "SELECT x FROM t /* unterminated 'comment",
+	}
+	for query, want := range tests {
+		if got := RedactSQL(query); got != want {
+			t.Errorf("RedactSQL(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestDebugLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := &http.Client{Transport: &headerRoundTripper{base: http.DefaultTransport, config: &config.TrinoConfig{}}}
+
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+	defer SetDebugLogging(false)
+
+	get := func() string {
+		buf.Reset()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/statement/executing/1?token=x", nil)
+		req.SetBasicAuth("alice", "hunter2")
+		req.Header.Set("X-Trino-User", "alice")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return buf.String()
+	}
+
+	if out := get(); strings.Contains(out, "DEBUG") {
+		t.Errorf("logged while debug logging is off: %s", out)
+	}
+	SetDebugLogging(true)
+	out := get()
+	if !strings.Contains(out, "DEBUG: Trino GET "+server.URL+"/v1/statement/executing/1 -> 200 OK") || !strings.Contains(out, "X-Trino-User: alice") {
+		t.Errorf("log = %q, want the request and its headers", out)
+	}
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "Basic ") || strings.Contains(out, "token=x") {
+		t.Errorf("log = %q, want credentials and query string redacted", out)
+	}
+}
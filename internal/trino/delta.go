@@ -0,0 +1,119 @@
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Delta Lake history defaults and bounds
+const (
+	DefaultDeltaHistoryLimit = 20
+	MaxDeltaHistoryLimit     = 100
+)
+
+// DeltaHistory lists the commits of a Delta Lake table, newest first
+type DeltaHistory struct {
+	Table   string              `json:"table"`
+	Entries []DeltaHistoryEntry `json:"entries"`
+}
+
+// DeltaHistoryEntry is a row of the $history metadata table
+type DeltaHistoryEntry struct {
+	Version             int64             `json:"version"`
+	Timestamp           string            `json:"timestamp"`
+	UserName            string            `json:"userName,omitempty"`
+	Operation           string            `json:"operation"`
+	OperationParameters map[string]string `json:"operationParameters,omitempty"`
+	OperationMetrics    map[string]string `json:"operationMetrics,omitempty"` // Rows and files added, removed or updated, when the writer recorded them
+	ReadVersion         *int64            `json:"readVersion,omitempty"`
+	IsolationLevel      string            `json:"isolationLevel,omitempty"`
+	IsBlindAppend       *bool             `json:"isBlindAppend,omitempty"`
+}
+
+// DeltaHistoryWithContext returns up to limit commits of a Delta Lake table
+// from its $history metadata table, optionally only those since a version
+// and of one operation (WRITE, MERGE, DELETE, OPTIMIZE, ...).
+func (c *Client) DeltaHistoryWithContext(ctx context.Context, catalog, schema, table string, limit int, sinceVersion *int64, operation string) (*DeltaHistory, error) {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	if err := c.checkTableAccess(catalog, schema, table); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultDeltaHistoryLimit
+	}
+	if limit > MaxDeltaHistoryLimit {
+		limit = MaxDeltaHistoryLimit
+	}
+	history := &DeltaHistory{Table: catalog + "." + schema + "." + table, Entries: []DeltaHistoryEntry{}}
+	name := quoteIdentifier(catalog) + "." + quoteIdentifier(schema) + "." + quoteIdentifier(table+"$history")
+
+	// operation_metrics is only available in newer Trino versions
+	probe, err := c.ExecuteQueryWithResult(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT 0", name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read $history of %s (is it a Delta Lake table?): %w", history.Table, err)
+	}
+	metrics := "CAST(NULL AS varchar)"
+	for _, column := range probe.Columns {
+		if column == "operation_metrics" {
+			metrics = "json_format(CAST(operation_metrics AS JSON))"
+		}
+	}
+
+	var filters []string
+	var params []interface{}
+	if sinceVersion != nil {
+		filters = append(filters, "version >= ?")
+		params = append(params, *sinceVersion)
+	}
+	if operation != "" {
+		filters = append(filters, "upper(operation) = upper(?)")
+		params = append(params, operation)
+	}
+	where := ""
+	if len(filters) > 0 {
+		where = "WHERE " + strings.Join(filters, " AND ") + "\n"
+	}
+
+	query := fmt.Sprintf(`SELECT version, CAST("timestamp" AS varchar) AS committed_at, user_name, operation,
+  json_format(CAST(operation_parameters AS JSON)) AS operation_parameters, %s AS operation_metrics,
+  read_version, isolation_level, is_blind_append
+FROM %s
+%sORDER BY version DESC LIMIT %d`, metrics, name, where, limit)
+	result, err := c.executeQueryWithRetry(ctx, query, queryOptions{params: params}, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read $history of %s: %w", history.Table, err)
+	}
+
+	for _, row := range result.Rows {
+		entry := DeltaHistoryEntry{
+			Version:             int64Value(row["version"]),
+			Timestamp:           stringValue(row["committed_at"]),
+			UserName:            stringValue(row["user_name"]),
+			Operation:           stringValue(row["operation"]),
+			OperationParameters: jsonStringMap(row["operation_parameters"]),
+			OperationMetrics:    jsonStringMap(row["operation_metrics"]),
+			ReadVersion:         optionalInt64(row["read_version"]),
+			IsolationLevel:      stringValue(row["isolation_level"]),
+		}
+		if blindAppend, ok := row["is_blind_append"].(bool); ok {
+			entry.IsBlindAppend = &blindAppend
+		}
+		history.Entries = append(history.Entries, entry)
+	}
+	return history, nil
+}
+
+// jsonStringMap decodes a map(varchar, varchar) rendered with json_format
+func jsonStringMap(value interface{}) map[string]string {
+	text, ok := value.(string)
+	if !ok || text == "" {
+		return nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(text), &m); err != nil {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,46 @@
+package trino
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestDeltaHistoryAllowlist(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			Catalog:       "delta",
+			Schema:        "default",
+			AllowedTables: []string{"delta.sales.orders"},
+		},
+	}
+
+	_, err := client.DeltaHistoryWithContext(context.Background(), "", "", "sales.customers", 0, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "table access denied: delta.sales.customers") {
+		t.Errorf("DeltaHistoryWithContext() error = %v, want table access denied", err)
+	}
+}
+
+func TestJSONStringMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected map[string]string
+	}{
+		{"Metrics", `{"numDeletedRows":"12","numRemovedFiles":"1"}`, map[string]string{"numDeletedRows": "12", "numRemovedFiles": "1"}},
+		{"Empty map", `{}`, map[string]string{}},
+		{"Null", nil, nil},
+		{"Not JSON", "mode=Append", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonStringMap(tt.value); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("jsonStringMap(%v) = %v, want %v", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
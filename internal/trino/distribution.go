@@ -0,0 +1,319 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultDistributionBuckets is the number of histogram buckets or top values returned by default
+	DefaultDistributionBuckets = 10
+	// MaxDistributionBuckets bounds the histogram buckets or top values returned
+	MaxDistributionBuckets = 100
+	// DistributionSampleRows is the number of rows a distribution is computed
+	// from; larger tables are sampled down to about this many rows
+	DistributionSampleRows = 1_000_000
+	// topValuesCapacity is the capacity of approx_most_frequent's summary
+	topValuesCapacity = 10_000
+)
+
+// Column distribution kinds
+const (
+	DistributionHistogram = "histogram"  // Equal-width buckets of a numeric column
+	DistributionTopValues = "top_values" // Most frequent values of any column
+)
+
+// DistributionOptions controls what ColumnDistributionWithContext computes
+type DistributionOptions struct {
+	Kind    string // DistributionHistogram, DistributionTopValues, or empty to choose by column type
+	Buckets int    // Histogram buckets or top values to return
+	Where   string // Filter applied to the table, e.g. a partition: ds = '2024-01-01'
+}
+
+// ColumnDistribution summarizes the values of a column
+type ColumnDistribution struct {
+	Table          string            `json:"table"`
+	Column         string            `json:"column"`
+	Type           string            `json:"type"`
+	Kind           string            `json:"kind"`
+	Rows           int64             `json:"rows"`                    // Rows of the table after the filter
+	SamplePercent  float64           `json:"samplePercent,omitempty"` // Percentage of rows sampled, when sampled
+	SampledRows    int64             `json:"sampledRows"`             // Rows the distribution was computed from
+	NullFraction   float64           `json:"nullFraction"`
+	DistinctValues int64             `json:"distinctValues"` // Approximate, within the sampled rows
+	Min            string            `json:"min,omitempty"`
+	Max            string            `json:"max,omitempty"`
+	Histogram      []HistogramBucket `json:"histogram,omitempty"`
+	TopValues      []ValueFrequency  `json:"topValues,omitempty"`
+	Queries        []string          `json:"queries"` // The SQL that was run
+}
+
+// HistogramBucket counts the non-null values in [Lower, Upper); the last
+// bucket includes Upper
+type HistogramBucket struct {
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+	Count    int64   `json:"count"`
+	Fraction float64 `json:"fraction"` // Of the non-null sampled values
+}
+
+// ValueFrequency is a frequent value and its approximate count
+type ValueFrequency struct {
+	Value    string  `json:"value"`
+	Count    int64   `json:"count"`
+	Fraction float64 `json:"fraction"` // Of the non-null sampled values
+}
+
+// ColumnDistributionWithContext computes the distribution of a column by
+// generating and running aggregation SQL: null fraction, approximate distinct
+// count, minimum and maximum, and either an equal-width histogram
+// (width_bucket) of a numeric column or its most frequent values
+// (approx_most_frequent). Tables with more than DistributionSampleRows rows
+// after the filter are sampled with TABLESAMPLE BERNOULLI when the connector
+// supports it. Masked columns are rejected.
+func (c *Client) ColumnDistributionWithContext(ctx context.Context, table, column string, opts DistributionOptions) (*ColumnDistribution, error) {
+	kind := strings.ToLower(strings.TrimSpace(opts.Kind))
+	if kind != "" && kind != DistributionHistogram && kind != DistributionTopValues {
+		return nil, fmt.Errorf("invalid kind '%s': use %s or %s", opts.Kind, DistributionHistogram, DistributionTopValues)
+	}
+	buckets := opts.Buckets
+	if buckets <= 0 {
+		buckets = DefaultDistributionBuckets
+	}
+	if buckets > MaxDistributionBuckets {
+		buckets = MaxDistributionBuckets
+	}
+	where := strings.TrimSuffix(strings.TrimSpace(opts.Where), ";")
+
+	ref, err := c.parseDiffTable(table)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkTableAccess(ref.Catalog, ref.Schema, ref.Table); err != nil {
+		return nil, err
+	}
+	if err := c.validateFilter("filter", where, ref); err != nil {
+		return nil, err
+	}
+	columns, err := c.schemaColumns(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns[ref.Table]) == 0 {
+		return nil, fmt.Errorf("table %s not found", ref)
+	}
+	column = strings.ToLower(strings.TrimSpace(column))
+	dist := &ColumnDistribution{Table: ref.String(), Column: column}
+	for _, col := range columns[ref.Table] {
+		if strings.EqualFold(col.Name, column) {
+			dist.Type = col.Type
+		}
+	}
+	switch {
+	case dist.Type == "":
+		return nil, fmt.Errorf("column '%s' not found in %s", column, ref)
+	case maskedByName(c.currentPolicy().ColumnMasks, ref.Table, column):
+		return nil, policyError(ErrorQueryRejected, "Masked columns cannot be profiled",
+			"column masking: masked column '%s' cannot be profiled", column)
+	case kind == DistributionHistogram && !isNumericType(dist.Type):
+		return nil, fmt.Errorf("a histogram needs a numeric column; %s is %s, use kind %s", column, dist.Type, DistributionTopValues)
+	}
+
+	// Sample large tables down to about DistributionSampleRows rows
+	countQuery := fmt.Sprintf("SELECT count(*) AS row_count FROM %s%s", quoteTable(ref), whereClause(where))
+	dist.Queries = append(dist.Queries, countQuery)
+	rows, err := c.ExecuteQueryWithContext(ctx, countQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rows: %w", err)
+	}
+	if len(rows) == 1 {
+		dist.Rows = int64Value(rows[0]["row_count"])
+	}
+	if dist.Rows > DistributionSampleRows {
+		dist.SamplePercent = samplePercent(dist.Rows)
+	}
+
+	err = c.distributionStats(ctx, dist, ref, where)
+	if err != nil && dist.SamplePercent > 0 && isSamplingUnsupported(err) {
+		log.Printf("INFO: TABLESAMPLE not supported for %s, profiling every row: %v", dist.Table, err)
+		dist.SamplePercent = 0
+		err = c.distributionStats(ctx, dist, ref, where)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dist.Kind = kind
+	if kind == "" {
+		dist.Kind = DistributionTopValues
+		if isNumericType(dist.Type) && dist.DistinctValues > int64(buckets) {
+			dist.Kind = DistributionHistogram
+		}
+	}
+	if dist.Kind == DistributionHistogram {
+		return dist, c.distributionHistogram(ctx, dist, ref, where, buckets)
+	}
+	return dist, c.distributionTopValues(ctx, dist, ref, where, buckets)
+}
+
+// distributionStats counts the sampled, null and distinct values of the
+// column and reads its minimum and maximum
+func (c *Client) distributionStats(ctx context.Context, dist *ColumnDistribution, ref TableRef, where string) error {
+	col := quoteIdentifier(dist.Column)
+	items := fmt.Sprintf("count(*) AS row_count, count(%s) AS non_null, approx_distinct(%s) AS distinct_values", col, col)
+	if isOrderableType(dist.Type) {
+		items += fmt.Sprintf(", CAST(min(%s) AS varchar) AS min_value, CAST(max(%s) AS varchar) AS max_value", col, col)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", items, distributionSource(ref, where, dist.SamplePercent))
+	dist.Queries = append(dist.Queries, query)
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to compute column statistics: %w", err)
+	}
+	if len(rows) != 1 {
+		return nil
+	}
+	dist.SampledRows = int64Value(rows[0]["row_count"])
+	if dist.SampledRows > 0 {
+		dist.NullFraction = float64(dist.SampledRows-int64Value(rows[0]["non_null"])) / float64(dist.SampledRows)
+	}
+	dist.DistinctValues = int64Value(rows[0]["distinct_values"])
+	dist.Min = stringValue(rows[0]["min_value"])
+	dist.Max = stringValue(rows[0]["max_value"])
+	return nil
+}
+
+// distributionHistogram counts the values in equal-width buckets between the
+// minimum and maximum
+func (c *Client) distributionHistogram(ctx context.Context, dist *ColumnDistribution, ref TableRef, where string, buckets int) error {
+	lo, errLo := strconv.ParseFloat(dist.Min, 64)
+	hi, errHi := strconv.ParseFloat(dist.Max, 64)
+	nonNull := nonNullRows(dist)
+	switch {
+	case errLo != nil || errHi != nil:
+		return nil // No non-null values
+	case lo == hi:
+		dist.Histogram = []HistogramBucket{{Lower: lo, Upper: hi, Count: nonNull, Fraction: 1}}
+		return nil
+	}
+
+	filter := quoteIdentifier(dist.Column) + " IS NOT NULL"
+	if where != "" {
+		filter += " AND (" + where + ")"
+	}
+	// Values outside the bounds of the first sample fall in the outer buckets
+	query := fmt.Sprintf("SELECT greatest(least(width_bucket(CAST(%s AS double), %s, %s, %d), %d), 1) AS bucket, count(*) AS row_count FROM %s GROUP BY 1 ORDER BY 1",
+		quoteIdentifier(dist.Column), doubleLiteral(lo), doubleLiteral(hi), buckets, buckets,
+		distributionSource(ref, filter, dist.SamplePercent))
+	dist.Queries = append(dist.Queries, query)
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to compute histogram: %w", err)
+	}
+	counts := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		counts[int64Value(row["bucket"])] = int64Value(row["row_count"])
+	}
+	dist.Histogram = histogramBuckets(lo, hi, buckets, counts)
+	return nil
+}
+
+// distributionTopValues finds the most frequent values of the column
+func (c *Client) distributionTopValues(ctx context.Context, dist *ColumnDistribution, ref TableRef, where string, buckets int) error {
+	query := fmt.Sprintf(`SELECT value, row_count FROM (SELECT approx_most_frequent(%d, CAST(%s AS varchar), %d) AS frequent FROM %s)
+CROSS JOIN UNNEST(frequent) AS f(value, row_count)
+ORDER BY row_count DESC, value`, buckets, quoteIdentifier(dist.Column), topValuesCapacity, distributionSource(ref, where, dist.SamplePercent))
+	dist.Queries = append(dist.Queries, query)
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to find top values: %w", err)
+	}
+	nonNull := nonNullRows(dist)
+	for _, row := range rows {
+		value := ValueFrequency{Value: stringValue(row["value"]), Count: int64Value(row["row_count"])}
+		if nonNull > 0 {
+			value.Fraction = float64(value.Count) / float64(nonNull)
+		}
+		dist.TopValues = append(dist.TopValues, value)
+	}
+	return nil
+}
+
+// distributionSource returns the FROM clause of a distribution query: the
+// table, sampled when percent is positive, and the filter
+func distributionSource(ref TableRef, where string, percent float64) string {
+	source := quoteTable(ref)
+	if percent > 0 {
+		source += " TABLESAMPLE BERNOULLI (" + strconv.FormatFloat(percent, 'f', -1, 64) + ")"
+	}
+	return source + whereClause(where)
+}
+
+// samplePercent returns the percentage of rows that samples about
+// DistributionSampleRows rows, rounded up to four decimals
+func samplePercent(rows int64) float64 {
+	return math.Ceil(float64(DistributionSampleRows)/float64(rows)*100*1e4) / 1e4
+}
+
+// histogramBuckets spreads the bucket counts of width_bucket (1 to n) over
+// equal-width ranges, including empty buckets
+func histogramBuckets(lo, hi float64, n int, counts map[int64]int64) []HistogramBucket {
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	width := (hi - lo) / float64(n)
+	histogram := make([]HistogramBucket, n)
+	for i := range histogram {
+		bucket := HistogramBucket{Lower: lo + float64(i)*width, Upper: lo + float64(i+1)*width, Count: counts[int64(i+1)]}
+		if i == n-1 {
+			bucket.Upper = hi
+		}
+		if total > 0 {
+			bucket.Fraction = float64(bucket.Count) / float64(total)
+		}
+		histogram[i] = bucket
+	}
+	return histogram
+}
+
+// nonNullRows returns the number of non-null sampled values
+func nonNullRows(dist *ColumnDistribution) int64 {
+	return int64(math.Round(float64(dist.SampledRows) * (1 - dist.NullFraction)))
+}
+
+// doubleLiteral formats a double as a SQL literal, e.g. 1.5E+03
+func doubleLiteral(v float64) string {
+	return strconv.FormatFloat(v, 'E', -1, 64)
+}
+
+// baseType returns a type name without its parameters, e.g. decimal for decimal(10,2)
+func baseType(typ string) string {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if i := strings.IndexByte(typ, '('); i >= 0 {
+		typ = typ[:i]
+	}
+	return strings.TrimSpace(typ)
+}
+
+// isNumericType reports whether a column type is a number
+func isNumericType(typ string) bool {
+	switch baseType(typ) {
+	case "tinyint", "smallint", "integer", "bigint", "real", "double", "decimal":
+		return true
+	}
+	return false
+}
+
+// isOrderableType reports whether min and max of a column type are meaningful
+func isOrderableType(typ string) bool {
+	switch baseType(typ) {
+	case "varchar", "char", "date", "time", "timestamp", "boolean":
+		return true
+	}
+	return isNumericType(typ)
+}
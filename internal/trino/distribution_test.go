@@ -0,0 +1,84 @@
+package trino
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestDistributionSource(t *testing.T) {
+	ref := TableRef{Catalog: "hive", Schema: "sales", Table: "orders"}
+	tests := []struct {
+		name    string
+		where   string
+		percent float64
+		want    string
+	}{
+		{"Whole table", "", 0, `"hive"."sales"."orders"`},
+		{"Filtered", "ds = '2024-01-01'", 0, `"hive"."sales"."orders" WHERE ds = '2024-01-01'`},
+		{"Sampled and filtered", "ds = '2024-01-01'", 2.5, `"hive"."sales"."orders" TABLESAMPLE BERNOULLI (2.5) WHERE ds = '2024-01-01'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := distributionSource(ref, tt.where, tt.percent); got != tt.want {
+				t.Errorf("distributionSource() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSamplePercent(t *testing.T) {
+	for rows, want := range map[int64]float64{2_000_000: 50, 3_000_000: 33.3334, 1_000_000_000: 0.1} {
+		if got := samplePercent(rows); got != want {
+			t.Errorf("samplePercent(%d) = %v, want %v", rows, got, want)
+		}
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	got := histogramBuckets(0, 100, 4, map[int64]int64{1: 6, 2: 2, 4: 2})
+	want := []HistogramBucket{
+		{Lower: 0, Upper: 25, Count: 6, Fraction: 0.6},
+		{Lower: 25, Upper: 50, Count: 2, Fraction: 0.2},
+		{Lower: 50, Upper: 75, Count: 0, Fraction: 0},
+		{Lower: 75, Upper: 100, Count: 2, Fraction: 0.2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("histogramBuckets() = %+v, want %+v", got, want)
+	}
+}
+
+func TestColumnTypes(t *testing.T) {
+	for typ, want := range map[string][2]bool{
+		"bigint":                      {true, true},
+		"decimal(12,2)":               {true, true},
+		"varchar(25)":                 {false, true},
+		"timestamp(3) with time zone": {false, true},
+		"array(varchar)":              {false, false},
+		"map(varchar, bigint)":        {false, false},
+	} {
+		if got := [2]bool{isNumericType(typ), isOrderableType(typ)}; got != want {
+			t.Errorf("isNumericType, isOrderableType(%s) = %v, want %v", typ, got, want)
+		}
+	}
+}
+
+func TestColumnDistributionRejectsInvalidOptions(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "sales", AllowedCatalogs: []string{"hive"}}}
+	ctx := context.Background()
+
+	if _, err := client.ColumnDistributionWithContext(ctx, "orders", "amount", DistributionOptions{Kind: "quantiles"}); err == nil {
+		t.Error("an unknown kind should fail")
+	}
+	_, err := client.ColumnDistributionWithContext(ctx, "postgres.sales.orders", "amount", DistributionOptions{})
+	if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorPermissionDenied {
+		t.Errorf("ColumnDistributionWithContext() outside the allowlist error = %v, want %s", err, ErrorPermissionDenied)
+	}
+	opts := DistributionOptions{Where: "amount > (SELECT max(balance) FROM postgres.bank.accounts)"}
+	if _, err := client.ColumnDistributionWithContext(ctx, "orders", "amount", opts); err == nil || !strings.Contains(err.Error(), "subqueries are not allowed") {
+		t.Errorf("ColumnDistributionWithContext() with a subquery filter error = %v, want a rejection", err)
+	}
+}
@@ -0,0 +1,129 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// Error names of queries rejected by this server's own policies, matching the
+// names Trino uses for the same situations
+const (
+	ErrorPermissionDenied = "PERMISSION_DENIED"
+	ErrorQueryRejected    = "QUERY_REJECTED"
+)
+
+// QueryError is a failed or rejected query with the details Trino reported:
+// the error name and code, the failing position in the SQL and a hint on how
+// to fix the query
+type QueryError struct {
+	Name        string   `json:"name"`           // Trino error name, e.g. SYNTAX_ERROR, EXCEEDED_LOCAL_MEMORY_LIMIT, PERMISSION_DENIED
+	Code        int      `json:"code,omitempty"` // Trino error code; 0 for rejections by this server
+	Type        string   `json:"type"`           // USER_ERROR, INTERNAL_ERROR, INSUFFICIENT_RESOURCES or EXTERNAL
+	Message     string   `json:"message"`
+	Line        int      `json:"line,omitempty"` // Position of the error in the SQL, when known
+	Column      int      `json:"column,omitempty"`
+	QueryID     string   `json:"queryId,omitempty"`
+	Policy      bool     `json:"policy,omitempty"` // Rejected by this server's configuration rather than by Trino
+	Hint        string   `json:"hint,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"` // Closest existing names, for TABLE_NOT_FOUND and COLUMN_NOT_FOUND
+
+	cause error
+}
+
+// Error returns the message, prefixed with the error name for Trino errors
+func (e *QueryError) Error() string {
+	if e.Policy {
+		return e.Message
+	}
+	return e.Name + ": " + e.Message
+}
+
+// Unwrap returns the driver error a Trino error was built from
+func (e *QueryError) Unwrap() error {
+	return e.cause
+}
+
+// policyError reports a query rejected by this server's configuration
+func policyError(name, hint, format string, args ...interface{}) *QueryError {
+	return &QueryError{Name: name, Type: "USER_ERROR", Message: fmt.Sprintf(format, args...), Policy: true, Hint: hint}
+}
+
+// accessDenied reports a catalog, schema or table outside the allowlists
+func accessDenied(format string, args ...interface{}) *QueryError {
+	return policyError(ErrorPermissionDenied,
+		"This server only exposes the catalogs, schemas and tables in its allowlists; use list_catalogs, list_schemas and list_tables to see them",
+		format, args...)
+}
+
+// queryError converts a failure reported by Trino, or the expiry of the query
+// timeout, into a QueryError; other errors are returned unchanged
+func queryError(err error, queryID string, timeout bool) error {
+	var trinoErr *trino.ErrTrino
+	if errors.As(err, &trinoErr) {
+		location := trinoErr.ErrorLocation
+		if location.LineNumber == 0 {
+			location = trinoErr.FailureInfo.ErrorLocation
+		}
+		e := &QueryError{
+			Name:    trinoErr.ErrorName,
+			Code:    trinoErr.ErrorCode,
+			Type:    trinoErr.ErrorType,
+			Message: trinoErr.Message,
+			Line:    location.LineNumber,
+			Column:  location.ColumnNumber,
+			QueryID: queryID,
+			cause:   err,
+		}
+		e.Hint = errorHint(e.Name, e.Type)
+		return e
+	}
+	if timeout && errors.Is(err, context.DeadlineExceeded) {
+		return &QueryError{
+			Name:    "EXCEEDED_TIME_LIMIT",
+			Type:    "USER_ERROR",
+			Message: "query did not finish within its timeout (TRINO_QUERY_TIMEOUT or timeout_seconds) and was cancelled",
+			QueryID: queryID,
+			Hint:    errorHint("EXCEEDED_TIME_LIMIT", ""),
+			cause:   err,
+		}
+	}
+	return err
+}
+
+// errorHints suggest how to fix a query for common Trino error names
+var errorHints = map[string]string{
+	"SYNTAX_ERROR":          "Check the SQL at the reported line and column; Trino follows ANSI SQL, so quote identifiers with double quotes and strings with single quotes",
+	"COLUMN_NOT_FOUND":      "Use get_table_schema to list the table's columns",
+	"TABLE_NOT_FOUND":       "Use list_tables to find the table; qualify it as catalog.schema.table",
+	"SCHEMA_NOT_FOUND":      "Use list_schemas to find the schema",
+	"CATALOG_NOT_FOUND":     "Use list_catalogs to find the catalog",
+	"FUNCTION_NOT_FOUND":    "Use list_functions to find the function and describe_function for its signatures",
+	"TYPE_MISMATCH":         "Cast the operands to matching types, e.g. CAST(x AS DATE) or CAST(x AS varchar)",
+	"INVALID_CAST_ARGUMENT": "Use TRY_CAST to turn values that cannot be converted into NULL",
+	"DIVISION_BY_ZERO":      "Guard the divisor with NULLIF(divisor, 0)",
+	"PERMISSION_DENIED":     "The Trino user lacks a privilege; use show_grants to see what the server's user and role can access",
+	"NOT_SUPPORTED":         "The connector does not support this operation; check the connector documentation",
+	"EXCEEDED_TIME_LIMIT":   "Filter on partition columns, aggregate before joining, pass a longer timeout_seconds to execute_query, or use export_query for large results",
+	"EXCEEDED_CPU_LIMIT":    "Filter on partition columns or aggregate before joining to reduce the work",
+	"EXCEEDED_SCAN_LIMIT":   "Filter on partition columns to scan less data",
+	"QUERY_QUEUE_FULL":      "The resource group's queue is full; use get_resource_groups and retry later",
+	"CLUSTER_OUT_OF_MEMORY": "The cluster is out of memory; retry later or reduce the data the query processes",
+}
+
+// errorHint returns the hint for a Trino error, falling back to one for the error type
+func errorHint(name, errorType string) string {
+	if hint, ok := errorHints[name]; ok {
+		return hint
+	}
+	switch errorType {
+	case "INSUFFICIENT_RESOURCES":
+		// EXCEEDED_LOCAL_MEMORY_LIMIT, EXCEEDED_GLOBAL_MEMORY_LIMIT, EXCEEDED_SPILL_LIMIT, ...
+		return "Reduce the data the query processes: filter on partition columns, aggregate before joining, or add a LIMIT"
+	case "INTERNAL_ERROR", "EXTERNAL":
+		return "This is not caused by the SQL; retry, and if it persists share the query ID (see find_query) with the Trino operators"
+	}
+	return ""
+}
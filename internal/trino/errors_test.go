@@ -0,0 +1,97 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/trinodb/trino-go-client/trino"
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestQueryError(t *testing.T) {
+	syntaxErr := &trino.ErrQueryFailed{StatusCode: 200, Reason: &trino.ErrTrino{
+		Message:       "line 2:8: mismatched input 'FORM'",
+		ErrorCode:     1,
+		ErrorName:     "SYNTAX_ERROR",
+		ErrorType:     "USER_ERROR",
+		ErrorLocation: trino.ErrorLocation{LineNumber: 2, ColumnNumber: 8},
+	}}
+	err := fmt.Errorf("query execution failed: %w", queryError(syntaxErr, "20250101_120000_00042_abcde", false))
+
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("queryError() = %v, want a QueryError", err)
+	}
+	if queryErr.Name != "SYNTAX_ERROR" || queryErr.Code != 1 || queryErr.Type != "USER_ERROR" {
+		t.Errorf("QueryError = %s/%d/%s, want SYNTAX_ERROR/1/USER_ERROR", queryErr.Name, queryErr.Code, queryErr.Type)
+	}
+	if queryErr.Line != 2 || queryErr.Column != 8 || queryErr.QueryID != "20250101_120000_00042_abcde" {
+		t.Errorf("QueryError position = %d:%d, query ID %q", queryErr.Line, queryErr.Column, queryErr.QueryID)
+	}
+	if queryErr.Hint == "" || queryErr.Policy {
+		t.Errorf("QueryError hint = %q, policy = %v", queryErr.Hint, queryErr.Policy)
+	}
+	if want := "query execution failed: SYNTAX_ERROR: line 2:8: mismatched input 'FORM'"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	var driverErr *trino.ErrQueryFailed
+	if !errors.As(err, &driverErr) {
+		t.Error("QueryError should unwrap to the driver error")
+	}
+}
+
+func TestQueryErrorHints(t *testing.T) {
+	tests := []struct {
+		name      string
+		errorName string
+		errorType string
+		wantHint  bool
+	}{
+		{"Known name", "COLUMN_NOT_FOUND", "USER_ERROR", true},
+		{"Memory limit falls back to the type", "EXCEEDED_LOCAL_MEMORY_LIMIT", "INSUFFICIENT_RESOURCES", true},
+		{"Internal error", "GENERIC_INTERNAL_ERROR", "INTERNAL_ERROR", true},
+		{"Unknown user error", "INVALID_FUNCTION_ARGUMENT", "USER_ERROR", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if hint := errorHint(tt.errorName, tt.errorType); (hint != "") != tt.wantHint {
+				t.Errorf("errorHint(%s, %s) = %q, want hint: %v", tt.errorName, tt.errorType, hint, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestQueryErrorPassthrough(t *testing.T) {
+	networkErr := errors.New("dial tcp: connection refused")
+	if err := queryError(networkErr, "", false); err != networkErr {
+		t.Errorf("queryError() = %v, want the error unchanged", err)
+	}
+
+	// Only the query's own timeout is reported as EXCEEDED_TIME_LIMIT
+	if err := queryError(context.DeadlineExceeded, "", false); err != context.DeadlineExceeded {
+		t.Errorf("queryError() = %v, want the error unchanged", err)
+	}
+	var queryErr *QueryError
+	if err := queryError(context.DeadlineExceeded, "q1", true); !errors.As(err, &queryErr) || queryErr.Name != "EXCEEDED_TIME_LIMIT" {
+		t.Errorf("queryError() = %v, want EXCEEDED_TIME_LIMIT", err)
+	}
+}
+
+func TestPolicyErrors(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{AllowedCatalogs: []string{"hive"}}}
+
+	err := client.checkTableAccess("iceberg", "sales", "orders")
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("checkTableAccess() = %v, want a QueryError", err)
+	}
+	if queryErr.Name != ErrorPermissionDenied || !queryErr.Policy || queryErr.Hint == "" {
+		t.Errorf("QueryError = %+v, want a PERMISSION_DENIED policy error with a hint", queryErr)
+	}
+	if want := "catalog access denied: iceberg not in allowlist"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
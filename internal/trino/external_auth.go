@@ -2,8 +2,8 @@ package trino
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -12,19 +12,94 @@ import (
 	"os/exec"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+const (
+	// defaultTokenPollInterval is how often the token URL is polled during a
+	// login unless TRINO_EXTERNAL_AUTH_POLL_INTERVAL is set
+	defaultTokenPollInterval = 5 * time.Second
+
+	// maxTokenPollBackoff bounds the wait between polls when backing off
+	maxTokenPollBackoff = time.Minute
 )
 
+// Login states, as reported by get_auth_url and LoginError
+const (
+	AuthAuthenticated = "authenticated" // A valid token is cached
+	AuthWaiting       = "waiting"       // A login is waiting for the user to complete it
+	AuthDenied        = "denied"        // The identity provider or Trino refused the login
+	AuthExpired       = "expired"       // The login expired before the user completed it
+)
+
+// LoginError reports why a login ended without a token
+type LoginError struct {
+	State  string // AuthDenied or AuthExpired
+	Reason string
+}
+
+func (e *LoginError) Error() string {
+	return fmt.Sprintf("authentication %s: %s", e.State, e.Reason)
+}
+
 // ExternalAuthenticator handles Trino external authentication (browser OAuth flow)
 type ExternalAuthenticator struct {
-	baseURL    string
-	username   string
-	httpClient *http.Client
-	tokenCache *tokenCache
-	timeout    time.Duration
-	mu         sync.Mutex // Protects concurrent access to tokenCache
+	baseURL      string
+	username     string
+	httpClient   *http.Client
+	tokenCache   *tokenCache
+	timeout      time.Duration
+	pollInterval time.Duration // Wait between polls of Trino's token server
+	login        loginFunc     // Starts a login: Trino's own flow, or PKCE with the IdP
+	headless     bool          // Return the login URL instead of opening a browser and waiting
+	pending      *authFlow     // Background login in progress, nil when none
+	lastLoginErr error         // Why the last background login failed, until one succeeds
+	mu           sync.Mutex    // Protects concurrent access to tokenCache and pending
+}
+
+// loginFunc starts a login and returns the URL the user must open in a
+// browser, and a function that waits for the login's token and its expiry
+type loginFunc func(ctx context.Context) (redirectURL string, wait waitFunc, err error)
+
+// waitFunc waits for the token of a login started by a loginFunc
+type waitFunc func(ctx context.Context) (token string, expiresAt time.Time, err error)
+
+// authFlow is a background login waiting for the user to open its URL
+type authFlow struct {
+	redirectURL string
+	expiresAt   time.Time
+	cancel      context.CancelFunc // Abandons the login, when reauthenticating
+	done        chan struct{}      // Closed when the login ends
+}
+
+// AuthRequiredError reports that a headless login was started and the user
+// must open URL in a browser before retrying
+type AuthRequiredError struct {
+	URL      string
+	Previous error // Why the previous login failed, if it did
+}
+
+func (e *AuthRequiredError) Error() string {
+	message := fmt.Sprintf("Trino sign-in required: open %s in a browser to log in, then retry (get_auth_url shows the link again)", e.URL)
+	if e.Previous != nil {
+		message += fmt.Sprintf("; the previous login failed: %v", e.Previous)
+	}
+	return message
+}
+
+// AuthStatus describes the external authentication state of a client
+type AuthStatus struct {
+	State          string     `json:"state"` // AuthAuthenticated or AuthWaiting
+	Authenticated  bool       `json:"authenticated"`
+	URL            string     `json:"url,omitempty"`          // Login URL to open, when not authenticated
+	URLExpiresAt   *time.Time `json:"urlExpiresAt,omitempty"` // When the login stops waiting for the user
+	TokenExpiresAt *time.Time `json:"tokenExpiresAt,omitempty"`
+	PreviousError  string     `json:"previousError,omitempty"` // Why the previous login failed, e.g. it was denied
 }
 
 // tokenCache holds cached OAuth tokens
@@ -35,18 +110,16 @@ type tokenCache struct {
 
 // NewExternalAuthenticator creates a new external authenticator
 func NewExternalAuthenticator(baseURL, username string, timeoutSecs int, sslInsecure bool) *ExternalAuthenticator {
-	// Create HTTP client with TLS config matching the main Trino connection
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: sslInsecure, //nolint:gosec // User-configurable for self-signed certs
-		},
-	}
-	return &ExternalAuthenticator{
-		baseURL:    baseURL,
-		username:   username,
-		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: transport},
-		timeout:    time.Duration(timeoutSecs) * time.Second,
+	// Create HTTP client with the TLS and proxy settings of the main Trino connection
+	a := &ExternalAuthenticator{
+		baseURL:      baseURL,
+		username:     username,
+		httpClient:   &http.Client{Timeout: 30 * time.Second, Transport: createTransport(sslInsecure)},
+		timeout:      time.Duration(timeoutSecs) * time.Second,
+		pollInterval: defaultTokenPollInterval,
 	}
+	a.login = a.trinoLogin
+	return a
 }
 
 // GetToken retrieves a valid OAuth token, using cache if available
@@ -64,12 +137,44 @@ func (a *ExternalAuthenticator) GetToken(ctx context.Context) (string, error) {
 	// Release lock during long-running auth flow to allow other operations
 	a.mu.Unlock()
 
+	// Join a login started in the background, e.g. by reauthenticate
+	a.mu.Lock()
+	flow := a.pending
+	a.mu.Unlock()
+	if flow != nil && !a.headless {
+		log.Println("INFO: Waiting for the authentication already in progress...")
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-flow.done:
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.tokenCache != nil && time.Now().Before(a.tokenCache.expiresAt) {
+			return a.tokenCache.token, nil
+		}
+		if a.lastLoginErr != nil {
+			return "", fmt.Errorf("failed to get token: %w", a.lastLoginErr)
+		}
+		return "", fmt.Errorf("failed to get token: the login was replaced by a new one")
+	}
+
+	if a.headless {
+		redirectURL, err := a.startBackgroundLogin(ctx)
+		if err != nil {
+			return "", err
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return "", &AuthRequiredError{URL: redirectURL, Previous: a.lastLoginErr}
+	}
+
 	log.Println("INFO: No valid cached token, initiating external authentication flow")
 
 	// Trigger the external auth flow
-	redirectURL, tokenURL, err := a.getAuthURLs(ctx)
+	redirectURL, wait, err := a.login(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get auth URLs: %w", err)
+		return "", err
 	}
 
 	log.Printf("INFO: Opening browser for authentication at: %s", redirectURL)
@@ -82,7 +187,7 @@ func (a *ExternalAuthenticator) GetToken(ctx context.Context) (string, error) {
 
 	// Poll for token
 	log.Println("INFO: Waiting for authentication to complete...")
-	token, err := a.pollForToken(ctx, tokenURL)
+	token, expiresAt, err := wait(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get token: %w", err)
 	}
@@ -96,10 +201,9 @@ func (a *ExternalAuthenticator) GetToken(ctx context.Context) (string, error) {
 		return a.tokenCache.token, nil
 	}
 
-	// Cache the token (assume 1 hour TTL if not specified)
 	a.tokenCache = &tokenCache{
 		token:     token,
-		expiresAt: time.Now().Add(1 * time.Hour),
+		expiresAt: expiresAt,
 	}
 
 	log.Println("INFO: Successfully authenticated and cached token")
@@ -114,6 +218,134 @@ func (a *ExternalAuthenticator) InvalidateToken() {
 	log.Println("INFO: OAuth token cache invalidated")
 }
 
+// Reauthenticate discards the cached token and any login in progress, and
+// starts a new login in the background. Unless headless, it opens the browser.
+// Queries use the new token once the user has logged in.
+func (a *ExternalAuthenticator) Reauthenticate(ctx context.Context) (*AuthStatus, error) {
+	a.mu.Lock()
+	a.tokenCache = nil
+	a.lastLoginErr = nil
+	if a.pending != nil {
+		a.pending.cancel()
+		a.pending = nil
+	}
+	a.mu.Unlock()
+	log.Println("INFO: Reauthenticating - OAuth token cache invalidated")
+
+	redirectURL, err := a.startBackgroundLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !a.headless {
+		log.Printf("INFO: Opening browser for authentication at: %s", redirectURL)
+		if err := openBrowser(redirectURL); err != nil {
+			log.Printf("WARNING: Failed to open browser automatically: %v", err)
+		}
+	}
+	return a.Status(ctx)
+}
+
+// Status reports whether a valid token is cached. Otherwise it starts a
+// headless login, or reuses the one in progress, and returns its URL.
+func (a *ExternalAuthenticator) Status(ctx context.Context) (*AuthStatus, error) {
+	a.mu.Lock()
+	if a.tokenCache != nil && time.Now().Before(a.tokenCache.expiresAt) {
+		expires := a.tokenCache.expiresAt
+		a.mu.Unlock()
+		return &AuthStatus{State: AuthAuthenticated, Authenticated: true, TokenExpiresAt: &expires}, nil
+	}
+	a.mu.Unlock()
+
+	redirectURL, err := a.startBackgroundLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	status := &AuthStatus{State: AuthWaiting, URL: redirectURL}
+	if a.lastLoginErr != nil {
+		status.PreviousError = a.lastLoginErr.Error()
+	}
+	if a.pending != nil {
+		expires := a.pending.expiresAt
+		status.URLExpiresAt = &expires
+	}
+	return status, nil
+}
+
+// startBackgroundLogin returns the URL of the background login in progress, or
+// starts one that polls for the token in the background until
+// TRINO_EXTERNAL_AUTH_TIMEOUT and caches it
+func (a *ExternalAuthenticator) startBackgroundLogin(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	if a.pending != nil {
+		redirectURL := a.pending.redirectURL
+		a.mu.Unlock()
+		return redirectURL, nil
+	}
+	a.mu.Unlock()
+
+	redirectURL, wait, err := a.login(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Another request may have started a login meanwhile; waiting on this one
+	// with a cancelled context releases it
+	if a.pending != nil {
+		abandoned, cancel := context.WithCancel(context.Background())
+		cancel()
+		go func() { _, _, _ = wait(abandoned) }()
+		return a.pending.redirectURL, nil
+	}
+	// Not bound to the request: the login outlives the call that started it
+	flowCtx, cancel := context.WithCancel(context.Background())
+	flow := &authFlow{redirectURL: redirectURL, expiresAt: time.Now().Add(a.timeout), cancel: cancel, done: make(chan struct{})}
+	a.pending = flow
+	log.Printf("INFO: External authentication - waiting for the user to log in at: %s", redirectURL)
+
+	go func() {
+		defer close(flow.done)
+		defer cancel()
+		token, expiresAt, err := wait(flowCtx)
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		// A login replaced by Reauthenticate leaves no trace
+		if a.pending != flow {
+			return
+		}
+		a.pending = nil
+		if err != nil {
+			log.Printf("WARNING: External authentication failed: %v", err)
+			a.lastLoginErr = err
+			return
+		}
+		a.lastLoginErr = nil
+		a.tokenCache = &tokenCache{
+			token:     token,
+			expiresAt: expiresAt,
+		}
+		log.Println("INFO: Successfully authenticated and cached token")
+	}()
+	return redirectURL, nil
+}
+
+// trinoLogin starts Trino's external authentication flow: the token is polled
+// from the x_token_server Trino names in its challenge
+func (a *ExternalAuthenticator) trinoLogin(ctx context.Context) (string, waitFunc, error) {
+	redirectURL, tokenURL, err := a.getAuthURLs(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get auth URLs: %w", err)
+	}
+	return redirectURL, func(ctx context.Context) (string, time.Time, error) {
+		token, err := a.pollForToken(ctx, tokenURL)
+		// Trino does not report the token's lifetime; assume 1 hour
+		return token, time.Now().Add(1 * time.Hour), err
+	}, nil
+}
+
 // getAuthURLs retrieves the OAuth redirect and token URLs from Trino server
 func (a *ExternalAuthenticator) getAuthURLs(ctx context.Context) (redirectURL, tokenURL string, err error) {
 	// Make a request to Trino without auth to trigger 401 with OAuth URLs
@@ -177,77 +409,143 @@ func parseAuthHeader(header string) (redirectURL, tokenURL string) {
 	return redirectURL, tokenURL
 }
 
-// pollForToken polls the token URL until authentication is complete
+// pollForToken polls the token URL until authentication is complete, the
+// login is denied or expires, or TRINO_EXTERNAL_AUTH_TIMEOUT passes. Between
+// polls it waits TRINO_EXTERNAL_AUTH_POLL_INTERVAL, or longer when the token
+// server asks for it with Retry-After or fails.
 func (a *ExternalAuthenticator) pollForToken(ctx context.Context, tokenURL string) (string, error) {
-	pollInterval := 5 * time.Second
-
-	// Try immediately first (user may have already completed auth)
-	token, err := a.tryGetToken(ctx, tokenURL)
-	if err == nil && token != "" {
-		return token, nil
-	}
-	if err != nil {
-		log.Printf("DEBUG: Initial token retrieval attempt failed: %v (will retry)", err)
-	}
-
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
-
 	// Use timer for precise timeout instead of loop condition check
 	timer := time.NewTimer(a.timeout)
 	defer timer.Stop()
 
+	// Try immediately first (user may have already completed auth)
+	var delay time.Duration
+	backoff := a.pollInterval
 	for {
+		wait := time.NewTimer(delay)
 		select {
 		case <-ctx.Done():
+			wait.Stop()
 			return "", ctx.Err()
 		case <-timer.C:
-			return "", fmt.Errorf("authentication timeout: user did not complete authentication within %v", a.timeout)
-		case <-ticker.C:
-			token, err := a.tryGetToken(ctx, tokenURL)
-			if err == nil && token != "" {
-				return token, nil
-			}
-			if err != nil {
-				log.Printf("DEBUG: Token retrieval attempt failed: %v (will retry)", err)
-			}
+			wait.Stop()
+			return "", &LoginError{State: AuthExpired, Reason: fmt.Sprintf("user did not complete authentication within %v", a.timeout)}
+		case <-wait.C:
+		}
+
+		poll, err := a.tryGetToken(ctx, tokenURL)
+		var loginErr *LoginError
+		switch {
+		case errors.As(err, &loginErr):
+			return "", err
+		case err != nil:
+			// Network or server errors: retry, backing off
+			log.Printf("DEBUG: Token retrieval attempt failed: %v (will retry)", err)
+			backoff = min(backoff*2, maxTokenPollBackoff)
+			delay = backoff
+			continue
+		case poll.token != "":
+			return poll.token, nil
+		}
+
+		if poll.nextURI != "" {
+			tokenURL = poll.nextURI
+		}
+		switch {
+		case poll.retryAfter > 0:
+			delay = poll.retryAfter
+		case poll.throttled:
+			backoff = min(backoff*2, maxTokenPollBackoff)
+			delay = backoff
+		default:
+			backoff = a.pollInterval
+			delay = a.pollInterval
 		}
 	}
 }
 
-// tryGetToken attempts to retrieve the token from the token URL
-func (a *ExternalAuthenticator) tryGetToken(ctx context.Context, tokenURL string) (string, error) {
+// tokenPoll is a response of the token server while the login is in progress
+type tokenPoll struct {
+	token      string
+	nextURI    string        // Where to poll next, when the server says
+	retryAfter time.Duration // How long the server asked to wait, if it did
+	throttled  bool          // The server is rate limiting or overloaded
+}
+
+// tryGetToken attempts to retrieve the token from the token URL. It returns
+// a *LoginError when the login was denied or has expired.
+func (a *ExternalAuthenticator) tryGetToken(ctx context.Context, tokenURL string) (*tokenPoll, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", tokenURL, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	resp, err := a.httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	// 200 means token is ready
-	if resp.StatusCode == http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return "", err
-		}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	// Trino answers {"nextUri": ...} while waiting, then {"token": ...} or {"error": ...}
+	var tokenResp struct {
+		Token   string `json:"token"`
+		NextURI string `json:"nextUri"`
+		Error   string `json:"error"`
+	}
+	jsonErr := json.Unmarshal(body, &tokenResp)
+	poll := &tokenPoll{nextURI: tokenResp.NextURI, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
 
-		// Parse token from response
-		var tokenResp struct {
-			Token string `json:"token"`
-		}
-		if err := json.Unmarshal(body, &tokenResp); err != nil {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if jsonErr != nil {
 			// Token might be plain text
-			return strings.TrimSpace(string(body)), nil
+			poll.token = strings.TrimSpace(string(body))
+			return poll, nil
+		}
+		if tokenResp.Error != "" {
+			return nil, &LoginError{State: AuthDenied, Reason: tokenResp.Error}
 		}
-		return tokenResp.Token, nil
+		poll.token = tokenResp.Token
+		return poll, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, &LoginError{State: AuthDenied, Reason: loginReason(resp.StatusCode, tokenResp.Error)}
+	case http.StatusGone:
+		return nil, &LoginError{State: AuthExpired, Reason: loginReason(resp.StatusCode, tokenResp.Error)}
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		poll.throttled = true
+		return poll, nil
+	case http.StatusNotFound:
+		// Some token servers answer 404 until the login completes
+		return poll, nil
 	}
+	return nil, fmt.Errorf("token not ready (status: %d)", resp.StatusCode)
+}
 
-	// 404 or other codes mean not ready yet
-	return "", fmt.Errorf("token not ready (status: %d)", resp.StatusCode)
+// loginReason describes a failed token server response
+func loginReason(statusCode int, message string) string {
+	if message != "" {
+		return message
+	}
+	return fmt.Sprintf("token server returned HTTP %d", statusCode)
+}
+
+// parseRetryAfter returns the delay of a Retry-After header, given in seconds
+// or as an HTTP date, or 0 when there is none
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(header)); err == nil && seconds > 0 {
+		return min(time.Duration(seconds)*time.Second, maxTokenPollBackoff)
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return min(date.Sub(now), maxTokenPollBackoff)
+	}
+	return 0
 }
 
 // openBrowser opens the specified URL in the default browser
@@ -304,3 +602,10 @@ func IsAuthenticationError(err error) bool {
 	}
 	return false
 }
+
+// IsUnauthorized reports whether Trino rejected the credentials of a request
+// (HTTP 401), as opposed to the connection problems IsAuthenticationError also covers
+func IsUnauthorized(err error) bool {
+	var queryFailed *trino.ErrQueryFailed
+	return errors.As(err, &queryFailed) && queryFailed.StatusCode == http.StatusUnauthorized
+}
@@ -2,7 +2,11 @@ package trino
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -271,3 +275,197 @@ func TestConcurrentInvalidateAndGetToken(t *testing.T) {
 	wg.Wait()
 	// If we get here without -race detecting issues, the test passes
 }
+
+func TestHeadlessExternalAuth(t *testing.T) {
+	var mu sync.Mutex
+	loggedIn, challenges := false, 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/statement":
+			challenges++
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer x_redirect_server="%s/oauth2/token/initiate/abc", x_token_server="%s/oauth2/token/abc"`, server.URL, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/oauth2/token/abc":
+			if !loggedIn {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"token":"sso-token"}`)
+		}
+	}))
+	defer server.Close()
+
+	auth := NewExternalAuthenticator(server.URL, "testuser", 300, false)
+	auth.headless = true
+	auth.pollInterval = 10 * time.Millisecond
+	wantURL := server.URL + "/oauth2/token/initiate/abc"
+
+	// The first query starts a login and returns its URL instead of waiting
+	_, err := auth.GetToken(context.Background())
+	var authRequired *AuthRequiredError
+	if !errors.As(err, &authRequired) || authRequired.URL != wantURL {
+		t.Fatalf("GetToken() error = %v, want AuthRequiredError for %s", err, wantURL)
+	}
+
+	// Later calls return the same login until the user completes it
+	status, err := auth.Status(context.Background())
+	if err != nil || status.Authenticated || status.URL != wantURL || status.URLExpiresAt == nil {
+		t.Errorf("Status() = %+v, %v; want the pending login", status, err)
+	}
+	mu.Lock()
+	if challenges != 1 {
+		t.Errorf("started %d logins, want 1", challenges)
+	}
+	loggedIn = true
+	mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		token, err := auth.GetToken(context.Background())
+		if err == nil {
+			if token != "sso-token" {
+				t.Errorf("GetToken() = %q, want sso-token", token)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetToken() still failing after login: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status, err := auth.Status(context.Background()); err != nil || !status.Authenticated || status.URL != "" {
+		t.Errorf("Status() after login = %+v, %v; want authenticated", status, err)
+	}
+}
+
+func TestPollForTokenStates(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls++
+		switch r.URL.Path {
+		case "/token/waiting":
+			// Overloaded once, then Trino's long-poll response naming the next URL
+			if polls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintf(w, `{"nextUri":"http://%s/token/ready"}`, r.Host)
+		case "/token/ready":
+			fmt.Fprint(w, `{"token":"sso-token"}`)
+		case "/token/denied":
+			fmt.Fprint(w, `{"error":"user denied access"}`)
+		case "/token/expired":
+			w.WriteHeader(http.StatusGone)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	auth := NewExternalAuthenticator(server.URL, "testuser", 1, false)
+	auth.pollInterval = 10 * time.Millisecond
+
+	token, err := auth.pollForToken(context.Background(), server.URL+"/token/waiting")
+	if err != nil || token != "sso-token" {
+		t.Errorf("pollForToken() = %q, %v; want the token from nextUri", token, err)
+	}
+
+	for _, tt := range []struct{ path, state, reason string }{
+		{path: "/token/denied", state: AuthDenied, reason: "user denied access"},
+		{path: "/token/expired", state: AuthExpired, reason: "HTTP 410"},
+		{path: "/token/unknown", state: AuthExpired, reason: "within 1s"},
+	} {
+		_, err := auth.pollForToken(context.Background(), server.URL+tt.path)
+		var loginErr *LoginError
+		if !errors.As(err, &loginErr) || loginErr.State != tt.state || !strings.Contains(loginErr.Reason, tt.reason) {
+			t.Errorf("pollForToken(%s) = %v, want %s (%s)", tt.path, err, tt.state, tt.reason)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{header: "", want: 0},
+		{header: "7", want: 7 * time.Second},
+		{header: "3600", want: maxTokenPollBackoff},
+		{header: "Sat, 01 Jun 2024 12:00:30 GMT", want: 30 * time.Second},
+		{header: "Sat, 01 Jun 2024 11:00:00 GMT", want: 0},
+		{header: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestReauthenticate(t *testing.T) {
+	var mu sync.Mutex
+	logins, ready := 0, 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/v1/statement" {
+			logins++
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer x_redirect_server="%s/initiate/%d", x_token_server="%s/token/%d"`, server.URL, logins, server.URL, logins))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var login int
+		fmt.Sscanf(r.URL.Path, "/token/%d", &login)
+		if login > ready {
+			fmt.Fprintf(w, `{"nextUri":"%s%s"}`, server.URL, r.URL.Path)
+			return
+		}
+		fmt.Fprintf(w, `{"token":"token-%d"}`, login)
+	}))
+	defer server.Close()
+
+	auth := NewExternalAuthenticator(server.URL, "testuser", 300, false)
+	auth.headless = true
+	auth.pollInterval = 10 * time.Millisecond
+	auth.tokenCache = &tokenCache{token: "revoked", expiresAt: time.Now().Add(time.Hour)}
+
+	// The cached token is dropped and a new login started right away
+	status, err := auth.Reauthenticate(context.Background())
+	if err != nil || status.State != AuthWaiting || status.URL != server.URL+"/initiate/1" {
+		t.Fatalf("Reauthenticate() = %+v, %v; want a new login", status, err)
+	}
+	if _, err := auth.GetToken(context.Background()); !errors.As(err, new(*AuthRequiredError)) {
+		t.Errorf("GetToken() after Reauthenticate() = %v, want the login to be required", err)
+	}
+
+	// Reauthenticating again replaces the login in progress
+	if status, err = auth.Reauthenticate(context.Background()); err != nil || status.URL != server.URL+"/initiate/2" {
+		t.Fatalf("second Reauthenticate() = %+v, %v; want another login", status, err)
+	}
+	mu.Lock()
+	ready = 2
+	mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		token, err := auth.GetToken(context.Background())
+		if err == nil {
+			if token != "token-2" {
+				t.Errorf("GetToken() = %q, want the token of the latest login", token)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetToken() still failing after login: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
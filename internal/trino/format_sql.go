@@ -0,0 +1,513 @@
+package trino
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keyword cases FormatSQL writes keywords in
+const (
+	KeywordCaseUpper = "upper"
+	KeywordCaseLower = "lower"
+)
+
+// formatIndent indents each level of formatted SQL
+const formatIndent = "  "
+
+// formatKeywords are the words FormatSQL writes in the keyword case.
+// Unquoted identifiers are case-insensitive in Trino, so changing their case
+// never changes a statement's meaning; words that are common column names are
+// left out or only recognized in context.
+var formatKeywords = map[string]bool{
+	"add": true, "all": true, "alter": true, "analyze": true, "and": true, "any": true, "as": true,
+	"asc": true, "at": true, "between": true, "by": true, "call": true, "cascade": true, "case": true,
+	"cast": true, "constraint": true, "create": true, "cross": true, "cube": true,
+	"current_catalog": true, "current_date": true, "current_path": true, "current_role": true,
+	"current_schema": true, "current_time": true, "current_timestamp": true, "current_user": true,
+	"deallocate": true, "delete": true, "desc": true, "describe": true, "distinct": true, "drop": true,
+	"else": true, "end": true, "escape": true, "except": true, "execute": true, "exists": true,
+	"explain": true, "extract": true, "false": true, "fetch": true, "filter": true, "following": true,
+	"for": true, "from": true, "full": true, "grant": true, "group": true, "grouping": true,
+	"having": true, "if": true, "ignore": true, "in": true, "inner": true, "insert": true,
+	"intersect": true, "interval": true, "into": true, "is": true, "join": true, "lateral": true,
+	"left": true, "like": true, "limit": true, "localtime": true, "localtimestamp": true,
+	"matched": true, "materialized": true, "merge": true, "natural": true, "not": true, "null": true,
+	"nulls": true, "offset": true, "on": true, "or": true, "order": true, "ordinality": true,
+	"outer": true, "over": true, "partition": true, "preceding": true, "prepare": true,
+	"recursive": true, "replace": true, "respect": true, "revoke": true, "right": true, "rollup": true,
+	"rows": true, "select": true, "set": true, "sets": true, "show": true, "some": true, "table": true,
+	"tablesample": true, "then": true, "to": true, "true": true, "unbounded": true, "union": true,
+	"unnest": true, "update": true, "using": true, "values": true, "when": true, "where": true,
+	"window": true, "with": true, "within": true, "without": true,
+}
+
+// formatContextKeywords are keywords only after one of the listed words, such
+// as FIRST in NULLS FIRST
+var formatContextKeywords = map[string][]string{
+	"first": {"nulls", "fetch"},
+	"last":  {"nulls"},
+	"next":  {"fetch"},
+	"only":  {"rows", "row"},
+	"ties":  {"with"},
+	"zone":  {"time"},
+	"time":  {"with", "without", "at"},
+}
+
+// formatTypeKeywords are keywords when they start a typed literal, as in DATE '2024-01-01'
+var formatTypeKeywords = map[string]bool{"date": true, "time": true, "timestamp": true}
+
+// formatCallKeywords are keywords written like function calls, without a
+// space before their parenthesis
+var formatCallKeywords = map[string]bool{
+	"cast": true, "extract": true, "if": true, "grouping": true, "replace": true, "unnest": true,
+	"table": true, "date": true, "time": true, "timestamp": true, "any": true, "some": true,
+}
+
+// formatJoinWords start a join
+var formatJoinWords = map[string]bool{
+	"join": true, "left": true, "right": true, "full": true, "inner": true, "cross": true, "natural": true,
+}
+
+// formatOperators are the operators longer than one character
+var formatOperators = []string{"<=", ">=", "<>", "!=", "||", "->", "=>"}
+
+type formatTokenKind int
+
+const (
+	formatWord formatTokenKind = iota
+	formatQuoted
+	formatString
+	formatNumber
+	formatOperator
+	formatPunct
+	formatLineComment
+	formatBlockComment
+)
+
+// formatToken is a token of a statement being formatted, with its text as written
+type formatToken struct {
+	kind     formatTokenKind
+	text     string
+	adjacent bool // No whitespace separates it from the previous token
+}
+
+// lower returns the lower-cased text of a word, or "" for other tokens
+func (t *formatToken) lower() string {
+	if t == nil || t.kind != formatWord {
+		return ""
+	}
+	return strings.ToLower(t.text)
+}
+
+// is reports whether the token is the given punctuation or operator
+func (t *formatToken) is(text string) bool {
+	return t != nil && (t.kind == formatPunct || t.kind == formatOperator) && t.text == text
+}
+
+// lexSQL splits statements into tokens, keeping comments and literals as written
+func lexSQL(query string) ([]formatToken, error) {
+	var tokens []formatToken
+	adjacent := false
+	i, n := 0, len(query)
+	for i < n {
+		ch := query[i]
+		start := i
+		var kind formatTokenKind
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+			adjacent = false
+			continue
+		case ch == '-' && i+1 < n && query[i+1] == '-':
+			for i < n && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+			kind = formatLineComment
+		case ch == '/' && i+1 < n && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at position %d", start+1)
+			}
+			i += end + 4
+			kind = formatBlockComment
+		case ch == '\'' || ch == '"':
+			// '' and "" escape the quote
+			i++
+			for {
+				if i >= n {
+					if ch == '\'' {
+						return nil, fmt.Errorf("unterminated string literal at position %d", start+1)
+					}
+					return nil, fmt.Errorf("unterminated quoted identifier at position %d", start+1)
+				}
+				if query[i] == ch {
+					if i+1 < n && query[i+1] == ch {
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			kind = formatString
+			if ch == '"' {
+				kind = formatQuoted
+			}
+		case ch >= '0' && ch <= '9' || ch == '.' && i+1 < n && query[i+1] >= '0' && query[i+1] <= '9' && !endsValue(tokens, adjacent):
+			for i < n && (isIdentChar(query[i]) || query[i] == '.') {
+				// Exponents may be signed, as in 1e-3
+				if (query[i] == 'e' || query[i] == 'E') && i+1 < n && (query[i+1] == '-' || query[i+1] == '+') {
+					i++
+				}
+				i++
+			}
+			kind = formatNumber
+		case isIdentChar(ch):
+			for i < n && isIdentChar(query[i]) {
+				i++
+			}
+			kind = formatWord
+		case strings.ContainsRune("()[],;.", rune(ch)):
+			i++
+			kind = formatPunct
+		default:
+			i++
+			for _, op := range formatOperators {
+				if strings.HasPrefix(query[start:], op) {
+					i = start + len(op)
+					break
+				}
+			}
+			kind = formatOperator
+		}
+		tokens = append(tokens, formatToken{kind: kind, text: query[start:i], adjacent: adjacent && len(tokens) > 0})
+		adjacent = true
+	}
+	return tokens, nil
+}
+
+// endsValue reports whether the last token ends a value directly before the
+// current position, so that a following dot qualifies it rather than starting
+// a number
+func endsValue(tokens []formatToken, adjacent bool) bool {
+	if len(tokens) == 0 || !adjacent {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind == formatWord || last.kind == formatQuoted || last.is(")") || last.is("]")
+}
+
+// formatFrame is a statement, subquery or parenthesized expression being formatted
+type formatFrame struct {
+	paren      bool   // An expression in parentheses or brackets, written on one line
+	subquery   bool   // A parenthesized query, whose closing parenthesis goes on its own line
+	indent     int    // Indent of the clauses of a statement or subquery
+	openIndent int    // Indent of the line a subquery's parenthesis opened on
+	clause     string // Clause being written
+	listItems  bool   // Commas separate items on their own lines
+	listBreak  bool   // The next token starts the first item on a new line
+	between    int    // BETWEEN operators waiting for their AND
+	cases      int    // Open CASE expressions
+	tokens     int
+}
+
+// sqlFormatter writes tokens as formatted SQL
+type sqlFormatter struct {
+	buf        []byte
+	frames     []*formatFrame
+	lineIndent int
+	prev       *formatToken // Last token written, comments aside
+	prevUnary  bool         // prev is a unary plus or minus
+	upper      bool
+}
+
+// FormatSQL reformats SQL statements canonically without executing them:
+// keywords in keywordCase (KeywordCaseUpper by default), clauses on their own
+// lines, select lists and AND/OR conditions one per line and subqueries
+// indented. Literals, quoted identifiers and comments are kept as written;
+// the statements' meaning does not change. FormatSQL only tokenizes SQL, so
+// it formats statements that do not parse as best it can.
+func FormatSQL(query, keywordCase string) (string, error) {
+	switch strings.ToLower(keywordCase) {
+	case "", KeywordCaseUpper, KeywordCaseLower:
+	default:
+		return "", fmt.Errorf("invalid keyword case '%s': must be %s or %s", keywordCase, KeywordCaseUpper, KeywordCaseLower)
+	}
+	tokens, err := lexSQL(query)
+	if err != nil {
+		return "", err
+	}
+
+	f := &sqlFormatter{upper: strings.ToLower(keywordCase) != KeywordCaseLower}
+	f.frames = []*formatFrame{{}}
+	for i := range tokens {
+		var next *formatToken
+		for j := i + 1; j < len(tokens); j++ {
+			if tokens[j].kind != formatLineComment && tokens[j].kind != formatBlockComment {
+				next = &tokens[j]
+				break
+			}
+		}
+		f.token(&tokens[i], next)
+	}
+	return strings.TrimRight(string(f.buf), " \n"), nil
+}
+
+func (f *sqlFormatter) frame() *formatFrame {
+	return f.frames[len(f.frames)-1]
+}
+
+// newline starts a new line at the indent level, replacing the indent of an
+// empty current line
+func (f *sqlFormatter) newline(indent int) {
+	for len(f.buf) > 0 && f.buf[len(f.buf)-1] == ' ' {
+		f.buf = f.buf[:len(f.buf)-1]
+	}
+	if len(f.buf) > 0 && f.buf[len(f.buf)-1] != '\n' {
+		f.buf = append(f.buf, '\n')
+	}
+	if len(f.buf) > 0 {
+		f.buf = append(f.buf, strings.Repeat(formatIndent, indent)...)
+	}
+	f.lineIndent = indent
+}
+
+// atLineStart reports whether nothing but indentation is on the current line
+func (f *sqlFormatter) atLineStart() bool {
+	for i := len(f.buf) - 1; i >= 0; i-- {
+		switch f.buf[i] {
+		case ' ':
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// write appends text, after a space unless the line is empty or space is false
+func (f *sqlFormatter) write(text string, space bool) {
+	if space && !f.atLineStart() {
+		f.buf = append(f.buf, ' ')
+	}
+	f.buf = append(f.buf, text...)
+}
+
+// keyword reports whether a word is written as a keyword
+func (f *sqlFormatter) keyword(t, next *formatToken) bool {
+	word := t.lower()
+	if word == "" || f.prev.is(".") || next.is(".") {
+		return false
+	}
+	if formatTypeKeywords[word] && next != nil && next.kind == formatString {
+		return true
+	}
+	if word == "current" || word == "row" {
+		// CURRENT ROW of a window frame
+		return word == "current" && next.lower() == "row" || word == "row" && f.prev.lower() == "current"
+	}
+	if after, ok := formatContextKeywords[word]; ok {
+		for _, w := range after {
+			if f.prev.lower() == w {
+				return true
+			}
+		}
+		return false
+	}
+	return formatKeywords[word]
+}
+
+// space reports whether a space separates the token from the previous one
+func (f *sqlFormatter) space(t *formatToken) bool {
+	prev := f.prev
+	switch {
+	case prev == nil || f.prevUnary:
+		return false
+	case t.is(")") || t.is("]") || t.is(",") || t.is(";") || t.is("."):
+		return false
+	case prev.is("(") || prev.is("[") || prev.is("."):
+		return false
+	case t.is("("):
+		// Function calls and type parameters take no space; clauses and operators do
+		if f.frame().clause == "target" {
+			// The column list of INSERT INTO t (a, b) or CREATE TABLE t (a bigint)
+			return true
+		}
+		if prev.kind == formatWord {
+			word := prev.lower()
+			return formatKeywords[word] && !formatCallKeywords[word]
+		}
+		return prev.kind != formatQuoted
+	case t.is("["):
+		return !(prev.kind == formatWord || prev.kind == formatQuoted || prev.is(")") || prev.is("]"))
+	case t.kind == formatString && t.adjacent && prev.kind == formatWord:
+		// Prefixed literals such as X'00ff'
+		return false
+	}
+	return true
+}
+
+// unary reports whether a plus or minus is a sign rather than an operator
+func (f *sqlFormatter) unary(t *formatToken) bool {
+	if !t.is("-") && !t.is("+") {
+		return false
+	}
+	prev := f.prev
+	switch {
+	case prev == nil:
+		return true
+	case prev.kind == formatOperator || prev.is("(") || prev.is("[") || prev.is(","):
+		return true
+	case prev.kind == formatWord:
+		return formatKeywords[prev.lower()]
+	}
+	return false
+}
+
+// clauseStart reports whether a keyword starts a clause of a statement or subquery
+func (f *sqlFormatter) clauseStart(word string, next *formatToken) bool {
+	fr := f.frame()
+	prev := f.prev.lower()
+	switch word {
+	case "select", "where", "having", "limit", "offset", "fetch", "window", "values", "union", "intersect", "except":
+		return true
+	case "from":
+		// Not in DELETE FROM or IS DISTINCT FROM
+		return prev != "delete" && prev != "distinct"
+	case "group", "order":
+		return next.lower() == "by"
+	case "with":
+		return fr.tokens == 0
+	case "set":
+		return fr.clause == "update"
+	}
+	if formatJoinWords[word] && !formatJoinWords[prev] && prev != "outer" {
+		switch next.lower() {
+		case "join", "outer", "left", "right", "full", "inner":
+			return true
+		}
+		return word == "join"
+	}
+	return false
+}
+
+func (f *sqlFormatter) token(t, next *formatToken) {
+	fr := f.frame()
+
+	switch t.kind {
+	case formatLineComment:
+		f.write(t.text, true)
+		f.newline(f.lineIndent)
+		return
+	case formatBlockComment:
+		f.write(t.text, true)
+		return
+	}
+
+	word := ""
+	if f.keyword(t, next) {
+		word = t.lower()
+	}
+
+	if fr.listBreak {
+		if word == "distinct" || word == "all" || word == "by" {
+			f.emit(t, word)
+			return
+		}
+		fr.listBreak = false
+		f.newline(fr.indent + 1)
+	}
+
+	clauses := !fr.paren
+	switch {
+	case clauses && word != "" && f.clauseStart(word, next):
+		f.newline(fr.indent)
+		f.emit(t, word)
+		fr.clause = word
+		fr.listItems = word == "select" || word == "group" || word == "order" || word == "from"
+		fr.listBreak = word == "select" || word == "group" || word == "order"
+		fr.between, fr.cases = 0, 0
+		if formatJoinWords[word] {
+			fr.clause = "join"
+		}
+		return
+	case clauses && (word == "and" || word == "or") && fr.cases == 0:
+		if word == "and" && fr.between > 0 {
+			fr.between--
+			break
+		}
+		switch fr.clause {
+		case "where", "having", "on", "join":
+			f.newline(fr.indent + 1)
+		}
+	case clauses && word == "between":
+		fr.between++
+	case clauses && word == "case":
+		fr.cases++
+	case clauses && word == "end" && fr.cases > 0:
+		fr.cases--
+	case clauses && (word == "on" || word == "update"):
+		fr.clause = word
+	case clauses && (word == "into" || word == "table" && (f.prev.lower() == "create" || f.prev.lower() == "exists")):
+		// A table name follows
+		f.emit(t, word)
+		fr.clause = "target"
+		return
+	case t.is(","):
+		f.emit(t, word)
+		if clauses && fr.cases == 0 {
+			switch {
+			case fr.clause == "with":
+				f.newline(fr.indent)
+			case fr.listItems:
+				f.newline(fr.indent + 1)
+			}
+		}
+		return
+	case t.is("(") || t.is("["):
+		f.emit(t, word)
+		if fr.clause == "target" {
+			fr.clause = ""
+		}
+		if t.is("(") && (next.lower() == "select" || next.lower() == "with" || next.lower() == "values") {
+			f.frames = append(f.frames, &formatFrame{subquery: true, indent: f.lineIndent + 1, openIndent: f.lineIndent})
+		} else {
+			f.frames = append(f.frames, &formatFrame{paren: true, indent: fr.indent})
+		}
+		return
+	case t.is(")") || t.is("]"):
+		if len(f.frames) > 1 {
+			f.frames = f.frames[:len(f.frames)-1]
+			if fr.subquery {
+				f.newline(fr.openIndent)
+			}
+		}
+		f.emit(t, word)
+		return
+	case t.is(";"):
+		f.emit(t, word)
+		f.frames = []*formatFrame{{}}
+		f.buf = append(f.buf, "\n\n"...)
+		f.lineIndent = 0
+		f.prev = nil
+		return
+	}
+	f.emit(t, word)
+}
+
+// emit writes a token, in the keyword case when word is set
+func (f *sqlFormatter) emit(t *formatToken, word string) {
+	text := t.text
+	if word != "" {
+		text = strings.ToLower(text)
+		if f.upper {
+			text = strings.ToUpper(text)
+		}
+	}
+	unary := f.unary(t)
+	f.write(text, f.space(t))
+	f.prev, f.prevUnary = t, unary
+	f.frame().tokens++
+}
@@ -0,0 +1,139 @@
+package trino
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Clauses and select list",
+			query: "select a, b as c, count(*) from hive.s.t t1 where a > 1 and b like 'x%' group by 1, 2 order by 3 desc nulls last limit 10",
+			want: `SELECT
+  a,
+  b AS c,
+  count(*)
+FROM hive.s.t t1
+WHERE a > 1
+  AND b LIKE 'x%'
+GROUP BY
+  1,
+  2
+ORDER BY
+  3 DESC NULLS LAST
+LIMIT 10`,
+		},
+		{
+			name:  "Joins and BETWEEN",
+			query: "SELECT o.id FROM orders o LEFT OUTER JOIN users u ON o.user_id=u.id AND u.active WHERE o.day BETWEEN DATE '2024-01-01' AND DATE '2024-01-31' OR o.id IS NULL",
+			want: `SELECT
+  o.id
+FROM orders o
+LEFT OUTER JOIN users u ON o.user_id = u.id
+  AND u.active
+WHERE o.day BETWEEN DATE '2024-01-01' AND DATE '2024-01-31'
+  OR o.id IS NULL`,
+		},
+		{
+			name:  "CTEs and subqueries",
+			query: "with x as (select 1 a), y as (select * from x) select * from y where a in (select a from x) union all select 2",
+			want: `WITH x AS (
+  SELECT
+    1 a
+),
+y AS (
+  SELECT
+    *
+  FROM x
+)
+SELECT
+  *
+FROM y
+WHERE a IN (
+  SELECT
+    a
+  FROM x
+)
+UNION ALL
+SELECT
+  2`,
+		},
+		{
+			name:  "Expressions stay on one line",
+			query: "select case when a>1 and a<3 then -1 else a end, sum(x) over (partition by a order by b rows between unbounded preceding and current row), cast(x as timestamp(3)), arr[1], x.first from t",
+			want: `SELECT
+  CASE WHEN a > 1 AND a < 3 THEN -1 ELSE a END,
+  sum(x) OVER (PARTITION BY a ORDER BY b ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW),
+  CAST(x AS timestamp(3)),
+  arr[1],
+  x.first
+FROM t`,
+		},
+		{
+			name:  "Literals, quoted identifiers and comments are kept",
+			query: "-- daily totals\nselect \"Select\", 'it''s  FROM', X'00ff', 1.5e-3 /* keep */ from \"My Table\"",
+			want: `-- daily totals
+SELECT
+  "Select",
+  'it''s  FROM',
+  X'00ff',
+  1.5e-3 /* keep */
+FROM "My Table"`,
+		},
+		{
+			name:  "Statements",
+			query: "create table if not exists c.s.t (a bigint, b varchar(3)); insert into t (a, b) values (1, 'x'), (2, 'y');",
+			want: `CREATE TABLE IF NOT EXISTS c.s.t (a bigint, b varchar(3));
+
+INSERT INTO t (a, b)
+VALUES (1, 'x'), (2, 'y');`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatSQL(tt.query, "")
+			if err != nil {
+				t.Fatalf("FormatSQL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatSQL() =\n%s\nwant\n%s", got, tt.want)
+			}
+			// Formatting is stable, so formatted SQL diffs cleanly
+			again, err := FormatSQL(got, "")
+			if err != nil {
+				t.Fatalf("FormatSQL() of formatted SQL error = %v", err)
+			}
+			if again != got {
+				t.Errorf("FormatSQL() of formatted SQL =\n%s\nwant it unchanged", again)
+			}
+		})
+	}
+}
+
+func TestFormatSQLKeywordCase(t *testing.T) {
+	got, err := FormatSQL("SELECT A FROM T WHERE B IS NULL", KeywordCaseLower)
+	if err != nil {
+		t.Fatalf("FormatSQL() error = %v", err)
+	}
+	if want := "select\n  A\nfrom T\nwhere B is null"; got != want {
+		t.Errorf("FormatSQL() = %q, want %q", got, want)
+	}
+
+	if _, err := FormatSQL("SELECT 1", "title"); err == nil {
+		t.Error("FormatSQL() accepted an invalid keyword case")
+	}
+}
+
+func TestFormatSQLErrors(t *testing.T) {
+	for _, query := range []string{"SELECT 'open", `SELECT "open`, "SELECT 1 /* open"} {
+		if _, err := FormatSQL(query, ""); err == nil || !strings.Contains(err.Error(), "unterminated") {
+			t.Errorf("FormatSQL(%q) error = %v, want an unterminated token error", query, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FunctionSummary is a function listed by ListFunctionsWithContext; overloads
+// of the same name are merged
+type FunctionSummary struct {
+	Name         string `json:"name"`
+	FunctionType string `json:"functionType"` // scalar, aggregate, window or table
+	Overloads    int    `json:"overloads"`
+	Description  string `json:"description,omitempty"`
+}
+
+// FunctionSignature is a row of SHOW FUNCTIONS
+type FunctionSignature struct {
+	Name          string `json:"name"`
+	ReturnType    string `json:"returnType"`
+	ArgumentTypes string `json:"argumentTypes"`
+	FunctionType  string `json:"functionType"`
+	Deterministic bool   `json:"deterministic"`
+	Description   string `json:"description,omitempty"`
+}
+
+// ListFunctionsWithContext lists the functions whose name matches pattern, a
+// LIKE pattern; a pattern without % matches names containing it. Without a
+// catalog and schema the built-in and global functions are listed, otherwise
+// the functions (UDFs) registered in that schema.
+func (c *Client) ListFunctionsWithContext(ctx context.Context, catalog, schema, pattern string) ([]FunctionSummary, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern != "" && !strings.Contains(pattern, "%") {
+		pattern = "%" + pattern + "%"
+	}
+	signatures, err := c.showFunctions(ctx, catalog, schema, pattern, false)
+	if err != nil {
+		return nil, err
+	}
+
+	functions := []FunctionSummary{}
+	index := make(map[string]int)
+	for _, signature := range signatures {
+		key := signature.Name + "\x00" + signature.FunctionType
+		if i, ok := index[key]; ok {
+			functions[i].Overloads++
+			if functions[i].Description == "" {
+				functions[i].Description = signature.Description
+			}
+			continue
+		}
+		index[key] = len(functions)
+		functions = append(functions, FunctionSummary{
+			Name:         signature.Name,
+			FunctionType: signature.FunctionType,
+			Overloads:    1,
+			Description:  signature.Description,
+		})
+	}
+	sort.SliceStable(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions, nil
+}
+
+// DescribeFunctionWithContext returns every signature of a function. The name
+// may be qualified as catalog.schema.function for functions registered in a
+// catalog.
+func (c *Client) DescribeFunctionWithContext(ctx context.Context, name string) ([]FunctionSignature, error) {
+	name = strings.TrimSpace(name)
+	var catalog, schema string
+	parts := strings.Split(name, ".")
+	switch len(parts) {
+	case 1:
+	case 3:
+		catalog, schema, name = parts[0], parts[1], parts[2]
+	default:
+		return nil, fmt.Errorf("invalid function name '%s': use function or catalog.schema.function", name)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("function name is required")
+	}
+
+	signatures, err := c.showFunctions(ctx, catalog, schema, name, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(signatures) == 0 {
+		return nil, fmt.Errorf("function '%s' not found; use list_functions to search for it", name)
+	}
+	return signatures, nil
+}
+
+// showFunctions runs SHOW FUNCTIONS, optionally from a catalog schema and
+// restricted to names matching pattern (exactly, when exact is set)
+func (c *Client) showFunctions(ctx context.Context, catalog, schema, pattern string, exact bool) ([]FunctionSignature, error) {
+	query := "SHOW FUNCTIONS"
+	if catalog != "" || schema != "" {
+		if catalog == "" {
+			catalog = c.config.Catalog
+		}
+		if schema == "" {
+			return nil, fmt.Errorf("schema is required to list the functions of catalog %s", catalog)
+		}
+		policy := c.currentPolicy()
+		if len(policy.AllowedCatalogs) > 0 && !c.isCatalogAllowed(catalog) {
+			return nil, accessDenied("catalog access denied: %s not in allowlist", catalog)
+		}
+		if len(policy.AllowedSchemas) > 0 && !c.isSchemaAllowed(catalog, schema) {
+			return nil, accessDenied("schema access denied: %s.%s not in allowlist", catalog, schema)
+		}
+		query += fmt.Sprintf(" FROM %s.%s", quoteIdentifier(catalog), quoteIdentifier(schema))
+	}
+	if pattern != "" {
+		if exact {
+			pattern = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(pattern)
+		}
+		query += fmt.Sprintf(` LIKE %s ESCAPE '\'`, quoteLiteral(strings.ToLower(pattern)))
+	}
+
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	signatures := make([]FunctionSignature, 0, len(rows))
+	for _, row := range rows {
+		deterministic, _ := row["Deterministic"].(bool)
+		signatures = append(signatures, FunctionSignature{
+			Name:          stringValue(row["Function"]),
+			ReturnType:    stringValue(row["Return Type"]),
+			ArgumentTypes: stringValue(row["Argument Types"]),
+			FunctionType:  stringValue(row["Function Type"]),
+			Deterministic: deterministic,
+			Description:   stringValue(row["Description"]),
+		})
+	}
+	return signatures, nil
+}
+
+// quoteLiteral quotes a SQL string literal, escaping embedded quotes
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
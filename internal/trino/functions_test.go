@@ -0,0 +1,52 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestDescribeFunctionValidation(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			Catalog:         "hive",
+			Schema:          "default",
+			AllowedCatalogs: []string{"hive"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		function    string
+		expectError string
+	}{
+		{"Two-part name", "analytics.mask_email", "invalid function name 'analytics.mask_email'"},
+		{"Empty name", " ", "function name is required"},
+		{"Catalog outside allowlist", "postgres.public.mask_email", "catalog access denied: postgres"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.DescribeFunctionWithContext(context.Background(), tt.function)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("DescribeFunctionWithContext(%q) error = %v, want %q", tt.function, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestListFunctionsRequiresSchema(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "default"}}
+	_, err := client.ListFunctionsWithContext(context.Background(), "hive", "", "")
+	if err == nil || !strings.Contains(err.Error(), "schema is required") {
+		t.Errorf("ListFunctionsWithContext() error = %v, want schema is required", err)
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	if got := quoteLiteral("it's"); got != "'it''s'" {
+		t.Errorf("quoteLiteral() = %s, want 'it''s'", got)
+	}
+}
@@ -0,0 +1,69 @@
+package trino
+
+import (
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+)
+
+// maxRedirects is how many redirects a Trino request follows, like net/http
+const maxRedirects = 10
+
+// affinityJar keeps the cookies of a cluster, such as a gateway's sticky
+// session, and can forget them so that the gateway picks a backend again
+type affinityJar struct {
+	mu  sync.Mutex
+	jar *cookiejar.Jar
+}
+
+func newAffinityJar() *affinityJar {
+	jar, _ := cookiejar.New(nil) // Fails only with options
+	return &affinityJar{jar: jar}
+}
+
+func (j *affinityJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+func (j *affinityJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.jar.Cookies(u)
+}
+
+// reset forgets every cookie
+func (j *affinityJar) reset() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar, _ = cookiejar.New(nil)
+}
+
+// gatewayRedirect follows a Trino Gateway redirect to a backend with the
+// original credentials, which net/http drops when the host changes. It never
+// sends them from https to http.
+func gatewayRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	original := via[0]
+	auth := original.Header.Get("Authorization")
+	if auth == "" || req.Header.Get("Authorization") != "" {
+		return nil
+	}
+	if original.URL.Scheme == "https" && req.URL.Scheme != "https" {
+		return nil
+	}
+	req.Header.Set("Authorization", auth)
+	return nil
+}
+
+// resetAffinity forgets the backend the gateway pinned this client to
+func (c *Client) resetAffinity() {
+	if c != nil && c.jar != nil {
+		c.jar.reset()
+	}
+}
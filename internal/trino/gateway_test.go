@@ -0,0 +1,115 @@
+package trino
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestGatewayAffinity(t *testing.T) {
+	var cookie, routingGroup, source string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			// The gateway pins the query to a backend on the initial POST
+			http.SetCookie(w, &http.Cookie{Name: "trino-backend", Value: "b2", Path: "/"})
+			return
+		}
+		if c, err := r.Cookie("trino-backend"); err == nil {
+			cookie = c.Value
+		}
+		routingGroup = r.Header.Get("X-Trino-Routing-Group")
+		source = r.Header.Get("X-Trino-Source")
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	client, err := NewClient(&config.TrinoConfig{
+		Host: u.Hostname(), Port: port, Scheme: "http", User: "test", ClusterName: "gateway-test",
+		TrinoSource:  "mcp-trino/test",
+		ExternalAuth: true,
+		HTTPHeaders:  map[string]string{"X-Trino-Routing-Group": "adhoc", "X-Trino-Source": "ignored"},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	resp, err := client.httpClient.Post(server.URL+"/v1/statement", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	resp, err = client.httpClient.Get(server.URL + "/v1/statement/executing/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if cookie != "b2" {
+		t.Errorf("nextUri request cookie = %q, want the gateway's affinity cookie", cookie)
+	}
+	if routingGroup != "adhoc" {
+		t.Errorf("X-Trino-Routing-Group = %q, want the configured header", routingGroup)
+	}
+	if source != "mcp-trino/test" {
+		t.Errorf("X-Trino-Source = %q, want mcp-trino's own header to take precedence", source)
+	}
+}
+
+func TestGatewayRedirect(t *testing.T) {
+	var auth, body, routingGroup string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		routingGroup = r.Header.Get("X-Trino-Routing-Group")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer backend.Close()
+	// Redirect to another host name, for which net/http drops credentials
+	backendURL := strings.Replace(backend.URL, "127.0.0.1", "localhost", 1)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, backendURL+r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer gateway.Close()
+
+	client := &http.Client{
+		CheckRedirect: gatewayRedirect,
+		Transport: &headerRoundTripper{
+			base:   http.DefaultTransport,
+			config: &config.TrinoConfig{Gateway: true, RoutingGroup: "adhoc"},
+		},
+	}
+	req, _ := http.NewRequestWithContext(WithQueryLabels(context.Background(), QueryLabels{RoutingGroup: "etl"}),
+		http.MethodPost, gateway.URL+"/v1/statement", strings.NewReader("SELECT 1"))
+	req.SetBasicAuth("alice", "secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if body != "SELECT 1" {
+		t.Errorf("backend received body %q, want the query re-sent", body)
+	}
+	if auth == "" {
+		t.Error("backend received no Authorization header")
+	}
+	if routingGroup != "etl" {
+		t.Errorf("X-Trino-Routing-Group = %q, want the request's routing group", routingGroup)
+	}
+
+	// Credentials are not sent from https to http
+	https, _ := http.NewRequest(http.MethodPost, "https://gateway.example.com/v1/statement", nil)
+	https.SetBasicAuth("alice", "secret")
+	downgrade, _ := http.NewRequest(http.MethodPost, "http://backend.example.com/v1/statement", nil)
+	if err := gatewayRedirect(downgrade, []*http.Request{https}); err != nil || downgrade.Header.Get("Authorization") != "" {
+		t.Errorf("redirect to http: Authorization = %q, err = %v; want none", downgrade.Header.Get("Authorization"), err)
+	}
+}
@@ -0,0 +1,305 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Iceberg metadata tables summarized by IcebergMetadataWithContext
+const (
+	IcebergSnapshots  = "snapshots"
+	IcebergHistory    = "history"
+	IcebergPartitions = "partitions"
+	IcebergFiles      = "files"
+)
+
+// Iceberg metadata defaults and bounds
+const (
+	DefaultIcebergLimit     = 20
+	MaxIcebergLimit         = 100
+	DefaultSmallFileSizeMiB = 100 // Matches the file_size_threshold of Trino's optimize procedure
+)
+
+// IcebergMetadata summarizes one metadata table of an Iceberg table
+type IcebergMetadata struct {
+	Table      string                 `json:"table"`
+	Metadata   string                 `json:"metadata"`
+	Snapshots  []IcebergSnapshot      `json:"snapshots,omitempty"`
+	History    []IcebergHistoryEntry  `json:"history,omitempty"`
+	Partitions *IcebergPartitionStats `json:"partitions,omitempty"`
+	Files      *IcebergFileStats      `json:"files,omitempty"`
+}
+
+// IcebergSnapshot is a row of $snapshots with the commonly used summary fields
+type IcebergSnapshot struct {
+	CommittedAt      string `json:"committedAt"`
+	SnapshotID       int64  `json:"snapshotId"`
+	ParentID         *int64 `json:"parentId,omitempty"`
+	Operation        string `json:"operation"`
+	AddedDataFiles   *int64 `json:"addedDataFiles,omitempty"`
+	AddedRecords     *int64 `json:"addedRecords,omitempty"`
+	DeletedDataFiles *int64 `json:"deletedDataFiles,omitempty"`
+	DeletedRecords   *int64 `json:"deletedRecords,omitempty"`
+	TotalDataFiles   *int64 `json:"totalDataFiles,omitempty"`
+	TotalRecords     *int64 `json:"totalRecords,omitempty"`
+	TotalFilesSize   *int64 `json:"totalFilesSize,omitempty"`
+}
+
+// IcebergHistoryEntry is a row of $history
+type IcebergHistoryEntry struct {
+	MadeCurrentAt     string `json:"madeCurrentAt"`
+	SnapshotID        int64  `json:"snapshotId"`
+	ParentID          *int64 `json:"parentId,omitempty"`
+	IsCurrentAncestor bool   `json:"isCurrentAncestor"`
+}
+
+// IcebergPartitionStats summarizes $partitions
+type IcebergPartitionStats struct {
+	PartitionCount int64              `json:"partitionCount"`
+	RecordCount    int64              `json:"recordCount"`
+	FileCount      int64              `json:"fileCount"`
+	TotalSize      int64              `json:"totalSize"`
+	Largest        []IcebergPartition `json:"largest,omitempty"` // Largest partitions by size
+	Note           string             `json:"note,omitempty"`
+}
+
+// IcebergPartition is a row of $partitions
+type IcebergPartition struct {
+	Partition   string `json:"partition"` // Partition values as a JSON object
+	RecordCount int64  `json:"recordCount"`
+	FileCount   int64  `json:"fileCount"`
+	TotalSize   int64  `json:"totalSize"`
+}
+
+// IcebergFileStats summarizes $files
+type IcebergFileStats struct {
+	DataFiles          int64 `json:"dataFiles"`
+	DataSize           int64 `json:"dataSize"`
+	RecordCount        int64 `json:"recordCount"`
+	MinFileSize        int64 `json:"minFileSize"`
+	MaxFileSize        int64 `json:"maxFileSize"`
+	AvgFileSize        int64 `json:"avgFileSize"`
+	SmallFiles         int64 `json:"smallFiles"`         // Data files below SmallFileThreshold
+	SmallFileThreshold int64 `json:"smallFileThreshold"` // Bytes
+	DeleteFiles        int64 `json:"deleteFiles"`        // Position and equality delete files
+}
+
+// IcebergMetadataWithContext queries one of the $snapshots, $history,
+// $partitions or $files metadata tables of an Iceberg table and summarizes it.
+// limit bounds the snapshots, history entries and partitions listed;
+// smallFileSize is the size in bytes below which a data file counts as small.
+func (c *Client) IcebergMetadataWithContext(ctx context.Context, catalog, schema, table, metadata string, limit int, smallFileSize int64) (*IcebergMetadata, error) {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	if err := c.checkTableAccess(catalog, schema, table); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultIcebergLimit
+	}
+	if limit > MaxIcebergLimit {
+		limit = MaxIcebergLimit
+	}
+	if smallFileSize <= 0 {
+		smallFileSize = DefaultSmallFileSizeMiB << 20
+	}
+
+	// Metadata tables are addressed as "table$name"
+	metadataTable := func(name string) string {
+		return quoteIdentifier(catalog) + "." + quoteIdentifier(schema) + "." + quoteIdentifier(table+"$"+name)
+	}
+	result := &IcebergMetadata{Table: catalog + "." + schema + "." + table, Metadata: metadata}
+
+	var err error
+	switch metadata {
+	case IcebergSnapshots:
+		result.Snapshots, err = c.icebergSnapshots(ctx, metadataTable(IcebergSnapshots), limit)
+	case IcebergHistory:
+		result.History, err = c.icebergHistory(ctx, metadataTable(IcebergHistory), limit)
+	case IcebergPartitions:
+		result.Partitions, err = c.icebergPartitions(ctx, metadataTable(IcebergPartitions), limit, c.hasColumnMasks(catalog, schema, table))
+	case IcebergFiles:
+		result.Files, err = c.icebergFiles(ctx, metadataTable(IcebergFiles), smallFileSize)
+	default:
+		return nil, fmt.Errorf("invalid metadata '%s': must be snapshots, history, partitions or files", metadata)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read $%s of %s (is it an Iceberg table?): %w", metadata, result.Table, err)
+	}
+	return result, nil
+}
+
+func (c *Client) icebergSnapshots(ctx context.Context, name string, limit int) ([]IcebergSnapshot, error) {
+	rows, err := c.ExecuteQueryWithContext(ctx, fmt.Sprintf(`SELECT CAST(committed_at AS varchar) AS committed_at, snapshot_id, parent_id, operation,
+  CAST(element_at(summary, 'added-data-files') AS bigint) AS added_data_files,
+  CAST(element_at(summary, 'added-records') AS bigint) AS added_records,
+  CAST(element_at(summary, 'deleted-data-files') AS bigint) AS deleted_data_files,
+  CAST(element_at(summary, 'deleted-records') AS bigint) AS deleted_records,
+  CAST(element_at(summary, 'total-data-files') AS bigint) AS total_data_files,
+  CAST(element_at(summary, 'total-records') AS bigint) AS total_records,
+  CAST(element_at(summary, 'total-files-size') AS bigint) AS total_files_size
+FROM %s ORDER BY committed_at DESC LIMIT %d`, name, limit))
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]IcebergSnapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshots = append(snapshots, IcebergSnapshot{
+			CommittedAt:      stringValue(row["committed_at"]),
+			SnapshotID:       int64Value(row["snapshot_id"]),
+			ParentID:         optionalInt64(row["parent_id"]),
+			Operation:        stringValue(row["operation"]),
+			AddedDataFiles:   optionalInt64(row["added_data_files"]),
+			AddedRecords:     optionalInt64(row["added_records"]),
+			DeletedDataFiles: optionalInt64(row["deleted_data_files"]),
+			DeletedRecords:   optionalInt64(row["deleted_records"]),
+			TotalDataFiles:   optionalInt64(row["total_data_files"]),
+			TotalRecords:     optionalInt64(row["total_records"]),
+			TotalFilesSize:   optionalInt64(row["total_files_size"]),
+		})
+	}
+	return snapshots, nil
+}
+
+func (c *Client) icebergHistory(ctx context.Context, name string, limit int) ([]IcebergHistoryEntry, error) {
+	rows, err := c.ExecuteQueryWithContext(ctx, fmt.Sprintf(`SELECT CAST(made_current_at AS varchar) AS made_current_at, snapshot_id, parent_id, is_current_ancestor
+FROM %s ORDER BY made_current_at DESC LIMIT %d`, name, limit))
+	if err != nil {
+		return nil, err
+	}
+	history := make([]IcebergHistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		ancestor, _ := row["is_current_ancestor"].(bool)
+		history = append(history, IcebergHistoryEntry{
+			MadeCurrentAt:     stringValue(row["made_current_at"]),
+			SnapshotID:        int64Value(row["snapshot_id"]),
+			ParentID:          optionalInt64(row["parent_id"]),
+			IsCurrentAncestor: ancestor,
+		})
+	}
+	return history, nil
+}
+
+func (c *Client) icebergPartitions(ctx context.Context, name string, limit int, masked bool) (*IcebergPartitionStats, error) {
+	totals, err := c.ExecuteQueryWithContext(ctx, fmt.Sprintf(`SELECT count(*) AS partition_count, coalesce(sum(record_count), 0) AS record_count,
+  coalesce(sum(file_count), 0) AS file_count, coalesce(sum(total_size), 0) AS total_size
+FROM %s`, name))
+	if err != nil {
+		return nil, err
+	}
+	stats := &IcebergPartitionStats{}
+	if len(totals) > 0 {
+		stats.PartitionCount = int64Value(totals[0]["partition_count"])
+		stats.RecordCount = int64Value(totals[0]["record_count"])
+		stats.FileCount = int64Value(totals[0]["file_count"])
+		stats.TotalSize = int64Value(totals[0]["total_size"])
+	}
+
+	// Unpartitioned tables have no partition column
+	probe, err := c.ExecuteQueryWithResult(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT 0", name))
+	if err != nil {
+		return nil, err
+	}
+	partitioned := false
+	for _, column := range probe.Columns {
+		partitioned = partitioned || column == "partition"
+	}
+	switch {
+	case !partitioned:
+		stats.Note = "table is not partitioned"
+		return stats, nil
+	case masked:
+		// Partition values could reveal masked columns
+		stats.Note = "partition values are hidden because the table has masked columns"
+		return stats, nil
+	}
+
+	rows, err := c.ExecuteQueryWithContext(ctx, fmt.Sprintf(`SELECT json_format(CAST(partition AS JSON)) AS partition, record_count, file_count, total_size
+FROM %s ORDER BY total_size DESC LIMIT %d`, name, limit))
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		stats.Largest = append(stats.Largest, IcebergPartition{
+			Partition:   stringValue(row["partition"]),
+			RecordCount: int64Value(row["record_count"]),
+			FileCount:   int64Value(row["file_count"]),
+			TotalSize:   int64Value(row["total_size"]),
+		})
+	}
+	return stats, nil
+}
+
+func (c *Client) icebergFiles(ctx context.Context, name string, smallFileSize int64) (*IcebergFileStats, error) {
+	// content is 0 for data files, 1 and 2 for position and equality deletes
+	rows, err := c.ExecuteQueryWithContext(ctx, fmt.Sprintf(`SELECT count(*) FILTER (WHERE content = 0) AS data_files,
+  coalesce(sum(file_size_in_bytes) FILTER (WHERE content = 0), 0) AS data_size,
+  coalesce(sum(record_count) FILTER (WHERE content = 0), 0) AS record_count,
+  coalesce(min(file_size_in_bytes) FILTER (WHERE content = 0), 0) AS min_file_size,
+  coalesce(max(file_size_in_bytes) FILTER (WHERE content = 0), 0) AS max_file_size,
+  count(*) FILTER (WHERE content = 0 AND file_size_in_bytes < %d) AS small_files,
+  count(*) FILTER (WHERE content <> 0) AS delete_files
+FROM %s`, smallFileSize, name))
+	if err != nil {
+		return nil, err
+	}
+	stats := &IcebergFileStats{SmallFileThreshold: smallFileSize}
+	if len(rows) == 0 {
+		return stats, nil
+	}
+	row := rows[0]
+	stats.DataFiles = int64Value(row["data_files"])
+	stats.DataSize = int64Value(row["data_size"])
+	stats.RecordCount = int64Value(row["record_count"])
+	stats.MinFileSize = int64Value(row["min_file_size"])
+	stats.MaxFileSize = int64Value(row["max_file_size"])
+	stats.SmallFiles = int64Value(row["small_files"])
+	stats.DeleteFiles = int64Value(row["delete_files"])
+	if stats.DataFiles > 0 {
+		stats.AvgFileSize = stats.DataSize / stats.DataFiles
+	}
+	return stats, nil
+}
+
+// hasColumnMasks reports whether any column of the table is masked
+func (c *Client) hasColumnMasks(catalog, schema, table string) bool {
+	prefix := strings.ToLower(catalog + "." + schema + "." + table + ".")
+	for column := range c.currentPolicy().ColumnMasks {
+		if strings.HasPrefix(column, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// int64Value converts a BIGINT or INTEGER result value; NULL becomes 0
+func int64Value(value interface{}) int64 {
+	if v := optionalInt64(value); v != nil {
+		return *v
+	}
+	return 0
+}
+
+// optionalInt64 converts a BIGINT or INTEGER result value, keeping NULL as nil
+func optionalInt64(value interface{}) *int64 {
+	var v int64
+	switch n := value.(type) {
+	case int64:
+		v = n
+	case int32:
+		v = int64(n)
+	case int:
+		v = int64(n)
+	case float64:
+		v = int64(n)
+	default:
+		return nil
+	}
+	return &v
+}
+
+// stringValue converts a VARCHAR result value; NULL becomes ""
+func stringValue(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
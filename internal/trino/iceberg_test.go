@@ -0,0 +1,79 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestIcebergMetadataValidation(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			Catalog:       "iceberg",
+			Schema:        "default",
+			AllowedTables: []string{"iceberg.analytics.events"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		table       string
+		metadata    string
+		expectError string
+	}{
+		{"Table outside allowlist", "analytics.users", IcebergSnapshots, "table access denied: iceberg.analytics.users"},
+		{"Unknown metadata table", "analytics.events", "manifests", "invalid metadata 'manifests'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.IcebergMetadataWithContext(context.Background(), "", "", tt.table, tt.metadata, 0, 0)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("IcebergMetadataWithContext() error = %v, want %q", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestHasColumnMasks(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			ColumnMasks: map[string]string{"iceberg.analytics.users.email": config.MaskSHA256},
+		},
+	}
+	if !client.hasColumnMasks("Iceberg", "analytics", "users") {
+		t.Error("hasColumnMasks() = false for a table with a masked column")
+	}
+	if client.hasColumnMasks("iceberg", "analytics", "user") {
+		t.Error("hasColumnMasks() = true for a table name that only shares a prefix")
+	}
+}
+
+func TestOptionalInt64(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected *int64
+	}{
+		{int64(42), int64Ptr(42)},
+		{int32(7), int64Ptr(7)},
+		{float64(3), int64Ptr(3)},
+		{nil, nil},
+		{"12", nil},
+	}
+
+	for _, tt := range tests {
+		got := optionalInt64(tt.value)
+		if (got == nil) != (tt.expected == nil) || got != nil && *got != *tt.expected {
+			t.Errorf("optionalInt64(%#v) = %v, want %v", tt.value, got, tt.expected)
+		}
+	}
+	if int64Value(nil) != 0 {
+		t.Error("int64Value(nil) should be 0")
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
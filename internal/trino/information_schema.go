@@ -0,0 +1,267 @@
+package trino
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// informationSchemaView names the columns of an information_schema view that
+// identify the schema, table and column a row describes
+type informationSchemaView struct {
+	schema, table, column string
+}
+
+// informationSchemaViews are the information_schema views that list objects;
+// the role views list none and are not filtered
+var informationSchemaViews = map[string]informationSchemaView{
+	"schemata":         {schema: "schema_name"},
+	"tables":           {schema: "table_schema", table: "table_name"},
+	"views":            {schema: "table_schema", table: "table_name"},
+	"columns":          {schema: "table_schema", table: "table_name", column: "column_name"},
+	"table_privileges": {schema: "table_schema", table: "table_name"},
+}
+
+// checkInformationSchema applies TRINO_INFORMATION_SCHEMA to a query an agent
+// wrote, so that information_schema does not reveal objects the allowlists
+// hide. By default each information_schema view the query reads is replaced
+// with a subquery that keeps only the rows of allowed schemas and tables and
+// drops columns removed by column masks; a catalog outside the allowlist is
+// rejected. With block, any query of information_schema is rejected.
+func (c *Client) checkInformationSchema(query string) (string, error) {
+	if c.config.InformationSchema == config.InformationSchemaAllow {
+		return query, nil
+	}
+	tokens := tokenizeSQL(query)
+	if !containsIdentifier(tokens, "information_schema") && c.config.DefaultSchema(c.config.Catalog) != "information_schema" {
+		return query, nil
+	}
+
+	type replacement struct {
+		start, end int
+		text       string
+	}
+	var replacements []replacement
+	for _, name := range tableNames(tokens) {
+		if name.write || name.cte {
+			continue
+		}
+		ref := resolveTable(name.parts, c.config.Catalog, c.config.DefaultSchema(c.config.Catalog))
+		if !strings.EqualFold(ref.Schema, "information_schema") {
+			continue
+		}
+		if c.config.InformationSchema == config.InformationSchemaBlock {
+			return "", policyError(ErrorPermissionDenied, "Use list_schemas, list_tables and get_table_schema to explore metadata",
+				"information_schema access denied: queries of %s are not allowed (TRINO_INFORMATION_SCHEMA=block)", ref)
+		}
+		if len(c.currentPolicy().AllowedCatalogs) > 0 && !c.isCatalogAllowed(ref.Catalog) {
+			return "", accessDenied("catalog access denied: %s not in allowlist", ref.Catalog)
+		}
+		condition := c.informationSchemaFilter(ref)
+		if condition == "" {
+			continue
+		}
+
+		// Replace the name, keeping any alias; without one the view's own
+		// name remains usable as a qualifier
+		text := " (SELECT * FROM " + quoteTable(ref) + " WHERE " + condition + ")"
+		if name.alias == "" {
+			text += " " + quoteIdentifier(ref.Table)
+		}
+		start := 0
+		if name.start > 0 {
+			start = tokens[name.start-1].end
+		}
+		replacements = append(replacements, replacement{start: start, end: tokens[name.nameEnd-1].end, text: text})
+	}
+	if len(replacements) == 0 {
+		return query, nil
+	}
+
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].start > replacements[j].start })
+	for _, r := range replacements {
+		query = query[:r.start] + r.text + query[r.end:]
+	}
+	return query, nil
+}
+
+// checkShowStatement applies the allowlists to SHOW SCHEMAS, SHOW TABLES and
+// SHOW CATALOGS in a query an agent wrote, which Trino answers from metadata
+// the table checks never see. A catalog or schema outside the allowlists is
+// rejected; unless TRINO_INFORMATION_SCHEMA=allow, the schemas and tables
+// listed are filtered by running the statement as the information_schema
+// query Trino rewrites it to, with the filter of that view. SHOW CATALOGS is
+// rejected while catalogs are allowlisted; list_catalogs filters them.
+func (c *Client) checkShowStatement(query string) (string, error) {
+	policy := c.currentPolicy()
+	if len(policy.AllowedCatalogs) == 0 && len(policy.AllowedSchemas) == 0 && len(policy.AllowedTables) == 0 {
+		return query, nil
+	}
+	tokens := tokenizeSQL(query)
+	if len(tokens) < 2 || !tokens[0].keyword("show") {
+		return query, nil
+	}
+	if tokens[1].keyword("catalogs") {
+		if len(policy.AllowedCatalogs) > 0 {
+			return "", accessDenied("catalog access denied: SHOW CATALOGS lists catalogs outside the allowlist")
+		}
+		return query, nil
+	}
+	if !tokens[1].keyword("schemas") && !tokens[1].keyword("tables") {
+		return query, nil
+	}
+
+	// SHOW SCHEMAS|TABLES [FROM|IN name] [LIKE 'pattern' [ESCAPE 'c']]; other
+	// forms, such as Unicode string patterns, cannot be filtered safely
+	object := strings.ToUpper(tokens[1].text)
+	unsupported := policyError(ErrorQueryRejected, "Use list_schemas and list_tables to explore metadata",
+		"query rejected: while allowlists apply, SHOW %s must be SHOW %s [FROM name] [LIKE 'pattern' [ESCAPE 'c']]", object, object)
+	var parts []string
+	i := 2
+	if i < len(tokens) && (tokens[i].keyword("from") || tokens[i].keyword("in")) {
+		if parts, i = qualifiedName(tokens, i+1); parts == nil {
+			return "", unsupported
+		}
+	}
+	like := ""
+	if i < len(tokens) {
+		switch {
+		case i+2 == len(tokens) && tokens[i].keyword("like") && tokens[i+1].text == "'":
+		case i+4 == len(tokens) && tokens[i].keyword("like") && tokens[i+1].text == "'" && tokens[i+2].keyword("escape") && tokens[i+3].text == "'":
+		default:
+			return "", unsupported
+		}
+		like = query[tokens[i].end:tokens[len(tokens)-1].end]
+	}
+
+	catalog := c.config.Catalog
+	if tokens[1].keyword("schemas") {
+		if len(parts) > 1 {
+			return "", unsupported
+		}
+		if len(parts) == 1 {
+			catalog = parts[0]
+		}
+		if catalog == "" {
+			return query, nil
+		}
+		if len(policy.AllowedCatalogs) > 0 && !c.isCatalogAllowed(catalog) {
+			return "", accessDenied("catalog access denied: %s not in allowlist", catalog)
+		}
+		view := TableRef{Catalog: catalog, Schema: "information_schema", Table: "schemata"}
+		condition := c.informationSchemaFilter(view)
+		if condition == "" || c.config.InformationSchema == config.InformationSchemaAllow {
+			return query, nil
+		}
+		if like != "" {
+			condition += " AND schema_name LIKE" + like
+		}
+		return `SELECT schema_name AS "Schema" FROM ` + quoteTable(view) + " WHERE " + condition + " ORDER BY schema_name", nil
+	}
+
+	schema := c.config.DefaultSchema(c.config.Catalog)
+	switch len(parts) {
+	case 1:
+		schema = parts[0]
+	case 2:
+		catalog, schema = parts[0], parts[1]
+	case 3:
+		return "", unsupported
+	}
+	if catalog == "" || schema == "" {
+		return query, nil
+	}
+	if len(policy.AllowedCatalogs) > 0 && !c.isCatalogAllowed(catalog) {
+		return "", accessDenied("catalog access denied: %s not in allowlist", catalog)
+	}
+	if len(policy.AllowedSchemas) > 0 && !c.isSchemaAllowed(catalog, schema) {
+		return "", accessDenied("schema access denied: %s.%s not in allowlist", catalog, schema)
+	}
+	view := TableRef{Catalog: catalog, Schema: "information_schema", Table: "tables"}
+	condition := c.informationSchemaFilter(view)
+	if condition == "" || c.config.InformationSchema == config.InformationSchemaAllow {
+		return query, nil
+	}
+	condition = "table_schema = " + quoteLiteral(schema) + " AND " + condition
+	if like != "" {
+		condition += " AND table_name LIKE" + like
+	}
+	return `SELECT table_name AS "Table" FROM ` + quoteTable(view) + " WHERE " + condition + " ORDER BY table_name", nil
+}
+
+// informationSchemaFilter returns the condition that keeps the rows of an
+// information_schema view describing objects within the allowlists and not
+// dropped by column masks, or "" when every row is kept
+func (c *Client) informationSchemaFilter(ref TableRef) string {
+	view, ok := informationSchemaViews[strings.ToLower(ref.Table)]
+	if !ok {
+		return ""
+	}
+	policy := c.currentPolicy()
+	catalog := strings.ToLower(ref.Catalog) + "."
+
+	var conditions []string
+	if len(policy.AllowedSchemas) > 0 {
+		conditions = append(conditions, inCondition("lower("+view.schema+")", allowedUnder(policy.AllowedSchemas, catalog), false))
+	}
+	if view.table != "" && len(policy.AllowedTables) > 0 {
+		conditions = append(conditions, inCondition("lower("+view.schema+") || '.' || lower("+view.table+")", allowedUnder(policy.AllowedTables, catalog), false))
+	}
+	if view.column != "" {
+		var dropped []string
+		for column, mask := range policy.ColumnMasks {
+			if mask == config.MaskDrop && strings.HasPrefix(column, catalog) {
+				dropped = append(dropped, strings.TrimPrefix(column, catalog))
+			}
+		}
+		if len(dropped) > 0 {
+			conditions = append(conditions, inCondition("lower("+view.schema+") || '.' || lower("+view.table+") || '.' || lower("+view.column+")", dropped, true))
+		}
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// allowedUnder returns the lower-case allowlist entries of a catalog without
+// the catalog prefix
+func allowedUnder(allowlist []string, catalog string) []string {
+	var names []string
+	for _, entry := range allowlist {
+		if entry = strings.ToLower(entry); strings.HasPrefix(entry, catalog) {
+			names = append(names, strings.TrimPrefix(entry, catalog))
+		}
+	}
+	return names
+}
+
+// inCondition renders expr [NOT] IN ('a', 'b'); an empty IN list is FALSE
+// and an empty NOT IN list TRUE
+func inCondition(expr string, values []string, not bool) string {
+	if len(values) == 0 {
+		if not {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	sort.Strings(values)
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	operator := " IN ("
+	if not {
+		operator = " NOT IN ("
+	}
+	return expr + operator + strings.Join(literals, ", ") + ")"
+}
+
+// containsIdentifier reports whether a statement has the unquoted or quoted
+// identifier word
+func containsIdentifier(tokens []sqlToken, word string) bool {
+	for _, token := range tokens {
+		if token.ident && token.text == word {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package trino
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestCheckInformationSchema(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:         "hive",
+		Schema:          "analytics",
+		AllowedCatalogs: []string{"hive", "iceberg"},
+		AllowedSchemas:  []string{"hive.analytics", "Hive.Marts"},
+		AllowedTables:   []string{"hive.analytics.users", "hive.marts.revenue"},
+		ColumnMasks:     map[string]string{"hive.analytics.users.ssn": config.MaskDrop, "hive.analytics.users.email": config.MaskSHA256},
+	}}
+
+	tests := []struct {
+		name        string
+		query       string
+		want        string
+		expectError string
+	}{
+		{"Other tables", "SELECT * FROM users", "SELECT * FROM users", ""},
+		{"Schemata", "SELECT schema_name FROM information_schema.schemata",
+			`SELECT schema_name FROM (SELECT * FROM "hive"."information_schema"."schemata" WHERE lower(schema_name) IN ('analytics', 'marts')) "schemata"`, ""},
+		{"Tables with alias", "SELECT t.table_name FROM hive.information_schema.tables t WHERE t.table_schema = 'analytics'",
+			`SELECT t.table_name FROM (SELECT * FROM "hive"."information_schema"."tables" WHERE lower(table_schema) IN ('analytics', 'marts') AND lower(table_schema) || '.' || lower(table_name) IN ('analytics.users', 'marts.revenue')) t WHERE t.table_schema = 'analytics'`, ""},
+		{"Columns hide dropped columns", `SELECT column_name FROM "information_schema"."columns" WHERE table_name = 'users'`,
+			`SELECT column_name FROM (SELECT * FROM "hive"."information_schema"."columns" WHERE lower(table_schema) IN ('analytics', 'marts') AND lower(table_schema) || '.' || lower(table_name) IN ('analytics.users', 'marts.revenue') AND lower(table_schema) || '.' || lower(table_name) || '.' || lower(column_name) NOT IN ('analytics.users.ssn')) "columns" WHERE table_name = 'users'`, ""},
+		{"Catalog without allowed schemas", "SELECT * FROM iceberg.information_schema.schemata",
+			`SELECT * FROM (SELECT * FROM "iceberg"."information_schema"."schemata" WHERE FALSE) "schemata"`, ""},
+		{"Parenthesized", "SELECT * FROM (information_schema.schemata)",
+			`SELECT * FROM ( (SELECT * FROM "hive"."information_schema"."schemata" WHERE lower(schema_name) IN ('analytics', 'marts')) "schemata")`, ""},
+		{"Role views are not filtered", "SELECT * FROM information_schema.enabled_roles", "SELECT * FROM information_schema.enabled_roles", ""},
+		{"Catalog outside the allowlist", "SELECT * FROM postgresql.information_schema.tables", "", "catalog access denied: postgresql not in allowlist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.checkInformationSchema(tt.query)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("checkInformationSchema(%q) error = %v, want %q", tt.query, err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkInformationSchema(%q) unexpected error: %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("checkInformationSchema(%q) =\n%s\nwant\n%s", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// Without allowlists or dropped columns nothing is filtered
+	open := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "analytics"}}
+	if got, err := open.checkInformationSchema("SELECT * FROM information_schema.tables"); err != nil || got != "SELECT * FROM information_schema.tables" {
+		t.Errorf("checkInformationSchema() without allowlists = %q, %v; want the query unchanged", got, err)
+	}
+
+	client.config.InformationSchema = config.InformationSchemaBlock
+	if _, err := client.checkInformationSchema("SELECT * FROM users u JOIN information_schema.columns c ON true"); err == nil || !strings.Contains(err.Error(), "TRINO_INFORMATION_SCHEMA=block") {
+		t.Errorf("checkInformationSchema() in block mode error = %v, want information_schema access denied", err)
+	}
+	client.config.InformationSchema = config.InformationSchemaAllow
+	if got, err := client.checkInformationSchema("SELECT * FROM information_schema.tables"); err != nil || got != "SELECT * FROM information_schema.tables" {
+		t.Errorf("checkInformationSchema() in allow mode = %q, %v; want the query unchanged", got, err)
+	}
+}
+
+func TestCheckShowStatement(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:         "hive",
+		Schema:          "analytics",
+		AllowedCatalogs: []string{"hive", "iceberg"},
+		AllowedSchemas:  []string{"hive.analytics", "hive.marts"},
+		AllowedTables:   []string{"hive.analytics.users", "hive.marts.revenue"},
+	}}
+
+	tests := []struct {
+		name        string
+		query       string
+		want        string
+		expectError string
+	}{
+		{"Other statements", "SHOW COLUMNS FROM users", "SHOW COLUMNS FROM users", ""},
+		{"Schemas", "SHOW SCHEMAS",
+			`SELECT schema_name AS "Schema" FROM "hive"."information_schema"."schemata" WHERE lower(schema_name) IN ('analytics', 'marts') ORDER BY schema_name`, ""},
+		{"Schemas with a pattern", "SHOW SCHEMAS FROM hive LIKE 'an%'",
+			`SELECT schema_name AS "Schema" FROM "hive"."information_schema"."schemata" WHERE lower(schema_name) IN ('analytics', 'marts') AND schema_name LIKE 'an%' ORDER BY schema_name`, ""},
+		{"Tables", "SHOW TABLES FROM hive.marts",
+			`SELECT table_name AS "Table" FROM "hive"."information_schema"."tables" WHERE table_schema = 'marts' AND lower(table_schema) IN ('analytics', 'marts') AND lower(table_schema) || '.' || lower(table_name) IN ('analytics.users', 'marts.revenue') ORDER BY table_name`, ""},
+		{"Tables of the default schema", `SHOW TABLES LIKE 'u\_%' ESCAPE '\'`,
+			`SELECT table_name AS "Table" FROM "hive"."information_schema"."tables" WHERE table_schema = 'analytics' AND lower(table_schema) IN ('analytics', 'marts') AND lower(table_schema) || '.' || lower(table_name) IN ('analytics.users', 'marts.revenue') AND table_name LIKE 'u\_%' ESCAPE '\' ORDER BY table_name`, ""},
+		{"Schemas of a catalog outside the allowlist", "SHOW SCHEMAS FROM postgresql", "", "catalog access denied: postgresql not in allowlist"},
+		{"Tables of a schema outside the allowlist", "SHOW TABLES IN hive.hr", "", "schema access denied: hive.hr not in allowlist"},
+		{"Catalogs", "SHOW CATALOGS", "", "SHOW CATALOGS lists catalogs outside the allowlist"},
+		{"Unicode pattern", "SHOW TABLES LIKE U&'%'", "", "SHOW TABLES must be SHOW TABLES [FROM name]"},
+		{"Trailing clause", "SHOW SCHEMAS LIKE '%' UNION SELECT 1", "", "SHOW SCHEMAS must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.checkShowStatement(tt.query)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("checkShowStatement(%q) error = %v, want %q", tt.query, err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkShowStatement(%q) unexpected error: %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("checkShowStatement(%q) =\n%s\nwant\n%s", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// In allow mode the listings are not filtered, but the allowlists still apply
+	client.config.InformationSchema = config.InformationSchemaAllow
+	if got, err := client.checkShowStatement("SHOW TABLES FROM hive.marts"); err != nil || got != "SHOW TABLES FROM hive.marts" {
+		t.Errorf("checkShowStatement() in allow mode = %q, %v; want the query unchanged", got, err)
+	}
+	if _, err := client.checkShowStatement("SHOW TABLES FROM hive.hr"); err == nil {
+		t.Error("checkShowStatement() in allow mode accepted a schema outside the allowlist")
+	}
+}
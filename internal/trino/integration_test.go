@@ -0,0 +1,187 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+// newMockClient connects a client to a mock coordinator
+func newMockClient(t *testing.T, server *trinotest.Server, name string, configure func(*config.TrinoConfig)) *Client {
+	t.Helper()
+	host, port := server.HostPort()
+	cfg := &config.TrinoConfig{
+		Host: host, Port: port, Scheme: "http", User: "svc", ClusterName: name,
+		Catalog: "hive", Schema: "sales", QueryTimeout: 30 * time.Second,
+		AllowWriteQueries: true,
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestMockServerPaging(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	rows := make([][]any, 25)
+	for i := range rows {
+		rows[i] = []any{i, "row"}
+	}
+	server.Handle("SELECT id, name FROM orders", trinotest.Result{
+		Columns:  []trinotest.Column{{Name: "id", Type: "integer"}, {Name: "name", Type: "varchar(10)"}},
+		Rows:     rows,
+		PageSize: 10,
+	})
+	client := newMockClient(t, server, "mock-paging", nil)
+
+	result, err := client.ExecuteQueryWithResult(context.Background(), "SELECT id, name FROM orders;")
+	if err != nil {
+		t.Fatalf("ExecuteQueryWithResult() error = %v", err)
+	}
+	if len(result.Rows) != 25 || result.Rows[24]["id"] != int64(24) || result.Rows[0]["name"] != "row" {
+		t.Errorf("rows = %d, last = %v; want all 25 rows across 3 pages", len(result.Rows), result.Rows[len(result.Rows)-1])
+	}
+	if result.ColumnTypes[0].Type != "INTEGER" || result.ColumnTypes[1].Type != "VARCHAR" {
+		t.Errorf("column types = %+v, want INTEGER and VARCHAR", result.ColumnTypes)
+	}
+	if result.QueryID == "" {
+		t.Error("QueryID is empty, want the mock's query ID")
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 || requests[0].User != "svc" || requests[0].Catalog != "hive" || requests[0].Schema != "sales" {
+		t.Errorf("requests = %+v, want one statement as svc in hive.sales", requests)
+	}
+
+	// Stopping at the row limit cancels the rest of the query
+	limited := newMockClient(t, server, "mock-paging-limited", func(cfg *config.TrinoConfig) { cfg.MaxResultRows = 5 })
+	result, err = limited.ExecuteQueryWithResult(context.Background(), "SELECT id, name FROM orders")
+	if err != nil {
+		t.Fatalf("ExecuteQueryWithResult() with row limit error = %v", err)
+	}
+	if len(result.Rows) != 5 || !result.Truncated {
+		t.Errorf("rows = %d, truncated = %v; want 5 and truncated", len(result.Rows), result.Truncated)
+	}
+}
+
+func TestMockServerError(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	server.Handle("SELECT * FROM missing", trinotest.Result{Error: &trinotest.Error{
+		Name: "TABLE_NOT_FOUND", Type: "USER_ERROR", Code: 46, Message: "line 1:15: Table 'hive.sales.missing' does not exist",
+	}})
+	client := newMockClient(t, server, "mock-error", nil)
+
+	_, err := client.ExecuteQueryWithResult(context.Background(), "SELECT * FROM missing")
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) || queryErr.Name != "TABLE_NOT_FOUND" || queryErr.Type != "USER_ERROR" {
+		t.Fatalf("error = %v, want TABLE_NOT_FOUND QueryError", err)
+	}
+	if queryErr.QueryID == "" {
+		t.Error("QueryError.QueryID is empty")
+	}
+
+	if _, err := client.ExecuteQueryWithResult(context.Background(), "SELECT 42"); err == nil || !strings.Contains(err.Error(), "no result for query") {
+		t.Errorf("unknown query error = %v, want no result for query", err)
+	}
+}
+
+func TestMockServerReauthentication(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	server.Handle("SELECT 1", trinotest.Result{Columns: []trinotest.Column{{Name: "_col0", Type: "integer"}}, Rows: [][]any{{1}}})
+	server.RequireToken("token-1")
+	client := newMockClient(t, server, "mock-reauth", func(cfg *config.TrinoConfig) {
+		cfg.ExternalAuth = true
+		cfg.ExternalAuthHeadless = true
+		cfg.ExternalAuthTimeout = 30
+		cfg.ExternalAuthPollInterval = 10 * time.Millisecond
+	})
+
+	// login answers the pending login URL like a user in the browser, and waits for the token
+	login := func(err error) {
+		t.Helper()
+		var authRequired *AuthRequiredError
+		if !errors.As(err, &authRequired) {
+			t.Fatalf("error = %v, want AuthRequiredError", err)
+		}
+		resp, err := http.Get(authRequired.URL)
+		if err != nil {
+			t.Fatalf("opening login URL: %v", err)
+		}
+		_ = resp.Body.Close()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			status, err := client.AuthStatus(context.Background())
+			if err == nil && status.Authenticated {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("AuthStatus() = %+v, %v; want authenticated", status, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	_, err := client.ExecuteQueryWithResult(context.Background(), "SELECT 1")
+	login(err)
+	if _, err := client.ExecuteQueryWithResult(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("query after login error = %v", err)
+	}
+
+	// A rejected token starts a new login instead of failing for good
+	server.RequireToken("token-2")
+	_, err = client.ExecuteQueryWithResult(context.Background(), "SELECT 1")
+	login(err)
+	if _, err := client.ExecuteQueryWithResult(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("query after reauthentication error = %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 || requests[0].Token != "token-1" || requests[1].Token != "token-2" {
+		t.Errorf("requests = %+v, want one query with each token", requests)
+	}
+}
+
+func TestMockServerColumnMasks(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	server.Handle("SELECT id, ssn, email FROM customers", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "id", Type: "integer"}, {Name: "ssn", Type: "varchar"}, {Name: "email", Type: "varchar"}},
+		Rows:    [][]any{{1, "123-45-6789", "a@example.com"}, {2, "987-65-4321", "b@example.com"}},
+	})
+	client := newMockClient(t, server, "mock-masks", func(cfg *config.TrinoConfig) {
+		cfg.ColumnMasks = map[string]string{
+			"hive.sales.customers.ssn":   config.MaskDrop,
+			"hive.sales.customers.email": config.MaskRedact,
+		}
+	})
+
+	result, err := client.ExecuteQueryWithResult(context.Background(), "SELECT id, ssn, email FROM customers")
+	if err != nil {
+		t.Fatalf("ExecuteQueryWithResult() error = %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(result.Rows))
+	}
+	for _, row := range result.Rows {
+		if _, ok := row["ssn"]; ok || row["email"] != redactedValue || row["id"] == nil || len(row) != 2 {
+			t.Errorf("row = %v, want id and a redacted email without ssn", row)
+		}
+	}
+	if len(result.ColumnTypes) != 2 || result.ColumnTypes[0].Name != "id" || result.ColumnTypes[1].Name != "email" {
+		t.Errorf("column types = %+v, want id and email", result.ColumnTypes)
+	}
+}
@@ -0,0 +1,211 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Join suggestion confidence levels
+const (
+	JoinHigh   = "high"   // The key column is named after one of the tables
+	JoinMedium = "medium" // Both tables have a key column of that name
+)
+
+const maxJoinSuggestions = 20
+
+// joinNote explains where join suggestions come from; Trino's
+// information_schema has no primary or foreign key constraints to read
+const joinNote = "Trino does not expose primary or foreign keys, so joins are inferred from column names and types. Check that the related key is unique before relying on a join."
+
+// JoinSuggestions lists the tables a table likely joins with
+type JoinSuggestions struct {
+	Table       string           `json:"table"`
+	Suggestions []JoinSuggestion `json:"suggestions"`
+	Note        string           `json:"note"`
+}
+
+// JoinSuggestion is a related table and the columns to join it on
+type JoinSuggestion struct {
+	Table      string    `json:"table"`
+	Condition  string    `json:"condition"` // e.g. orders.custkey = customer.custkey
+	Keys       []JoinKey `json:"keys"`
+	Confidence string    `json:"confidence"`
+}
+
+// JoinKey is a column pair of a join suggestion
+type JoinKey struct {
+	Column        string `json:"column"`        // Column of the inspected table
+	RelatedColumn string `json:"relatedColumn"` // Column of the related table
+	Confidence    string `json:"confidence"`
+	Reason        string `json:"reason"`
+}
+
+// SuggestJoinsWithContext proposes join keys between a table and the other
+// tables of its schema: columns with the same key-like name (id, _id, key,
+// _code) and compatible types, and foreign-key style pairs such as
+// orders.customer_id = customers.id. Column listings of a schema are cached
+// like the other metadata; tables outside the allowlists and dropped masked
+// columns are never suggested.
+func (c *Client) SuggestJoinsWithContext(ctx context.Context, catalog, schema, table string) (*JoinSuggestions, error) {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	ref := TableRef{Catalog: strings.ToLower(catalog), Schema: strings.ToLower(schema), Table: strings.ToLower(table)}
+	if err := c.checkTableAccess(ref.Catalog, ref.Schema, ref.Table); err != nil {
+		return nil, err
+	}
+
+	key := metadataKey(ctx, "schema columns", ref.Catalog, ref.Schema)
+	tables, ok := c.metadata.getTables(key)
+	if !ok {
+		var err error
+		if tables, err = c.readColumns(ctx, TableRef{Catalog: ref.Catalog, Schema: ref.Schema}); err != nil {
+			return nil, err
+		}
+		c.metadata.putTables(key, tables)
+	}
+	tables = c.visibleColumns(ref, tables)
+	if len(tables[ref.Table]) == 0 {
+		return nil, fmt.Errorf("table %s not found", ref)
+	}
+
+	return &JoinSuggestions{
+		Table:       ref.String(),
+		Suggestions: suggestJoins(ref, tables),
+		Note:        joinNote,
+	}, nil
+}
+
+// suggestJoins matches the columns of ref's table against every other table
+// of the listing, most likely joins first
+func suggestJoins(ref TableRef, tables map[string][]ColumnDefinition) []JoinSuggestion {
+	var suggestions []JoinSuggestion
+	for _, related := range sortedKeys(tables) {
+		if related == ref.Table {
+			continue
+		}
+		var keys []JoinKey
+		for _, col := range tables[ref.Table] {
+			for _, other := range tables[related] {
+				if k, ok := joinKey(ref.Table, col, related, other); ok {
+					keys = append(keys, k)
+				}
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		suggestion := JoinSuggestion{
+			Table:      ref.Catalog + "." + ref.Schema + "." + related,
+			Keys:       keys,
+			Confidence: JoinMedium,
+		}
+		conditions := make([]string, len(keys))
+		for i, k := range keys {
+			conditions[i] = ref.Table + "." + k.Column + " = " + related + "." + k.RelatedColumn
+			if k.Confidence == JoinHigh {
+				suggestion.Confidence = JoinHigh
+			}
+		}
+		suggestion.Condition = strings.Join(conditions, " AND ")
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence == JoinHigh
+		}
+		return len(a.Keys) > len(b.Keys)
+	})
+	if len(suggestions) > maxJoinSuggestions {
+		suggestions = suggestions[:maxJoinSuggestions]
+	}
+	return suggestions
+}
+
+// joinKey reports whether a column of table joins a column of related
+func joinKey(table string, col ColumnDefinition, related string, other ColumnDefinition) (JoinKey, bool) {
+	if !compatibleTypes(col.Type, other.Type) {
+		return JoinKey{}, false
+	}
+	name, otherName := strings.ToLower(col.Name), strings.ToLower(other.Name)
+	stem, isKey := keyStem(name)
+	otherStem, otherIsKey := keyStem(otherName)
+	k := JoinKey{Column: col.Name, RelatedColumn: other.Name, Confidence: JoinHigh}
+
+	switch {
+	case name == otherName && isKey && stem != "":
+		switch {
+		case namesTable(stem, related):
+			k.Reason = fmt.Sprintf("both tables have %s, the key of %s", col.Name, related)
+		case namesTable(stem, table):
+			k.Reason = fmt.Sprintf("both tables have %s, the key of %s", col.Name, table)
+		default:
+			k.Confidence = JoinMedium
+			k.Reason = fmt.Sprintf("both tables have the key column %s", col.Name)
+		}
+	case otherName == "id" && isKey && stem != "" && namesTable(stem, related):
+		k.Reason = fmt.Sprintf("%s.%s references the id of %s", table, col.Name, related)
+	case name == "id" && otherIsKey && otherStem != "" && namesTable(otherStem, table):
+		k.Reason = fmt.Sprintf("%s.%s references the id of %s", related, other.Name, table)
+	default:
+		return JoinKey{}, false
+	}
+	return k, true
+}
+
+// keyStem returns the entity a key-like column name refers to: customer for
+// customer_id, cust for custkey, and "" for a bare id
+func keyStem(name string) (string, bool) {
+	if name == "id" || name == "key" {
+		return "", true
+	}
+	for _, suffix := range []string{"_id", "_key", "_code", "_uuid", "key"} {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return strings.TrimSuffix(strings.TrimSuffix(name, suffix), "_"), true
+		}
+	}
+	return "", false
+}
+
+// namesTable reports whether a key stem names a table: customer and cust
+// name customers, customer names dim_customer
+func namesTable(stem, table string) bool {
+	table = strings.ToLower(table)
+	for _, name := range []string{table, singular(table)} {
+		if stem == name || len(stem) >= 3 && strings.HasPrefix(name, stem) || strings.HasSuffix(name, "_"+stem) {
+			return true
+		}
+	}
+	return false
+}
+
+// singular strips a plural ending from a table name
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// compatibleTypes reports whether two columns can be compared without a
+// cast that defeats the join: the same base type, or both integers or strings
+func compatibleTypes(a, b string) bool {
+	family := func(typ string) string {
+		switch typ = baseType(typ); typ {
+		case "tinyint", "smallint", "integer", "int", "bigint":
+			return "integer"
+		case "varchar", "char":
+			return "string"
+		}
+		return typ
+	}
+	return family(a) != "" && family(a) == family(b)
+}
@@ -0,0 +1,92 @@
+package trino
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestSuggestJoins(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:       "tpch",
+		Schema:        "tiny",
+		AllowedTables: []string{"tpch.tiny.lineitem", "tpch.tiny.orders", "tpch.tiny.part", "tpch.tiny.partsupp", "tpch.tiny.supplier", "tpch.tiny.customer"},
+	}}
+	ctx := context.Background()
+	client.metadata.putTables(metadataKey(ctx, "schema columns", "tpch", "tiny"), map[string][]ColumnDefinition{
+		"lineitem": {{Name: "orderkey", Type: "bigint"}, {Name: "partkey", Type: "bigint"}, {Name: "suppkey", Type: "bigint"}, {Name: "comment", Type: "varchar(44)"}},
+		"orders":   {{Name: "orderkey", Type: "bigint"}, {Name: "custkey", Type: "bigint"}, {Name: "comment", Type: "varchar(79)"}},
+		"part":     {{Name: "partkey", Type: "bigint"}, {Name: "name", Type: "varchar(55)"}},
+		"partsupp": {{Name: "partkey", Type: "bigint"}, {Name: "suppkey", Type: "bigint"}},
+		"supplier": {{Name: "suppkey", Type: "bigint"}, {Name: "nationkey", Type: "bigint"}},
+		"customer": {{Name: "custkey", Type: "bigint"}, {Name: "nationkey", Type: "bigint"}},
+		"nation":   {{Name: "nationkey", Type: "bigint"}}, // Outside the allowlist
+	})
+
+	result, err := client.SuggestJoinsWithContext(ctx, "", "", "lineitem")
+	if err != nil {
+		t.Fatalf("SuggestJoinsWithContext() error = %v", err)
+	}
+	var got []string
+	for _, s := range result.Suggestions {
+		got = append(got, s.Confidence+": "+s.Condition)
+	}
+	want := []string{
+		"high: lineitem.partkey = partsupp.partkey AND lineitem.suppkey = partsupp.suppkey",
+		"high: lineitem.orderkey = orders.orderkey",
+		"high: lineitem.partkey = part.partkey",
+		"high: lineitem.suppkey = supplier.suppkey",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestJoinsWithContext() = %q, want %q", got, want)
+	}
+
+	result, err = client.SuggestJoinsWithContext(ctx, "tpch", "tiny", "customer")
+	if err != nil {
+		t.Fatalf("SuggestJoinsWithContext() error = %v", err)
+	}
+	got = nil
+	for _, s := range result.Suggestions {
+		got = append(got, s.Confidence+": "+s.Condition)
+	}
+	want = []string{
+		"high: customer.custkey = orders.custkey",
+		"medium: customer.nationkey = supplier.nationkey",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestJoinsWithContext() = %q, want %q", got, want)
+	}
+
+	if _, err := client.SuggestJoinsWithContext(ctx, "", "", "nation"); err == nil {
+		t.Error("SuggestJoinsWithContext() of a table outside the allowlist succeeded")
+	}
+}
+
+func TestJoinKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		table, related string
+		col, other     ColumnDefinition
+		want           string // Confidence, or "" for no join
+	}{
+		{"Foreign key to id", "orders", "customers", ColumnDefinition{Name: "customer_id", Type: "bigint"}, ColumnDefinition{Name: "id", Type: "integer"}, JoinHigh},
+		{"Id referenced by a foreign key", "categories", "products", ColumnDefinition{Name: "id", Type: "varchar"}, ColumnDefinition{Name: "category_id", Type: "varchar(36)"}, JoinHigh},
+		{"Foreign key to a prefixed table", "orders", "dim_customer", ColumnDefinition{Name: "customer_id", Type: "bigint"}, ColumnDefinition{Name: "id", Type: "bigint"}, JoinHigh},
+		{"Shared key column", "orders", "returns", ColumnDefinition{Name: "region_code", Type: "varchar"}, ColumnDefinition{Name: "region_code", Type: "varchar"}, JoinMedium},
+		{"Incompatible types", "orders", "customers", ColumnDefinition{Name: "customer_id", Type: "varchar"}, ColumnDefinition{Name: "id", Type: "bigint"}, ""},
+		{"Both bare ids", "orders", "customers", ColumnDefinition{Name: "id", Type: "bigint"}, ColumnDefinition{Name: "id", Type: "bigint"}, ""},
+		{"Shared non-key column", "orders", "customers", ColumnDefinition{Name: "name", Type: "varchar"}, ColumnDefinition{Name: "name", Type: "varchar"}, ""},
+		{"Foreign key to another table", "orders", "customers", ColumnDefinition{Name: "product_id", Type: "bigint"}, ColumnDefinition{Name: "id", Type: "bigint"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, ok := joinKey(tt.table, tt.col, tt.related, tt.other)
+			if got := map[bool]string{true: k.Confidence}[ok]; got != tt.want {
+				t.Errorf("joinKey() confidence = %q, want %q (%s)", got, tt.want, k.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// pingTimeout bounds a single warm-up or keepalive ping
+const pingTimeout = 10 * time.Second
+
+// errNotLoggedIn is the ping result of an external auth client before its first login
+var errNotLoggedIn = errors.New("not logged in: external authentication starts on the first query")
+
+// Ping runs SELECT 1, which checks that Trino accepts the client's credentials
+// and runs queries, and keeps a pooled connection open. Unlike a query, it
+// never starts an external authentication login.
+func (c *Client) Ping(ctx context.Context) error {
+	c.mu.Lock()
+	db, password := c.db, c.password
+	c.mu.Unlock()
+	if db == nil {
+		if c.authenticator != nil {
+			return errNotLoggedIn
+		}
+		return fmt.Errorf("client not initialized")
+	}
+
+	var one int
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("ping failed: %w", sanitizeConnectionError(err, password))
+	}
+	return nil
+}
+
+// setPing records a ping outcome, logging state changes
+func (c *Cluster) setPing(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	failing := c.pingError != ""
+	c.pingedAt = time.Now()
+	if err != nil {
+		if !failing {
+			log.Printf("WARNING: Trino cluster '%s' keepalive ping failed: %v", c.Name, err)
+		}
+		c.pingError = err.Error()
+		return
+	}
+	if failing {
+		log.Printf("INFO: Trino cluster '%s' keepalive ping succeeded again", c.Name)
+	}
+	c.pingError = ""
+}
+
+// startKeepalive pings every cluster at startup with TRINO_WARM_UP, and at
+// each TRINO_KEEPALIVE_INTERVAL until Close is called
+func (s *Clusters) startKeepalive(cfg *config.TrinoConfig) {
+	if cfg.KeepaliveInterval > 0 || cfg.WarmUp {
+		s.every(cfg.KeepaliveInterval, s.ping)
+	}
+}
+
+// ping runs one ping against every cluster
+func (s *Clusters) ping() {
+	for _, cl := range s.clusters {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err := cl.Client.Ping(ctx)
+		cancel()
+		cl.setPing(err)
+	}
+}
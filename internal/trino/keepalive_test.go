@@ -0,0 +1,71 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestPing(t *testing.T) {
+	var failing atomic.Bool
+	var queries atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		switch {
+		case failing.Load():
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			_, _ = w.Write([]byte(`{"id":"q1","stats":{"state":"QUEUED"},"nextUri":"http://` + r.Host + `/v1/statement/executing/q1"}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"q1","stats":{"state":"FINISHED"},` +
+				`"columns":[{"name":"_col0","type":"integer","typeSignature":{"rawType":"integer","arguments":[]}}],"data":[[1]]}`))
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	cfg := &config.TrinoConfig{Host: u.Hostname(), Port: port, Scheme: "http", User: "test", ClusterName: "keepalive-test"}
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	set := &Clusters{clusters: []*Cluster{{Name: "keepalive", Config: cfg, Client: client}}}
+
+	set.ping()
+	if status := set.clusters[0].Status(); status.PingedAt.IsZero() || status.PingError != "" {
+		t.Fatalf("status after ping = %+v, want a successful ping", status)
+	}
+
+	// Expired credentials show up in the status before a user query fails
+	failing.Store(true)
+	set.ping()
+	if status := set.clusters[0].Status(); status.PingError == "" {
+		t.Errorf("status after rejected ping = %+v, want its error", status)
+	}
+	failing.Store(false)
+	set.ping()
+	if status := set.clusters[0].Status(); status.PingError != "" {
+		t.Errorf("status after recovery = %+v, want the error cleared", status)
+	}
+
+	// External auth clients are not pinged into a login
+	queries.Store(0)
+	external := &Client{config: cfg, authenticator: NewExternalAuthenticator(server.URL, "test", 300, false)}
+	if err := external.Ping(context.Background()); !errors.Is(err, errNotLoggedIn) {
+		t.Errorf("Ping() before login = %v, want errNotLoggedIn", err)
+	}
+	if n := queries.Load(); n != 0 {
+		t.Errorf("Ping() before login sent %d requests, want none", n)
+	}
+}
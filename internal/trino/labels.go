@@ -0,0 +1,70 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const queryLabelsKey contextKey = "query_labels"
+
+// QueryLabels overrides how the queries of one tool call are labeled for
+// Trino resource group selectors and chargeback
+type QueryLabels struct {
+	Source       string   // Replaces TRINO_QUERY_SOURCE as X-Trino-Source
+	ClientTags   []string // Added to TRINO_CLIENT_TAGS in X-Trino-Client-Tags
+	RoutingGroup string   // Replaces TRINO_ROUTING_GROUP as X-Trino-Routing-Group
+}
+
+// Validate rejects labels Trino would misread: client tags are sent
+// comma-separated, and header values cannot hold control characters
+func (l QueryLabels) Validate() error {
+	if strings.ContainsFunc(l.Source, isControl) {
+		return fmt.Errorf("invalid source %q: control characters are not allowed", l.Source)
+	}
+	if strings.ContainsFunc(l.RoutingGroup, isControl) {
+		return fmt.Errorf("invalid routing group %q: control characters are not allowed", l.RoutingGroup)
+	}
+	for _, tag := range l.ClientTags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("client tags must not be empty")
+		}
+		if strings.Contains(tag, ",") || strings.ContainsFunc(tag, isControl) {
+			return fmt.Errorf("invalid client tag %q: commas and control characters are not allowed", tag)
+		}
+	}
+	return nil
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
+// WithQueryLabels adds per-request query labels to context
+func WithQueryLabels(ctx context.Context, labels QueryLabels) context.Context {
+	return context.WithValue(ctx, queryLabelsKey, labels)
+}
+
+// getQueryLabels retrieves the per-request query labels from context
+func getQueryLabels(ctx context.Context) (QueryLabels, bool) {
+	labels, ok := ctx.Value(queryLabelsKey).(QueryLabels)
+	return labels, ok
+}
+
+// clientTags returns the configured client tags followed by the request's
+// own and the extra tags, without duplicates
+func (c *Client) clientTags(ctx context.Context, extra ...string) []string {
+	labels, _ := getQueryLabels(ctx)
+	var tags []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{c.config.ClientTags, labels.ClientTags, extra} {
+		for _, tag := range list {
+			tag = strings.TrimSpace(tag)
+			if tag != "" && !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,62 @@
+package trino
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestClientTags(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{ClientTags: []string{"mcp", "team:data"}}}
+
+	if got := client.clientTags(context.Background()); !reflect.DeepEqual(got, []string{"mcp", "team:data"}) {
+		t.Errorf("clientTags() = %v, want the configured tags", got)
+	}
+
+	ctx := WithQueryLabels(context.Background(), QueryLabels{ClientTags: []string{"priority:low", "mcp"}})
+	got := client.clientTags(ctx, "alice", "")
+	want := []string{"mcp", "team:data", "priority:low", "alice"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clientTags() = %v, want %v", got, want)
+	}
+
+	if got := (&Client{config: &config.TrinoConfig{}}).clientTags(context.Background(), ""); got != nil {
+		t.Errorf("clientTags() without tags = %v, want none", got)
+	}
+}
+
+func TestHeaderRoundTripperRequestSource(t *testing.T) {
+	var source string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		source = r.Header.Get("X-Trino-Source")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &headerRoundTripper{
+		base:   http.DefaultTransport,
+		config: &config.TrinoConfig{TrinoSource: "mcp-trino/test"},
+	}}
+	get := func(ctx context.Context) string {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return source
+	}
+
+	if got := get(context.Background()); got != "mcp-trino/test" {
+		t.Errorf("X-Trino-Source = %q, want the configured source", got)
+	}
+	if got := get(WithQueryLabels(context.Background(), QueryLabels{Source: "weekly-report"})); got != "weekly-report" {
+		t.Errorf("X-Trino-Source = %q, want the request's source", got)
+	}
+}
@@ -0,0 +1,200 @@
+package trino
+
+// ColumnRef is a column of a fully qualified table
+type ColumnRef struct {
+	Catalog string `json:"catalog"`
+	Schema  string `json:"schema"`
+	Table   string `json:"table"`
+	Column  string `json:"column"` // "*" when all columns are read
+}
+
+// QueryLineage lists the tables and columns a statement reads and writes
+type QueryLineage struct {
+	StatementType string      `json:"statementType"`
+	ReadOnly      bool        `json:"readOnly"`
+	Reads         []TableRef  `json:"reads"`
+	Writes        []TableRef  `json:"writes"`
+	Columns       []ColumnRef `json:"columns"` // Referenced columns that can be attributed to a table
+}
+
+// sqlKeywords are words that are never reported as unqualified column names
+var sqlKeywords = map[string]bool{
+	"select": true, "from": true, "where": true, "and": true, "or": true, "not": true, "in": true,
+	"is": true, "null": true, "like": true, "escape": true, "between": true, "case": true, "when": true,
+	"then": true, "else": true, "end": true, "as": true, "on": true, "distinct": true, "all": true,
+	"group": true, "by": true, "order": true, "asc": true, "desc": true, "nulls": true, "first": true,
+	"last": true, "limit": true, "offset": true, "fetch": true, "next": true, "row": true, "rows": true,
+	"only": true, "ties": true, "having": true, "join": true, "inner": true, "left": true, "right": true,
+	"full": true, "outer": true, "cross": true, "natural": true, "using": true, "union": true,
+	"intersect": true, "except": true, "with": true, "recursive": true, "values": true, "true": true,
+	"false": true, "exists": true, "any": true, "some": true, "interval": true, "over": true,
+	"partition": true, "window": true, "range": true, "preceding": true, "following": true,
+	"unbounded": true, "current": true, "filter": true, "within": true, "lateral": true,
+	"ordinality": true, "date": true, "time": true, "timestamp": true, "zone": true, "at": true,
+	"year": true, "month": true, "day": true, "hour": true, "minute": true, "second": true, "to": true,
+	"insert": true, "into": true, "update": true, "set": true, "delete": true, "merge": true,
+	"matched": true, "create": true, "table": true, "view": true, "materialized": true, "drop": true,
+	"alter": true, "if": true, "replace": true, "tablesample": true, "bernoulli": true, "system": true,
+	"grouping": true, "sets": true, "cube": true, "rollup": true, "array": true, "map": true,
+	"describe": true, "show": true, "explain": true, "analyze": true,
+}
+
+// AnalyzeLineage extracts the tables a statement reads and writes and the
+// columns it references. Like AnalyzeQuery it is lexical: qualified columns
+// (alias.column, table.column) are attributed through the FROM clause, while
+// unqualified columns are only reported when the statement reads a single
+// table without subqueries or WITH clauses.
+func AnalyzeLineage(query, defaultCatalog, defaultSchema string) *QueryLineage {
+	tokens := tokenizeSQL(query)
+	lineage := &QueryLineage{
+		StatementType: statementType(tokens),
+		ReadOnly:      isReadOnlyQuery(query),
+		Reads:         []TableRef{},
+		Writes:        []TableRef{},
+		Columns:       []ColumnRef{},
+	}
+
+	ctes := cteNames(tokens)
+	skip := make([]bool, len(tokens))    // Tokens naming tables rather than columns
+	tables := make(map[string]*TableRef) // Alias or table name; nil when ambiguous
+	bind := func(name string, ref *TableRef) {
+		if existing, ok := tables[name]; ok && (existing == nil || ref == nil || *existing != *ref) {
+			ref = nil
+		}
+		tables[name] = ref
+	}
+	seen := make(map[string]bool)
+	for _, name := range tableNames(tokens) {
+		for i := name.start; i < name.end; i++ {
+			skip[i] = true
+		}
+		if name.cte {
+			bind(name.parts[0], nil)
+			if name.alias != "" {
+				bind(name.alias, nil)
+			}
+			continue
+		}
+		ref := resolveTable(name.parts, defaultCatalog, defaultSchema)
+		bind(ref.Table, &ref)
+		if name.alias != "" {
+			bind(name.alias, &ref)
+		}
+		key := ref.String()
+		if name.write {
+			key = "write:" + key
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if name.write {
+			lineage.Writes = append(lineage.Writes, ref)
+		} else {
+			lineage.Reads = append(lineage.Reads, ref)
+		}
+	}
+
+	// Unqualified columns belong to the only table the statement reads, or
+	// the table it updates
+	var single *TableRef
+	candidates := lineage.Reads
+	if len(candidates) == 0 {
+		candidates = lineage.Writes
+	}
+	if len(candidates) == 1 && len(ctes) == 0 && !hasSubquery(tokens) {
+		single = &candidates[0]
+	}
+
+	seenColumns := make(map[string]bool)
+	addColumn := func(ref TableRef, column string) {
+		col := ColumnRef{Catalog: ref.Catalog, Schema: ref.Schema, Table: ref.Table, Column: column}
+		if key := ref.String() + "." + column; !seenColumns[key] {
+			seenColumns[key] = true
+			lineage.Columns = append(lineage.Columns, col)
+		}
+	}
+
+	aliases := make(map[string]bool) // Select item aliases, which are not columns
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if skip[i] {
+			continue
+		}
+
+		// SELECT * reads every column of the tables in FROM
+		if token.text == "*" && i > 0 && (tokens[i-1].keyword("select") || tokens[i-1].keyword("distinct") || tokens[i-1].text == ",") {
+			if len(ctes) == 0 && !hasSubquery(tokens) {
+				for _, ref := range lineage.Reads {
+					addColumn(ref, "*")
+				}
+			}
+			continue
+		}
+		if !token.ident {
+			continue
+		}
+
+		// Qualified reference: qualifier.column, where the qualifier is an
+		// alias or a table name of one to three parts
+		parts := []string{token.text}
+		j := i + 1
+		for len(parts) < 4 && j+1 < len(tokens) && tokens[j].text == "." && (tokens[j+1].ident || tokens[j+1].text == "*") {
+			parts = append(parts, tokens[j+1].text)
+			j += 2
+		}
+		if len(parts) > 1 {
+			i = j - 1
+			if j < len(tokens) && tokens[j].text == "(" {
+				continue // Qualified function name
+			}
+			if ref := lookupQualifier(tables, parts[:len(parts)-1], defaultCatalog, defaultSchema); ref != nil {
+				addColumn(*ref, parts[len(parts)-1])
+			}
+			continue
+		}
+
+		// Aliases, function names, keywords and typed literals are not columns
+		prev := sqlToken{}
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if prev.keyword("as") || prev.text == ")" || prev.ident && !sqlKeywords[prev.text] && !token.quoted && !sqlKeywords[token.text] {
+			aliases[token.text] = true
+			continue
+		}
+		if !token.quoted && sqlKeywords[token.text] || aliases[token.text] || tables[token.text] != nil {
+			continue
+		}
+		if i+1 < len(tokens) && (tokens[i+1].text == "(" || tokens[i+1].text == "'") {
+			continue
+		}
+		if single != nil {
+			addColumn(*single, token.text)
+		}
+	}
+	return lineage
+}
+
+// lookupQualifier resolves the qualifier of a column reference to a table of
+// the statement, or returns nil for derived tables, CTEs and unknown names
+func lookupQualifier(tables map[string]*TableRef, qualifier []string, defaultCatalog, defaultSchema string) *TableRef {
+	if len(qualifier) == 1 {
+		return tables[qualifier[0]]
+	}
+	ref := resolveTable(qualifier, defaultCatalog, defaultSchema)
+	if known := tables[ref.Table]; known != nil && *known == ref {
+		return known
+	}
+	return nil
+}
+
+// hasSubquery reports whether a statement contains a parenthesized SELECT
+func hasSubquery(tokens []sqlToken) bool {
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].text == "(" && (tokens[i+1].keyword("select") || tokens[i+1].keyword("with")) {
+			return true
+		}
+	}
+	return false
+}
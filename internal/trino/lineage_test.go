@@ -0,0 +1,74 @@
+package trino
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeLineage(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		reads   []string
+		writes  []string
+		columns []string
+	}{
+		{"Single table", "SELECT id, email FROM users WHERE created_at > DATE '2024-01-01' ORDER BY id",
+			[]string{"hive.sales.users"}, nil,
+			[]string{"hive.sales.users.id", "hive.sales.users.email", "hive.sales.users.created_at"}},
+		{"Select star", "SELECT * FROM analytics.users", []string{"hive.analytics.users"}, nil,
+			[]string{"hive.analytics.users.*"}},
+		{"Aliases and functions", "SELECT upper(name) AS n, count(*) total FROM users GROUP BY n ORDER BY total",
+			[]string{"hive.sales.users"}, nil, []string{"hive.sales.users.name"}},
+		{"Join through aliases", "SELECT o.id, u.email, amount FROM orders o JOIN iceberg.crm.users AS u ON o.user_id = u.id",
+			[]string{"hive.sales.orders", "iceberg.crm.users"}, nil,
+			[]string{"hive.sales.orders.id", "iceberg.crm.users.email", "hive.sales.orders.user_id", "iceberg.crm.users.id"}},
+		{"Qualified by table name", "SELECT sales.orders.id, orders.total FROM sales.orders, users",
+			[]string{"hive.sales.orders", "hive.sales.users"}, nil,
+			[]string{"hive.sales.orders.id", "hive.sales.orders.total"}},
+		{"Qualified star", "SELECT u.* FROM users u JOIN orders o ON u.id = o.user_id",
+			[]string{"hive.sales.users", "hive.sales.orders"}, nil,
+			[]string{"hive.sales.users.*", "hive.sales.users.id", "hive.sales.orders.user_id"}},
+		{"Derived table columns are not attributed", "SELECT t.x FROM (SELECT id AS x FROM users) t",
+			[]string{"hive.sales.users"}, nil, nil},
+		{"Insert select", "INSERT INTO archive.orders (id, total) SELECT id, total FROM orders WHERE status = 'closed'",
+			[]string{"hive.sales.orders"}, []string{"hive.archive.orders"},
+			[]string{"hive.sales.orders.id", "hive.sales.orders.total", "hive.sales.orders.status"}},
+		{"Update", "UPDATE orders SET status = 'closed' WHERE closed_at IS NOT NULL", nil, []string{"hive.sales.orders"},
+			[]string{"hive.sales.orders.status", "hive.sales.orders.closed_at"}},
+		{"Delete", "DELETE FROM orders WHERE id = 1", nil, []string{"hive.sales.orders"}, []string{"hive.sales.orders.id"}},
+		{"Create table as", "CREATE TABLE IF NOT EXISTS tmp.copy AS SELECT id FROM orders",
+			[]string{"hive.sales.orders"}, []string{"hive.tmp.copy"}, []string{"hive.sales.orders.id"}},
+		{"Create table", "CREATE TABLE tmp.t (id bigint, name varchar)", nil, []string{"hive.tmp.t"}, nil},
+		{"CTE", "WITH recent AS (SELECT id FROM orders) SELECT r.id FROM recent r",
+			[]string{"hive.sales.orders"}, nil, nil},
+		{"Describe", "DESCRIBE memory.default.t", []string{"memory.default.t"}, nil, nil},
+		{"Explain", "EXPLAIN ANALYZE SELECT region FROM orders", []string{"hive.sales.orders"}, nil,
+			[]string{"hive.sales.orders.region"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lineage := AnalyzeLineage(tt.query, "hive", "sales")
+			var reads, writes, columns []string
+			for _, table := range lineage.Reads {
+				reads = append(reads, table.String())
+			}
+			for _, table := range lineage.Writes {
+				writes = append(writes, table.String())
+			}
+			for _, column := range lineage.Columns {
+				columns = append(columns, column.Catalog+"."+column.Schema+"."+column.Table+"."+column.Column)
+			}
+			if !reflect.DeepEqual(reads, tt.reads) {
+				t.Errorf("Reads = %v, want %v", reads, tt.reads)
+			}
+			if !reflect.DeepEqual(writes, tt.writes) {
+				t.Errorf("Writes = %v, want %v", writes, tt.writes)
+			}
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("Columns = %v, want %v", columns, tt.columns)
+			}
+		})
+	}
+}
@@ -0,0 +1,542 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// Rules reported by LintQueryWithContext
+const (
+	LintSelectStar             = "select_star"              // SELECT * on a wide table
+	LintMissingPartitionFilter = "missing_partition_filter" // A partitioned table is scanned without filtering a partition column
+	LintCrossJoin              = "cross_join"               // Tables joined without a join condition
+	LintNonSargable            = "non_sargable"             // A WHERE condition that cannot be pushed down or prune partitions
+	LintImplicitCast           = "implicit_cast"            // A column compared to a literal of another type
+)
+
+// Severities of lint findings
+const (
+	LintError   = "error"   // The query fails or is rejected
+	LintWarning = "warning" // The query runs but reads or computes more than it needs to
+)
+
+const (
+	wideTableColumns = 20 // SELECT * is reported on tables with more columns
+	maxLintTables    = 10 // Tables of a query whose columns are looked up
+	lintLookupTime   = 10 * time.Second
+)
+
+// LintFinding is a problem LintQueryWithContext found in a query
+type LintFinding struct {
+	Rule       string `json:"rule"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Table      string `json:"table,omitempty"`
+	Column     string `json:"column,omitempty"`
+	Suggestion string `json:"suggestion"`
+}
+
+// QueryLint lists the findings for a query; no findings means none of the rules matched
+type QueryLint struct {
+	StatementType string        `json:"statementType"`
+	Tables        []TableRef    `json:"tables"` // Tables the query reads
+	Findings      []LintFinding `json:"findings"`
+	Note          string        `json:"note,omitempty"` // Tables whose columns could not be looked up
+}
+
+// lintTable is a table a query reads with its columns, when they could be looked up
+type lintTable struct {
+	ref     TableRef
+	columns []describedColumn
+}
+
+// column returns the described column of the table with the given name
+func (t *lintTable) column(name string) (describedColumn, bool) {
+	for _, col := range t.columns {
+		if strings.EqualFold(col.name, name) {
+			return col, true
+		}
+	}
+	return describedColumn{}, false
+}
+
+// visibleColumns returns the columns of the table that column masks do not drop
+func (t *lintTable) visibleColumns(masks map[string]string) []describedColumn {
+	var visible []describedColumn
+	for _, col := range t.columns {
+		if masks[strings.ToLower(t.ref.String()+"."+col.name)] != config.MaskDrop {
+			visible = append(visible, col)
+		}
+	}
+	return visible
+}
+
+// LintQueryWithContext checks a query for common problems without running
+// it: SELECT * on wide tables, partitioned tables scanned without a partition
+// filter, joins without a join condition, WHERE conditions that apply
+// functions to columns and comparisons between columns and literals of other
+// types. Column types and Hive partition keys come from the cached DESCRIBE
+// output of the tables, which is looked up when not cached; partition columns
+// also include those of TRINO_REQUIRED_PARTITION_FILTERS. Like the other
+// query checks the analysis is lexical.
+func (c *Client) LintQueryWithContext(ctx context.Context, query string) (*QueryLint, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query must not be empty")
+	}
+	tokens := tokenizeSQL(query)
+	lint := &QueryLint{StatementType: statementType(tokens), Tables: []TableRef{}, Findings: []LintFinding{}}
+
+	ctx, cancel := context.WithTimeout(ctx, lintLookupTime)
+	defer cancel()
+	tables, names, unknown := c.readTables(ctx, tokens, lint.StatementType)
+	for _, table := range tables {
+		lint.Tables = append(lint.Tables, table.ref)
+	}
+	if len(unknown) > 0 {
+		lint.Note = "columns of " + strings.Join(unknown, ", ") + " could not be looked up; rules that need column types skipped them"
+	}
+
+	lint.Findings = append(lint.Findings, c.lintSelectStar(tokens, tables, names)...)
+	lint.Findings = append(lint.Findings, c.lintPartitionFilters(tokens, tables)...)
+	lint.Findings = append(lint.Findings, lintCrossJoins(tokens)...)
+	lint.Findings = append(lint.Findings, lintNonSargable(query, tokens)...)
+	lint.Findings = append(lint.Findings, lintImplicitCasts(tokens, tables, names)...)
+	return lint, nil
+}
+
+// readTables returns the tables a statement reads, also by table name and
+// alias, with their columns. Columns are looked up for the first
+// maxLintTables tables; the tables whose columns could not be looked up are
+// returned as unknown.
+func (c *Client) readTables(ctx context.Context, tokens []sqlToken, statement string) ([]*lintTable, map[string]*lintTable, []string) {
+	var tables []*lintTable
+	names := make(map[string]*lintTable)
+	var unknown []string
+	for _, name := range tableNames(tokens) {
+		if name.write && statement != "delete" && statement != "update" {
+			continue
+		}
+		if name.cte {
+			continue
+		}
+		ref := resolveTable(name.parts, c.config.Catalog, c.config.DefaultSchema(c.config.Catalog))
+		if c.checkTableAccess(ref.Catalog, ref.Schema, ref.Table) != nil {
+			continue
+		}
+		var table *lintTable
+		for _, t := range tables {
+			if t.ref == ref {
+				table = t
+			}
+		}
+		if table == nil {
+			table = &lintTable{ref: ref}
+			if len(tables) < maxLintTables {
+				var ok bool
+				if table.columns, ok = c.describeColumns(ctx, ref); !ok {
+					unknown = append(unknown, ref.String())
+				}
+			}
+			tables = append(tables, table)
+		}
+		names[ref.Table] = table
+		if name.alias != "" {
+			names[name.alias] = table
+		}
+	}
+	return tables, names, unknown
+}
+
+// describeColumns returns the columns of a table from the metadata cache,
+// describing the table when they are not cached
+func (c *Client) describeColumns(ctx context.Context, ref TableRef) ([]describedColumn, bool) {
+	key := metadataKey(ctx, "describe", ref.Catalog, ref.Schema, ref.Table)
+	if columns, ok := c.metadata.getColumns(key); ok {
+		return columns, true
+	}
+	if _, err := c.GetTableSchemaWithContext(ctx, ref.Catalog, ref.Schema, ref.Table); err != nil {
+		return nil, false
+	}
+	return c.metadata.getColumns(key)
+}
+
+// lintSelectStar reports SELECT * and alias.* on tables with more than
+// wideTableColumns visible columns, or more than
+// TRINO_SELECT_STAR_MAX_COLUMNS when set, which rejects the query
+func (c *Client) lintSelectStar(tokens []sqlToken, tables []*lintTable, names map[string]*lintTable) []LintFinding {
+	masks := c.currentPolicy().ColumnMasks
+	limit, severity := wideTableColumns, LintWarning
+	if c.config.SelectStarMaxColumns > 0 {
+		limit, severity = c.config.SelectStarMaxColumns, LintError
+	}
+	reported := make(map[*lintTable]bool)
+	var findings []LintFinding
+	for _, star := range selectStars(tokens, tables, names) {
+		for _, table := range star.tables {
+			visible := len(table.visibleColumns(masks))
+			if visible <= limit || reported[table] {
+				continue
+			}
+			reported[table] = true
+			findings = append(findings, LintFinding{
+				Rule:       LintSelectStar,
+				Severity:   severity,
+				Message:    fmt.Sprintf("SELECT * reads all %d columns of %s", visible, table.ref),
+				Table:      table.ref.String(),
+				Suggestion: "Select only the columns you need; get_table_schema lists them",
+			})
+		}
+	}
+	return findings
+}
+
+// lintPartitionFilters reports partitioned tables the query reads without
+// comparing a partition column in a WHERE clause
+func (c *Client) lintPartitionFilters(tokens []sqlToken, tables []*lintTable) []LintFinding {
+	required := c.currentPolicy().RequiredPartitionFilters
+	var filtered map[string]bool
+	var findings []LintFinding
+	for _, table := range tables {
+		columns := required[strings.ToLower(table.ref.String())]
+		severity := LintError // The query is rejected
+		if len(columns) == 0 {
+			severity = LintWarning
+			for _, col := range table.columns {
+				if strings.Contains(strings.ToLower(col.extra), "partition key") {
+					columns = append(columns, strings.ToLower(col.name))
+				}
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		if filtered == nil {
+			filtered = filteredColumns(tokens)
+		}
+		if containsAny(filtered, columns) {
+			continue
+		}
+		which := columns[0]
+		if len(columns) > 1 {
+			which = "one of " + strings.Join(columns, ", ")
+		}
+		message := fmt.Sprintf("%s is partitioned by %s but the query does not filter on it, so every partition is scanned", table.ref, strings.Join(columns, ", "))
+		if severity == LintError {
+			message = fmt.Sprintf("queries on %s must filter on %s (TRINO_REQUIRED_PARTITION_FILTERS); this query is rejected", table.ref, which)
+		}
+		findings = append(findings, LintFinding{
+			Rule:       LintMissingPartitionFilter,
+			Severity:   severity,
+			Message:    message,
+			Table:      table.ref.String(),
+			Column:     strings.Join(columns, ", "),
+			Suggestion: "Add a WHERE condition comparing " + which + " directly; list_partitions shows the partitions that exist",
+		})
+	}
+	return findings
+}
+
+// lintCrossJoins reports CROSS JOIN, ON TRUE and comma-separated tables in a
+// FROM clause of a query level without a WHERE clause
+func lintCrossJoins(tokens []sqlToken) []LintFinding {
+	var findings []LintFinding
+	report := func(message string) {
+		findings = append(findings, LintFinding{
+			Rule:       LintCrossJoin,
+			Severity:   LintWarning,
+			Message:    message,
+			Suggestion: "Join with an ON condition on the related columns; if every combination of rows is intended, keep the CROSS JOIN and make sure both sides are small",
+		})
+	}
+	for i, token := range tokens {
+		switch {
+		case token.keyword("cross") && i+2 < len(tokens) && tokens[i+1].keyword("join"):
+			// CROSS JOIN UNNEST expands arrays rather than joining tables
+			if next := tokens[i+2]; !next.keyword("unnest") && !next.keyword("lateral") && next.text != "(" {
+				report(fmt.Sprintf("CROSS JOIN %s combines every row with every row of the other side", next.text))
+			}
+		case token.keyword("on") && i+1 < len(tokens) && (tokens[i+1].keyword("true") ||
+			i+3 < len(tokens) && tokens[i+2].text == "=" && isNumber(tokens[i+1]) && tokens[i+1].text == tokens[i+3].text):
+			report("the join condition is always true, which joins every row with every row of the other side")
+		case token.keyword("from"):
+			count, j := 0, i+1
+			for {
+				parts, nameEnd := qualifiedName(tokens, j)
+				if parts == nil || nameEnd < len(tokens) && tokens[nameEnd].text == "(" {
+					break
+				}
+				count++
+				_, end := skipAlias(tokens, skipQueryPeriod(tokens, nameEnd))
+				if end >= len(tokens) || tokens[end].text != "," {
+					j = end
+					break
+				}
+				j = end + 1
+			}
+			if count > 1 && !hasWhere(tokens, j) {
+				report(fmt.Sprintf("%d tables are listed in FROM without a WHERE clause, which joins every row with every row", count))
+			}
+		}
+	}
+	return findings
+}
+
+// hasWhere reports whether a WHERE clause follows at the query level of i
+func hasWhere(tokens []sqlToken, i int) bool {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch {
+		case tokens[i].text == "(":
+			depth++
+		case tokens[i].text == ")":
+			if depth--; depth < 0 {
+				return false
+			}
+		case depth == 0 && tokens[i].keyword("where"):
+			return true
+		case depth == 0 && (tokens[i].keyword("union") || tokens[i].keyword("intersect") || tokens[i].keyword("except")):
+			return false
+		}
+	}
+	return false
+}
+
+// lintNonSargable reports functions applied to columns in WHERE comparisons,
+// as in date(ts) = DATE '2024-01-01', and LIKE patterns with a leading wildcard
+func lintNonSargable(query string, tokens []sqlToken) []LintFinding {
+	var findings []LintFinding
+	inWhere := []bool{false} // Per parenthesis depth, as in filteredColumns
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		top := len(inWhere) - 1
+		switch {
+		case token.text == "(":
+			subquery := i+1 < len(tokens) && (tokens[i+1].keyword("select") || tokens[i+1].keyword("with"))
+			inWhere = append(inWhere, inWhere[top] && !subquery)
+			continue
+		case token.text == ")":
+			if top > 0 {
+				inWhere = inWhere[:top]
+			}
+			continue
+		case token.keyword("where"):
+			inWhere[top] = true
+			continue
+		case token.ident && !token.quoted && whereClauseEnd[token.text]:
+			inWhere[top] = false
+			continue
+		}
+		if !inWhere[top] {
+			continue
+		}
+
+		// LIKE '%...' cannot use the statistics or sorting of the column
+		if token.keyword("like") && i+1 < len(tokens) && tokens[i+1].text == "'" {
+			literal := strings.TrimSpace(query[token.end:tokens[i+1].end])
+			if strings.HasPrefix(literal, "'%") && i > 0 && tokens[i-1].ident {
+				findings = append(findings, LintFinding{
+					Rule:       LintNonSargable,
+					Severity:   LintWarning,
+					Message:    fmt.Sprintf("the LIKE pattern %s on %s starts with a wildcard, so it cannot be pushed down and every value is scanned", literal, tokens[i-1].text),
+					Column:     tokens[i-1].text,
+					Suggestion: "Anchor the pattern at the start if possible, or combine it with a filter on a partition or sorted column",
+				})
+			}
+			continue
+		}
+
+		// function(column, ...) compared to something
+		if !token.ident || token.quoted || notFunctions[token.text] || i+1 >= len(tokens) || tokens[i+1].text != "(" {
+			continue
+		}
+		end := skipParens(tokens, i+1)
+		compared := end < len(tokens) && isComparison(tokens[end])
+		column := ""
+		for j, depth := i+2, 1; j < end-1 && column == ""; j++ {
+			switch {
+			case tokens[j].text == "(":
+				depth++
+			case tokens[j].text == ")":
+				depth--
+			case depth == 1 && tokens[j].ident && !sqlKeywords[tokens[j].text] && !strings.HasPrefix(tokens[j].text, "current_") &&
+				j+1 < len(tokens) && tokens[j+1].text != "(" && tokens[j+1].text != "'" && tokens[j+1].text != ".":
+				column = tokens[j].text
+			}
+		}
+		if compared && column != "" {
+			findings = append(findings, LintFinding{
+				Rule:       LintNonSargable,
+				Severity:   LintWarning,
+				Message:    fmt.Sprintf("%s() is applied to column %s in a WHERE condition, which prevents predicate pushdown and partition pruning on it", token.text, column),
+				Column:     column,
+				Suggestion: fmt.Sprintf("Compare %s itself, e.g. rewrite date(ts) = DATE '2024-01-01' as ts >= TIMESTAMP '2024-01-01 00:00:00' AND ts < TIMESTAMP '2024-01-02 00:00:00'", column),
+			})
+		}
+		i = end - 1
+	}
+	return findings
+}
+
+// notFunctions are the words followed by a parenthesis that are not function calls
+var notFunctions = map[string]bool{
+	"in": true, "exists": true, "not": true, "and": true, "or": true, "all": true, "any": true,
+	"some": true, "values": true, "select": true, "with": true, "over": true, "filter": true,
+	"within": true, "as": true, "on": true, "using": true, "when": true, "then": true, "else": true,
+	"case": true, "is": true, "like": true, "between": true, "where": true,
+}
+
+// isComparison reports whether a token starts a comparison operator, IN,
+// BETWEEN or LIKE
+func isComparison(token sqlToken) bool {
+	return token.text == "=" || token.text == "<" || token.text == ">" || token.text == "!" ||
+		token.keyword("in") || token.keyword("between") || token.keyword("like") || token.keyword("not")
+}
+
+// lintImplicitCasts reports columns compared to literals of another type:
+// strings compared to numbers, dates or timestamps, which Trino rejects, and
+// columns Trino casts to the literal's type, which can prevent pushdown
+func lintImplicitCasts(tokens []sqlToken, tables []*lintTable, names map[string]*lintTable) []LintFinding {
+	var findings []LintFinding
+	for i, token := range tokens {
+		if !token.ident || sqlKeywords[token.text] && !token.quoted || i+1 >= len(tokens) {
+			continue
+		}
+
+		// An operator of one or two characters, then a literal
+		j := i + 1
+		for j < len(tokens) && j < i+3 && strings.Contains("=<>!", tokens[j].text) && tokens[j].text != "" {
+			j++
+		}
+		if j == i+1 || j >= len(tokens) {
+			continue
+		}
+		literal := literalType(tokens, j)
+		if literal == "" {
+			continue
+		}
+
+		// The column, qualified by a table or alias or the only table with that name
+		var table *lintTable
+		var col describedColumn
+		if i >= 2 && tokens[i-1].text == "." {
+			if table = names[tokens[i-2].text]; table == nil {
+				continue
+			}
+			var ok bool
+			if col, ok = table.column(token.text); !ok {
+				continue
+			}
+		} else {
+			for _, t := range tables {
+				if c, ok := t.column(token.text); ok {
+					if table != nil {
+						table = nil
+						break
+					}
+					table, col = t, c
+				}
+			}
+			if table == nil {
+				continue
+			}
+		}
+
+		if finding, ok := castFinding(strings.ToLower(col.typ), literal); ok {
+			finding.Message = fmt.Sprintf("column %s of type %s is compared to %s; %s", col.name, col.typ, literalNames[literal], finding.Message)
+			finding.Table = table.ref.String()
+			finding.Column = col.name
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// literalNames describe the literal types of literalType
+var literalNames = map[string]string{
+	"string":    "a string literal",
+	"integer":   "an integer literal",
+	"decimal":   "a decimal literal",
+	"double":    "a double literal",
+	"date":      "a DATE literal",
+	"timestamp": "a TIMESTAMP literal",
+}
+
+// literalType returns the type of the literal starting at i: string, integer,
+// decimal, double, date or timestamp, or "" when no literal starts there
+func literalType(tokens []sqlToken, i int) string {
+	token := tokens[i]
+	if token.text == "-" && i+1 < len(tokens) {
+		token = tokens[i+1]
+		i++
+	}
+	switch {
+	case token.text == "'":
+		return "string"
+	case (token.keyword("date") || token.keyword("timestamp")) && i+1 < len(tokens) && tokens[i+1].text == "'":
+		return token.text
+	case isNumber(token):
+		if strings.ContainsAny(token.text, "eE") {
+			return "double"
+		}
+		// 1.5 is tokenized as 1, ., 5
+		if i+2 < len(tokens) && tokens[i+1].text == "." && tokens[i+1].end == token.end+1 {
+			if next := tokens[i+2]; isNumber(next) {
+				if strings.ContainsAny(next.text, "eE") {
+					return "double"
+				}
+				return "decimal"
+			}
+		}
+		return "integer"
+	}
+	return ""
+}
+
+// isNumber reports whether a token is a number; numbers are tokenized as unquoted words
+func isNumber(token sqlToken) bool {
+	return !token.ident && token.text != "" && token.text[0] >= '0' && token.text[0] <= '9'
+}
+
+// castFinding returns the finding for comparing a column of a Trino type to
+// a literal type, if the comparison needs a cast
+func castFinding(columnType, literal string) (LintFinding, bool) {
+	base := columnType
+	if i := strings.IndexAny(base, "( "); i >= 0 {
+		base = base[:i]
+	}
+	numeric := map[string]bool{"tinyint": true, "smallint": true, "integer": true, "bigint": true, "decimal": true, "real": true, "double": true}
+	text := base == "varchar" || base == "char"
+
+	switch {
+	case literal == "string" && (numeric[base] || base == "date" || base == "timestamp" || base == "boolean"):
+		suggestion := "Compare with a number without quotes"
+		switch base {
+		case "date":
+			suggestion = "Use a typed literal such as DATE '2024-01-01'"
+		case "timestamp":
+			suggestion = "Use a typed literal such as TIMESTAMP '2024-01-01 00:00:00'"
+		case "boolean":
+			suggestion = "Compare with true or false without quotes"
+		}
+		return LintFinding{Rule: LintImplicitCast, Severity: LintError,
+			Message:    "Trino does not convert between varchar and " + base + " implicitly, so the query fails",
+			Suggestion: suggestion}, true
+	case literal != "string" && literal != "date" && literal != "timestamp" && text:
+		return LintFinding{Rule: LintImplicitCast, Severity: LintError,
+			Message:    "Trino does not convert between varchar and numbers implicitly, so the query fails",
+			Suggestion: "Quote the value to compare strings"}, true
+	case (literal == "decimal" || literal == "double") && (base == "tinyint" || base == "smallint" || base == "integer" || base == "bigint"):
+		return LintFinding{Rule: LintImplicitCast, Severity: LintWarning,
+			Message:    "the column is cast to " + literal + " for the comparison, which can prevent predicate pushdown",
+			Suggestion: "Compare with an integer literal, rounding the bound as the condition requires"}, true
+	case literal == "timestamp" && base == "date":
+		return LintFinding{Rule: LintImplicitCast, Severity: LintWarning,
+			Message:    "the column is cast to timestamp for the comparison, which can prevent partition pruning",
+			Suggestion: "Compare with a DATE literal"}, true
+	}
+	return LintFinding{}, false
+}
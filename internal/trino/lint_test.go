@@ -0,0 +1,84 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestLintQuery(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:                  "hive",
+		Schema:                   "sales",
+		RequiredPartitionFilters: map[string][]string{"hive.sales.clicks": {"ds"}},
+	}}
+	ctx := context.Background()
+	wide := []describedColumn{{name: "id", typ: "bigint"}}
+	for i := 1; i <= wideTableColumns; i++ {
+		wide = append(wide, describedColumn{name: fmt.Sprintf("attr_%d", i), typ: "varchar"})
+	}
+	client.metadata.putColumns(metadataKey(ctx, "describe", "hive", "sales", "customers"), wide)
+	client.metadata.putColumns(metadataKey(ctx, "describe", "hive", "sales", "orders"), []describedColumn{
+		{name: "id", typ: "bigint"},
+		{name: "customer_id", typ: "varchar(20)"},
+		{name: "quantity", typ: "integer"},
+		{name: "order_date", typ: "date"},
+		{name: "ts", typ: "timestamp(3)"},
+		{name: "ds", typ: "varchar", extra: "partition key"},
+	})
+	client.metadata.putColumns(metadataKey(ctx, "describe", "hive", "sales", "clicks"), []describedColumn{
+		{name: "url", typ: "varchar"},
+		{name: "ds", typ: "varchar"},
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string // rule:severity:column of each finding
+	}{
+		{"Clean query", "SELECT id, quantity FROM orders WHERE ds = '2024-01-01' AND quantity > 2", nil},
+		{"Select star on a wide table", "SELECT * FROM customers", []string{"select_star:warning:"}},
+		{"Select star on a narrow table", "SELECT * FROM orders WHERE ds = '2024-01-01'", nil},
+		{"Qualified star", "SELECT c.*, o.id FROM customers c JOIN orders o ON c.id = o.id WHERE o.ds = '2024-01-01'", []string{"select_star:warning:"}},
+		{"Missing Hive partition filter", "SELECT id FROM orders", []string{"missing_partition_filter:warning:ds"}},
+		{"Missing required partition filter", "SELECT url FROM clicks", []string{"missing_partition_filter:error:ds"}},
+		{"Explicit cross join", "SELECT o.id FROM orders o CROSS JOIN clicks c WHERE o.ds = '1' AND c.ds = '1'", []string{"cross_join:warning:"}},
+		{"Cross join with unnest", "SELECT o.id, x FROM orders o CROSS JOIN UNNEST(ARRAY[1, 2]) AS t(x) WHERE o.ds = '1'", nil},
+		{"Comma join without WHERE", "SELECT 1 FROM customers a, customers b", []string{"cross_join:warning:"}},
+		{"Always true join condition", "SELECT 1 FROM customers a JOIN customers b ON 1 = 1", []string{"cross_join:warning:"}},
+		{"Function on a column", "SELECT id FROM orders WHERE ds = '1' AND date(ts) = DATE '2024-01-01'", []string{"non_sargable:warning:ts"}},
+		{"Function on a literal", "SELECT id FROM orders WHERE ds = '1' AND ts > date_add('day', -7, current_date)", nil},
+		{"Leading wildcard", "SELECT id FROM orders WHERE ds = '1' AND customer_id LIKE '%42'", []string{"non_sargable:warning:customer_id"}},
+		{"String compared to a number", "SELECT id FROM orders WHERE ds = '1' AND customer_id = 42", []string{"implicit_cast:error:customer_id"}},
+		{"Number compared to a string", "SELECT id FROM orders o WHERE o.ds = '1' AND o.quantity >= '2'", []string{"implicit_cast:error:quantity"}},
+		{"Date compared to a string", "SELECT id FROM orders WHERE ds = '1' AND order_date = '2024-01-01'", []string{"implicit_cast:error:order_date"}},
+		{"Date compared to a timestamp", "SELECT id FROM orders WHERE ds = '1' AND order_date < TIMESTAMP '2024-01-01 00:00:00'", []string{"implicit_cast:warning:order_date"}},
+		{"Integer compared to a decimal", "SELECT id FROM orders WHERE ds = '1' AND quantity > 1.5", []string{"implicit_cast:warning:quantity"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lint, err := client.LintQueryWithContext(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("LintQueryWithContext() error = %v", err)
+			}
+			var got []string
+			for _, finding := range lint.Findings {
+				got = append(got, finding.Rule+":"+finding.Severity+":"+finding.Column)
+				if finding.Message == "" || finding.Suggestion == "" {
+					t.Errorf("finding %+v lacks a message or suggestion", finding)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := client.LintQueryWithContext(ctx, "  "); err == nil {
+		t.Error("LintQueryWithContext() accepted an empty query")
+	}
+}
@@ -0,0 +1,113 @@
+package trino
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// MaxListLimit bounds the names a page of list_schemas or list_tables returns
+const MaxListLimit = 1000
+
+// ListPage selects part of a schema or table listing, so that catalogs with
+// thousands of schemas or tables can be browsed a page at a time
+type ListPage struct {
+	Pattern   string // SQL LIKE pattern the names must match; \ escapes % and _
+	Limit     int    // Most names returned, at most MaxListLimit; 0 returns all
+	PageToken string // NextPageToken of the previous page; "" starts at the first name
+}
+
+// Paged reports whether the listing is filtered or paged, rather than the
+// whole list SHOW SCHEMAS or SHOW TABLES returns
+func (p ListPage) Paged() bool {
+	return p.Pattern != "" || p.Limit > 0 || p.PageToken != ""
+}
+
+// NamePage is a page of schema or table names in name order
+type NamePage struct {
+	Names         []string
+	NextPageToken string // "" on the last page
+}
+
+// ListSchemasPageWithContext returns a page of the schemas of a catalog. The
+// pattern, allowlists and page bounds are pushed into the information_schema
+// query, so only the page is read from Trino.
+func (c *Client) ListSchemasPageWithContext(ctx context.Context, catalog string, page ListPage) (*NamePage, error) {
+	if catalog == "" {
+		catalog = c.config.Catalog
+	}
+	view := TableRef{Catalog: catalog, Schema: "information_schema", Table: "schemata"}
+	result, err := c.listNames(ctx, view, "schema_name", nil, page)
+	if err != nil {
+		return nil, err
+	}
+	result.Names = c.filterSchemas(result.Names, catalog)
+	return result, nil
+}
+
+// ListTablesPageWithContext returns a page of the tables and views of a
+// schema, like ListSchemasPageWithContext
+func (c *Client) ListTablesPageWithContext(ctx context.Context, catalog, schema string, page ListPage) (*NamePage, error) {
+	if catalog == "" {
+		catalog = c.config.Catalog
+	}
+	if schema == "" {
+		schema = c.config.DefaultSchema(catalog)
+	}
+	view := TableRef{Catalog: catalog, Schema: "information_schema", Table: "tables"}
+	result, err := c.listNames(ctx, view, "table_name", []string{"table_schema = " + quoteLiteral(schema)}, page)
+	if err != nil {
+		return nil, err
+	}
+	result.Names = c.filterTables(result.Names, catalog, schema)
+	return result, nil
+}
+
+// listNames reads a page of the name column of an information_schema view
+// that meets the conditions. One name more than the limit is read to learn
+// whether another page follows; the page token is the last name returned.
+func (c *Client) listNames(ctx context.Context, view TableRef, column string, conditions []string, page ListPage) (*NamePage, error) {
+	if page.Limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d: must be positive", page.Limit)
+	}
+	limit := min(page.Limit, MaxListLimit)
+	if page.Pattern != "" {
+		conditions = append(conditions, column+" LIKE "+quoteLiteral(page.Pattern)+` ESCAPE '\'`)
+	}
+	if page.PageToken != "" {
+		after, err := base64.RawURLEncoding.DecodeString(page.PageToken)
+		if err != nil || len(after) == 0 {
+			return nil, fmt.Errorf("invalid page_token: pass the nextPageToken of the previous page unchanged")
+		}
+		conditions = append(conditions, column+" > "+quoteLiteral(string(after)))
+	}
+	if filter := c.informationSchemaFilter(view); filter != "" {
+		conditions = append(conditions, filter)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", column, quoteTable(view))
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY " + column
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit+1)
+	}
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NamePage{Names: make([]string, 0, len(rows))}
+	for _, row := range rows {
+		if name, ok := row[column].(string); ok {
+			result.Names = append(result.Names, name)
+		}
+	}
+	if limit > 0 && len(result.Names) > limit {
+		result.Names = result.Names[:limit]
+		result.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(result.Names[limit-1]))
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package trino
+
+import (
+	"context"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestListSchemasPage(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	names := trinotest.Result{
+		Columns: []trinotest.Column{{Name: "schema_name", Type: "varchar"}},
+		Rows:    [][]any{{"sales_emea"}, {"sales_us"}, {"sales_zz"}},
+	}
+	server.Handle(`SELECT schema_name FROM "hive"."information_schema"."schemata" WHERE schema_name LIKE 'sales\_%' ESCAPE '\' ORDER BY schema_name LIMIT 3`, names)
+	server.Handle(`SELECT schema_name FROM "hive"."information_schema"."schemata" WHERE schema_name LIKE 'sales\_%' ESCAPE '\' AND schema_name > 'sales_us' ORDER BY schema_name LIMIT 3`, trinotest.Result{
+		Columns: names.Columns,
+		Rows:    [][]any{{"sales_zz"}},
+	})
+	client := newMockClient(t, server, "list-schemas-page", nil)
+
+	page := ListPage{Pattern: `sales\_%`, Limit: 2}
+	first, err := client.ListSchemasPageWithContext(context.Background(), "", page)
+	if err != nil {
+		t.Fatalf("ListSchemasPageWithContext() error = %v", err)
+	}
+	if !slices.Equal(first.Names, []string{"sales_emea", "sales_us"}) || first.NextPageToken == "" {
+		t.Fatalf("first page = %+v, want two schemas and a next page", first)
+	}
+
+	page.PageToken = first.NextPageToken
+	second, err := client.ListSchemasPageWithContext(context.Background(), "", page)
+	if err != nil {
+		t.Fatalf("ListSchemasPageWithContext() error = %v", err)
+	}
+	if !slices.Equal(second.Names, []string{"sales_zz"}) || second.NextPageToken != "" {
+		t.Errorf("last page = %+v, want one schema and no next page", second)
+	}
+
+	page.PageToken = "not base64!"
+	if _, err := client.ListSchemasPageWithContext(context.Background(), "", page); err == nil || !strings.Contains(err.Error(), "invalid page_token") {
+		t.Errorf("ListSchemasPageWithContext() with a bad token error = %v", err)
+	}
+}
+
+func TestListTablesPage(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	after := base64.RawURLEncoding.EncodeToString([]byte("orders"))
+	server.Handle(`SELECT table_name FROM "hive"."information_schema"."tables" WHERE table_schema = 'sales' AND table_name > 'orders'`+
+		` AND lower(table_schema) || '.' || lower(table_name) IN ('finance.ledger', 'sales.order_items', 'sales.orders') ORDER BY table_name`, trinotest.Result{
+		Columns: []trinotest.Column{{Name: "table_name", Type: "varchar"}},
+		Rows:    [][]any{{"order_items"}},
+	})
+	client := newMockClient(t, server, "list-tables-page", func(cfg *config.TrinoConfig) {
+		cfg.AllowedTables = []string{"hive.sales.orders", "hive.sales.order_items", "hive.finance.ledger"}
+	})
+
+	// The allowlist is pushed into the query, so pages hold only allowed tables
+	tables, err := client.ListTablesPageWithContext(context.Background(), "", "", ListPage{PageToken: after})
+	if err != nil {
+		t.Fatalf("ListTablesPageWithContext() error = %v", err)
+	}
+	if !slices.Equal(tables.Names, []string{"order_items"}) || tables.NextPageToken != "" {
+		t.Errorf("tables = %+v", tables)
+	}
+}
@@ -0,0 +1,381 @@
+package trino
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// redactedValue replaces values of columns masked with config.MaskRedact
+const redactedValue = "[REDACTED]"
+
+// maskStrength orders mask actions; when a column name is masked in several
+// referenced tables the strongest action wins
+var maskStrength = map[string]int{
+	config.MaskSHA256: 1,
+	config.MaskRedact: 2,
+	config.MaskNull:   3,
+	config.MaskDrop:   4,
+}
+
+// sqlToken is a lexical token of a SQL statement. Identifiers are lower-cased;
+// quoted identifiers keep their content without the quotes.
+type sqlToken struct {
+	text   string
+	ident  bool
+	quoted bool
+	start  int // Byte offset of the token in the statement
+	end    int // Byte offset after the token in the statement
+}
+
+// keyword reports whether the token is the given unquoted keyword
+func (t sqlToken) keyword(word string) bool {
+	return t.ident && !t.quoted && t.text == word
+}
+
+// tokenizeSQL splits a statement into identifiers and punctuation, dropping
+// comments and string literals
+func tokenizeSQL(query string) []sqlToken {
+	var tokens []sqlToken
+	i, n := 0, len(query)
+	for i < n {
+		ch, start := query[i], i
+		switch {
+		case ch == '-' && i+1 < n && query[i+1] == '-':
+			for i < n && query[i] != '\n' {
+				i++
+			}
+		case ch == '/' && i+1 < n && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = n
+			} else {
+				i += end + 4
+			}
+		case ch == '\'':
+			// String literal ('' is an escaped quote)
+			i++
+			for i < n {
+				if query[i] == '\'' {
+					if i+1 < n && query[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+			tokens = append(tokens, sqlToken{text: "'", start: start, end: min(i, n)})
+		case ch == '"':
+			// Quoted identifier ("" is an escaped quote)
+			var ident strings.Builder
+			i++
+			for i < n {
+				if query[i] == '"' {
+					if i+1 < n && query[i+1] == '"' {
+						ident.WriteByte('"')
+						i += 2
+						continue
+					}
+					break
+				}
+				ident.WriteByte(query[i])
+				i++
+			}
+			i++
+			tokens = append(tokens, sqlToken{text: strings.ToLower(ident.String()), ident: true, quoted: true, start: start, end: min(i, n)})
+		case isIdentChar(ch):
+			for i < n && isIdentChar(query[i]) {
+				i++
+			}
+			word := strings.ToLower(query[start:i])
+			tokens = append(tokens, sqlToken{text: word, ident: word[0] < '0' || word[0] > '9', start: start, end: i})
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		default:
+			i++
+			tokens = append(tokens, sqlToken{text: string(ch), start: start, end: i})
+		}
+	}
+	return tokens
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ch == '$' || ch == '@' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch >= 0x80
+}
+
+// activeColumnMasks returns the masks, by column name, of the masked tables a
+// query references. Tables are matched by name alone, so a masked column also
+// applies to same-named tables in other schemas.
+//
+// Masking works on result column names, so a query is rejected when it uses a
+// masked column other than as a plain select item: aliases, expressions,
+// filters and column alias lists could otherwise carry the values out under
+// another name.
+func activeColumnMasks(masks map[string]string, query string) (map[string]string, error) {
+	if len(masks) == 0 {
+		return nil, nil
+	}
+	tokens := tokenizeSQL(query)
+	referenced := make(map[string]bool)
+	for _, token := range tokens {
+		if token.ident {
+			referenced[token.text] = true
+		}
+	}
+
+	active := make(map[string]string)
+	for column, action := range masks {
+		parts := strings.Split(column, ".")
+		if len(parts) != 4 || !referenced[parts[2]] {
+			continue
+		}
+		if current, ok := active[parts[3]]; !ok || maskStrength[action] > maskStrength[current] {
+			active[parts[3]] = action
+		}
+	}
+	if len(active) == 0 {
+		return nil, nil
+	}
+	// Column statistics include the lowest and highest values
+	if len(tokens) > 2 && tokens[0].keyword("show") && tokens[1].keyword("stats") {
+		return nil, policyError(ErrorQueryRejected, "Masked columns have no statistics to show",
+			"column masking: SHOW STATS is not allowed on tables with masked columns")
+	}
+
+	for i, token := range tokens {
+		if !token.ident {
+			continue
+		}
+		if _, masked := active[token.text]; masked && !isPlainSelectItem(tokens, i) {
+			return nil, policyError(ErrorQueryRejected, "Select the masked column as it is, or leave it out of the query",
+				"column masking: masked column '%s' may only be selected directly, "+
+					"without an alias, expression or filter", token.text)
+		}
+	}
+	if renamesColumns(tokens) {
+		return nil, policyError(ErrorQueryRejected, "Remove the column alias list; masked columns must keep their names",
+			"column masking: column alias lists are not allowed in queries on tables with masked columns")
+	}
+	// Set operations name the result columns after the first branch, so a
+	// masked column of a later branch would come back under another name
+	for _, token := range tokens {
+		if token.keyword("union") || token.keyword("intersect") || token.keyword("except") {
+			return nil, policyError(ErrorQueryRejected, "Run each branch of the set operation as its own query",
+				"column masking: %s is not allowed in queries on tables with masked columns", strings.ToUpper(token.text))
+		}
+	}
+	return active, nil
+}
+
+// isPlainSelectItem reports whether the identifier at i is a whole select item,
+// optionally qualified: preceded by SELECT, DISTINCT or a comma and followed by
+// a comma, FROM or the end of the statement
+func isPlainSelectItem(tokens []sqlToken, i int) bool {
+	start := i
+	for start >= 2 && tokens[start-1].text == "." && tokens[start-2].ident {
+		start -= 2
+	}
+	if start == 0 {
+		return false
+	}
+	prev := tokens[start-1]
+	if !prev.keyword("select") && !prev.keyword("distinct") && prev.text != "," {
+		return false
+	}
+	if i+1 == len(tokens) {
+		return true
+	}
+	next := tokens[i+1]
+	return next.text == "," || next.keyword("from")
+}
+
+// renamesColumns reports whether the statement contains a column alias list,
+// as in "FROM (...) t(a, b)" or "WITH t(a, b) AS (...)"
+func renamesColumns(tokens []sqlToken) bool {
+	for i := 1; i+1 < len(tokens); i++ {
+		if !tokens[i].ident || tokens[i+1].text != "(" {
+			continue
+		}
+		end, identsOnly := i+2, true
+		for end < len(tokens) && tokens[end].text != ")" {
+			if !tokens[end].ident && tokens[end].text != "," {
+				identsOnly = false
+			}
+			end++
+		}
+		if !identsOnly || end == len(tokens) {
+			continue
+		}
+		prev := tokens[i-1]
+		derivedTable := prev.text == ")" || prev.keyword("as") && i >= 2 && tokens[i-2].text == ")"
+		cte := end+2 < len(tokens) && tokens[end+1].keyword("as") && tokens[end+2].text == "(" &&
+			(prev.keyword("with") || prev.text == ",")
+		if derivedTable || cte {
+			return true
+		}
+	}
+	return false
+}
+
+// columnMasker applies column masks to result rows
+type columnMasker struct {
+	actions []string // Mask action per source column ("" leaves it unchanged)
+	columns []string // Names of the columns that are kept
+	infos   []ColumnInfo
+}
+
+// newColumnMasker returns a masker for the result columns, or nil if none is masked
+func newColumnMasker(active map[string]string, infos []ColumnInfo) *columnMasker {
+	if len(active) == 0 {
+		return nil
+	}
+	m := &columnMasker{actions: make([]string, len(infos))}
+	masked := false
+	for i, info := range infos {
+		action := active[strings.ToLower(info.Name)]
+		m.actions[i] = action
+		switch action {
+		case config.MaskDrop:
+			masked = true
+			continue
+		case config.MaskSHA256, config.MaskRedact:
+			masked = true
+			info = ColumnInfo{Name: info.Name, Type: "varchar"}
+		case config.MaskNull:
+			masked = true
+		}
+		m.columns = append(m.columns, info.Name)
+		m.infos = append(m.infos, info)
+	}
+	if !masked {
+		return nil
+	}
+	return m
+}
+
+// apply returns the masked values of a row, without dropped columns
+func (m *columnMasker) apply(values []interface{}) []interface{} {
+	masked := make([]interface{}, 0, len(m.columns))
+	for i, value := range values {
+		switch m.actions[i] {
+		case config.MaskDrop:
+			continue
+		case config.MaskNull:
+			value = nil
+		case config.MaskRedact:
+			if value != nil {
+				value = redactedValue
+			}
+		case config.MaskSHA256:
+			value = hashValue(value)
+		}
+		masked = append(masked, value)
+	}
+	return masked
+}
+
+// hashValue returns the SHA-256 hex digest of a value's text form; NULL stays NULL
+func hashValue(value interface{}) interface{} {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		data = []byte(fmt.Sprint(v))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// maskTableSchema drops and annotates masked columns in DESCRIBE output
+func maskTableSchema(masks map[string]string, catalog, schema, table string, rows []map[string]interface{}) []map[string]interface{} {
+	if len(masks) == 0 {
+		return rows
+	}
+	prefix := strings.ToLower(catalog + "." + schema + "." + table + ".")
+	filtered := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		name, _ := row["Column"].(string)
+		action := masks[prefix+strings.ToLower(name)]
+		switch action {
+		case "":
+		case config.MaskDrop:
+			continue
+		default:
+			note := "masked (" + action + ")"
+			if extra, _ := row["Extra"].(string); extra != "" {
+				note = extra + ", " + note
+			}
+			row["Extra"] = note
+		}
+		filtered = append(filtered, row)
+	}
+	return filtered
+}
+
+// maskTableDDL drops and annotates masked columns in the column list of
+// SHOW CREATE TABLE output: one column per line, between the CREATE line
+// ending in "(" and the closing ")"
+func maskTableDDL(masks map[string]string, catalog, schema, table, ddl string) string {
+	if len(masks) == 0 {
+		return ddl
+	}
+	lines := strings.Split(ddl, "\n")
+	if len(lines) < 2 || !strings.HasSuffix(strings.TrimSpace(lines[0]), "(") {
+		return ddl
+	}
+	end := 1
+	for end < len(lines) && !strings.HasPrefix(lines[end], ")") {
+		end++
+	}
+
+	prefix := strings.ToLower(catalog + "." + schema + "." + table + ".")
+	var columns []string
+	for _, line := range lines[1:end] {
+		line = strings.TrimSuffix(line, ",")
+		switch action := masks[prefix+ddlColumnName(line)]; action {
+		case "":
+		case config.MaskDrop:
+			continue
+		default:
+			line += " /* masked (" + action + ") */"
+		}
+		columns = append(columns, line)
+	}
+	for i := range columns[:max(len(columns)-1, 0)] {
+		columns[i] += ","
+	}
+	return strings.Join(append(append(lines[:1:1], columns...), lines[end:]...), "\n")
+}
+
+// ddlColumnName returns the lowercased column name a column definition line
+// starts with, unquoting a quoted name
+func ddlColumnName(line string) string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, `"`) {
+		name, _, _ := strings.Cut(line, " ")
+		return strings.ToLower(name)
+	}
+	var name strings.Builder
+	for i := 1; i < len(line); i++ {
+		if line[i] == '"' {
+			if i+1 < len(line) && line[i+1] == '"' {
+				name.WriteByte('"')
+				i++
+				continue
+			}
+			break
+		}
+		name.WriteByte(line[i])
+	}
+	return strings.ToLower(name.String())
+}
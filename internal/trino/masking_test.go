@@ -0,0 +1,151 @@
+package trino
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+var testMasks = map[string]string{
+	"hive.analytics.users.email": config.MaskSHA256,
+	"hive.analytics.users.ssn":   config.MaskDrop,
+	"hive.sales.orders.email":    config.MaskRedact,
+}
+
+func TestActiveColumnMasks(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		expected    map[string]string
+		expectError bool
+	}{
+		{"Unrelated table", "SELECT email FROM hive.analytics.events", nil, false},
+		{"Select star", "SELECT * FROM hive.analytics.users", map[string]string{"email": "sha256", "ssn": "drop"}, false},
+		{"Plain columns", "SELECT id, email, u.ssn FROM users u", map[string]string{"email": "sha256", "ssn": "drop"}, false},
+		{"Quoted column", `SELECT "Email" FROM "users"`, map[string]string{"email": "sha256", "ssn": "drop"}, false},
+		{"Strongest mask wins", "SELECT o.email FROM orders o JOIN users u ON o.user_id = u.id",
+			map[string]string{"email": "redact", "ssn": "drop"}, false},
+		{"Mentioned only in a literal", "SELECT id FROM users WHERE note = 'ssn' -- email", map[string]string{"email": "sha256", "ssn": "drop"}, false},
+		{"Alias", "SELECT email AS contact FROM users", nil, true},
+		{"Implicit alias", "SELECT email contact FROM users", nil, true},
+		{"Expression", "SELECT upper(u.email) FROM users u", nil, true},
+		{"Filter", "SELECT id FROM users WHERE ssn LIKE '1%'", nil, true},
+		{"Quoted alias evasion", `SELECT "ssn" AS x FROM users`, nil, true},
+		{"Derived table alias list", "SELECT x FROM (SELECT email FROM users) t(x)", nil, true},
+		{"CTE alias list", "WITH t(x) AS (SELECT email FROM users) SELECT x FROM t", nil, true},
+		{"Union", "SELECT id FROM hive.sales.orders WHERE false UNION ALL SELECT ssn FROM hive.analytics.users", nil, true},
+		{"Except", "SELECT email FROM users EXCEPT SELECT email FROM orders", nil, true},
+		{"Union of unmasked tables", "SELECT email FROM events UNION SELECT email FROM visits", nil, false},
+		{"Show stats", "SHOW STATS FOR users", nil, true},
+		{"Show stats of a query", "SHOW STATS FOR (SELECT ssn FROM users)", nil, true},
+		{"Function after comma", "SELECT id, upper(name) AS n, email FROM users", map[string]string{"email": "sha256", "ssn": "drop"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := activeColumnMasks(testMasks, tt.query)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("activeColumnMasks(%q) expected error, got %v", tt.query, active)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("activeColumnMasks(%q) error = %v", tt.query, err)
+			}
+			if len(active) == 0 && len(tt.expected) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(active, tt.expected) {
+				t.Errorf("activeColumnMasks(%q) = %v, want %v", tt.query, active, tt.expected)
+			}
+		})
+	}
+}
+
+func TestColumnMasker(t *testing.T) {
+	active := map[string]string{"email": config.MaskSHA256, "ssn": config.MaskDrop, "phone": config.MaskRedact, "dob": config.MaskNull}
+	infos := []ColumnInfo{
+		{Name: "id", Type: "bigint"},
+		{Name: "email", Type: "varchar"},
+		{Name: "ssn", Type: "varchar"},
+		{Name: "phone", Type: "varchar"},
+		{Name: "dob", Type: "date"},
+		{Name: "balance", Type: "decimal", Precision: 10, Scale: 2},
+	}
+
+	m := newColumnMasker(active, infos)
+	if m == nil {
+		t.Fatal("newColumnMasker() returned nil")
+	}
+	if want := []string{"id", "email", "phone", "dob", "balance"}; !reflect.DeepEqual(m.columns, want) {
+		t.Errorf("columns = %v, want %v", m.columns, want)
+	}
+	if m.infos[4] != infos[5] {
+		t.Errorf("unmasked column info changed: %+v", m.infos[4])
+	}
+
+	got := m.apply([]interface{}{int64(1), "a@example.com", "123-45-6789", "555-0100", "1990-01-01", "10.50"})
+	want := []interface{}{
+		int64(1),
+		"08168cd80dfd534ab0f10af10f1303fe00af2d43ab5c1432360d137f8197e17a",
+		redactedValue,
+		nil,
+		"10.50",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("apply() = %v, want %v", got, want)
+	}
+	if hashValue(nil) != nil {
+		t.Error("hashValue(nil) should stay NULL")
+	}
+
+	if newColumnMasker(active, []ColumnInfo{{Name: "id"}}) != nil {
+		t.Error("newColumnMasker() should return nil when no result column is masked")
+	}
+}
+
+func TestMaskTableSchema(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"Column": "id", "Type": "bigint", "Extra": ""},
+		{"Column": "email", "Type": "varchar", "Extra": ""},
+		{"Column": "ssn", "Type": "varchar", "Extra": ""},
+	}
+	got := maskTableSchema(testMasks, "Hive", "analytics", "users", rows)
+	if len(got) != 2 {
+		t.Fatalf("maskTableSchema() returned %d columns, want 2 (ssn dropped)", len(got))
+	}
+	if got[1]["Extra"] != "masked (sha256)" {
+		t.Errorf("email Extra = %v, want masked (sha256)", got[1]["Extra"])
+	}
+
+	// Columns of other tables are untouched
+	if got := maskTableSchema(testMasks, "hive", "analytics", "events", rows); len(got) != 3 {
+		t.Errorf("maskTableSchema() on unmasked table returned %d columns, want 3", len(got))
+	}
+}
+
+func TestMaskTableDDL(t *testing.T) {
+	ddl := `CREATE TABLE hive.analytics.users (
+   id bigint,
+   email varchar COMMENT 'contact, primary',
+   "SSN" varchar
+)
+WITH (
+   format = 'ORC'
+)`
+	want := `CREATE TABLE hive.analytics.users (
+   id bigint,
+   email varchar COMMENT 'contact, primary' /* masked (sha256) */
+)
+WITH (
+   format = 'ORC'
+)`
+	if got := maskTableDDL(testMasks, "hive", "analytics", "users", ddl); got != want {
+		t.Errorf("maskTableDDL() =\n%s\nwant\n%s", got, want)
+	}
+	if got := maskTableDDL(testMasks, "hive", "analytics", "events", ddl); got != ddl {
+		t.Errorf("maskTableDDL() changed an unmasked table:\n%s", got)
+	}
+}
@@ -0,0 +1,101 @@
+package trino
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/dbt"
+)
+
+// ModelTable is a dbt model with the columns of the Trino table it materializes
+type ModelTable struct {
+	*dbt.Model
+	Relation       string        `json:"relation,omitempty"`       // catalog.schema.table to query; empty for ephemeral models
+	Columns        []ModelColumn `json:"columns,omitempty"`        // Columns of the table, with their dbt docs
+	MissingColumns []string      `json:"missingColumns,omitempty"` // Documented in dbt but not in the table
+	TableError     string        `json:"tableError,omitempty"`     // Why the table could not be described, e.g. not built yet
+}
+
+// ModelColumn is a table column with its dbt documentation
+type ModelColumn struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"` // Trino type; empty when the table could not be described
+	Extra       string   `json:"extra,omitempty"`
+	Description string   `json:"description,omitempty"`
+	DataType    string   `json:"dataType,omitempty"` // Type declared in dbt
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// DescribeModelWithContext maps a dbt model to its Trino table and merges
+// the model's column docs into the table's columns, in table order. When the
+// table cannot be described, the documented columns are returned with the
+// error. Allowlists and column masks apply as in get_table_schema.
+func (c *Client) DescribeModelWithContext(ctx context.Context, model *dbt.Model) (*ModelTable, error) {
+	described := &ModelTable{Model: model, Relation: model.Relation()}
+	if described.Relation == "" {
+		described.Columns = c.documentedColumns(model, nil)
+		return described, nil
+	}
+	if err := c.checkTableAccess(model.Catalog, model.Schema, model.Table); err != nil {
+		return nil, err
+	}
+
+	rows, err := c.GetTableSchemaWithContext(ctx, model.Catalog, model.Schema, model.Table)
+	if err != nil {
+		described.TableError = err.Error()
+		described.Columns = c.documentedColumns(model, nil)
+		return described, nil
+	}
+
+	docs := make(map[string]dbt.Column, len(model.Columns))
+	for _, col := range model.Columns {
+		docs[strings.ToLower(col.Name)] = col
+	}
+	inTable := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		name := stringValue(row["Column"])
+		inTable[strings.ToLower(name)] = true
+		doc := docs[strings.ToLower(name)]
+		described.Columns = append(described.Columns, ModelColumn{
+			Name:        name,
+			Type:        stringValue(row["Type"]),
+			Extra:       stringValue(row["Extra"]),
+			Description: doc.Description,
+			DataType:    doc.DataType,
+			Tags:        doc.Tags,
+		})
+	}
+	for _, col := range c.documentedColumns(model, inTable) {
+		described.MissingColumns = append(described.MissingColumns, col.Name)
+	}
+	return described, nil
+}
+
+// documentedColumns returns the documented columns of a model that are not
+// in skip, leaving out dropped masked columns
+func (c *Client) documentedColumns(model *dbt.Model, skip map[string]bool) []ModelColumn {
+	masks := c.currentPolicy().ColumnMasks
+	prefix := model.Catalog + "." + model.Schema + "." + model.Table + "."
+	var columns []ModelColumn
+	for _, col := range model.Columns {
+		name := strings.ToLower(col.Name)
+		if skip[name] || masks[prefix+name] == config.MaskDrop {
+			continue
+		}
+		columns = append(columns, ModelColumn{Name: col.Name, Description: col.Description, DataType: col.DataType, Tags: col.Tags})
+	}
+	return columns
+}
+
+// VisibleModels returns the models whose tables are within the allowlists;
+// ephemeral models have no table and are always visible
+func (c *Client) VisibleModels(models []*dbt.Model) []*dbt.Model {
+	visible := make([]*dbt.Model, 0, len(models))
+	for _, model := range models {
+		if model.Relation() == "" || c.checkTableAccess(model.Catalog, model.Schema, model.Table) == nil {
+			visible = append(visible, model)
+		}
+	}
+	return visible
+}
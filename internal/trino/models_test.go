@@ -0,0 +1,52 @@
+package trino
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/dbt"
+)
+
+func TestVisibleModels(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{AllowedSchemas: []string{"hive.analytics"}}}
+	models := []*dbt.Model{
+		{UniqueID: "model.shop.orders", Catalog: "hive", Schema: "analytics", Table: "orders", Materialized: "table"},
+		{UniqueID: "model.shop.payroll", Catalog: "hive", Schema: "hr", Table: "payroll", Materialized: "table"},
+		{UniqueID: "model.shop.stg_orders", Catalog: "hive", Schema: "staging", Table: "stg_orders", Materialized: "ephemeral"},
+	}
+
+	var got []string
+	for _, model := range client.VisibleModels(models) {
+		got = append(got, model.UniqueID)
+	}
+	if want := []string{"model.shop.orders", "model.shop.stg_orders"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("VisibleModels() = %v, want %v", got, want)
+	}
+}
+
+func TestDescribeModel(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		AllowedSchemas: []string{"hive.analytics"},
+		ColumnMasks:    map[string]string{"hive.staging.stg_orders.email": config.MaskDrop},
+	}}
+	ctx := context.Background()
+
+	// Ephemeral models are described from their docs, without dropped masked columns
+	ephemeral := &dbt.Model{Catalog: "hive", Schema: "staging", Table: "stg_orders", Materialized: "ephemeral",
+		Columns: []dbt.Column{{Name: "email"}, {Name: "order_id", Description: "Primary key"}}}
+	described, err := client.DescribeModelWithContext(ctx, ephemeral)
+	if err != nil {
+		t.Fatalf("DescribeModelWithContext() error = %v", err)
+	}
+	if want := []ModelColumn{{Name: "order_id", Description: "Primary key"}}; described.Relation != "" || !reflect.DeepEqual(described.Columns, want) {
+		t.Errorf("DescribeModelWithContext() = %+v, want no relation and columns %+v", described, want)
+	}
+
+	hidden := &dbt.Model{Catalog: "hive", Schema: "hr", Table: "payroll", Materialized: "table"}
+	_, err = client.DescribeModelWithContext(ctx, hidden)
+	if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorPermissionDenied {
+		t.Errorf("DescribeModelWithContext() outside the allowlist error = %v, want %s", err, ErrorPermissionDenied)
+	}
+}
@@ -0,0 +1,183 @@
+package trino
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+const (
+	// notifyTimeout bounds each webhook request
+	notifyTimeout = 5 * time.Second
+	// maxNotifyQueryLength bounds the query text included in a notification
+	maxNotifyQueryLength = 500
+)
+
+// queryEvent is a slow or failed query reported by a notification
+type queryEvent struct {
+	Title     string // What happened, e.g. Trino query failed
+	QueryID   string
+	InfoURI   string
+	User      string // Authenticated OAuth user
+	TrinoUser string // User the query ran as in Trino
+	Cluster   string
+	Elapsed   time.Duration
+	Error     string
+	Query     string
+}
+
+// queryNotifier posts a chat message to a Slack or Teams incoming webhook
+// when a query runs longer than a threshold or fails with a configured
+// error type or name, so platform teams see the load agents put on Trino
+type queryNotifier struct {
+	url        string
+	format     string
+	slow       time.Duration
+	errors     map[string]bool
+	httpClient *http.Client
+}
+
+// newQueryNotifier returns a notifier for cfg, or nil if TRINO_NOTIFY_WEBHOOK_URL is not set
+func newQueryNotifier(cfg *config.TrinoConfig) *queryNotifier {
+	if cfg.NotifyWebhookURL == "" {
+		return nil
+	}
+	errorClasses := make(map[string]bool, len(cfg.NotifyErrors))
+	for _, class := range cfg.NotifyErrors {
+		errorClasses[strings.ToUpper(class)] = true
+	}
+	return &queryNotifier{
+		url:        cfg.NotifyWebhookURL,
+		format:     cfg.NotifyFormat,
+		slow:       cfg.NotifySlowQuery,
+		errors:     errorClasses,
+		httpClient: &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// title returns the title of the notification for a finished query, or ""
+// when the query is not reported
+func (n *queryNotifier) title(elapsed time.Duration, err error) string {
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) && (n.errors[queryErr.Type] || n.errors[queryErr.Name]) {
+		return "Trino query failed"
+	}
+	if n.slow > 0 && elapsed >= n.slow {
+		return fmt.Sprintf("Slow Trino query (%s)", elapsed.Round(time.Second))
+	}
+	return ""
+}
+
+// send posts the notification; failures are logged, never returned to the query
+func (n *queryNotifier) send(ctx context.Context, event queryEvent) {
+	body, err := json.Marshal(n.payload(event))
+	if err != nil {
+		log.Printf("WARNING: Failed to encode query notification: %v", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("WARNING: Failed to create query notification request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		log.Printf("WARNING: Query notification failed: %v", err)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		log.Printf("WARNING: Query notification webhook returned HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+}
+
+// payload builds the webhook message: Slack mrkdwn text, or a Teams MessageCard
+func (n *queryNotifier) payload(event queryEvent) map[string]interface{} {
+	queryID := event.QueryID
+	if queryID == "" {
+		queryID = "unknown"
+	}
+	var lines []string
+	field := func(name, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("*%s:* %s", name, value))
+		}
+	}
+	if n.format == config.NotifyFormatSlack && event.InfoURI != "" {
+		field("Query ID", fmt.Sprintf("<%s|%s>", event.InfoURI, queryID))
+	} else if event.InfoURI != "" {
+		field("Query ID", fmt.Sprintf("[%s](%s)", queryID, event.InfoURI))
+	} else {
+		field("Query ID", queryID)
+	}
+	field("User", event.User)
+	field("Trino user", event.TrinoUser)
+	field("Cluster", event.Cluster)
+	field("Elapsed", event.Elapsed.Round(time.Millisecond).String())
+	field("Error", event.Error)
+
+	query := event.Query
+	if len(query) > maxNotifyQueryLength {
+		query = query[:maxNotifyQueryLength] + "…"
+	}
+	if n.format == config.NotifyFormatTeams {
+		// Teams renders markdown with ** for bold and needs blank lines between paragraphs
+		text := strings.ReplaceAll(strings.Join(lines, "\n\n"), "*", "**")
+		return map[string]interface{}{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  event.Title,
+			"title":    event.Title,
+			"text":     text + "\n\n```\n" + query + "\n```",
+		}
+	}
+	return map[string]interface{}{
+		"text": "*" + event.Title + "*\n" + strings.Join(lines, "\n") + "\n```" + query + "```",
+	}
+}
+
+// notifyQuery reports a finished query when it was slow or failed with a
+// configured error class. The webhook is called in the background.
+func (c *Client) notifyQuery(ctx context.Context, query string, tracker *queryTracker, started time.Time, err error) {
+	if c.notifier == nil {
+		return
+	}
+	elapsed := time.Since(started)
+	title := c.notifier.title(elapsed, err)
+	if title == "" {
+		return
+	}
+	event := queryEvent{
+		Title:     title,
+		QueryID:   tracker.QueryID(),
+		InfoURI:   tracker.InfoURI(),
+		User:      getQueryUsername(ctx),
+		TrinoUser: c.config.User,
+		Cluster:   c.config.ClusterName,
+		Elapsed:   elapsed,
+		Query:     query,
+	}
+	if user, ok := GetImpersonatedUser(ctx); ok {
+		event.TrinoUser = user
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		c.notifier.send(ctx, event)
+	}()
+}
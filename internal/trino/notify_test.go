@@ -0,0 +1,117 @@
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestQueryNotifierTitle(t *testing.T) {
+	notifier := newQueryNotifier(&config.TrinoConfig{
+		NotifyWebhookURL: "https://hooks.example.com/services/abc",
+		NotifySlowQuery:  time.Minute,
+		NotifyErrors:     []string{"INTERNAL_ERROR", "exceeded_time_limit"},
+	})
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		err     error
+		want    string
+	}{
+		{"Fast success", time.Second, nil, ""},
+		{"Slow success", 90 * time.Second, nil, "Slow Trino query (1m30s)"},
+		{"Matching error type", time.Second, &QueryError{Name: "GENERIC_INTERNAL_ERROR", Type: "INTERNAL_ERROR"}, "Trino query failed"},
+		{"Matching error name", time.Second, &QueryError{Name: "EXCEEDED_TIME_LIMIT", Type: "USER_ERROR"}, "Trino query failed"},
+		{"Other user error", time.Second, &QueryError{Name: "SYNTAX_ERROR", Type: "USER_ERROR"}, ""},
+		{"Slow user error", 2 * time.Minute, &QueryError{Name: "SYNTAX_ERROR", Type: "USER_ERROR"}, "Slow Trino query (2m0s)"},
+		{"Driver error", time.Second, errors.New("connection refused"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notifier.title(tt.elapsed, tt.err); got != tt.want {
+				t.Errorf("title() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if newQueryNotifier(&config.TrinoConfig{}) != nil {
+		t.Error("newQueryNotifier() should return nil without TRINO_NOTIFY_WEBHOOK_URL")
+	}
+}
+
+func TestQueryNotifierSend(t *testing.T) {
+	event := queryEvent{
+		Title:     "Trino query failed",
+		QueryID:   "20240101_000000_00001_abcde",
+		InfoURI:   "https://trino.example.com/ui/query.html?20240101_000000_00001_abcde",
+		User:      "alice@example.com",
+		TrinoUser: "alice",
+		Cluster:   "prod",
+		Elapsed:   1500 * time.Millisecond,
+		Error:     "Query exceeded per-node memory limit",
+		Query:     "SELECT * FROM hive.sales.orders " + strings.Repeat("x", maxNotifyQueryLength),
+	}
+
+	tests := []struct {
+		format   string
+		key      string
+		contains []string
+	}{
+		{config.NotifyFormatSlack, "text", []string{
+			"*Trino query failed*",
+			"*Query ID:* <https://trino.example.com/ui/query.html?20240101_000000_00001_abcde|20240101_000000_00001_abcde>",
+			"*User:* alice@example.com",
+			"*Trino user:* alice",
+			"*Cluster:* prod",
+			"*Elapsed:* 1.5s",
+			"*Error:* Query exceeded per-node memory limit",
+			"SELECT * FROM hive.sales.orders",
+			"…",
+		}},
+		{config.NotifyFormatTeams, "text", []string{
+			"**Query ID:** [20240101_000000_00001_abcde](https://trino.example.com/ui/query.html?20240101_000000_00001_abcde)",
+			"**User:** alice@example.com",
+			"SELECT * FROM hive.sales.orders",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			received := make(chan map[string]interface{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var payload map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("invalid payload: %v", err)
+				}
+				if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Content-Type = %q, want application/json", ct)
+				}
+				received <- payload
+			}))
+			defer server.Close()
+
+			notifier := newQueryNotifier(&config.TrinoConfig{NotifyWebhookURL: server.URL, NotifyFormat: tt.format})
+			notifier.send(context.Background(), event)
+
+			payload := <-received
+			text, _ := payload[tt.key].(string)
+			for _, want := range tt.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("%s payload missing %q:\n%s", tt.format, want, text)
+				}
+			}
+			if tt.format == config.NotifyFormatTeams && (payload["@type"] != "MessageCard" || payload["title"] != event.Title) {
+				t.Errorf("Teams payload = %v, want a MessageCard titled %q", payload, event.Title)
+			}
+		})
+	}
+}
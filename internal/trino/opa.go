@@ -0,0 +1,160 @@
+package trino
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// opaInput is the document sent to OPA as "input" for every query
+type opaInput struct {
+	Query         string     `json:"query"`
+	StatementType string     `json:"statementType"`
+	ReadOnly      bool       `json:"readOnly"`
+	Tables        []TableRef `json:"tables"`
+	User          string     `json:"user,omitempty"` // Authenticated OAuth user
+	TrinoUser     string     `json:"trinoUser"`      // User the query runs as in Trino
+	Cluster       string     `json:"cluster,omitempty"`
+	Limits        opaLimits  `json:"limits"`
+}
+
+// opaLimits are the result limits of a query; 0 means unlimited
+type opaLimits struct {
+	MaxRows  int   `json:"maxRows"`
+	MaxBytes int64 `json:"maxBytes"`
+}
+
+// opaDecision is the object form of a policy result. A policy may also
+// return a plain boolean.
+type opaDecision struct {
+	Allow    bool   `json:"allow"`
+	Reason   string `json:"reason"`
+	MaxRows  *int   `json:"maxRows"`  // Tightens the row limit
+	MaxBytes *int64 `json:"maxBytes"` // Tightens the byte limit
+}
+
+// opaAuthorizer asks an Open Policy Agent endpoint whether a query may run
+type opaAuthorizer struct {
+	url        string
+	failOpen   bool
+	httpClient *http.Client
+}
+
+// newOPAAuthorizer returns an authorizer for cfg, or nil if TRINO_OPA_URL is not set
+func newOPAAuthorizer(cfg *config.TrinoConfig) *opaAuthorizer {
+	if cfg.OPAURL == "" {
+		return nil
+	}
+	timeout := cfg.OPATimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &opaAuthorizer{
+		url:        cfg.OPAURL,
+		failOpen:   cfg.OPAFailOpen,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// authorize posts the query metadata to OPA and enforces the decision. An
+// allowed query may come back with tighter result limits in opts.
+func (a *opaAuthorizer) authorize(ctx context.Context, input *opaInput, opts *queryOptions) error {
+	decision, err := a.decide(ctx, input)
+	if err != nil {
+		if a.failOpen {
+			log.Printf("WARNING: OPA policy check failed, allowing query (TRINO_OPA_FAIL_OPEN=true): %v", err)
+			return nil
+		}
+		return fmt.Errorf("query authorization failed: %w", err)
+	}
+
+	if !decision.Allow {
+		log.Printf("WARNING: Query from user %s denied by OPA policy: %s", input.TrinoUser, decision.Reason)
+		if decision.Reason != "" {
+			return policyError(ErrorPermissionDenied, "", "query denied by policy: %s", decision.Reason)
+		}
+		return policyError(ErrorPermissionDenied, "", "query denied by policy")
+	}
+
+	if decision.MaxRows != nil && *decision.MaxRows > 0 && (opts.maxRows == 0 || *decision.MaxRows < opts.maxRows) {
+		opts.maxRows = *decision.MaxRows
+	}
+	if decision.MaxBytes != nil && *decision.MaxBytes > 0 && (opts.maxBytes == 0 || *decision.MaxBytes < opts.maxBytes) {
+		opts.maxBytes = *decision.MaxBytes
+	}
+	return nil
+}
+
+// decide queries the OPA Data API and parses the result
+func (a *opaAuthorizer) decide(ctx context.Context, input *opaInput) (*opaDecision, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OPA input: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OPA request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OPA request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("OPA returned HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid OPA response: %w", err)
+	}
+	if len(response.Result) == 0 {
+		return nil, fmt.Errorf("OPA returned no result; check that the policy path in TRINO_OPA_URL exists")
+	}
+
+	var allow bool
+	if err := json.Unmarshal(response.Result, &allow); err == nil {
+		return &opaDecision{Allow: allow}, nil
+	}
+	var decision opaDecision
+	if err := json.Unmarshal(response.Result, &decision); err != nil {
+		return nil, fmt.Errorf("invalid OPA result: expected a boolean or an object with an allow field: %w", err)
+	}
+	return &decision, nil
+}
+
+// authorizeQuery checks the query with OPA when TRINO_OPA_URL is set
+func (c *Client) authorizeQuery(ctx context.Context, query string, opts *queryOptions) error {
+	if c.authorizer == nil {
+		return nil
+	}
+	metadata := AnalyzeQuery(query, c.config.Catalog, c.config.DefaultSchema(c.config.Catalog))
+	trinoUser := c.config.User
+	if user, ok := GetImpersonatedUser(ctx); ok {
+		trinoUser = user
+	}
+	input := &opaInput{
+		Query:         query,
+		StatementType: metadata.StatementType,
+		ReadOnly:      metadata.ReadOnly,
+		Tables:        metadata.Tables,
+		User:          getQueryUsername(ctx),
+		TrinoUser:     trinoUser,
+		Cluster:       c.config.ClusterName,
+		Limits:        opaLimits{MaxRows: opts.maxRows, MaxBytes: opts.maxBytes},
+	}
+	return c.authorizer.authorize(ctx, input, opts)
+}
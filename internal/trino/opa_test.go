@@ -0,0 +1,113 @@
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestOPAAuthorize(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      string
+		opts        queryOptions
+		expectError string
+		expected    queryOptions
+	}{
+		{"Boolean allow", `{"result": true}`, queryOptions{maxRows: 100}, "", queryOptions{maxRows: 100}},
+		{"Boolean deny", `{"result": false}`, queryOptions{}, "query denied by policy", queryOptions{}},
+		{"Deny with reason", `{"result": {"allow": false, "reason": "pii tables are restricted"}}`, queryOptions{},
+			"query denied by policy: pii tables are restricted", queryOptions{}},
+		{"Tighter limits", `{"result": {"allow": true, "maxRows": 10, "maxBytes": 1024}}`,
+			queryOptions{maxRows: 100}, "", queryOptions{maxRows: 10, maxBytes: 1024}},
+		{"Looser limits are ignored", `{"result": {"allow": true, "maxRows": 1000}}`,
+			queryOptions{maxRows: 100}, "", queryOptions{maxRows: 100}},
+		{"Missing result", `{}`, queryOptions{}, "OPA returned no result", queryOptions{}},
+		{"Invalid result", `{"result": "yes"}`, queryOptions{}, "invalid OPA result", queryOptions{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.result))
+			}))
+			defer server.Close()
+
+			authorizer := newOPAAuthorizer(&config.TrinoConfig{OPAURL: server.URL})
+			opts := tt.opts
+			err := authorizer.authorize(context.Background(), &opaInput{Query: "SELECT 1"}, &opts)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("authorize() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authorize() error = %v", err)
+			}
+			if opts.maxRows != tt.expected.maxRows || opts.maxBytes != tt.expected.maxBytes {
+				t.Errorf("limits = %d rows / %d bytes, want %d / %d", opts.maxRows, opts.maxBytes, tt.expected.maxRows, tt.expected.maxBytes)
+			}
+		})
+	}
+}
+
+func TestOPAUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "policy engine down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := &config.TrinoConfig{OPAURL: server.URL, OPATimeout: time.Second}
+	err := newOPAAuthorizer(cfg).authorize(context.Background(), &opaInput{}, &queryOptions{})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 503") {
+		t.Errorf("authorize() error = %v, want HTTP 503 failure", err)
+	}
+
+	cfg.OPAFailOpen = true
+	if err := newOPAAuthorizer(cfg).authorize(context.Background(), &opaInput{}, &queryOptions{}); err != nil {
+		t.Errorf("authorize() with fail open error = %v, want nil", err)
+	}
+
+	if newOPAAuthorizer(&config.TrinoConfig{}) != nil {
+		t.Error("newOPAAuthorizer() should return nil without TRINO_OPA_URL")
+	}
+}
+
+func TestAuthorizeQueryInput(t *testing.T) {
+	var input opaInput
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input opaInput `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode OPA request: %v", err)
+		}
+		input = body.Input
+		_, _ = w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.TrinoConfig{OPAURL: server.URL, User: "svc", Catalog: "hive", Schema: "sales", ClusterName: "prod"}
+	client := &Client{config: cfg, authorizer: newOPAAuthorizer(cfg)}
+	ctx := WithImpersonatedUser(context.Background(), "alice")
+	if err := client.authorizeQuery(ctx, "SELECT * FROM orders", &queryOptions{maxRows: 50}); err != nil {
+		t.Fatalf("authorizeQuery() error = %v", err)
+	}
+
+	if input.StatementType != "select" || !input.ReadOnly {
+		t.Errorf("statement = %q read-only %t, want select read-only", input.StatementType, input.ReadOnly)
+	}
+	if len(input.Tables) != 1 || input.Tables[0].String() != "hive.sales.orders" {
+		t.Errorf("Tables = %v, want [hive.sales.orders]", input.Tables)
+	}
+	if input.TrinoUser != "alice" || input.Cluster != "prod" || input.Limits.MaxRows != 50 {
+		t.Errorf("input = %+v, want trinoUser alice, cluster prod, maxRows 50", input)
+	}
+}
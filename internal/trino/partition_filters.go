@@ -0,0 +1,127 @@
+package trino
+
+import "strings"
+
+// whereClauseEnd are the keywords that end a WHERE clause at the same
+// parenthesis depth
+var whereClauseEnd = map[string]bool{
+	"group": true, "order": true, "having": true, "limit": true, "offset": true, "fetch": true,
+	"window": true, "union": true, "intersect": true, "except": true,
+}
+
+// checkPartitionFilters rejects a query that scans a table listed in
+// TRINO_REQUIRED_PARTITION_FILTERS without comparing one of the table's
+// partition columns in a WHERE clause. Like the other query checks it is
+// lexical: a column counts as filtered when it is compared directly (=, <, >,
+// IN, BETWEEN, LIKE) anywhere in a WHERE clause of the statement.
+func (c *Client) checkPartitionFilters(query string) error {
+	required := c.currentPolicy().RequiredPartitionFilters
+	if len(required) == 0 {
+		return nil
+	}
+	tokens := tokenizeSQL(query)
+	statement := statementType(tokens)
+	if statement == "describe" || statement == "show" {
+		return nil
+	}
+
+	var filtered map[string]bool
+	for _, name := range tableNames(tokens) {
+		if name.write && statement != "delete" && statement != "update" {
+			continue // INSERT, CREATE and other targets are not scanned
+		}
+		if name.cte {
+			continue
+		}
+		ref := resolveTable(name.parts, c.config.Catalog, c.config.DefaultSchema(c.config.Catalog))
+		columns := required[strings.ToLower(ref.String())]
+		if len(columns) == 0 {
+			continue
+		}
+		if filtered == nil {
+			filtered = filteredColumns(tokens)
+		}
+		if !containsAny(filtered, columns) {
+			which := columns[0]
+			if len(columns) > 1 {
+				which = "one of " + strings.Join(columns, ", ")
+			}
+			return policyError(ErrorQueryRejected, "Add a WHERE condition on "+which+"; list_partitions shows the partitions that exist",
+				"partition filter required: queries on %s must filter on %s in the WHERE clause", ref, which)
+		}
+	}
+	return nil
+}
+
+// filteredColumns returns the columns compared in WHERE clauses. Qualified
+// references (e.ds) are reported by column name.
+func filteredColumns(tokens []sqlToken) map[string]bool {
+	filtered := make(map[string]bool)
+	inWhere := []bool{false} // Per parenthesis depth
+	for i, token := range tokens {
+		top := len(inWhere) - 1
+		switch {
+		case token.text == "(":
+			// Conditions stay in the WHERE clause; a subquery starts a new statement
+			subquery := i+1 < len(tokens) && (tokens[i+1].keyword("select") || tokens[i+1].keyword("with"))
+			inWhere = append(inWhere, inWhere[top] && !subquery)
+			continue
+		case token.text == ")":
+			if top > 0 {
+				inWhere = inWhere[:top]
+			}
+			continue
+		case token.keyword("where"):
+			inWhere[top] = true
+			continue
+		case token.ident && !token.quoted && whereClauseEnd[token.text]:
+			inWhere[top] = false
+			continue
+		}
+		if !inWhere[top] || !token.ident || i+1 < len(tokens) && (tokens[i+1].text == "." || tokens[i+1].text == "(" || tokens[i+1].text == "'") {
+			continue // Not a column: qualifier, function name or typed literal
+		}
+		if isCompared(tokens, i) {
+			filtered[token.text] = true
+		}
+	}
+	return filtered
+}
+
+// isCompared reports whether the column at i is an operand of a comparison,
+// IN, BETWEEN or LIKE
+func isCompared(tokens []sqlToken, i int) bool {
+	if i+1 < len(tokens) {
+		next := tokens[i+1]
+		if next.text == "=" || next.text == "<" || next.text == ">" || next.text == "!" {
+			return true
+		}
+		if next.keyword("not") && i+2 < len(tokens) {
+			next = tokens[i+2]
+		}
+		if next.keyword("in") || next.keyword("between") || next.keyword("like") {
+			return true
+		}
+	}
+
+	// Column on the right-hand side, possibly qualified: '2024-01-01' <= e.ds
+	start := i
+	for start >= 2 && tokens[start-1].text == "." && tokens[start-2].ident {
+		start -= 2
+	}
+	if start > 0 {
+		prev := tokens[start-1].text
+		return prev == "=" || prev == "<" || prev == ">"
+	}
+	return false
+}
+
+// containsAny reports whether any of the names is in the set
+func containsAny(set map[string]bool, names []string) bool {
+	for _, name := range names {
+		if set[name] {
+			return true
+		}
+	}
+	return false
+}
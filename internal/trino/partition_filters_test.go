@@ -0,0 +1,68 @@
+package trino
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestCheckPartitionFilters(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			Catalog: "hive",
+			Schema:  "analytics",
+			RequiredPartitionFilters: map[string][]string{
+				"hive.analytics.events": {"ds"},
+				"hive.logs.requests":    {"ds", "hour"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		expectError string
+	}{
+		{"Equality", "SELECT * FROM events WHERE ds = '2024-01-31'", ""},
+		{"Range", "SELECT count(*) FROM hive.analytics.events WHERE ds >= '2024-01-01' AND ds < '2024-02-01'", ""},
+		{"Qualified column", "SELECT e.id FROM events e JOIN users u ON e.user_id = u.id WHERE e.ds = '2024-01-31'", ""},
+		{"Column on the right", "SELECT * FROM events WHERE '2024-01-01' <= ds", ""},
+		{"In list", "SELECT * FROM events WHERE ds IN ('2024-01-30', '2024-01-31')", ""},
+		{"Between", "SELECT * FROM events WHERE ds BETWEEN '2024-01-01' AND '2024-01-31'", ""},
+		{"Nested condition", "SELECT * FROM events WHERE (ds = '2024-01-31' OR ds = '2024-01-30') AND id > 0", ""},
+		{"Any of several columns", "SELECT * FROM hive.logs.requests WHERE hour = 12", ""},
+		{"Subquery filters", "SELECT * FROM users WHERE id IN (SELECT user_id FROM events WHERE ds = '2024-01-31')", ""},
+		{"Other table", "SELECT * FROM users", ""},
+		{"Partitions metadata table", `SELECT * FROM "events$partitions"`, ""},
+		{"Describe", "DESCRIBE events", ""},
+		{"Insert target", "INSERT INTO events SELECT * FROM staging WHERE id > 0", ""},
+		{"No WHERE clause", "SELECT * FROM events LIMIT 10", "queries on hive.analytics.events must filter on ds in the WHERE clause"},
+		{"Filter on other column", "SELECT * FROM events WHERE id = 1", "must filter on ds"},
+		{"Partition column in select list only", "SELECT ds, count(*) FROM events GROUP BY ds", "must filter on ds"},
+		{"Partition column only in ORDER BY", "SELECT * FROM events WHERE id = 1 ORDER BY ds = '2024-01-31'", "must filter on ds"},
+		{"Join condition", "SELECT * FROM users u JOIN events e ON e.ds = u.signup_date", "must filter on ds"},
+		{"Function of the column", "SELECT * FROM events WHERE date(ds) = current_date", "must filter on ds"},
+		{"IS NOT NULL", "SELECT * FROM events WHERE ds IS NOT NULL", "must filter on ds"},
+		{"Several columns", "SELECT * FROM hive.logs.requests", "queries on hive.logs.requests must filter on one of ds, hour"},
+		{"Through a CTE", "WITH e AS (SELECT * FROM events) SELECT * FROM e WHERE ds = '2024-01-31'", ""},
+		{"Parenthesized table", "SELECT * FROM (events) e", "must filter on ds"},
+		{"Parenthesized table filtered", "SELECT * FROM (events) e WHERE e.ds = '2024-01-31'", ""},
+		{"Explain", "EXPLAIN SELECT * FROM events", "must filter on ds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.checkPartitionFilters(tt.query)
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("checkPartitionFilters(%q) unexpected error: %v", tt.query, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("checkPartitionFilters(%q) error = %v, want %q", tt.query, err, tt.expectError)
+			}
+		})
+	}
+}
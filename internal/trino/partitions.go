@@ -0,0 +1,173 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Partition table formats detected by ListPartitionsWithContext
+const (
+	PartitionsHive    = "hive"    // $partitions has one column per partition key
+	PartitionsIceberg = "iceberg" // $partitions has a partition row with per-partition statistics
+)
+
+// Partition listing defaults and bounds
+const (
+	DefaultPartitionLimit = 100
+	MaxPartitionLimit     = 1000
+)
+
+// PartitionListing lists the partitions of a Hive or Iceberg table and, when
+// a filter is given, how many of them a query with that filter would scan
+type PartitionListing struct {
+	Table              string                   `json:"table"`
+	Format             string                   `json:"format"`
+	PartitionColumns   []string                 `json:"partitionColumns"`
+	Filter             string                   `json:"filter,omitempty"`
+	TotalPartitions    int64                    `json:"totalPartitions"`
+	MatchingPartitions int64                    `json:"matchingPartitions"`     // Partitions the filter keeps; all of them without a filter
+	TotalSize          *int64                   `json:"totalSize,omitempty"`    // Iceberg only
+	MatchingSize       *int64                   `json:"matchingSize,omitempty"` // Iceberg only
+	Partitions         []map[string]interface{} `json:"partitions,omitempty"`   // Matching partitions, newest partition values first
+	Truncated          bool                     `json:"truncated,omitempty"`
+	Note               string                   `json:"note,omitempty"`
+}
+
+// ListPartitionsWithContext lists up to limit partitions of a Hive or Iceberg
+// table from its $partitions metadata table. The filter is a WHERE condition
+// on the partition columns, such as "ds >= '2024-01-01'"; the partitions it
+// keeps are the ones a query with the same condition reads, which shows
+// whether the condition prunes partitions before an expensive scan runs.
+// Iceberg partition columns are the partition field names, which include the
+// transform (event_time_day, id_bucket) unless the field is an identity.
+func (c *Client) ListPartitionsWithContext(ctx context.Context, catalog, schema, table, filter string, limit int) (*PartitionListing, error) {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	if err := c.checkTableAccess(catalog, schema, table); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultPartitionLimit
+	}
+	if limit > MaxPartitionLimit {
+		limit = MaxPartitionLimit
+	}
+	filter = strings.TrimSuffix(strings.TrimSpace(filter), ";")
+	if err := c.validateFilter("partition filter", filter, TableRef{Catalog: catalog, Schema: schema, Table: table}); err != nil {
+		return nil, err
+	}
+
+	listing := &PartitionListing{Table: catalog + "." + schema + "." + table, Filter: filter}
+	name := quoteIdentifier(catalog) + "." + quoteIdentifier(schema) + "." + quoteIdentifier(table+"$partitions")
+	failed := func(err error) error {
+		return fmt.Errorf("failed to read $partitions of %s (is it a partitioned Hive or Iceberg table?): %w", listing.Table, err)
+	}
+
+	// Iceberg exposes the partition values as a row next to statistics columns
+	probe, err := c.ExecuteQueryWithResult(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT 0", name))
+	if err != nil {
+		return nil, failed(err)
+	}
+	columns := make(map[string]bool)
+	for _, column := range probe.Columns {
+		columns[column] = true
+	}
+	source := name
+	listing.Format = PartitionsHive
+	listing.PartitionColumns = probe.Columns
+	if columns["record_count"] && columns["file_count"] && columns["total_size"] {
+		listing.Format = PartitionsIceberg
+		if !columns["partition"] {
+			listing.Note = "table is not partitioned"
+			listing.PartitionColumns = []string{}
+			return listing, nil
+		}
+		source = fmt.Sprintf("(SELECT partition.*, record_count, file_count, total_size FROM %s)", name)
+		fields, err := c.ExecuteQueryWithResult(ctx, fmt.Sprintf("SELECT partition.* FROM %s LIMIT 0", name))
+		if err != nil {
+			return nil, failed(err)
+		}
+		listing.PartitionColumns = fields.Columns
+	}
+
+	condition := "true"
+	if filter != "" {
+		condition = "(" + filter + ")"
+	}
+	aggregates := fmt.Sprintf("count(*) AS total, count_if(%s) AS matching", condition)
+	if listing.Format == PartitionsIceberg {
+		aggregates += fmt.Sprintf(", coalesce(sum(total_size), 0) AS total_size, coalesce(sum(total_size) FILTER (WHERE %s), 0) AS matching_size", condition)
+	}
+	totals, err := c.ExecuteQueryWithContext(ctx, fmt.Sprintf("SELECT %s FROM %s", aggregates, source))
+	if err != nil {
+		return nil, failed(err)
+	}
+	if len(totals) > 0 {
+		listing.TotalPartitions = int64Value(totals[0]["total"])
+		listing.MatchingPartitions = int64Value(totals[0]["matching"])
+		listing.TotalSize = optionalInt64(totals[0]["total_size"])
+		listing.MatchingSize = optionalInt64(totals[0]["matching_size"])
+	}
+
+	// Partition values could reveal masked columns
+	if c.hasColumnMasks(catalog, schema, table) {
+		listing.Note = "partition values are hidden because the table has masked columns"
+		return listing, nil
+	}
+
+	order := make([]string, 0, len(listing.PartitionColumns))
+	for _, column := range listing.PartitionColumns {
+		order = append(order, quoteIdentifier(column)+" DESC")
+	}
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s", source, condition)
+	if len(order) > 0 {
+		query += " ORDER BY " + strings.Join(order, ", ")
+	}
+	rows, err := c.ExecuteQueryWithContext(ctx, fmt.Sprintf("%s LIMIT %d", query, limit))
+	if err != nil {
+		return nil, failed(err)
+	}
+	listing.Partitions = rows
+	listing.Truncated = int64(len(rows)) < listing.MatchingPartitions
+	return listing, nil
+}
+
+// validateFilter checks that a filter, such as a partition filter, is a
+// single condition: it may not contain subqueries or other statements, which
+// could read tables the allowlists do not cover, nor refer to masked columns
+// of the tables it applies to. kind names the filter in errors.
+func (c *Client) validateFilter(kind, filter string, tables ...TableRef) error {
+	depth := 0
+	for _, token := range tokenizeSQL(filter) {
+		switch {
+		case token.text == "(":
+			depth++
+		case token.text == ")":
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("invalid %s: unbalanced parentheses", kind)
+			}
+		case token.text == ";":
+			return fmt.Errorf("invalid %s: must be a single condition", kind)
+		case token.keyword("select") || token.keyword("from") || token.keyword("with") || token.keyword("values") ||
+			token.keyword("table") || token.keyword("union") || token.keyword("intersect") || token.keyword("except"):
+			return fmt.Errorf("invalid %s: subqueries are not allowed", kind)
+		case token.ident:
+			for _, table := range tables {
+				if c.isMaskedColumn(table.Catalog, table.Schema, table.Table, token.text) {
+					return fmt.Errorf("column masking: masked column '%s' may not be used in a %s", token.text, kind)
+				}
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("invalid %s: unbalanced parentheses", kind)
+	}
+	return nil
+}
+
+// isMaskedColumn reports whether a column of the table is masked
+func (c *Client) isMaskedColumn(catalog, schema, table, column string) bool {
+	_, masked := c.currentPolicy().ColumnMasks[strings.ToLower(catalog+"."+schema+"."+table+"."+column)]
+	return masked
+}
@@ -0,0 +1,67 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestValidateFilter(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			ColumnMasks: map[string]string{"hive.analytics.events.user_id": config.MaskSHA256},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		filter      string
+		expectError string
+	}{
+		{"Empty", "", ""},
+		{"Range", "ds >= '2024-01-01' AND ds < '2024-02-01'", ""},
+		{"Nested condition", "(region = 'eu' OR region = 'us') AND ds = '2024-01-31'", ""},
+		{"Function call", "date(ds) > current_date - interval '7' day", ""},
+		{"Keywords in literal", "region = 'select from'", ""},
+		{"Subquery", "ds IN (SELECT max(ds) FROM hive.analytics.users)", "subqueries are not allowed"},
+		{"Union", "true UNION SELECT 1", "subqueries are not allowed"},
+		{"Closing the condition", "true) UNION (SELECT 1", "unbalanced parentheses"},
+		{"Second statement", "true; DROP TABLE x", "single condition"},
+		{"Unbalanced close", "ds = '1')", "unbalanced parentheses"},
+		{"Unbalanced open", "(ds = '1'", "unbalanced parentheses"},
+		{"Masked column", "user_id = 'abc'", "masked column 'user_id'"},
+		{"Masked quoted column", `"USER_ID" = 'abc'`, "masked column 'user_id'"}, // Trino identifiers are case-insensitive
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.validateFilter("partition filter", tt.filter, TableRef{Catalog: "hive", Schema: "analytics", Table: "events"})
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("validateFilter(%q) unexpected error: %v", tt.filter, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("validateFilter(%q) error = %v, want %q", tt.filter, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestListPartitionsAllowlist(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			Catalog:       "hive",
+			Schema:        "default",
+			AllowedTables: []string{"hive.analytics.events"},
+		},
+	}
+
+	_, err := client.ListPartitionsWithContext(context.Background(), "", "", "analytics.users", "", 0)
+	if err == nil || !strings.Contains(err.Error(), "table access denied: hive.analytics.users") {
+		t.Errorf("ListPartitionsWithContext() error = %v, want table access denied", err)
+	}
+}
@@ -0,0 +1,207 @@
+package trino
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// pkceLogin logs in with the identity provider directly instead of through
+// Trino: it serves the OAuth redirect on localhost and exchanges the
+// authorization code with PKCE. It suits Trino deployments whose OAuth setup
+// does not expose x_token_server, as long as Trino accepts the IdP's tokens.
+type pkceLogin struct {
+	idp        *config.ExternalAuthIdP
+	httpClient *http.Client
+	timeout    time.Duration
+
+	mu       sync.Mutex
+	endpoint *oauth2.Endpoint // Discovered from the issuer on first use
+}
+
+// pkceCallback is the result of the redirect back from the identity provider
+type pkceCallback struct {
+	code string
+	err  error
+}
+
+// usePKCE makes the authenticator log in with idp instead of Trino's flow
+func (a *ExternalAuthenticator) usePKCE(idp *config.ExternalAuthIdP) {
+	p := &pkceLogin{idp: idp, httpClient: a.httpClient, timeout: a.timeout}
+	a.login = p.start
+}
+
+// start listens for the redirect and returns the IdP's authorization URL
+func (p *pkceLogin) start(ctx context.Context) (string, waitFunc, error) {
+	endpoint, err := p.endpoints(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to discover OAuth endpoints of %s: %w", p.idp.Issuer, err)
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", p.idp.RedirectPort))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to listen for the OAuth redirect: %w", err)
+	}
+	conf := &oauth2.Config{
+		ClientID:     p.idp.ClientID,
+		ClientSecret: p.idp.ClientSecret,
+		Endpoint:     *endpoint,
+		RedirectURL:  fmt.Sprintf("http://localhost:%d/callback", listener.Addr().(*net.TCPAddr).Port),
+		Scopes:       p.idp.Scopes,
+	}
+	state, err := randomState()
+	if err != nil {
+		_ = listener.Close()
+		return "", nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	callbacks := make(chan pkceCallback, 1)
+	server := &http.Server{Handler: pkceCallbackHandler(state, callbacks), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("WARNING: OAuth redirect listener failed: %v", err)
+		}
+	}()
+
+	wait := func(ctx context.Context) (string, time.Time, error) {
+		defer server.Close()
+		timer := time.NewTimer(p.timeout)
+		defer timer.Stop()
+
+		var callback pkceCallback
+		select {
+		case <-ctx.Done():
+			return "", time.Time{}, ctx.Err()
+		case <-timer.C:
+			return "", time.Time{}, &LoginError{State: AuthExpired, Reason: fmt.Sprintf("user did not complete authentication within %v", p.timeout)}
+		case callback = <-callbacks:
+		}
+		if callback.err != nil {
+			return "", time.Time{}, callback.err
+		}
+
+		token, err := conf.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.httpClient), callback.code, oauth2.VerifierOption(verifier))
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to exchange the authorization code: %w", err)
+		}
+		expiresAt := token.Expiry
+		if expiresAt.IsZero() {
+			expiresAt = time.Now().Add(1 * time.Hour)
+		}
+		if p.idp.UseIDToken {
+			idToken, _ := token.Extra("id_token").(string)
+			if idToken == "" {
+				return "", time.Time{}, fmt.Errorf("identity provider returned no ID token; request the openid scope")
+			}
+			return idToken, expiresAt, nil
+		}
+		return token.AccessToken, expiresAt, nil
+	}
+	return conf.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), wait, nil
+}
+
+// pkceCallbackHandler receives the redirect from the identity provider and
+// passes on its authorization code or error
+func pkceCallbackHandler(state string, callbacks chan<- pkceCallback) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		// Ignore redirects that do not belong to this login
+		if query.Get("state") != state {
+			http.Error(w, "Unknown login. Start again from your MCP client.", http.StatusBadRequest)
+			return
+		}
+
+		var callback pkceCallback
+		switch {
+		case query.Get("error") != "":
+			callback.err = &LoginError{State: AuthDenied, Reason: fmt.Sprintf("identity provider returned %s: %s", query.Get("error"), query.Get("error_description"))}
+		case query.Get("code") == "":
+			callback.err = fmt.Errorf("identity provider returned no authorization code")
+		default:
+			callback.code = query.Get("code")
+		}
+		select {
+		case callbacks <- callback:
+		default:
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if callback.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<p>Trino login failed: %s</p>", html.EscapeString(callback.err.Error()))
+			return
+		}
+		fmt.Fprint(w, "<p>Logged in to Trino. You can close this window.</p>")
+	})
+	return mux
+}
+
+// endpoints returns the configured authorization and token endpoints, or
+// those of the issuer's OpenID Connect discovery document
+func (p *pkceLogin) endpoints(ctx context.Context) (*oauth2.Endpoint, error) {
+	if p.idp.AuthorizeURL != "" && p.idp.TokenURL != "" {
+		return &oauth2.Endpoint{AuthURL: p.idp.AuthorizeURL, TokenURL: p.idp.TokenURL}, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.endpoint != nil {
+		return p.endpoint, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.idp.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery returned HTTP %d", resp.StatusCode)
+	}
+	var discovery struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&discovery); err != nil {
+		return nil, fmt.Errorf("invalid discovery document: %w", err)
+	}
+
+	endpoint := &oauth2.Endpoint{AuthURL: discovery.AuthorizationEndpoint, TokenURL: discovery.TokenEndpoint}
+	// Explicit endpoints override discovered ones
+	if p.idp.AuthorizeURL != "" {
+		endpoint.AuthURL = p.idp.AuthorizeURL
+	}
+	if p.idp.TokenURL != "" {
+		endpoint.TokenURL = p.idp.TokenURL
+	}
+	if endpoint.AuthURL == "" || endpoint.TokenURL == "" {
+		return nil, fmt.Errorf("discovery document has no authorization or token endpoint")
+	}
+	p.endpoint = endpoint
+	return endpoint, nil
+}
+
+// randomState returns an unguessable OAuth state parameter
+func randomState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
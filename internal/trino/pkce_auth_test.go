@@ -0,0 +1,95 @@
+package trino
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestPKCELogin(t *testing.T) {
+	var challenge string
+	var idp *httptest.Server
+	idp = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			fmt.Fprintf(w, `{"authorization_endpoint":"%s/authorize","token_endpoint":"%s/token"}`, idp.URL, idp.URL)
+		case "/token":
+			_ = r.ParseForm()
+			sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+			if r.PostForm.Get("code") != "auth-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant"}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token":"idp-access-token","id_token":"idp-id-token","token_type":"Bearer","expires_in":600}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer idp.Close()
+
+	auth := NewExternalAuthenticator("http://trino.invalid", "testuser", 5, false)
+	auth.headless = true
+	auth.usePKCE(&config.ExternalAuthIdP{Issuer: idp.URL, ClientID: "mcp-trino", Scopes: []string{"openid"}})
+
+	status, err := auth.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	loginURL, err := url.Parse(status.URL)
+	if err != nil || !strings.HasPrefix(status.URL, idp.URL+"/authorize?") {
+		t.Fatalf("login URL = %q, want the IdP's authorization endpoint", status.URL)
+	}
+	query := loginURL.Query()
+	challenge = query.Get("code_challenge")
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != "mcp-trino" || challenge == "" {
+		t.Errorf("login URL = %s, want a PKCE request for mcp-trino", status.URL)
+	}
+
+	// A redirect for another login is refused; the real one completes it
+	redirect := query.Get("redirect_uri")
+	resp, err := http.Get(redirect + "?code=auth-code&state=forged")
+	if err != nil {
+		t.Fatalf("redirect with a forged state: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("redirect with a forged state = HTTP %d, want 400", resp.StatusCode)
+	}
+	resp, err = http.Get(redirect + "?code=auth-code&state=" + url.QueryEscape(query.Get("state")))
+	if err != nil {
+		t.Fatalf("redirect: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("redirect = HTTP %d, want 200", resp.StatusCode)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		token, err := auth.GetToken(context.Background())
+		if err == nil {
+			if token != "idp-access-token" {
+				t.Errorf("GetToken() = %q, want the IdP's access token", token)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetToken() still failing after login: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status, _ := auth.Status(context.Background()); status.TokenExpiresAt == nil || time.Until(*status.TokenExpiresAt) > 10*time.Minute {
+		t.Errorf("token expiry = %v, want the IdP's expires_in", status.TokenExpiresAt)
+	}
+}
@@ -0,0 +1,263 @@
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diagram formats ExplainDiagramWithContext renders plans in
+const (
+	DiagramMermaid = "mermaid" // Mermaid flowchart
+	DiagramDOT     = "dot"     // Graphviz DOT digraph
+)
+
+// planNode is a node of a plan in Trino's EXPLAIN (FORMAT JSON) output
+type planNode struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Descriptor map[string]string `json:"descriptor"`
+	Estimates  []planEstimate    `json:"estimates"`
+	Children   []planNode        `json:"children"`
+}
+
+// planEstimate is a node's cost estimate; unknown values are NaN, which
+// Trino writes as the string "NaN"
+type planEstimate struct {
+	OutputRowCount interface{} `json:"outputRowCount"`
+}
+
+// planDescriptorKeys are the descriptor entries shown under a node's name
+var planDescriptorKeys = []string{"table", "type", "partitioning", "scope"}
+
+// sourceFragmentIDs matches the fragment list of RemoteSource and RemoteMerge nodes
+var sourceFragmentIDs = regexp.MustCompile(`\d+`)
+
+// planFragment is a plan fragment (stage) with its root node
+type planFragment struct {
+	id   string
+	root planNode
+}
+
+// ExplainDiagramWithContext explains a query and renders its plan as a
+// Mermaid flowchart or Graphviz DOT digraph: one node per operator with its
+// table or join type and estimated output rows, and with the distributed plan
+// (planType DISTRIBUTED, the default) one cluster per fragment and dashed
+// edges for the exchanges between them. planType LOGICAL renders the single
+// logical plan.
+func (c *Client) ExplainDiagramWithContext(ctx context.Context, query, planType, diagram string) (string, error) {
+	planType = strings.ToUpper(strings.TrimSpace(planType))
+	if planType == "" {
+		planType = "DISTRIBUTED"
+	}
+	if planType != "DISTRIBUTED" && planType != "LOGICAL" {
+		return "", fmt.Errorf("invalid plan type for a diagram: %q (allowed: LOGICAL, DISTRIBUTED)", planType)
+	}
+	diagram = strings.ToLower(strings.TrimSpace(diagram))
+	if diagram != DiagramMermaid && diagram != DiagramDOT {
+		return "", fmt.Errorf("invalid diagram format: %q (allowed: %s, %s)", diagram, DiagramMermaid, DiagramDOT)
+	}
+
+	// Explained queries are the agent's, like those of ExplainQueryWithContext
+	explainQuery := fmt.Sprintf("EXPLAIN (TYPE %s, FORMAT JSON) %s", planType, query)
+	result, err := c.executeQueryWithRetry(ctx, explainQuery, queryOptions{agent: true}, false)
+	if err != nil {
+		return "", err
+	}
+	var plan string
+	for _, row := range result.Rows {
+		for _, value := range row {
+			plan = stringValue(value)
+		}
+	}
+	if plan == "" {
+		return "", fmt.Errorf("EXPLAIN returned no plan")
+	}
+	return renderPlanDiagram(plan, diagram)
+}
+
+// renderPlanDiagram renders a JSON plan: a single node for LOGICAL plans, or
+// the root nodes of the fragments by fragment ID for DISTRIBUTED plans
+func renderPlanDiagram(plan, diagram string) (string, error) {
+	var fragments []planFragment
+	var root planNode
+	if err := json.Unmarshal([]byte(plan), &root); err == nil && root.Name != "" {
+		fragments = []planFragment{{root: root}}
+	} else {
+		byID := make(map[string]planNode)
+		if err := json.Unmarshal([]byte(plan), &byID); err != nil {
+			return "", fmt.Errorf("failed to parse JSON plan: %w", err)
+		}
+		for id, node := range byID {
+			fragments = append(fragments, planFragment{id: id, root: node})
+		}
+		sort.Slice(fragments, func(i, j int) bool {
+			a, _ := strconv.Atoi(fragments[i].id)
+			b, _ := strconv.Atoi(fragments[j].id)
+			return a < b
+		})
+	}
+
+	r := &planRenderer{dot: diagram == DiagramDOT, roots: make(map[string]string)}
+	for _, fragment := range fragments {
+		r.roots[fragment.id] = r.nodeID(fragment.root)
+	}
+	r.begin()
+	for _, fragment := range fragments {
+		r.fragment(fragment)
+	}
+	r.edges()
+	return strings.TrimRight(r.sb.String(), "\n"), nil
+}
+
+// planRenderer writes a plan as Mermaid or DOT
+type planRenderer struct {
+	dot       bool
+	sb        strings.Builder
+	roots     map[string]string // Diagram ID of each fragment's root node
+	links     []string          // Edges within fragments
+	exchanges []string          // Edges between fragments
+	ids       int
+}
+
+func (r *planRenderer) begin() {
+	if r.dot {
+		r.sb.WriteString("digraph plan {\n  rankdir=BT;\n  node [shape=box, fontname=\"Helvetica\"];\n")
+	} else {
+		r.sb.WriteString("flowchart BT\n")
+	}
+}
+
+// nodeID returns the diagram ID of a plan node
+func (r *planRenderer) nodeID(node planNode) string {
+	var id strings.Builder
+	for _, ch := range node.ID {
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' {
+			id.WriteRune(ch)
+		}
+	}
+	if id.Len() == 0 {
+		r.ids++
+		return fmt.Sprintf("node_%d", r.ids)
+	}
+	return "n" + id.String()
+}
+
+// fragment writes the nodes of a fragment, grouped unless the plan has a single fragment
+func (r *planRenderer) fragment(fragment planFragment) {
+	indent := "  "
+	if fragment.id != "" {
+		if r.dot {
+			fmt.Fprintf(&r.sb, "  subgraph cluster_%s {\n    label=\"Fragment %s\";\n    style=dashed;\n", fragment.id, fragment.id)
+		} else {
+			fmt.Fprintf(&r.sb, "  subgraph fragment%s[\"Fragment %s\"]\n", fragment.id, fragment.id)
+		}
+		indent = "    "
+	}
+	r.nodes(fragment.root, r.roots[fragment.id], indent)
+	if fragment.id != "" {
+		if r.dot {
+			r.sb.WriteString("  }\n")
+		} else {
+			r.sb.WriteString("  end\n")
+		}
+	}
+}
+
+// nodes writes a node and its descendants; edges point in the direction data flows
+func (r *planRenderer) nodes(node planNode, id, indent string) {
+	lines := []string{node.Name}
+	for _, key := range planDescriptorKeys {
+		if value := node.Descriptor[key]; value != "" {
+			lines = append(lines, key+": "+value)
+		}
+	}
+	if len(node.Estimates) > 0 {
+		if rows, ok := estimateValue(node.Estimates[0].OutputRowCount); ok {
+			lines = append(lines, "rows: "+formatRowCount(rows))
+		}
+	}
+
+	if r.dot {
+		fmt.Fprintf(&r.sb, "%s%s [label=\"%s\"];\n", indent, id, dotEscape(strings.Join(lines, "\n")))
+	} else {
+		fmt.Fprintf(&r.sb, "%s%s[\"%s\"]\n", indent, id, mermaidEscape(lines))
+	}
+
+	// Remote sources read the output of other fragments
+	if strings.HasPrefix(node.Name, "Remote") {
+		for _, source := range sourceFragmentIDs.FindAllString(node.Descriptor["sourceFragmentIds"], -1) {
+			if root, ok := r.roots[source]; ok {
+				r.exchanges = append(r.exchanges, r.edge(root, id, true))
+			}
+		}
+	}
+	for _, child := range node.Children {
+		childID := r.nodeID(child)
+		r.nodes(child, childID, indent)
+		r.links = append(r.links, r.edge(childID, id, false))
+	}
+}
+
+func (r *planRenderer) edge(from, to string, exchange bool) string {
+	switch {
+	case r.dot && exchange:
+		return fmt.Sprintf("  %s -> %s [style=dashed, label=\"exchange\"];\n", from, to)
+	case r.dot:
+		return fmt.Sprintf("  %s -> %s;\n", from, to)
+	case exchange:
+		return fmt.Sprintf("  %s -. exchange .-> %s\n", from, to)
+	}
+	return fmt.Sprintf("  %s --> %s\n", from, to)
+}
+
+func (r *planRenderer) edges() {
+	for _, edge := range append(r.links, r.exchanges...) {
+		r.sb.WriteString(edge)
+	}
+	if r.dot {
+		r.sb.WriteString("}\n")
+	}
+}
+
+// estimateValue returns a finite estimate; Trino reports unknown estimates as NaN
+func estimateValue(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// formatRowCount abbreviates a row count, e.g. 1.5M
+func formatRowCount(rows float64) string {
+	for _, unit := range []struct {
+		size   float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "K"}} {
+		if rows >= unit.size {
+			return strconv.FormatFloat(math.Round(rows/unit.size*10)/10, 'f', -1, 64) + unit.suffix
+		}
+	}
+	return strconv.FormatFloat(math.Round(rows), 'f', -1, 64)
+}
+
+// mermaidEscape joins label lines for a quoted Mermaid label
+func mermaidEscape(lines []string) string {
+	replacer := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = replacer.Replace(line)
+	}
+	return strings.Join(escaped, "<br/>")
+}
+
+// dotEscape escapes a DOT label, keeping line breaks
+func dotEscape(label string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(label)
+}
@@ -0,0 +1,105 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+const distributedPlan = `{
+  "0" : {"id" : "9", "name" : "Output", "descriptor" : {"columnNames" : "[region, n]"}, "estimates" : [ {"outputRowCount" : 5.0} ],
+    "children" : [ {"id" : "145", "name" : "RemoteSource", "descriptor" : {"sourceFragmentIds" : "[1]"}, "estimates" : [ {"outputRowCount" : "NaN"} ], "children" : [ ] } ] },
+  "1" : {"id" : "4", "name" : "Aggregate", "descriptor" : {"type" : "FINAL", "keys" : "[region]"}, "estimates" : [ {"outputRowCount" : 1500000.0} ],
+    "children" : [ {"id" : "0", "name" : "TableScan", "descriptor" : {"table" : "tpch:tiny:\"customer\""}, "estimates" : [ {"outputRowCount" : 1500.0} ], "children" : [ ] } ] }
+}`
+
+func TestRenderPlanDiagram(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    string
+		diagram string
+		want    string
+	}{
+		{"Distributed Mermaid", distributedPlan, DiagramMermaid, `flowchart BT
+  subgraph fragment0["Fragment 0"]
+    n9["Output<br/>rows: 5"]
+    n145["RemoteSource"]
+  end
+  subgraph fragment1["Fragment 1"]
+    n4["Aggregate<br/>type: FINAL<br/>rows: 1.5M"]
+    n0["TableScan<br/>table: tpch:tiny:#quot;customer#quot;<br/>rows: 1.5K"]
+  end
+  n145 --> n9
+  n0 --> n4
+  n4 -. exchange .-> n145`},
+		{"Distributed DOT", distributedPlan, DiagramDOT, `digraph plan {
+  rankdir=BT;
+  node [shape=box, fontname="Helvetica"];
+  subgraph cluster_0 {
+    label="Fragment 0";
+    style=dashed;
+    n9 [label="Output\nrows: 5"];
+    n145 [label="RemoteSource"];
+  }
+  subgraph cluster_1 {
+    label="Fragment 1";
+    style=dashed;
+    n4 [label="Aggregate\ntype: FINAL\nrows: 1.5M"];
+    n0 [label="TableScan\ntable: tpch:tiny:\"customer\"\nrows: 1.5K"];
+  }
+  n145 -> n9;
+  n0 -> n4;
+  n4 -> n145 [style=dashed, label="exchange"];
+}`},
+		{"Logical plan", `{"id" : "3", "name" : "Output", "children" : [ {"id" : "1", "name" : "Values", "estimates" : [ {"outputRowCount" : 2.0} ] } ]}`, DiagramMermaid, `flowchart BT
+  n3["Output"]
+  n1["Values<br/>rows: 2"]
+  n1 --> n3`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderPlanDiagram(tt.plan, tt.diagram)
+			if err != nil {
+				t.Fatalf("renderPlanDiagram() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderPlanDiagram() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := renderPlanDiagram("Fragment 0 [SINGLE]", DiagramMermaid); err == nil || !strings.Contains(err.Error(), "JSON plan") {
+		t.Errorf("renderPlanDiagram() of a text plan error = %v, want a parse error", err)
+	}
+}
+
+func TestFormatRowCount(t *testing.T) {
+	for rows, want := range map[float64]string{0: "0", 999.4: "999", 1500: "1.5K", 2_000_000: "2M", 3.25e9: "3.3B"} {
+		if got := formatRowCount(rows); got != want {
+			t.Errorf("formatRowCount(%v) = %q, want %q", rows, got, want)
+		}
+	}
+}
+
+func TestExplainDiagramChecksQuery(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	client := newMockClient(t, server, "mock-diagram", func(cfg *config.TrinoConfig) {
+		cfg.AllowedCatalogs = []string{"hive"}
+		cfg.AllowedSchemas = []string{"hive.sales"}
+		cfg.AllowedTables = []string{"hive.sales.orders"}
+	})
+	for _, diagram := range []string{DiagramMermaid, DiagramDOT} {
+		_, err := client.ExplainDiagramWithContext(context.Background(), "SELECT id FROM hive.secret.users", "", diagram)
+		if err == nil || !strings.Contains(err.Error(), "schema access denied: hive.secret not in allowlist") {
+			t.Errorf("ExplainDiagramWithContext(%s) error = %v, want the allowlist rejection", diagram, err)
+		}
+	}
+	if requests := server.Requests(); len(requests) != 0 {
+		t.Errorf("requests = %+v, want the query rejected before reaching Trino", requests)
+	}
+}
@@ -0,0 +1,109 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// Sampling methods of PreviewTableWithContext
+const (
+	SampleBernoulli = "bernoulli" // Row-level sampling, supported by every connector
+	SampleSystem    = "system"    // Split-level sampling; cheaper but connector dependent
+	SampleNone      = "none"      // First rows in table order
+)
+
+// Preview defaults and bounds
+const (
+	DefaultPreviewRows   = 10
+	MaxPreviewRows       = 100
+	DefaultSamplePercent = 1.0
+)
+
+// TablePreview holds sample rows of a table
+type TablePreview struct {
+	Table    string `json:"table"`    // Fully qualified table name
+	Sampling string `json:"sampling"` // Sampling method used; none when sampling was skipped or unsupported
+	*QueryResult
+}
+
+// PreviewTableWithContext returns up to rows sample rows of a table. Rows are
+// sampled with TABLESAMPLE unless method is SampleNone; when the connector
+// does not support the method, or the sample is smaller than requested (as on
+// small tables), the first rows of the table are returned instead. Allowlists,
+// column masks and TRINO_MAX_RESULT_ROWS / TRINO_MAX_RESULT_BYTES apply.
+func (c *Client) PreviewTableWithContext(ctx context.Context, catalog, schema, table string, rows int, method string, percent float64) (*TablePreview, error) {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	if err := c.checkTableAccess(catalog, schema, table); err != nil {
+		return nil, err
+	}
+
+	if rows <= 0 {
+		rows = DefaultPreviewRows
+	}
+	if rows > MaxPreviewRows {
+		rows = MaxPreviewRows
+	}
+	policy := c.currentPolicy()
+	if policy.MaxResultRows > 0 && rows > policy.MaxResultRows {
+		rows = policy.MaxResultRows
+	}
+	if method == "" {
+		method = SampleBernoulli
+	}
+	if method != SampleBernoulli && method != SampleSystem && method != SampleNone {
+		return nil, fmt.Errorf("invalid sampling method '%s': must be bernoulli, system or none", method)
+	}
+	if percent <= 0 {
+		percent = DefaultSamplePercent
+	}
+	if percent > 100 {
+		return nil, fmt.Errorf("invalid sample percentage %g: must be between 0 and 100", percent)
+	}
+
+	name := quoteIdentifier(catalog) + "." + quoteIdentifier(schema) + "." + quoteIdentifier(table)
+	opts := queryOptions{maxRows: rows, maxBytes: policy.MaxResultBytes}
+	preview := &TablePreview{Table: catalog + "." + schema + "." + table, Sampling: method}
+
+	if method != SampleNone {
+		query := fmt.Sprintf("SELECT * FROM %s TABLESAMPLE %s (%s) LIMIT %d",
+			name, strings.ToUpper(method), strconv.FormatFloat(percent, 'f', -1, 64), rows)
+		result, err := c.executeQueryWithRetry(ctx, query, opts, false)
+		switch {
+		case err == nil && (result.RowCount >= rows || result.Truncated):
+			preview.QueryResult = result
+			return preview, nil
+		case err != nil && !isSamplingUnsupported(err):
+			return nil, err
+		case err != nil:
+			log.Printf("INFO: TABLESAMPLE %s not supported for %s, previewing without sampling: %v", method, preview.Table, err)
+		}
+		preview.Sampling = SampleNone
+	}
+
+	result, err := c.executeQueryWithRetry(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT %d", name, rows), opts, false)
+	if err != nil {
+		return nil, err
+	}
+	preview.QueryResult = result
+	return preview, nil
+}
+
+// isSamplingUnsupported reports whether Trino rejected a TABLESAMPLE clause
+func isSamplingUnsupported(err error) bool {
+	var trinoErr *trino.ErrTrino
+	if !errors.As(err, &trinoErr) {
+		return false
+	}
+	return trinoErr.ErrorName == "NOT_SUPPORTED" || strings.Contains(strings.ToUpper(trinoErr.Message), "TABLESAMPLE")
+}
+
+// quoteIdentifier quotes a SQL identifier, escaping embedded quotes
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,107 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/trinodb/trino-go-client/trino"
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestCheckTableAccess(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			AllowedCatalogs: []string{"hive"},
+			AllowedSchemas:  []string{"hive.analytics"},
+			AllowedTables:   []string{"hive.analytics.users"},
+		},
+	}
+
+	tests := []struct {
+		catalog, schema, table string
+		expectError            string
+	}{
+		{"hive", "analytics", "users", ""},
+		{"HIVE", "Analytics", "Users", ""},
+		{"postgresql", "analytics", "users", "catalog access denied"},
+		{"hive", "raw", "users", "schema access denied"},
+		{"hive", "analytics", "orders", "table access denied"},
+	}
+
+	for _, tt := range tests {
+		err := client.checkTableAccess(tt.catalog, tt.schema, tt.table)
+		if tt.expectError == "" {
+			if err != nil {
+				t.Errorf("checkTableAccess(%s.%s.%s) error = %v", tt.catalog, tt.schema, tt.table, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+			t.Errorf("checkTableAccess(%s.%s.%s) error = %v, want %q", tt.catalog, tt.schema, tt.table, err, tt.expectError)
+		}
+	}
+}
+
+func TestPreviewTableValidation(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			Catalog:       "hive",
+			Schema:        "default",
+			AllowedTables: []string{"hive.analytics.users"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		table       string
+		method      string
+		percent     float64
+		expectError string
+	}{
+		{"Table outside allowlist", "analytics.orders", "", 0, "table access denied: hive.analytics.orders"},
+		{"Unknown sampling method", "analytics.users", "reservoir", 0, "invalid sampling method"},
+		{"Percentage above 100", "analytics.users", SampleSystem, 150, "invalid sample percentage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.PreviewTableWithContext(context.Background(), "", "", tt.table, 10, tt.method, tt.percent)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("PreviewTableWithContext() error = %v, want %q", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestIsSamplingUnsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Not supported", fmt.Errorf("query execution failed: %w", &trino.ErrQueryFailed{
+			Reason: &trino.ErrTrino{ErrorName: "NOT_SUPPORTED", Message: "Sampling is not supported"}}), true},
+		{"Tablesample error", &trino.ErrQueryFailed{
+			Reason: &trino.ErrTrino{ErrorName: "GENERIC_USER_ERROR", Message: "TABLESAMPLE SYSTEM is not supported by this connector"}}, true},
+		{"Missing table", &trino.ErrQueryFailed{
+			Reason: &trino.ErrTrino{ErrorName: "TABLE_NOT_FOUND", Message: "Table 'hive.default.nope' does not exist"}}, false},
+		{"Connection error", errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSamplingUnsupported(tt.err); got != tt.expected {
+				t.Errorf("isSamplingUnsupported() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	if got := quoteIdentifier(`odd"name`); got != `"odd""name"` {
+		t.Errorf("quoteIdentifier() = %s, want \"odd\"\"name\"", got)
+	}
+}
@@ -0,0 +1,113 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ProcedureArgument is an argument of a CALL. Value is a string, number,
+// boolean or nil; with Type set, Value must be a string and is cast to that
+// type, which passes values JSON cannot carry exactly, such as 64-bit IDs.
+type ProcedureArgument struct {
+	Name  string      // Passes the argument by name; leave empty for positional arguments
+	Type  string      // Optional Trino type to cast a string Value to, e.g. bigint
+	Value interface{} // String, float64, bool or nil
+}
+
+// procedureArgumentName matches the argument names accepted unquoted
+var procedureArgumentName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// procedureArgumentType matches the types arguments may be cast to, such as
+// bigint, decimal(20, 0) or timestamp(3) with time zone
+var procedureArgumentType = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_ ]*(\(\s*\d+\s*(,\s*\d+\s*)?\))?[A-Za-z ]*$`)
+
+// CallProcedureWithContext runs a connector procedure with CALL and returns
+// the statement it ran. procedure is procedure, schema.procedure or
+// catalog.schema.procedure, defaulting to the system schema of the default
+// catalog. Only procedures in TRINO_ALLOWED_PROCEDURES can be called; they
+// run even when write queries are not allowed, and the catalog allowlist applies.
+func (c *Client) CallProcedureWithContext(ctx context.Context, procedure string, args []ProcedureArgument) (string, error) {
+	catalog, schema, name := c.config.Catalog, "system", procedure
+	parts := strings.Split(procedure, ".")
+	switch len(parts) {
+	case 1:
+	case 2:
+		schema, name = parts[0], parts[1]
+	case 3:
+		catalog, schema, name = parts[0], parts[1], parts[2]
+	default:
+		return "", fmt.Errorf("invalid procedure '%s': use procedure, schema.procedure or catalog.schema.procedure", procedure)
+	}
+	if catalog == "" || schema == "" || name == "" {
+		return "", fmt.Errorf("invalid procedure '%s': use procedure, schema.procedure or catalog.schema.procedure", procedure)
+	}
+	if err := c.checkProcedureAccess(catalog, schema, name); err != nil {
+		return "", err
+	}
+
+	values := make([]string, len(args))
+	for i, arg := range args {
+		value, err := procedureLiteral(arg)
+		if err != nil {
+			return "", err
+		}
+		if arg.Name != "" {
+			if !procedureArgumentName.MatchString(arg.Name) {
+				return "", fmt.Errorf("invalid argument name '%s'", arg.Name)
+			}
+			value = arg.Name + " => " + value
+		}
+		values[i] = value
+	}
+	statement := fmt.Sprintf("CALL %s.%s.%s(%s)", quoteIdentifier(catalog), quoteIdentifier(schema), quoteIdentifier(name), strings.Join(values, ", "))
+	if _, err := c.executeQueryWithRetry(ctx, statement, queryOptions{procedure: true}, false); err != nil {
+		return "", err
+	}
+	return statement, nil
+}
+
+// checkProcedureAccess returns a permission error unless the procedure is in
+// the procedure allowlist and its catalog in the catalog allowlist
+func (c *Client) checkProcedureAccess(catalog, schema, name string) error {
+	policy := c.currentPolicy()
+	if len(policy.AllowedCatalogs) > 0 && !c.isCatalogAllowed(catalog) {
+		return accessDenied("catalog access denied: %s not in allowlist", catalog)
+	}
+	qualified := strings.ToLower(schema + "." + name)
+	for _, allowed := range policy.AllowedProcedures {
+		if allowed == qualified || allowed == strings.ToLower(catalog)+"."+qualified {
+			return nil
+		}
+	}
+	return policyError(ErrorPermissionDenied, "Ask an administrator to add the procedure to TRINO_ALLOWED_PROCEDURES",
+		"procedure access denied: %s.%s.%s not in TRINO_ALLOWED_PROCEDURES", catalog, schema, name)
+}
+
+// procedureLiteral renders an argument value as a SQL literal
+func procedureLiteral(arg ProcedureArgument) (string, error) {
+	if arg.Type != "" {
+		s, ok := arg.Value.(string)
+		if !ok {
+			return "", fmt.Errorf("argument with type %s must have a string value", arg.Type)
+		}
+		if !procedureArgumentType.MatchString(arg.Type) {
+			return "", fmt.Errorf("invalid argument type '%s'", arg.Type)
+		}
+		return fmt.Sprintf("CAST(%s AS %s)", quoteLiteral(s), arg.Type), nil
+	}
+	switch v := arg.Value.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteLiteral(v), nil
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v)), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported argument value %v: use a string, number, boolean or null", v)
+	}
+}
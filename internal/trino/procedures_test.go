@@ -0,0 +1,70 @@
+package trino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestCheckProcedureAccess(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		AllowedCatalogs:   []string{"hive", "iceberg"},
+		AllowedProcedures: []string{"system.sync_partition_metadata", "iceberg.system.rollback_to_snapshot"},
+	}}
+
+	tests := []struct {
+		catalog, schema, name string
+		allowed               bool
+	}{
+		{"hive", "system", "sync_partition_metadata", true},
+		{"Iceberg", "System", "Sync_Partition_Metadata", true}, // schema.procedure entries match any catalog
+		{"iceberg", "system", "rollback_to_snapshot", true},
+		{"hive", "system", "rollback_to_snapshot", false}, // allowlisted in another catalog only
+		{"hive", "system", "drop_stats", false},
+		{"postgresql", "system", "sync_partition_metadata", false}, // catalog not in allowlist
+	}
+	for _, tt := range tests {
+		err := client.checkProcedureAccess(tt.catalog, tt.schema, tt.name)
+		if (err == nil) != tt.allowed {
+			t.Errorf("checkProcedureAccess(%s.%s.%s) error = %v, want allowed %v", tt.catalog, tt.schema, tt.name, err, tt.allowed)
+		}
+	}
+}
+
+func TestCallProcedureRejectsUnlistedProcedures(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{Catalog: "hive"}}
+
+	_, err := client.CallProcedureWithContext(context.Background(), "sync_partition_metadata", nil)
+	if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorPermissionDenied {
+		t.Errorf("CallProcedureWithContext() error = %v, want %s", err, ErrorPermissionDenied)
+	}
+	if _, err := client.CallProcedureWithContext(context.Background(), "a.b.c.d", nil); err == nil {
+		t.Error("CallProcedureWithContext() with a four-part name expected error")
+	}
+}
+
+func TestProcedureLiteral(t *testing.T) {
+	tests := []struct {
+		arg     ProcedureArgument
+		want    string
+		wantErr bool
+	}{
+		{arg: ProcedureArgument{Value: "it's"}, want: "'it''s'"},
+		{arg: ProcedureArgument{Value: float64(42)}, want: "42"},
+		{arg: ProcedureArgument{Value: 1.5}, want: "1.5"},
+		{arg: ProcedureArgument{Value: true}, want: "TRUE"},
+		{arg: ProcedureArgument{Value: nil}, want: "NULL"},
+		{arg: ProcedureArgument{Type: "bigint", Value: "8954597067493422955"}, want: "CAST('8954597067493422955' AS bigint)"},
+		{arg: ProcedureArgument{Type: "timestamp(3) with time zone", Value: "2024-01-31 00:00:00 UTC"}, want: "CAST('2024-01-31 00:00:00 UTC' AS timestamp(3) with time zone)"},
+		{arg: ProcedureArgument{Type: "bigint) , (1", Value: "1"}, wantErr: true},
+		{arg: ProcedureArgument{Type: "bigint", Value: float64(1)}, wantErr: true},
+		{arg: ProcedureArgument{Value: []interface{}{"a"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := procedureLiteral(tt.arg)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("procedureLiteral(%+v) = %q, %v; want %q, error %v", tt.arg, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
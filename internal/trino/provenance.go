@@ -0,0 +1,107 @@
+package trino
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// serverVersionTimeout bounds the /v1/info request made to learn the Trino
+// version of a coordinator for provenance
+const serverVersionTimeout = 2 * time.Second
+
+// Provenance records where a query result came from, so that consumers and
+// auditors can trace the numbers back to the cluster, tables and query that
+// produced them. It is attached to tool results as MCP _meta.
+type Provenance struct {
+	Cluster          string         `json:"cluster"`
+	Server           string         `json:"server"`                 // Coordinator host and port
+	TrinoVersion     string         `json:"trinoVersion,omitempty"` // Known once the coordinator's /v1/info was read
+	User             string         `json:"user,omitempty"`         // Trino user the query ran as
+	QueryID          string         `json:"queryId,omitempty"`
+	InfoURI          string         `json:"infoUri,omitempty"`
+	QuerySHA256      string         `json:"querySha256"` // Digest of the SQL text as run
+	Tables           []TableVersion `json:"tables,omitempty"`
+	StartedAt        time.Time      `json:"startedAt"`
+	ElapsedMillis    int64          `json:"elapsedMillis"`
+	RowCount         int            `json:"rowCount"`
+	Truncated        bool           `json:"truncated"`
+	TruncationReason string         `json:"truncationReason,omitempty"`
+	ResultSHA256     string         `json:"resultSha256,omitempty"` // Digest of the rendered result, when the result is returned inline
+}
+
+// TableVersion is a table a query references, with the version it read when
+// the query pinned one with FOR VERSION AS OF or FOR TIMESTAMP AS OF
+type TableVersion struct {
+	Table   string `json:"table"`
+	Version string `json:"version,omitempty"` // e.g. VERSION AS OF 42
+}
+
+// Provenance describes a result of query, which started at started, read
+// from the cluster
+func (c *Cluster) Provenance(ctx context.Context, query string, result *QueryResult, started time.Time) *Provenance {
+	p := &Provenance{
+		Cluster:       c.Name,
+		Server:        fmt.Sprintf("%s:%d", c.Config.Host, c.Config.Port),
+		TrinoVersion:  c.Client.ServerVersion(ctx),
+		User:          c.Config.User,
+		QuerySHA256:   fmt.Sprintf("%x", sha256.Sum256([]byte(query))),
+		Tables:        tableVersions(query, c.Config.Catalog, c.Config.DefaultSchema(c.Config.Catalog)),
+		StartedAt:     started.UTC(),
+		ElapsedMillis: time.Since(started).Milliseconds(),
+	}
+	if user, ok := GetImpersonatedUser(ctx); ok && user != "" && c.Config.EnableImpersonation {
+		p.User = user
+	}
+	if result != nil {
+		p.QueryID = result.QueryID
+		p.InfoURI = result.InfoURI
+		p.RowCount = result.RowCount
+		p.Truncated = result.Truncated
+		p.TruncationReason = result.TruncationReason
+	}
+	return p
+}
+
+// ServerVersion returns the Trino version of the coordinator, or "" when it
+// is not known. The version is read from /v1/info by health checks; without
+// them it is read once, on first use.
+func (c *Client) ServerVersion(ctx context.Context) string {
+	if version := c.version.Load(); version != nil {
+		return *version
+	}
+	if c.httpClient == nil || c.versionRead.Swap(true) {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, serverVersionTimeout)
+	defer cancel()
+	_ = c.CheckHealth(ctx)
+	if version := c.version.Load(); version != nil {
+		return *version
+	}
+	return ""
+}
+
+// tableVersions returns the tables a statement references, in order, with
+// the version clause that follows each
+func tableVersions(query, defaultCatalog, defaultSchema string) []TableVersion {
+	tokens := tokenizeSQL(query)
+	seen := make(map[string]bool)
+	var tables []TableVersion
+	for _, name := range tableNames(tokens) {
+		if name.cte {
+			continue
+		}
+		table := TableVersion{Table: resolveTable(name.parts, defaultCatalog, defaultSchema).String()}
+		if end := skipQueryPeriod(tokens, name.nameEnd); end > name.nameEnd {
+			table.Version = strings.Join(strings.Fields(query[tokens[name.nameEnd].end:tokens[end-1].end]), " ")
+		}
+		if key := table.Table + " " + table.Version; !seen[key] {
+			seen[key] = true
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
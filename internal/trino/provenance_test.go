@@ -0,0 +1,99 @@
+package trino
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestTableVersions(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []TableVersion
+	}{
+		{"Unqualified", "SELECT * FROM orders", []TableVersion{{Table: "hive.sales.orders"}}},
+		{
+			"Snapshot",
+			"SELECT * FROM iceberg.marts.revenue FOR VERSION AS OF 8954597067493422955 r",
+			[]TableVersion{{Table: "iceberg.marts.revenue", Version: "VERSION AS OF 8954597067493422955"}},
+		},
+		{
+			"Timestamp",
+			"SELECT * FROM iceberg.marts.revenue FOR TIMESTAMP AS OF TIMESTAMP '2024-01-31 12:00:00 UTC'",
+			[]TableVersion{{Table: "iceberg.marts.revenue", Version: "TIMESTAMP AS OF TIMESTAMP '2024-01-31 12:00:00 UTC'"}},
+		},
+		{
+			"Join and CTE",
+			"WITH recent AS (SELECT * FROM orders) SELECT * FROM recent JOIN customers c ON recent.customer_id = c.id JOIN orders o ON o.id = recent.id",
+			[]TableVersion{{Table: "hive.sales.orders"}, {Table: "hive.sales.customers"}},
+		},
+		{"No tables", "SELECT 1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tableVersions(tt.query, "hive", "sales"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tableVersions(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	infoRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		infoRequests++
+		_, _ = w.Write([]byte(`{"nodeVersion":{"version":"476"},"starting":false}`))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+	cfg := &config.TrinoConfig{Scheme: "http", Host: serverURL.Hostname(), Port: port, User: "mcp", Catalog: "hive", Schema: "sales"}
+	cluster := &Cluster{Name: "analytics", Config: cfg, Client: &Client{config: cfg, httpClient: server.Client()}}
+
+	result := &QueryResult{QueryID: "20240131_120000_00001_abcde", RowCount: 100, Truncated: true, TruncationReason: "max rows"}
+	started := time.Now().Add(-time.Second)
+	p := cluster.Provenance(context.Background(), "SELECT * FROM orders", result, started)
+
+	if p.Cluster != "analytics" || p.Server != serverURL.Host || p.User != "mcp" {
+		t.Errorf("Provenance() cluster = %q, server = %q, user = %q", p.Cluster, p.Server, p.User)
+	}
+	if p.TrinoVersion != "476" {
+		t.Errorf("Provenance() trinoVersion = %q, want 476", p.TrinoVersion)
+	}
+	if p.QueryID != result.QueryID || p.RowCount != 100 || !p.Truncated || p.TruncationReason != "max rows" {
+		t.Errorf("Provenance() = %+v, want the result's query ID and truncation", p)
+	}
+	if want := fmt.Sprintf("%x", sha256.Sum256([]byte("SELECT * FROM orders"))); p.QuerySHA256 != want {
+		t.Errorf("Provenance() querySha256 = %q, want %q", p.QuerySHA256, want)
+	}
+	if !reflect.DeepEqual(p.Tables, []TableVersion{{Table: "hive.sales.orders"}}) {
+		t.Errorf("Provenance() tables = %+v", p.Tables)
+	}
+	if !p.StartedAt.Equal(started) || p.ElapsedMillis < 1000 {
+		t.Errorf("Provenance() startedAt = %v, elapsedMillis = %d", p.StartedAt, p.ElapsedMillis)
+	}
+
+	// The version is read once and then remembered
+	cluster.Provenance(context.Background(), "SELECT 1", nil, time.Now())
+	if infoRequests != 1 {
+		t.Errorf("/v1/info requests = %d, want 1", infoRequests)
+	}
+
+	// Impersonated users are reported when impersonation is on
+	cfg.EnableImpersonation = true
+	ctx := WithImpersonatedUser(context.Background(), "alice")
+	if p := cluster.Provenance(ctx, "SELECT 1", nil, time.Now()); p.User != "alice" {
+		t.Errorf("Provenance() user = %q, want alice", p.User)
+	}
+}
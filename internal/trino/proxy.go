@@ -0,0 +1,27 @@
+package trino
+
+import (
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// proxyFunc sends requests through proxyURL, except to the hosts in NO_PROXY,
+// which connect directly
+func proxyFunc(proxyURL string) func(*http.Request) (*url.URL, error) {
+	cfg := httpproxy.FromEnvironment()
+	cfg.HTTPProxy, cfg.HTTPSProxy = proxyURL, proxyURL
+	proxy := cfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// useProxy sends the authenticator's requests, including those to the
+// identity provider, through the Trino connection proxy
+func (a *ExternalAuthenticator) useProxy(proxyURL string) {
+	if transport, ok := a.httpClient.Transport.(*http.Transport); ok {
+		transport.Proxy = proxyFunc(proxyURL)
+	}
+}
@@ -0,0 +1,43 @@
+package trino
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProxyFunc(t *testing.T) {
+	t.Setenv("NO_PROXY", "trino.internal")
+	proxy := proxyFunc("http://proxy.example.com:3128")
+
+	req, _ := http.NewRequest(http.MethodGet, "https://trino.example.com/v1/statement", nil)
+	if u, err := proxy(req); err != nil || u == nil || u.Host != "proxy.example.com:3128" {
+		t.Errorf("proxy for trino.example.com = %v, %v; want the configured proxy", u, err)
+	}
+	req, _ = http.NewRequest(http.MethodGet, "https://trino.internal/v1/statement", nil)
+	if u, err := proxy(req); err != nil || u != nil {
+		t.Errorf("proxy for a NO_PROXY host = %v, %v; want a direct connection", u, err)
+	}
+}
+
+func TestExternalAuthenticatorUsesProxy(t *testing.T) {
+	t.Setenv("NO_PROXY", "")
+	var proxied string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute URL of the target
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxyServer.Close()
+
+	auth := NewExternalAuthenticator("http://trino.example.com", "testuser", 5, false)
+	auth.useProxy(proxyServer.URL)
+	resp, err := auth.httpClient.Get("http://trino.example.com/v1/info")
+	if err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if proxied != "http://trino.example.com/v1/info" {
+		t.Errorf("proxy received %q, want the Trino URL", proxied)
+	}
+}
@@ -0,0 +1,124 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultFindQueryLimit is the number of queries FindQueriesWithContext returns by default
+	DefaultFindQueryLimit = 10
+	// MaxFindQueryLimit bounds the limit argument of FindQueriesWithContext
+	MaxFindQueryLimit = 50
+
+	// maxQueryTextLength bounds the query text returned per query
+	maxQueryTextLength = 2000
+
+	// findQueryMarker appears in the search query itself, so that it and
+	// earlier searches are not reported as matches of the text fragment
+	findQueryMarker = "mcp-trino:find_query"
+)
+
+// QueryStatus is a query known to the coordinator, from system.runtime.queries
+type QueryStatus struct {
+	QueryID        string `json:"queryId"`
+	State          string `json:"state"`
+	User           string `json:"user"`
+	Source         string `json:"source,omitempty"`
+	ResourceGroup  string `json:"resourceGroup,omitempty"`
+	Query          string `json:"query"`
+	QueryTruncated bool   `json:"queryTruncated,omitempty"`
+	ErrorType      string `json:"errorType,omitempty"` // USER_ERROR, INTERNAL_ERROR, INSUFFICIENT_RESOURCES or EXTERNAL
+	ErrorCode      string `json:"errorCode,omitempty"` // e.g. EXCEEDED_TIME_LIMIT
+	Created        string `json:"created,omitempty"`
+	Started        string `json:"started,omitempty"`
+	Ended          string `json:"ended,omitempty"`
+	QueuedTimeMs   *int64 `json:"queuedTimeMs,omitempty"`
+	PlanningTimeMs *int64 `json:"planningTimeMs,omitempty"`
+	InfoURI        string `json:"infoUri"` // Query page of the Trino UI
+}
+
+// FindQueriesWithContext looks up a query by ID, or searches the text of
+// recent queries for a fragment (case-insensitive), newest first. The
+// coordinator only keeps recent queries (query.max-history), and Trino access
+// control decides whose queries are visible.
+func (c *Client) FindQueriesWithContext(ctx context.Context, queryID, text string, limit int) ([]QueryStatus, error) {
+	queryID, text = strings.TrimSpace(queryID), strings.TrimSpace(text)
+	if (queryID == "") == (text == "") {
+		return nil, fmt.Errorf("specify either a query ID or a text fragment")
+	}
+	if limit <= 0 {
+		limit = DefaultFindQueryLimit
+	}
+	if limit > MaxFindQueryLimit {
+		limit = MaxFindQueryLimit
+	}
+
+	var where string
+	var params []interface{}
+	if queryID != "" {
+		if !queryIDPattern.MatchString(queryID) {
+			return nil, fmt.Errorf("invalid query ID '%s'", queryID)
+		}
+		where = "query_id = ?"
+		params = append(params, queryID)
+	} else {
+		where = fmt.Sprintf("strpos(lower(query), lower(?)) > 0 AND strpos(query, '%s') = 0", findQueryMarker)
+		params = append(params, text)
+	}
+	query := fmt.Sprintf(`SELECT query_id, state, "user", source, array_join(resource_group_id, '.') AS resource_group, query,
+  error_type, error_code, CAST(created AS varchar) AS created_at, CAST(started AS varchar) AS started_at,
+  CAST("end" AS varchar) AS ended_at, queued_time_ms, planning_time_ms
+FROM system.runtime.queries
+WHERE %s
+ORDER BY created DESC LIMIT %d`, where, limit)
+	result, err := c.executeQueryWithRetry(ctx, query, queryOptions{params: params}, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read system.runtime.queries: %w", err)
+	}
+
+	queries := make([]QueryStatus, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		status := QueryStatus{
+			QueryID:        stringValue(row["query_id"]),
+			State:          stringValue(row["state"]),
+			User:           stringValue(row["user"]),
+			Source:         stringValue(row["source"]),
+			ResourceGroup:  stringValue(row["resource_group"]),
+			Query:          stringValue(row["query"]),
+			ErrorType:      stringValue(row["error_type"]),
+			ErrorCode:      stringValue(row["error_code"]),
+			Created:        stringValue(row["created_at"]),
+			Started:        stringValue(row["started_at"]),
+			Ended:          stringValue(row["ended_at"]),
+			QueuedTimeMs:   optionalInt64(row["queued_time_ms"]),
+			PlanningTimeMs: optionalInt64(row["planning_time_ms"]),
+		}
+		status.Query, status.QueryTruncated = truncateText(status.Query, maxQueryTextLength)
+		status.InfoURI = c.queryInfoURI(status.QueryID)
+		queries = append(queries, status)
+	}
+	if queryID != "" && len(queries) == 0 {
+		return nil, fmt.Errorf("query %s not found: it may have left the coordinator's query history or belong to another user", queryID)
+	}
+	return queries, nil
+}
+
+// queryInfoURI returns the Trino UI page of a query on this client's coordinator
+func (c *Client) queryInfoURI(queryID string) string {
+	return fmt.Sprintf("%s://%s:%d/ui/query.html?%s", c.config.Scheme, c.config.Host, c.config.Port, queryID)
+}
+
+// truncateText shortens text to at most max bytes without splitting a character
+func truncateText(text string, max int) (string, bool) {
+	if len(text) <= max {
+		return text, false
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut], true
+}
@@ -0,0 +1,50 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestFindQueriesValidation(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{}}
+
+	tests := []struct {
+		name    string
+		queryID string
+		text    string
+		wantErr string
+	}{
+		{"Neither", "", " ", "specify either a query ID or a text fragment"},
+		{"Both", "20250101_120000_00042_abcde", "orders", "specify either a query ID or a text fragment"},
+		{"Invalid query ID", "20250101' OR '1'='1", "", "invalid query ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.FindQueriesWithContext(context.Background(), tt.queryID, tt.text, 0)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FindQueriesWithContext() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryInfoURI(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{Scheme: "https", Host: "trino.example.com", Port: 443}}
+	want := "https://trino.example.com:443/ui/query.html?20250101_120000_00042_abcde"
+	if got := client.queryInfoURI("20250101_120000_00042_abcde"); got != want {
+		t.Errorf("queryInfoURI() = %q, want %q", got, want)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	if got, truncated := truncateText("SELECT 1", 20); got != "SELECT 1" || truncated {
+		t.Errorf("truncateText() = %q, %v; want the text unchanged", got, truncated)
+	}
+	if got, truncated := truncateText("SELECT 'héllo'", 10); got != "SELECT 'h" || !truncated {
+		t.Errorf("truncateText() = %q, %v; want a cut before the multi-byte character", got, truncated)
+	}
+}
@@ -0,0 +1,398 @@
+package trino
+
+// TableRef is a fully qualified table referenced by a query
+type TableRef struct {
+	Catalog string `json:"catalog"`
+	Schema  string `json:"schema"`
+	Table   string `json:"table"`
+}
+
+// String returns the table as catalog.schema.table
+func (t TableRef) String() string {
+	return t.Catalog + "." + t.Schema + "." + t.Table
+}
+
+// QueryMetadata describes a statement without executing it
+type QueryMetadata struct {
+	StatementType string     `json:"statementType"` // Leading keyword, e.g. select, insert, show; WITH queries report select
+	ReadOnly      bool       `json:"readOnly"`
+	Tables        []TableRef `json:"tables"` // Tables the statement reads or writes, with default catalog/schema applied
+}
+
+// AnalyzeQuery extracts the statement type and referenced tables of a query.
+// It is a lexical analysis, not a full SQL parser: table functions and
+// tables referenced only through views are not reported.
+func AnalyzeQuery(query, defaultCatalog, defaultSchema string) *QueryMetadata {
+	tokens := tokenizeSQL(query)
+	metadata := &QueryMetadata{
+		StatementType: statementType(tokens),
+		ReadOnly:      isReadOnlyQuery(query),
+		Tables:        []TableRef{},
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range tableNames(tokens) {
+		if name.cte {
+			continue
+		}
+		ref := resolveTable(name.parts, defaultCatalog, defaultSchema)
+		if key := ref.String(); !seen[key] {
+			seen[key] = true
+			metadata.Tables = append(metadata.Tables, ref)
+		}
+	}
+	return metadata
+}
+
+// resolveTable qualifies a table name of one to three parts with the defaults
+func resolveTable(parts []string, defaultCatalog, defaultSchema string) TableRef {
+	ref := TableRef{Catalog: defaultCatalog, Schema: defaultSchema, Table: parts[len(parts)-1]}
+	if len(parts) >= 2 {
+		ref.Schema = parts[len(parts)-2]
+	}
+	if len(parts) == 3 {
+		ref.Catalog = parts[0]
+	}
+	return ref
+}
+
+// statementType returns the leading keyword of a statement
+func statementType(tokens []sqlToken) string {
+	for _, token := range tokens {
+		if token.text == "(" {
+			continue
+		}
+		if !token.ident || token.quoted {
+			return ""
+		}
+		if token.text == "with" {
+			return "select"
+		}
+		return token.text
+	}
+	return ""
+}
+
+// cteNames returns the names defined in WITH clauses
+func cteNames(tokens []sqlToken) map[string]bool {
+	names := make(map[string]bool)
+	for _, scope := range cteScopes(tokens) {
+		names[scope.name] = true
+	}
+	return names
+}
+
+// cteScope is where a name defined in a WITH clause refers to the CTE: from
+// the end of its definition (from the name itself with WITH RECURSIVE) to the
+// end of the query the WITH clause belongs to. Elsewhere the name is a table.
+type cteScope struct {
+	name       string
+	start, end int // Token indexes
+}
+
+// cteScopes returns the names defined in WITH clauses with their scopes
+func cteScopes(tokens []sqlToken) []cteScope {
+	var scopes []cteScope
+	depth := 0
+	recursive := make(map[int]bool) // Per parenthesis depth, whether its WITH clause is recursive
+	for i := 1; i < len(tokens); i++ {
+		prev := tokens[i-1]
+		switch prev.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if prev.keyword("with") {
+			recursive[depth] = tokens[i].keyword("recursive")
+		}
+		if !tokens[i].ident || !(prev.keyword("with") || prev.keyword("recursive") || prev.text == ",") {
+			continue
+		}
+		next := i + 1
+		if next < len(tokens) && tokens[next].text == "(" {
+			next = skipParens(tokens, next)
+		}
+		if next+1 < len(tokens) && tokens[next].keyword("as") && tokens[next+1].text == "(" {
+			scope := cteScope{name: tokens[i].text, start: skipParens(tokens, next+1), end: groupEnd(tokens, i)}
+			if recursive[depth] {
+				scope.start = i
+			}
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// groupEnd returns the index of the parenthesis closing the group the token
+// at i is in, or the end of the statement
+func groupEnd(tokens []sqlToken, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth < 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// skipParens returns the index after the parenthesis group starting at i
+func skipParens(tokens []sqlToken, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// tableName is a table reference found in a statement
+type tableName struct {
+	parts   []string // One to three name parts as written
+	alias   string
+	write   bool // The statement writes to the table
+	cte     bool // The name refers to a CTE rather than a table
+	start   int  // Index of the first name token
+	nameEnd int  // Index after the name
+	end     int  // Index after the name, its alias and a column list
+}
+
+// tableNames returns the table references of a statement
+func tableNames(tokens []sqlToken) []tableName {
+	var names []tableName
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// Statements naming a single table outside of FROM
+	switch {
+	case tokens[0].keyword("describe"):
+		if parts, end := qualifiedName(tokens, 1); parts != nil {
+			names = append(names, tableName{parts: parts, start: 1, nameEnd: end, end: end})
+		}
+		return names
+	case tokens[0].keyword("show"):
+		// SHOW STATS FOR (SELECT ...) reads the tables of its query, which
+		// are found like those of any other query
+		if len(tokens) < 4 || !tokens[1].keyword("stats") || !tokens[2].keyword("for") || tokens[3].text != "(" {
+			return showTableNames(tokens)
+		}
+	}
+
+	// Parentheses that open a subquery or wrap relations, as opposed to an
+	// expression such as EXTRACT(year FROM ts) whose FROM names no table.
+	// Within a FROM clause each comma starts another relation.
+	type group struct {
+		scan      bool // The group is a query or relations whose tables are reported
+		relations bool // In a FROM clause
+	}
+	groups := []group{{scan: true}}
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		top := len(groups) - 1
+		prev := sqlToken{}
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		switch token.text {
+		case "(":
+			// FROM (t), JOIN ((a JOIN b ON ...)) and FROM a, (b) wrap relations
+			relation := groups[top].scan && !startsQuery(tokens, i+1) && (prev.keyword("from") || prev.keyword("join") ||
+				(prev.text == "," || prev.text == "(") && groups[top].relations)
+			groups = append(groups, group{
+				scan:      relation || startsQuery(tokens, i+1) || i+1 < len(tokens) && tokens[i+1].text == "(",
+				relations: relation,
+			})
+			continue
+		case ")":
+			if top > 0 {
+				groups = groups[:top]
+			}
+			continue
+		case ",":
+			if groups[top].relations {
+				if name, ok := relationName(tokens, i+1, false); ok {
+					names = append(names, name)
+				}
+			}
+			continue
+		}
+		if !token.ident || token.quoted || !groups[top].scan {
+			continue
+		}
+		switch token.text {
+		case "from", "join":
+			groups[top].relations = true
+			if name, ok := relationName(tokens, i+1, prev.keyword("delete")); ok {
+				names = append(names, name)
+			}
+		case "where", "group", "having", "order", "limit", "offset", "fetch", "window",
+			"union", "intersect", "except", "select", "with":
+			groups[top].relations = false
+		case "into", "update", "using", "table", "view":
+			write := token.text != "using"
+			if token.text == "table" || token.text == "view" {
+				ddl := prev.keyword("create") || prev.keyword("replace") || prev.keyword("drop") ||
+					prev.keyword("alter") || prev.keyword("truncate") || prev.keyword("materialized")
+				if !ddl && (token.text == "view" || i > 0 && prev.text != "(") {
+					continue // A column named table or view
+				}
+				write = ddl // TABLE t is short for SELECT * FROM t
+			}
+			j := i + 1
+			for j < len(tokens) && (tokens[j].keyword("if") || tokens[j].keyword("not") || tokens[j].keyword("exists")) {
+				j++ // CREATE TABLE IF NOT EXISTS, DROP TABLE IF EXISTS
+			}
+			parts, nameEnd := qualifiedName(tokens, j)
+			if parts == nil {
+				continue
+			}
+			alias, end := "", nameEnd
+			if token.text == "into" || token.text == "update" || token.text == "using" {
+				alias, end = skipAlias(tokens, end)
+			}
+			if write && end < len(tokens) && tokens[end].text == "(" {
+				end = skipParens(tokens, end) // INSERT column list or CREATE TABLE column definitions
+			}
+			names = append(names, tableName{parts: parts, alias: alias, write: write, start: j, nameEnd: nameEnd, end: end})
+		}
+	}
+	// Names of the CTEs in scope refer to them rather than to tables
+	for _, scope := range cteScopes(tokens) {
+		for i := range names {
+			if len(names[i].parts) == 1 && names[i].parts[0] == scope.name && names[i].start >= scope.start && names[i].start < scope.end {
+				names[i].cte = true
+			}
+		}
+	}
+	return names
+}
+
+// startsQuery reports whether the token at i starts a query, as in
+// "(SELECT ...)", "(VALUES ...)" or "(TABLE t)"
+func startsQuery(tokens []sqlToken, i int) bool {
+	if i >= len(tokens) {
+		return false
+	}
+	token := tokens[i]
+	return token.keyword("select") || token.keyword("with") || token.keyword("values") || token.keyword("table")
+}
+
+// relationName reads the table a relation starting at i names, which may be
+// wrapped in parentheses as in "FROM (t) x". It reports false for subqueries,
+// keywords and table functions such as unnest(...).
+func relationName(tokens []sqlToken, i int, write bool) (tableName, bool) {
+	open := 0
+	for i < len(tokens) && tokens[i].text == "(" && !startsQuery(tokens, i+1) {
+		i++
+		open++
+	}
+	parts, nameEnd := qualifiedName(tokens, i)
+	if parts == nil || nameEnd < len(tokens) && tokens[nameEnd].text == "(" {
+		return tableName{}, false
+	}
+	alias, end := skipAlias(tokens, skipQueryPeriod(tokens, nameEnd))
+	for ; open > 0 && end < len(tokens) && tokens[end].text == ")"; open-- {
+		if outer, outerEnd := skipAlias(tokens, end+1); outer != "" {
+			alias, end = outer, outerEnd
+		} else {
+			end++
+		}
+	}
+	return tableName{parts: parts, alias: alias, write: write, start: i, nameEnd: nameEnd, end: end}, true
+}
+
+// showTableNames handles SHOW COLUMNS FROM t, SHOW CREATE TABLE|VIEW t and SHOW STATS FOR t
+func showTableNames(tokens []sqlToken) []tableName {
+	if len(tokens) < 3 {
+		return nil
+	}
+	start := 0
+	switch {
+	case tokens[1].keyword("columns") && (tokens[2].keyword("from") || tokens[2].keyword("in")):
+		start = 3
+	case tokens[1].keyword("create") && (tokens[2].keyword("table") || tokens[2].keyword("view")):
+		start = 3
+	case tokens[1].keyword("create") && tokens[2].keyword("materialized") && len(tokens) > 3:
+		start = 4
+	case tokens[1].keyword("stats") && tokens[2].keyword("for"):
+		start = 3
+	default:
+		return nil
+	}
+	if parts, end := qualifiedName(tokens, start); parts != nil {
+		return []tableName{{parts: parts, start: start, nameEnd: end, end: end}}
+	}
+	return nil
+}
+
+// qualifiedName reads a name of up to three dot-separated parts starting at i.
+// It returns nil for subqueries and keywords.
+func qualifiedName(tokens []sqlToken, i int) ([]string, int) {
+	if i >= len(tokens) || !tokens[i].ident || isReservedWord(tokens[i]) {
+		return nil, i
+	}
+	name := []string{tokens[i].text}
+	i++
+	for len(name) < 3 && i+1 < len(tokens) && tokens[i].text == "." && tokens[i+1].ident {
+		name = append(name, tokens[i+1].text)
+		i += 2
+	}
+	return name, i
+}
+
+// skipQueryPeriod skips a FOR VERSION|TIMESTAMP AS OF clause with a literal
+// version, as in "FROM t FOR VERSION AS OF 42 x"
+func skipQueryPeriod(tokens []sqlToken, i int) int {
+	if i+4 >= len(tokens) || !tokens[i].keyword("for") || !tokens[i+2].keyword("as") || !tokens[i+3].keyword("of") {
+		return i
+	}
+	j := i + 4
+	if tokens[j].ident && j+1 < len(tokens) && tokens[j+1].text == "'" {
+		j++ // TIMESTAMP '...'
+	}
+	return j + 1
+}
+
+// skipAlias skips an optional [AS] alias [(column, ...)] after a table name and
+// returns the alias
+func skipAlias(tokens []sqlToken, i int) (string, int) {
+	if i < len(tokens) && tokens[i].keyword("as") {
+		i++
+	}
+	if i < len(tokens) && tokens[i].ident && !isReservedWord(tokens[i]) {
+		alias := tokens[i].text
+		i++
+		if i < len(tokens) && tokens[i].text == "(" {
+			i = skipParens(tokens, i)
+		}
+		return alias, i
+	}
+	return "", i
+}
+
+// reservedWords can follow FROM or a table name but are never table names or aliases
+var reservedWords = map[string]bool{
+	"select": true, "where": true, "group": true, "order": true, "having": true, "limit": true,
+	"offset": true, "fetch": true, "join": true, "inner": true, "left": true, "right": true,
+	"full": true, "cross": true, "natural": true, "on": true, "using": true, "union": true,
+	"intersect": true, "except": true, "window": true, "lateral": true, "unnest": true,
+	"values": true, "with": true, "tablesample": true, "for": true, "when": true, "set": true,
+}
+
+func isReservedWord(token sqlToken) bool {
+	return !token.quoted && reservedWords[token.text]
+}
@@ -0,0 +1,73 @@
+package trino
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		statementType string
+		readOnly      bool
+		tables        []string
+	}{
+		{"Simple select", "SELECT * FROM orders", "select", true, []string{"hive.sales.orders"}},
+		{"Qualified names", "SELECT * FROM iceberg.raw.events e JOIN analytics.users AS u ON e.user_id = u.id",
+			"select", true, []string{"iceberg.raw.events", "hive.analytics.users"}},
+		{"Comma list", "SELECT * FROM a, b x, c AS y WHERE a.id = b.id", "select", true,
+			[]string{"hive.sales.a", "hive.sales.b", "hive.sales.c"}},
+		{"Quoted identifiers", `SELECT * FROM "Hive"."Sales"."Orders"`, "select", true, []string{"hive.sales.orders"}},
+		{"Subquery", "SELECT * FROM (SELECT id FROM orders) t WHERE id IN (SELECT id FROM refunds)",
+			"select", true, []string{"hive.sales.orders", "hive.sales.refunds"}},
+		{"CTE is not a table", "WITH recent AS (SELECT * FROM orders) SELECT * FROM recent JOIN users ON true",
+			"select", true, []string{"hive.sales.orders", "hive.sales.users"}},
+		{"CTE shadowing a table", "SELECT * FROM salaries, (WITH salaries AS (SELECT 1 x) SELECT * FROM salaries) s",
+			"select", true, []string{"hive.sales.salaries"}},
+		{"CTE reading the table it shadows", "WITH orders AS (SELECT * FROM orders WHERE id > 0) SELECT * FROM orders",
+			"select", true, []string{"hive.sales.orders"}},
+		{"Recursive CTE", "WITH RECURSIVE t(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM t WHERE n < 5) SELECT * FROM t",
+			"select", true, nil},
+		{"Extract is not a table", "SELECT EXTRACT(year FROM created_at) FROM orders", "select", true, []string{"hive.sales.orders"}},
+		{"Table function", "SELECT * FROM UNNEST(ARRAY[1, 2]) AS t(x)", "select", true, nil},
+		{"Literal and comment", "SELECT 'FROM secrets' FROM orders -- JOIN passwords", "select", true, []string{"hive.sales.orders"}},
+		{"Insert", "INSERT INTO archive.orders SELECT * FROM orders", "insert", false,
+			[]string{"hive.archive.orders", "hive.sales.orders"}},
+		{"Create if not exists", "CREATE TABLE IF NOT EXISTS tmp.copy AS SELECT * FROM orders", "create", false,
+			[]string{"hive.tmp.copy", "hive.sales.orders"}},
+		{"Describe", "DESCRIBE memory.default.t", "describe", true, []string{"memory.default.t"}},
+		{"Show columns", "SHOW COLUMNS FROM orders", "show", true, []string{"hive.sales.orders"}},
+		{"Show stats of a query", "SHOW STATS FOR (SELECT * FROM hive.hr.salaries WHERE ds = '2024-01-01')", "show", true, []string{"hive.hr.salaries"}},
+		{"Show stats of a table", "SHOW STATS FOR orders", "show", true, []string{"hive.sales.orders"}},
+		{"Show catalogs", "SHOW CATALOGS", "show", true, nil},
+		{"Time travel clause", "SELECT * FROM orders FOR VERSION AS OF 3 o, users FOR TIMESTAMP AS OF TIMESTAMP '2024-01-01 00:00:00 UTC'",
+			"select", true, []string{"hive.sales.orders", "hive.sales.users"}},
+		{"Parenthesized table", "SELECT * FROM (hive.secret.users) u WHERE u.id = 1", "select", true, []string{"hive.secret.users"}},
+		{"Parenthesized join", "SELECT * FROM orders JOIN ((a JOIN b ON a.id = b.id)) ON true", "select", true,
+			[]string{"hive.sales.orders", "hive.sales.a", "hive.sales.b"}},
+		{"Comma after a join", "SELECT * FROM a JOIN b ON a.id = b.id, c, (d) WHERE c.id IN (1, 2) ORDER BY a.id, b.id",
+			"select", true, []string{"hive.sales.a", "hive.sales.b", "hive.sales.c", "hive.sales.d"}},
+		{"TABLE in parentheses", "SELECT * FROM (TABLE orders)", "select", true, []string{"hive.sales.orders"}},
+		{"Duplicates", "SELECT * FROM orders o1 JOIN orders o2 ON o1.id = o2.parent", "select", true, []string{"hive.sales.orders"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := AnalyzeQuery(tt.query, "hive", "sales")
+			if metadata.StatementType != tt.statementType {
+				t.Errorf("StatementType = %q, want %q", metadata.StatementType, tt.statementType)
+			}
+			if metadata.ReadOnly != tt.readOnly {
+				t.Errorf("ReadOnly = %t, want %t", metadata.ReadOnly, tt.readOnly)
+			}
+			var tables []string
+			for _, table := range metadata.Tables {
+				tables = append(tables, table.String())
+			}
+			if !reflect.DeepEqual(tables, tt.tables) {
+				t.Errorf("Tables = %v, want %v", tables, tt.tables)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package trino
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// normalizeQueryForRules prepares a query for the blocked query patterns:
+// comments are removed, string literals are emptied so their content never
+// matches, quoted identifiers lose their quotes and whitespace collapses to
+// single spaces. "CROSS /* x */\n JOIN" thus reads "CROSS JOIN" and
+// "information_schema"."table_privileges" reads information_schema.table_privileges.
+func normalizeQueryForRules(query string) string {
+	var result strings.Builder
+	tokens := tokenizeSQL(query)
+	for i, token := range tokens {
+		// Whitespace and comments leave a gap between tokens
+		if i > 0 && token.start > tokens[i-1].end {
+			result.WriteByte(' ')
+		}
+		switch {
+		case token.text == "'" && !token.ident:
+			result.WriteString("''")
+		case token.quoted:
+			result.WriteString(token.text)
+		default:
+			result.WriteString(query[token.start:token.end])
+		}
+	}
+	return result.String()
+}
+
+// checkBlockedPatterns rejects a query that matches one of the configured
+// TRINO_BLOCKED_QUERY_PATTERNS, logging the rule that matched
+func (c *Client) checkBlockedPatterns(ctx context.Context, query string) error {
+	patterns := c.currentPolicy().BlockedQueryPatterns
+	if len(patterns) == 0 {
+		return nil
+	}
+	normalized := normalizeQueryForRules(query)
+	for _, pattern := range patterns {
+		if pattern.MatchString(normalized) {
+			rule := strings.TrimPrefix(pattern.String(), "(?i)")
+			user := getQueryUsername(ctx)
+			if user == "" {
+				user = c.config.User
+			}
+			log.Printf("WARNING: Query from user %s blocked by rule %q: %s", user, rule, normalized)
+			return policyError(ErrorQueryRejected, "This server's TRINO_BLOCKED_QUERY_PATTERNS reject queries like this one; rewrite the query",
+				"security restriction: query blocked by rule %q", rule)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package trino
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestNormalizeQueryForRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"Whitespace", "SELECT *\n  FROM a\tCROSS   JOIN b", "SELECT * FROM a CROSS JOIN b"},
+		{"Comments", "SELECT * FROM a CROSS/* hidden */JOIN b -- trailing", "SELECT * FROM a CROSS JOIN b"},
+		{"Literals", "SELECT 'CROSS JOIN', 'it''s' FROM a", "SELECT '', '' FROM a"},
+		{"Quoted identifiers", `SELECT * FROM "information_schema"."table_privileges"`, "SELECT * FROM information_schema.table_privileges"},
+		{"Unterminated literal", "SELECT * FROM a WHERE b = 'CROSS JOIN", "SELECT * FROM a WHERE b = ''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeQueryForRules(tt.query); got != tt.expected {
+				t.Errorf("normalizeQueryForRules(%q) = %q, want %q", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckBlockedPatterns(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{User: "trino"}}
+	client.SetPolicy(&config.Policy{BlockedQueryPatterns: []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bcross join\b`),
+		regexp.MustCompile(`(?i)information_schema\.table_privileges`),
+	}})
+
+	tests := []struct {
+		name    string
+		query   string
+		blocked bool
+	}{
+		{"Allowed", "SELECT * FROM a JOIN b ON a.id = b.id", false},
+		{"Cross join", "select * from a cross\n join b", true},
+		{"Cross join split by comment", "SELECT * FROM a CROSS /**/ JOIN b", true},
+		{"Cross join in a literal", "SELECT 'cross join' FROM a", false},
+		{"Cross join in a comment", "SELECT * FROM a -- no cross join here", false},
+		{"Quoted privileges table", `SELECT * FROM "INFORMATION_SCHEMA"."TABLE_PRIVILEGES"`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.checkBlockedPatterns(context.Background(), tt.query)
+			if tt.blocked && err == nil {
+				t.Errorf("checkBlockedPatterns(%q) expected error, got nil", tt.query)
+			}
+			if !tt.blocked && err != nil {
+				t.Errorf("checkBlockedPatterns(%q) error = %v", tt.query, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package trino
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// checkQueryScope rejects a query that exceeds one of the complexity limits:
+// TRINO_MAX_QUERY_LENGTH characters, TRINO_MAX_QUERY_TABLES distinct tables,
+// TRINO_MAX_QUERY_JOINS joins or TRINO_MAX_SUBQUERY_DEPTH nested subqueries,
+// or that references tables of more than one catalog when
+// TRINO_BLOCK_CROSS_CATALOG_QUERIES is set. Federated joins and joins of many
+// large tables are the queries most likely to overload a cluster. Like the
+// other query checks it is lexical: tables read through views or table
+// functions are not counted.
+func (c *Client) checkQueryScope(query string) error {
+	policy := c.currentPolicy()
+	if !policy.BlockCrossCatalogQueries && policy.MaxQueryTables == 0 && policy.MaxQueryLength == 0 &&
+		policy.MaxQueryJoins == 0 && policy.MaxSubqueryDepth == 0 {
+		return nil
+	}
+
+	if length := utf8.RuneCountInString(query); policy.MaxQueryLength > 0 && length > policy.MaxQueryLength {
+		return policyError(ErrorQueryRejected, "Shorten the query, e.g. by splitting it into several queries",
+			"query too long: the query is %d characters, more than the limit of %d (TRINO_MAX_QUERY_LENGTH)", length, policy.MaxQueryLength)
+	}
+
+	tokens := tokenizeSQL(query)
+	if joins := countJoins(tokens); policy.MaxQueryJoins > 0 && joins > policy.MaxQueryJoins {
+		return policyError(ErrorQueryRejected, "Split the query into smaller queries that each join fewer tables",
+			"too many joins: the query has %d joins, more than the limit of %d (TRINO_MAX_QUERY_JOINS)", joins, policy.MaxQueryJoins)
+	}
+	if depth := subqueryDepth(tokens); policy.MaxSubqueryDepth > 0 && depth > policy.MaxSubqueryDepth {
+		return policyError(ErrorQueryRejected, "Flatten nested subqueries into joins or WITH clauses",
+			"subqueries too deep: the query nests subqueries %d deep, more than the limit of %d (TRINO_MAX_SUBQUERY_DEPTH)", depth, policy.MaxSubqueryDepth)
+	}
+
+	tables := AnalyzeQuery(query, c.config.Catalog, c.config.DefaultSchema(c.config.Catalog)).Tables
+	if policy.MaxQueryTables > 0 && len(tables) > policy.MaxQueryTables {
+		return policyError(ErrorQueryRejected, "Split the query into smaller queries that each reference fewer tables",
+			"too many tables: the query references %d tables, more than the limit of %d (TRINO_MAX_QUERY_TABLES)", len(tables), policy.MaxQueryTables)
+	}
+
+	if policy.BlockCrossCatalogQueries {
+		seen := make(map[string]bool)
+		var catalogs []string
+		for _, table := range tables {
+			catalog := strings.ToLower(table.Catalog)
+			if !seen[catalog] {
+				seen[catalog] = true
+				catalogs = append(catalogs, catalog)
+			}
+		}
+		if len(catalogs) > 1 {
+			sort.Strings(catalogs)
+			return policyError(ErrorQueryRejected, "Query each catalog separately and combine the results",
+				"cross-catalog query: the query references tables of catalogs %s; queries may only reference one catalog", strings.Join(catalogs, ", "))
+		}
+	}
+	return nil
+}
+
+// countJoins returns the number of JOIN clauses of a statement
+func countJoins(tokens []sqlToken) int {
+	joins := 0
+	for _, token := range tokens {
+		if token.keyword("join") {
+			joins++
+		}
+	}
+	return joins
+}
+
+// subqueryDepth returns how deeply subqueries are nested in a statement: 0
+// for a query without subqueries, 1 for a subquery or WITH clause query in
+// it, and so on
+func subqueryDepth(tokens []sqlToken) int {
+	var open []bool // Per parenthesis, whether it opens a subquery
+	depth, deepest := 0, 0
+	for i, token := range tokens {
+		switch token.text {
+		case "(":
+			subquery := startsQuery(tokens, i+1)
+			open = append(open, subquery)
+			if subquery {
+				depth++
+				deepest = max(deepest, depth)
+			}
+		case ")":
+			if len(open) > 0 {
+				if open[len(open)-1] {
+					depth--
+				}
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	return deepest
+}
@@ -0,0 +1,97 @@
+package trino
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestCheckQueryScope(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			Catalog:                  "hive",
+			Schema:                   "analytics",
+			BlockCrossCatalogQueries: true,
+			MaxQueryTables:           3,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		expectError string
+	}{
+		{"Single table", "SELECT * FROM events", ""},
+		{"Join in the default catalog", "SELECT * FROM events e JOIN hive.crm.users u ON e.user_id = u.id", ""},
+		{"Same table twice", "SELECT * FROM events a JOIN events b ON a.parent_id = b.id", ""},
+		{"CTE is not a table", "WITH recent AS (SELECT * FROM events) SELECT * FROM recent JOIN users ON recent.user_id = users.id", ""},
+		{"Other catalog only", "SELECT * FROM postgresql.public.accounts", ""},
+		{"No tables", "SELECT 1", ""},
+		{"Federated join", "SELECT * FROM events e JOIN postgresql.public.accounts a ON e.account_id = a.id", "tables of catalogs hive, postgresql"},
+		{"Subquery in another catalog", "SELECT * FROM events WHERE user_id IN (SELECT id FROM iceberg.crm.users)", "tables of catalogs hive, iceberg"},
+		{"Parenthesized table in another catalog", "SELECT * FROM events JOIN (postgresql.public.accounts) a ON true", "tables of catalogs hive, postgresql"},
+		{"Too many tables", "SELECT * FROM a JOIN b ON a.id = b.id JOIN c ON b.id = c.id JOIN d ON c.id = d.id", "references 4 tables, more than the limit of 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.checkQueryScope(tt.query)
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("checkQueryScope(%q) unexpected error: %v", tt.query, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("checkQueryScope(%q) error = %v, want %q", tt.query, err, tt.expectError)
+			}
+		})
+	}
+
+	complexity := &Client{
+		config: &config.TrinoConfig{
+			Catalog:          "hive",
+			Schema:           "analytics",
+			MaxQueryLength:   120,
+			MaxQueryJoins:    2,
+			MaxSubqueryDepth: 1,
+		},
+	}
+	complexityTests := []struct {
+		name        string
+		query       string
+		expectError string
+	}{
+		{"Within limits", "SELECT * FROM a JOIN b ON a.id = b.id LEFT JOIN c ON b.id = c.id", ""},
+		{"One subquery", "SELECT * FROM a WHERE id IN (SELECT id FROM b)", ""},
+		{"Sibling subqueries", "SELECT (SELECT max(x) FROM b), (SELECT min(x) FROM c) FROM a", ""},
+		{"Expression parentheses", "SELECT (1 + (2 * 3)) FROM a WHERE (id = 1 OR (id = 2))", ""},
+		{"JOIN in a string", "SELECT 'join join join' FROM a", ""},
+		{"Too long", "SELECT " + strings.Repeat("x, ", 50) + "y FROM a", "the query is 165 characters, more than the limit of 120 (TRINO_MAX_QUERY_LENGTH)"},
+		{"Too many joins", "SELECT * FROM a JOIN b ON a.id = b.id JOIN c ON b.id = c.id CROSS JOIN d", "the query has 3 joins, more than the limit of 2 (TRINO_MAX_QUERY_JOINS)"},
+		{"Nested subqueries", "SELECT * FROM (SELECT * FROM (SELECT * FROM a) x) y", "nests subqueries 2 deep, more than the limit of 1 (TRINO_MAX_SUBQUERY_DEPTH)"},
+		{"Parenthesized tables", "SELECT * FROM ((a)) JOIN (b) ON true", ""},
+		{"TABLE subquery", "SELECT * FROM (SELECT * FROM (TABLE a) x) y", "nests subqueries 2 deep"},
+		{"Subquery in a CTE", "WITH x AS (SELECT * FROM a WHERE id IN (SELECT id FROM b)) SELECT * FROM x", "nests subqueries 2 deep"},
+	}
+	for _, tt := range complexityTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := complexity.checkQueryScope(tt.query)
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("checkQueryScope(%q) unexpected error: %v", tt.query, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("checkQueryScope(%q) error = %v, want %q", tt.query, err, tt.expectError)
+			}
+		})
+	}
+
+	unlimited := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "analytics"}}
+	if err := unlimited.checkQueryScope("SELECT * FROM events JOIN postgresql.public.accounts ON true"); err != nil {
+		t.Errorf("checkQueryScope() without limits error = %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const queryTimeoutKey contextKey = "query_timeout"
+
+// WithQueryTimeout overrides TRINO_QUERY_TIMEOUT for the queries of one tool
+// call. The timeout is capped at TRINO_MAX_QUERY_TIMEOUT and also sent to
+// Trino as the query_max_run_time session property, so the coordinator stops
+// the query even if the server's cancellation does not reach it.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey, timeout)
+}
+
+// queryTimeout returns how long a query may run and, for a timeout requested
+// by the caller, the query_max_run_time session property enforcing it
+func (c *Client) queryTimeout(ctx context.Context) (time.Duration, string) {
+	timeout, ok := ctx.Value(queryTimeoutKey).(time.Duration)
+	if !ok || timeout <= 0 {
+		return c.timeout, ""
+	}
+	if limit := c.config.MaxQueryTimeout; limit > 0 && timeout > limit {
+		timeout = limit
+	}
+	return timeout, fmt.Sprintf("query_max_run_time=%ds", int64(timeout.Round(time.Second)/time.Second))
+}
@@ -0,0 +1,23 @@
+package trino
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestQueryTimeout(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{MaxQueryTimeout: 10 * time.Minute}, timeout: 30 * time.Second}
+
+	if timeout, session := client.queryTimeout(context.Background()); timeout != 30*time.Second || session != "" {
+		t.Errorf("queryTimeout() = %v, %q; want the configured timeout without a session property", timeout, session)
+	}
+	if timeout, session := client.queryTimeout(WithQueryTimeout(context.Background(), 5*time.Minute)); timeout != 5*time.Minute || session != "query_max_run_time=300s" {
+		t.Errorf("queryTimeout() with override = %v, %q; want 5m and query_max_run_time=300s", timeout, session)
+	}
+	if timeout, session := client.queryTimeout(WithQueryTimeout(context.Background(), time.Hour)); timeout != 10*time.Minute || session != "query_max_run_time=600s" {
+		t.Errorf("queryTimeout() over the maximum = %v, %q; want it capped at 10m", timeout, session)
+	}
+}
@@ -0,0 +1,246 @@
+package trino
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+const (
+	queryTrackerKey contextKey = "query_tracker"
+	usageKey        contextKey = "usage"
+
+	// killQueryTimeout bounds the explicit kill issued after a cancelled query
+	killQueryTimeout = 10 * time.Second
+
+	// progressCallbackPeriod throttles driver progress updates while a query runs
+	progressCallbackPeriod = time.Second
+)
+
+// queryIDPattern matches Trino query IDs (e.g. 20240101_123456_00001_abcde)
+var queryIDPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// QueryStats holds post-execution statistics reported by Trino for a query
+type QueryStats struct {
+	State              string `json:"state"`
+	CPUTimeMillis      int64  `json:"cpuTimeMillis"`
+	WallTimeMillis     int64  `json:"wallTimeMillis"`
+	QueuedTimeMillis   int64  `json:"queuedTimeMillis"`
+	ElapsedTimeMillis  int64  `json:"elapsedTimeMillis"`
+	ProcessedRows      int64  `json:"processedRows"`
+	ProcessedBytes     int64  `json:"processedBytes"`
+	PhysicalInputBytes int64  `json:"physicalInputBytes"`
+	PeakMemoryBytes    int64  `json:"peakMemoryBytes"`
+	SpilledBytes       int64  `json:"spilledBytes"`
+}
+
+// queryTracker records the Trino query ID, UI link and latest statistics for a
+// statement. It is used to kill the query explicitly when the caller goes away
+// and to report execution metadata alongside results. It implements the driver's
+// trino.ProgressUpdater interface.
+type queryTracker struct {
+	mu      sync.Mutex
+	queryID string
+	infoURI string
+	stats   *QueryStats
+}
+
+// withQueryTracker attaches a new query tracker to the context
+func withQueryTracker(ctx context.Context) (context.Context, *queryTracker) {
+	tracker := &queryTracker{}
+	return context.WithValue(ctx, queryTrackerKey, tracker), tracker
+}
+
+// getQueryTracker retrieves the query tracker from context
+func getQueryTracker(ctx context.Context) (*queryTracker, bool) {
+	tracker, ok := ctx.Value(queryTrackerKey).(*queryTracker)
+	return tracker, ok
+}
+
+// Usage adds up the bytes scanned by the queries run with a context, e.g.
+// for the daily per-client quotas of the MCP server
+type Usage struct {
+	mu           sync.Mutex
+	scannedBytes int64
+}
+
+// WithUsage returns a context whose queries add their scanned bytes to the returned Usage
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	usage := &Usage{}
+	return context.WithValue(ctx, usageKey, usage), usage
+}
+
+// ScannedBytes returns the bytes scanned so far
+func (u *Usage) ScannedBytes() int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.scannedBytes
+}
+
+// recordUsage adds the bytes a finished query scanned to the Usage of ctx:
+// the physical input read from storage, or the processed input for connectors
+// that do not report it (e.g. tpch)
+func recordUsage(ctx context.Context, tracker *queryTracker) {
+	usage, ok := ctx.Value(usageKey).(*Usage)
+	if !ok {
+		return
+	}
+	stats := tracker.Stats()
+	if stats == nil {
+		return
+	}
+	scanned := stats.PhysicalInputBytes
+	if scanned == 0 {
+		scanned = stats.ProcessedBytes
+	}
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.scannedBytes += scanned
+}
+
+// QueryID returns the recorded Trino query ID, or an empty string if none was seen yet
+func (t *queryTracker) QueryID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.queryID
+}
+
+// InfoURI returns the Trino UI link for the query, or an empty string if unknown
+func (t *queryTracker) InfoURI() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.infoURI
+}
+
+// Stats returns the latest statistics reported for the query, or nil if none were received
+func (t *queryTracker) Stats() *QueryStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stats == nil {
+		return nil
+	}
+	stats := *t.stats
+	return &stats
+}
+
+func (t *queryTracker) setQueryID(id, infoURI string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.queryID == "" {
+		t.queryID = id
+	}
+	if t.infoURI == "" && t.queryID == id {
+		t.infoURI = infoURI
+	}
+}
+
+// Update implements trino.ProgressUpdater. The driver keeps the updater on the
+// pooled connection, so updates for other queries are ignored.
+func (t *queryTracker) Update(info trino.QueryProgressInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.queryID == "" {
+		t.queryID = info.QueryId
+	}
+	if info.QueryId != t.queryID {
+		return
+	}
+	s := info.QueryStats
+	t.stats = &QueryStats{
+		State:              s.State,
+		CPUTimeMillis:      s.CPUTimeMillis,
+		WallTimeMillis:     s.WallTimeMillis,
+		QueuedTimeMillis:   s.QueuedTimeMillis,
+		ElapsedTimeMillis:  s.ElapsedTimeMillis,
+		ProcessedRows:      s.ProcessedRows,
+		ProcessedBytes:     s.ProcessedBytes,
+		PhysicalInputBytes: s.PhysicalInputBytes,
+		PeakMemoryBytes:    s.PeakMemoryBytes,
+		SpilledBytes:       s.SpilledBytes,
+	}
+}
+
+// captureQueryID reads the query ID and UI link from the initial POST /v1/statement response
+// and restores the body so the driver can consume it unchanged
+func captureQueryID(req *http.Request, resp *http.Response) {
+	tracker, ok := getQueryTracker(req.Context())
+	if !ok || req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/v1/statement") {
+		return
+	}
+	if resp.StatusCode != http.StatusOK || resp.Body == nil {
+		return
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	var statement struct {
+		ID      string `json:"id"`
+		InfoURI string `json:"infoUri"`
+	}
+	if err := json.Unmarshal(body, &statement); err == nil && statement.ID != "" {
+		tracker.setQueryID(statement.ID, statement.InfoURI)
+	}
+}
+
+// KillQuery kills a running query of any user via system.runtime.kill_query,
+// for operators. Trino's access control decides whether the connecting user
+// may kill it.
+func (c *Client) KillQuery(ctx context.Context, queryID string) error {
+	if !queryIDPattern.MatchString(queryID) {
+		return fmt.Errorf("invalid query ID '%s': must be a Trino query ID such as 20240101_123456_00001_abcde", queryID)
+	}
+	db, err := c.ensureConnected(ctx)
+	if err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf("CALL system.runtime.kill_query(query_id => '%s', message => 'Killed by an MCP server administrator')", queryID)
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("failed to kill query %s: %w", queryID, err)
+	}
+	return nil
+}
+
+// killQuery explicitly kills a running query via system.runtime.kill_query.
+// The driver already issues a DELETE when the context is cancelled; this makes
+// sure the query does not keep consuming cluster resources if that request is lost.
+func (c *Client) killQuery(ctx context.Context, queryID string) {
+	if queryID == "" || !queryIDPattern.MatchString(queryID) {
+		return
+	}
+
+	c.mu.Lock()
+	db := c.db
+	c.mu.Unlock()
+	if db == nil {
+		return
+	}
+
+	// Detach from the cancelled context but keep its values (impersonation)
+	killCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), killQueryTimeout)
+	defer cancel()
+
+	stmt := fmt.Sprintf("CALL system.runtime.kill_query(query_id => '%s', message => 'Cancelled by MCP client')", queryID)
+	if _, err := db.ExecContext(killCtx, stmt); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "not running") {
+			return // Already cancelled by the driver
+		}
+		log.Printf("WARNING: Failed to kill cancelled query %s: %v", queryID, err)
+		return
+	}
+	log.Printf("INFO: Killed cancelled query %s", queryID)
+}
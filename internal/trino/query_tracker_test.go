@@ -0,0 +1,168 @@
+package trino
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+func TestCaptureQueryID(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		statusCode  int
+		body        string
+		tracked     bool
+		expectedID  string
+		expectedURI string
+	}{
+		{
+			name:        "Initial statement response",
+			method:      http.MethodPost,
+			path:        "/v1/statement",
+			statusCode:  http.StatusOK,
+			body:        `{"id":"20240101_000000_00001_abcde","infoUri":"http://trino/ui/query.html?20240101_000000_00001_abcde","nextUri":"http://trino/v1/statement/queued/x"}`,
+			tracked:     true,
+			expectedID:  "20240101_000000_00001_abcde",
+			expectedURI: "http://trino/ui/query.html?20240101_000000_00001_abcde",
+		},
+		{
+			name:       "No tracker in context",
+			method:     http.MethodPost,
+			path:       "/v1/statement",
+			statusCode: http.StatusOK,
+			body:       `{"id":"20240101_000000_00001_abcde"}`,
+			tracked:    false,
+		},
+		{
+			name:       "Ignores follow-up requests",
+			method:     http.MethodGet,
+			path:       "/v1/statement/executing/20240101_000000_00001_abcde/y/1",
+			statusCode: http.StatusOK,
+			body:       `{"id":"20240101_000000_00001_abcde"}`,
+			tracked:    true,
+		},
+		{
+			name:       "Ignores error responses",
+			method:     http.MethodPost,
+			path:       "/v1/statement",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"id":"20240101_000000_00001_abcde"}`,
+			tracked:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var tracker *queryTracker
+			if tt.tracked {
+				ctx, tracker = withQueryTracker(ctx)
+			}
+
+			req, _ := http.NewRequestWithContext(ctx, tt.method, "http://trino:8080"+tt.path, nil)
+			resp := &http.Response{
+				StatusCode: tt.statusCode,
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			captureQueryID(req, resp)
+
+			// Body must remain readable by the driver
+			body, err := io.ReadAll(resp.Body)
+			if err != nil || string(body) != tt.body {
+				t.Errorf("response body = %q, %v; want %q", body, err, tt.body)
+			}
+
+			if tracker != nil && tracker.QueryID() != tt.expectedID {
+				t.Errorf("QueryID() = %q, want %q", tracker.QueryID(), tt.expectedID)
+			}
+			if tracker != nil && tracker.InfoURI() != tt.expectedURI {
+				t.Errorf("InfoURI() = %q, want %q", tracker.InfoURI(), tt.expectedURI)
+			}
+		})
+	}
+}
+
+func TestQueryTrackerUpdate(t *testing.T) {
+	_, tracker := withQueryTracker(context.Background())
+
+	if tracker.Stats() != nil {
+		t.Fatal("Stats() before any update should be nil")
+	}
+
+	var info trino.QueryProgressInfo
+	info.QueryId = "query_1"
+	info.QueryStats.State = "FINISHED"
+	info.QueryStats.CPUTimeMillis = 1200
+	info.QueryStats.ProcessedRows = 42
+	info.QueryStats.PeakMemoryBytes = 1024
+	tracker.Update(info)
+
+	stats := tracker.Stats()
+	if stats == nil || stats.State != "FINISHED" || stats.CPUTimeMillis != 1200 || stats.ProcessedRows != 42 || stats.PeakMemoryBytes != 1024 {
+		t.Errorf("Stats() = %+v, want values from update", stats)
+	}
+	if tracker.QueryID() != "query_1" {
+		t.Errorf("QueryID() = %q, want query_1", tracker.QueryID())
+	}
+
+	// Updates for a different query on the same pooled connection are ignored
+	info.QueryId = "query_2"
+	info.QueryStats.ProcessedRows = 7
+	tracker.Update(info)
+	if got := tracker.Stats().ProcessedRows; got != 42 {
+		t.Errorf("ProcessedRows after foreign update = %d, want 42", got)
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	ctx, usage := WithUsage(context.Background())
+
+	// A query that failed before reporting statistics scanned nothing
+	_, tracker := withQueryTracker(ctx)
+	recordUsage(ctx, tracker)
+
+	var info trino.QueryProgressInfo
+	info.QueryId = "query_1"
+	info.QueryStats.PhysicalInputBytes = 4096
+	info.QueryStats.ProcessedBytes = 8192
+	tracker.Update(info)
+	recordUsage(ctx, tracker)
+
+	// Connectors without physical input report processed bytes
+	_, tracker = withQueryTracker(ctx)
+	info.QueryId = "query_2"
+	info.QueryStats.PhysicalInputBytes = 0
+	info.QueryStats.ProcessedBytes = 100
+	tracker.Update(info)
+	recordUsage(ctx, tracker)
+
+	if got := usage.ScannedBytes(); got != 4196 {
+		t.Errorf("ScannedBytes() = %d, want 4196", got)
+	}
+
+	// Queries outside a usage context are not recorded anywhere
+	recordUsage(context.Background(), tracker)
+}
+
+func TestQueryIDPattern(t *testing.T) {
+	valid := []string{"20240101_000000_00001_abcde"}
+	invalid := []string{"", "x'; DROP TABLE y; --", "id with spaces"}
+
+	for _, id := range valid {
+		if !queryIDPattern.MatchString(id) {
+			t.Errorf("expected %q to be a valid query ID", id)
+		}
+	}
+	for _, id := range invalid {
+		if queryIDPattern.MatchString(id) {
+			t.Errorf("expected %q to be rejected", id)
+		}
+	}
+}
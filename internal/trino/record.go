@@ -0,0 +1,227 @@
+package trino
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// exchange is a Trino HTTP request and its response, saved as a fixture file.
+// Requests are matched on method, path and query, and body; the host is
+// ignored so fixtures replay against any TRINO_HOST.
+type exchange struct {
+	Method        string      `json:"method"`
+	URL           string      `json:"url"` // Path and query
+	RequestBody   string      `json:"requestBody,omitempty"`
+	RequestHeader http.Header `json:"requestHeader,omitempty"` // For reference, with credentials redacted
+	Status        int         `json:"status"`
+	Header        http.Header `json:"header,omitempty"`
+	Body          string      `json:"body,omitempty"`
+	BodyBase64    string      `json:"bodyBase64,omitempty"` // Instead of Body, for compressed or binary responses
+}
+
+// fixtureDir is where the fixtures of a cluster are kept: each named cluster
+// has its own subdirectory, since its queries get different responses
+func fixtureDir(dir, name string) string {
+	if name == "" {
+		return dir
+	}
+	return filepath.Join(dir, name)
+}
+
+// recorder saves every exchange with Trino to a numbered fixture file, for
+// TRINO_RECORD_DIR. Response bodies are read in full before they are passed on.
+type recorder struct {
+	base http.RoundTripper
+	dir  string
+
+	mu  sync.Mutex
+	seq int
+}
+
+func newRecorder(base http.RoundTripper, dir string) (*recorder, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create TRINO_RECORD_DIR: %w", err)
+	}
+	// Continue the numbering of a previous recording, e.g. of a reconnect
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &recorder{base: base, dir: dir, seq: len(existing)}, nil
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	ex := &exchange{
+		Method:        req.Method,
+		URL:           req.URL.RequestURI(),
+		RequestBody:   reqBody,
+		RequestHeader: redactedHeader(req.Header),
+		Status:        resp.StatusCode,
+		Header:        resp.Header,
+	}
+	if utf8.Valid(body) && resp.Header.Get("Content-Encoding") == "" {
+		ex.Body = string(body)
+	} else {
+		ex.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+	if err := r.save(ex); err != nil {
+		log.Printf("WARNING: Failed to record Trino exchange %s %s: %v", req.Method, req.URL.Path, err)
+	}
+	return resp, nil
+}
+
+var fixtureNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// save writes the exchange to the next fixture file, e.g. 0001-post-v1-statement.json
+func (r *recorder) save(ex *exchange) error {
+	data, err := json.MarshalIndent(ex, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	slug := strings.Trim(fixtureNameChars.ReplaceAllString(strings.ToLower(ex.Method+" "+strings.SplitN(ex.URL, "?", 2)[0]), "-"), "-")
+	if len(slug) > 60 {
+		slug = slug[:60]
+	}
+	return os.WriteFile(filepath.Join(r.dir, fmt.Sprintf("%04d-%s.json", r.seq, slug)), data, 0o600)
+}
+
+// replayer answers requests from the fixtures of a recording instead of the
+// network, for TRINO_REPLAY_DIR. Each request gets the first unused recorded
+// response with the same method, URL and body; once all are used, the last
+// one is repeated, so a query can run more often than it was recorded.
+type replayer struct {
+	dir string
+
+	mu        sync.Mutex
+	exchanges []*exchange
+	used      []bool
+}
+
+func newReplayer(dir string) (*replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no fixtures in TRINO_REPLAY_DIR %s", dir)
+	}
+	sort.Strings(files)
+	r := &replayer{dir: dir, used: make([]bool, len(files))}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		var ex exchange
+		if err := json.Unmarshal(data, &ex); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", filepath.Base(file), err)
+		}
+		r.exchanges = append(r.exchanges, &ex)
+	}
+	return r, nil
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	ex := r.match(req.Method, req.URL.RequestURI(), reqBody)
+	if ex == nil {
+		return nil, fmt.Errorf("no recorded Trino exchange for %s %s in %s", req.Method, req.URL.Path, r.dir)
+	}
+
+	body := []byte(ex.Body)
+	if ex.BodyBase64 != "" {
+		if body, err = base64.StdEncoding.DecodeString(ex.BodyBase64); err != nil {
+			return nil, fmt.Errorf("invalid body of recorded Trino exchange for %s %s: %w", req.Method, req.URL.Path, err)
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        ex.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *replayer) match(method, url, body string) *exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last := -1
+	for i, ex := range r.exchanges {
+		if ex.Method != method || ex.URL != url || ex.RequestBody != body {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return ex
+		}
+		last = i
+	}
+	if last < 0 {
+		return nil
+	}
+	return r.exchanges[last]
+}
+
+// readRequestBody returns the body of req and restores it for sending
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return string(body), nil
+}
+
+// redactedHeader copies header with the credentials debug logging hides
+// replaced, so fixtures can be shared
+func redactedHeader(header http.Header) http.Header {
+	out := header.Clone()
+	for name := range out {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			out[name] = []string{"[REDACTED]"}
+		}
+	}
+	return out
+}
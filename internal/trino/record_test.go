@@ -0,0 +1,57 @@
+package trino
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	server := trinotest.NewServer()
+	server.Handle("SELECT name FROM nation", trinotest.Result{
+		Columns:  []trinotest.Column{{Name: "name", Type: "varchar(25)"}},
+		Rows:     [][]any{{"ALGERIA"}, {"ARGENTINA"}, {"BRAZIL"}},
+		PageSize: 2,
+	})
+	dir := t.TempDir()
+
+	recording := newMockClient(t, server, "record-test", func(cfg *config.TrinoConfig) { cfg.AccessToken = "s3cret-jwt"; cfg.RecordDir = dir })
+	recorded, err := recording.ExecuteQueryWithResult(context.Background(), "SELECT name FROM nation")
+	if err != nil {
+		t.Fatalf("recording ExecuteQueryWithResult() error = %v", err)
+	}
+	server.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "record-test", "*.json"))
+	if len(files) != 3 || !strings.HasSuffix(files[0], "0001-post-v1-statement.json") {
+		t.Fatalf("fixtures = %v, want the statement and its two pages", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if strings.Contains(string(data), "s3cret-jwt") {
+		t.Errorf("fixture contains the access token: %s", data)
+	}
+
+	// Replay needs no coordinator; fixtures work under another cluster name
+	if err := os.Rename(filepath.Join(dir, "record-test"), filepath.Join(dir, "replay-test")); err != nil {
+		t.Fatal(err)
+	}
+	replaying := newMockClient(t, server, "replay-test", func(cfg *config.TrinoConfig) { cfg.ReplayDir = dir })
+	for range 2 {
+		replayed, err := replaying.ExecuteQueryWithResult(context.Background(), "SELECT name FROM nation")
+		if err != nil {
+			t.Fatalf("replayed ExecuteQueryWithResult() error = %v", err)
+		}
+		if len(replayed.Rows) != 3 || replayed.Rows[2]["name"] != "BRAZIL" || replayed.QueryID != recorded.QueryID {
+			t.Errorf("replayed result = %+v, want the recorded %+v", replayed, recorded)
+		}
+	}
+
+	if _, err := replaying.ExecuteQueryWithResult(context.Background(), "SELECT 1"); err == nil || !strings.Contains(err.Error(), "no recorded Trino exchange") {
+		t.Errorf("unrecorded query error = %v, want no recorded Trino exchange", err)
+	}
+}
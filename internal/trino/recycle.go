@@ -0,0 +1,39 @@
+package trino
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// connectionRecycler closes the idle connections of a transport every maxAge,
+// so that new connections resolve the coordinator's name again and follow it
+// to new IPs after a blue/green deploy or a Kubernetes service move. A busy
+// pool would otherwise keep its connections to the old IPs indefinitely.
+// Connections in use finish their request and are closed at a later recycle.
+type connectionRecycler struct {
+	base   *http.Transport
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu         sync.Mutex
+	recycledAt time.Time
+}
+
+func newConnectionRecycler(base *http.Transport, maxAge time.Duration) *connectionRecycler {
+	// The first request starts the clock; there are no connections to close yet
+	return &connectionRecycler{base: base, maxAge: maxAge, now: time.Now}
+}
+
+func (r *connectionRecycler) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	recycle := r.now().Sub(r.recycledAt) >= r.maxAge
+	if recycle {
+		r.recycledAt = r.now()
+	}
+	r.mu.Unlock()
+	if recycle {
+		r.base.CloseIdleConnections()
+	}
+	return r.base.RoundTrip(req)
+}
@@ -0,0 +1,49 @@
+package trino
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConnectionRecycler(t *testing.T) {
+	var dials atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			dials.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	now := time.Now()
+	recycler := newConnectionRecycler(createTransport(false), time.Minute)
+	recycler.now = func() time.Time { return now }
+	client := &http.Client{Transport: recycler}
+	get := func() {
+		resp, err := client.Get(server.URL + "/v1/info")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	get()
+	get()
+	if n := dials.Load(); n != 1 {
+		t.Fatalf("connections before max age = %d, want the first one reused", n)
+	}
+	now = now.Add(time.Minute)
+	get()
+	if n := dials.Load(); n != 2 {
+		t.Errorf("connections after max age = %d, want a new connection", n)
+	}
+	get()
+	if n := dials.Load(); n != 2 {
+		t.Errorf("connections after recycling = %d, want the new one reused", n)
+	}
+}
@@ -0,0 +1,76 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResourceGroups summarizes the queries of each resource group
+type ResourceGroups struct {
+	RunningQueries int64                 `json:"runningQueries"`
+	QueuedQueries  int64                 `json:"queuedQueries"`
+	Groups         []ResourceGroupStatus `json:"groups"` // Most queued first
+	Note           string                `json:"note,omitempty"`
+}
+
+// ResourceGroupStatus is the load of one resource group
+type ResourceGroupStatus struct {
+	ResourceGroup   string `json:"resourceGroup"` // Dot-separated group path, e.g. global.adhoc.alice
+	Running         int64  `json:"running"`
+	Queued          int64  `json:"queued"`
+	LongestQueuedMs *int64 `json:"longestQueuedMs,omitempty"` // Wait so far of the oldest queued query
+	RecentQueries   int64  `json:"recentQueries"`             // Queries that left the queue, still in the coordinator's query history
+	AvgQueuedTimeMs *int64 `json:"avgQueuedTimeMs,omitempty"` // Average queue wait of the recent queries
+	MaxQueuedTimeMs *int64 `json:"maxQueuedTimeMs,omitempty"` // Longest queue wait of the recent queries
+	RecentFailed    int64  `json:"recentFailed,omitempty"`    // Recent queries that failed, e.g. on resource limits
+}
+
+// Query states counted as queued; every other state but FINISHED and FAILED counts as running
+const queuedStates = "('QUEUED', 'WAITING_FOR_RESOURCES')"
+
+// ResourceGroupsWithContext reports running and queued queries per resource
+// group, with the queue waits of recent queries, from system.runtime.queries.
+// Trino access control decides which queries a user can see there, so
+// without the right to view other users' queries the counts only cover the
+// user's own queries.
+func (c *Client) ResourceGroupsWithContext(ctx context.Context) (*ResourceGroups, error) {
+	query := fmt.Sprintf(`SELECT coalesce(array_join(resource_group_id, '.'), '') AS resource_group,
+  count_if(state NOT IN %[1]s AND state NOT IN ('FINISHED', 'FAILED')) AS running,
+  count_if(state IN %[1]s) AS queued,
+  max(date_diff('millisecond', created, current_timestamp)) FILTER (WHERE state IN %[1]s) AS longest_queued_ms,
+  count_if(state NOT IN %[1]s) AS recent_queries,
+  avg(queued_time_ms) FILTER (WHERE state NOT IN %[1]s) AS avg_queued_ms,
+  max(queued_time_ms) FILTER (WHERE state NOT IN %[1]s) AS max_queued_ms,
+  count_if(state = 'FAILED') AS failed
+FROM system.runtime.queries
+GROUP BY 1
+ORDER BY queued DESC, running DESC, resource_group`, queuedStates)
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read system.runtime.queries: %w", err)
+	}
+
+	groups := &ResourceGroups{Groups: []ResourceGroupStatus{}}
+	for _, row := range rows {
+		group := ResourceGroupStatus{
+			ResourceGroup:   stringValue(row["resource_group"]),
+			Running:         int64Value(row["running"]),
+			Queued:          int64Value(row["queued"]),
+			LongestQueuedMs: optionalInt64(row["longest_queued_ms"]),
+			RecentQueries:   int64Value(row["recent_queries"]),
+			AvgQueuedTimeMs: optionalInt64(row["avg_queued_ms"]),
+			MaxQueuedTimeMs: optionalInt64(row["max_queued_ms"]),
+			RecentFailed:    int64Value(row["failed"]),
+		}
+		if group.ResourceGroup == "" {
+			group.ResourceGroup = "(none)" // Queries not assigned to a resource group
+		}
+		groups.RunningQueries += group.Running
+		groups.QueuedQueries += group.Queued
+		groups.Groups = append(groups.Groups, group)
+	}
+	if len(groups.Groups) == 0 {
+		groups.Note = "no queries are visible in system.runtime.queries"
+	}
+	return groups, nil
+}
@@ -0,0 +1,86 @@
+package trino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+const resourceGroupsQuery = `SELECT coalesce(array_join(resource_group_id, '.'), '') AS resource_group,
+  count_if(state NOT IN ('QUEUED', 'WAITING_FOR_RESOURCES') AND state NOT IN ('FINISHED', 'FAILED')) AS running,
+  count_if(state IN ('QUEUED', 'WAITING_FOR_RESOURCES')) AS queued,
+  max(date_diff('millisecond', created, current_timestamp)) FILTER (WHERE state IN ('QUEUED', 'WAITING_FOR_RESOURCES')) AS longest_queued_ms,
+  count_if(state NOT IN ('QUEUED', 'WAITING_FOR_RESOURCES')) AS recent_queries,
+  avg(queued_time_ms) FILTER (WHERE state NOT IN ('QUEUED', 'WAITING_FOR_RESOURCES')) AS avg_queued_ms,
+  max(queued_time_ms) FILTER (WHERE state NOT IN ('QUEUED', 'WAITING_FOR_RESOURCES')) AS max_queued_ms,
+  count_if(state = 'FAILED') AS failed
+FROM system.runtime.queries
+GROUP BY 1
+ORDER BY queued DESC, running DESC, resource_group`
+
+var resourceGroupsColumns = []trinotest.Column{
+	{Name: "resource_group", Type: "varchar"},
+	{Name: "running", Type: "bigint"},
+	{Name: "queued", Type: "bigint"},
+	{Name: "longest_queued_ms", Type: "bigint"},
+	{Name: "recent_queries", Type: "bigint"},
+	{Name: "avg_queued_ms", Type: "double"},
+	{Name: "max_queued_ms", Type: "bigint"},
+	{Name: "failed", Type: "bigint"},
+}
+
+func TestResourceGroups(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	server.Handle(resourceGroupsQuery, trinotest.Result{
+		Columns: resourceGroupsColumns,
+		Rows: [][]any{
+			{"global.adhoc.alice", 1, 3, 45000, 12, 1500.5, 9000, 2},
+			{"global.etl", 4, 0, nil, 30, 20.0, 100, 0},
+			{"", 2, 0, nil, 0, nil, nil, 0},
+		},
+	})
+	client := newMockClient(t, server, "resource-groups", nil)
+
+	groups, err := client.ResourceGroupsWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("ResourceGroupsWithContext() error = %v", err)
+	}
+	if groups.RunningQueries != 7 || groups.QueuedQueries != 3 || groups.Note != "" {
+		t.Errorf("totals = %d running, %d queued, note %q; want 7 and 3 summed over the groups", groups.RunningQueries, groups.QueuedQueries, groups.Note)
+	}
+	if len(groups.Groups) != 3 {
+		t.Fatalf("groups = %+v, want 3", groups.Groups)
+	}
+
+	adhoc := groups.Groups[0]
+	if adhoc.ResourceGroup != "global.adhoc.alice" || adhoc.Running != 1 || adhoc.Queued != 3 || adhoc.RecentQueries != 12 || adhoc.RecentFailed != 2 {
+		t.Errorf("adhoc group = %+v", adhoc)
+	}
+	if adhoc.LongestQueuedMs == nil || *adhoc.LongestQueuedMs != 45000 || adhoc.AvgQueuedTimeMs == nil || *adhoc.AvgQueuedTimeMs != 1500 ||
+		adhoc.MaxQueuedTimeMs == nil || *adhoc.MaxQueuedTimeMs != 9000 {
+		t.Errorf("adhoc queue waits = %v, %v, %v; want 45000, 1500 and 9000", adhoc.LongestQueuedMs, adhoc.AvgQueuedTimeMs, adhoc.MaxQueuedTimeMs)
+	}
+	if etl := groups.Groups[1]; etl.ResourceGroup != "global.etl" || etl.LongestQueuedMs != nil {
+		t.Errorf("etl group = %+v, want no longest queue wait without queued queries", etl)
+	}
+	if none := groups.Groups[2]; none.ResourceGroup != "(none)" || none.Running != 2 || none.AvgQueuedTimeMs != nil || none.MaxQueuedTimeMs != nil {
+		t.Errorf("unassigned group = %+v, want (none) without queue waits", none)
+	}
+}
+
+func TestResourceGroupsWithoutQueries(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	server.Handle(resourceGroupsQuery, trinotest.Result{Columns: resourceGroupsColumns})
+	client := newMockClient(t, server, "resource-groups-empty", nil)
+
+	groups, err := client.ResourceGroupsWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("ResourceGroupsWithContext() error = %v", err)
+	}
+	if len(groups.Groups) != 0 || groups.Groups == nil || groups.Note == "" {
+		t.Errorf("groups = %+v, want an empty list with a note", groups)
+	}
+}
@@ -0,0 +1,164 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// MaxGrantRows bounds the table privileges returned by ShowGrantsWithContext
+const MaxGrantRows = 500
+
+// configuredRoles returns the roles to enable by catalog, with the system
+// access control role under the system catalog. TRINO_CATALOG_ROLES (or a
+// cluster's catalogRoles) takes precedence over the roles of TRINO_CATALOGS_JSON.
+func configuredRoles(cfg *config.TrinoConfig) map[string]string {
+	roles := make(map[string]string, len(cfg.CatalogRoles)+len(cfg.Catalogs)+1)
+	for catalog, cat := range cfg.Catalogs {
+		if cat.Role != "" {
+			roles[catalog] = cat.Role
+		}
+	}
+	for catalog, role := range cfg.CatalogRoles {
+		roles[catalog] = role
+	}
+	if cfg.Role != "" {
+		roles["system"] = cfg.Role
+	}
+	return roles
+}
+
+// roleHeader renders the configured roles as an X-Trino-Role value, the
+// protocol form of SET ROLE: comma-separated catalog=ROLE{name}, ALL or NONE
+// entries. trino-go-client has no role setting, so the header is added by
+// headerRoundTripper.
+func roleHeader(cfg *config.TrinoConfig) string {
+	roles := configuredRoles(cfg)
+	entries := make([]string, 0, len(roles))
+	for catalog, role := range roles {
+		selected := "ROLE{" + role + "}"
+		if strings.EqualFold(role, "all") || strings.EqualFold(role, "none") {
+			selected = strings.ToUpper(role)
+		}
+		entries = append(entries, catalog+"="+url.QueryEscape(selected))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// Grants describes what the server's effective Trino identity can access
+type Grants struct {
+	User            string            `json:"user"`                      // current_user, after impersonation
+	ConfiguredRoles map[string]string `json:"configuredRoles,omitempty"` // TRINO_ROLE (as catalog system), TRINO_CATALOG_ROLES and TRINO_CATALOGS_JSON
+	CurrentRoles    []string          `json:"currentRoles"`              // Enabled roles of the system access control, or of the catalog
+	RoleGrants      []string          `json:"roleGrants"`                // Roles granted to the user
+	Privileges      []TablePrivilege  `json:"privileges"`
+	Truncated       bool              `json:"truncated,omitempty"`
+	Notes           []string          `json:"notes,omitempty"` // Parts the catalog or access control could not report
+}
+
+// TablePrivilege is a row of information_schema.table_privileges
+type TablePrivilege struct {
+	Table     string `json:"table"`
+	Privilege string `json:"privilege"`
+	Grantee   string `json:"grantee"`
+	Grantable bool   `json:"grantable,omitempty"`
+}
+
+// ShowGrantsWithContext reports the current user, its enabled and granted
+// roles and the table privileges visible to it in a catalog, optionally only
+// for one schema or table. Connectors and access controls without role or
+// privilege support report an error for that part, which is added to Notes
+// instead of failing the call.
+func (c *Client) ShowGrantsWithContext(ctx context.Context, catalog, schema, table string) (*Grants, error) {
+	if catalog == "" {
+		catalog = c.config.Catalog
+	}
+	if table != "" {
+		catalog, schema, table = c.resolveTableName(catalog, schema, table)
+		if err := c.checkTableAccess(catalog, schema, table); err != nil {
+			return nil, err
+		}
+	} else if len(c.currentPolicy().AllowedCatalogs) > 0 && !c.isCatalogAllowed(catalog) {
+		return nil, accessDenied("catalog access denied: %s not in allowlist", catalog)
+	}
+
+	rows, err := c.ExecuteQueryWithContext(ctx, "SELECT current_user AS user_name")
+	if err != nil {
+		return nil, err
+	}
+	grants := &Grants{CurrentRoles: []string{}, RoleGrants: []string{}, Privileges: []TablePrivilege{}}
+	if len(rows) > 0 {
+		grants.User = stringValue(rows[0]["user_name"])
+	}
+	roles := configuredRoles(c.config)
+	if len(roles) > 0 {
+		grants.ConfiguredRoles = roles
+	}
+
+	// Roles are per catalog for connectors with their own roles (Hive),
+	// otherwise they belong to the system access control
+	from := ""
+	_, catalogRole := c.config.CatalogRoles[strings.ToLower(catalog)]
+	if catalogRole || c.config.Catalogs[strings.ToLower(catalog)].Role != "" {
+		from = " FROM " + quoteIdentifier(catalog)
+	}
+	note := func(part string, err error) {
+		grants.Notes = append(grants.Notes, fmt.Sprintf("%s unavailable: %v", part, err))
+	}
+	if rows, err := c.ExecuteQueryWithContext(ctx, "SHOW CURRENT ROLES"+from); err != nil {
+		note("current roles", err)
+	} else {
+		for _, row := range rows {
+			grants.CurrentRoles = append(grants.CurrentRoles, stringValue(row["Role"]))
+		}
+	}
+	if rows, err := c.ExecuteQueryWithContext(ctx, "SHOW ROLE GRANTS"+from); err != nil {
+		note("role grants", err)
+	} else {
+		for _, row := range rows {
+			grants.RoleGrants = append(grants.RoleGrants, stringValue(row["Role Grants"]))
+		}
+	}
+
+	var filters []string
+	if schema != "" {
+		filters = append(filters, "table_schema = "+quoteLiteral(strings.ToLower(schema)))
+	}
+	if table != "" {
+		filters = append(filters, "table_name = "+quoteLiteral(strings.ToLower(table)))
+	}
+	where := ""
+	if len(filters) > 0 {
+		where = " WHERE " + strings.Join(filters, " AND ")
+	}
+	query := fmt.Sprintf(`SELECT table_catalog, table_schema, table_name, privilege_type, grantee, is_grantable
+FROM %s.information_schema.table_privileges%s
+ORDER BY table_schema, table_name, privilege_type LIMIT %d`, quoteIdentifier(catalog), where, MaxGrantRows+1)
+	rows, err = c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		note("table privileges", err)
+		return grants, nil
+	}
+	if len(rows) > MaxGrantRows {
+		rows = rows[:MaxGrantRows]
+		grants.Truncated = true
+	}
+	for _, row := range rows {
+		rowCatalog, rowSchema, rowTable := stringValue(row["table_catalog"]), stringValue(row["table_schema"]), stringValue(row["table_name"])
+		if c.checkTableAccess(rowCatalog, rowSchema, rowTable) != nil {
+			continue // Not exposed by the allowlists
+		}
+		grants.Privileges = append(grants.Privileges, TablePrivilege{
+			Table:     rowCatalog + "." + rowSchema + "." + rowTable,
+			Privilege: stringValue(row["privilege_type"]),
+			Grantee:   stringValue(row["grantee"]),
+			Grantable: stringValue(row["is_grantable"]) == "YES",
+		})
+	}
+	return grants, nil
+}
@@ -0,0 +1,59 @@
+package trino
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestRoleHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *config.TrinoConfig
+		expected string
+	}{
+		{"No roles", &config.TrinoConfig{}, ""},
+		{"System role", &config.TrinoConfig{Role: "analyst"}, "system=ROLE%7Banalyst%7D"},
+		{"All roles", &config.TrinoConfig{Role: "all"}, "system=ALL"},
+		{
+			"Catalog roles",
+			&config.TrinoConfig{Role: "analyst", CatalogRoles: map[string]string{"hive": "admin", "iceberg": "none"}},
+			"hive=ROLE%7Badmin%7D,iceberg=NONE,system=ROLE%7Banalyst%7D",
+		},
+		{"TRINO_ROLE wins over a system catalog role", &config.TrinoConfig{Role: "analyst", CatalogRoles: map[string]string{"system": "admin"}}, "system=ROLE%7Banalyst%7D"},
+		{
+			"TRINO_CATALOG_ROLES wins over TRINO_CATALOGS_JSON",
+			&config.TrinoConfig{CatalogRoles: map[string]string{"hive": "admin"}, Catalogs: map[string]config.CatalogConfig{"hive": {Role: "reader"}, "postgresql": {Role: "etl"}}},
+			"hive=ROLE%7Badmin%7D,postgresql=ROLE%7Betl%7D",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roleHeader(tt.config); got != tt.expected {
+				t.Errorf("roleHeader() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHeaderRoundTripperSetsRoles(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Trino-Role")
+	}))
+	defer server.Close()
+
+	cfg := &config.TrinoConfig{Role: "analyst"}
+	client := &http.Client{Transport: &headerRoundTripper{base: http.DefaultTransport, config: cfg, roles: roleHeader(cfg)}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if header != "system=ROLE%7Banalyst%7D" {
+		t.Errorf("X-Trino-Role = %q, want system=ROLE%%7Banalyst%%7D", header)
+	}
+}
@@ -0,0 +1,238 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// healthCheckTimeout bounds a single coordinator health check
+const healthCheckTimeout = 5 * time.Second
+
+// ClusterStatus reports the health and circuit breaker state of a cluster
+type ClusterStatus struct {
+	Healthy   bool
+	Circuit   string
+	LastError string
+	CheckedAt time.Time // Zero until the first health check
+	PingedAt  time.Time // Zero until the first warm-up or keepalive ping
+	PingError string
+}
+
+// Status returns the cluster's last known health and circuit state
+func (c *Cluster) Status() ClusterStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ClusterStatus{
+		Healthy:   !c.unhealthy,
+		Circuit:   c.breaker.currentState(),
+		LastError: c.lastError,
+		CheckedAt: c.checkedAt,
+		PingedAt:  c.pingedAt,
+		PingError: c.pingError,
+	}
+}
+
+// healthy reports whether the last health check or request succeeded
+func (c *Cluster) healthy() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.unhealthy
+}
+
+// setHealth records a health check or request outcome, logging state changes
+func (c *Cluster) setHealth(err error, checked bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if checked {
+		c.checkedAt = time.Now()
+	}
+	if err != nil {
+		if !c.unhealthy {
+			log.Printf("WARNING: Trino cluster '%s' is unhealthy: %v", c.Name, err)
+		}
+		c.unhealthy = true
+		c.lastError = err.Error()
+		return
+	}
+	if c.unhealthy {
+		log.Printf("INFO: Trino cluster '%s' is healthy again", c.Name)
+	}
+	c.unhealthy = false
+	c.lastError = ""
+}
+
+// configureRouting sets up circuit breaking for every cluster and, when a
+// routing policy is configured, health-based routing
+func (s *Clusters) configureRouting(cfg *config.TrinoConfig) {
+	// Circuit breaking applies with or without a routing policy
+	for _, cl := range s.clusters {
+		cl.breaker = newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown)
+	}
+	if cfg.RoutingPolicy == "" || cfg.RoutingPolicy == config.RoutingNone {
+		return
+	}
+	s.policy = cfg.RoutingPolicy
+
+	// Routing order: TRINO_ROUTING_CLUSTERS as listed, otherwise the default
+	// cluster followed by the others in configuration order
+	if len(cfg.RoutingClusters) > 0 {
+		for _, name := range cfg.RoutingClusters {
+			if cl, err := s.Get(name); err == nil {
+				s.pool = append(s.pool, cl)
+			}
+		}
+	} else {
+		primary := s.Default()
+		s.pool = append(s.pool, primary)
+		for _, cl := range s.clusters {
+			if cl != primary {
+				s.pool = append(s.pool, cl)
+			}
+		}
+	}
+
+	if cfg.HealthCheckInterval > 0 {
+		s.every(cfg.HealthCheckInterval, s.checkHealth)
+	}
+}
+
+// candidates returns the clusters to try for a request, in order. A named
+// cluster is the only candidate; otherwise the routing policy orders the pool,
+// with clusters that failed their last health check moved to the end.
+func (s *Clusters) candidates(name string) ([]*Cluster, error) {
+	if name != "" || s.policy == "" || len(s.pool) == 0 {
+		cl, err := s.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return []*Cluster{cl}, nil
+	}
+
+	ordered := make([]*Cluster, 0, len(s.pool))
+	start := 0
+	if s.policy == config.RoutingRoundRobin {
+		start = int(s.next.Add(1)-1) % len(s.pool)
+	}
+	for i := range s.pool {
+		ordered = append(ordered, s.pool[(start+i)%len(s.pool)])
+	}
+
+	healthy := make([]*Cluster, 0, len(ordered))
+	var unhealthy []*Cluster
+	for _, cl := range ordered {
+		if cl.healthy() {
+			healthy = append(healthy, cl)
+		} else {
+			unhealthy = append(unhealthy, cl)
+		}
+	}
+	return append(healthy, unhealthy...), nil
+}
+
+// Route runs fn on the cluster serving a request. An explicitly named cluster is
+// used as-is; otherwise the routing policy picks one. Clusters with an open
+// circuit are skipped. When retryable is true, a request that fails because a
+// cluster is unreachable is retried on the next candidate; pass false for writes
+// and for requests whose partial output cannot be undone. Behind a Trino
+// Gateway, a retryable request is first retried on the same cluster, without
+// its sticky session, so that the gateway picks another backend.
+func Route[T any](ctx context.Context, s *Clusters, name string, retryable bool, fn func(*Cluster) (T, error)) (T, error) {
+	var zero T
+	candidates, err := s.candidates(name)
+	if err != nil {
+		return zero, err
+	}
+
+	var lastErr error
+	for i, cl := range candidates {
+		if ok, retryAfter := cl.breaker.allow(); !ok {
+			if lastErr == nil {
+				lastErr = &UnavailableError{Cluster: cl.Name, RetryAfter: retryAfter}
+			}
+			continue
+		}
+
+		result, err := fn(cl)
+		if retryable && cl.Config.Gateway && isUnavailable(err) && ctx.Err() == nil {
+			log.Printf("WARNING: Trino Gateway of cluster '%s' found no working backend, retrying: %v", cl.Name, err)
+			cl.Client.resetAffinity()
+			result, err = fn(cl)
+		}
+		cl.breaker.record(err)
+		if !isUnavailable(err) {
+			if err == nil && s.policy != "" {
+				cl.setHealth(nil, false)
+			}
+			return result, err
+		}
+		if s.policy != "" {
+			cl.setHealth(err, false)
+		}
+		if !retryable || ctx.Err() != nil {
+			return result, err
+		}
+		lastErr = err
+		if i < len(candidates)-1 {
+			log.Printf("WARNING: Trino cluster '%s' is unreachable, failing over: %v", cl.Name, err)
+		}
+	}
+	return zero, lastErr
+}
+
+// isUnavailable reports whether err means the cluster could not serve the
+// request at all (connection failures, 5xx responses), as opposed to a query
+// error reported by Trino or a cancelled or timed out request
+func isUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var queryFailed *trino.ErrQueryFailed
+	if errors.As(err, &queryFailed) {
+		return queryFailed.StatusCode == 0 || queryFailed.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// every runs fn in the background immediately and then at each interval until
+// Close is called. A zero interval runs it once.
+func (s *Clusters) every(interval time.Duration, fn func()) {
+	if s.stop == nil {
+		s.stop = make(chan struct{})
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn()
+		if interval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+}
+
+// checkHealth runs one health check against every cluster
+func (s *Clusters) checkHealth() {
+	for _, cl := range s.clusters {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		err := cl.Client.CheckHealth(ctx)
+		cancel()
+		cl.setHealth(err, true)
+	}
+}
@@ -0,0 +1,325 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// unreachable is a connection failure as reported by the driver
+var unreachable = fmt.Errorf("query execution failed: %w",
+	&trino.ErrQueryFailed{Reason: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}})
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"No error", nil, false},
+		{"Connection refused", unreachable, true},
+		{"Service unavailable", &trino.ErrQueryFailed{StatusCode: http.StatusServiceUnavailable}, true},
+		{"Bad request", &trino.ErrQueryFailed{StatusCode: http.StatusBadRequest}, false},
+		{"Query error", errors.New("line 1:8: Column 'x' cannot be resolved"), false},
+		{"Timeout", fmt.Errorf("query execution failed: %w", &trino.ErrQueryFailed{Reason: context.DeadlineExceeded}), false},
+		{"Cancelled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUnavailable(tt.err); got != tt.expected {
+				t.Errorf("isUnavailable(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+// allowed reports whether the breaker lets a request through
+func allowed(b *circuitBreaker) bool {
+	ok, _ := b.allow()
+	return ok
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	// Query errors never open the circuit
+	for i := 0; i < 3; i++ {
+		allowed(b)
+		b.record(errors.New("syntax error"))
+	}
+	if state := b.currentState(); state != CircuitClosed {
+		t.Fatalf("state after query errors = %s, want closed", state)
+	}
+
+	for i := 0; i < 2; i++ {
+		if !allowed(b) {
+			t.Fatalf("request %d rejected while closed", i)
+		}
+		b.record(unreachable)
+	}
+	if state := b.currentState(); state != CircuitOpen {
+		t.Fatalf("state after failures = %s, want open", state)
+	}
+	if ok, retryAfter := b.allow(); ok || retryAfter != time.Minute {
+		t.Fatalf("open circuit: allow() = %v, %v; want rejection with a one minute retry hint", ok, retryAfter)
+	}
+
+	// After the cooldown a single trial request goes through
+	now = now.Add(time.Minute)
+	if !allowed(b) {
+		t.Fatal("trial request rejected after cooldown")
+	}
+	if allowed(b) {
+		t.Fatal("second request allowed while trial in flight")
+	}
+	b.record(unreachable)
+	if allowed(b) {
+		t.Fatal("failed trial should reopen the circuit")
+	}
+
+	now = now.Add(time.Minute)
+	if !allowed(b) {
+		t.Fatal("trial request rejected after second cooldown")
+	}
+	b.record(nil)
+	if state := b.currentState(); state != CircuitClosed {
+		t.Fatalf("state after successful trial = %s, want closed", state)
+	}
+
+	// A disabled breaker lets everything through
+	var disabled *circuitBreaker
+	disabled.record(unreachable)
+	if !allowed(disabled) || disabled.currentState() != CircuitClosed {
+		t.Error("nil breaker should always allow requests")
+	}
+}
+
+// testClusters builds a routed cluster set without Trino clients
+func testClusters(policy string, threshold int, names ...string) *Clusters {
+	set := &Clusters{defaultName: names[0]}
+	for _, name := range names {
+		set.clusters = append(set.clusters, &Cluster{Name: name, Config: &config.TrinoConfig{}})
+	}
+	set.configureRouting(&config.TrinoConfig{
+		RoutingPolicy:    policy,
+		BreakerThreshold: threshold,
+		BreakerCooldown:  time.Minute,
+	})
+	return set
+}
+
+func TestRouteFailover(t *testing.T) {
+	set := testClusters(config.RoutingFailover, 1, "primary", "secondary")
+	ctx := context.Background()
+
+	var tried []string
+	name, err := Route(ctx, set, "", true, func(cl *Cluster) (string, error) {
+		tried = append(tried, cl.Name)
+		if cl.Name == "primary" {
+			return "", unreachable
+		}
+		return cl.Name, nil
+	})
+	if err != nil || name != "secondary" {
+		t.Fatalf("Route() = %q, %v; want secondary", name, err)
+	}
+	if len(tried) != 2 {
+		t.Errorf("tried %v, want primary then secondary", tried)
+	}
+	if status := set.clusters[0].Status(); status.Healthy || status.Circuit != CircuitOpen {
+		t.Errorf("primary status = %+v, want unhealthy with open circuit", status)
+	}
+
+	// The open circuit keeps requests away from the primary
+	tried = nil
+	if _, err := Route(ctx, set, "", true, func(cl *Cluster) (string, error) {
+		tried = append(tried, cl.Name)
+		return cl.Name, nil
+	}); err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if len(tried) != 1 || tried[0] != "secondary" {
+		t.Errorf("tried %v, want only secondary", tried)
+	}
+
+	// An explicitly named cluster fails fast instead of failing over
+	if _, err := Route(ctx, set, "primary", true, func(cl *Cluster) (string, error) {
+		t.Fatal("request sent to a cluster with an open circuit")
+		return "", nil
+	}); err == nil {
+		t.Error("Route(primary) expected circuit open error")
+	}
+}
+
+func TestRouteDoesNotRetryQueryErrorsOrWrites(t *testing.T) {
+	set := testClusters(config.RoutingFailover, 3, "primary", "secondary")
+	ctx := context.Background()
+
+	calls := 0
+	_, err := Route(ctx, set, "", true, func(cl *Cluster) (string, error) {
+		calls++
+		return "", errors.New("line 1:8: Column 'x' cannot be resolved")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("query error: calls = %d, err = %v; want one call and the error", calls, err)
+	}
+
+	calls = 0
+	_, err = Route(ctx, set, "", false, func(cl *Cluster) (string, error) {
+		calls++
+		return "", unreachable
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("non-retryable: calls = %d, err = %v; want one call and the error", calls, err)
+	}
+}
+
+func TestRouteRoundRobin(t *testing.T) {
+	set := testClusters(config.RoutingRoundRobin, 3, "a", "b", "c")
+	var served []string
+	for i := 0; i < 4; i++ {
+		name, err := Route(context.Background(), set, "", true, func(cl *Cluster) (string, error) {
+			return cl.Name, nil
+		})
+		if err != nil {
+			t.Fatalf("Route() error = %v", err)
+		}
+		served = append(served, name)
+	}
+	if fmt.Sprint(served) != "[a b c a]" {
+		t.Errorf("served = %v, want [a b c a]", served)
+	}
+
+	// Unhealthy clusters move to the back of the rotation
+	set.clusters[1].setHealth(unreachable, true)
+	candidates, err := set.candidates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := candidates[len(candidates)-1].Name; last != "b" {
+		t.Errorf("last candidate = %s, want unhealthy cluster b", last)
+	}
+}
+
+func TestRouteWithoutPolicy(t *testing.T) {
+	set := testClusters(config.RoutingNone, 3, "primary", "secondary")
+	calls := 0
+	_, err := Route(context.Background(), set, "", true, func(cl *Cluster) (string, error) {
+		calls++
+		return "", unreachable
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("calls = %d, err = %v; want a single attempt on the default cluster", calls, err)
+	}
+
+	// The circuit breaker still applies: after the threshold, calls fail fast
+	for i := 0; i < 2; i++ {
+		_, _ = Route(context.Background(), set, "", true, func(cl *Cluster) (string, error) {
+			return "", unreachable
+		})
+	}
+	_, err = Route(context.Background(), set, "", true, func(cl *Cluster) (string, error) {
+		t.Fatal("request sent while the circuit is open")
+		return "", nil
+	})
+	var unavailable *UnavailableError
+	if !errors.As(err, &unavailable) || unavailable.Cluster != "primary" || unavailable.RetryAfter <= 0 {
+		t.Errorf("Route() error = %v, want UnavailableError with a retry hint", err)
+	}
+	if !strings.Contains(err.Error(), "Trino unavailable") || !strings.Contains(err.Error(), "retry after 1m0s") {
+		t.Errorf("error message = %q", err.Error())
+	}
+	if _, err := Route(context.Background(), set, "missing", true, func(cl *Cluster) (string, error) {
+		return cl.Name, nil
+	}); err == nil {
+		t.Error("Route(missing) expected unknown cluster error")
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		body        string
+		expectError bool
+	}{
+		{"Healthy", http.StatusOK, `{"nodeVersion":{"version":"476"},"starting":false}`, false},
+		{"Starting", http.StatusOK, `{"starting":true}`, true},
+		{"Server error", http.StatusServiceUnavailable, ``, true},
+		{"Invalid body", http.StatusOK, `not json`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/info" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			serverURL, _ := url.Parse(server.URL)
+			port, _ := strconv.Atoi(serverURL.Port())
+			client := &Client{
+				config:     &config.TrinoConfig{Scheme: "http", Host: serverURL.Hostname(), Port: port},
+				httpClient: server.Client(),
+			}
+			err := client.CheckHealth(context.Background())
+			if tt.expectError && err == nil {
+				t.Error("CheckHealth() expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("CheckHealth() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRouteRetriesBehindGateway(t *testing.T) {
+	set := testClusters(config.RoutingNone, 3, "gateway")
+	set.clusters[0].Config.Gateway = true
+	jar := newAffinityJar()
+	set.clusters[0].Client = &Client{config: set.clusters[0].Config, jar: jar}
+	gatewayURL, _ := url.Parse("https://gateway.example.com/v1/statement")
+	jar.SetCookies(gatewayURL, []*http.Cookie{{Name: "trino-backend", Value: "b1"}})
+
+	calls := 0
+	name, err := Route(context.Background(), set, "", true, func(cl *Cluster) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", unreachable
+		}
+		return cl.Name, nil
+	})
+	if err != nil || name != "gateway" || calls != 2 {
+		t.Fatalf("Route() = %q, %v after %d calls; want a retry on the gateway", name, err, calls)
+	}
+	if cookies := jar.Cookies(gatewayURL); len(cookies) != 0 {
+		t.Errorf("cookies after retry = %v, want the sticky session dropped", cookies)
+	}
+
+	// Writes are not retried
+	calls = 0
+	if _, err := Route(context.Background(), set, "", false, func(cl *Cluster) (string, error) {
+		calls++
+		return "", unreachable
+	}); err == nil || calls != 1 {
+		t.Errorf("non-retryable: calls = %d, err = %v; want one call and the error", calls, err)
+	}
+}
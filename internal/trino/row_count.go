@@ -0,0 +1,114 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// Row count estimation methods
+const (
+	RowCountStats  = "stats"  // Table statistics of the cost-based optimizer (SHOW STATS)
+	RowCountSample = "sample" // count(*) of a TABLESAMPLE BERNOULLI sample, scaled up
+	RowCountExact  = "count"  // count(*) of every row
+)
+
+// RowCountOptions controls how EstimateRowCountWithContext counts
+type RowCountOptions struct {
+	Where         string  // Filter, e.g. a partition: ds = '2024-01-01'
+	SamplePercent float64 // Sample this percentage of rows when statistics are unavailable; 0 counts every row
+	Exact         bool    // Skip statistics and count every row
+}
+
+// RowCountEstimate is the estimated number of rows of a table
+type RowCountEstimate struct {
+	Table         string   `json:"table"`
+	Where         string   `json:"where,omitempty"`
+	Rows          int64    `json:"rows"`
+	Method        string   `json:"method"`
+	Exact         bool     `json:"exact"`
+	SamplePercent float64  `json:"samplePercent,omitempty"`
+	Queries       []string `json:"queries"` // The SQL that was run
+}
+
+// EstimateRowCountWithContext estimates the rows of a table, or of the rows
+// matching a filter. Table statistics (SHOW STATS) are preferred since they
+// cost no scan; without statistics the rows are counted, in a sample when
+// opts.SamplePercent is set and the connector supports TABLESAMPLE.
+func (c *Client) EstimateRowCountWithContext(ctx context.Context, table string, opts RowCountOptions) (*RowCountEstimate, error) {
+	if opts.SamplePercent < 0 || opts.SamplePercent > 100 {
+		return nil, fmt.Errorf("invalid sample percentage %g: must be between 0 and 100", opts.SamplePercent)
+	}
+	ref, err := c.parseDiffTable(table)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkTableAccess(ref.Catalog, ref.Schema, ref.Table); err != nil {
+		return nil, err
+	}
+	where := strings.TrimSuffix(strings.TrimSpace(opts.Where), ";")
+	if err := c.validateFilter("filter", where, ref); err != nil {
+		return nil, err
+	}
+	estimate := &RowCountEstimate{Table: ref.String(), Where: where}
+
+	if !opts.Exact {
+		query := fmt.Sprintf("SHOW STATS FOR (SELECT * FROM %s%s)", quoteTable(ref), whereClause(where))
+		estimate.Queries = append(estimate.Queries, query)
+		rows, err := c.ExecuteQueryWithContext(ctx, query)
+		if err != nil {
+			log.Printf("INFO: statistics unavailable for %s, counting rows: %v", estimate.Table, err)
+		}
+		if rows, ok := statsRowCount(rows); ok {
+			estimate.Rows, estimate.Method = rows, RowCountStats
+			return estimate, nil
+		}
+	}
+
+	if opts.SamplePercent > 0 && opts.SamplePercent < 100 {
+		query := fmt.Sprintf("SELECT count(*) AS row_count FROM %s", distributionSource(ref, where, opts.SamplePercent))
+		estimate.Queries = append(estimate.Queries, query)
+		rows, err := c.ExecuteQueryWithContext(ctx, query)
+		switch {
+		case err == nil:
+			estimate.Method, estimate.SamplePercent = RowCountSample, opts.SamplePercent
+			if len(rows) == 1 {
+				estimate.Rows = int64(math.Round(float64(int64Value(rows[0]["row_count"])) * 100 / opts.SamplePercent))
+			}
+			return estimate, nil
+		case !isSamplingUnsupported(err):
+			return nil, fmt.Errorf("failed to count rows: %w", err)
+		}
+		log.Printf("INFO: TABLESAMPLE not supported for %s, counting every row: %v", estimate.Table, err)
+	}
+
+	query := fmt.Sprintf("SELECT count(*) AS row_count FROM %s", distributionSource(ref, where, 0))
+	estimate.Queries = append(estimate.Queries, query)
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rows: %w", err)
+	}
+	estimate.Method, estimate.Exact = RowCountExact, true
+	if len(rows) == 1 {
+		estimate.Rows = int64Value(rows[0]["row_count"])
+	}
+	return estimate, nil
+}
+
+// statsRowCount returns the row count of the summary row of SHOW STATS, the
+// one without a column name; connectors without statistics report NULL
+func statsRowCount(rows []map[string]interface{}) (int64, bool) {
+	for _, row := range rows {
+		if row["column_name"] != nil {
+			continue
+		}
+		count, ok := row["row_count"].(float64)
+		if !ok || math.IsNaN(count) || math.IsInf(count, 0) {
+			return 0, false
+		}
+		return int64(math.Round(count)), true
+	}
+	return 0, false
+}
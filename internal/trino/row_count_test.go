@@ -0,0 +1,65 @@
+package trino
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestStatsRowCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []map[string]interface{}
+		want   int64
+		wantOK bool
+	}{
+		{"Summary row", []map[string]interface{}{
+			{"column_name": "id", "row_count": nil, "distinct_values_count": 1500.0},
+			{"column_name": nil, "row_count": 1500.0},
+		}, 1500, true},
+		{"Filtered estimate", []map[string]interface{}{{"column_name": nil, "row_count": 212.6}}, 213, true},
+		{"No statistics", []map[string]interface{}{{"column_name": "id"}, {"column_name": nil, "row_count": nil}}, 0, false},
+		{"Unknown estimate", []map[string]interface{}{{"column_name": nil, "row_count": math.NaN()}}, 0, false},
+		{"No rows", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := statsRowCount(tt.rows)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("statsRowCount() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEstimateRowCountRejectsInvalidOptions(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "sales", AllowedCatalogs: []string{"hive"}}}
+	ctx := context.Background()
+
+	if _, err := client.EstimateRowCountWithContext(ctx, "orders", RowCountOptions{SamplePercent: 150}); err == nil {
+		t.Error("a sample percentage above 100 should fail")
+	}
+	_, err := client.EstimateRowCountWithContext(ctx, "postgres.sales.orders", RowCountOptions{})
+	if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorPermissionDenied {
+		t.Errorf("EstimateRowCountWithContext() outside the allowlist error = %v, want %s", err, ErrorPermissionDenied)
+	}
+
+	// A subquery filter would count rows of tables outside the allowlists
+	for _, where := range []string{
+		"EXISTS (SELECT 1 FROM postgres.bank.accounts WHERE balance > 1000000)",
+		"true) UNION ALL (SELECT * FROM postgres.bank.accounts",
+	} {
+		_, err := client.EstimateRowCountWithContext(ctx, "orders", RowCountOptions{Where: where})
+		if err == nil || !strings.Contains(err.Error(), "invalid filter") {
+			t.Errorf("EstimateRowCountWithContext(where %q) error = %v, want a rejection", where, err)
+		}
+	}
+	masked := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "sales",
+		ColumnMasks: map[string]string{"hive.sales.orders.card": config.MaskDrop}}}
+	if _, err := masked.EstimateRowCountWithContext(ctx, "orders", RowCountOptions{Where: "card LIKE '4%'"}); err == nil || !strings.Contains(err.Error(), "masked column 'card'") {
+		t.Errorf("EstimateRowCountWithContext() filtering on a masked column error = %v, want a rejection", err)
+	}
+}
@@ -0,0 +1,253 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// SchemaDiff lists how the columns of table or schema B differ from those of A
+type SchemaDiff struct {
+	A             string      `json:"a"`
+	B             string      `json:"b"`
+	Identical     bool        `json:"identical"`
+	AddedTables   []string    `json:"addedTables,omitempty"`   // Tables only in B, when comparing schemas
+	RemovedTables []string    `json:"removedTables,omitempty"` // Tables only in A, when comparing schemas
+	Tables        []TableDiff `json:"tables,omitempty"`        // Tables in both whose columns differ
+}
+
+// TableDiff lists the column differences of one table
+type TableDiff struct {
+	Table   string             `json:"table"`
+	Added   []ColumnDefinition `json:"added,omitempty"`   // Columns only in B
+	Removed []ColumnDefinition `json:"removed,omitempty"` // Columns only in A
+	Changed []ColumnChange     `json:"changed,omitempty"` // Columns whose type or nullability differ
+}
+
+// ColumnDefinition is a column as listed by information_schema.columns
+type ColumnDefinition struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// ColumnChange is a column present in both tables with a different definition
+type ColumnChange struct {
+	Name      string `json:"name"`
+	TypeA     string `json:"typeA"`
+	TypeB     string `json:"typeB"`
+	NullableA bool   `json:"nullableA"`
+	NullableB bool   `json:"nullableB"`
+}
+
+// DiffSchemasWithContext compares the column names, types and nullability of
+// two tables (catalog.schema.table), or of all tables in two schemas
+// (catalog.schema), e.g. staging against production. Tables outside the
+// allowlists and dropped masked columns are left out of the comparison.
+func (c *Client) DiffSchemasWithContext(ctx context.Context, a, b string) (*SchemaDiff, error) {
+	refA, err := parseDiffRef(a)
+	if err != nil {
+		return nil, err
+	}
+	refB, err := parseDiffRef(b)
+	if err != nil {
+		return nil, err
+	}
+	if (refA.Table == "") != (refB.Table == "") {
+		return nil, errors.New("compare two tables (catalog.schema.table) or two schemas (catalog.schema), not a table with a schema")
+	}
+	for _, ref := range []TableRef{refA, refB} {
+		if err := c.checkDiffAccess(ref); err != nil {
+			return nil, err
+		}
+	}
+
+	columnsA, err := c.schemaColumns(ctx, refA)
+	if err != nil {
+		return nil, err
+	}
+	columnsB, err := c.schemaColumns(ctx, refB)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SchemaDiff{A: diffRefName(refA), B: diffRefName(refB)}
+	if refA.Table != "" {
+		if len(columnsA[refA.Table]) == 0 {
+			return nil, fmt.Errorf("table %s not found", diffRefName(refA))
+		}
+		if len(columnsB[refB.Table]) == 0 {
+			return nil, fmt.Errorf("table %s not found", diffRefName(refB))
+		}
+		if td := diffColumns(refB.Table, columnsA[refA.Table], columnsB[refB.Table]); td != nil {
+			diff.Tables = append(diff.Tables, *td)
+		}
+	} else {
+		for _, table := range sortedKeys(columnsA) {
+			if _, ok := columnsB[table]; !ok {
+				diff.RemovedTables = append(diff.RemovedTables, table)
+			} else if td := diffColumns(table, columnsA[table], columnsB[table]); td != nil {
+				diff.Tables = append(diff.Tables, *td)
+			}
+		}
+		for _, table := range sortedKeys(columnsB) {
+			if _, ok := columnsA[table]; !ok {
+				diff.AddedTables = append(diff.AddedTables, table)
+			}
+		}
+	}
+	diff.Identical = len(diff.Tables) == 0 && len(diff.AddedTables) == 0 && len(diff.RemovedTables) == 0
+	return diff, nil
+}
+
+// parseDiffRef parses catalog.schema.table or catalog.schema
+func parseDiffRef(ref string) (TableRef, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(ref)), ".")
+	for _, part := range parts {
+		if part == "" {
+			return TableRef{}, fmt.Errorf("invalid reference '%s': expected catalog.schema.table or catalog.schema", ref)
+		}
+	}
+	switch len(parts) {
+	case 3:
+		return TableRef{Catalog: parts[0], Schema: parts[1], Table: parts[2]}, nil
+	case 2:
+		return TableRef{Catalog: parts[0], Schema: parts[1]}, nil
+	default:
+		return TableRef{}, fmt.Errorf("invalid reference '%s': expected catalog.schema.table or catalog.schema", ref)
+	}
+}
+
+// diffRefName returns catalog.schema.table, or catalog.schema for a schema
+func diffRefName(ref TableRef) string {
+	if ref.Table == "" {
+		return ref.Catalog + "." + ref.Schema
+	}
+	return ref.String()
+}
+
+// checkDiffAccess checks a compared table, or the catalog and schema of a
+// compared schema, against the allowlists
+func (c *Client) checkDiffAccess(ref TableRef) error {
+	if ref.Table != "" {
+		return c.checkTableAccess(ref.Catalog, ref.Schema, ref.Table)
+	}
+	policy := c.currentPolicy()
+	if len(policy.AllowedCatalogs) > 0 && !c.isCatalogAllowed(ref.Catalog) {
+		return accessDenied("catalog access denied: %s not in allowlist", ref.Catalog)
+	}
+	if len(policy.AllowedSchemas) > 0 && !c.isSchemaAllowed(ref.Catalog, ref.Schema) {
+		return accessDenied("schema access denied: %s.%s not in allowlist", ref.Catalog, ref.Schema)
+	}
+	return nil
+}
+
+// schemaColumns reads the columns of a table, or of every table in a schema,
+// by table name in ordinal order
+func (c *Client) schemaColumns(ctx context.Context, ref TableRef) (map[string][]ColumnDefinition, error) {
+	tables, err := c.readColumns(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return c.visibleColumns(ref, tables), nil
+}
+
+// readColumns reads the columns of a table, or of every table in a schema,
+// from information_schema.columns without applying the allowlists
+func (c *Client) readColumns(ctx context.Context, ref TableRef) (map[string][]ColumnDefinition, error) {
+	where := "table_schema = " + quoteLiteral(ref.Schema)
+	if ref.Table != "" {
+		where += " AND table_name = " + quoteLiteral(ref.Table)
+	}
+	query := fmt.Sprintf(`SELECT table_name, column_name, data_type, is_nullable
+FROM %s.information_schema.columns
+WHERE %s
+ORDER BY table_name, ordinal_position`, quoteIdentifier(ref.Catalog), where)
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", diffRefName(ref), err)
+	}
+
+	tables := make(map[string][]ColumnDefinition)
+	for _, row := range rows {
+		table := stringValue(row["table_name"])
+		tables[table] = append(tables[table], ColumnDefinition{
+			Name:     stringValue(row["column_name"]),
+			Type:     stringValue(row["data_type"]),
+			Nullable: stringValue(row["is_nullable"]) == "YES",
+		})
+	}
+	return tables, nil
+}
+
+// visibleColumns leaves out the tables outside the allowlists and the
+// dropped masked columns of a column listing of ref's schema
+func (c *Client) visibleColumns(ref TableRef, tables map[string][]ColumnDefinition) map[string][]ColumnDefinition {
+	masks := c.currentPolicy().ColumnMasks
+	visible := make(map[string][]ColumnDefinition, len(tables))
+	for table, columns := range tables {
+		if c.checkTableAccess(ref.Catalog, ref.Schema, table) != nil {
+			continue // Not exposed by the allowlists
+		}
+		for _, col := range columns {
+			if masks[strings.ToLower(ref.Catalog+"."+ref.Schema+"."+table+"."+col.Name)] == config.MaskDrop {
+				continue
+			}
+			visible[table] = append(visible[table], col)
+		}
+	}
+	return visible
+}
+
+// diffColumns compares the columns of a table in A and B, returning nil when
+// they match. Removed and changed columns follow A's order, added ones B's.
+func diffColumns(table string, a, b []ColumnDefinition) *TableDiff {
+	inA := make(map[string]ColumnDefinition, len(a))
+	for _, col := range a {
+		inA[strings.ToLower(col.Name)] = col
+	}
+	inB := make(map[string]ColumnDefinition, len(b))
+	for _, col := range b {
+		inB[strings.ToLower(col.Name)] = col
+	}
+
+	td := &TableDiff{Table: table}
+	for _, colA := range a {
+		colB, ok := inB[strings.ToLower(colA.Name)]
+		switch {
+		case !ok:
+			td.Removed = append(td.Removed, colA)
+		case !strings.EqualFold(colA.Type, colB.Type) || colA.Nullable != colB.Nullable:
+			td.Changed = append(td.Changed, ColumnChange{
+				Name:      colA.Name,
+				TypeA:     colA.Type,
+				TypeB:     colB.Type,
+				NullableA: colA.Nullable,
+				NullableB: colB.Nullable,
+			})
+		}
+	}
+	for _, colB := range b {
+		if _, ok := inA[strings.ToLower(colB.Name)]; !ok {
+			td.Added = append(td.Added, colB)
+		}
+	}
+	if len(td.Added) == 0 && len(td.Removed) == 0 && len(td.Changed) == 0 {
+		return nil
+	}
+	return td
+}
+
+// sortedKeys returns the table names of a column listing in order
+func sortedKeys(tables map[string][]ColumnDefinition) []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,81 @@
+package trino
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestParseDiffRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    TableRef
+		wantErr bool
+	}{
+		{ref: "hive.prod.Orders", want: TableRef{Catalog: "hive", Schema: "prod", Table: "orders"}},
+		{ref: " hive.staging ", want: TableRef{Catalog: "hive", Schema: "staging"}},
+		{ref: "orders", wantErr: true},
+		{ref: "hive..orders", wantErr: true},
+		{ref: "a.b.c.d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := parseDiffRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDiffRef(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseDiffRef(%q) = %+v, want %+v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffColumns(t *testing.T) {
+	a := []ColumnDefinition{
+		{Name: "id", Type: "bigint"},
+		{Name: "amount", Type: "decimal(10,2)", Nullable: true},
+		{Name: "status", Type: "varchar", Nullable: true},
+		{Name: "legacy_flag", Type: "boolean", Nullable: true},
+	}
+	b := []ColumnDefinition{
+		{Name: "id", Type: "BIGINT"},
+		{Name: "amount", Type: "decimal(12,2)", Nullable: true},
+		{Name: "status", Type: "varchar"},
+		{Name: "created_at", Type: "timestamp(3)", Nullable: true},
+	}
+
+	got := diffColumns("orders", a, b)
+	want := &TableDiff{
+		Table:   "orders",
+		Added:   []ColumnDefinition{{Name: "created_at", Type: "timestamp(3)", Nullable: true}},
+		Removed: []ColumnDefinition{{Name: "legacy_flag", Type: "boolean", Nullable: true}},
+		Changed: []ColumnChange{
+			{Name: "amount", TypeA: "decimal(10,2)", TypeB: "decimal(12,2)", NullableA: true, NullableB: true},
+			{Name: "status", TypeA: "varchar", TypeB: "varchar", NullableA: true, NullableB: false},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffColumns() = %+v, want %+v", got, want)
+	}
+
+	if got := diffColumns("orders", a, a); got != nil {
+		t.Errorf("diffColumns() of identical columns = %+v, want nil", got)
+	}
+}
+
+func TestDiffSchemasRejectsInvalidPairs(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{AllowedCatalogs: []string{"hive"}}}
+	ctx := context.Background()
+
+	if _, err := client.DiffSchemasWithContext(ctx, "hive.prod.orders", "hive.staging"); err == nil {
+		t.Error("comparing a table with a schema should fail")
+	}
+	_, err := client.DiffSchemasWithContext(ctx, "hive.prod", "postgres.prod")
+	if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorPermissionDenied {
+		t.Errorf("DiffSchemasWithContext() outside the allowlist error = %v, want %s", err, ErrorPermissionDenied)
+	}
+}
@@ -0,0 +1,140 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// MaxDumpTables bounds the tables DumpSchemaWithContext describes
+	MaxDumpTables = 500
+
+	// dumpWorkers bounds the metadata queries a dump runs at once
+	dumpWorkers = 8
+)
+
+// SchemaDump is the definition of every table in a catalog or schema
+type SchemaDump struct {
+	Catalog    string             `json:"catalog"`
+	Schemas    []SchemaDefinition `json:"schemas"`
+	TableCount int                `json:"tableCount"`
+	Truncated  bool               `json:"truncated,omitempty"` // More than MaxDumpTables tables were found
+}
+
+// SchemaDefinition holds the tables of one schema
+type SchemaDefinition struct {
+	Name   string            `json:"name"`
+	Tables []TableDefinition `json:"tables"`
+	Error  string            `json:"error,omitempty"` // Why the tables could not be listed
+}
+
+// TableDefinition holds the columns of one table as returned by get_table_schema
+type TableDefinition struct {
+	Name    string                   `json:"name"`
+	Columns []map[string]interface{} `json:"columns,omitempty"`
+	Error   string                   `json:"error,omitempty"` // Why the table could not be described
+}
+
+// DumpSchemaWithContext describes every table of a schema, or of all schemas
+// of a catalog except information_schema, within the allowlists. Tables are
+// listed and described concurrently by a bounded pool of workers. A table or
+// schema that cannot be read is reported with its error instead of failing
+// the dump; at most MaxDumpTables tables are described.
+func (c *Client) DumpSchemaWithContext(ctx context.Context, catalog, schema string) (*SchemaDump, error) {
+	if catalog == "" {
+		catalog = c.config.Catalog
+	}
+	policy := c.currentPolicy()
+	if len(policy.AllowedCatalogs) > 0 && !c.isCatalogAllowed(catalog) {
+		return nil, accessDenied("catalog access denied: %s not in allowlist", catalog)
+	}
+
+	var schemas []string
+	if schema != "" {
+		if len(policy.AllowedSchemas) > 0 && !c.isSchemaAllowed(catalog, schema) {
+			return nil, accessDenied("schema access denied: %s.%s not in allowlist", catalog, schema)
+		}
+		schemas = []string{schema}
+	} else {
+		all, err := c.ListSchemasWithContext(ctx, catalog)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range all {
+			if !strings.EqualFold(name, "information_schema") {
+				schemas = append(schemas, name)
+			}
+		}
+	}
+	sort.Strings(schemas)
+
+	// List the tables of every schema
+	dump := &SchemaDump{Catalog: catalog, Schemas: make([]SchemaDefinition, len(schemas))}
+	tables := make([][]string, len(schemas))
+	forEach(ctx, len(schemas), func(i int) {
+		dump.Schemas[i].Name = schemas[i]
+		list, err := c.ListTablesWithContext(ctx, catalog, schemas[i])
+		if err != nil {
+			dump.Schemas[i].Error = err.Error()
+			return
+		}
+		sort.Strings(list)
+		tables[i] = list
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Describe the tables, up to MaxDumpTables in schema and table order
+	type tableRef struct{ schema, table int }
+	var refs []tableRef
+	for i := range schemas {
+		dump.Schemas[i].Tables = make([]TableDefinition, 0, len(tables[i]))
+		for j, name := range tables[i] {
+			if len(refs) == MaxDumpTables {
+				dump.Truncated = true
+				break
+			}
+			dump.Schemas[i].Tables = append(dump.Schemas[i].Tables, TableDefinition{Name: name})
+			refs = append(refs, tableRef{i, j})
+		}
+	}
+	forEach(ctx, len(refs), func(k int) {
+		def := &dump.Schemas[refs[k].schema].Tables[refs[k].table]
+		columns, err := c.GetTableSchemaWithContext(ctx, catalog, schemas[refs[k].schema], def.Name)
+		if err != nil {
+			def.Error = err.Error()
+			return
+		}
+		def.Columns = columns
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("schema dump interrupted: %w", err)
+	}
+	dump.TableCount = len(refs)
+	return dump, nil
+}
+
+// forEach calls fn with every index below n on at most dumpWorkers
+// goroutines, skipping the remaining indexes once ctx is done
+func forEach(ctx context.Context, n int, fn func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < dumpWorkers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
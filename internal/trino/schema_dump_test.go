@@ -0,0 +1,71 @@
+package trino
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestForEachBoundsWorkers(t *testing.T) {
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+
+	forEach(context.Background(), 50, func(i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+	})
+
+	if len(seen) != 50 {
+		t.Errorf("forEach() visited %d indexes, want 50", len(seen))
+	}
+	if peak.Load() > dumpWorkers {
+		t.Errorf("forEach() ran %d calls at once, want at most %d", peak.Load(), dumpWorkers)
+	}
+}
+
+func TestForEachStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	forEach(ctx, 1000, func(int) {
+		if calls.Add(1) == 1 {
+			cancel()
+		}
+	})
+	if calls.Load() > dumpWorkers+1 {
+		t.Errorf("forEach() made %d calls after cancellation, want at most %d", calls.Load(), dumpWorkers+1)
+	}
+}
+
+func TestDumpSchemaAllowlists(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:         "hive",
+		AllowedCatalogs: []string{"hive"},
+		AllowedSchemas:  []string{"hive.sales"},
+	}}
+
+	for _, tt := range []struct{ catalog, schema string }{
+		{"postgres", ""},
+		{"hive", "hr"},
+	} {
+		_, err := client.DumpSchemaWithContext(context.Background(), tt.catalog, tt.schema)
+		if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorPermissionDenied {
+			t.Errorf("DumpSchemaWithContext(%q, %q) error = %v, want %s", tt.catalog, tt.schema, err, ErrorPermissionDenied)
+		}
+	}
+}
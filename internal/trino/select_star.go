@@ -0,0 +1,133 @@
+package trino
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// selectStar is a * or alias.* in a select list
+type selectStar struct {
+	token     int          // Index of the * token
+	qualifier string       // Alias or table name of alias.*; empty for a bare *
+	tables    []*lintTable // Tables whose columns it selects, when known
+}
+
+// selectStars finds the * and alias.* of a statement's select lists. A bare
+// * selects every table the statement reads; alias.* the named table, unless
+// it names a subquery or WITH clause.
+func selectStars(tokens []sqlToken, tables []*lintTable, names map[string]*lintTable) []selectStar {
+	var stars []selectStar
+	for i := 1; i < len(tokens); i++ {
+		prev := tokens[i-1]
+		if tokens[i].text != "*" {
+			continue
+		}
+		switch {
+		case prev.keyword("select") || prev.keyword("distinct") || prev.keyword("all") || prev.text == ",":
+			stars = append(stars, selectStar{token: i, tables: tables})
+		case prev.text == "." && i >= 2 && tokens[i-2].ident:
+			star := selectStar{token: i, qualifier: tokens[i-2].text}
+			if table := names[star.qualifier]; table != nil {
+				star.tables = []*lintTable{table}
+			}
+			stars = append(stars, star)
+		}
+	}
+	return stars
+}
+
+// checkSelectStar applies TRINO_SELECT_STAR_MAX_COLUMNS and
+// TRINO_SELECT_STAR_EXPAND to a query an agent wrote. SELECT * on a table
+// with more visible columns than the limit is rejected, so agents do not
+// transfer every column of a wide table when they need a few. With expansion
+// on, the * of a query reading a single table, and alias.* of a table, are
+// rewritten into the explicit list of the table's visible columns, from the
+// cached DESCRIBE output; other stars are left as written. Tables whose
+// columns cannot be looked up are not checked.
+func (c *Client) checkSelectStar(ctx context.Context, query string) (string, error) {
+	maxColumns, expand := c.config.SelectStarMaxColumns, c.config.SelectStarExpand
+	if maxColumns == 0 && !expand {
+		return query, nil
+	}
+	tokens := tokenizeSQL(query)
+	if !containsStar(tokens) {
+		return query, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lintLookupTime)
+	defer cancel()
+	statement := statementType(tokens)
+	tables, names, _ := c.readTables(ctx, tokens, statement)
+	stars := selectStars(tokens, tables, names)
+	masks := c.currentPolicy().ColumnMasks
+
+	if maxColumns > 0 {
+		for _, star := range stars {
+			for _, table := range star.tables {
+				if visible := len(table.visibleColumns(masks)); visible > maxColumns {
+					return "", policyError(ErrorQueryRejected, "Select only the columns you need; get_table_schema lists them",
+						"SELECT * on a wide table: %s has %d columns, more than the limit of %d (TRINO_SELECT_STAR_MAX_COLUMNS)",
+						table.ref, visible, maxColumns)
+				}
+			}
+		}
+	}
+	if !expand || statement != "select" {
+		return query, nil
+	}
+
+	// A bare * is only expanded when it cannot select the columns of a
+	// subquery or WITH clause instead of the table
+	simple := len(tables) == 1 && len(cteNames(tokens)) == 0 && subqueryDepth(tokens) == 0
+	type replacement struct {
+		start, end int
+		text       string
+	}
+	var replacements []replacement
+	for _, star := range stars {
+		if len(star.tables) != 1 || star.qualifier == "" && !simple {
+			continue
+		}
+		visible := star.tables[0].visibleColumns(masks)
+		if len(visible) == 0 {
+			continue
+		}
+		// The qualifier and dot before alias.* apply to the first column
+		prefix := ""
+		if star.qualifier != "" {
+			prefix = star.qualifier + "."
+			if tokens[star.token-2].quoted {
+				prefix = quoteIdentifier(star.qualifier) + "."
+			}
+		}
+		columns := make([]string, len(visible))
+		for i, col := range visible {
+			columns[i] = quoteIdentifier(col.name)
+			if i > 0 {
+				columns[i] = prefix + columns[i]
+			}
+		}
+		end := tokens[star.token].end
+		replacements = append(replacements, replacement{start: end - 1, end: end, text: strings.Join(columns, ", ")})
+	}
+	if len(replacements) == 0 {
+		return query, nil
+	}
+
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].start > replacements[j].start })
+	for _, r := range replacements {
+		query = query[:r.start] + r.text + query[r.end:]
+	}
+	return query, nil
+}
+
+// containsStar reports whether a statement has a * token
+func containsStar(tokens []sqlToken) bool {
+	for _, token := range tokens {
+		if token.text == "*" {
+			return true
+		}
+	}
+	return false
+}
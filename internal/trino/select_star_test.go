@@ -0,0 +1,74 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestCheckSelectStar(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:              "hive",
+		Schema:               "sales",
+		SelectStarMaxColumns: 3,
+		SelectStarExpand:     true,
+		ColumnMasks:          map[string]string{"hive.sales.customers.ssn": config.MaskDrop},
+	}}
+	ctx := context.Background()
+	client.metadata.putColumns(metadataKey(ctx, "describe", "hive", "sales", "customers"), []describedColumn{
+		{name: "id", typ: "bigint"},
+		{name: "name", typ: "varchar"},
+		{name: "ssn", typ: "varchar"},
+		{name: "region", typ: "varchar"},
+	})
+	client.metadata.putColumns(metadataKey(ctx, "describe", "hive", "sales", "orders"), []describedColumn{
+		{name: "id", typ: "bigint"},
+		{name: "customer_id", typ: "bigint"},
+	})
+	client.metadata.putColumns(metadataKey(ctx, "describe", "hive", "sales", "events"), []describedColumn{
+		{name: "a", typ: "bigint"}, {name: "b", typ: "bigint"}, {name: "c", typ: "bigint"}, {name: "d", typ: "bigint"},
+	})
+
+	tests := []struct {
+		name        string
+		query       string
+		want        string
+		expectError string
+	}{
+		{"Single table", "SELECT * FROM orders", `SELECT "id", "customer_id" FROM orders`, ""},
+		{"Dropped column not counted or expanded", "SELECT * FROM customers WHERE region = 'EU'", `SELECT "id", "name", "region" FROM customers WHERE region = 'EU'`, ""},
+		{"Qualified star", "SELECT o.*, c.name FROM orders o JOIN customers c ON o.customer_id = c.id",
+			`SELECT o."id", o."customer_id", c.name FROM orders o JOIN customers c ON o.customer_id = c.id`, ""},
+		{"Bare star of a join is kept", "SELECT * FROM orders o JOIN customers c ON o.customer_id = c.id",
+			"SELECT * FROM orders o JOIN customers c ON o.customer_id = c.id", ""},
+		{"Star of a subquery is kept", "SELECT * FROM (SELECT id FROM orders)", "SELECT * FROM (SELECT id FROM orders)", ""},
+		{"Count star", "SELECT count(*) FROM events", "SELECT count(*) FROM events", ""},
+		{"Unknown table", "SELECT * FROM returns", "SELECT * FROM returns", ""},
+		{"Wide table", "SELECT * FROM events", "", "hive.sales.events has 4 columns, more than the limit of 3 (TRINO_SELECT_STAR_MAX_COLUMNS)"},
+		{"Wide table in a join", "SELECT o.id, e.* FROM orders o JOIN events e ON o.id = e.a", "", "hive.sales.events has 4 columns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.checkSelectStar(ctx, tt.query)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("checkSelectStar(%q) error = %v, want %q", tt.query, err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkSelectStar(%q) unexpected error: %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("checkSelectStar(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	off := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "sales"}}
+	if got, err := off.checkSelectStar(ctx, "SELECT * FROM events"); err != nil || got != "SELECT * FROM events" {
+		t.Errorf("checkSelectStar() without settings = %q, %v; want the query unchanged", got, err)
+	}
+}
@@ -0,0 +1,116 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// maxSlowQueryLogLength bounds the normalized SQL in a slow query log line
+const maxSlowQueryLogLength = 2000
+
+// logSlowQuery logs a finished query that ran at least TRINO_SLOW_QUERY_LOG_SECONDS,
+// with its query ID, users, normalized SQL and Trino statistics, and counts it
+// for server_stats
+func (c *Client) logSlowQuery(ctx context.Context, query string, tracker *queryTracker, started time.Time, err error) {
+	elapsed := time.Since(started)
+	if c.config.SlowQueryLog <= 0 || elapsed < c.config.SlowQueryLog {
+		return
+	}
+	c.slowQueries.Add(1)
+
+	cluster := c.config.ClusterName
+	if cluster == "" {
+		cluster = config.DefaultClusterName
+	}
+	queryID := tracker.QueryID()
+	if queryID == "" {
+		queryID = "unknown"
+	}
+	user := getQueryUsername(ctx)
+	if user == "" {
+		user = "-"
+	}
+	trinoUser := c.config.User
+	if impersonated, ok := GetImpersonatedUser(ctx); ok {
+		trinoUser = impersonated
+	}
+	outcome := "finished"
+	if err != nil {
+		outcome = "failed"
+	}
+
+	var stats string
+	if s := tracker.Stats(); s != nil {
+		millis := func(ms int64) time.Duration { return (time.Duration(ms) * time.Millisecond).Round(time.Millisecond) }
+		stats = fmt.Sprintf(" state=%s queued=%v cpu=%v processed_rows=%d processed_bytes=%d physical_input_bytes=%d peak_memory_bytes=%d spilled_bytes=%d",
+			s.State, millis(s.QueuedTimeMillis), millis(s.CPUTimeMillis), s.ProcessedRows, s.ProcessedBytes, s.PhysicalInputBytes, s.PeakMemoryBytes, s.SpilledBytes)
+	}
+
+	sql := normalizeSQL(query)
+	if len(sql) > maxSlowQueryLogLength {
+		sql = sql[:maxSlowQueryLogLength] + "…"
+	}
+	log.Printf("WARNING: Slow Trino query %s after %v: cluster=%s query_id=%s user=%s trino_user=%s%s sql=%q",
+		outcome, elapsed.Round(time.Millisecond), cluster, queryID, user, trinoUser, stats, sql)
+}
+
+// normalizeSQL reduces a statement to its shape: comments are dropped,
+// whitespace is collapsed, and string and numeric literals become ?, so
+// queries that differ only in their values log alike and leak no values
+func normalizeSQL(query string) string {
+	var out strings.Builder
+	tokens := tokenizeSQL(query)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		// Whitespace and comments leave a gap between tokens
+		if i > 0 && token.start > tokens[i-1].end {
+			out.WriteByte(' ')
+		}
+		switch {
+		case token.text == "'" && !token.ident:
+			out.WriteString("?")
+		case numberStarts(tokens, i):
+			// Numeric literals are split at dots and exponent signs
+			for i+1 < len(tokens) && tokens[i+1].start == tokens[i].end && numberContinues(tokens[i], tokens[i+1]) {
+				i++
+			}
+			out.WriteString("?")
+		default:
+			out.WriteString(query[token.start:token.end])
+		}
+	}
+	return out.String()
+}
+
+// numberStarts reports whether a numeric literal starts at token i, as in
+// 42, 1e3 or .5
+func numberStarts(tokens []sqlToken, i int) bool {
+	if isDigits(tokens[i]) {
+		return true
+	}
+	return tokens[i].text == "." && i+1 < len(tokens) && tokens[i+1].start == tokens[i].end && isDigits(tokens[i+1])
+}
+
+// numberContinues reports whether next, adjacent to token, belongs to the
+// same numeric literal: its decimal point, fraction or exponent
+func numberContinues(token, next sqlToken) bool {
+	switch {
+	case next.text == ".":
+		return true
+	case next.text == "+" || next.text == "-":
+		return isDigits(token) && strings.HasSuffix(token.text, "e")
+	default:
+		return isDigits(next)
+	}
+}
+
+// isDigits reports whether a token is a number without its fraction, such as
+// 42 or 2e
+func isDigits(token sqlToken) bool {
+	return !token.ident && token.text[0] >= '0' && token.text[0] <= '9'
+}
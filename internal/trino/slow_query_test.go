@@ -0,0 +1,56 @@
+package trino
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := map[string]string{
+		"SELECT *\n  FROM users\n WHERE email = 'alice@example.com'":    "SELECT * FROM users WHERE email = ?",
+		"SELECT a1, \"col 2\" FROM t2 WHERE x > 10.5 AND y IN (1, 2e3)": "SELECT a1, \"col 2\" FROM t2 WHERE x > ? AND y IN (?, ?)",
+		"-- daily report\nSELECT /* hint */ count(*) FROM t LIMIT 100":  "SELECT count(*) FROM t LIMIT ?",
+		"SELECT DATE '2024-01-01', 'it''s'":                             "SELECT DATE ?, ?",
+		"SELECT x-1e-5, .5+t.c1 FROM \"T\".t3 /* it's */":               "SELECT x-?, ?+t.c1 FROM \"T\".t3",
+	}
+	for query, want := range tests {
+		if got := normalizeSQL(query); got != want {
+			t.Errorf("normalizeSQL(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestLogSlowQuery(t *testing.T) {
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+
+	client := &Client{config: &config.TrinoConfig{User: "svc", ClusterName: "prod", SlowQueryLog: time.Minute}}
+	tracker := &queryTracker{}
+	tracker.setQueryID("20240601_000000_00001_abcde", "")
+	tracker.stats = &QueryStats{State: "FINISHED", ProcessedRows: 42}
+	ctx := WithImpersonatedUser(context.Background(), "alice")
+
+	client.logSlowQuery(ctx, "SELECT * FROM t WHERE id = 7", tracker, time.Now().Add(-30*time.Second), nil)
+	if buf.Len() != 0 || client.Stats().SlowQueries != 0 {
+		t.Fatalf("logged a query under the threshold: %s", buf.String())
+	}
+
+	client.logSlowQuery(ctx, "SELECT * FROM t WHERE id = 7", tracker, time.Now().Add(-2*time.Minute), nil)
+	out := buf.String()
+	for _, want := range []string{"Slow Trino query finished after 2m0", "cluster=prod", "query_id=20240601_000000_00001_abcde",
+		"trino_user=alice", "state=FINISHED", "processed_rows=42", `sql="SELECT * FROM t WHERE id = ?"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log = %q, want %q", out, want)
+		}
+	}
+	if n := client.Stats().SlowQueries; n != 1 {
+		t.Errorf("SlowQueries = %d, want 1", n)
+	}
+}
@@ -0,0 +1,34 @@
+package trino
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// spoolingArgs are the driver arguments that request the configured segment
+// encodings and download segments in parallel. Trino only spools when the
+// spooling protocol is enabled on the cluster; otherwise results are paged
+// through the coordinator as before.
+func spoolingArgs(spooling config.Spooling) []interface{} {
+	if len(spooling.Encodings) == 0 {
+		return nil
+	}
+	return []interface{}{
+		sql.Named("encoding", strings.Join(spooling.Encodings, ", ")),
+		sql.Named("spooling_worker_count", strconv.Itoa(spooling.Workers)),
+		sql.Named("max_out_of_order_segments", strconv.Itoa(spooling.MaxOutOfOrderSegments)),
+	}
+}
+
+// isSpooledSegment reports whether req downloads a segment from the spooling
+// storage rather than calling Trino: it goes to another host than the
+// cluster's, outside the REST API under /v1/. Segments the coordinator serves
+// itself, and the backends a gateway redirects to, are under /v1/.
+func (t *headerRoundTripper) isSpooledSegment(req *http.Request) bool {
+	return t.config.Host != "" && !strings.EqualFold(req.URL.Hostname(), t.config.Host) &&
+		!strings.HasPrefix(req.URL.Path, "/v1/")
+}
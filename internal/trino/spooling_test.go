@@ -0,0 +1,41 @@
+package trino
+
+import (
+	"database/sql"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestSpoolingArgs(t *testing.T) {
+	args := spoolingArgs(config.Spooling{Encodings: []string{"json+zstd", "json"}, Workers: 8, MaxOutOfOrderSegments: 20})
+	want := []interface{}{
+		sql.Named("encoding", "json+zstd, json"),
+		sql.Named("spooling_worker_count", "8"),
+		sql.Named("max_out_of_order_segments", "20"),
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("spoolingArgs() = %v, want %v", args, want)
+	}
+	if args := spoolingArgs(config.Spooling{}); args != nil {
+		t.Errorf("spoolingArgs() without encodings = %v, want the driver defaults", args)
+	}
+}
+
+func TestIsSpooledSegment(t *testing.T) {
+	rt := &headerRoundTripper{config: &config.TrinoConfig{Host: "trino.example.com"}}
+	tests := map[string]bool{
+		"https://trino.example.com/v1/statement/executing/1":          false,
+		"https://trino.example.com/v1/spooled/download/abc":           false,
+		"https://backend-2.example.com/v1/statement/queued/1":         false, // Redirected by a gateway
+		"https://spool.s3.amazonaws.com/segments/abc?X-Amz-Signature": true,
+	}
+	for rawURL, want := range tests {
+		req, _ := http.NewRequest(http.MethodGet, rawURL, nil)
+		if got := rt.isSpooledSegment(req); got != want {
+			t.Errorf("isSpooledSegment(%s) = %v, want %v", rawURL, got, want)
+		}
+	}
+}
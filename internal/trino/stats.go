@@ -0,0 +1,38 @@
+package trino
+
+// ClientStats reports the connection pool and metadata cache of a client
+type ClientStats struct {
+	OpenConnections      int   `json:"openConnections"`
+	InUse                int   `json:"inUse"`
+	Idle                 int   `json:"idle"`
+	WaitCount            int64 `json:"waitCount"` // Queries that waited for a free connection
+	MetadataCacheEntries int   `json:"metadataCacheEntries"`
+	SlowQueries          int64 `json:"slowQueries"` // Queries logged as slow since startup (TRINO_SLOW_QUERY_LOG_SECONDS)
+}
+
+// Stats returns the client's connection pool and cache sizes; the pool is
+// empty until an external auth client logs in
+func (c *Client) Stats() ClientStats {
+	c.mu.Lock()
+	db := c.db
+	c.mu.Unlock()
+
+	var stats ClientStats
+	if db != nil {
+		pool := db.Stats()
+		stats.OpenConnections = pool.OpenConnections
+		stats.InUse = pool.InUse
+		stats.Idle = pool.Idle
+		stats.WaitCount = pool.WaitCount
+	}
+	stats.MetadataCacheEntries = c.metadata.size()
+	stats.SlowQueries = c.slowQueries.Load()
+	return stats
+}
+
+// size returns the number of cached entries, including expired ones not yet evicted
+func (m *metadataCache) size() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
@@ -0,0 +1,277 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+const (
+	metadataCacheTTL     = 5 * time.Minute
+	maxMetadataEntries   = 1000 // The cache is emptied when it grows beyond this
+	maxSuggestions       = 3
+	maxSuggestionTables  = 5 // Tables of a query whose columns are looked up for suggestions
+	suggestionLookupTime = 5 * time.Second
+)
+
+var (
+	missingTablePattern  = regexp.MustCompile(`Table '([^']+)' does not exist`)
+	missingColumnPattern = regexp.MustCompile(`Column '([^']+)' cannot be resolved`)
+)
+
+// metadataCache remembers the catalogs, the schemas of catalogs, the table
+// names of schemas and the columns of tables, per Trino user, for "did you
+// mean" suggestions, query linting, join suggestions and argument completion.
+// Names are cached before the allowlists are applied, so policy reloads take
+// effect at once.
+type metadataCache struct {
+	mu      sync.Mutex
+	entries map[string]metadataEntry
+}
+
+type metadataEntry struct {
+	names   []string
+	columns []describedColumn             // Columns of "describe" entries
+	tables  map[string][]ColumnDefinition // Columns by table of "schema columns" entries
+	expires time.Time
+}
+
+// describedColumn is a column as DESCRIBE reports it
+type describedColumn struct {
+	name  string
+	typ   string
+	extra string // "partition key" for Hive partition columns
+}
+
+// metadataKey identifies a cached name list of the user in ctx
+func metadataKey(ctx context.Context, kind string, parts ...string) string {
+	user, _ := GetImpersonatedUser(ctx)
+	return user + "\x00" + kind + "\x00" + strings.ToLower(strings.Join(parts, "."))
+}
+
+func (m *metadataCache) get(key string) ([]string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.names, true
+}
+
+func (m *metadataCache) put(key string, names []string) {
+	m.store(key, metadataEntry{names: names})
+}
+
+func (m *metadataCache) getColumns(key string) ([]describedColumn, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.columns, true
+}
+
+func (m *metadataCache) putColumns(key string, columns []describedColumn) {
+	m.store(key, metadataEntry{columns: columns})
+}
+
+func (m *metadataCache) getTables(key string) (map[string][]ColumnDefinition, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.tables, true
+}
+
+func (m *metadataCache) putTables(key string, tables map[string][]ColumnDefinition) {
+	m.store(key, metadataEntry{tables: tables})
+}
+
+func (m *metadataCache) store(key string, entry metadataEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil || len(m.entries) >= maxMetadataEntries {
+		m.entries = make(map[string]metadataEntry)
+	}
+	entry.expires = time.Now().Add(metadataCacheTTL)
+	m.entries[key] = entry
+}
+
+// clear drops every entry and returns how many there were
+func (m *metadataCache) clear() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := len(m.entries)
+	m.entries = nil
+	return n
+}
+
+// InvalidateMetadata empties the client's metadata cache, so that tables and
+// columns changed in Trino are looked up again at once rather than after
+// metadataCacheTTL. It returns the number of entries dropped.
+func (c *Client) InvalidateMetadata() int {
+	return c.metadata.clear()
+}
+
+// suggestNames adds the closest table or column names within the allowlists
+// to TABLE_NOT_FOUND and COLUMN_NOT_FOUND errors, so the query can be fixed
+// without another round of discovery
+func (c *Client) suggestNames(ctx context.Context, query string, err error) error {
+	var queryErr *QueryError
+	if err == nil || !errors.As(err, &queryErr) || queryErr.Policy {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, suggestionLookupTime)
+	defer cancel()
+
+	var suggestions []string
+	switch queryErr.Name {
+	case "TABLE_NOT_FOUND":
+		if match := missingTablePattern.FindStringSubmatch(queryErr.Message); match != nil {
+			suggestions = c.suggestTables(ctx, match[1])
+		}
+	case "COLUMN_NOT_FOUND":
+		if match := missingColumnPattern.FindStringSubmatch(queryErr.Message); match != nil {
+			suggestions = c.suggestColumns(ctx, query, match[1])
+		}
+	}
+	if len(suggestions) > 0 {
+		queryErr.Suggestions = suggestions
+		queryErr.Hint = strings.TrimSpace("Did you mean " + strings.Join(suggestions, ", ") + "? " + queryErr.Hint)
+	}
+	return err
+}
+
+// suggestTables returns the allowed tables of the schema of a missing table
+// whose names are closest to it, fully qualified
+func (c *Client) suggestTables(ctx context.Context, name string) []string {
+	ref := resolveTable(strings.Split(strings.ToLower(name), "."), c.config.Catalog, c.config.DefaultSchema(c.config.Catalog))
+	tables, ok := c.metadata.get(metadataKey(ctx, "tables", ref.Catalog, ref.Schema))
+	if !ok {
+		var err error
+		if tables, err = c.ListTablesWithContext(ctx, ref.Catalog, ref.Schema); err != nil {
+			return nil
+		}
+	}
+
+	var candidates []string
+	for _, table := range tables {
+		if c.checkTableAccess(ref.Catalog, ref.Schema, table) == nil {
+			candidates = append(candidates, table)
+		}
+	}
+	suggestions := closestNames(ref.Table, candidates)
+	for i, table := range suggestions {
+		suggestions[i] = ref.Catalog + "." + ref.Schema + "." + table
+	}
+	return suggestions
+}
+
+// suggestColumns returns the columns of the tables the query reads whose
+// names are closest to a column Trino could not resolve
+func (c *Client) suggestColumns(ctx context.Context, query, name string) []string {
+	column := strings.ToLower(name[strings.LastIndex(name, ".")+1:])
+	tokens := tokenizeSQL(query)
+	masks := c.currentPolicy().ColumnMasks
+
+	var candidates []string
+	seen := make(map[string]bool)
+	looked := 0
+	for _, table := range tableNames(tokens) {
+		if table.cte {
+			continue
+		}
+		ref := resolveTable(table.parts, c.config.Catalog, c.config.DefaultSchema(c.config.Catalog))
+		if seen[ref.String()] || c.checkTableAccess(ref.Catalog, ref.Schema, ref.Table) != nil {
+			continue
+		}
+		seen[ref.String()] = true
+		if looked++; looked > maxSuggestionTables {
+			break
+		}
+		columns, ok := c.metadata.get(metadataKey(ctx, "columns", ref.Catalog, ref.Schema, ref.Table))
+		if !ok {
+			if _, err := c.GetTableSchemaWithContext(ctx, ref.Catalog, ref.Schema, ref.Table); err != nil {
+				continue
+			}
+			columns, _ = c.metadata.get(metadataKey(ctx, "columns", ref.Catalog, ref.Schema, ref.Table))
+		}
+		for _, col := range columns {
+			if masks[strings.ToLower(ref.String()+"."+col)] != config.MaskDrop && !seen[col] {
+				seen[col] = true
+				candidates = append(candidates, col)
+			}
+		}
+	}
+	return closestNames(column, candidates)
+}
+
+// closestNames returns up to maxSuggestions candidates within a small edit
+// distance of name, or containing it, closest first
+func closestNames(name string, candidates []string) []string {
+	name = strings.ToLower(name)
+	maxDistance := len([]rune(name)) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	type scored struct {
+		name     string
+		distance int
+	}
+	var matches []scored
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		if lower == name {
+			continue
+		}
+		distance := editDistance(name, lower)
+		if distance <= maxDistance || len(name) >= 3 && (strings.Contains(lower, name) || strings.Contains(name, lower)) {
+			matches = append(matches, scored{candidate, distance})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var names []string
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		names = append(names, matches[i].name)
+	}
+	return names
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,108 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestClosestNames(t *testing.T) {
+	candidates := []string{"orders", "order_items", "customers", "lineitem", "ordrs_archive"}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"Typo", "ordrs", []string{"orders", "ordrs_archive"}},
+		{"Plural", "customer", []string{"customers"}},
+		{"Case-insensitive", "LINEITEMS", []string{"lineitem"}},
+		{"Nothing close", "nation", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := closestNames(tt.input, candidates); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("closestNames(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"orders", "orders", 0},
+		{"ordrs", "orders", 1},
+		{"revenue", "revnue", 1},
+		{"kitten", "sitting", 3},
+		{"café", "cafe", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestSuggestNames(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:         "hive",
+		Schema:          "sales",
+		AllowedCatalogs: []string{"hive"},
+		AllowedTables:   []string{"hive.sales.orders", "hive.sales.order_items"},
+		ColumnMasks:     map[string]string{"hive.sales.orders.customer_email": config.MaskDrop},
+	}}
+	ctx := context.Background()
+	client.metadata.put(metadataKey(ctx, "tables", "hive", "sales"), []string{"orders", "order_items", "orders_private"})
+	client.metadata.put(metadataKey(ctx, "columns", "hive", "sales", "orders"), []string{"orderkey", "order_date", "customer_email", "revenue"})
+
+	tableErr := &QueryError{Name: "TABLE_NOT_FOUND", Message: "line 1:15: Table 'hive.sales.order' does not exist", Hint: "Use list_tables"}
+	err := client.suggestNames(ctx, "SELECT * FROM \"order\"", fmt.Errorf("query execution failed: %w", tableErr))
+	if err == nil || !reflect.DeepEqual(tableErr.Suggestions, []string{"hive.sales.orders", "hive.sales.order_items"}) {
+		t.Errorf("table suggestions = %v, want the allowed tables closest to order", tableErr.Suggestions)
+	}
+	if !strings.HasPrefix(tableErr.Hint, "Did you mean hive.sales.orders, hive.sales.order_items? Use list_tables") {
+		t.Errorf("hint = %q", tableErr.Hint)
+	}
+
+	columnErr := &QueryError{Name: "COLUMN_NOT_FOUND", Message: "line 1:8: Column 'o.revenu' cannot be resolved"}
+	_ = client.suggestNames(ctx, "SELECT o.revenu FROM orders o", columnErr)
+	if !reflect.DeepEqual(columnErr.Suggestions, []string{"revenue"}) {
+		t.Errorf("column suggestions = %v, want [revenue]", columnErr.Suggestions)
+	}
+
+	// Dropped columns are never suggested
+	droppedErr := &QueryError{Name: "COLUMN_NOT_FOUND", Message: "line 1:8: Column 'customer_mail' cannot be resolved"}
+	_ = client.suggestNames(ctx, "SELECT customer_mail FROM orders", droppedErr)
+	if droppedErr.Suggestions != nil {
+		t.Errorf("column suggestions = %v, want none for a dropped column", droppedErr.Suggestions)
+	}
+
+	// Rejections by this server get no suggestions
+	policyErr := accessDenied("table access denied: hive.sales.orders_private not in allowlist")
+	_ = client.suggestNames(ctx, "SELECT * FROM orders_private", policyErr)
+	if policyErr.Suggestions != nil {
+		t.Errorf("policy error suggestions = %v, want none", policyErr.Suggestions)
+	}
+}
+
+func TestMetadataCacheIsPerUser(t *testing.T) {
+	var cache metadataCache
+	alice := WithImpersonatedUser(context.Background(), "alice")
+	cache.put(metadataKey(alice, "tables", "hive", "sales"), []string{"orders"})
+
+	if _, ok := cache.get(metadataKey(WithImpersonatedUser(context.Background(), "bob"), "tables", "hive", "sales")); ok {
+		t.Error("another user's cached names should not be visible")
+	}
+	if names, ok := cache.get(metadataKey(alice, "tables", "HIVE", "Sales")); !ok || !reflect.DeepEqual(names, []string{"orders"}) {
+		t.Errorf("cache.get() = %v, %v; want the cached names", names, ok)
+	}
+}
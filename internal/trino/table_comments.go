@@ -0,0 +1,95 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// TableCommentWithContext returns the comment of a table from
+// system.metadata.table_comments, or "" if it has none. Column comments are
+// part of GetTableSchemaWithContext.
+func (c *Client) TableCommentWithContext(ctx context.Context, catalog, schema, table string) (string, error) {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	if err := c.checkTableAccess(catalog, schema, table); err != nil {
+		return "", err
+	}
+	comments, err := c.tableComments(ctx, catalog, schema, table)
+	if err != nil {
+		return "", err
+	}
+	return comments[table], nil
+}
+
+// TableCommentsWithContext returns the comments of the tables in a schema
+// that have one, by table name
+func (c *Client) TableCommentsWithContext(ctx context.Context, catalog, schema string) (map[string]string, error) {
+	if catalog == "" {
+		catalog = c.config.Catalog
+	}
+	if schema == "" {
+		schema = c.config.DefaultSchema(catalog)
+	}
+	comments, err := c.tableComments(ctx, catalog, schema, "")
+	if err != nil {
+		return nil, err
+	}
+	for table := range comments {
+		if c.checkTableAccess(catalog, schema, table) != nil {
+			delete(comments, table)
+		}
+	}
+	return comments, nil
+}
+
+// SetCommentWithContext sets the comment of a table, or of one of its
+// columns, with COMMENT ON and returns the statement it ran. An empty comment
+// removes the comment. Like any write, it requires TRINO_ALLOW_WRITE_QUERIES;
+// allowlists apply and dropped masked columns cannot be commented on.
+func (c *Client) SetCommentWithContext(ctx context.Context, catalog, schema, table, column, comment string) (string, error) {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	if err := c.checkTableAccess(catalog, schema, table); err != nil {
+		return "", err
+	}
+
+	value := "NULL"
+	if comment != "" {
+		value = quoteLiteral(comment)
+	}
+	target := "TABLE " + quoteIdentifier(catalog) + "." + quoteIdentifier(schema) + "." + quoteIdentifier(table)
+	if column != "" {
+		key := strings.ToLower(catalog + "." + schema + "." + table + "." + column)
+		if c.currentPolicy().ColumnMasks[key] == config.MaskDrop {
+			return "", accessDenied("column access denied: %s.%s.%s.%s is masked", catalog, schema, table, column)
+		}
+		target = "COLUMN " + quoteIdentifier(catalog) + "." + quoteIdentifier(schema) + "." + quoteIdentifier(table) + "." + quoteIdentifier(column)
+	}
+	statement := fmt.Sprintf("COMMENT ON %s IS %s", target, value)
+	if _, err := c.executeQueryWithRetry(ctx, statement, queryOptions{}, false); err != nil {
+		return "", err
+	}
+	return statement, nil
+}
+
+// tableComments reads the non-empty comments of a table, or of all tables in
+// a schema when table is empty
+func (c *Client) tableComments(ctx context.Context, catalog, schema, table string) (map[string]string, error) {
+	query := fmt.Sprintf(`SELECT table_name, comment
+FROM system.metadata.table_comments
+WHERE catalog_name = %s AND schema_name = %s AND comment IS NOT NULL AND comment <> ''`,
+		quoteLiteral(catalog), quoteLiteral(schema))
+	if table != "" {
+		query += " AND table_name = " + quoteLiteral(table)
+	}
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table comments: %w", err)
+	}
+	comments := make(map[string]string, len(rows))
+	for _, row := range rows {
+		comments[stringValue(row["table_name"])] = stringValue(row["comment"])
+	}
+	return comments, nil
+}
@@ -0,0 +1,44 @@
+package trino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestTableCommentChecksAllowlist(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "sales", AllowedTables: []string{"hive.sales.orders"}}}
+
+	_, err := client.TableCommentWithContext(context.Background(), "", "", "customers")
+	if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorPermissionDenied {
+		t.Errorf("TableCommentWithContext() outside the allowlist error = %v, want %s", err, ErrorPermissionDenied)
+	}
+}
+
+func TestSetCommentChecksPolicy(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:       "hive",
+		Schema:        "sales",
+		AllowedTables: []string{"hive.sales.orders"},
+		ColumnMasks:   map[string]string{"hive.sales.orders.card_number": config.MaskDrop},
+	}}
+
+	tests := []struct {
+		name   string
+		table  string
+		column string
+	}{
+		{name: "table outside the allowlist", table: "customers"},
+		{name: "column outside the allowlist", table: "customers", column: "email"},
+		{name: "dropped masked column", table: "orders", column: "CARD_NUMBER"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.SetCommentWithContext(context.Background(), "", "", tt.table, tt.column, "documented")
+			if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorPermissionDenied {
+				t.Errorf("SetCommentWithContext() error = %v, want %s", err, ErrorPermissionDenied)
+			}
+		})
+	}
+}
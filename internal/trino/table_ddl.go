@@ -0,0 +1,46 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GetTableDDLWithContext returns the CREATE statement of a table, view or
+// materialized view as SHOW CREATE reports it, with the table properties
+// such as format, partitioning and bucketing. The table is resolved and
+// checked against the allowlists like GetTableSchemaWithContext; dropped
+// masked columns are left out and other masked columns are annotated.
+func (c *Client) GetTableDDLWithContext(ctx context.Context, catalog, schema, table string) (string, error) {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	if err := c.checkTableAccess(catalog, schema, table); err != nil {
+		return "", err
+	}
+
+	name := quoteTable(TableRef{Catalog: catalog, Schema: schema, Table: table})
+	rows, err := c.ExecuteQueryWithContext(ctx, "SHOW CREATE TABLE "+name)
+	if err != nil {
+		// SHOW CREATE TABLE rejects views with "Relation '...' is a view, not a table"
+		message := strings.ToLower(err.Error())
+		switch {
+		case strings.Contains(message, "is a materialized view"):
+			rows, err = c.ExecuteQueryWithContext(ctx, "SHOW CREATE MATERIALIZED VIEW "+name)
+		case strings.Contains(message, "is a view"):
+			rows, err = c.ExecuteQueryWithContext(ctx, "SHOW CREATE VIEW "+name)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var ddl string
+	for _, row := range rows {
+		for _, value := range row {
+			ddl = stringValue(value)
+		}
+	}
+	if ddl == "" {
+		return "", fmt.Errorf("SHOW CREATE returned no statement for %s.%s.%s", catalog, schema, table)
+	}
+	return maskTableDDL(c.currentPolicy().ColumnMasks, catalog, schema, table, ddl), nil
+}
@@ -0,0 +1,365 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultDiffMismatchLimit is the number of mismatched rows DiffTablesWithContext returns by default
+	DefaultDiffMismatchLimit = 20
+	// MaxDiffMismatchLimit bounds the mismatched rows returned
+	MaxDiffMismatchLimit = 100
+	// MaxDiffJoinRows bounds the rows per table compared row by row; larger
+	// comparisons need a filter or the checksum mode
+	MaxDiffJoinRows = 10_000_000
+)
+
+// Table diff modes
+const (
+	DiffModeRows     = "rows"     // Join the tables on their keys and report mismatched rows
+	DiffModeChecksum = "checksum" // Compare row counts and order-insensitive checksums only
+)
+
+// DiffTablesOptions controls what DiffTablesWithContext compares
+type DiffTablesOptions struct {
+	KeyColumns []string // Columns identifying a row in both tables
+	Columns    []string // Columns to compare; empty compares every other column both tables share
+	Where      string   // Filter applied to both tables, e.g. a partition: ds = '2024-01-01'
+	Mode       string   // DiffModeRows (default) or DiffModeChecksum
+	Limit      int      // Mismatched rows to return in rows mode
+}
+
+// TableDataDiff reports how the rows of table B differ from those of A
+type TableDataDiff struct {
+	A              string   `json:"a"`
+	B              string   `json:"b"`
+	Mode           string   `json:"mode"`
+	KeyColumns     []string `json:"keyColumns"`
+	Columns        []string `json:"columns"`                  // Compared columns
+	SkippedColumns []string `json:"skippedColumns,omitempty"` // Columns in one table only, or masked
+	Identical      bool     `json:"identical"`
+	RowsA          int64    `json:"rowsA"`
+	RowsB          int64    `json:"rowsB"`
+
+	// Checksum mode
+	ChecksumA string `json:"checksumA,omitempty"`
+	ChecksumB string `json:"checksumB,omitempty"`
+
+	// Rows mode: rows by key found in one table only, or in both with different values
+	OnlyInA             *int64                   `json:"onlyInA,omitempty"`
+	OnlyInB             *int64                   `json:"onlyInB,omitempty"`
+	Changed             *int64                   `json:"changed,omitempty"`
+	Mismatches          []map[string]interface{} `json:"mismatches,omitempty"` // Keys, _diff and the a./b. values of the compared columns
+	MismatchesTruncated bool                     `json:"mismatchesTruncated,omitempty"`
+
+	Queries []string `json:"queries"` // The comparison SQL that was run
+}
+
+// DiffTablesWithContext compares the data of two tables by generating and
+// running comparison SQL: row counts and checksums, or a full outer join on
+// the key columns that counts rows missing on either side or with different
+// values and returns a sample of them, ordered by key. The join only runs
+// when both tables (after the filter) hold at most MaxDiffJoinRows rows.
+// Masked columns are never compared.
+func (c *Client) DiffTablesWithContext(ctx context.Context, a, b string, opts DiffTablesOptions) (*TableDataDiff, error) {
+	mode := strings.ToLower(strings.TrimSpace(opts.Mode))
+	switch mode {
+	case "":
+		mode = DiffModeRows
+	case DiffModeRows, DiffModeChecksum:
+	default:
+		return nil, fmt.Errorf("invalid mode '%s': use %s or %s", opts.Mode, DiffModeRows, DiffModeChecksum)
+	}
+	if len(opts.KeyColumns) == 0 {
+		return nil, errors.New("at least one key column is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultDiffMismatchLimit
+	}
+	if limit > MaxDiffMismatchLimit {
+		limit = MaxDiffMismatchLimit
+	}
+	where := strings.TrimSuffix(strings.TrimSpace(opts.Where), ";")
+
+	refA, err := c.parseDiffTable(a)
+	if err != nil {
+		return nil, err
+	}
+	refB, err := c.parseDiffTable(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range []TableRef{refA, refB} {
+		if err := c.checkTableAccess(ref.Catalog, ref.Schema, ref.Table); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.validateFilter("filter", where, refA, refB); err != nil {
+		return nil, err
+	}
+
+	columnsA, err := c.schemaColumns(ctx, refA)
+	if err != nil {
+		return nil, err
+	}
+	columnsB, err := c.schemaColumns(ctx, refB)
+	if err != nil {
+		return nil, err
+	}
+	if len(columnsA[refA.Table]) == 0 {
+		return nil, fmt.Errorf("table %s not found", refA)
+	}
+	if len(columnsB[refB.Table]) == 0 {
+		return nil, fmt.Errorf("table %s not found", refB)
+	}
+
+	diff := &TableDataDiff{A: refA.String(), B: refB.String(), Mode: mode}
+	diff.KeyColumns, diff.Columns, diff.SkippedColumns, err = c.diffColumnSets(refA, refB,
+		columnsA[refA.Table], columnsB[refB.Table], opts.KeyColumns, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == DiffModeChecksum {
+		return diff, c.diffChecksums(ctx, diff, refA, refB, where)
+	}
+	return diff, c.diffRows(ctx, diff, refA, refB, where, limit)
+}
+
+// parseDiffTable resolves a table of one to three parts with the defaults
+func (c *Client) parseDiffTable(name string) (TableRef, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(name)), ".")
+	for _, part := range parts {
+		if part == "" {
+			return TableRef{}, fmt.Errorf("invalid table '%s': expected catalog.schema.table", name)
+		}
+	}
+	if len(parts) > 3 {
+		return TableRef{}, fmt.Errorf("invalid table '%s': expected catalog.schema.table", name)
+	}
+	return resolveTable(parts, c.config.Catalog, c.config.DefaultSchema(c.config.Catalog)), nil
+}
+
+// diffColumnSets checks the key and compared columns against both tables and
+// the column masks, defaulting the compared columns to all shared ones
+func (c *Client) diffColumnSets(refA, refB TableRef, a, b []ColumnDefinition, keyColumns, columns []string) ([]string, []string, []string, error) {
+	masks := c.currentPolicy().ColumnMasks
+	inB := make(map[string]bool, len(b))
+	for _, col := range b {
+		inB[strings.ToLower(col.Name)] = true
+	}
+	inA := make(map[string]bool, len(a))
+	for _, col := range a {
+		inA[strings.ToLower(col.Name)] = true
+	}
+	masked := func(column string) bool {
+		return maskedByName(masks, refA.Table, column) || maskedByName(masks, refB.Table, column)
+	}
+	check := func(kind, column string) (string, error) {
+		column = strings.ToLower(strings.TrimSpace(column))
+		switch {
+		case !inA[column] || !inB[column]:
+			return "", fmt.Errorf("%s column '%s' must exist in both tables", kind, column)
+		case masked(column):
+			return "", policyError(ErrorQueryRejected, "Leave masked columns out of the comparison",
+				"column masking: masked column '%s' cannot be compared", column)
+		}
+		return column, nil
+	}
+
+	keys := make([]string, 0, len(keyColumns))
+	isKey := make(map[string]bool)
+	for _, column := range keyColumns {
+		key, err := check("key", column)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !isKey[key] {
+			isKey[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	var compared, skipped []string
+	if len(columns) > 0 {
+		for _, column := range columns {
+			name, err := check("compared", column)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if !isKey[name] {
+				compared = append(compared, name)
+			}
+		}
+		return keys, compared, nil, nil
+	}
+	for _, col := range a {
+		name := strings.ToLower(col.Name)
+		switch {
+		case isKey[name]:
+		case !inB[name] || masked(name):
+			skipped = append(skipped, name)
+		default:
+			compared = append(compared, name)
+		}
+	}
+	for _, col := range b {
+		if name := strings.ToLower(col.Name); !inA[name] {
+			skipped = append(skipped, name)
+		}
+	}
+	return keys, compared, skipped, nil
+}
+
+// maskedByName reports whether a column of a table with this name is masked,
+// matching tables by name alone like activeColumnMasks
+func maskedByName(masks map[string]string, table, column string) bool {
+	for key := range masks {
+		parts := strings.Split(key, ".")
+		if len(parts) == 4 && parts[2] == table && parts[3] == column {
+			return true
+		}
+	}
+	return false
+}
+
+// diffChecksums compares the row counts and checksums of both tables
+func (c *Client) diffChecksums(ctx context.Context, diff *TableDataDiff, refA, refB TableRef, where string) error {
+	row := "ROW(" + quoteIdentifiers(append(append([]string{}, diff.KeyColumns...), diff.Columns...)) + ")"
+	side := func(label string, ref TableRef) string {
+		return fmt.Sprintf("SELECT '%s' AS side, count(*) AS row_count, to_hex(checksum(%s)) AS checksum FROM %s%s",
+			label, row, quoteTable(ref), whereClause(where))
+	}
+	query := side("a", refA) + "\nUNION ALL\n" + side("b", refB)
+	diff.Queries = append(diff.Queries, query)
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to compute checksums: %w", err)
+	}
+	for _, r := range rows {
+		count := int64Value(r["row_count"])
+		if stringValue(r["side"]) == "a" {
+			diff.RowsA, diff.ChecksumA = count, stringValue(r["checksum"])
+		} else {
+			diff.RowsB, diff.ChecksumB = count, stringValue(r["checksum"])
+		}
+	}
+	diff.Identical = diff.RowsA == diff.RowsB && diff.ChecksumA == diff.ChecksumB
+	return nil
+}
+
+// diffRows joins both tables on the key columns, counting and sampling the
+// rows that differ
+func (c *Client) diffRows(ctx context.Context, diff *TableDataDiff, refA, refB TableRef, where string, limit int) error {
+	// Cost guard: count the rows to join first
+	countQuery := fmt.Sprintf("SELECT (SELECT count(*) FROM %s%s) AS rows_a, (SELECT count(*) FROM %s%s) AS rows_b",
+		quoteTable(refA), whereClause(where), quoteTable(refB), whereClause(where))
+	diff.Queries = append(diff.Queries, countQuery)
+	rows, err := c.ExecuteQueryWithContext(ctx, countQuery)
+	if err != nil {
+		return fmt.Errorf("failed to count rows: %w", err)
+	}
+	if len(rows) == 1 {
+		diff.RowsA = int64Value(rows[0]["rows_a"])
+		diff.RowsB = int64Value(rows[0]["rows_b"])
+	}
+	if diff.RowsA > MaxDiffJoinRows || diff.RowsB > MaxDiffJoinRows {
+		return policyError(ErrorQueryRejected,
+			"Narrow the comparison with where, e.g. to one partition, or use mode checksum",
+			"tables too large to compare row by row: %d and %d rows, at most %d each", diff.RowsA, diff.RowsB, MaxDiffJoinRows)
+	}
+
+	joined := diffJoinSQL(refA, refB, diff.KeyColumns, diff.Columns, where)
+	summaryQuery := joined + `SELECT "_diff", count(*) AS row_count FROM d WHERE "_diff" IS NOT NULL GROUP BY "_diff"`
+	diff.Queries = append(diff.Queries, summaryQuery)
+	rows, err = c.ExecuteQueryWithContext(ctx, summaryQuery)
+	if err != nil {
+		return fmt.Errorf("failed to compare rows: %w", err)
+	}
+	var onlyInA, onlyInB, changed int64
+	for _, r := range rows {
+		count := int64Value(r["row_count"])
+		switch stringValue(r["_diff"]) {
+		case "only_in_a":
+			onlyInA = count
+		case "only_in_b":
+			onlyInB = count
+		case "changed":
+			changed = count
+		}
+	}
+	diff.OnlyInA, diff.OnlyInB, diff.Changed = &onlyInA, &onlyInB, &changed
+	diff.Identical = onlyInA == 0 && onlyInB == 0 && changed == 0
+	if diff.Identical {
+		return nil
+	}
+
+	sampleQuery := joined + fmt.Sprintf(`SELECT * FROM d WHERE "_diff" IS NOT NULL ORDER BY %s LIMIT %d`,
+		quoteIdentifiers(diff.KeyColumns), limit)
+	diff.Queries = append(diff.Queries, sampleQuery)
+	diff.Mismatches, err = c.ExecuteQueryWithContext(ctx, sampleQuery)
+	if err != nil {
+		return fmt.Errorf("failed to sample mismatched rows: %w", err)
+	}
+	diff.MismatchesTruncated = onlyInA+onlyInB+changed > int64(len(diff.Mismatches))
+	return nil
+}
+
+// diffJoinSQL returns the WITH clause that full outer joins both tables on
+// the key columns into d: one row per key with "_diff" set to only_in_a,
+// only_in_b or changed for rows that differ, and the values of the compared
+// columns from both tables as "a.<column>" and "b.<column>"
+func diffJoinSQL(refA, refB TableRef, keys, columns []string, where string) string {
+	selected := quoteIdentifiers(append(append([]string{}, keys...), columns...))
+
+	var items, on, distinct, values []string
+	for _, key := range keys {
+		k := quoteIdentifier(key)
+		items = append(items, fmt.Sprintf("COALESCE(a.%s, b.%s) AS %s", k, k, k))
+		on = append(on, fmt.Sprintf("a.%s = b.%s", k, k))
+	}
+	for _, column := range columns {
+		col := quoteIdentifier(column)
+		distinct = append(distinct, fmt.Sprintf("a.%s IS DISTINCT FROM b.%s", col, col))
+		values = append(values, fmt.Sprintf("a.%s AS %s, b.%s AS %s", col, quoteIdentifier("a."+column), col, quoteIdentifier("b."+column)))
+	}
+	status := `CASE WHEN b."$present" IS NULL THEN 'only_in_a' WHEN a."$present" IS NULL THEN 'only_in_b'`
+	if len(distinct) > 0 {
+		status += " WHEN " + strings.Join(distinct, " OR ") + " THEN 'changed'"
+	}
+	status += ` END AS "_diff"`
+	items = append(items, status)
+	items = append(items, values...)
+
+	return fmt.Sprintf(`WITH a AS (SELECT %s, true AS "$present" FROM %s%s),
+b AS (SELECT %s, true AS "$present" FROM %s%s),
+d AS (SELECT %s FROM a FULL OUTER JOIN b ON %s)
+`, selected, quoteTable(refA), whereClause(where), selected, quoteTable(refB), whereClause(where),
+		strings.Join(items, ", "), strings.Join(on, " AND "))
+}
+
+// quoteTable returns the quoted, fully qualified name of a table
+func quoteTable(ref TableRef) string {
+	return quoteIdentifier(ref.Catalog) + "." + quoteIdentifier(ref.Schema) + "." + quoteIdentifier(ref.Table)
+}
+
+// quoteIdentifiers returns a comma-separated list of quoted identifiers
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// whereClause returns " WHERE <filter>", or nothing without a filter
+func whereClause(filter string) string {
+	if filter == "" {
+		return ""
+	}
+	return " WHERE " + filter
+}
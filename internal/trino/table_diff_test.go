@@ -0,0 +1,87 @@
+package trino
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestDiffColumnSets(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{ColumnMasks: map[string]string{
+		"hive.prod.orders.email": config.MaskRedact,
+	}}}
+	refA := TableRef{Catalog: "hive", Schema: "prod", Table: "orders"}
+	refB := TableRef{Catalog: "hive", Schema: "staging", Table: "orders"}
+	a := []ColumnDefinition{{Name: "id"}, {Name: "amount"}, {Name: "email"}, {Name: "legacy_flag"}}
+	b := []ColumnDefinition{{Name: "id"}, {Name: "amount"}, {Name: "email"}, {Name: "created_at"}}
+
+	keys, columns, skipped, err := client.diffColumnSets(refA, refB, a, b, []string{"ID"}, nil)
+	if err != nil {
+		t.Fatalf("diffColumnSets() error = %v", err)
+	}
+	if !reflect.DeepEqual(keys, []string{"id"}) || !reflect.DeepEqual(columns, []string{"amount"}) {
+		t.Errorf("diffColumnSets() keys = %v, columns = %v, want [id] and [amount]", keys, columns)
+	}
+	if want := []string{"email", "legacy_flag", "created_at"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("diffColumnSets() skipped = %v, want %v", skipped, want)
+	}
+
+	if _, _, _, err := client.diffColumnSets(refA, refB, a, b, []string{"legacy_flag"}, nil); err == nil {
+		t.Error("a key column missing from b should fail")
+	}
+	_, _, _, err = client.diffColumnSets(refA, refB, a, b, []string{"id"}, []string{"email"})
+	if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorQueryRejected {
+		t.Errorf("comparing a masked column error = %v, want %s", err, ErrorQueryRejected)
+	}
+}
+
+func TestDiffJoinSQL(t *testing.T) {
+	refA := TableRef{Catalog: "hive", Schema: "prod", Table: "orders"}
+	refB := TableRef{Catalog: "hive", Schema: "staging", Table: "orders"}
+
+	got := diffJoinSQL(refA, refB, []string{"id"}, []string{"amount"}, "ds = '2024-01-01'")
+	for _, want := range []string{
+		`FROM "hive"."prod"."orders" WHERE ds = '2024-01-01'`,
+		`FROM "hive"."staging"."orders" WHERE ds = '2024-01-01'`,
+		`COALESCE(a."id", b."id") AS "id"`,
+		`WHEN a."amount" IS DISTINCT FROM b."amount" THEN 'changed'`,
+		`a."amount" AS "a.amount", b."amount" AS "b.amount"`,
+		`FULL OUTER JOIN b ON a."id" = b."id"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diffJoinSQL() = %s\nmissing %s", got, want)
+		}
+	}
+}
+
+func TestDiffTablesRejectsInvalidOptions(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "prod", AllowedCatalogs: []string{"hive"}}}
+	ctx := context.Background()
+
+	if _, err := client.DiffTablesWithContext(ctx, "orders", "staging.orders", DiffTablesOptions{}); err == nil {
+		t.Error("a diff without key columns should fail")
+	}
+	opts := DiffTablesOptions{KeyColumns: []string{"id"}, Mode: "sample"}
+	if _, err := client.DiffTablesWithContext(ctx, "orders", "staging.orders", opts); err == nil {
+		t.Error("an unknown mode should fail")
+	}
+	_, err := client.DiffTablesWithContext(ctx, "orders", "postgres.prod.orders", DiffTablesOptions{KeyColumns: []string{"id"}})
+	if queryErr, ok := err.(*QueryError); !ok || queryErr.Name != ErrorPermissionDenied {
+		t.Errorf("DiffTablesWithContext() outside the allowlist error = %v, want %s", err, ErrorPermissionDenied)
+	}
+
+	// The filter runs in unchecked queries, so it may not read other tables
+	opts = DiffTablesOptions{KeyColumns: []string{"id"}, Where: "EXISTS (SELECT 1 FROM other.secret.t WHERE t.id = id)"}
+	if _, err := client.DiffTablesWithContext(ctx, "orders", "staging.orders", opts); err == nil || !strings.Contains(err.Error(), "subqueries are not allowed") {
+		t.Errorf("DiffTablesWithContext() with a subquery filter error = %v, want a rejection", err)
+	}
+	masked := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "prod",
+		ColumnMasks: map[string]string{"hive.staging.orders.card": config.MaskRedact}}}
+	opts = DiffTablesOptions{KeyColumns: []string{"id"}, Where: "card LIKE '4%'"}
+	if _, err := masked.DiffTablesWithContext(ctx, "orders", "staging.orders", opts); err == nil || !strings.Contains(err.Error(), "masked column 'card'") {
+		t.Errorf("DiffTablesWithContext() filtering on a masked column error = %v, want a rejection", err)
+	}
+}
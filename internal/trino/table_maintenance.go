@@ -0,0 +1,168 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Iceberg maintenance procedures run by RunTableMaintenanceWithContext
+const (
+	MaintenanceOptimize          = "optimize"
+	MaintenanceExpireSnapshots   = "expire_snapshots"
+	MaintenanceRemoveOrphanFiles = "remove_orphan_files"
+)
+
+// Table maintenance defaults and bounds
+const (
+	DefaultMaintenanceRetentionDays = 7 // Trino's default iceberg.expire-snapshots.min-retention and remove-orphan-files.min-retention
+	MaxFileSizeThresholdMiB         = 10240
+)
+
+// TableMaintenanceOptions controls RunTableMaintenanceWithContext
+type TableMaintenanceOptions struct {
+	Procedure            string // MaintenanceOptimize, MaintenanceExpireSnapshots or MaintenanceRemoveOrphanFiles
+	FileSizeThresholdMiB int    // optimize: files below this size are rewritten; default DefaultSmallFileSizeMiB
+	RetentionDays        int    // expire_snapshots and remove_orphan_files: keep what is younger; default DefaultMaintenanceRetentionDays
+	DryRun               bool   // Estimate the work instead of running the procedure
+}
+
+// TableMaintenance reports a maintenance procedure run, or its estimate on a dry run
+type TableMaintenance struct {
+	Table     string `json:"table"`
+	Procedure string `json:"procedure"`
+	Statement string `json:"statement"`
+	DryRun    bool   `json:"dryRun"`
+
+	// Dry run estimates
+	Optimize        *OptimizeEstimate        `json:"optimize,omitempty"`
+	ExpireSnapshots *ExpireSnapshotsEstimate `json:"expireSnapshots,omitempty"`
+	Note            string                   `json:"note,omitempty"`
+
+	Result []map[string]interface{} `json:"result,omitempty"` // Metrics reported by the procedure, on Trino versions that report them
+}
+
+// OptimizeEstimate is an upper bound on the files optimize rewrites
+type OptimizeEstimate struct {
+	DataFiles      int64 `json:"dataFiles"`
+	FilesToRewrite int64 `json:"filesToRewrite"` // Data files below the threshold
+	BytesToRewrite int64 `json:"bytesToRewrite"`
+	DeleteFiles    int64 `json:"deleteFiles"` // Delete files merged into the rewritten data files
+}
+
+// ExpireSnapshotsEstimate counts the snapshots expire_snapshots removes
+type ExpireSnapshotsEstimate struct {
+	Snapshots         int64 `json:"snapshots"`
+	SnapshotsToExpire int64 `json:"snapshotsToExpire"` // Older than the retention, except the latest snapshot
+}
+
+// RunTableMaintenanceWithContext runs one of Iceberg's optimize,
+// expire_snapshots or remove_orphan_files procedures on a table with ALTER
+// TABLE ... EXECUTE, or on a dry run only estimates its work from the
+// table's metadata tables. Like any write, running a procedure requires
+// TRINO_ALLOW_WRITE_QUERIES; allowlists apply to both.
+func (c *Client) RunTableMaintenanceWithContext(ctx context.Context, catalog, schema, table string, opts TableMaintenanceOptions) (*TableMaintenance, error) {
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	if err := c.checkTableAccess(catalog, schema, table); err != nil {
+		return nil, err
+	}
+
+	procedure := strings.ToLower(strings.TrimSpace(opts.Procedure))
+	threshold := opts.FileSizeThresholdMiB
+	if threshold == 0 {
+		threshold = DefaultSmallFileSizeMiB
+	}
+	if threshold < 0 || threshold > MaxFileSizeThresholdMiB {
+		return nil, fmt.Errorf("invalid file_size_threshold_mb %d: must be between 1 and %d", threshold, MaxFileSizeThresholdMiB)
+	}
+	retention := opts.RetentionDays
+	if retention == 0 {
+		retention = DefaultMaintenanceRetentionDays
+	}
+	if retention < 0 {
+		return nil, fmt.Errorf("invalid retention_days %d: must be positive", retention)
+	}
+
+	name := quoteIdentifier(catalog) + "." + quoteIdentifier(schema) + "." + quoteIdentifier(table)
+	var statement string
+	switch procedure {
+	case MaintenanceOptimize:
+		statement = fmt.Sprintf("ALTER TABLE %s EXECUTE optimize(file_size_threshold => '%dMB')", name, threshold)
+	case MaintenanceExpireSnapshots, MaintenanceRemoveOrphanFiles:
+		statement = fmt.Sprintf("ALTER TABLE %s EXECUTE %s(retention_threshold => '%dd')", name, procedure, retention)
+	default:
+		return nil, fmt.Errorf("invalid procedure '%s': must be %s, %s or %s", opts.Procedure,
+			MaintenanceOptimize, MaintenanceExpireSnapshots, MaintenanceRemoveOrphanFiles)
+	}
+	result := &TableMaintenance{
+		Table:     catalog + "." + schema + "." + table,
+		Procedure: procedure,
+		Statement: statement,
+		DryRun:    opts.DryRun,
+	}
+
+	if !opts.DryRun {
+		queryResult, err := c.executeQueryWithRetry(ctx, statement, queryOptions{}, false)
+		if err != nil {
+			return nil, err
+		}
+		result.Result = queryResult.Rows
+		return result, nil
+	}
+
+	// Metadata tables are addressed as "table$name"
+	metadataTable := func(name string) string {
+		return quoteIdentifier(catalog) + "." + quoteIdentifier(schema) + "." + quoteIdentifier(table+"$"+name)
+	}
+	var err error
+	switch procedure {
+	case MaintenanceOptimize:
+		result.Optimize, err = c.estimateOptimize(ctx, metadataTable(IcebergFiles), int64(threshold)<<20)
+	case MaintenanceExpireSnapshots:
+		result.ExpireSnapshots, err = c.estimateExpireSnapshots(ctx, metadataTable(IcebergSnapshots), retention)
+	case MaintenanceRemoveOrphanFiles:
+		// Orphan files are found by listing the table location, which SQL cannot do
+		result.Note = fmt.Sprintf("orphan files cannot be estimated; files older than %d days that no snapshot references will be deleted", retention)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate %s of %s (is it an Iceberg table?): %w", procedure, result.Table, err)
+	}
+	return result, nil
+}
+
+func (c *Client) estimateOptimize(ctx context.Context, name string, threshold int64) (*OptimizeEstimate, error) {
+	// content is 0 for data files, 1 and 2 for position and equality deletes
+	rows, err := c.ExecuteQueryWithContext(ctx, fmt.Sprintf(`SELECT count(*) FILTER (WHERE content = 0) AS data_files,
+  count(*) FILTER (WHERE content = 0 AND file_size_in_bytes < %[1]d) AS small_files,
+  coalesce(sum(file_size_in_bytes) FILTER (WHERE content = 0 AND file_size_in_bytes < %[1]d), 0) AS small_size,
+  count(*) FILTER (WHERE content <> 0) AS delete_files
+FROM %[2]s`, threshold, name))
+	if err != nil {
+		return nil, err
+	}
+	estimate := &OptimizeEstimate{}
+	if len(rows) > 0 {
+		estimate.DataFiles = int64Value(rows[0]["data_files"])
+		estimate.FilesToRewrite = int64Value(rows[0]["small_files"])
+		estimate.BytesToRewrite = int64Value(rows[0]["small_size"])
+		estimate.DeleteFiles = int64Value(rows[0]["delete_files"])
+	}
+	return estimate, nil
+}
+
+func (c *Client) estimateExpireSnapshots(ctx context.Context, name string, retentionDays int) (*ExpireSnapshotsEstimate, error) {
+	// The latest snapshot is retained whatever its age
+	rows, err := c.ExecuteQueryWithContext(ctx, fmt.Sprintf(`SELECT count(*) AS snapshots,
+  count(*) FILTER (WHERE committed_at < current_timestamp - INTERVAL '%[1]d' DAY
+    AND committed_at < (SELECT max(committed_at) FROM %[2]s)) AS expired
+FROM %[2]s`, retentionDays, name))
+	if err != nil {
+		return nil, err
+	}
+	estimate := &ExpireSnapshotsEstimate{}
+	if len(rows) > 0 {
+		estimate.Snapshots = int64Value(rows[0]["snapshots"])
+		estimate.SnapshotsToExpire = int64Value(rows[0]["expired"])
+	}
+	return estimate, nil
+}
@@ -0,0 +1,58 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestRunTableMaintenanceValidation(t *testing.T) {
+	client := &Client{
+		config: &config.TrinoConfig{
+			Catalog:       "iceberg",
+			Schema:        "default",
+			AllowedTables: []string{"iceberg.analytics.events"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		table       string
+		opts        TableMaintenanceOptions
+		expectError string
+	}{
+		{"Table outside allowlist", "analytics.users", TableMaintenanceOptions{Procedure: MaintenanceOptimize}, "table access denied: iceberg.analytics.users"},
+		{"Unknown procedure", "analytics.events", TableMaintenanceOptions{Procedure: "rollback_to_snapshot"}, "invalid procedure 'rollback_to_snapshot'"},
+		{"Threshold too large", "analytics.events", TableMaintenanceOptions{Procedure: MaintenanceOptimize, FileSizeThresholdMiB: MaxFileSizeThresholdMiB + 1}, "invalid file_size_threshold_mb"},
+		{"Negative retention", "analytics.events", TableMaintenanceOptions{Procedure: MaintenanceExpireSnapshots, RetentionDays: -1}, "invalid retention_days -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.RunTableMaintenanceWithContext(context.Background(), "", "", tt.table, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("RunTableMaintenanceWithContext() error = %v, want %q", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestRunTableMaintenanceStatement(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{Catalog: "iceberg", Schema: "analytics"}}
+
+	// remove_orphan_files is the one dry run that needs no query
+	maintenance, err := client.RunTableMaintenanceWithContext(context.Background(), "", "", "events",
+		TableMaintenanceOptions{Procedure: "Remove_Orphan_Files", RetentionDays: 14, DryRun: true})
+	if err != nil {
+		t.Fatalf("RunTableMaintenanceWithContext() error = %v", err)
+	}
+	want := `ALTER TABLE "iceberg"."analytics"."events" EXECUTE remove_orphan_files(retention_threshold => '14d')`
+	if maintenance.Statement != want {
+		t.Errorf("Statement = %q, want %q", maintenance.Statement, want)
+	}
+	if maintenance.Procedure != MaintenanceRemoveOrphanFiles || maintenance.Note == "" || maintenance.Result != nil {
+		t.Errorf("RunTableMaintenanceWithContext() = %+v, want a dry run with a note", maintenance)
+	}
+}
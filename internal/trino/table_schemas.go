@@ -0,0 +1,118 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MaxSchemaTables bounds the tables one get_table_schemas call describes
+const MaxSchemaTables = 50
+
+// TableSchema is a table described by GetTableSchemasWithContext
+type TableSchema struct {
+	Table   TableRef
+	Columns []map[string]interface{} // Column and Type, like the rows of DESCRIBE
+	Err     error                    // Why the table could not be described
+}
+
+// GetTableSchemasWithContext describes several tables at once, e.g. the
+// tables of a join being planned. The columns of all tables of a catalog are
+// read in one information_schema.columns query rather than a DESCRIBE per
+// table. Tables are returned in the order given; a table outside the
+// allowlists or not found has Err set and does not fail the others.
+func (c *Client) GetTableSchemasWithContext(ctx context.Context, catalog, schema string, tables []string) ([]TableSchema, error) {
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no tables given")
+	}
+	if len(tables) > MaxSchemaTables {
+		return nil, fmt.Errorf("too many tables: %d given, at most %d can be described at once", len(tables), MaxSchemaTables)
+	}
+
+	schemas := make([]TableSchema, len(tables))
+	byCatalog := make(map[string][]int) // Indexes of the allowed tables of each catalog
+	for i, table := range tables {
+		schemas[i].Table = c.ResolveTable(catalog, schema, table)
+		ref := schemas[i].Table
+		if schemas[i].Err = c.checkTableAccess(ref.Catalog, ref.Schema, ref.Table); schemas[i].Err == nil {
+			byCatalog[ref.Catalog] = append(byCatalog[ref.Catalog], i)
+		}
+	}
+
+	catalogs := make([]string, 0, len(byCatalog))
+	for cat := range byCatalog {
+		catalogs = append(catalogs, cat)
+	}
+	sort.Strings(catalogs)
+	for _, cat := range catalogs {
+		indexes := byCatalog[cat]
+		columns, err := c.readTableColumns(ctx, cat, schemas, indexes)
+		if err != nil {
+			return nil, err
+		}
+		masks := c.currentPolicy().ColumnMasks
+		for _, i := range indexes {
+			ref := schemas[i].Table
+			defs, ok := columns[strings.ToLower(ref.Schema+"."+ref.Table)]
+			if !ok {
+				schemas[i].Err = fmt.Errorf("table %s does not exist", ref)
+				continue
+			}
+			names := make([]string, len(defs))
+			rows := make([]map[string]interface{}, len(defs))
+			for j, def := range defs {
+				names[j] = def.Name
+				rows[j] = map[string]interface{}{"Column": def.Name, "Type": def.Type}
+			}
+			c.metadata.put(metadataKey(ctx, "columns", ref.Catalog, ref.Schema, ref.Table), names)
+			schemas[i].Columns = maskTableSchema(masks, ref.Catalog, ref.Schema, ref.Table, rows)
+		}
+	}
+	return schemas, nil
+}
+
+// readTableColumns reads the columns of the indexed tables of a catalog in
+// ordinal order, by lower-case schema.table. The query narrows by schema and
+// table name, which Trino pushes into the connectors' metadata listing; pairs
+// not asked for are dropped by the caller's lookup.
+func (c *Client) readTableColumns(ctx context.Context, catalog string, schemas []TableSchema, indexes []int) (map[string][]ColumnDefinition, error) {
+	// Trino keeps unquoted identifiers in lower case
+	var schemaNames, tableNames []string
+	for _, i := range indexes {
+		if name := strings.ToLower(schemas[i].Table.Schema); !slices.Contains(schemaNames, name) {
+			schemaNames = append(schemaNames, name)
+		}
+		if name := strings.ToLower(schemas[i].Table.Table); !slices.Contains(tableNames, name) {
+			tableNames = append(tableNames, name)
+		}
+	}
+	query := fmt.Sprintf(`SELECT table_schema, table_name, column_name, data_type
+FROM %s.information_schema.columns
+WHERE table_schema IN (%s) AND table_name IN (%s)
+ORDER BY table_schema, table_name, ordinal_position`, quoteIdentifier(catalog), quoteLiterals(schemaNames), quoteLiterals(tableNames))
+	rows, err := c.ExecuteQueryWithContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of tables in %s: %w", catalog, err)
+	}
+
+	columns := make(map[string][]ColumnDefinition)
+	for _, row := range rows {
+		key := strings.ToLower(stringValue(row["table_schema"]) + "." + stringValue(row["table_name"]))
+		columns[key] = append(columns[key], ColumnDefinition{
+			Name: stringValue(row["column_name"]),
+			Type: stringValue(row["data_type"]),
+		})
+	}
+	return columns, nil
+}
+
+// quoteLiterals renders values as a comma-separated list of string literals
+func quoteLiterals(values []string) string {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = quoteLiteral(value)
+	}
+	return strings.Join(literals, ", ")
+}
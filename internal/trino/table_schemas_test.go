@@ -0,0 +1,77 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestGetTableSchemas(t *testing.T) {
+	server := trinotest.NewServer()
+	defer server.Close()
+	server.Handle(`SELECT table_schema, table_name, column_name, data_type FROM "hive".information_schema.columns`+
+		` WHERE table_schema IN ('sales') AND table_name IN ('orders', 'customers', 'returns')`+
+		` ORDER BY table_schema, table_name, ordinal_position`, trinotest.Result{
+		Columns: []trinotest.Column{
+			{Name: "table_schema", Type: "varchar"}, {Name: "table_name", Type: "varchar"},
+			{Name: "column_name", Type: "varchar"}, {Name: "data_type", Type: "varchar"},
+		},
+		Rows: [][]any{
+			{"sales", "customers", "customer_id", "bigint"},
+			{"sales", "customers", "email", "varchar"},
+			{"sales", "customers", "ssn", "varchar"},
+			{"sales", "orders", "order_id", "bigint"},
+			{"sales", "orders", "customer_id", "bigint"},
+		},
+	})
+	client := newMockClient(t, server, "table-schemas", func(cfg *config.TrinoConfig) {
+		cfg.AllowedSchemas = []string{"hive.sales"}
+		cfg.ColumnMasks = map[string]string{
+			"hive.sales.customers.email": config.MaskSHA256,
+			"hive.sales.customers.ssn":   config.MaskDrop,
+		}
+	})
+
+	schemas, err := client.GetTableSchemasWithContext(context.Background(), "", "", []string{"Orders", "sales.customers", "returns", "finance.ledger"})
+	if err != nil {
+		t.Fatalf("GetTableSchemasWithContext() error = %v", err)
+	}
+	if len(schemas) != 4 {
+		t.Fatalf("got %d tables, want 4", len(schemas))
+	}
+
+	orders, customers := schemas[0], schemas[1]
+	if orders.Err != nil || len(orders.Columns) != 2 || orders.Columns[0]["Column"] != "order_id" {
+		t.Errorf("orders = %+v", orders)
+	}
+	if customers.Err != nil || len(customers.Columns) != 2 {
+		t.Fatalf("customers = %+v, want the dropped column left out", customers)
+	}
+	if extra := customers.Columns[1]["Extra"]; extra != "masked (sha256)" {
+		t.Errorf("email Extra = %v, want masked (sha256)", extra)
+	}
+	if err := schemas[2].Err; err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("returns error = %v, want does not exist", err)
+	}
+	if err := schemas[3].Err; err == nil || !strings.Contains(err.Error(), "not in allowlist") {
+		t.Errorf("finance.ledger error = %v, want access denied", err)
+	}
+}
+
+func TestGetTableSchemasLimits(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{Catalog: "hive", Schema: "sales"}}
+	if _, err := client.GetTableSchemasWithContext(context.Background(), "", "", nil); err == nil {
+		t.Error("GetTableSchemasWithContext() without tables succeeded")
+	}
+	tables := make([]string, MaxSchemaTables+1)
+	for i := range tables {
+		tables[i] = fmt.Sprintf("t%d", i)
+	}
+	if _, err := client.GetTableSchemasWithContext(context.Background(), "", "", tables); err == nil || !strings.Contains(err.Error(), "too many tables") {
+		t.Errorf("GetTableSchemasWithContext() with %d tables error = %v", len(tables), err)
+	}
+}
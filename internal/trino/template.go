@@ -0,0 +1,364 @@
+package trino
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Template parameter types. A type followed by [] takes a list of values,
+// rendered comma-separated, e.g. for IN ({{ids:integer[]}}).
+const (
+	ParamIdentifier = "identifier" // Quoted identifier; dotted names are quoted per part
+	ParamString     = "string"     // VARCHAR literal
+	ParamInteger    = "integer"
+	ParamNumber     = "number"
+	ParamBoolean    = "boolean"
+	ParamDate       = "date"      // DATE literal from YYYY-MM-DD
+	ParamTimestamp  = "timestamp" // TIMESTAMP literal, optionally with a UTC offset
+)
+
+var (
+	templateParamTypes = map[string]bool{
+		ParamIdentifier: true, ParamString: true, ParamInteger: true, ParamNumber: true,
+		ParamBoolean: true, ParamDate: true, ParamTimestamp: true,
+	}
+	templateParamName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	numberLiteral     = regexp.MustCompile(`^-?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+	timestampLayouts  = []struct {
+		layout string
+		zoned  bool
+	}{
+		{"2006-01-02 15:04:05.999999999", false},
+		{"2006-01-02T15:04:05.999999999", false},
+		{"2006-01-02 15:04:05.999999999Z07:00", true},
+		{time.RFC3339Nano, true},
+		{"2006-01-02 15:04:05.999999999 Z07:00", true},
+	}
+)
+
+// TemplateParam is a placeholder declared by a query template
+type TemplateParam struct {
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Required bool    `json:"required"`
+	Default  *string `json:"default,omitempty"`
+}
+
+// QueryTemplate is SQL with typed placeholders:
+//
+//	{{name:type}}          required
+//	{{name:type?}}         optional; renders NULL when omitted
+//	{{name:type=default}}  optional with a default (comma-separated for lists)
+//
+// Values are rendered as literals of their type or as quoted identifiers, so
+// parameters can never change the structure of the query. Placeholders inside
+// string literals, quoted identifiers and comments are rejected.
+type QueryTemplate struct {
+	parts  []templatePart
+	params []TemplateParam
+}
+
+// templatePart is literal SQL text, or a placeholder when param is set
+type templatePart struct {
+	text  string
+	param *TemplateParam
+}
+
+// ParseTemplate parses a query template, checking its placeholders
+func ParseTemplate(text string) (*QueryTemplate, error) {
+	t := &QueryTemplate{}
+	declared := make(map[string]*TemplateParam)
+	start := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case quote == '-' && ch == '\n', quote == '*' && ch == '*' && i+1 < len(text) && text[i+1] == '/':
+			quote = 0
+		case quote == '\'' || quote == '"':
+			if ch == quote {
+				quote = 0
+			}
+		case quote != 0:
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '-' && i+1 < len(text) && text[i+1] == '-':
+			quote = '-'
+		case ch == '/' && i+1 < len(text) && text[i+1] == '*':
+			quote = '*'
+		}
+		if !strings.HasPrefix(text[i:], "{{") {
+			continue
+		}
+		if quote != 0 {
+			return nil, fmt.Errorf("placeholder at offset %d is inside a string, quoted identifier or comment; put the placeholder in place of the whole literal", i)
+		}
+		end := strings.Index(text[i:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated placeholder at offset %d", i)
+		}
+		part, err := parsePlaceholder(strings.TrimSpace(text[i+2 : i+end]))
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := declared[part.param.Name]; ok {
+			if prev.Type != part.param.Type {
+				return nil, fmt.Errorf("parameter %s is declared as both %s and %s", prev.Name, prev.Type, part.param.Type)
+			}
+			if prev.Default == nil && part.param.Default != nil {
+				prev.Default = part.param.Default
+			}
+			prev.Required = prev.Required && part.param.Required
+			part.param = prev
+		} else {
+			declared[part.param.Name] = part.param
+		}
+		t.parts = append(t.parts, templatePart{text: text[start:i]}, part)
+		i += end + 1
+		start = i + 1
+	}
+	t.parts = append(t.parts, templatePart{text: text[start:]})
+
+	for _, param := range declared {
+		if param.Type == ParamIdentifier && !param.Required && param.Default == nil {
+			return nil, fmt.Errorf("identifier parameter %s cannot render NULL; make it required or give it a default", param.Name)
+		}
+		if param.Default != nil {
+			if _, err := renderTemplateValue(param, *param.Default, true); err != nil {
+				return nil, fmt.Errorf("invalid default of parameter %s: %w", param.Name, err)
+			}
+		}
+		t.params = append(t.params, *param)
+	}
+	sort.Slice(t.params, func(i, j int) bool { return t.params[i].Name < t.params[j].Name })
+	return t, nil
+}
+
+// parsePlaceholder parses name:type, name:type? or name:type=default
+func parsePlaceholder(spec string) (templatePart, error) {
+	name, rest, ok := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if !ok || !templateParamName.MatchString(name) {
+		return templatePart{}, fmt.Errorf("invalid placeholder {{%s}}: expected {{name:type}}", spec)
+	}
+	param := &TemplateParam{Name: name, Required: true}
+	typ, def, hasDefault := strings.Cut(rest, "=")
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if hasDefault {
+		def = strings.TrimSpace(def)
+		param.Default, param.Required = &def, false
+	} else if strings.HasSuffix(typ, "?") {
+		typ, param.Required = strings.TrimSpace(strings.TrimSuffix(typ, "?")), false
+	}
+	base := strings.TrimSuffix(typ, "[]")
+	if !templateParamTypes[base] {
+		return templatePart{}, fmt.Errorf("unknown type '%s' of parameter %s (supported: identifier, string, integer, number, boolean, date, timestamp, optionally followed by [])", typ, name)
+	}
+	param.Type = typ
+	return templatePart{param: param}, nil
+}
+
+// Params returns the placeholders of the template, by name
+func (t *QueryTemplate) Params() []TemplateParam {
+	return t.params
+}
+
+// Render substitutes the parameter values, which may be strings, numbers,
+// booleans, nil or lists of them as decoded from JSON. Unknown parameters are
+// rejected so misspelled names do not silently fall back to defaults.
+func (t *QueryTemplate) Render(values map[string]interface{}) (string, error) {
+	known := make(map[string]bool, len(t.params))
+	for _, param := range t.params {
+		known[param.Name] = true
+	}
+	for name := range values {
+		if !known[name] {
+			return "", fmt.Errorf("unknown parameter %s", name)
+		}
+	}
+
+	rendered := make(map[string]string, len(t.params))
+	for _, param := range t.params {
+		value, ok := values[param.Name]
+		var sql string
+		var err error
+		switch {
+		case ok:
+			sql, err = renderTemplateValue(&param, value, false)
+		case param.Default != nil:
+			sql, err = renderTemplateValue(&param, *param.Default, true)
+		case param.Required:
+			err = fmt.Errorf("missing required parameter %s (%s)", param.Name, param.Type)
+		default:
+			sql = "NULL"
+		}
+		if err != nil {
+			return "", err
+		}
+		rendered[param.Name] = sql
+	}
+
+	var sb strings.Builder
+	for _, part := range t.parts {
+		if part.param == nil {
+			sb.WriteString(part.text)
+		} else {
+			sb.WriteString(rendered[part.param.Name])
+		}
+	}
+	return sb.String(), nil
+}
+
+// RenderTemplate parses a query template and renders it with values
+func RenderTemplate(text string, values map[string]interface{}) (string, error) {
+	t, err := ParseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	return t.Render(values)
+}
+
+// renderTemplateValue renders a parameter value as SQL. A default is the
+// placeholder's text, comma-separated for lists.
+func renderTemplateValue(param *TemplateParam, value interface{}, isDefault bool) (string, error) {
+	base := strings.TrimSuffix(param.Type, "[]")
+	if base == param.Type {
+		sql, err := renderScalar(base, value)
+		if err != nil {
+			return "", fmt.Errorf("parameter %s: %w", param.Name, err)
+		}
+		return sql, nil
+	}
+
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case string:
+		if !isDefault {
+			return "", fmt.Errorf("parameter %s must be a list of %s values", param.Name, base)
+		}
+		for _, item := range strings.Split(v, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+	default:
+		return "", fmt.Errorf("parameter %s must be a list of %s values", param.Name, base)
+	}
+	if len(items) == 0 {
+		return "", fmt.Errorf("parameter %s must not be an empty list", param.Name)
+	}
+	rendered := make([]string, len(items))
+	for i, item := range items {
+		sql, err := renderScalar(base, item)
+		if err != nil {
+			return "", fmt.Errorf("parameter %s[%d]: %w", param.Name, i, err)
+		}
+		rendered[i] = sql
+	}
+	return strings.Join(rendered, ", "), nil
+}
+
+// renderScalar renders one value of a parameter type
+func renderScalar(typ string, value interface{}) (string, error) {
+	if value == nil {
+		if typ == ParamIdentifier {
+			return "", fmt.Errorf("an identifier cannot be null")
+		}
+		return "NULL", nil
+	}
+
+	switch typ {
+	case ParamIdentifier:
+		name, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("expected an identifier string, got %T", value)
+		}
+		parts := strings.Split(name, ".")
+		for i, part := range parts {
+			if part == "" {
+				return "", fmt.Errorf("invalid identifier '%s'", name)
+			}
+			parts[i] = quoteIdentifier(part)
+		}
+		return strings.Join(parts, "."), nil
+
+	case ParamString:
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("expected a string, got %T", value)
+		}
+		return quoteLiteral(s), nil
+
+	case ParamInteger:
+		switch v := value.(type) {
+		case float64:
+			if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+				return "", fmt.Errorf("expected an integer, got %v", v)
+			}
+			return strconv.FormatInt(int64(v), 10), nil
+		case string:
+			n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return "", fmt.Errorf("expected an integer, got '%s'", v)
+			}
+			return strconv.FormatInt(n, 10), nil
+		}
+
+	case ParamNumber:
+		switch v := value.(type) {
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return "", fmt.Errorf("expected a finite number, got %v", v)
+			}
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case string:
+			if v = strings.TrimSpace(v); !numberLiteral.MatchString(v) {
+				return "", fmt.Errorf("expected a number, got '%s'", v)
+			}
+			return v, nil
+		}
+
+	case ParamBoolean:
+		switch v := value.(type) {
+		case bool:
+			return strings.ToUpper(strconv.FormatBool(v)), nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return "", fmt.Errorf("expected a boolean, got '%s'", v)
+			}
+			return strings.ToUpper(strconv.FormatBool(b)), nil
+		}
+
+	case ParamDate:
+		if s, ok := value.(string); ok {
+			d, err := time.Parse("2006-01-02", strings.TrimSpace(s))
+			if err != nil {
+				return "", fmt.Errorf("expected a date (YYYY-MM-DD), got '%s'", s)
+			}
+			return "DATE '" + d.Format("2006-01-02") + "'", nil
+		}
+
+	case ParamTimestamp:
+		if s, ok := value.(string); ok {
+			for _, layout := range timestampLayouts {
+				ts, err := time.Parse(layout.layout, strings.TrimSpace(s))
+				if err != nil {
+					continue
+				}
+				literal := ts.Format("2006-01-02 15:04:05.999999999")
+				if layout.zoned {
+					literal += ts.Format(" -07:00")
+				}
+				return "TIMESTAMP '" + literal + "'", nil
+			}
+			return "", fmt.Errorf("expected a timestamp (YYYY-MM-DD HH:MM:SS[.fff][ +HH:MM] or RFC 3339), got '%s'", s)
+		}
+	}
+	return "", fmt.Errorf("expected a %s, got %T", typ, value)
+}
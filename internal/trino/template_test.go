@@ -0,0 +1,109 @@
+package trino
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   map[string]interface{}
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "Typed literals",
+			template: "SELECT * FROM orders WHERE status = {{status:string}} AND amount > {{min:number}} AND id < {{max:integer}} AND paid = {{paid:boolean}}",
+			values:   map[string]interface{}{"status": "it's shipped", "min": 9.5, "max": "100", "paid": true},
+			want:     "SELECT * FROM orders WHERE status = 'it''s shipped' AND amount > 9.5 AND id < 100 AND paid = TRUE",
+		},
+		{
+			name:     "Identifiers",
+			template: "SELECT {{col:identifier}} FROM {{table:identifier}}",
+			values:   map[string]interface{}{"col": `weird"name`, "table": "hive.sales.orders"},
+			want:     `SELECT "weird""name" FROM "hive"."sales"."orders"`,
+		},
+		{
+			name:     "Dates and timestamps",
+			template: "WHERE d = {{d:date}} AND ts >= {{from:timestamp}} AND ts < {{to:timestamp}}",
+			values:   map[string]interface{}{"d": "2024-01-31", "from": "2024-01-31 12:00:00", "to": "2024-02-01T00:00:00.5+02:00"},
+			want:     "WHERE d = DATE '2024-01-31' AND ts >= TIMESTAMP '2024-01-31 12:00:00' AND ts < TIMESTAMP '2024-02-01 00:00:00.5 +02:00'",
+		},
+		{
+			name:     "Lists",
+			template: "WHERE id IN ({{ids:integer[]}}) AND region IN ({{regions:string[]=EU, US}})",
+			values:   map[string]interface{}{"ids": []interface{}{1.0, 2.0, "3"}},
+			want:     "WHERE id IN (1, 2, 3) AND region IN ('EU', 'US')",
+		},
+		{
+			name:     "Defaults, optional and repeated parameters",
+			template: "SELECT {{n:integer=10}}, {{note:string?}}, {{n:integer}} LIMIT {{n:integer}}",
+			values:   map[string]interface{}{},
+			want:     "SELECT 10, NULL, 10 LIMIT 10",
+		},
+		{
+			name:     "Explicit null",
+			template: "SELECT {{note:string}}",
+			values:   map[string]interface{}{"note": nil},
+			want:     "SELECT NULL",
+		},
+		{
+			name:     "Braces in strings and comments are text",
+			template: "SELECT '{x}' -- {not a placeholder\nFROM t",
+			want:     "SELECT '{x}' -- {not a placeholder\nFROM t",
+		},
+		{name: "Missing required parameter", template: "SELECT {{id:integer}}", wantErr: "missing required parameter id"},
+		{name: "Unknown parameter", template: "SELECT 1", values: map[string]interface{}{"id": 1.0}, wantErr: "unknown parameter id"},
+		{name: "Unknown type", template: "SELECT {{id:uuid}}", wantErr: "unknown type 'uuid'"},
+		{name: "Placeholder in a string", template: "SELECT * FROM t WHERE name = '{{name:string}}'", wantErr: "inside a string"},
+		{name: "Placeholder in a comment", template: "SELECT 1 /* {{name:string}} */", wantErr: "inside a string"},
+		{name: "Unterminated placeholder", template: "SELECT {{id:integer", wantErr: "unterminated placeholder"},
+		{name: "Conflicting types", template: "SELECT {{id:integer}}, {{id:string}}", wantErr: "declared as both"},
+		{name: "Optional identifier", template: "SELECT {{col:identifier?}}", wantErr: "cannot render NULL"},
+		{name: "Invalid default", template: "SELECT {{n:integer=ten}}", wantErr: "invalid default of parameter n"},
+		{name: "Injection through an integer", template: "SELECT {{id:integer}}", values: map[string]interface{}{"id": "1; DROP TABLE t"}, wantErr: "expected an integer"},
+		{name: "Fractional integer", template: "SELECT {{id:integer}}", values: map[string]interface{}{"id": 1.5}, wantErr: "expected an integer"},
+		{name: "Invalid date", template: "SELECT {{d:date}}", values: map[string]interface{}{"d": "2024-02-30"}, wantErr: "expected a date"},
+		{name: "Empty list", template: "SELECT {{ids:integer[]}}", values: map[string]interface{}{"ids": []interface{}{}}, wantErr: "empty list"},
+		{name: "Scalar for a list", template: "SELECT {{ids:integer[]}}", values: map[string]interface{}{"ids": "1,2"}, wantErr: "must be a list"},
+		{name: "Null identifier", template: "SELECT {{col:identifier}}", values: map[string]interface{}{"col": nil}, wantErr: "cannot be null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate(tt.template, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RenderTemplate() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderTemplate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderTemplate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateParams(t *testing.T) {
+	tmpl, err := ParseTemplate("SELECT {{b:string?}} FROM {{a:identifier}} LIMIT {{c:integer=5}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	var got []string
+	for _, p := range tmpl.Params() {
+		def := ""
+		if p.Default != nil {
+			def = "=" + *p.Default
+		}
+		got = append(got, p.Name+":"+p.Type+def+map[bool]string{true: "!", false: ""}[p.Required])
+	}
+	if want := "a:identifier! b:string c:integer=5"; strings.Join(got, " ") != want {
+		t.Errorf("Params() = %s, want %s", strings.Join(got, " "), want)
+	}
+}
@@ -0,0 +1,157 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// asOfLayouts are the accepted as_of_timestamp formats; values without a
+// zone are taken as UTC
+var asOfLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999 -07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// TimeTravel selects a historical version of Iceberg or Delta Lake tables
+type TimeTravel struct {
+	SnapshotID *int64    // FOR VERSION AS OF
+	AsOf       time.Time // FOR TIMESTAMP AS OF; zero when unset
+}
+
+// ParseTimeTravel parses the snapshot_id and as_of_timestamp tool arguments.
+// Both empty returns a zero TimeTravel.
+func ParseTimeTravel(snapshotID, asOf string) (TimeTravel, error) {
+	snapshotID, asOf = strings.TrimSpace(snapshotID), strings.TrimSpace(asOf)
+	switch {
+	case snapshotID != "" && asOf != "":
+		return TimeTravel{}, fmt.Errorf("set only one of snapshot_id and as_of_timestamp")
+	case snapshotID != "":
+		id, err := strconv.ParseInt(snapshotID, 10, 64)
+		if err != nil {
+			return TimeTravel{}, fmt.Errorf("invalid snapshot_id '%s': must be an integer snapshot ID", snapshotID)
+		}
+		return TimeTravel{SnapshotID: &id}, nil
+	case asOf != "":
+		for _, layout := range asOfLayouts {
+			if t, err := time.Parse(layout, asOf); err == nil {
+				return TimeTravel{AsOf: t}, nil
+			}
+		}
+		return TimeTravel{}, fmt.Errorf("invalid as_of_timestamp '%s': use RFC 3339 (2024-01-31T12:00:00Z) or 2024-01-31 12:00:00", asOf)
+	}
+	return TimeTravel{}, nil
+}
+
+// IsZero reports whether no version is selected
+func (t TimeTravel) IsZero() bool {
+	return t.SnapshotID == nil && t.AsOf.IsZero()
+}
+
+// clause returns the FOR VERSION AS OF / FOR TIMESTAMP AS OF clause
+func (t TimeTravel) clause() string {
+	if t.SnapshotID != nil {
+		return fmt.Sprintf("FOR VERSION AS OF %d", *t.SnapshotID)
+	}
+	return fmt.Sprintf("FOR TIMESTAMP AS OF TIMESTAMP '%s'", t.AsOf.UTC().Format("2006-01-02 15:04:05.000000 UTC"))
+}
+
+// ApplyTimeTravel adds the time travel clause after every table a SELECT
+// query reads. A snapshot ID identifies a version of one table, so it is
+// rejected for queries that read several tables; tables that already carry
+// a FOR ... AS OF clause are left as they are.
+func ApplyTimeTravel(query string, travel TimeTravel) (string, error) {
+	if travel.IsZero() {
+		return query, nil
+	}
+	tokens := tokenizeSQL(query)
+	if statementType(tokens) != "select" {
+		return "", fmt.Errorf("time travel is only supported for SELECT queries")
+	}
+
+	var offsets []int
+	tables := make(map[string]bool)
+	for _, name := range tableNames(tokens) {
+		if name.write || name.cte {
+			continue
+		}
+		if name.nameEnd < len(tokens) && tokens[name.nameEnd].keyword("for") {
+			continue
+		}
+		tables[strings.Join(name.parts, ".")] = true
+		offsets = append(offsets, tokens[name.nameEnd-1].end)
+	}
+	if len(offsets) == 0 {
+		return "", fmt.Errorf("time travel requires a query that reads a table")
+	}
+	if travel.SnapshotID != nil && len(tables) > 1 {
+		names := make([]string, 0, len(tables))
+		for table := range tables {
+			names = append(names, table)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("snapshot_id identifies a version of a single table, but the query reads %s; "+
+			"use as_of_timestamp or write FOR VERSION AS OF after the table in the SQL", strings.Join(names, ", "))
+	}
+
+	// Insert from the end so earlier offsets stay valid
+	clause := " " + travel.clause()
+	for i := len(offsets) - 1; i >= 0; i-- {
+		query = query[:offsets[i]] + clause + query[offsets[i]:]
+	}
+	return query, nil
+}
+
+// GetTableSchemaAtWithContext returns the columns a table had at the selected
+// version. DESCRIBE cannot time travel, so the columns are read from an empty
+// SELECT instead; Comment and Extra are left empty, and varchar lengths and
+// nested field names are not reported.
+func (c *Client) GetTableSchemaAtWithContext(ctx context.Context, catalog, schema, table string, travel TimeTravel) ([]map[string]interface{}, error) {
+	if travel.IsZero() {
+		return c.GetTableSchemaWithContext(ctx, catalog, schema, table)
+	}
+	catalog, schema, table = c.resolveTableName(catalog, schema, table)
+	if len(c.currentPolicy().AllowedTables) > 0 && !c.isTableAllowed(catalog, schema, table) {
+		return nil, accessDenied("table access denied: %s.%s.%s not in allowlist", catalog, schema, table)
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s.%s.%s %s LIMIT 0",
+		quoteIdentifier(catalog), quoteIdentifier(schema), quoteIdentifier(table), travel.clause())
+	result, err := c.executeQueryWithRetry(ctx, query, queryOptions{}, false)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]map[string]interface{}, 0, len(result.ColumnTypes))
+	for _, info := range result.ColumnTypes {
+		columns = append(columns, map[string]interface{}{
+			"Column":  info.Name,
+			"Type":    columnTypeName(info),
+			"Extra":   "",
+			"Comment": "",
+		})
+	}
+	return maskTableSchema(c.currentPolicy().ColumnMasks, catalog, schema, table, columns), nil
+}
+
+// columnTypeName renders a result column type the way DESCRIBE does, as far
+// as the driver reports it
+func columnTypeName(info ColumnInfo) string {
+	name := strings.ToLower(info.Type)
+	switch {
+	case name == "decimal" && info.Precision > 0:
+		return fmt.Sprintf("decimal(%d,%d)", info.Precision, info.Scale)
+	case strings.HasPrefix(name, "time") && info.Precision > 0: // time, timestamp, with or without time zone
+		base, zone, _ := strings.Cut(name, " ")
+		if zone != "" {
+			zone = " " + zone
+		}
+		return fmt.Sprintf("%s(%d)%s", base, info.Precision, zone)
+	}
+	return name
+}
@@ -0,0 +1,104 @@
+package trino
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeTravel(t *testing.T) {
+	tests := []struct {
+		name     string
+		asOf     string
+		expected time.Time
+		wantErr  bool
+	}{
+		{"RFC 3339", "2024-01-31T12:00:00Z", time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), false},
+		{"RFC 3339 with offset", "2024-01-31T14:00:00+02:00", time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), false},
+		{"Space separated", "2024-01-31 12:00:00", time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), false},
+		{"Fractional seconds", "2024-01-31 12:00:00.250", time.Date(2024, 1, 31, 12, 0, 0, 250000000, time.UTC), false},
+		{"Date only", "2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), false},
+		{"Injection attempt", "2024-01-31' OR '1'='1", time.Time{}, true},
+		{"Relative time", "yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			travel, err := ParseTimeTravel("", tt.asOf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeTravel(%q) error = %v, wantErr %v", tt.asOf, err, tt.wantErr)
+			}
+			if !tt.wantErr && !travel.AsOf.Equal(tt.expected) {
+				t.Errorf("ParseTimeTravel(%q) = %v, want %v", tt.asOf, travel.AsOf, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ParseTimeTravel("12abc", ""); err == nil {
+		t.Error("ParseTimeTravel() accepted a non-numeric snapshot ID")
+	}
+	if travel, err := ParseTimeTravel("", ""); err != nil || !travel.IsZero() {
+		t.Errorf("ParseTimeTravel() without arguments = %+v, %v; want zero", travel, err)
+	}
+}
+
+func TestApplyTimeTravel(t *testing.T) {
+	snapshot := int64(8954597067493422955)
+	bySnapshot := TimeTravel{SnapshotID: &snapshot}
+	byTime := TimeTravel{AsOf: time.Date(2024, 1, 31, 14, 0, 0, 0, time.FixedZone("CEST", 2*3600))}
+
+	tests := []struct {
+		name     string
+		query    string
+		travel   TimeTravel
+		expected string
+		wantErr  bool
+	}{
+		{"No time travel", "SELECT * FROM orders", TimeTravel{}, "SELECT * FROM orders", false},
+		{"Snapshot", "SELECT count(*) FROM iceberg.sales.orders o WHERE o.status = 'open'", bySnapshot,
+			"SELECT count(*) FROM iceberg.sales.orders FOR VERSION AS OF 8954597067493422955 o WHERE o.status = 'open'", false},
+		{"Quoted name", `SELECT * FROM "sales"."Orders"`, bySnapshot,
+			`SELECT * FROM "sales"."Orders" FOR VERSION AS OF 8954597067493422955`, false},
+		{"Timestamp on every table", "SELECT * FROM orders JOIN customers c ON orders.cid = c.id", byTime,
+			"SELECT * FROM orders FOR TIMESTAMP AS OF TIMESTAMP '2024-01-31 12:00:00.000000 UTC' JOIN customers FOR TIMESTAMP AS OF TIMESTAMP '2024-01-31 12:00:00.000000 UTC' c ON orders.cid = c.id", false},
+		{"Self join with snapshot", "SELECT * FROM orders a JOIN orders b ON a.id = b.parent", bySnapshot,
+			"SELECT * FROM orders FOR VERSION AS OF 8954597067493422955 a JOIN orders FOR VERSION AS OF 8954597067493422955 b ON a.id = b.parent", false},
+		{"CTE is not a table", "WITH recent AS (SELECT * FROM orders) SELECT * FROM recent", bySnapshot,
+			"WITH recent AS (SELECT * FROM orders FOR VERSION AS OF 8954597067493422955) SELECT * FROM recent", false},
+		{"Existing clause kept", "SELECT * FROM orders FOR VERSION AS OF 1 o, customers c", byTime,
+			"SELECT * FROM orders FOR VERSION AS OF 1 o, customers FOR TIMESTAMP AS OF TIMESTAMP '2024-01-31 12:00:00.000000 UTC' c", false},
+		{"Snapshot with several tables", "SELECT * FROM orders, customers", bySnapshot, "", true},
+		{"Not a query", "INSERT INTO orders SELECT * FROM staging", byTime, "", true},
+		{"No table", "SELECT 1", byTime, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyTimeTravel(tt.query, tt.travel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyTimeTravel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ApplyTimeTravel() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestColumnTypeName(t *testing.T) {
+	tests := []struct {
+		info     ColumnInfo
+		expected string
+	}{
+		{ColumnInfo{Type: "BIGINT"}, "bigint"},
+		{ColumnInfo{Type: "DECIMAL", Precision: 10, Scale: 2}, "decimal(10,2)"},
+		{ColumnInfo{Type: "TIMESTAMP", Precision: 6}, "timestamp(6)"},
+		{ColumnInfo{Type: "TIMESTAMP WITH TIME ZONE", Precision: 3}, "timestamp(3) with time zone"},
+		{ColumnInfo{Type: "ARRAY(VARCHAR)"}, "array(varchar)"},
+	}
+
+	for _, tt := range tests {
+		if got := columnTypeName(tt.info); got != tt.expected {
+			t.Errorf("columnTypeName(%+v) = %q, want %q", tt.info, got, tt.expected)
+		}
+	}
+}
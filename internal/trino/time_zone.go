@@ -0,0 +1,61 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+const sessionTimeZoneKey contextKey = "session_time_zone"
+
+// WithSessionTimeZone overrides TRINO_SESSION_TIMEZONE for the queries of one tool call
+func WithSessionTimeZone(ctx context.Context, zone string) context.Context {
+	return context.WithValue(ctx, sessionTimeZoneKey, zone)
+}
+
+// sessionTimeZone returns the time zone a query runs in: the request's, the
+// configured one, or "" to leave Trino's default. Timestamps without time
+// zone are reported in the returned location, UTC when the zone is "".
+func (c *Client) sessionTimeZone(ctx context.Context) (string, *time.Location, error) {
+	zone := c.config.SessionTimeZone
+	if override, ok := ctx.Value(sessionTimeZoneKey).(string); ok && override != "" {
+		zone = override
+	}
+	if zone == "" {
+		return "", time.UTC, nil
+	}
+	loc, err := config.LoadTimeZone(zone)
+	if err != nil {
+		return "", nil, err
+	}
+	return zone, loc, nil
+}
+
+// wallClockColumns reports which columns are TIMESTAMP without time zone,
+// or nil when there are none
+func wallClockColumns(infos []ColumnInfo) []bool {
+	var wallClock []bool
+	for i, info := range infos {
+		name := strings.ToLower(info.Type)
+		if strings.HasPrefix(name, "timestamp") && !strings.HasSuffix(name, "with time zone") {
+			if wallClock == nil {
+				wallClock = make([]bool, len(infos))
+			}
+			wallClock[i] = true
+		}
+	}
+	return wallClock
+}
+
+// inTimeZone gives the wall-clock readings of TIMESTAMP without time zone
+// values the session time zone, which the driver parses in the server's
+// local zone, so that results carry the offset Trino would use to convert them
+func inTimeZone(values []interface{}, wallClock []bool, loc *time.Location) {
+	for i, v := range values {
+		if t, ok := v.(time.Time); ok && wallClock[i] {
+			values[i] = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+		}
+	}
+}
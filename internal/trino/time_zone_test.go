@@ -0,0 +1,53 @@
+package trino
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestSessionTimeZone(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{SessionTimeZone: "Europe/Paris"}}
+
+	zone, loc, err := client.sessionTimeZone(context.Background())
+	if err != nil || zone != "Europe/Paris" || loc.String() != "Europe/Paris" {
+		t.Errorf("sessionTimeZone() = %q, %v, %v; want the configured zone", zone, loc, err)
+	}
+	zone, _, err = client.sessionTimeZone(WithSessionTimeZone(context.Background(), "+05:30"))
+	if err != nil || zone != "+05:30" {
+		t.Errorf("sessionTimeZone() with override = %q, %v; want +05:30", zone, err)
+	}
+
+	client.config.SessionTimeZone = ""
+	zone, loc, err = client.sessionTimeZone(context.Background())
+	if err != nil || zone != "" || loc != time.UTC {
+		t.Errorf("sessionTimeZone() unset = %q, %v, %v; want no zone and UTC", zone, loc, err)
+	}
+}
+
+func TestInTimeZone(t *testing.T) {
+	infos := []ColumnInfo{{Type: "TIMESTAMP"}, {Type: "TIMESTAMP WITH TIME ZONE"}, {Type: "VARCHAR"}, {Type: "timestamp(6)"}}
+	wallClock := wallClockColumns(infos)
+	if want := []bool{true, false, false, true}; !reflect.DeepEqual(wallClock, want) {
+		t.Fatalf("wallClockColumns() = %v, want %v", wallClock, want)
+	}
+	if wallClockColumns([]ColumnInfo{{Type: "DATE"}}) != nil {
+		t.Error("wallClockColumns() without timestamps should be nil")
+	}
+
+	loc, _ := config.LoadTimeZone("America/New_York")
+	local := time.Date(2024, 1, 31, 9, 30, 0, 0, time.FixedZone("server", 3600))
+	zoned := time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC)
+	values := []interface{}{local, zoned, "text", nil}
+	inTimeZone(values, wallClock, loc)
+
+	if got := values[0].(time.Time).Format(time.RFC3339); got != "2024-01-31T09:30:00-05:00" {
+		t.Errorf("timestamp = %s, want the wall clock with the session offset", got)
+	}
+	if !values[1].(time.Time).Equal(zoned) || values[2] != "text" || values[3] != nil {
+		t.Errorf("inTimeZone() changed other values: %v", values)
+	}
+}
@@ -0,0 +1,49 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Validation is the outcome of ValidateQueryWithContext
+type Validation struct {
+	Valid bool        `json:"valid"`
+	Error *QueryError `json:"error,omitempty"` // Why the query would fail, when it is not valid
+}
+
+// ValidateQueryWithContext checks a query without executing it: first
+// against the server's query policies, then with EXPLAIN (TYPE VALIDATE),
+// which makes Trino parse and analyze it (syntax, table and column existence,
+// types and access control) without planning or running it. A query that
+// would fail is reported as not valid with the error; other errors, such as
+// an unreachable cluster, are returned.
+func (c *Client) ValidateQueryWithContext(ctx context.Context, query string) (*Validation, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	if query == "" {
+		return nil, errors.New("query is required")
+	}
+
+	_, err := c.checkSelectStar(ctx, query)
+	if err == nil {
+		_, err = c.checkInformationSchema(query)
+	}
+	if err == nil {
+		_, err = c.checkShowStatement(query)
+	}
+	if err == nil {
+		_, err = c.checkQuery(ctx, query, &queryOptions{agent: true})
+	}
+	if err == nil {
+		_, err = c.ExplainQueryWithContext(ctx, query, "VALIDATE")
+		err = c.suggestNames(ctx, query, err)
+	}
+	if err == nil {
+		return &Validation{Valid: true}, nil
+	}
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return &Validation{Error: queryErr}, nil
+	}
+	return nil, err
+}
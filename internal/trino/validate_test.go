@@ -0,0 +1,41 @@
+package trino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func TestValidateQueryPolicies(t *testing.T) {
+	client := &Client{config: &config.TrinoConfig{
+		Catalog:                  "hive",
+		Schema:                   "analytics",
+		RequiredPartitionFilters: map[string][]string{"hive.analytics.events": {"ds"}},
+	}}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantName string
+	}{
+		{"Write statement", "DELETE FROM events WHERE ds = '2024-01-01'", ErrorPermissionDenied},
+		{"Missing partition filter", "SELECT count(*) FROM events;", ErrorQueryRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validation, err := client.ValidateQueryWithContext(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("ValidateQueryWithContext() error = %v", err)
+			}
+			if validation.Valid || validation.Error == nil || validation.Error.Name != tt.wantName || !validation.Error.Policy {
+				t.Errorf("ValidateQueryWithContext() = %+v, want a %s policy error", validation, tt.wantName)
+			}
+		})
+	}
+
+	if _, err := client.ValidateQueryWithContext(context.Background(), " ; "); err == nil {
+		t.Error("ValidateQueryWithContext() with an empty query expected error")
+	}
+}
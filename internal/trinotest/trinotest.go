@@ -0,0 +1,331 @@
+// Package trinotest provides an in-process Trino coordinator for tests. It
+// implements enough of the Trino client REST protocol for the Go driver and
+// mcp-trino: statement submission, results paged over nextUri, query
+// cancellation, /v1/info and the OAuth2 challenge and token server of
+// Trino's external authentication. Queries are answered from canned results.
+//
+//	server := trinotest.NewServer()
+//	defer server.Close()
+//	server.Handle("SHOW CATALOGS", trinotest.Result{
+//		Columns: []trinotest.Column{{Name: "Catalog", Type: "varchar"}},
+//		Rows:    [][]any{{"hive"}, {"system"}},
+//	})
+//	host, port := server.HostPort() // TRINO_HOST, TRINO_PORT with TRINO_SCHEME=http
+package trinotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Column is a result column with its Trino type, e.g. varchar or decimal(10,2)
+type Column struct {
+	Name string
+	Type string
+}
+
+// Result is the canned answer to a query: its rows, or the error it fails with
+type Result struct {
+	Columns  []Column
+	Rows     [][]any
+	PageSize int    // Rows per nextUri page; 0 returns all rows on one page
+	Error    *Error // Fail the query instead of returning rows
+}
+
+// Error is a failed query as Trino reports it
+type Error struct {
+	Name    string // e.g. TABLE_NOT_FOUND
+	Type    string // USER_ERROR, INTERNAL_ERROR, INSUFFICIENT_RESOURCES or EXTERNAL
+	Code    int
+	Message string
+}
+
+// Request is a statement the server received
+type Request struct {
+	Query   string
+	User    string // X-Trino-User
+	Catalog string // X-Trino-Catalog
+	Schema  string // X-Trino-Schema
+	Token   string // Bearer token, if any
+	Header  http.Header
+}
+
+// Server is a mock Trino coordinator listening on a local port
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	results  map[string]Result
+	queries  map[string]*query
+	requests []Request
+	token    string          // Bearer token required for statements; "" accepts all
+	logins   map[string]bool // Logins started by the OAuth challenge, by ID; true once completed
+	nextID   int
+}
+
+// query is a statement whose pages are being fetched
+type query struct {
+	result Result
+}
+
+// NewServer starts a mock coordinator. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		results: make(map[string]Result),
+		queries: make(map[string]*query),
+		logins:  make(map[string]bool),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/statement", s.submit)
+	mux.HandleFunc("GET /v1/statement/executing/{id}/{page}", s.fetch)
+	mux.HandleFunc("DELETE /v1/statement/executing/{id}/{page}", s.cancel)
+	mux.HandleFunc("GET /v1/info", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"nodeVersion": map[string]string{"version": "trinotest"}, "starting": false})
+	})
+	mux.HandleFunc("GET /oauth2/token/initiate/{id}", s.completeLogin)
+	mux.HandleFunc("GET /oauth2/token/{id}", s.pollToken)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// HostPort returns the host and port to configure as TRINO_HOST and TRINO_PORT
+func (s *Server) HostPort() (string, int) {
+	host, port, _ := net.SplitHostPort(s.Listener.Addr().String())
+	n, _ := strconv.Atoi(port)
+	return host, n
+}
+
+// Handle sets the result of query. Queries match regardless of comments,
+// whitespace and a trailing semicolon; queries without a result fail.
+func (s *Server) Handle(query string, result Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[normalize(query)] = result
+}
+
+// Requests returns the statements received so far, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequireToken makes the server accept statements only with the bearer token,
+// like a coordinator with the OAuth2 authenticator. Other requests get a 401
+// challenge naming a login URL and a token server; the token server hands out
+// token once the login URL has been opened. Calling it again rotates the
+// token, rejecting the old one; "" turns authentication off.
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// submit starts a statement and answers with the URI of its first page
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := fmt.Sprintf("20240101_000000_%05d_trino", s.nextID)
+	result, ok := s.results[normalize(string(body))]
+	if !ok {
+		result = Result{Error: &Error{
+			Name:    "NOT_SUPPORTED",
+			Type:    "USER_ERROR",
+			Code:    13,
+			Message: fmt.Sprintf("trinotest: no result for query: %s", body),
+		}}
+	}
+	s.queries[id] = &query{result: result}
+	s.requests = append(s.requests, Request{
+		Query:   string(body),
+		User:    r.Header.Get("X-Trino-User"),
+		Catalog: r.Header.Get("X-Trino-Catalog"),
+		Schema:  r.Header.Get("X-Trino-Schema"),
+		Token:   bearerToken(r),
+		Header:  r.Header.Clone(),
+	})
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]any{
+		"id":      id,
+		"infoUri": s.URL + "/ui/query.html?" + id,
+		"nextUri": s.pageURI(id, 0),
+		"stats":   map[string]any{"state": "QUEUED"},
+	})
+}
+
+// fetch returns a page of rows, or the error the query fails with
+func (s *Server) fetch(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+	id := r.PathValue("id")
+	page, err := strconv.Atoi(r.PathValue("page"))
+	s.mu.Lock()
+	q := s.queries[id]
+	s.mu.Unlock()
+	if q == nil || err != nil {
+		http.Error(w, "Query not found", http.StatusNotFound)
+		return
+	}
+
+	resp := map[string]any{"id": id, "infoUri": s.URL + "/ui/query.html?" + id}
+	if e := q.result.Error; e != nil {
+		resp["stats"] = map[string]any{"state": "FAILED"}
+		resp["error"] = map[string]any{"message": e.Message, "errorCode": e.Code, "errorName": e.Name, "errorType": e.Type}
+		s.finish(id)
+		writeJSON(w, resp)
+		return
+	}
+
+	rows := q.result.Rows
+	size := q.result.PageSize
+	if size <= 0 {
+		size = max(len(rows), 1)
+	}
+	start := min(page*size, len(rows))
+	end := min(start+size, len(rows))
+	state := "FINISHED"
+	if end < len(rows) {
+		state = "RUNNING"
+		resp["nextUri"] = s.pageURI(id, page+1)
+	} else {
+		s.finish(id)
+	}
+	resp["stats"] = map[string]any{"state": state, "processedRows": end}
+	resp["columns"] = columns(q.result.Columns)
+	if end > start {
+		resp["data"] = rows[start:end]
+	}
+	writeJSON(w, resp)
+}
+
+// cancel forgets a query the client closed before reading all its pages
+func (s *Server) cancel(w http.ResponseWriter, r *http.Request) {
+	s.finish(r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) finish(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.queries, id)
+}
+
+func (s *Server) pageURI(id string, page int) string {
+	return fmt.Sprintf("%s/v1/statement/executing/%s/%d", s.URL, id, page)
+}
+
+// authorized checks the bearer token, challenging requests without a valid one
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == "" || bearerToken(r) == s.token {
+		return true
+	}
+	s.nextID++
+	login := strconv.Itoa(s.nextID)
+	s.logins[login] = false
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer x_redirect_server="%s/oauth2/token/initiate/%s", x_token_server="%s/oauth2/token/%s"`,
+		s.URL, login, s.URL, login))
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	return false
+}
+
+// completeLogin stands in for the identity provider: opening the login URL
+// logs the user in
+func (s *Server) completeLogin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, ok := s.logins[r.PathValue("id")]
+	if ok {
+		s.logins[r.PathValue("id")] = true
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "Unknown login", http.StatusNotFound)
+		return
+	}
+	_, _ = io.WriteString(w, "Logged in to trinotest. You can close this window.")
+}
+
+// pollToken answers the token poll: the token once the user has logged in,
+// and until then the URI to poll again
+func (s *Server) pollToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	done, ok := s.logins[r.PathValue("id")]
+	token := s.token
+	if done {
+		delete(s.logins, r.PathValue("id"))
+	}
+	s.mu.Unlock()
+	switch {
+	case !ok:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusGone)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "login expired"})
+	case done:
+		writeJSON(w, map[string]string{"token": token})
+	default:
+		writeJSON(w, map[string]string{"nextUri": s.URL + r.URL.Path})
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token
+}
+
+// columns describes result columns with the type signatures the driver
+// decodes values with. Parameters such as varchar(10) or decimal(10,2) become
+// numeric arguments; nested types like array(varchar) are not supported.
+func columns(cols []Column) []map[string]any {
+	out := make([]map[string]any, len(cols))
+	for i, c := range cols {
+		raw, params, _ := strings.Cut(c.Type, "(")
+		args := []map[string]any{}
+		for _, p := range strings.Split(strings.TrimSuffix(params, ")"), ",") {
+			if n, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64); err == nil {
+				args = append(args, map[string]any{"kind": "LONG", "value": n})
+			}
+		}
+		out[i] = map[string]any{
+			"name":          c.Name,
+			"type":          c.Type,
+			"typeSignature": map[string]any{"rawType": strings.TrimSpace(raw), "arguments": args},
+		}
+	}
+	return out
+}
+
+var (
+	lineComment  = regexp.MustCompile(`--[^\n]*`)
+	blockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+)
+
+// normalize drops comments, extra whitespace and a trailing semicolon
+func normalize(query string) string {
+	query = blockComment.ReplaceAllString(lineComment.ReplaceAllString(query, " "), " ")
+	return strings.TrimSpace(strings.TrimSuffix(strings.Join(strings.Fields(query), " "), ";"))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,47 @@
+package trinotest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"SELECT 1":                          "SELECT 1",
+		"  SELECT\n\t1 ;":                   "SELECT 1",
+		"-- daily\nSELECT /* hint */ 1;":    "SELECT 1",
+		"SELECT *\nFROM t\nWHERE a = 'x'  ": "SELECT * FROM t WHERE a = 'x'",
+	}
+	for query, want := range tests {
+		if got := normalize(query); got != want {
+			t.Errorf("normalize(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.RequireToken("secret")
+
+	resp, err := http.Post(server.URL+"/v1/statement", "text/plain", strings.NewReader("SELECT 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	challenge := resp.Header.Get("WWW-Authenticate")
+	if resp.StatusCode != http.StatusUnauthorized || !strings.Contains(challenge, `x_token_server="`+server.URL+`/oauth2/token/`) {
+		t.Fatalf("unauthenticated statement = %d %q, want a 401 OAuth2 challenge", resp.StatusCode, challenge)
+	}
+
+	// Polls for a login that was never started have expired
+	resp, err = http.Get(server.URL + "/oauth2/token/unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusGone {
+		t.Errorf("unknown login poll = %d, want 410", resp.StatusCode)
+	}
+}